@@ -1,101 +1,149 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"log/slog"
+	"net/http"
 	"os"
-	"path/filepath"
+	"os/signal"
+	"syscall"
 
+	"tourism/internal/config"
 	"tourism/internal/handler"
+	"tourism/internal/health"
+	"tourism/internal/i18n"
+	"tourism/internal/logging"
+	"tourism/internal/metrics"
+	"tourism/internal/migrate"
 	"tourism/internal/repository"
 	"tourism/internal/service"
+	"tourism/migrations"
 
 	"github.com/gin-gonic/gin"
-	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq" // PostgreSQL драйвер
 )
 
 func main() {
-	// Читаем параметры подключения к БД из переменных окружения
-	dbHost := os.Getenv("DB_HOST")
-	dbPort := os.Getenv("DB_PORT")
-	dbUser := os.Getenv("DB_USER")
-	dbPass := os.Getenv("DB_PASS")
-	dbName := os.Getenv("DB_NAME")
-	if dbHost == "" {
-		dbHost = "localhost"
+	cfg, err := config.Load(config.AppAPI)
+	if err != nil {
+		log.Fatal(err)
 	}
-	if dbPort == "" {
-		dbPort = "5432"
+	if err := logging.Setup("api", cfg.Log.Level, cfg.Log.Format); err != nil {
+		log.Fatal(err)
 	}
-	dsn := "host=" + dbHost + " port=" + dbPort + " user=" + dbUser + " password=" + dbPass + " dbname=" + dbName + " sslmode=disable"
-	db, err := sqlx.Connect("postgres", dsn)
+	slog.Info("Конфигурация загружена", "config", cfg)
+	db, err := cfg.DB.Connect()
 	if err != nil {
-		log.Fatalf("Не удалось подключиться к базе данных: %v", err)
+		fatal("Нет подключения к базе данных", err)
 	}
-	// Выполняем миграции (если есть)
-	files, err := filepath.Glob("migrations/*.sql")
-	if err == nil {
-		for _, file := range files {
-			if _, err := db.Exec("BEGIN"); err != nil {
-				log.Printf("Ошибка при инициации транзакции миграции: %v", err)
-				continue
-			}
-			err := func() error {
-				content, readErr := os.ReadFile(file)
-				if readErr != nil {
-					return readErr
-				}
-				if _, execErr := db.Exec(string(content)); execErr != nil {
-					return execErr
-				}
-				return nil
-			}()
-			if err != nil {
-				log.Printf("Миграция %s завершилась ошибкой: %v", file, err)
-				db.Exec("ROLLBACK")
-			} else {
-				db.Exec("COMMIT")
-				log.Printf("Миграция %s применена.", file)
-			}
-		}
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Применяем миграции, которые еще не применены
+	if _, err := migrate.Apply(ctx, db, migrations.FS); err != nil {
+		fatal("Ошибка миграции базы данных", err)
 	}
+	checker := health.NewChecker()
+	checker.Add("database", health.Database(db))
+	checker.Add("migrations", health.Migrations(db, migrations.FS))
 
+	// запросы репозиториев ограничены сроком контекста и DB_QUERY_TIMEOUT
+	store := repository.NewDB(db, cfg.DB.QueryTimeout.Std())
 	// Инициализируем репозитории
-	userRepo := repository.NewUserRepository(db)
-	locationRepo := repository.NewLocationRepository(db)
-	tripRepo := repository.NewTripRepository(db)
-	bookingRepo := repository.NewBookingRepository(db)
+	userRepo := repository.NewUserRepository(store)
+	locationRepo := repository.NewLocationRepository(store)
+	tripRepo := repository.NewTripRepository(store)
+	bookingRepo := repository.NewBookingRepository(store)
 
-	subRepo := repository.NewSubscriptionRepository(db)
+	subRepo := repository.NewSubscriptionRepository(store)
+	offerRepo := repository.NewOfferRepository(store)
+	reviewRepo := repository.NewReviewRepository(store)
+	translationRepo := repository.NewTranslationRepository(store)
+	// уведомления, поставленные в очередь через API, отправляет бот
+	notificationRepo := repository.NewNotificationRepository(store)
+	messageRepo := repository.NewMessageRepository(store)
+	auditRepo := repository.NewAuditRepository(store)
+	stateRepo := repository.NewStateRepository(store)
 	// Инициализируем сервисы
 
 	userService := service.NewUserService(userRepo)
 	locationService := service.NewLocationService(locationRepo)
 	tripService := service.NewTripService(tripRepo, locationRepo)
-	bookingService := service.NewBookingService(bookingRepo)
-	chatService := service.NewChatService(bookingRepo, userRepo, locationRepo)
-	offerService := service.NewOfferService(subRepo)
+	bookingService := service.NewBookingService(store, bookingRepo, locationRepo, userRepo, notificationRepo)
+	chatService := service.NewChatService(bookingRepo, userRepo, locationRepo, stateRepo)
+	offerService := service.NewOfferService(subRepo, offerRepo, locationRepo)
+	reviewService := service.NewReviewService(store, reviewRepo, locationRepo, userRepo, notificationRepo, service.DefaultModerationRules())
+	translationService := service.NewTranslationService(store, translationRepo, locationRepo, offerRepo, userRepo, notificationRepo)
+	adminService := service.NewAdminService(store, userRepo, bookingRepo, tripRepo, messageRepo, auditRepo, notificationRepo)
+	// фото сохраняет основной бот; API читает их из того же хранилища (STORAGE_*)
+	blobs, err := cfg.Storage.Open()
+	if err != nil {
+		fatal("Не удалось открыть хранилище фото", err)
+	}
+	photoStorage := service.NewPhotoStorageService(locationRepo, blobs)
+	renderer, err := cfg.Maps.Renderer()
+	if err != nil {
+		fatal("Не удалось подготовить отрисовку карт", err)
+	}
+	mapService := service.NewMapService(tripRepo, renderer)
+	// локали ботов — это и языки, на которые переводятся локации и предложения
+	texts, err := i18n.New()
+	if err != nil {
+		fatal("Не удалось загрузить тексты сообщений", err)
+	}
 
 	// Создаем Handler и регистрируем маршруты
-	h := handler.NewHandler(userService, locationService, tripService, bookingService, chatService, offerService)
-	router := gin.Default()
-	api := router.Group("/api")
-	{
-		api.GET("/locations", h.ListLocations)
-		api.GET("/users", h.ListUsers)
-		// Дополнительные маршруты (например, для добавления локации) могут быть добавлены при необходимости
-	}
-	// Health-check endpoint
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "ok"})
-	})
+	h := handler.NewHandler(userService, locationService, tripService, bookingService, chatService, offerService, reviewService,
+		translationService, adminService, photoStorage, mapService, texts)
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.Use(handler.RequestLogger(), handler.Metrics(), gin.Recovery())
+	// маршруты пользователей ботов требуют Authorization: tma <данные запуска Mini App>,
+	// подписанные токеном основного бота или бота поддержки
+	auth := handler.Auth(userService, []string{cfg.Bot.Token.Value(), cfg.SupportBot.Token.Value()}, cfg.API.AuthMaxAge.Std())
+	h.Register(router, auth)
+	// Метрики Prometheus
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+	// Проверки живости и готовности; /health оставлен для совместимости
+	router.GET("/health", gin.WrapF(checker.LiveHandler))
+	router.GET("/health/live", gin.WrapF(checker.LiveHandler))
+	router.GET("/health/ready", gin.WrapF(checker.ReadyHandler))
 
 	// Запускаем HTTP-сервер
-	port := os.Getenv("API_PORT")
-	if port == "" {
-		port = "8080"
+	server := &http.Server{
+		Addr:         fmt.Sprintf(":%d", cfg.API.Port),
+		Handler:      router,
+		ReadTimeout:  cfg.API.ReadTimeout.Std(),
+		WriteTimeout: cfg.API.WriteTimeout.Std(),
 	}
-	if err := router.Run(":" + port); err != nil {
-		log.Fatalf("Ошибка запуска сервера: %v", err)
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("API запущено", "addr", server.Addr)
+		serveErr <- server.ListenAndServe()
+	}()
+	select {
+	case err := <-serveErr:
+		fatal("Ошибка запуска сервера", err)
+	case <-ctx.Done():
+	}
+
+	// Завершаем работу: перестаем быть готовыми, дожидаемся начатых запросов и закрываем базу
+	slog.Info("Получен сигнал завершения, останавливаем API")
+	checker.Shutdown()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.API.ShutdownTimeout.Std())
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Не все запросы завершились до остановки", "err", err)
 	}
+	if err := db.Close(); err != nil {
+		slog.Error("Ошибка закрытия базы данных", "err", err)
+	}
+	slog.Info("API остановлено")
+}
+
+// fatal логирует ошибку и завершает процесс.
+func fatal(msg string, err error) {
+	slog.Error(msg, "err", err)
+	os.Exit(1)
 }
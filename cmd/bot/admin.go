@@ -0,0 +1,363 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"tourism/internal/bot"
+	"tourism/internal/model"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// сколько записей показывается администратору в списках: найденные пользователи, брони,
+// маршруты, сообщения поддержки и журнал
+const adminListLimit = 20
+
+// сценарии администратора: ввод запроса поиска и причины блокировки
+const (
+	flowUserSearch = "user_search"
+	flowUserBlock  = "user_block"
+)
+
+// userBlockPayload — данные сценария блокировки пользователя.
+type userBlockPayload struct {
+	UserID int `json:"user_id"`
+}
+
+// adminUsers ищет пользователей по запросу из команды /users <запрос> или спрашивает запрос.
+func (a *app) adminUsers(c *bot.Context) error {
+	if query := strings.TrimSpace(c.Args()); query != "" {
+		return a.adminSearch(c, query)
+	}
+	if err := c.SetState(flowUserSearch, "", nil); err != nil {
+		return err
+	}
+	return c.Reply(c.T("admin.search_prompt"))
+}
+
+// adminSearchInput ищет пользователей по запросу, введенному в сценарии поиска.
+func (a *app) adminSearchInput(c *bot.Context) error {
+	query := strings.TrimSpace(c.Text())
+	if query == "" {
+		return c.Reply(c.T("admin.search_prompt"))
+	}
+	if err := c.ClearState(); err != nil {
+		return err
+	}
+	return a.adminSearch(c, query)
+}
+
+// adminSearch показывает найденных пользователей кнопками, открывающими их карточки.
+func (a *app) adminSearch(c *bot.Context, query string) error {
+	users, err := a.admin.Search(c, c.User.ID, query, adminListLimit)
+	if err != nil {
+		return c.Reply(c.T("admin.failed", "err", err.Error()))
+	}
+	if len(users) == 0 {
+		return c.Reply(c.T("admin.search_empty", "query", query))
+	}
+	rows := [][]tgbotapi.InlineKeyboardButton{}
+	for _, u := range users {
+		label := c.T("admin.user_label", "id", u.ID, "name", fullName(&u), "username", u.Username,
+			"role", c.T("role."+u.Role), "blocked", u.IsBlocked)
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("ADM_USER_%d", u.ID)),
+		))
+	}
+	msg := tgbotapi.NewMessage(c.ChatID, c.T("admin.search_results", "query", query, "count", len(users)))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	_, err = c.Send(msg)
+	return err
+}
+
+// adminUserCommand показывает карточку пользователя: /user <ID>.
+func (a *app) adminUserCommand(c *bot.Context) error {
+	id, err := strconv.Atoi(strings.TrimSpace(c.Args()))
+	if err != nil {
+		return c.Reply(c.T("admin.user_usage"))
+	}
+	return a.sendAdminUser(c, id)
+}
+
+// adminUser показывает карточку пользователя по кнопке из результатов поиска.
+func (a *app) adminUser(c *bot.Context) error {
+	id, err := c.IntParam()
+	if err != nil {
+		return err
+	}
+	return a.sendAdminUser(c, id)
+}
+
+// sendAdminUser отправляет карточку пользователя с кнопками просмотра и управления.
+func (a *app) sendAdminUser(c *bot.Context, id int) error {
+	u, err := a.admin.User(c, c.User.ID, id)
+	if err != nil {
+		return c.Reply(c.T("admin.failed", "err", err.Error()))
+	}
+	msg := tgbotapi.NewMessage(c.ChatID, c.T("admin.user_card",
+		"id", u.ID,
+		"telegram_id", u.TelegramID,
+		"name", fullName(u),
+		"username", u.Username,
+		"role", c.T("role."+u.Role),
+		"language", u.PreferredLanguage(),
+		"created", u.CreatedAt.In(broadcastZone).Format("2006-01-02 15:04"),
+		"active", u.IsActive,
+		"blocked", u.IsBlocked,
+	))
+	button := func(key, action string) tgbotapi.InlineKeyboardButton {
+		return tgbotapi.NewInlineKeyboardButtonData(c.T(key), fmt.Sprintf("ADM_%s_%d", action, u.ID))
+	}
+	block := button("admin.block", "BLOCK")
+	if u.IsBlocked {
+		block = button("admin.unblock", "UNBLOCK")
+	}
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(button("admin.bookings", "BOOKINGS"), button("admin.trips", "TRIPS"),
+			button("admin.tickets", "TICKETS")),
+		tgbotapi.NewInlineKeyboardRow(button("admin.change_role", "ROLES"), block),
+		tgbotapi.NewInlineKeyboardRow(button("admin.audit", "AUDIT")),
+	)
+	_, err = c.Send(msg)
+	return err
+}
+
+// adminBookings показывает последние брони пользователя.
+func (a *app) adminBookings(c *bot.Context) error {
+	id, err := c.IntParam()
+	if err != nil {
+		return err
+	}
+	bookings, err := a.admin.Bookings(c, c.User.ID, id, adminListLimit)
+	if err != nil {
+		return c.Reply(c.T("admin.failed", "err", err.Error()))
+	}
+	if len(bookings) == 0 {
+		return c.Reply(c.T("admin.bookings_empty", "id", id))
+	}
+	lines := []string{c.T("admin.bookings_title", "id", id)}
+	for _, bk := range bookings {
+		location := strconv.Itoa(bk.LocationID)
+		if loc, err := a.locRepo.GetByID(c, bk.LocationID); err == nil {
+			location = loc.Name
+		}
+		lines = append(lines, c.T("admin.booking_line", "id", bk.ID, "location", location,
+			"status", c.T("booking.status."+bk.Status), "details", bk.Details))
+	}
+	return c.Reply(strings.Join(lines, "\n"))
+}
+
+// adminTrips показывает последние маршруты пользователя.
+func (a *app) adminTrips(c *bot.Context) error {
+	id, err := c.IntParam()
+	if err != nil {
+		return err
+	}
+	trips, err := a.admin.Trips(c, c.User.ID, id, adminListLimit)
+	if err != nil {
+		return c.Reply(c.T("admin.failed", "err", err.Error()))
+	}
+	if len(trips) == 0 {
+		return c.Reply(c.T("admin.trips_empty", "id", id))
+	}
+	lines := []string{c.T("admin.trips_title", "id", id)}
+	for _, t := range trips {
+		lines = append(lines, c.T("admin.trip_line", "id", t.ID, "name", t.Name, "status", t.Status))
+	}
+	return c.Reply(strings.Join(lines, "\n"))
+}
+
+// adminTickets показывает последние сообщения переписки пользователя с поддержкой.
+func (a *app) adminTickets(c *bot.Context) error {
+	id, err := c.IntParam()
+	if err != nil {
+		return err
+	}
+	messages, err := a.admin.Tickets(c, c.User.ID, id, adminListLimit)
+	if err != nil {
+		return c.Reply(c.T("admin.failed", "err", err.Error()))
+	}
+	if len(messages) == 0 {
+		return c.Reply(c.T("admin.tickets_empty", "id", id))
+	}
+	lines := []string{c.T("admin.tickets_title", "id", id)}
+	for _, m := range messages {
+		lines = append(lines, c.T("admin.ticket_line", "from_user", m.FromUserID == id, "text", m.Content))
+	}
+	return c.Reply(strings.Join(lines, "\n"))
+}
+
+// adminAudit показывает журнал действий с пользователем по кнопке карточки.
+func (a *app) adminAudit(c *bot.Context) error {
+	id, err := c.IntParam()
+	if err != nil {
+		return err
+	}
+	return a.sendAudit(c, id)
+}
+
+// adminAuditCommand показывает журнал: /audit — последние действия, /audit <ID> — действия с пользователем.
+func (a *app) adminAuditCommand(c *bot.Context) error {
+	id := 0
+	if args := strings.TrimSpace(c.Args()); args != "" {
+		var err error
+		if id, err = strconv.Atoi(args); err != nil {
+			return c.Reply(c.T("admin.audit_usage"))
+		}
+	}
+	return a.sendAudit(c, id)
+}
+
+func (a *app) sendAudit(c *bot.Context, userID int) error {
+	entries, err := a.admin.AuditLog(c, c.User.ID, userID, adminListLimit)
+	if err != nil {
+		return c.Reply(c.T("admin.failed", "err", err.Error()))
+	}
+	if len(entries) == 0 {
+		return c.Reply(c.T("admin.audit_empty"))
+	}
+	lines := []string{c.T("admin.audit_title", "id", userID)}
+	for _, e := range entries {
+		actor, target := 0, 0
+		if e.ActorID != nil {
+			actor = *e.ActorID
+		}
+		if e.TargetUserID != nil {
+			target = *e.TargetUserID
+		}
+		lines = append(lines, c.T("admin.audit_line",
+			"time", e.CreatedAt.In(broadcastZone).Format("2006-01-02 15:04"),
+			"actor", actor,
+			"action", c.T("admin.action."+e.Action),
+			"target", target,
+			"details", e.Details,
+		))
+	}
+	return c.Reply(strings.Join(lines, "\n"))
+}
+
+// adminRoles предлагает выбрать новую роль пользователя.
+func (a *app) adminRoles(c *bot.Context) error {
+	id, err := c.IntParam()
+	if err != nil {
+		return err
+	}
+	row := []tgbotapi.InlineKeyboardButton{}
+	for _, role := range model.Roles {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(c.T("role."+role), fmt.Sprintf("ADM_SETROLE_%d_%s", id, role)))
+	}
+	msg := tgbotapi.NewMessage(c.ChatID, c.T("admin.choose_role", "id", id))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(row)
+	_, err = c.Send(msg)
+	return err
+}
+
+// adminSetRoleButton меняет роль, выбранную кнопкой: ADM_SETROLE_<ID>_<роль>.
+func (a *app) adminSetRoleButton(c *bot.Context) error {
+	idText, role, _ := strings.Cut(c.Param(), "_")
+	id, err := strconv.Atoi(idText)
+	if err != nil {
+		return fmt.Errorf("некорректный параметр кнопки %q", c.Param())
+	}
+	return a.adminSetRole(c, id, role)
+}
+
+// adminSetRoleCommand меняет роль командой /set_role <ID> <роль>.
+func (a *app) adminSetRoleCommand(c *bot.Context) error {
+	args := strings.Fields(c.Args())
+	if len(args) != 2 {
+		return c.Reply(c.T("admin.set_role_usage", "roles", strings.Join(model.Roles, ", ")))
+	}
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return c.Reply(c.T("admin.set_role_usage", "roles", strings.Join(model.Roles, ", ")))
+	}
+	return a.adminSetRole(c, id, args[1])
+}
+
+func (a *app) adminSetRole(c *bot.Context, id int, role string) error {
+	u, err := a.admin.SetRole(c, c.User.ID, id, role)
+	if err != nil {
+		return c.Reply(c.T("admin.failed", "err", err.Error()))
+	}
+	return c.Reply(c.T("admin.role_changed", "id", u.ID, "name", fullName(u), "role", c.T("role."+u.Role)))
+}
+
+// adminBlock запрашивает причину блокировки пользователя.
+func (a *app) adminBlock(c *bot.Context) error {
+	id, err := c.IntParam()
+	if err != nil {
+		return err
+	}
+	if err := c.SetState(flowUserBlock, "", userBlockPayload{UserID: id}); err != nil {
+		return err
+	}
+	return c.Reply(c.T("admin.block_prompt", "id", id))
+}
+
+// adminBlockInput блокирует пользователя с причиной, введенной администратором.
+func (a *app) adminBlockInput(c *bot.Context) error {
+	var p userBlockPayload
+	if err := c.Payload(&p); err != nil {
+		return err
+	}
+	reason := strings.TrimSpace(c.Text())
+	if reason == "" {
+		return c.Reply(c.T("admin.block_prompt", "id", p.UserID))
+	}
+	if err := c.ClearState(); err != nil {
+		return err
+	}
+	return a.blockUser(c, p.UserID, reason)
+}
+
+// adminBlockCommand блокирует пользователя командой /block <ID> [причина].
+func (a *app) adminBlockCommand(c *bot.Context) error {
+	idText, reason, _ := strings.Cut(strings.TrimSpace(c.Args()), " ")
+	id, err := strconv.Atoi(idText)
+	if err != nil {
+		return c.Reply(c.T("admin.block_usage"))
+	}
+	return a.blockUser(c, id, reason)
+}
+
+func (a *app) blockUser(c *bot.Context, id int, reason string) error {
+	u, err := a.admin.Block(c, c.User.ID, id, reason)
+	if err != nil {
+		return c.Reply(c.T("admin.failed", "err", err.Error()))
+	}
+	return c.Reply(c.T("admin.blocked", "id", u.ID, "name", fullName(u)))
+}
+
+// adminUnblock снимает блокировку по кнопке карточки.
+func (a *app) adminUnblock(c *bot.Context) error {
+	id, err := c.IntParam()
+	if err != nil {
+		return err
+	}
+	return a.unblockUser(c, id)
+}
+
+// adminUnblockCommand снимает блокировку командой /unblock <ID>.
+func (a *app) adminUnblockCommand(c *bot.Context) error {
+	id, err := strconv.Atoi(strings.TrimSpace(c.Args()))
+	if err != nil {
+		return c.Reply(c.T("admin.unblock_usage"))
+	}
+	return a.unblockUser(c, id)
+}
+
+func (a *app) unblockUser(c *bot.Context, id int) error {
+	u, err := a.admin.Unblock(c, c.User.ID, id)
+	if err != nil {
+		return c.Reply(c.T("admin.failed", "err", err.Error()))
+	}
+	return c.Reply(c.T("admin.unblocked", "id", u.ID, "name", fullName(u)))
+}
+
+// fullName возвращает имя и фамилию пользователя.
+func fullName(u *model.User) string {
+	return strings.TrimSpace(u.FirstName + " " + u.LastName)
+}
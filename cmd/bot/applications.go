@@ -0,0 +1,152 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"tourism/internal/bot"
+	"tourism/internal/model"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// сколько заявок провайдеров показывается поддержке за раз
+const applicationsQueueLimit = 10
+
+// сценарий ввода причины отклонения заявки
+const flowApplicationReject = "application_reject"
+
+// applicationRejectPayload — данные сценария отклонения заявки.
+type applicationRejectPayload struct {
+	ApplicationID int `json:"application_id"`
+}
+
+// applicationsQueue показывает поддержке заявки провайдеров, ожидающие проверки.
+// Очередь живет в основном боте: FileID фото документов действительны только для бота, который их получил.
+func (a *app) applicationsQueue(c *bot.Context) error {
+	applications, err := a.providers.Pending(c, applicationsQueueLimit)
+	if err != nil {
+		return c.Reply(c.T("provider.queue_failed"))
+	}
+	if len(applications) == 0 {
+		return c.Reply(c.T("provider.queue_empty"))
+	}
+	for i := range applications {
+		if err := a.sendApplication(c, &applications[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sendApplication отправляет фото документов заявки и карточку с кнопками решения.
+func (a *app) sendApplication(c *bot.Context, application *model.ProviderApplication) error {
+	sendPhotos(c, application.DocumentFileIDs)
+	locations, err := a.providers.ApplicationLocations(c, application)
+	if err != nil {
+		return err
+	}
+	a.localizeLocations(c, locations)
+	names := []string{}
+	for _, loc := range locations {
+		names = append(names, loc.Name)
+	}
+	applicant := strconv.Itoa(application.UserID)
+	if user, err := a.users.GetByID(c, application.UserID); err == nil {
+		applicant = user.FirstName
+	}
+	msg := tgbotapi.NewMessage(c.ChatID, c.T("provider.card",
+		"id", application.ID,
+		"applicant", applicant,
+		"user_id", application.UserID,
+		"business_name", application.BusinessName,
+		"contacts", application.Contacts,
+		"documents", len(application.DocumentFileIDs),
+		"locations", strings.Join(names, ", "),
+		"note", application.LocationsNote,
+	))
+	id := strconv.Itoa(application.ID)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(c.T("provider.approve"), "APP_APPROVE_"+id),
+		tgbotapi.NewInlineKeyboardButtonData(c.T("provider.reject"), "APP_REJECT_"+id),
+	))
+	_, err = c.Send(msg)
+	return err
+}
+
+// applicationApprove одобряет заявку провайдера.
+func (a *app) applicationApprove(c *bot.Context) error {
+	id, err := c.IntParam()
+	if err != nil {
+		return err
+	}
+	application, linked, err := a.providers.Approve(c, c.User.ID, id)
+	if err != nil {
+		return c.Reply(c.T("provider.decision_failed", "err", err.Error()))
+	}
+	return c.Reply(c.T("provider.approved", "id", application.ID, "linked", len(linked)))
+}
+
+// applicationReject запрашивает у поддержки причину отклонения заявки.
+func (a *app) applicationReject(c *bot.Context) error {
+	id, err := c.IntParam()
+	if err != nil {
+		return err
+	}
+	if err := c.SetState(flowApplicationReject, "", applicationRejectPayload{ApplicationID: id}); err != nil {
+		return err
+	}
+	return c.Reply(c.T("provider.reject_prompt", "id", id))
+}
+
+// applicationRejectInput отклоняет заявку с причиной, введенной поддержкой.
+func (a *app) applicationRejectInput(c *bot.Context) error {
+	var p applicationRejectPayload
+	if err := c.Payload(&p); err != nil {
+		return err
+	}
+	reason := strings.TrimSpace(c.Text())
+	if reason == "" {
+		return c.Reply(c.T("provider.reject_prompt", "id", p.ApplicationID))
+	}
+	if err := c.ClearState(); err != nil {
+		return err
+	}
+	if _, err := a.providers.Reject(c, c.User.ID, p.ApplicationID, reason); err != nil {
+		return c.Reply(c.T("provider.decision_failed", "err", err.Error()))
+	}
+	return c.Reply(c.T("provider.rejected", "id", p.ApplicationID))
+}
+
+// linkLocation закрепляет локацию за провайдером: /link_location <ID локации> <ID пользователя>.
+func (a *app) linkLocation(c *bot.Context) error {
+	args := strings.Fields(c.Args())
+	if len(args) != 2 {
+		return c.Reply(c.T("provider.link_usage"))
+	}
+	locationID, err1 := strconv.Atoi(args[0])
+	providerID, err2 := strconv.Atoi(args[1])
+	if err1 != nil || err2 != nil {
+		return c.Reply(c.T("provider.link_usage"))
+	}
+	loc, err := a.providers.LinkLocation(c, c.User.ID, locationID, providerID)
+	if err != nil {
+		return c.Reply(c.T("provider.decision_failed", "err", err.Error()))
+	}
+	return c.Reply(c.T("provider.linked", "name", loc.Name, "user_id", providerID))
+}
+
+// sendPhotos отправляет фото на проверку поддержке: одно — отдельным сообщением, несколько — альбомом.
+func sendPhotos(c *bot.Context, fileIDs []string) {
+	switch len(fileIDs) {
+	case 0:
+	case 1:
+		c.Send(tgbotapi.NewPhoto(c.ChatID, tgbotapi.FileID(fileIDs[0])))
+	default:
+		files := []interface{}{}
+		for _, id := range fileIDs {
+			files = append(files, tgbotapi.NewInputMediaPhoto(tgbotapi.FileID(id)))
+		}
+		c.SendMediaGroup(tgbotapi.NewMediaGroup(c.ChatID, files))
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"tourism/internal/bot"
+	"tourism/internal/model"
+	"tourism/internal/service"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// сценарий бронирования: ожидаются даты и количество участников
+const flowBooking = "booking"
+
+// bookingPayload — данные сценария бронирования.
+type bookingPayload struct {
+	OfferID int `json:"offer_id"`
+}
+
+// bookingCategory предлагает выбрать тип бронирования.
+func (a *app) bookingCategory(c *bot.Context) error {
+	kbd := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(c.T("booking.type_housing"), "BOOKING_TYPE_housing"),
+			tgbotapi.NewInlineKeyboardButtonData(c.T("booking.type_tour"), "BOOKING_TYPE_tour"),
+		),
+	)
+	msg := tgbotapi.NewMessage(c.ChatID, c.T("booking.choose_type"))
+	msg.ReplyMarkup = kbd
+	_, err := c.Send(msg)
+	return err
+}
+
+// bookingOffers показывает предложения выбранного типа.
+func (a *app) bookingOffers(c *bot.Context) error {
+	offers, err := a.offers.ListOffers(c, c.Param())
+	if err != nil {
+		return err
+	}
+	if len(offers) == 0 {
+		return c.Reply(c.T("booking.no_offers"))
+	}
+	a.localizeOffers(c, offers)
+	for _, o := range offers {
+		photo := tgbotapi.NewPhoto(c.ChatID, tgbotapi.FileID(o.PhotoFileID))
+		photo.Caption = c.T("booking.offer",
+			"name", o.Name, "description", o.Description, "price", o.Price, "contact", o.Contact, "links", o.SocialLinks,
+		)
+		photo.ParseMode = tgbotapi.ModeMarkdownV2
+		btn := tgbotapi.NewInlineKeyboardButtonData(
+			c.T("booking.book"), fmt.Sprintf("BOOK_OFFER_%d", o.ID),
+		)
+		rows := [][]tgbotapi.InlineKeyboardButton{tgbotapi.NewInlineKeyboardRow(btn)}
+		if a.canTranslateOffer(c, &o) {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(translateButton(c, model.TranslationOffer, o.ID)))
+		}
+		photo.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+		c.Send(photo)
+	}
+	return nil
+}
+
+// bookOffer запрашивает детали брони выбранного предложения.
+func (a *app) bookOffer(c *bot.Context) error {
+	id, err := c.IntParam()
+	if err != nil {
+		return err
+	}
+	if err := c.SetState(flowBooking, "", bookingPayload{OfferID: id}); err != nil {
+		return err
+	}
+	return c.Reply(c.T("booking.details_prompt"))
+}
+
+// bookingDetails создает заявку; уведомление провайдеру отправляется из очереди уведомлений.
+func (a *app) bookingDetails(c *bot.Context) error {
+	var p bookingPayload
+	if err := c.Payload(&p); err != nil {
+		return err
+	}
+	text := c.Text()
+	if strings.TrimSpace(text) == "" {
+		return c.Reply(c.T("booking.details_empty"))
+	}
+	if err := c.ClearState(); err != nil {
+		return err
+	}
+	// бронь оформляется от внутреннего пользователя на локацию предложения
+	// скрытое провайдером предложение забронировать нельзя
+	o, err := a.offers.GetOffer(c, p.OfferID)
+	if err != nil || o.Hidden {
+		return c.Reply(c.T("booking.create_failed"))
+	}
+	bookID, err := a.bookings.CreateBooking(c, c.User.ID, o.LocationID, text)
+	if err != nil {
+		slog.ErrorContext(c, "Не удалось создать бронь", "offer_id", o.ID, "err", err)
+		return c.Reply(c.T("booking.create_failed"))
+	}
+	return c.Reply(c.T("booking.created", "id", bookID))
+}
+
+// bookingDecision обрабатывает подтверждение или отказ провайдера; турист узнает о решении
+// из очереди уведомлений. Данные кнопки присылает клиент, поэтому право на решение и текущий
+// статус брони проверяет сервис: чужая бронь выглядит для провайдера несуществующей.
+func (a *app) bookingDecision(c *bot.Context) error {
+	parts := strings.Split(c.Text(), "_")
+	action := parts[0]
+	bID, err := c.IntParam()
+	if err != nil {
+		return err
+	}
+	if action == "CONFIRM" {
+		err = a.bookings.ConfirmBooking(c, c.User.ID, bID)
+	} else {
+		err = a.bookings.RejectBooking(c, c.User.ID, bID)
+	}
+	if errors.Is(err, sql.ErrNoRows) || errors.Is(err, service.ErrNotBookingProvider) {
+		return c.Reply(c.T("booking.not_found"))
+	} else if errors.Is(err, service.ErrBookingStatus) {
+		return c.Reply(c.T("booking.already_decided", "id", bID))
+	} else if err != nil {
+		return fmt.Errorf("смена статуса брони #%d: %w", bID, err)
+	}
+	if action == "CONFIRM" {
+		return c.Reply(c.T("booking.confirmed_by_provider", "id", bID))
+	}
+	return c.Reply(c.T("booking.rejected_by_provider", "id", bID))
+}
+
+// providerBookings показывает провайдеру последние заявки на его локации.
+func (a *app) providerBookings(c *bot.Context) error {
+	bookings, err := a.bookings.ListProviderBookings(c, c.User.ID)
+	if err != nil {
+		return err
+	}
+	if len(bookings) == 0 {
+		return c.Reply(c.T("booking.provider_empty"))
+	}
+	for _, bk := range bookings {
+		location := ""
+		if loc, err := a.locRepo.GetByID(c, bk.LocationID); err == nil {
+			a.localizeLocation(c, loc)
+			location = loc.Name
+		} else {
+			slog.WarnContext(c, "Локация брони не найдена", "booking_id", bk.ID, "location_id", bk.LocationID, "err", err)
+		}
+		msg := tgbotapi.NewMessage(c.ChatID, c.T("booking.provider_item",
+			"id", bk.ID, "location", location, "status", c.T("booking.status."+bk.Status), "details", bk.Details))
+		if bk.Status == "pending" {
+			msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(c.T("booking.confirm"), fmt.Sprintf("CONFIRM_%d", bk.ID)),
+				tgbotapi.NewInlineKeyboardButtonData(c.T("booking.reject"), fmt.Sprintf("REJECT_%d", bk.ID)),
+			))
+		} else if bk.Status == "confirmed" {
+			msg.Text += "\n" + c.T("booking.chat_hint", "id", bk.ID)
+		}
+		c.Send(msg)
+	}
+	return nil
+}
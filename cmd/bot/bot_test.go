@@ -0,0 +1,283 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"tourism/internal/bot"
+	"tourism/internal/i18n"
+	"tourism/internal/model"
+	"tourism/internal/repository/memory"
+	"tourism/internal/service"
+	"tourism/internal/staticmap"
+	"tourism/internal/telegram/telegramtest"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// сколько ждать ответа бота на обновление
+const replyTimeout = 5 * time.Second
+
+// testBot — основной бот поверх хранилищ в памяти, подключенный к поддельному Bot API.
+// Обновления проходят тот же путь, что и в main: getUpdates, диспетчер, роутер с middleware.
+type testBot struct {
+	t         *testing.T
+	server    *telegramtest.Server
+	texts     *i18n.Registry
+	app       *app
+	users     *memory.UserRepository
+	locations *memory.LocationRepository
+	offers    *memory.OfferRepository
+	bookings  *memory.BookingRepository
+}
+
+func newTestBot(t *testing.T) *testBot {
+	s := memory.NewStore()
+	users := memory.NewUserRepository(s)
+	messages := memory.NewMessageRepository(s)
+	locations := memory.NewLocationRepository(s)
+	trips := memory.NewTripRepository(s)
+	bookings := memory.NewBookingRepository(s)
+	offers := memory.NewOfferRepository(s)
+	subscriptions := memory.NewSubscriptionRepository(s)
+	notifications := memory.NewNotificationRepository(s)
+	states := memory.NewStateRepository(s)
+	texts, err := i18n.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	renderer, err := staticmap.NewRenderer(staticmap.DefaultConfig(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	translations := service.NewTranslationService(s, memory.NewTranslationRepository(s), locations, offers, users, notifications)
+	a := &app{
+		users:        users,
+		accounts:     service.NewUserService(users),
+		messages:     messages,
+		locRepo:      locations,
+		locations:    service.NewLocationService(locations),
+		trips:        service.NewTripService(trips, locations),
+		bookings:     service.NewBookingService(s, bookings, locations, users, notifications),
+		chat:         service.NewChatService(bookings, users, locations, states),
+		offers:       service.NewOfferService(subscriptions, offers, locations),
+		reviews:      service.NewReviewService(s, memory.NewReviewRepository(s), locations, users, notifications, service.DefaultModerationRules()),
+		translations: translations,
+		maps:         service.NewMapService(trips, renderer),
+		supportBot:   "support_bot",
+	}
+	router := bot.NewRouter(states, texts, time.Hour)
+	router.Use(bot.Recover(), bot.Logger(), bot.Auth(service.NewAuthService(users)))
+	a.register(router)
+
+	server := telegramtest.NewServer("123:test")
+	api, err := server.NewBot()
+	if err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		router.Run(ctx, api, api, bot.DispatcherConfig{ShutdownTimeout: time.Second})
+	}()
+	t.Cleanup(func() {
+		cancel()
+		server.Close()
+		<-done
+	})
+	return &testBot{t: t, server: server, texts: texts, app: a, users: users, locations: locations, offers: offers, bookings: bookings}
+}
+
+// newUser создает пользователя с ролью role и русским языком интерфейса и возвращает его
+// вместе с отправителем для обновлений.
+func (b *testBot) newUser(telegramID int64, role, name string) (*model.User, tgbotapi.User) {
+	b.t.Helper()
+	u := &model.User{TelegramID: telegramID, FirstName: name, Role: role, LanguageCode: "ru", Language: "ru",
+		IsActive: true, CreatedAt: time.Now()}
+	id, err := b.users.Create(context.Background(), u)
+	if err != nil {
+		b.t.Fatal(err)
+	}
+	u.ID = id
+	return u, tgbotapi.User{ID: telegramID, FirstName: name, LanguageCode: "ru"}
+}
+
+// newOffer создает локацию провайдера providerID с предложением жилья и возвращает предложение.
+func (b *testBot) newOffer(providerID int) *model.Offer {
+	b.t.Helper()
+	ctx := context.Background()
+	loc := &model.Location{Name: "Цейское ущелье", Description: "Ущелье в горах", Category: "nature",
+		Region: "Алагир", Latitude: 42.79, Longitude: 43.9, ProviderID: &providerID}
+	locID, err := b.locations.Create(ctx, loc)
+	if err != nil {
+		b.t.Fatal(err)
+	}
+	o := &model.Offer{LocationID: locID, Type: model.TopicHousing, Name: "Гостевой дом", Price: 3500}
+	if o.ID, err = b.offers.Create(ctx, o); err != nil {
+		b.t.Fatal(err)
+	}
+	return o
+}
+
+// text возвращает сообщение key на русском языке.
+func (b *testBot) text(key string, args ...any) string {
+	return b.texts.Text("ru", key, args...)
+}
+
+// expect ждет, что бот отправит в чат chatID сообщение с текстом text.
+func (b *testBot) expect(chatID int64, text string) {
+	b.t.Helper()
+	_, err := b.server.WaitCall(replyTimeout, func(c telegramtest.Call) bool {
+		return c.ChatID == chatID && c.Text == text
+	})
+	if err != nil {
+		b.t.Fatalf("нет сообщения %q в чат %d: %v; отправлено: %+v", text, chatID, err, b.server.CallsTo(chatID))
+	}
+}
+
+// bookingStatus возвращает текущий статус брони.
+func (b *testBot) bookingStatus(id int) string {
+	b.t.Helper()
+	bk, err := b.bookings.GetByID(context.Background(), id)
+	if err != nil {
+		b.t.Fatal(err)
+	}
+	return bk.Status
+}
+
+func TestBookingFlow(t *testing.T) {
+	b := newTestBot(t)
+	tourist, touristTG := b.newUser(1001, "user", "Алан")
+	provider, providerTG := b.newUser(2001, "provider", "Заур")
+	_, strangerTG := b.newUser(2002, "provider", "Батраз")
+	offer := b.newOffer(provider.ID)
+
+	b.server.Press(touristTG, 1, fmt.Sprintf("BOOK_OFFER_%d", offer.ID))
+	b.expect(touristTG.ID, b.text("booking.details_prompt"))
+	b.server.SendText(touristTG, "12–14 июля, двое")
+	b.expect(touristTG.ID, b.text("booking.created", "id", 1))
+	bk, err := b.bookings.GetByID(context.Background(), 1)
+	if err != nil || bk.UserID != tourist.ID || bk.LocationID != offer.LocationID || bk.Status != "pending" {
+		t.Fatalf("бронь: %+v, %v", bk, err)
+	}
+
+	// данные кнопки присылает клиент: чужой провайдер и турист не могут принять решение
+	b.server.Press(strangerTG, 2, "CONFIRM_1")
+	b.expect(strangerTG.ID, b.text("booking.not_found"))
+	b.server.Press(touristTG, 3, "CONFIRM_1")
+	b.expect(touristTG.ID, b.text("bot.role_required.provider"))
+	if status := b.bookingStatus(1); status != "pending" {
+		t.Fatalf("статус после чужих решений %q, ожидался pending", status)
+	}
+
+	b.server.Press(providerTG, 4, "CONFIRM_1")
+	b.expect(providerTG.ID, b.text("booking.confirmed_by_provider", "id", 1))
+	// повторное решение по обработанной брони не меняет статус
+	b.server.Press(providerTG, 5, "REJECT_1")
+	b.expect(providerTG.ID, b.text("booking.already_decided", "id", 1))
+	if status := b.bookingStatus(1); status != "confirmed" {
+		t.Fatalf("статус %q, ожидался confirmed", status)
+	}
+}
+
+func TestChatFlow(t *testing.T) {
+	b := newTestBot(t)
+	tourist, touristTG := b.newUser(1001, "user", "Алан")
+	provider, providerTG := b.newUser(2001, "provider", "Заур")
+	offer := b.newOffer(provider.ID)
+	ctx := context.Background()
+	bookingID, err := b.bookings.Create(ctx, &model.Booking{UserID: tourist.ID, LocationID: offer.LocationID, Status: "confirmed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b.server.SendText(touristTG, fmt.Sprintf("/chat %d", bookingID))
+	b.expect(providerTG.ID, b.text("chat.started_partner", "name", "Алан", "booking_id", bookingID))
+	b.expect(touristTG.ID, b.text("chat.started", "booking_id", bookingID))
+
+	b.server.SendText(touristTG, "Когда можно заселиться?")
+	b.expect(providerTG.ID, "Алан: Когда можно заселиться?")
+	b.server.SendText(providerTG, "С 14:00")
+	b.expect(touristTG.ID, "Заур: С 14:00")
+
+	// чат хранится в состоянии диалогов, а не в памяти обработчика
+	if partner, err := b.app.chat.GetChatPartner(ctx, touristTG.ID); err != nil || partner != providerTG.ID {
+		t.Fatalf("собеседник туриста: %d, %v", partner, err)
+	}
+	if id, err := b.app.chat.GetChatBookingID(ctx, providerTG.ID); err != nil || id != bookingID {
+		t.Fatalf("бронь чата провайдера: %d, %v", id, err)
+	}
+
+	b.server.SendText(providerTG, "/exit")
+	b.expect(providerTG.ID, b.text("chat.ended"))
+	b.expect(touristTG.ID, b.text("chat.partner_left"))
+	if partner, err := b.app.chat.GetChatPartner(ctx, touristTG.ID); err != nil || partner != 0 {
+		t.Fatalf("собеседник туриста после выхода: %d, %v", partner, err)
+	}
+}
+
+func TestChatRequiresConfirmedBooking(t *testing.T) {
+	b := newTestBot(t)
+	tourist, touristTG := b.newUser(1001, "user", "Алан")
+	provider, providerTG := b.newUser(2001, "provider", "Заур")
+	offer := b.newOffer(provider.ID)
+	bookingID, err := b.bookings.Create(context.Background(), &model.Booking{UserID: tourist.ID, LocationID: offer.LocationID, Status: "pending"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b.server.SendText(touristTG, fmt.Sprintf("/chat %d", bookingID))
+	b.expect(touristTG.ID, b.text("chat.not_confirmed"))
+	if calls := b.server.CallsTo(providerTG.ID); len(calls) != 0 {
+		t.Fatalf("провайдеру отправлены сообщения: %+v", calls)
+	}
+}
+
+func TestSupportLink(t *testing.T) {
+	b := newTestBot(t)
+	_, touristTG := b.newUser(1001, "user", "Алан")
+
+	b.server.SendText(touristTG, "/support")
+	b.expect(touristTG.ID, b.text("support.link", "bot", "support_bot"))
+}
+
+func TestTripReviewFlow(t *testing.T) {
+	b := newTestBot(t)
+	provider, _ := b.newUser(2001, "provider", "Заур")
+	tourist, touristTG := b.newUser(1001, "user", "Алан")
+	locID := b.newOffer(provider.ID).LocationID
+
+	// пока маршрут не завершен, отзыв оставить нельзя
+	b.server.SendText(touristTG, "/newtrip Выходные в горах")
+	b.expect(touristTG.ID, b.text("trip.created", "name", "Выходные в горах"))
+	b.server.SendText(touristTG, "/finish_trip")
+	b.expect(touristTG.ID, b.text("trip.empty"))
+	b.server.Press(touristTG, 1, fmt.Sprintf("ADDTRIP_%d", locID))
+	b.expect(touristTG.ID, b.text("trip.location_added", "name", "Выходные в горах"))
+	denied := b.app.reviews.CheckCanReview(context.Background(), tourist.ID, locID)
+	if denied == nil {
+		t.Fatal("черновик маршрута дает право на отзыв")
+	}
+	b.server.Press(touristTG, 2, fmt.Sprintf("REVIEW_NEW_%d", locID))
+	b.expect(touristTG.ID, denied.Error())
+
+	b.server.SendText(touristTG, "/finish_trip")
+	b.expect(touristTG.ID, b.text("trip.completed", "name", "Выходные в горах"))
+	b.server.SendText(touristTG, "/finish_trip")
+	b.expect(touristTG.ID, b.text("trip.none"))
+
+	b.server.Press(touristTG, 3, fmt.Sprintf("REVIEW_NEW_%d", locID))
+	b.expect(touristTG.ID, b.text("review.rate_prompt"))
+	b.server.Press(touristTG, 4, fmt.Sprintf("REVIEW_RATE_%d_5", locID))
+	b.expect(touristTG.ID, b.text("review.text_prompt"))
+	b.server.SendText(touristTG, "Красивое ущелье")
+	b.expect(touristTG.ID, b.text("review.saved", "pending", false))
+	reviews, total, err := b.app.reviews.ListReviews(context.Background(), locID, 10, 0)
+	if err != nil || total != 1 || reviews[0].Text != "Красивое ущелье" || reviews[0].Rating != 5 {
+		t.Fatalf("отзывы: %+v, %d, %v", reviews, total, err)
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"tourism/internal/bot"
+	"tourism/internal/broadcast"
+	"tourism/internal/model"
+	"tourism/internal/service"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// шаги диалога подготовки рассылки
+const (
+	broadcastStepContent  = "content"
+	broadcastStepSegment  = "segment"
+	broadcastStepSchedule = "schedule"
+)
+
+// сценарий подготовки рассылки оператором: шаги broadcastStep*
+const flowBroadcast = "broadcast"
+
+// broadcastPayload — данные сценария рассылки.
+type broadcastPayload struct {
+	CampaignID int `json:"campaign_id,omitempty"`
+}
+
+// часовой пояс, в котором оператор указывает время отправки
+var broadcastZone = time.FixedZone("MSK", 3*60*60)
+
+// campaignPanel формирует панель управления рассылкой для оператора.
+// stats передается для рассылок, которые уже отправляются или отправлены.
+func campaignPanel(ctx *bot.Context, c *model.Campaign, audience int, stats *model.DeliveryStats) tgbotapi.MessageConfig {
+	scheduled := ""
+	if c.ScheduledAt != nil {
+		scheduled = c.ScheduledAt.In(broadcastZone).Format("2006-01-02 15:04")
+	}
+	text := ctx.T("broadcast.panel", "id", c.ID, "status", c.Status, "segment", c.Segment.String(),
+		"audience", audience, "scheduled", scheduled)
+	if stats != nil {
+		text += "\n" + broadcast.FormatStats(ctx.Texts, ctx.Locale(), stats)
+	}
+	msg := tgbotapi.NewMessage(ctx.ChatID, text)
+	button := func(key, action string) tgbotapi.InlineKeyboardButton {
+		return tgbotapi.NewInlineKeyboardButtonData(ctx.T(key), fmt.Sprintf("BC_%s_%d", action, c.ID))
+	}
+	if c.Status == "draft" {
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(button("broadcast.audience", "AUDIENCE"), button("broadcast.preview", "PREVIEW")),
+			tgbotapi.NewInlineKeyboardRow(button("broadcast.schedule", "SCHEDULE"), button("broadcast.send", "SEND")),
+			tgbotapi.NewInlineKeyboardRow(button("broadcast.cancel", "CANCEL")),
+		)
+	} else if c.Status == "scheduled" {
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(button("broadcast.cancel", "CANCEL")),
+		)
+	} else if c.Status == "sending" || c.Status == "sent" {
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(button("broadcast.refresh_stats", "STATS")),
+		)
+	}
+	return msg
+}
+
+// sendCampaignPreview отправляет оператору рассылку в том виде, в котором ее увидят подписчики, и панель управления.
+func sendCampaignPreview(ctx *bot.Context, svc *service.BroadcastService, campaignID int) {
+	c, err := svc.GetCampaign(ctx, campaignID)
+	if err != nil {
+		ctx.Reply(ctx.T("broadcast.not_found"))
+		return
+	}
+	audience, err := svc.Audience(ctx, c.Segment)
+	if err != nil {
+		slog.ErrorContext(ctx, "Не удалось посчитать аудиторию рассылки", "campaign_id", c.ID, "err", err)
+	}
+	if _, err := ctx.Send(broadcast.CampaignMessage(ctx.ChatID, c)); err != nil {
+		ctx.Reply(ctx.T("broadcast.preview_failed", "err", err.Error()))
+	}
+	ctx.Send(campaignPanel(ctx, c, len(audience), nil))
+}
+
+// sendCampaignStats отправляет оператору текущую статистику доставки рассылки.
+func sendCampaignStats(ctx *bot.Context, svc *service.BroadcastService, campaignID int) {
+	c, err := svc.GetCampaign(ctx, campaignID)
+	if err != nil {
+		ctx.Reply(ctx.T("broadcast.not_found"))
+		return
+	}
+	stats, err := svc.Stats(ctx, campaignID)
+	if err != nil {
+		ctx.Reply(err.Error())
+		return
+	}
+	total := stats.Pending + stats.Sent + stats.Failed + stats.Blocked
+	ctx.Send(campaignPanel(ctx, c, total, stats))
+}
+
+// ошибки ввода времени отправки; текст ошибки — идентификатор сообщения оператору
+var (
+	errScheduleFormat = errors.New("broadcast.schedule_format")
+	errSchedulePast   = errors.New("broadcast.schedule_past")
+)
+
+// parseScheduleTime разбирает время отправки в формате "2006-01-02 15:04" (МСК).
+func parseScheduleTime(input string, now time.Time) (time.Time, error) {
+	at, err := time.ParseInLocation("2006-01-02 15:04", strings.TrimSpace(input), broadcastZone)
+	if err != nil {
+		return time.Time{}, errScheduleFormat
+	}
+	if at.Before(now) {
+		return time.Time{}, errSchedulePast
+	}
+	return at, nil
+}
+
+// broadcastStart начинает подготовку рассылки.
+func (a *app) broadcastStart(c *bot.Context) error {
+	if err := c.SetState(flowBroadcast, broadcastStepContent, nil); err != nil {
+		return err
+	}
+	return c.Reply(c.T("broadcast.content_prompt"))
+}
+
+// broadcastAction обрабатывает кнопки панели управления рассылкой (BC_<действие>_<ID>).
+func (a *app) broadcastAction(c *bot.Context) error {
+	parts := strings.Split(c.Text(), "_")
+	if len(parts) != 3 {
+		return nil
+	}
+	var campaignID int
+	if _, err := fmt.Sscan(parts[2], &campaignID); err != nil {
+		return fmt.Errorf("некорректный ID рассылки %q: %w", parts[2], err)
+	}
+	switch parts[1] {
+	case "AUDIENCE":
+		if err := c.SetState(flowBroadcast, broadcastStepSegment, broadcastPayload{CampaignID: campaignID}); err != nil {
+			return err
+		}
+		return c.Reply(c.T("broadcast.segment_prompt"))
+	case "SCHEDULE":
+		if err := c.SetState(flowBroadcast, broadcastStepSchedule, broadcastPayload{CampaignID: campaignID}); err != nil {
+			return err
+		}
+		return c.Reply(c.T("broadcast.schedule_prompt"))
+	case "PREVIEW":
+		sendCampaignPreview(c, a.broadcasts, campaignID)
+	case "STATS":
+		sendCampaignStats(c, a.broadcasts, campaignID)
+	case "SEND":
+		if err := a.broadcasts.SendNow(c, campaignID); err != nil {
+			return c.Reply(err.Error())
+		}
+		return c.Reply(c.T("broadcast.queued", "id", campaignID))
+	case "CANCEL":
+		if c.State != nil && c.State.Flow == flowBroadcast {
+			if err := c.ClearState(); err != nil {
+				return err
+			}
+		}
+		if err := a.broadcasts.Cancel(c, campaignID); err != nil {
+			return c.Reply(err.Error())
+		}
+		return c.Reply(c.T("broadcast.cancelled", "id", campaignID))
+	}
+	return nil
+}
+
+// broadcastInput обрабатывает ответы оператора: текст рассылки, аудиторию и время отправки.
+// При ошибке ввода оператор остается на том же шаге и может исправить ответ.
+func (a *app) broadcastInput(c *bot.Context) error {
+	var p broadcastPayload
+	if err := c.Payload(&p); err != nil {
+		return err
+	}
+	switch c.State.Step {
+	case broadcastStepContent:
+		camp, err := a.broadcasts.CreateDraft(c, c.User.ID, c.Text(), c.PhotoID())
+		if err != nil {
+			return c.Reply(c.T("broadcast.input_error", "err", err.Error()))
+		}
+		p.CampaignID = camp.ID
+	case broadcastStepSegment:
+		segment, err := service.ParseSegment(c.Text())
+		if err != nil {
+			return c.Reply(c.T("broadcast.input_error", "err", err.Error()))
+		}
+		if err := a.broadcasts.SetSegment(c, p.CampaignID, segment); err != nil {
+			c.ClearState()
+			return c.Reply(err.Error())
+		}
+	case broadcastStepSchedule:
+		at, err := parseScheduleTime(c.Text(), time.Now())
+		if err != nil {
+			return c.Reply(c.T("broadcast.input_error", "err", c.T(err.Error())))
+		}
+		if err := a.broadcasts.Schedule(c, p.CampaignID, at); err != nil {
+			c.ClearState()
+			return c.Reply(err.Error())
+		}
+	}
+	if err := c.ClearState(); err != nil {
+		return err
+	}
+	sendCampaignPreview(c, a.broadcasts, p.CampaignID)
+	return nil
+}
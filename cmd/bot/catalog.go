@@ -0,0 +1,513 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"tourism/internal/bot"
+	"tourism/internal/model"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/lib/pq"
+)
+
+// сценарий создания и редактирования локации или предложения провайдером
+const flowCatalog = "catalog"
+
+// шаги сценария каталога
+const (
+	catalogStepType        = "type"        // тип предложения (кнопками)
+	catalogStepName        = "name"        // название
+	catalogStepDescription = "description" // описание
+	catalogStepCategory    = "category"    // категория локации
+	catalogStepRegion      = "region"      // регион локации
+	catalogStepCoordinates = "coordinates" // геопозиция локации
+	catalogStepPrice       = "price"       // цена предложения
+	catalogStepContact     = "contact"     // контакт для бронирования
+	catalogStepPhotos      = "photos"      // фото
+)
+
+// порядок шагов для локаций и предложений
+var (
+	locationSteps = []string{catalogStepName, catalogStepDescription, catalogStepCategory, catalogStepRegion,
+		catalogStepCoordinates, catalogStepPhotos}
+	offerSteps = []string{catalogStepType, catalogStepName, catalogStepDescription, catalogStepPrice,
+		catalogStepContact, catalogStepPhotos}
+)
+
+// шаги, которые можно пропустить и у новой записи
+var optionalSteps = map[string]bool{catalogStepDescription: true, catalogStepContact: true, catalogStepPhotos: true}
+
+// сколько фото можно приложить за один раз: к локации — несколько, у предложения фото одно
+const (
+	catalogLocationPhotos = 10
+	catalogOfferPhotos    = 1
+)
+
+// catalogPayload — данные сценария каталога: все поля записи, у редактируемой заполненные текущими значениями.
+type catalogPayload struct {
+	Entity      string   `json:"entity"`
+	EntityID    int      `json:"entity_id,omitempty"`   // 0 для новой записи
+	LocationID  int      `json:"location_id,omitempty"` // локация нового предложения
+	Name        string   `json:"name,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Category    string   `json:"category,omitempty"`
+	Region      string   `json:"region,omitempty"`
+	Latitude    float64  `json:"latitude,omitempty"`
+	Longitude   float64  `json:"longitude,omitempty"`
+	OfferType   string   `json:"offer_type,omitempty"`
+	Price       float64  `json:"price,omitempty"`
+	Contact     string   `json:"contact,omitempty"`
+	Photos      []string `json:"photos,omitempty"`
+}
+
+func (p *catalogPayload) steps() []string {
+	if p.Entity == model.ContentOffer {
+		return offerSteps
+	}
+	return locationSteps
+}
+
+func (p *catalogPayload) photoLimit() int {
+	if p.Entity == model.ContentOffer {
+		return catalogOfferPhotos
+	}
+	return catalogLocationPhotos
+}
+
+// current возвращает текущее значение поля шага для подсказки и проверки обязательных полей.
+func (p *catalogPayload) current(c *bot.Context, step string) string {
+	switch step {
+	case catalogStepType:
+		if p.OfferType != "" {
+			return c.T("subscription.topic." + p.OfferType)
+		}
+	case catalogStepName:
+		return p.Name
+	case catalogStepDescription:
+		return p.Description
+	case catalogStepCategory:
+		return p.Category
+	case catalogStepRegion:
+		return p.Region
+	case catalogStepCoordinates:
+		if p.Latitude != 0 || p.Longitude != 0 {
+			return fmt.Sprintf("%.6f, %.6f", p.Latitude, p.Longitude)
+		}
+	case catalogStepPrice:
+		if p.EntityID != 0 {
+			return strconv.FormatFloat(p.Price, 'f', -1, 64)
+		}
+	case catalogStepContact:
+		return p.Contact
+	}
+	return ""
+}
+
+// myCatalog показывает провайдеру его локации с кнопками управления.
+func (a *app) myCatalog(c *bot.Context) error {
+	locations, err := a.content.ProviderLocations(c, c.User.ID)
+	if err != nil {
+		return err
+	}
+	rows := [][]tgbotapi.InlineKeyboardButton{}
+	for _, loc := range locations {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(hiddenLabel(c, loc.Name, loc.Hidden), fmt.Sprintf("CAT_LOC_%d", loc.ID)),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(c.T("catalog.new_location"), "CAT_NEWLOC"),
+	))
+	title := c.T("catalog.my_locations")
+	if len(locations) == 0 {
+		title = c.T("catalog.no_locations")
+	}
+	msg := tgbotapi.NewMessage(c.ChatID, title)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	_, err = c.Send(msg)
+	return err
+}
+
+// hiddenLabel помечает надпись кнопки скрытой записи.
+func hiddenLabel(c *bot.Context, name string, hidden bool) string {
+	if hidden {
+		return c.T("catalog.hidden_label", "name", name)
+	}
+	return name
+}
+
+// catalogLocation показывает карточку управления локацией.
+func (a *app) catalogLocation(c *bot.Context) error {
+	id, err := c.IntParam()
+	if err != nil {
+		return err
+	}
+	loc, err := a.content.OwnLocation(c, c.User.ID, id)
+	if err != nil {
+		return c.Reply(c.T("catalog.failed", "err", err.Error()))
+	}
+	return a.sendCatalogLocation(c, loc)
+}
+
+func (a *app) sendCatalogLocation(c *bot.Context, loc *model.Location) error {
+	msg := tgbotapi.NewMessage(c.ChatID, c.T("catalog.location_card",
+		"name", loc.Name, "description", loc.Description, "category", loc.Category, "region", loc.Region,
+		"latitude", fmt.Sprintf("%.6f", loc.Latitude), "longitude", fmt.Sprintf("%.6f", loc.Longitude),
+		"rating", loc.Rating, "hidden", loc.Hidden))
+	visibility := tgbotapi.NewInlineKeyboardButtonData(c.T("catalog.hide"), fmt.Sprintf("CAT_HIDELOC_%d", loc.ID))
+	if loc.Hidden {
+		visibility = tgbotapi.NewInlineKeyboardButtonData(c.T("catalog.show"), fmt.Sprintf("CAT_SHOWLOC_%d", loc.ID))
+	}
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(c.T("catalog.edit"), fmt.Sprintf("CAT_EDITLOC_%d", loc.ID)),
+			visibility,
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(c.T("catalog.offers"), fmt.Sprintf("CAT_OFFERS_%d", loc.ID)),
+			galleryButton(c, loc.ID),
+		),
+	)
+	_, err := c.Send(msg)
+	return err
+}
+
+// catalogLocationVisibility скрывает локацию или снова показывает ее.
+func (a *app) catalogLocationVisibility(c *bot.Context) error {
+	id, err := c.IntParam()
+	if err != nil {
+		return err
+	}
+	hidden := strings.HasPrefix(c.Text(), "CAT_HIDELOC_")
+	loc, err := a.content.SetLocationHidden(c, c.User.ID, id, hidden)
+	if err != nil {
+		return c.Reply(c.T("catalog.failed", "err", err.Error()))
+	}
+	return a.sendCatalogLocation(c, loc)
+}
+
+// catalogOffers показывает предложения локации провайдера.
+func (a *app) catalogOffers(c *bot.Context) error {
+	locationID, err := c.IntParam()
+	if err != nil {
+		return err
+	}
+	offers, err := a.content.LocationOffers(c, c.User.ID, locationID)
+	if err != nil {
+		return c.Reply(c.T("catalog.failed", "err", err.Error()))
+	}
+	rows := [][]tgbotapi.InlineKeyboardButton{}
+	for _, o := range offers {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(hiddenLabel(c, o.Name, o.Hidden), fmt.Sprintf("CAT_OFFER_%d", o.ID)),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(c.T("catalog.new_offer"), fmt.Sprintf("CAT_NEWOFFER_%d", locationID)),
+	))
+	title := c.T("catalog.offers_title")
+	if len(offers) == 0 {
+		title = c.T("catalog.no_offers")
+	}
+	msg := tgbotapi.NewMessage(c.ChatID, title)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	_, err = c.Send(msg)
+	return err
+}
+
+// catalogOffer показывает карточку управления предложением.
+func (a *app) catalogOffer(c *bot.Context) error {
+	id, err := c.IntParam()
+	if err != nil {
+		return err
+	}
+	offer, err := a.content.OwnOffer(c, c.User.ID, id)
+	if err != nil {
+		return c.Reply(c.T("catalog.failed", "err", err.Error()))
+	}
+	return a.sendCatalogOffer(c, offer)
+}
+
+func (a *app) sendCatalogOffer(c *bot.Context, o *model.Offer) error {
+	if o.PhotoFileID != "" {
+		c.Send(tgbotapi.NewPhoto(c.ChatID, tgbotapi.FileID(o.PhotoFileID)))
+	}
+	msg := tgbotapi.NewMessage(c.ChatID, c.T("catalog.offer_card",
+		"type", c.T("subscription.topic."+o.Type), "name", o.Name, "description", o.Description,
+		"price", o.Price, "contact", o.Contact, "hidden", o.Hidden))
+	visibility := tgbotapi.NewInlineKeyboardButtonData(c.T("catalog.hide"), fmt.Sprintf("CAT_HIDEOFFER_%d", o.ID))
+	if o.Hidden {
+		visibility = tgbotapi.NewInlineKeyboardButtonData(c.T("catalog.show"), fmt.Sprintf("CAT_SHOWOFFER_%d", o.ID))
+	}
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(c.T("catalog.edit"), fmt.Sprintf("CAT_EDITOFFER_%d", o.ID)),
+		visibility,
+	))
+	_, err := c.Send(msg)
+	return err
+}
+
+// catalogOfferVisibility скрывает предложение или снова показывает его.
+func (a *app) catalogOfferVisibility(c *bot.Context) error {
+	id, err := c.IntParam()
+	if err != nil {
+		return err
+	}
+	hidden := strings.HasPrefix(c.Text(), "CAT_HIDEOFFER_")
+	offer, err := a.content.SetOfferHidden(c, c.User.ID, id, hidden)
+	if err != nil {
+		return c.Reply(c.T("catalog.failed", "err", err.Error()))
+	}
+	return a.sendCatalogOffer(c, offer)
+}
+
+// catalogNewLocation начинает создание локации.
+func (a *app) catalogNewLocation(c *bot.Context) error {
+	return a.catalogStart(c, catalogPayload{Entity: model.ContentLocation})
+}
+
+// catalogEditLocation начинает редактирование локации с ее текущими значениями.
+func (a *app) catalogEditLocation(c *bot.Context) error {
+	id, err := c.IntParam()
+	if err != nil {
+		return err
+	}
+	loc, err := a.content.OwnLocation(c, c.User.ID, id)
+	if err != nil {
+		return c.Reply(c.T("catalog.failed", "err", err.Error()))
+	}
+	return a.catalogStart(c, catalogPayload{Entity: model.ContentLocation, EntityID: loc.ID, Name: loc.Name,
+		Description: loc.Description, Category: loc.Category, Region: loc.Region, Latitude: loc.Latitude,
+		Longitude: loc.Longitude})
+}
+
+// catalogNewOffer начинает создание предложения у локации.
+func (a *app) catalogNewOffer(c *bot.Context) error {
+	locationID, err := c.IntParam()
+	if err != nil {
+		return err
+	}
+	if _, err := a.content.OwnLocation(c, c.User.ID, locationID); err != nil {
+		return c.Reply(c.T("catalog.failed", "err", err.Error()))
+	}
+	return a.catalogStart(c, catalogPayload{Entity: model.ContentOffer, LocationID: locationID})
+}
+
+// catalogEditOffer начинает редактирование предложения с его текущими значениями.
+func (a *app) catalogEditOffer(c *bot.Context) error {
+	id, err := c.IntParam()
+	if err != nil {
+		return err
+	}
+	o, err := a.content.OwnOffer(c, c.User.ID, id)
+	if err != nil {
+		return c.Reply(c.T("catalog.failed", "err", err.Error()))
+	}
+	return a.catalogStart(c, catalogPayload{Entity: model.ContentOffer, EntityID: o.ID, LocationID: o.LocationID,
+		OfferType: o.Type, Name: o.Name, Description: o.Description, Price: o.Price, Contact: o.Contact})
+}
+
+func (a *app) catalogStart(c *bot.Context, p catalogPayload) error {
+	step := p.steps()[0]
+	if err := c.SetState(flowCatalog, step, p); err != nil {
+		return err
+	}
+	return a.catalogPrompt(c, p, step)
+}
+
+// catalogPrompt задает вопрос шага: с текущим значением и кнопкой «Оставить» при редактировании,
+// кнопкой «Пропустить» для необязательных полей, кнопками типов и завершения загрузки фото.
+func (a *app) catalogPrompt(c *bot.Context, p catalogPayload, step string) error {
+	current := p.current(c, step)
+	msg := tgbotapi.NewMessage(c.ChatID, c.T("catalog.prompt."+step, "current", current, "limit", p.photoLimit()))
+	rows := [][]tgbotapi.InlineKeyboardButton{}
+	switch step {
+	case catalogStepType:
+		for _, topic := range model.Topics {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(c.T("subscription.topic."+topic), "CAT_TYPE_"+topic),
+			))
+		}
+	case catalogStepPhotos:
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(c.T("catalog.done"), "CAT_DONE"),
+		))
+	}
+	switch {
+	case step == catalogStepPhotos || step == catalogStepType && current == "":
+	case current != "":
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(c.T("catalog.keep"), "CAT_KEEP"),
+		))
+	case optionalSteps[step]:
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(c.T("catalog.skip"), "CAT_KEEP"),
+		))
+	}
+	if len(rows) > 0 {
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	}
+	_, err := c.Send(msg)
+	return err
+}
+
+// catalogInput обрабатывает ответ на шаге сценария каталога.
+func (a *app) catalogInput(c *bot.Context) error {
+	var p catalogPayload
+	if err := c.Payload(&p); err != nil {
+		return err
+	}
+	step := c.State.Step
+	text := strings.TrimSpace(c.Text())
+	if text == "" && step != catalogStepCoordinates && step != catalogStepPhotos {
+		return c.Reply(c.T("catalog.text_expected"))
+	}
+	switch step {
+	case catalogStepType:
+		return a.catalogPrompt(c, p, step)
+	case catalogStepName:
+		p.Name = text
+	case catalogStepDescription:
+		p.Description = text
+	case catalogStepCategory:
+		p.Category = text
+	case catalogStepRegion:
+		p.Region = text
+	case catalogStepCoordinates:
+		lat, lon, ok := parseCoordinates(c, text)
+		if !ok {
+			return c.Reply(c.T("catalog.coordinates_invalid"))
+		}
+		p.Latitude, p.Longitude = lat, lon
+	case catalogStepPrice:
+		price, err := strconv.ParseFloat(strings.ReplaceAll(strings.ReplaceAll(text, " ", ""), ",", "."), 64)
+		if err != nil || price < 0 {
+			return c.Reply(c.T("catalog.price_invalid"))
+		}
+		p.Price = price
+	case catalogStepContact:
+		p.Contact = text
+	case catalogStepPhotos:
+		photoID := c.PhotoID()
+		if photoID == "" {
+			return a.catalogPrompt(c, p, step)
+		}
+		p.Photos = append(p.Photos, photoID)
+		if len(p.Photos) >= p.photoLimit() {
+			return a.catalogSubmit(c, p)
+		}
+		if err := c.SetState(flowCatalog, step, p); err != nil {
+			return err
+		}
+		return c.Reply(c.T("catalog.photo_added", "count", len(p.Photos), "limit", p.photoLimit()))
+	}
+	return a.catalogNext(c, p, step)
+}
+
+// catalogKeep оставляет текущее значение поля (или пропускает необязательное) и переходит дальше.
+func (a *app) catalogKeep(c *bot.Context) error {
+	if c.State == nil || c.State.Flow != flowCatalog {
+		return nil
+	}
+	var p catalogPayload
+	if err := c.Payload(&p); err != nil {
+		return err
+	}
+	step := c.State.Step
+	if p.current(c, step) == "" && !optionalSteps[step] {
+		return a.catalogPrompt(c, p, step)
+	}
+	return a.catalogNext(c, p, step)
+}
+
+// catalogType запоминает тип предложения, выбранный кнопкой.
+func (a *app) catalogType(c *bot.Context) error {
+	if c.State == nil || c.State.Flow != flowCatalog || c.State.Step != catalogStepType {
+		return nil
+	}
+	var p catalogPayload
+	if err := c.Payload(&p); err != nil {
+		return err
+	}
+	p.OfferType = c.Param()
+	return a.catalogNext(c, p, catalogStepType)
+}
+
+// catalogDone завершает загрузку фото и отправляет запись.
+func (a *app) catalogDone(c *bot.Context) error {
+	if c.State == nil || c.State.Flow != flowCatalog || c.State.Step != catalogStepPhotos {
+		return nil
+	}
+	var p catalogPayload
+	if err := c.Payload(&p); err != nil {
+		return err
+	}
+	return a.catalogSubmit(c, p)
+}
+
+// catalogNext переходит к шагу, следующему за step, или отправляет запись после последнего.
+func (a *app) catalogNext(c *bot.Context, p catalogPayload, step string) error {
+	steps := p.steps()
+	for i, s := range steps {
+		if s == step && i+1 < len(steps) {
+			if err := c.SetState(flowCatalog, steps[i+1], p); err != nil {
+				return err
+			}
+			return a.catalogPrompt(c, p, steps[i+1])
+		}
+	}
+	return a.catalogSubmit(c, p)
+}
+
+// catalogSubmit отправляет запись: сразу в каталог или на проверку поддержке.
+func (a *app) catalogSubmit(c *bot.Context, p catalogPayload) error {
+	if err := c.ClearState(); err != nil {
+		return err
+	}
+	change := &model.ContentChange{
+		Entity:       p.Entity,
+		AuthorID:     c.User.ID,
+		Name:         p.Name,
+		Description:  p.Description,
+		Category:     p.Category,
+		Region:       p.Region,
+		Latitude:     p.Latitude,
+		Longitude:    p.Longitude,
+		OfferType:    p.OfferType,
+		Price:        p.Price,
+		Contact:      p.Contact,
+		PhotoFileIDs: pq.StringArray(p.Photos),
+	}
+	if p.EntityID != 0 {
+		change.EntityID = &p.EntityID
+	}
+	if p.LocationID != 0 {
+		change.LocationID = &p.LocationID
+	}
+	if _, err := a.content.Submit(c, change); err != nil {
+		return c.Reply(c.T("catalog.failed", "err", err.Error()))
+	}
+	if change.Status == model.ChangePending {
+		return c.Reply(c.T("catalog.submitted", "id", change.ID))
+	}
+	return c.Reply(c.T("catalog.published", "name", change.Name))
+}
+
+// parseCoordinates читает координаты из отправленной геопозиции или из текста «широта, долгота».
+func parseCoordinates(c *bot.Context, text string) (float64, float64, bool) {
+	if loc := c.SharedLocation(); loc != nil {
+		return loc.Latitude, loc.Longitude, true
+	}
+	parts := strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == ';' || r == ' ' })
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+	lat, err1 := strconv.ParseFloat(parts[0], 64)
+	lon, err2 := strconv.ParseFloat(parts[1], 64)
+	if err1 != nil || err2 != nil || lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		return 0, 0, false
+	}
+	return lat, lon, true
+}
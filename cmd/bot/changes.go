@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"tourism/internal/bot"
+	"tourism/internal/model"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// сколько изменений каталога показывается поддержке за раз
+const changesQueueLimit = 10
+
+// сценарий ввода причины отклонения изменения каталога
+const flowChangeReject = "change_reject"
+
+// changeRejectPayload — данные сценария отклонения изменения.
+type changeRejectPayload struct {
+	ChangeID int `json:"change_id"`
+}
+
+// changesQueue показывает поддержке изменения каталога от провайдеров, ожидающие проверки.
+// Как и заявки провайдеров, очередь живет в основном боте: фото пришли сюда.
+func (a *app) changesQueue(c *bot.Context) error {
+	changes, err := a.content.Pending(c, changesQueueLimit)
+	if err != nil {
+		return c.Reply(c.T("change.queue_failed"))
+	}
+	if len(changes) == 0 {
+		return c.Reply(c.T("change.queue_empty"))
+	}
+	for i := range changes {
+		if err := a.sendChange(c, &changes[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sendChange отправляет новые фото изменения и карточку с кнопками решения.
+func (a *app) sendChange(c *bot.Context, change *model.ContentChange) error {
+	sendPhotos(c, change.PhotoFileIDs)
+	author := strconv.Itoa(change.AuthorID)
+	if user, err := a.users.GetByID(c, change.AuthorID); err == nil {
+		author = user.FirstName
+	}
+	// для редактирования показывается текущее название записи, чтобы было видно, что меняется
+	current := ""
+	if change.EntityID != nil {
+		switch change.Entity {
+		case model.ContentLocation:
+			if loc, err := a.locRepo.GetByID(c, *change.EntityID); err == nil {
+				current = loc.Name
+			}
+		case model.ContentOffer:
+			if o, err := a.offers.GetOffer(c, *change.EntityID); err == nil {
+				current = o.Name
+			}
+		}
+	}
+	offerType := ""
+	if change.OfferType != "" {
+		offerType = c.T("subscription.topic." + change.OfferType)
+	}
+	msg := tgbotapi.NewMessage(c.ChatID, c.T("change.card",
+		"id", change.ID,
+		"entity", c.T("content.entity."+change.Entity),
+		"new", change.EntityID == nil,
+		"current", current,
+		"author", author,
+		"author_id", change.AuthorID,
+		"name", change.Name,
+		"description", change.Description,
+		"category", change.Category,
+		"region", change.Region,
+		"coordinates", fmt.Sprintf("%.6f, %.6f", change.Latitude, change.Longitude),
+		"offer", change.Entity == model.ContentOffer,
+		"type", offerType,
+		"price", change.Price,
+		"contact", change.Contact,
+		"photos", len(change.PhotoFileIDs),
+	))
+	id := strconv.Itoa(change.ID)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(c.T("change.approve"), "CHG_APPROVE_"+id),
+		tgbotapi.NewInlineKeyboardButtonData(c.T("change.reject"), "CHG_REJECT_"+id),
+	))
+	_, err := c.Send(msg)
+	return err
+}
+
+// changeApprove применяет изменение каталога.
+func (a *app) changeApprove(c *bot.Context) error {
+	id, err := c.IntParam()
+	if err != nil {
+		return err
+	}
+	change, err := a.content.Approve(c, c.User.ID, id)
+	if err != nil {
+		return c.Reply(c.T("change.decision_failed", "err", err.Error()))
+	}
+	return c.Reply(c.T("change.approved", "id", change.ID, "name", change.Name))
+}
+
+// changeReject запрашивает у поддержки причину отклонения изменения.
+func (a *app) changeReject(c *bot.Context) error {
+	id, err := c.IntParam()
+	if err != nil {
+		return err
+	}
+	if err := c.SetState(flowChangeReject, "", changeRejectPayload{ChangeID: id}); err != nil {
+		return err
+	}
+	return c.Reply(c.T("change.reject_prompt", "id", id))
+}
+
+// changeRejectInput отклоняет изменение с причиной, введенной поддержкой.
+func (a *app) changeRejectInput(c *bot.Context) error {
+	var p changeRejectPayload
+	if err := c.Payload(&p); err != nil {
+		return err
+	}
+	reason := strings.TrimSpace(c.Text())
+	if reason == "" {
+		return c.Reply(c.T("change.reject_prompt", "id", p.ChangeID))
+	}
+	if err := c.ClearState(); err != nil {
+		return err
+	}
+	if _, err := a.content.Reject(c, c.User.ID, p.ChangeID, reason); err != nil {
+		return c.Reply(c.T("change.decision_failed", "err", err.Error()))
+	}
+	return c.Reply(c.T("change.rejected", "id", p.ChangeID))
+}
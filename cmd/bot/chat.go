@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"tourism/internal/bot"
+	"tourism/internal/model"
+	"tourism/internal/service"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// chatStart открывает чат по подтвержденному бронированию: /chat <ID брони>.
+func (a *app) chatStart(c *bot.Context) error {
+	bookingID, err := strconv.Atoi(strings.TrimSpace(c.Args()))
+	if err != nil {
+		return c.Reply(c.T("chat.usage"))
+	}
+	bk, err := a.bookings.GetBooking(c, bookingID)
+	if err != nil {
+		return c.Reply(c.T("booking.not_found"))
+	}
+	if bk.Status != "confirmed" {
+		return c.Reply(c.T("chat.not_confirmed"))
+	}
+	partner, err := a.chat.StartChat(c, c.From.ID, bookingID)
+	if err != nil {
+		return c.Reply(err.Error())
+	}
+	c.Send(tgbotapi.NewMessage(partner, a.textFor(c, partner, "chat.started_partner", "name", c.From.FirstName, "booking_id", bookingID)))
+	return c.Reply(c.T("chat.started", "booking_id", bookingID))
+}
+
+// chatExit завершает чат у обоих участников.
+func (a *app) chatExit(c *bot.Context) error {
+	if c.Interrupted == nil || c.Interrupted.Flow != service.ChatFlow {
+		return c.Reply(c.T("chat.not_in_chat"))
+	}
+	var p service.ChatSession
+	if err := bot.DecodePayload(c.Interrupted, &p); err != nil {
+		return err
+	}
+	left, err := a.chat.EndChat(c, c.From.ID, p.Partner)
+	if err != nil {
+		return err
+	}
+	if left {
+		c.Send(tgbotapi.NewMessage(p.Partner, a.textFor(c, p.Partner, "chat.partner_left")))
+	}
+	return c.Reply(c.T("chat.ended"))
+}
+
+// chatRelay пересылает сообщение собеседнику и сохраняет его в истории брони.
+func (a *app) chatRelay(c *bot.Context) error {
+	var p service.ChatSession
+	if err := c.Payload(&p); err != nil {
+		return err
+	}
+	partner, err := a.chat.GetChatPartner(c, c.From.ID)
+	if err != nil {
+		return err
+	}
+	if partner != p.Partner {
+		if err := c.ClearState(); err != nil {
+			return err
+		}
+		return c.Reply(c.T("chat.partner_gone"))
+	}
+	text := c.Text()
+	if photoID := c.PhotoID(); photoID != "" {
+		photo := tgbotapi.NewPhoto(p.Partner, tgbotapi.FileID(photoID))
+		photo.Caption = fmt.Sprintf("%s: %s", c.From.FirstName, text)
+		c.Send(photo)
+	} else if text != "" {
+		c.Send(tgbotapi.NewMessage(p.Partner, fmt.Sprintf("%s: %s", c.From.FirstName, text)))
+	} else {
+		return c.Reply(c.T("chat.unsupported"))
+	}
+	// продлеваем чат, пока участники переписываются
+	if err := a.chat.KeepAlive(c, c.From.ID); err != nil {
+		return err
+	}
+	// логирование
+	if r, err := a.users.GetByTelegramID(c, p.Partner); err == nil {
+		a.messages.Save(c, &model.Message{
+			FromUserID: c.User.ID,
+			ToUserID:   r.ID,
+			BookingID:  &p.BookingID,
+			Content:    text,
+			IsSupport:  false,
+		})
+	}
+	return nil
+}
+
+// textFor возвращает текст сообщения key на языке другого пользователя бота (по Telegram ID);
+// если пользователь не найден, используется язык текущего.
+func (a *app) textFor(c *bot.Context, telegramID int64, key string, args ...any) string {
+	u, err := a.users.GetByTelegramID(c, telegramID)
+	if err != nil {
+		return c.T(key, args...)
+	}
+	return c.Texts.Text(u.PreferredLanguage(), key, args...)
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"tourism/internal/bot"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// languageMenu показывает выбор языка интерфейса.
+func (a *app) languageMenu(c *bot.Context) error {
+	msg := tgbotapi.NewMessage(c.ChatID, c.T("language.choose"))
+	msg.ReplyMarkup = bot.LanguageKeyboard(c.Texts, c.Locale())
+	_, err := c.Send(msg)
+	return err
+}
+
+// languageSet сохраняет выбранный язык и показывает меню уже на нем.
+func (a *app) languageSet(c *bot.Context) error {
+	if !c.Texts.HasLocale(c.Param()) {
+		return c.Reply(c.T("language.unsupported"))
+	}
+	lang, err := a.accounts.SetLanguage(c, c.User.ID, c.Param())
+	if err != nil {
+		return err
+	}
+	c.User.Language = lang
+	resp := tgbotapi.NewMessage(c.ChatID, c.T("language.changed"))
+	resp.ReplyMarkup = keyboardFor(c)
+	_, err = c.Send(resp)
+	return err
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"tourism/internal/bot"
+	"tourism/internal/model"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// сценарий поиска локаций: ожидается ключевое слово
+const flowSearch = "search"
+
+// сколько найденных локаций показывать списком
+const searchLimit = 10
+
+// locationsStart запускает поиск: сразу по аргументу команды или после ввода ключевого слова.
+func (a *app) locationsStart(c *bot.Context) error {
+	if keyword := strings.TrimSpace(c.Args()); keyword != "" {
+		return a.searchLocations(c, keyword)
+	}
+	if err := c.SetState(flowSearch, "", nil); err != nil {
+		return err
+	}
+	return c.Reply(c.T("search.prompt"))
+}
+
+// locationsSearch ищет локации по тексту сообщения.
+func (a *app) locationsSearch(c *bot.Context) error {
+	if err := c.ClearState(); err != nil {
+		return err
+	}
+	return a.searchLocations(c, strings.TrimSpace(c.Text()))
+}
+
+// fallback обрабатывает сообщения вне сценариев: произвольный текст считается поисковым запросом.
+func (a *app) fallback(c *bot.Context) error {
+	msg := c.Message()
+	if msg.IsCommand() {
+		return c.Reply(c.T("bot.unknown_command"))
+	}
+	if strings.TrimSpace(msg.Text) == "" {
+		return nil
+	}
+	return a.searchLocations(c, strings.TrimSpace(msg.Text))
+}
+
+// searchLocations отправляет список найденных локаций с кнопками карточек.
+func (a *app) searchLocations(c *bot.Context, keyword string) error {
+	locations, err := a.locations.SearchLocations(c, "", "", 0, keyword)
+	if err != nil {
+		return err
+	}
+	if len(locations) == 0 {
+		return c.Reply(c.T("search.not_found", "query", keyword))
+	}
+	a.localizeLocations(c, locations)
+	_, err = c.Send(locationList(c, c.T("search.results"), locations, "LOC_"))
+	return err
+}
+
+// locationList формирует сообщение со списком локаций, каждая — кнопка с данными prefix+ID.
+func locationList(c *bot.Context, title string, locations []model.Location, prefix string) tgbotapi.MessageConfig {
+	rows := [][]tgbotapi.InlineKeyboardButton{}
+	for i, loc := range locations {
+		if i == searchLimit {
+			title += "\n" + c.T("search.truncated", "shown", searchLimit, "total", len(locations))
+			break
+		}
+		label := loc.Name
+		if loc.Region != "" {
+			label += " · " + loc.Region
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("%s%d", prefix, loc.ID)),
+		))
+	}
+	msg := tgbotapi.NewMessage(c.ChatID, title)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return msg
+}
+
+// locationCard отправляет карточку локации: альбом фото, описание, рейтинг, карту и кнопки действий.
+func (a *app) locationCard(c *bot.Context) error {
+	id, err := c.IntParam()
+	if err != nil {
+		return err
+	}
+	loc, photos, err := a.locations.GetLocationDetails(c, id)
+	if loc == nil {
+		slog.WarnContext(c, "Локация не найдена", "location_id", id, "err", err)
+		return c.Reply(c.T("location.not_found"))
+	}
+	// скрытую локацию видят только ее провайдер и поддержка
+	if loc.Hidden && !canTranslate(c, loc.ProviderID) {
+		return c.Reply(c.T("location.not_found"))
+	}
+	a.localizeLocation(c, loc)
+	sendGallery(c, photos)
+	a.sendLocationMap(c, loc)
+	// описание + рейтинг + ссылка на карту
+	text := c.T("location.card",
+		"name", loc.Name, "description", loc.Description, "rating", loc.Rating,
+		"map", fmt.Sprintf("https://maps.google.com/?q=%f,%f", loc.Latitude, loc.Longitude),
+	)
+	msg := tgbotapi.NewMessage(c.ChatID, text)
+	msg.ParseMode = tgbotapi.ModeMarkdownV2
+	btnAdd := tgbotapi.NewInlineKeyboardButtonData(c.T("location.add_to_trip"), fmt.Sprintf("ADDTRIP_%d", id))
+	btnBook := tgbotapi.NewInlineKeyboardButtonData(c.T("location.book"), "BOOKING_CATEGORY")
+	btnReviews := tgbotapi.NewInlineKeyboardButtonData(c.T("location.reviews"), fmt.Sprintf("REVIEWS_%d", id))
+	btnNewReview := tgbotapi.NewInlineKeyboardButtonData(c.T("review.leave"), fmt.Sprintf("REVIEW_NEW_%d", id))
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(btnAdd, btnBook),
+		tgbotapi.NewInlineKeyboardRow(btnReviews, btnNewReview),
+	}
+	if canTranslate(c, loc.ProviderID) {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(translateButton(c, model.TranslationLocation, id), galleryButton(c, id)))
+	}
+	if c.User.Role == "provider" && loc.ProviderID != nil && *loc.ProviderID == c.User.ID {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(c.T("catalog.manage"), fmt.Sprintf("CAT_LOC_%d", id)),
+		))
+	}
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	_, err = c.Send(msg)
+	return err
+}
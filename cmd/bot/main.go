@@ -1,319 +1,415 @@
 package main
 
 import (
-	"fmt"
+	"context"
+	"io"
 	"log"
+	"log/slog"
+	"net/http"
 	"os"
-	"strconv"
-	"strings"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
-	"tourism/internal/model"
+	"tourism/internal/bot"
+	"tourism/internal/broadcast"
+	"tourism/internal/config"
+	"tourism/internal/health"
+	"tourism/internal/i18n"
+	"tourism/internal/logging"
+	"tourism/internal/metrics"
+	"tourism/internal/photosync"
 	"tourism/internal/repository"
 	"tourism/internal/service"
+	"tourism/internal/telegram"
+	"tourism/migrations"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
-	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
 )
 
-// клавиатура для туристов
-func userKeyboard() tgbotapi.ReplyKeyboardMarkup {
-	return tgbotapi.NewReplyKeyboard(
-		tgbotapi.NewKeyboardButtonRow(
-			tgbotapi.NewKeyboardButton("📍 Найти локации"),
-			tgbotapi.NewKeyboardButton("🗺 Новый маршрут"),
-		),
-		tgbotapi.NewKeyboardButtonRow(
-			tgbotapi.NewKeyboardButton("✅ Подписка на предложения"),
-			tgbotapi.NewKeyboardButton("🛎 Поддержка"),
-		),
-	)
+// keyboardFor возвращает клавиатуру главного меню для роли пользователя на его языке.
+func keyboardFor(c *bot.Context) tgbotapi.ReplyKeyboardMarkup {
+	return bot.MenuKeyboard(c.Texts, c.Locale(), c.User.Role)
 }
 
-// клавиатура для провайдеров
-func providerKeyboard() tgbotapi.ReplyKeyboardMarkup {
-	return tgbotapi.NewReplyKeyboard(
-		tgbotapi.NewKeyboardButtonRow(
-			tgbotapi.NewKeyboardButton("📍 Найти локации"),
-			tgbotapi.NewKeyboardButton("📦 Мои бронирования"),
-		),
-		tgbotapi.NewKeyboardButtonRow(
-			tgbotapi.NewKeyboardButton("🛎 Поддержка"),
-		),
-	)
+// app объединяет зависимости обработчиков основного бота.
+type app struct {
+	users        repository.UserStore
+	accounts     *service.UserService
+	messages     repository.MessageStore
+	locRepo      repository.LocationStore
+	locations    *service.LocationService
+	trips        *service.TripService
+	bookings     *service.BookingService
+	chat         *service.ChatService
+	offers       *service.OfferService
+	broadcasts   *service.BroadcastService
+	reviews      *service.ReviewService
+	translations *service.TranslationService
+	providers    *service.ProviderService
+	content      *service.ContentService
+	admin        *service.AdminService
+	photoStorage *service.PhotoStorageService
+	maps         *service.MapService
+	supportBot   string // имя бота поддержки (без @)
 }
 
-// клавиатура для операторов поддержки
-func supportKeyboard() tgbotapi.ReplyKeyboardMarkup {
-	return tgbotapi.NewReplyKeyboard(
-		tgbotapi.NewKeyboardButtonRow(
-			tgbotapi.NewKeyboardButton("📤 Рассылка"),
-			tgbotapi.NewKeyboardButton("📷 Добавить фото"),
-		),
-		tgbotapi.NewKeyboardButtonRow(
-			tgbotapi.NewKeyboardButton("🔍 Проверить локации"),
-		),
-	)
+// register регистрирует обработчики всех сценариев бота.
+func (a *app) register(r *bot.Router) {
+	r.Command("start", a.start)
+
+	// язык интерфейса
+	r.Command("language", a.languageMenu)
+	r.Button("menu.language", a.languageMenu)
+	r.Callback(bot.LanguagePrefix, a.languageSet)
+
+	// каталог локаций
+	r.Command("locations", a.locationsStart)
+	r.Button("menu.search", a.locationsStart)
+	r.Flow(flowSearch, a.locationsSearch)
+	r.Callback("LOC_", a.locationCard)
+	r.Fallback(a.fallback)
+
+	// маршруты
+	r.Command("newtrip", a.newTrip)
+	r.Button("menu.new_trip", a.newTrip)
+	r.Flow(flowNewTrip, a.newTripName)
+	r.Command("optimize", a.optimizeTrip)
+	r.Command("finish_trip", a.finishTrip)
+	r.Callback("ADDTRIP_", a.addToTrip)
+
+	// бронирование
+	r.Callback("BOOKING_CATEGORY", a.bookingCategory)
+	r.Callback("BOOKING_TYPE_", a.bookingOffers)
+	r.Callback("BOOK_OFFER_", a.bookOffer)
+	r.Flow(flowBooking, a.bookingDetails)
+	r.Callback("CONFIRM_", bot.RequireRole("provider", a.bookingDecision))
+	r.Callback("REJECT_", bot.RequireRole("provider", a.bookingDecision))
+	r.Button("menu.bookings", bot.RequireRole("provider", a.providerBookings))
+
+	// чат туриста с провайдером
+	r.Command("chat", a.chatStart)
+	r.Command("exit", a.chatExit)
+	r.Flow(service.ChatFlow, a.chatRelay)
+
+	// отзывы
+	r.Callback("REVIEWS_", a.reviewsList)
+	r.Callback("REVIEW_NEW_", a.reviewNew)
+	r.Callback("REVIEW_RATE_", a.reviewRate)
+	r.Callback("REVIEW_DONE", a.reviewDone)
+	r.Callback("REVIEW_PHOTOS_", a.reviewPhotoAlbum)
+	r.Callback("REVIEW_REPLY_", a.reviewReply)
+	r.Flow(flowReview, a.reviewInput)
+
+	// переводы локаций и предложений (провайдеры своих записей и поддержка)
+	r.Callback("TRANSLATE_", a.translateStart)
+	r.Callback("TRANSLATE_LANG_", a.translateLocale)
+	r.Flow(flowTranslate, a.translateInput)
+
+	// подписка на предложения
+	r.Command("subscribe_offers", a.subscribe)
+	r.Command("settings", a.subscriptionSettings)
+	r.Button("menu.subscription", a.subscriptionSettings)
+	r.Command("unsubscribe_offers", a.unsubscribe)
+	r.Callback("SUB_", a.subscriptionUpdate)
+
+	// рассылки (операторы поддержки)
+	r.Command("broadcast", bot.RequireRole("support", a.broadcastStart))
+	r.Button("menu.broadcast", bot.RequireRole("support", a.broadcastStart))
+	r.Callback("BC_", bot.RequireRole("support", a.broadcastAction))
+	r.Flow(flowBroadcast, a.broadcastInput)
+
+	// заявка на роль провайдера
+	r.Command("become_provider", a.becomeProvider)
+	r.Button("menu.become_provider", a.becomeProvider)
+	r.Callback("PROVIDER_DOCS_DONE", a.applicationDocumentsDone)
+	r.Flow(flowProviderApply, a.applicationInput)
+
+	// проверка заявок провайдеров (операторы поддержки)
+	r.Command("applications", bot.RequireRole("support", a.applicationsQueue))
+	r.Button("menu.applications", bot.RequireRole("support", a.applicationsQueue))
+	r.Callback("APP_APPROVE_", bot.RequireRole("support", a.applicationApprove))
+	r.Callback("APP_REJECT_", bot.RequireRole("support", a.applicationReject))
+	r.Flow(flowApplicationReject, a.applicationRejectInput)
+	r.Command("link_location", bot.RequireRole("support", a.linkLocation))
+
+	// каталог провайдера: свои локации и предложения
+	r.Command("catalog", bot.RequireRole("provider", a.myCatalog))
+	r.Button("menu.catalog", bot.RequireRole("provider", a.myCatalog))
+	r.Callback("CAT_LOC_", bot.RequireRole("provider", a.catalogLocation))
+	r.Callback("CAT_NEWLOC", bot.RequireRole("provider", a.catalogNewLocation))
+	r.Callback("CAT_EDITLOC_", bot.RequireRole("provider", a.catalogEditLocation))
+	r.Callback("CAT_HIDELOC_", bot.RequireRole("provider", a.catalogLocationVisibility))
+	r.Callback("CAT_SHOWLOC_", bot.RequireRole("provider", a.catalogLocationVisibility))
+	r.Callback("CAT_OFFERS_", bot.RequireRole("provider", a.catalogOffers))
+	r.Callback("CAT_OFFER_", bot.RequireRole("provider", a.catalogOffer))
+	r.Callback("CAT_NEWOFFER_", bot.RequireRole("provider", a.catalogNewOffer))
+	r.Callback("CAT_EDITOFFER_", bot.RequireRole("provider", a.catalogEditOffer))
+	r.Callback("CAT_HIDEOFFER_", bot.RequireRole("provider", a.catalogOfferVisibility))
+	r.Callback("CAT_SHOWOFFER_", bot.RequireRole("provider", a.catalogOfferVisibility))
+	r.Callback("CAT_TYPE_", bot.RequireRole("provider", a.catalogType))
+	r.Callback("CAT_KEEP", bot.RequireRole("provider", a.catalogKeep))
+	r.Callback("CAT_DONE", bot.RequireRole("provider", a.catalogDone))
+	r.Flow(flowCatalog, a.catalogInput)
+
+	// проверка изменений каталога (операторы поддержки)
+	r.Command("changes", bot.RequireRole("support", a.changesQueue))
+	r.Button("menu.changes", bot.RequireRole("support", a.changesQueue))
+	r.Callback("CHG_APPROVE_", bot.RequireRole("support", a.changeApprove))
+	r.Callback("CHG_REJECT_", bot.RequireRole("support", a.changeReject))
+	r.Flow(flowChangeReject, a.changeRejectInput)
+
+	// управление пользователями (администраторы)
+	r.Command("users", bot.RequireRole("admin", a.adminUsers))
+	r.Button("menu.users", bot.RequireRole("admin", a.adminUsers))
+	r.Flow(flowUserSearch, a.adminSearchInput)
+	r.Command("user", bot.RequireRole("admin", a.adminUserCommand))
+	r.Callback("ADM_USER_", bot.RequireRole("admin", a.adminUser))
+	r.Callback("ADM_BOOKINGS_", bot.RequireRole("admin", a.adminBookings))
+	r.Callback("ADM_TRIPS_", bot.RequireRole("admin", a.adminTrips))
+	r.Callback("ADM_TICKETS_", bot.RequireRole("admin", a.adminTickets))
+	r.Command("audit", bot.RequireRole("admin", a.adminAuditCommand))
+	r.Button("menu.audit", bot.RequireRole("admin", a.adminAuditCommand))
+	r.Callback("ADM_AUDIT_", bot.RequireRole("admin", a.adminAudit))
+	r.Command("set_role", bot.RequireRole("admin", a.adminSetRoleCommand))
+	r.Callback("ADM_ROLES_", bot.RequireRole("admin", a.adminRoles))
+	r.Callback("ADM_SETROLE_", bot.RequireRole("admin", a.adminSetRoleButton))
+	r.Command("block", bot.RequireRole("admin", a.adminBlockCommand))
+	r.Callback("ADM_BLOCK_", bot.RequireRole("admin", a.adminBlock))
+	r.Flow(flowUserBlock, a.adminBlockInput)
+	r.Command("unblock", bot.RequireRole("admin", a.adminUnblockCommand))
+	r.Callback("ADM_UNBLOCK_", bot.RequireRole("admin", a.adminUnblock))
+
+	// поддержка и фото локаций
+	r.Command("support", a.support)
+	r.Button("menu.support", a.support)
+	r.Button("menu.add_photo", bot.RequireRole("support", a.addPhotoStart))
+	r.Callback("PHOTO_ADD_", bot.RequireRole("support", a.addPhotoFor))
+	r.Flow(flowAddPhoto, a.addPhotoInput)
+	r.Button("menu.check_locations", bot.RequireRole("support", a.checkLocations))
+
+	// галерея фото локации (провайдер локации и поддержка)
+	r.Command("photos", a.photosCommand)
+	r.Callback("PH_LIST_", a.photosList)
+	r.Callback("PH_UP_", a.photoShift)
+	r.Callback("PH_DOWN_", a.photoShift)
+	r.Command("photo_move", a.photoMoveCommand)
+	r.Callback("PH_COVER_", a.photoCover)
+	r.Callback("PH_CAPTION_", a.photoCaption)
+	r.Flow(flowPhotoCaption, a.photoCaptionInput)
+	r.Callback("PH_DEL_", a.photoDelete)
+	r.Callback("PH_DELOK_", a.photoDeleteConfirm)
+	r.Command("photo_delete", a.photoDeleteCommand)
+}
+
+// start приветствует пользователя и показывает меню для его роли.
+func (a *app) start(c *bot.Context) error {
+	resp := tgbotapi.NewMessage(c.ChatID, c.T("start.greeting", "name", c.User.FirstName))
+	resp.ReplyMarkup = keyboardFor(c)
+	_, err := c.Send(resp)
+	return err
 }
 
 func main() {
-	// подключение к БД
-	dbHost := os.Getenv("DB_HOST")
-	if dbHost == "" {
-		dbHost = "db"
+	cfg, err := config.Load(config.AppBot)
+	if err != nil {
+		log.Fatal(err)
 	}
-	dbPort := os.Getenv("DB_PORT")
-	if dbPort == "" {
-		dbPort = "5432"
+	if err := logging.Setup("bot", cfg.Log.Level, cfg.Log.Format); err != nil {
+		log.Fatal(err)
 	}
-	dsn := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		dbHost, dbPort,
-		os.Getenv("DB_USER"), os.Getenv("DB_PASS"), os.Getenv("DB_NAME"),
-	)
-	db, err := sqlx.Connect("postgres", dsn)
+	slog.Info("Конфигурация загружена", "config", cfg)
+	texts, err := i18n.New()
 	if err != nil {
-		log.Fatalf("DB connection failed: %v", err)
+		fatal("Не удалось загрузить тексты сообщений", err)
 	}
 
+	// подключение к БД
+	db, err := cfg.DB.Connect()
+	if err != nil {
+		fatal("Нет подключения к базе данных", err)
+	}
+
+	// запросы репозиториев ограничены сроком контекста и DB_QUERY_TIMEOUT
+	store := repository.NewDB(db, cfg.DB.QueryTimeout.Std())
 	// репозитории
-	userRepo := repository.NewUserRepository(db)
-	messageRepo := repository.NewMessageRepository(db)
-	locRepo := repository.NewLocationRepository(db)
-	tripRepo := repository.NewTripRepository(db)
-	bookRepo := repository.NewBookingRepository(db)
-	subRepo := repository.NewSubscriptionRepository(db)
-	offerRepo := repository.NewOfferRepository(db)
+	userRepo := repository.NewUserRepository(store)
+	messageRepo := repository.NewMessageRepository(store)
+	locRepo := repository.NewLocationRepository(store)
+	tripRepo := repository.NewTripRepository(store)
+	bookRepo := repository.NewBookingRepository(store)
+	subRepo := repository.NewSubscriptionRepository(store)
+	offerRepo := repository.NewOfferRepository(store)
+	campaignRepo := repository.NewCampaignRepository(store)
+	deliveryRepo := repository.NewDeliveryRepository(store)
+	notificationRepo := repository.NewNotificationRepository(store)
+	digestRepo := repository.NewDigestRepository(store)
+	reviewRepo := repository.NewReviewRepository(store)
+	translationRepo := repository.NewTranslationRepository(store)
+	applicationRepo := repository.NewProviderApplicationRepository(store)
+	changeRepo := repository.NewContentChangeRepository(store)
+	auditRepo := repository.NewAuditRepository(store)
+	stateRepo := repository.NewStateRepository(store)
 
 	// сервисы
-	locationService := service.NewLocationService(locRepo)
-	tripService := service.NewTripService(tripRepo, locRepo)
-	bookingService := service.NewBookingService(bookRepo)
-	chatService := service.NewChatService(bookRepo, userRepo, locRepo)
-	offerService := service.NewOfferService(subRepo, offerRepo)
 	authService := service.NewAuthService(userRepo)
+	broadcastService := service.NewBroadcastService(store, campaignRepo, deliveryRepo, subRepo, userRepo)
+	translationService := service.NewTranslationService(store, translationRepo, locRepo, offerRepo, userRepo, notificationRepo)
+	digestService := service.NewDigestService(digestRepo, userRepo, translationService, 7*24*time.Hour, 10)
+	notificationService := service.NewNotificationService(store, notificationRepo, userRepo)
+	// фото галерей копируются из Telegram в хранилище (STORAGE_*), чтобы их отдавало API
+	blobs, err := cfg.Storage.Open()
+	if err != nil {
+		fatal("Не удалось открыть хранилище фото", err)
+	}
+	photoStorage := service.NewPhotoStorageService(locRepo, blobs)
+	// статические карты рисуются локально: по тайлам из MAP_TILE_DIR или по векторной подложке
+	renderer, err := cfg.Maps.Renderer()
+	if err != nil {
+		fatal("Не удалось подготовить отрисовку карт", err)
+	}
+	a := &app{
+		users:        userRepo,
+		accounts:     service.NewUserService(userRepo),
+		messages:     messageRepo,
+		locRepo:      locRepo,
+		locations:    service.NewLocationService(locRepo),
+		trips:        service.NewTripService(tripRepo, locRepo),
+		bookings:     service.NewBookingService(store, bookRepo, locRepo, userRepo, notificationRepo),
+		chat:         service.NewChatService(bookRepo, userRepo, locRepo, stateRepo),
+		offers:       service.NewOfferService(subRepo, offerRepo, locRepo),
+		broadcasts:   broadcastService,
+		reviews:      service.NewReviewService(store, reviewRepo, locRepo, userRepo, notificationRepo, service.DefaultModerationRules()),
+		translations: translationService,
+		providers:    service.NewProviderService(store, applicationRepo, userRepo, locRepo, notificationRepo),
+		content: service.NewContentService(store, changeRepo, locRepo, offerRepo, userRepo, notificationRepo,
+			cfg.Features.ContentModeration),
+		admin:        service.NewAdminService(store, userRepo, bookRepo, tripRepo, messageRepo, auditRepo, notificationRepo),
+		photoStorage: photoStorage,
+		maps:         service.NewMapService(tripRepo, renderer),
+		supportBot:   cfg.Bot.SupportUsername,
+	}
 
 	// инициализация бота
-	botToken := os.Getenv("BOT_TOKEN")
-	if botToken == "" {
-		log.Fatal("Не указан BOT_TOKEN")
-	}
-	bot, err := tgbotapi.NewBotAPI(botToken)
+	botAPI, err := telegram.New(cfg.Bot.Token.Value(), cfg.Telegram.APIEndpoint)
 	if err != nil {
-		log.Fatalf("Ошибка инициализации бота: %v", err)
+		fatal("Ошибка инициализации бота", err)
 	}
-	log.Printf("Бот запущен: @%s", bot.Self.UserName)
-
-	updates := bot.GetUpdatesChan(tgbotapi.NewUpdate(0))
-
-	// временное состояние
-	activeTrip := make(map[int64]int)
-	pendingBooking := make(map[int64]int)
-	pendingAddPhoto := make(map[int64]int)
-
-	for update := range updates {
-		// inline callbacks
-		if cq := update.CallbackQuery; cq != nil {
-			bot.Request(tgbotapi.NewCallback(cq.ID, ""))
-			data := cq.Data
-			chatID := cq.Message.Chat.ID
-			userID := cq.From.ID
-
-			switch {
-			// карточка локации
-			case strings.HasPrefix(data, "LOC_"):
-				id, _ := strconv.Atoi(strings.TrimPrefix(data, "LOC_"))
-				loc, _ := locRepo.GetByID(id)
-				// отправляем первое фото
-				if len(loc.PhotoFileID) > 0 {
-					bot.Send(tgbotapi.NewPhoto(chatID, tgbotapi.FileID(loc.PhotoFileID)))
-				}
-				// описание + карта
-				text := fmt.Sprintf("*%s*\n%s\n[Открыть в картах](https://maps.google.com/?q=%f,%f)",
-					loc.Name, loc.Description, loc.Latitude, loc.Longitude,
-				)
-				msg := tgbotapi.NewMessage(chatID, text)
-				msg.ParseMode = "Markdown"
-				btnAdd := tgbotapi.NewInlineKeyboardButtonData("➕ В маршрут", fmt.Sprintf("ADDTRIP_%d", id))
-				btnBook := tgbotapi.NewInlineKeyboardButtonData("🛎 Забронировать", "BOOKING_CATEGORY")
-				msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
-					tgbotapi.NewInlineKeyboardRow(btnAdd, btnBook),
-				)
-				bot.Send(msg)
-
-			// добавить в маршрут
-			case strings.HasPrefix(data, "ADDTRIP_"):
-				id, _ := strconv.Atoi(strings.TrimPrefix(data, "ADDTRIP_"))
-				if tripID, ok := activeTrip[userID]; ok {
-					tripService.AddLocationToTrip(tripID, id)
-					bot.Send(tgbotapi.NewMessage(chatID, "Локация добавлена в маршрут"))
-				} else {
-					bot.Send(tgbotapi.NewMessage(chatID, "Сначала создайте маршрут (🗺 Новый маршрут)"))
-				}
+	slog.Info("Бот запущен", "username", botAPI.Self.UserName)
+	api := telegram.Instrument(botAPI)
 
-			// выбор типа брони
-			case data == "BOOKING_CATEGORY":
-				kbd := tgbotapi.NewInlineKeyboardMarkup(
-					tgbotapi.NewInlineKeyboardRow(
-						tgbotapi.NewInlineKeyboardButtonData("🏠 Жильё", "BOOKING_TYPE_housing"),
-						tgbotapi.NewInlineKeyboardButtonData("🗺 Тур", "BOOKING_TYPE_tour"),
-					),
-				)
-				msg := tgbotapi.NewMessage(chatID, "Выберите тип бронирования:")
-				msg.ReplyMarkup = kbd
-				bot.Send(msg)
-
-			// список предложений
-			case strings.HasPrefix(data, "BOOKING_TYPE_"):
-				typ := strings.TrimPrefix(data, "BOOKING_TYPE_")
-				offers, _ := offerService.ListOffers(typ)
-				for _, o := range offers {
-					photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileID(o.PhotoFileID))
-					cap := fmt.Sprintf("*%s*\n%s\nЦена: %.0f ₽\nКонтакт: %s",
-						o.Name, o.Description, o.Price, o.Contact,
-					)
-					if len(o.SocialLinks) > 0 {
-						cap += "\n" + strings.Join(o.SocialLinks, "\n")
-					}
-					photo.Caption = cap
-					photo.ParseMode = "Markdown"
-					btn := tgbotapi.NewInlineKeyboardButtonData(
-						"Забронировать", fmt.Sprintf("BOOK_OFFER_%d", o.ID),
-					)
-					photo.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
-						tgbotapi.NewInlineKeyboardRow(btn),
-					)
-					bot.Send(photo)
-				}
+	// служебный HTTP-сервер: метрики и проверки живости и готовности
+	checker := health.NewChecker()
+	checker.Add("database", health.Database(db))
+	checker.Add("migrations", health.Migrations(db, migrations.FS))
+	mux := http.NewServeMux()
+	checker.Register(mux)
+	srv, err := metrics.Serve(cfg.Bot.MetricsListen, mux)
+	if err != nil {
+		fatal("Не удалось запустить служебный HTTP-сервер", err)
+	}
 
-			// запрос деталей брони
-			case strings.HasPrefix(data, "BOOK_OFFER_"):
-				id, _ := strconv.Atoi(strings.TrimPrefix(data, "BOOK_OFFER_"))
-				pendingBooking[userID] = id
-				bot.Send(tgbotapi.NewMessage(chatID,
-					"Укажите даты и количество участников, напр.: 2025-07-01 — 2025-07-05, 3 человека",
-				))
-
-			// подтверждение/отказ провайдером
-			case strings.HasPrefix(data, "CONFIRM_"), strings.HasPrefix(data, "REJECT_"):
-				parts := strings.Split(data, "_")
-				action, sid := parts[0], parts[1]
-				bID, _ := strconv.Atoi(sid)
-				if action == "CONFIRM" {
-					bookingService.ConfirmBooking(bID)
-				} else {
-					bookingService.RejectBooking(bID)
-				}
-				bk, _ := bookingService.GetBooking(bID)
-				res := map[string]string{
-					"CONFIRM": "Ваша бронь подтверждена ✅",
-					"REJECT":  "Ваша бронь отклонена ❌",
-				}[action]
-				bot.Send(tgbotapi.NewMessage(int64(bk.UserID), res))
-			}
-
-			continue
-		}
+	// обновления приходят через вебхук (BOT_WEBHOOK_URL) или long polling
+	updates, err := telegram.Listen(api, cfg.Bot.Webhook.Telegram())
+	if err != nil {
+		fatal("Ошибка получения обновлений", err)
+	}
+	// по сигналу бот перестает принимать обновления и дорабатывает уже принятые
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-		// обработка сообщений
-		if msg := update.Message; msg != nil {
-			chatID := msg.Chat.ID
-			userID := msg.From.ID
-			text := msg.Text
-
-			// детали брони
-			if offerID, ok := pendingBooking[userID]; ok {
-				bookID, err := bookingService.CreateBooking(int(userID), offerID, text)
-				if err != nil {
-					bot.Send(tgbotapi.NewMessage(chatID, "Ошибка создания брони"))
-				} else {
-					bot.Send(tgbotapi.NewMessage(chatID,
-						fmt.Sprintf("Заявка #%d отправлена провайдеру", bookID),
-					))
-					o, _ := offerService.GetOffer(offerID)
-					provChat := getProviderChatID(o, locRepo, userRepo)
-					notify := tgbotapi.NewMessage(provChat,
-						fmt.Sprintf("Новая бронь #%d от %s: %s", bookID, msg.From.FirstName, text),
-					)
-					btnC := tgbotapi.NewInlineKeyboardButtonData("✔ Подтвердить", fmt.Sprintf("CONFIRM_%d", bookID))
-					btnR := tgbotapi.NewInlineKeyboardButtonData("✖ Отклонить", fmt.Sprintf("REJECT_%d", bookID))
-					notify.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
-						tgbotapi.NewInlineKeyboardRow(btnC, btnR),
-					)
-					bot.Send(notify)
-				}
-				delete(pendingBooking, userID)
-				continue
-			}
-
-			// добавление фото
-			if locID, ok := pendingAddPhoto[userID]; ok {
-				if len(msg.Photo) > 0 {
-					fileID := msg.Photo[len(msg.Photo)-1].FileID
-					locationService.AddPhoto(locID, fileID)
-					bot.Send(tgbotapi.NewMessage(chatID, "Фото сохранено"))
-				} else {
-					bot.Send(tgbotapi.NewMessage(chatID, "Ожидается фото"))
-				}
-				delete(pendingAddPhoto, userID)
-				continue
-			}
-
-			// чат турист ↔ провайдер
-			if partner := chatService.GetChatPartner(userID); partner != 0 {
-				out := fmt.Sprintf("%s: %s", msg.From.FirstName, text)
-				bot.Send(tgbotapi.NewMessage(partner, out))
-				// логирование
-				s, _ := userRepo.GetByTelegramID(userID)
-				r, _ := userRepo.GetByTelegramID(partner)
-				bID := chatService.GetChatBookingID(userID)
-				messageRepo.Save(&model.Message{
-					FromUserID: s.ID,
-					ToUserID:   r.ID,
-					BookingID:  &bID,
-					Content:    text,
-					IsSupport:  false,
-				})
-				continue
-			}
-
-			// команда /start
-			if msg.IsCommand() && msg.Command() == "start" {
-				user, _ := authService.AuthUser(int64(userID), msg.From.UserName, msg.From.FirstName, msg.From.LastName)
-				var kb tgbotapi.ReplyKeyboardMarkup
-				switch user.Role {
-				case "provider":
-					kb = providerKeyboard()
-				case "support":
-					kb = supportKeyboard()
-				default:
-					kb = userKeyboard()
+	// доставка уведомлений, запланированных рассылок и еженедельных дайджестов (с общим лимитом отправки)
+	// фоновые задачи дожидаются при остановке, чтобы начатые отправки успели записать результат
+	var background sync.WaitGroup
+	goBackground := func(run func(context.Context)) {
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			run(ctx)
+		}()
+	}
+	// лимит отправки считается в процессе, поэтому фоновые отправки выполняет один экземпляр бота,
+	// взявший блокировку в базе; остальные подменяют его, если он остановится
+	limiter := broadcast.NewLimiter(cfg.Broadcast.Rate)
+	senders := []func(context.Context){}
+	if cfg.Features.Notifications {
+		senders = append(senders, broadcast.NewNotificationDispatcher(api, notificationService, texts, limiter, broadcast.DefaultNotificationConfig()).Run)
+	}
+	if cfg.Features.Broadcasts {
+		senders = append(senders, broadcast.NewWorker(api, broadcastService, texts, limiter, broadcast.DefaultConfig()).Run)
+	}
+	if cfg.Features.Digests {
+		senders = append(senders, broadcast.NewDigestJob(api, digestService, texts, limiter, broadcast.DefaultDigestConfig()).Run)
+	}
+	if len(senders) > 0 {
+		lock := repository.NewAdvisoryLock(store, broadcast.LockID)
+		goBackground(func(ctx context.Context) {
+			broadcast.RunExclusive(ctx, lock, 30*time.Second, func(ctx context.Context) {
+				var wg sync.WaitGroup
+				for _, run := range senders {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						run(ctx)
+					}()
 				}
-				resp := tgbotapi.NewMessage(chatID, fmt.Sprintf("Здравствуйте, %s! Выберите действие:", user.FirstName))
-				resp.ReplyMarkup = kb
-				bot.Send(resp)
-				continue
-			}
-
-			// меню по тексту и команды (/locations, /newtrip, подписка и т.д.)
-			// ... ваша оставшаяся логика здесь ...
-		}
+				wg.Wait()
+			})
+		})
+	}
+	if cfg.Features.PhotoStorage {
+		files := telegram.NewDownloader(api, cfg.Bot.Token.Value(), cfg.Telegram.APIEndpoint)
+		goBackground(photosync.NewJob(files, photoStorage, photosync.DefaultConfig()).Run)
 	}
+
+	// сценарии диалогов хранятся в БД, брошенные удаляются по тайм-ауту
+	router := bot.NewRouter(stateRepo, texts, cfg.Bot.StateTTL.Std())
+	router.Use(bot.Recover(), bot.Logger(), bot.Auth(authService))
+	a.register(router)
+	goBackground(func(ctx context.Context) { bot.RunCleanup(ctx, stateRepo, 10*time.Minute) })
+	go func() {
+		<-ctx.Done()
+		checker.Shutdown()
+	}()
+
+	// ответы пользователям учитываются в том же лимите, что и фоновые отправки
+	router.Run(ctx, broadcast.NewLimitedSender(api, limiter), updates, bot.DispatcherConfig{
+		Workers:         cfg.Bot.Updates.Workers,
+		QueueSize:       cfg.Bot.Updates.QueueSize,
+		ShutdownTimeout: cfg.Bot.Updates.ShutdownTimeout.Std(),
+	})
+	shutdown(&background, srv, db, cfg.Bot.Updates.ShutdownTimeout.Std())
+	slog.Info("Бот остановлен")
 }
 
-// getProviderChatID возвращает Telegram ID провайдера.
-func getProviderChatID(
-	offer model.Offer,
-	locRepo repository.LocationRepository,
-	userRepo repository.UserRepository,
-) int64 {
-	loc, err := locRepo.GetByID(offer.LocationID)
-	if err != nil || loc.ProviderID == nil {
-		return 0
+// shutdown дожидается фоновых задач (не дольше timeout), останавливает служебный
+// HTTP-сервер и закрывает базу данных.
+func shutdown(background *sync.WaitGroup, srv *http.Server, db io.Closer, timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		background.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		slog.Warn("Фоновые задачи не завершились вовремя", "timeout", timeout)
 	}
-	prov, err := userRepo.GetByID(*loc.ProviderID)
-	if err != nil {
-		return 0
+	if srv != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			slog.Error("Ошибка остановки служебного HTTP-сервера", "err", err)
+		}
 	}
-	return prov.TelegramID
+	if err := db.Close(); err != nil {
+		slog.Error("Ошибка закрытия базы данных", "err", err)
+	}
+}
+
+// fatal логирует ошибку и завершает процесс.
+func fatal(msg string, err error) {
+	slog.Error(msg, "err", err)
+	os.Exit(1)
 }
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+
+	"tourism/internal/bot"
+	"tourism/internal/model"
+	"tourism/internal/staticmap"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// sendLocationMap отправляет карту с меткой локации. Карта дополняет карточку, поэтому ошибки
+// только записываются в лог.
+func (a *app) sendLocationMap(c *bot.Context, loc *model.Location) {
+	img, err := a.maps.LocationMap(c, loc)
+	a.sendMap(c, img, err, loc.Name, "location_id", loc.ID)
+}
+
+// sendRouteMap отправляет карту маршрута с пронумерованными точками.
+func (a *app) sendRouteMap(c *bot.Context, trip *model.Trip, locations []model.Location) {
+	img, err := a.maps.RouteMap(c, locations, 0, 0)
+	a.sendMap(c, img, err, trip.Name, "trip_id", trip.ID)
+}
+
+func (a *app) sendMap(c *bot.Context, img *staticmap.Image, err error, caption string, logArgs ...any) {
+	if errors.Is(err, staticmap.ErrNoPoints) {
+		return
+	}
+	if err != nil {
+		slog.WarnContext(c, "Не удалось нарисовать карту", append(logArgs, "err", err)...)
+		return
+	}
+	if img.Attribution != "" {
+		caption += "\n" + img.Attribution
+	}
+	msg := tgbotapi.NewPhoto(c.ChatID, tgbotapi.FileBytes{Name: "map.png", Bytes: img.PNG})
+	msg.Caption = caption
+	if _, err := c.Send(msg); err != nil {
+		slog.WarnContext(c, "Не удалось отправить карту", append(logArgs, "err", err)...)
+	}
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+
+	"tourism/internal/bot"
+	"tourism/internal/model"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// сколько фото помещается в один альбом Telegram
+const albumLimit = 10
+
+// сценарий ввода подписи к фото галереи
+const flowPhotoCaption = "photo_caption"
+
+// photoCaptionPayload — данные сценария ввода подписи.
+type photoCaptionPayload struct {
+	PhotoID int `json:"photo_id"`
+}
+
+// sendGallery отправляет фото локации: одно — отдельным сообщением, несколько — альбомом,
+// который открывает обложка. Подписи фото показываются под каждым снимком альбома.
+func sendGallery(c *bot.Context, photos []model.LocationPhoto) {
+	photos = galleryOrder(photos)
+	switch len(photos) {
+	case 0:
+	case 1:
+		msg := tgbotapi.NewPhoto(c.ChatID, tgbotapi.FileID(photos[0].FileID))
+		msg.Caption = photos[0].Caption
+		c.Send(msg)
+	default:
+		files := []interface{}{}
+		for _, p := range photos {
+			media := tgbotapi.NewInputMediaPhoto(tgbotapi.FileID(p.FileID))
+			media.Caption = p.Caption
+			files = append(files, media)
+		}
+		c.SendMediaGroup(tgbotapi.NewMediaGroup(c.ChatID, files))
+	}
+}
+
+// galleryOrder возвращает не более albumLimit фото для показа: обложку, затем остальные по порядку.
+func galleryOrder(photos []model.LocationPhoto) []model.LocationPhoto {
+	ordered := []model.LocationPhoto{}
+	if i := slices.IndexFunc(photos, func(p model.LocationPhoto) bool { return p.IsCover }); i >= 0 {
+		ordered = append(ordered, photos[i])
+	}
+	for _, p := range photos {
+		if !p.IsCover {
+			ordered = append(ordered, p)
+		}
+	}
+	if len(ordered) > albumLimit {
+		ordered = ordered[:albumLimit]
+	}
+	return ordered
+}
+
+// galleryButton открывает управление фото локации.
+func galleryButton(c *bot.Context, locationID int) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData(c.T("gallery.manage"), fmt.Sprintf("PH_LIST_%d", locationID))
+}
+
+// photosCommand показывает галерею для управления: /photos <ID локации>.
+func (a *app) photosCommand(c *bot.Context) error {
+	id, err := strconv.Atoi(strings.TrimSpace(c.Args()))
+	if err != nil {
+		return c.Reply(c.T("gallery.usage"))
+	}
+	return a.sendPhotoManager(c, id)
+}
+
+// photosList показывает галерею локации, выбранной кнопкой.
+func (a *app) photosList(c *bot.Context) error {
+	id, err := c.IntParam()
+	if err != nil {
+		return err
+	}
+	return a.sendPhotoManager(c, id)
+}
+
+// sendPhotoManager отправляет фото локации по одному с кнопками: переместить выше или ниже,
+// сделать обложкой, изменить подпись и удалить. Управлять галереей могут провайдер локации и поддержка.
+func (a *app) sendPhotoManager(c *bot.Context, locationID int) error {
+	loc, photos, err := a.content.LocationPhotos(c, c.User.ID, locationID)
+	if err != nil {
+		return c.Reply(c.T("gallery.failed", "err", err.Error()))
+	}
+	a.localizeLocation(c, loc)
+	if len(photos) == 0 {
+		return c.Reply(c.T("gallery.empty", "name", loc.Name))
+	}
+	if err := c.Reply(c.T("gallery.title", "name", loc.Name, "count", len(photos))); err != nil {
+		return err
+	}
+	for _, p := range photos {
+		msg := tgbotapi.NewPhoto(c.ChatID, tgbotapi.FileID(p.FileID))
+		msg.Caption = c.T("gallery.photo", "id", p.ID, "position", p.Position, "caption", p.Caption, "cover", p.IsCover)
+		id := strconv.Itoa(p.ID)
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(c.T("gallery.up"), "PH_UP_"+id),
+				tgbotapi.NewInlineKeyboardButtonData(c.T("gallery.down"), "PH_DOWN_"+id),
+				tgbotapi.NewInlineKeyboardButtonData(c.T("gallery.cover"), "PH_COVER_"+id),
+			),
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(c.T("gallery.caption"), "PH_CAPTION_"+id),
+				tgbotapi.NewInlineKeyboardButtonData(c.T("gallery.delete"), "PH_DEL_"+id),
+			),
+		)
+		if _, err := c.Send(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// photoShift перемещает фото на одну позицию выше (PH_UP_) или ниже (PH_DOWN_).
+func (a *app) photoShift(c *bot.Context) error {
+	id, err := c.IntParam()
+	if err != nil {
+		return err
+	}
+	photo, err := a.locRepo.GetPhoto(c, id)
+	if err != nil {
+		return c.Reply(c.T("gallery.failed", "err", c.T("gallery.not_found")))
+	}
+	position := photo.Position + 1
+	if strings.HasPrefix(c.Text(), "PH_UP_") {
+		position = photo.Position - 1
+	}
+	return a.movePhoto(c, id, position)
+}
+
+// photoMoveCommand ставит фото на указанную позицию: /photo_move <ID фото> <позиция>.
+func (a *app) photoMoveCommand(c *bot.Context) error {
+	args := strings.Fields(c.Args())
+	if len(args) != 2 {
+		return c.Reply(c.T("gallery.move_usage"))
+	}
+	id, err1 := strconv.Atoi(args[0])
+	position, err2 := strconv.Atoi(args[1])
+	if err1 != nil || err2 != nil {
+		return c.Reply(c.T("gallery.move_usage"))
+	}
+	return a.movePhoto(c, id, position)
+}
+
+func (a *app) movePhoto(c *bot.Context, id int, position int) error {
+	photo, err := a.content.MovePhoto(c, c.User.ID, id, position)
+	if err != nil {
+		return c.Reply(c.T("gallery.failed", "err", err.Error()))
+	}
+	return c.Reply(c.T("gallery.moved", "id", photo.ID, "position", photo.Position))
+}
+
+// photoCover делает фото обложкой локации.
+func (a *app) photoCover(c *bot.Context) error {
+	id, err := c.IntParam()
+	if err != nil {
+		return err
+	}
+	photo, err := a.content.SetPhotoCover(c, c.User.ID, id)
+	if err != nil {
+		return c.Reply(c.T("gallery.failed", "err", err.Error()))
+	}
+	return c.Reply(c.T("gallery.cover_set", "id", photo.ID))
+}
+
+// photoCaption запрашивает новую подпись к фото.
+func (a *app) photoCaption(c *bot.Context) error {
+	id, err := c.IntParam()
+	if err != nil {
+		return err
+	}
+	if err := c.SetState(flowPhotoCaption, "", photoCaptionPayload{PhotoID: id}); err != nil {
+		return err
+	}
+	return c.Reply(c.T("gallery.caption_prompt", "id", id))
+}
+
+// photoCaptionInput сохраняет подпись к фото; «-» убирает подпись.
+func (a *app) photoCaptionInput(c *bot.Context) error {
+	var p photoCaptionPayload
+	if err := c.Payload(&p); err != nil {
+		return err
+	}
+	caption := strings.TrimSpace(c.Text())
+	if caption == "" {
+		return c.Reply(c.T("gallery.caption_prompt", "id", p.PhotoID))
+	}
+	if caption == "-" {
+		caption = ""
+	}
+	if err := c.ClearState(); err != nil {
+		return err
+	}
+	photo, err := a.content.SetPhotoCaption(c, c.User.ID, p.PhotoID, caption)
+	if err != nil {
+		return c.Reply(c.T("gallery.failed", "err", err.Error()))
+	}
+	return c.Reply(c.T("gallery.caption_set", "id", photo.ID))
+}
+
+// photoDelete просит подтвердить удаление фото.
+func (a *app) photoDelete(c *bot.Context) error {
+	id, err := c.IntParam()
+	if err != nil {
+		return err
+	}
+	msg := tgbotapi.NewMessage(c.ChatID, c.T("gallery.delete_confirm", "id", id))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(c.T("gallery.delete_yes"), fmt.Sprintf("PH_DELOK_%d", id)),
+	))
+	_, err = c.Send(msg)
+	return err
+}
+
+// photoDeleteConfirm удаляет фото после подтверждения кнопкой.
+func (a *app) photoDeleteConfirm(c *bot.Context) error {
+	id, err := c.IntParam()
+	if err != nil {
+		return err
+	}
+	return a.deletePhoto(c, id)
+}
+
+// photoDeleteCommand удаляет фото: /photo_delete <ID фото>.
+func (a *app) photoDeleteCommand(c *bot.Context) error {
+	id, err := strconv.Atoi(strings.TrimSpace(c.Args()))
+	if err != nil {
+		return c.Reply(c.T("gallery.delete_usage"))
+	}
+	return a.deletePhoto(c, id)
+}
+
+func (a *app) deletePhoto(c *bot.Context, id int) error {
+	photo, err := a.content.DeletePhoto(c, c.User.ID, id)
+	if err != nil {
+		return c.Reply(c.T("gallery.failed", "err", err.Error()))
+	}
+	// фото уже удалено из галереи; оставшиеся в хранилище файлы ни на что не влияют
+	if err := a.photoStorage.Remove(c, photo); err != nil {
+		slog.WarnContext(c, "Не удалось удалить файлы фото из хранилища", "photo_id", photo.ID, "err", err)
+	}
+	return c.Reply(c.T("gallery.deleted", "id", photo.ID))
+}
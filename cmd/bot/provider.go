@@ -0,0 +1,157 @@
+package main
+
+import (
+	"strings"
+
+	"tourism/internal/bot"
+	"tourism/internal/model"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/lib/pq"
+)
+
+// сценарий заявки на роль провайдера и его шаги
+const (
+	flowProviderApply        = "provider_apply"
+	applicationStepBusiness  = "business"  // ожидается название бизнеса
+	applicationStepContacts  = "contacts"  // ожидаются контакты
+	applicationStepDocuments = "documents" // ожидаются фото документов
+	applicationStepLocations = "locations" // ожидаются названия своих локаций
+)
+
+// ответ на шаге локаций, если своих локаций в каталоге нет
+const applicationNoLocations = "-"
+
+// сколько фото документов можно приложить к заявке
+const applicationDocumentsLimit = 10
+
+// applicationPayload — данные сценария заявки.
+type applicationPayload struct {
+	BusinessName string   `json:"business_name,omitempty"`
+	Contacts     string   `json:"contacts,omitempty"`
+	Documents    []string `json:"documents,omitempty"`
+}
+
+// becomeProvider начинает заявку на роль провайдера или сообщает о состоянии поданной.
+func (a *app) becomeProvider(c *bot.Context) error {
+	if c.User.Role != "user" {
+		return c.Reply(c.T("provider.already"))
+	}
+	latest, err := a.providers.LatestApplication(c, c.User.ID)
+	if err != nil {
+		return err
+	}
+	if latest != nil && latest.Status == model.ApplicationPending {
+		return c.Reply(c.T("provider.application_pending", "id", latest.ID))
+	}
+	if err := c.SetState(flowProviderApply, applicationStepBusiness, applicationPayload{}); err != nil {
+		return err
+	}
+	return c.Reply(c.T("provider.business_prompt"))
+}
+
+// applicationInput обрабатывает шаги заявки: название, контакты, фото документов и локации.
+func (a *app) applicationInput(c *bot.Context) error {
+	var p applicationPayload
+	if err := c.Payload(&p); err != nil {
+		return err
+	}
+	text := strings.TrimSpace(c.Text())
+	switch c.State.Step {
+	case applicationStepBusiness:
+		if text == "" {
+			return c.Reply(c.T("provider.business_prompt"))
+		}
+		p.BusinessName = text
+		if err := c.SetState(flowProviderApply, applicationStepContacts, p); err != nil {
+			return err
+		}
+		return c.Reply(c.T("provider.contacts_prompt"))
+	case applicationStepContacts:
+		if text == "" {
+			return c.Reply(c.T("provider.contacts_prompt"))
+		}
+		p.Contacts = text
+		if err := c.SetState(flowProviderApply, applicationStepDocuments, p); err != nil {
+			return err
+		}
+		return a.documentsPrompt(c, "provider.documents_prompt")
+	case applicationStepDocuments:
+		photoID := c.PhotoID()
+		if photoID == "" {
+			return a.documentsPrompt(c, "provider.documents_hint")
+		}
+		if len(p.Documents) >= applicationDocumentsLimit {
+			return c.Reply(c.T("provider.documents_limit", "limit", applicationDocumentsLimit))
+		}
+		p.Documents = append(p.Documents, photoID)
+		if err := c.SetState(flowProviderApply, applicationStepDocuments, p); err != nil {
+			return err
+		}
+		return a.documentsPrompt(c, "provider.document_added")
+	case applicationStepLocations:
+		return a.submitApplication(c, p, text)
+	}
+	return c.ClearState()
+}
+
+// documentsPrompt отправляет сообщение шага документов с кнопкой завершения.
+func (a *app) documentsPrompt(c *bot.Context, key string) error {
+	msg := tgbotapi.NewMessage(c.ChatID, c.T(key, "limit", applicationDocumentsLimit))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(c.T("provider.documents_done"), "PROVIDER_DOCS_DONE"),
+	))
+	_, err := c.Send(msg)
+	return err
+}
+
+// applicationDocumentsDone завершает загрузку документов и спрашивает о локациях заявителя.
+func (a *app) applicationDocumentsDone(c *bot.Context) error {
+	if c.State == nil || c.State.Flow != flowProviderApply || c.State.Step != applicationStepDocuments {
+		return nil
+	}
+	var p applicationPayload
+	if err := c.Payload(&p); err != nil {
+		return err
+	}
+	if len(p.Documents) == 0 {
+		return c.Reply(c.T("provider.documents_required"))
+	}
+	if err := c.SetState(flowProviderApply, applicationStepLocations, p); err != nil {
+		return err
+	}
+	return c.Reply(c.T("provider.locations_prompt", "none", applicationNoLocations))
+}
+
+// submitApplication сопоставляет названия локаций с каталогом и отправляет заявку на проверку.
+func (a *app) submitApplication(c *bot.Context, p applicationPayload, locations string) error {
+	if locations == "" {
+		return c.Reply(c.T("provider.locations_prompt", "none", applicationNoLocations))
+	}
+	ids, unresolved := []int{}, []string{}
+	if locations != applicationNoLocations {
+		var err error
+		ids, unresolved, err = a.providers.FindLocations(c, strings.Split(locations, ","))
+		if err != nil {
+			return err
+		}
+	}
+	if err := c.ClearState(); err != nil {
+		return err
+	}
+	application := &model.ProviderApplication{
+		UserID:          c.User.ID,
+		BusinessName:    p.BusinessName,
+		Contacts:        p.Contacts,
+		DocumentFileIDs: pq.StringArray(p.Documents),
+		LocationsNote:   strings.Join(unresolved, ", "),
+	}
+	for _, id := range ids {
+		application.LocationIDs = append(application.LocationIDs, int64(id))
+	}
+	if _, err := a.providers.Apply(c, application); err != nil {
+		return c.Reply(c.T("provider.application_failed", "err", err.Error()))
+	}
+	// поддержка видит заявку в очереди /applications, решение приходит уведомлением
+	return c.Reply(c.T("provider.application_sent", "id", application.ID, "found", len(ids), "unresolved", unresolved))
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"tourism/internal/bot"
+	"tourism/internal/model"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// шаги диалога отзыва
+const (
+	reviewStepText   = "text"   // ожидается текст отзыва (или фото с подписью)
+	reviewStepPhotos = "photos" // ожидаются фото к уже сохраненному отзыву
+	reviewStepReply  = "reply"  // провайдер пишет ответ на отзыв
+)
+
+// сценарий отзыва: шаги reviewStep*
+const flowReview = "review"
+
+// reviewPayload — данные сценария отзыва.
+type reviewPayload struct {
+	LocationID int `json:"location_id,omitempty"`
+	Rating     int `json:"rating,omitempty"`
+	ReviewID   int `json:"review_id,omitempty"`
+}
+
+// сколько отзывов показывать в карточке
+const reviewsPageSize = 5
+
+// ratingKeyboard формирует кнопки выбора оценки.
+func ratingKeyboard(locationID int) tgbotapi.InlineKeyboardMarkup {
+	row := []tgbotapi.InlineKeyboardButton{}
+	for i := 1; i <= 5; i++ {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(
+			"⭐"+fmt.Sprint(i), fmt.Sprintf("REVIEW_RATE_%d_%d", locationID, i),
+		))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(row)
+}
+
+// reviewsMessage формирует список отзывов о локации.
+func reviewsMessage(c *bot.Context, loc *model.Location, reviews []model.Review, total int) tgbotapi.MessageConfig {
+	rows := [][]tgbotapi.InlineKeyboardButton{}
+	for _, r := range reviews {
+		if len(r.PhotoFileIDs) > 0 {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(
+				c.T("review.photos_button", "author", r.AuthorName), fmt.Sprintf("REVIEW_PHOTOS_%d", r.ID),
+			)))
+		}
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(c.T("review.leave"), fmt.Sprintf("REVIEW_NEW_%d", loc.ID)),
+	))
+	text := c.T("review.list", "location", loc.Name, "rating", loc.Rating, "total", total, "reviews", reviews)
+	msg := tgbotapi.NewMessage(c.ChatID, strings.TrimRight(text, "\n"))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return msg
+}
+
+// reviewPhotos формирует альбом фотографий отзыва.
+func reviewPhotos(chatID int64, r *model.Review) tgbotapi.MediaGroupConfig {
+	files := []interface{}{}
+	for _, id := range r.PhotoFileIDs {
+		files = append(files, tgbotapi.NewInputMediaPhoto(tgbotapi.FileID(id)))
+	}
+	return tgbotapi.NewMediaGroup(chatID, files)
+}
+
+// reviewsList показывает опубликованные отзывы о локации.
+func (a *app) reviewsList(c *bot.Context) error {
+	id, err := c.IntParam()
+	if err != nil {
+		return err
+	}
+	loc, err := a.locRepo.GetByID(c, id)
+	if err != nil {
+		return c.Reply(c.T("location.not_found"))
+	}
+	a.localizeLocation(c, loc)
+	reviews, total, err := a.reviews.ListReviews(c, id, reviewsPageSize, 0)
+	if err != nil {
+		slog.ErrorContext(c, "Не удалось загрузить отзывы", "location_id", id, "err", err)
+		return c.Reply(c.T("review.load_failed"))
+	}
+	_, err = c.Send(reviewsMessage(c, loc, reviews, total))
+	return err
+}
+
+// reviewNew проверяет, что пользователь может оценить локацию, и предлагает выбрать оценку.
+func (a *app) reviewNew(c *bot.Context) error {
+	locID, err := c.IntParam()
+	if err != nil {
+		return err
+	}
+	if err := a.reviews.CheckCanReview(c, c.User.ID, locID); err != nil {
+		return c.Reply(err.Error())
+	}
+	ask := tgbotapi.NewMessage(c.ChatID, c.T("review.rate_prompt"))
+	ask.ReplyMarkup = ratingKeyboard(locID)
+	_, err = c.Send(ask)
+	return err
+}
+
+// reviewRate запоминает оценку и запрашивает текст отзыва.
+func (a *app) reviewRate(c *bot.Context) error {
+	var locID, rating int
+	if _, err := fmt.Sscanf(c.Param(), "%d_%d", &locID, &rating); err != nil {
+		return fmt.Errorf("некорректная оценка %q: %w", c.Param(), err)
+	}
+	if err := c.SetState(flowReview, reviewStepText, reviewPayload{LocationID: locID, Rating: rating}); err != nil {
+		return err
+	}
+	return c.Reply(c.T("review.text_prompt"))
+}
+
+// reviewDone завершает добавление фото к отзыву.
+func (a *app) reviewDone(c *bot.Context) error {
+	if c.State != nil && c.State.Flow == flowReview {
+		if err := c.ClearState(); err != nil {
+			return err
+		}
+	}
+	return c.Reply(c.T("review.thanks"))
+}
+
+// reviewPhotoAlbum отправляет фотографии отзыва альбомом.
+func (a *app) reviewPhotoAlbum(c *bot.Context) error {
+	reviewID, err := c.IntParam()
+	if err != nil {
+		return err
+	}
+	if r, err := a.reviews.GetReview(c, reviewID); err == nil && len(r.PhotoFileIDs) > 0 {
+		c.SendMediaGroup(reviewPhotos(c.ChatID, r))
+	}
+	return nil
+}
+
+// reviewReply запрашивает у провайдера текст ответа на отзыв.
+func (a *app) reviewReply(c *bot.Context) error {
+	reviewID, err := c.IntParam()
+	if err != nil {
+		return err
+	}
+	if err := c.SetState(flowReview, reviewStepReply, reviewPayload{ReviewID: reviewID}); err != nil {
+		return err
+	}
+	return c.Reply(c.T("review.reply_prompt"))
+}
+
+// reviewInput обрабатывает шаги сценария отзыва: текст, фото и ответ провайдера.
+func (a *app) reviewInput(c *bot.Context) error {
+	var p reviewPayload
+	if err := c.Payload(&p); err != nil {
+		return err
+	}
+	photoID := c.PhotoID()
+	switch c.State.Step {
+	case reviewStepText:
+		photos := []string{}
+		if photoID != "" {
+			photos = append(photos, photoID)
+		}
+		review, err := a.reviews.CreateReview(c, c.User.ID, p.LocationID, p.Rating, c.Text(), photos)
+		if review == nil {
+			c.ClearState()
+			return c.Reply(c.T("review.save_failed", "err", err.Error()))
+		}
+		p.ReviewID = review.ID
+		if err := c.SetState(flowReview, reviewStepPhotos, p); err != nil {
+			return err
+		}
+		done := tgbotapi.NewMessage(c.ChatID, c.T("review.saved", "pending", review.Status == "pending"))
+		done.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(c.T("review.done"), "REVIEW_DONE"),
+		))
+		// провайдер узнает об опубликованном отзыве из очереди уведомлений
+		_, err = c.Send(done)
+		return err
+	case reviewStepPhotos:
+		if photoID == "" {
+			// любое другое сообщение завершает добавление фото и обрабатывается как обычно
+			if err := c.ClearState(); err != nil {
+				return err
+			}
+			return a.fallback(c)
+		}
+		review, err := a.reviews.AddPhoto(c, c.User.ID, p.ReviewID, photoID)
+		if err != nil {
+			return c.Reply(err.Error())
+		}
+		return c.Reply(c.T("review.photo_added", "pending", review.Status == "pending"))
+	case reviewStepReply:
+		if err := c.ClearState(); err != nil {
+			return err
+		}
+		if _, err := a.reviews.Reply(c, c.User.ID, p.ReviewID, c.Text()); err != nil {
+			return c.Reply(c.T("review.reply_failed", "err", err.Error()))
+		}
+		return c.Reply(c.T("review.reply_published"))
+	}
+	return c.ClearState()
+}
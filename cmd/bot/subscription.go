@@ -0,0 +1,133 @@
+package main
+
+import (
+	"strings"
+
+	"tourism/internal/bot"
+	"tourism/internal/model"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// subscriptionMenu формирует текст и inline-клавиатуру настроек подписки.
+// sub == nil означает, что пользователь не подписан.
+func subscriptionMenu(c *bot.Context, sub *model.OfferSubscription, regions []string) (string, tgbotapi.InlineKeyboardMarkup) {
+	if sub == nil {
+		return c.T("subscription.none"), tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(c.T("subscription.subscribe"), "SUB_ON")),
+		)
+	}
+
+	topics := []string{}
+	topicRow := []tgbotapi.InlineKeyboardButton{}
+	for _, t := range model.Topics {
+		title := c.T("subscription.topic." + t)
+		mark := "⬜ "
+		if sub.HasTopic(t) {
+			mark = "✅ "
+			topics = append(topics, title)
+		}
+		topicRow = append(topicRow, tgbotapi.NewInlineKeyboardButtonData(mark+title, "SUB_TOPIC_"+t))
+	}
+	rows := [][]tgbotapi.InlineKeyboardButton{topicRow}
+
+	for _, r := range regions {
+		data := "SUB_REGION_" + r
+		if len(data) > 64 { // ограничение Telegram на callback_data
+			continue
+		}
+		mark := "⬜ "
+		if sub.HasRegion(r) {
+			mark = "✅ "
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(mark+r, data)))
+	}
+
+	instant, weekly := c.T("subscription.instant"), c.T("subscription.weekly")
+	if sub.Frequency == model.FrequencyWeekly {
+		weekly = "✅ " + weekly
+	} else {
+		instant = "✅ " + instant
+	}
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(instant, "SUB_FREQ_"+model.FrequencyInstant),
+			tgbotapi.NewInlineKeyboardButtonData(weekly, "SUB_FREQ_"+model.FrequencyWeekly),
+		),
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(c.T("subscription.unsubscribe"), "SUB_OFF")),
+	)
+
+	text := c.T("subscription.settings",
+		"topics", topics, "regions", sub.Regions, "weekly", sub.Frequency == model.FrequencyWeekly)
+	return text, tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// subscribe оформляет подписку (если ее нет) и показывает настройки.
+func (a *app) subscribe(c *bot.Context) error {
+	sub, err := a.offers.GetPreferences(c, c.User.ID)
+	if err == nil && sub == nil {
+		sub, err = a.offers.SetFrequency(c, c.User.ID, model.FrequencyInstant)
+	}
+	if err != nil {
+		return c.Reply(c.T("subscription.load_failed"))
+	}
+	return a.sendSubscriptionMenu(c, sub)
+}
+
+// subscriptionSettings показывает настройки подписки.
+func (a *app) subscriptionSettings(c *bot.Context) error {
+	sub, err := a.offers.GetPreferences(c, c.User.ID)
+	if err != nil {
+		return c.Reply(c.T("subscription.load_failed"))
+	}
+	return a.sendSubscriptionMenu(c, sub)
+}
+
+func (a *app) sendSubscriptionMenu(c *bot.Context, sub *model.OfferSubscription) error {
+	regions, err := a.offers.ListRegions(c)
+	if err != nil {
+		return c.Reply(c.T("subscription.load_failed"))
+	}
+	menuText, markup := subscriptionMenu(c, sub, regions)
+	menu := tgbotapi.NewMessage(c.ChatID, menuText)
+	menu.ReplyMarkup = markup
+	_, err = c.Send(menu)
+	return err
+}
+
+// unsubscribe отменяет подписку на предложения.
+func (a *app) unsubscribe(c *bot.Context) error {
+	if err := a.offers.Unsubscribe(c, c.User.ID); err != nil {
+		return c.Reply(c.T("subscription.unsubscribe_failed"))
+	}
+	return c.Reply(c.T("subscription.unsubscribed"))
+}
+
+// subscriptionUpdate применяет нажатую кнопку меню подписки и обновляет меню.
+func (a *app) subscriptionUpdate(c *bot.Context) error {
+	data := c.Text()
+	var sub *model.OfferSubscription
+	var err error
+	switch {
+	case data == "SUB_ON":
+		sub, err = a.offers.SetFrequency(c, c.User.ID, model.FrequencyInstant)
+	case data == "SUB_OFF":
+		err = a.offers.Unsubscribe(c, c.User.ID)
+	case strings.HasPrefix(data, "SUB_TOPIC_"):
+		sub, err = a.offers.ToggleTopic(c, c.User.ID, strings.TrimPrefix(data, "SUB_TOPIC_"))
+	case strings.HasPrefix(data, "SUB_REGION_"):
+		sub, err = a.offers.ToggleRegion(c, c.User.ID, strings.TrimPrefix(data, "SUB_REGION_"))
+	case strings.HasPrefix(data, "SUB_FREQ_"):
+		sub, err = a.offers.SetFrequency(c, c.User.ID, strings.TrimPrefix(data, "SUB_FREQ_"))
+	}
+	if err != nil {
+		return c.Reply(c.T("subscription.update_failed", "err", err.Error()))
+	}
+	regions, err := a.offers.ListRegions(c)
+	if err != nil {
+		return c.Reply(c.T("subscription.load_failed"))
+	}
+	menuText, markup := subscriptionMenu(c, sub, regions)
+	_, err = c.Send(tgbotapi.NewEditMessageTextAndMarkup(c.ChatID, c.Callback().Message.MessageID, menuText, markup))
+	return err
+}
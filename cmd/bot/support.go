@@ -0,0 +1,99 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"tourism/internal/bot"
+)
+
+// сценарий добавления фото локации оператором
+const flowAddPhoto = "add_photo"
+
+// шаги сценария добавления фото
+const (
+	addPhotoStepLocation = "location" // ожидается ID локации
+	addPhotoStepPhoto    = "photo"    // ожидается фото
+)
+
+// addPhotoPayload — данные сценария добавления фото.
+type addPhotoPayload struct {
+	LocationID int `json:"location_id,omitempty"`
+}
+
+// support выдает ссылку на бот поддержки.
+func (a *app) support(c *bot.Context) error {
+	if a.supportBot == "" {
+		return c.Reply(c.T("support.unavailable"))
+	}
+	return c.Reply(c.T("support.link", "bot", strings.TrimPrefix(a.supportBot, "@")))
+}
+
+// addPhotoStart запрашивает у оператора ID локации для нового фото.
+func (a *app) addPhotoStart(c *bot.Context) error {
+	if err := c.SetState(flowAddPhoto, addPhotoStepLocation, nil); err != nil {
+		return err
+	}
+	return c.Reply(c.T("photo.location_prompt"))
+}
+
+// addPhotoFor запрашивает фото для локации, выбранной кнопкой.
+func (a *app) addPhotoFor(c *bot.Context) error {
+	id, err := c.IntParam()
+	if err != nil {
+		return err
+	}
+	return a.askLocationPhoto(c, id)
+}
+
+func (a *app) askLocationPhoto(c *bot.Context, locationID int) error {
+	loc, err := a.locRepo.GetByID(c, locationID)
+	if err != nil {
+		return c.Reply(c.T("photo.location_not_found"))
+	}
+	if err := c.SetState(flowAddPhoto, addPhotoStepPhoto, addPhotoPayload{LocationID: loc.ID}); err != nil {
+		return err
+	}
+	return c.Reply(c.T("photo.prompt", "name", loc.Name))
+}
+
+// addPhotoInput обрабатывает ID локации и фото с необязательной подписью.
+func (a *app) addPhotoInput(c *bot.Context) error {
+	if c.State.Step == addPhotoStepLocation {
+		id, err := strconv.Atoi(strings.TrimSpace(c.Text()))
+		if err != nil {
+			return c.Reply(c.T("photo.location_id_expected"))
+		}
+		return a.askLocationPhoto(c, id)
+	}
+	var p addPhotoPayload
+	if err := c.Payload(&p); err != nil {
+		return err
+	}
+	fileID := c.PhotoID()
+	if fileID == "" {
+		return c.Reply(c.T("photo.expected"))
+	}
+	if err := c.ClearState(); err != nil {
+		return err
+	}
+	// подпись к присланному фото становится подписью в галерее
+	if err := a.locations.AddPhoto(c, p.LocationID, fileID, c.Text()); err != nil {
+		return c.Reply(c.T("photo.failed", "err", err.Error()))
+	}
+	return c.Reply(c.T("photo.saved"))
+}
+
+// checkLocations показывает оператору локации, которым не хватает фото.
+func (a *app) checkLocations(c *bot.Context) error {
+	locations, err := a.locations.ListWithoutPhotos(c)
+	if err != nil {
+		return err
+	}
+	if len(locations) == 0 {
+		return c.Reply(c.T("photo.all_have_photos"))
+	}
+	a.localizeLocations(c, locations)
+	_, err = c.Send(locationList(c, c.T("photo.without_photos"), locations, "PHOTO_ADD_"))
+	return err
+}
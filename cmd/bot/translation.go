@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"tourism/internal/bot"
+	"tourism/internal/model"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// сценарий перевода локации или предложения и его шаги
+const (
+	flowTranslate            = "translate"
+	translateStepName        = "name"        // ожидается название на выбранном языке
+	translateStepDescription = "description" // ожидается описание
+)
+
+// ответ, которым пропускается перевод описания
+const translateSkip = "-"
+
+// translatePayload — данные сценария перевода.
+type translatePayload struct {
+	Entity   string `json:"entity"`
+	EntityID int    `json:"entity_id"`
+	Locale   string `json:"locale"`
+	Name     string `json:"name,omitempty"`
+}
+
+// translateButton возвращает кнопку перевода записи entity/id.
+func translateButton(c *bot.Context, entity string, id int) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData(c.T("translation.button"), fmt.Sprintf("TRANSLATE_%s_%d", entity, id))
+}
+
+// canTranslate сообщает, показывать ли пользователю кнопку перевода записи провайдера providerID.
+func canTranslate(c *bot.Context, providerID *int) bool {
+	return c.User.Role == "support" || (c.User.Role == "provider" && providerID != nil && *providerID == c.User.ID)
+}
+
+// canTranslateOffer сообщает, показывать ли кнопку перевода предложения: поддержке всегда,
+// провайдеру — для предложений его локаций.
+func (a *app) canTranslateOffer(c *bot.Context, o *model.Offer) bool {
+	if c.User.Role != "provider" {
+		return canTranslate(c, nil)
+	}
+	loc, err := a.locRepo.GetByID(c, o.LocationID)
+	return err == nil && canTranslate(c, loc.ProviderID)
+}
+
+// translateStart предлагает выбрать язык перевода; основной язык контента в списке не показывается.
+func (a *app) translateStart(c *bot.Context) error {
+	entity, id, err := translateTarget(c.Param())
+	if err != nil {
+		return err
+	}
+	rows := [][]tgbotapi.InlineKeyboardButton{}
+	for _, l := range c.Texts.Locales() {
+		if l == model.ContentLanguage {
+			continue
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(
+			c.Texts.Text(l, "language.name"), fmt.Sprintf("TRANSLATE_LANG_%s_%d_%s", entity, id, l))))
+	}
+	msg := tgbotapi.NewMessage(c.ChatID, c.T("translation.choose_language"))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	_, err = c.Send(msg)
+	return err
+}
+
+// translateLocale запоминает язык перевода и запрашивает название.
+func (a *app) translateLocale(c *bot.Context) error {
+	// данные кнопки: "<сущность>_<ID>_<локаль>"
+	sep := strings.LastIndex(c.Param(), "_")
+	if sep < 0 {
+		return fmt.Errorf("некорректные данные перевода %q", c.Param())
+	}
+	target, locale := c.Param()[:sep], c.Param()[sep+1:]
+	if !c.Texts.HasLocale(locale) || locale == model.ContentLanguage {
+		return c.Reply(c.T("language.unsupported"))
+	}
+	entity, id, err := translateTarget(target)
+	if err != nil {
+		return err
+	}
+	if err := c.SetState(flowTranslate, translateStepName, translatePayload{Entity: entity, EntityID: id, Locale: locale}); err != nil {
+		return err
+	}
+	return c.Reply(c.T("translation.name_prompt", "language", c.Texts.Text(locale, "language.name")))
+}
+
+// translateInput обрабатывает шаги сценария перевода: название и описание.
+func (a *app) translateInput(c *bot.Context) error {
+	var p translatePayload
+	if err := c.Payload(&p); err != nil {
+		return err
+	}
+	text := strings.TrimSpace(c.Text())
+	if text == "" {
+		return c.Reply(c.T("translation.text_required"))
+	}
+	if c.State.Step == translateStepName {
+		p.Name = text
+		if err := c.SetState(flowTranslate, translateStepDescription, p); err != nil {
+			return err
+		}
+		return c.Reply(c.T("translation.description_prompt", "skip", translateSkip))
+	}
+	if err := c.ClearState(); err != nil {
+		return err
+	}
+	if text == translateSkip {
+		text = ""
+	}
+	t, err := a.translations.Submit(c, c.User.ID, p.Entity, p.EntityID, p.Locale, p.Name, text)
+	if err != nil {
+		return c.Reply(c.T("translation.failed", "err", err.Error()))
+	}
+	// поддержка узнает о новом переводе из очереди в боте поддержки
+	return c.Reply(c.T("translation.saved", "pending", t.Status == model.TranslationPending))
+}
+
+// translateTarget разбирает данные кнопки перевода "<сущность>_<ID>".
+func translateTarget(param string) (string, int, error) {
+	var id int
+	entity, rest, _ := strings.Cut(param, "_")
+	if _, err := fmt.Sscanf(rest, "%d", &id); err != nil || (entity != model.TranslationLocation && entity != model.TranslationOffer) {
+		return "", 0, fmt.Errorf("некорректные данные перевода %q", param)
+	}
+	return entity, id, nil
+}
+
+// localizeLocations показывает локации на языке пользователя; если переводы не загрузились,
+// локации остаются на основном языке.
+func (a *app) localizeLocations(c *bot.Context, locations []model.Location) {
+	if err := a.translations.LocalizeLocations(c, c.Locale(), locations); err != nil {
+		slog.WarnContext(c, "Не удалось загрузить переводы локаций", "locale", c.Locale(), "err", err)
+	}
+}
+
+// localizeLocation показывает локацию на языке пользователя.
+func (a *app) localizeLocation(c *bot.Context, loc *model.Location) {
+	if err := a.translations.LocalizeLocation(c, c.Locale(), loc); err != nil {
+		slog.WarnContext(c, "Не удалось загрузить перевод локации", "location_id", loc.ID, "locale", c.Locale(), "err", err)
+	}
+}
+
+// localizeOffers показывает предложения на языке пользователя.
+func (a *app) localizeOffers(c *bot.Context, offers []model.Offer) {
+	if err := a.translations.LocalizeOffers(c, c.Locale(), offers); err != nil {
+		slog.WarnContext(c, "Не удалось загрузить переводы предложений", "locale", c.Locale(), "err", err)
+	}
+}
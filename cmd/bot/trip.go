@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"tourism/internal/bot"
+	"tourism/internal/model"
+	"tourism/internal/service"
+)
+
+// сценарий создания маршрута: ожидается название
+const flowNewTrip = "new_trip"
+
+// newTrip создает маршрут с названием из аргумента команды или запрашивает название.
+func (a *app) newTrip(c *bot.Context) error {
+	if name := strings.TrimSpace(c.Args()); name != "" {
+		return a.createTrip(c, name)
+	}
+	if err := c.SetState(flowNewTrip, "", nil); err != nil {
+		return err
+	}
+	return c.Reply(c.T("trip.name_prompt"))
+}
+
+// newTripName создает маршрут с введенным названием.
+func (a *app) newTripName(c *bot.Context) error {
+	name := strings.TrimSpace(c.Text())
+	if name == "" {
+		return c.Reply(c.T("trip.name_empty"))
+	}
+	if err := c.ClearState(); err != nil {
+		return err
+	}
+	return a.createTrip(c, name)
+}
+
+func (a *app) createTrip(c *bot.Context, name string) error {
+	if _, err := a.trips.CreateTrip(c, c.User.ID, name); err != nil {
+		return err
+	}
+	return c.Reply(c.T("trip.created", "name", name))
+}
+
+// addToTrip добавляет локацию в текущий маршрут пользователя.
+func (a *app) addToTrip(c *bot.Context) error {
+	id, err := c.IntParam()
+	if err != nil {
+		return err
+	}
+	trip, err := a.trips.GetActiveTrip(c, c.User.ID)
+	if err != nil {
+		return err
+	}
+	if trip == nil {
+		return c.Reply(c.T("trip.none"))
+	}
+	if err := a.trips.AddLocationToTrip(c, trip.ID, id); err != nil {
+		return err
+	}
+	return c.Reply(c.T("trip.location_added", "name", trip.Name))
+}
+
+// optimizeTrip упорядочивает точки текущего маршрута и присылает итоговый порядок и карту маршрута.
+func (a *app) optimizeTrip(c *bot.Context) error {
+	trip, err := a.trips.GetActiveTrip(c, c.User.ID)
+	if err != nil {
+		return err
+	}
+	if trip == nil {
+		return c.Reply(c.T("trip.none"))
+	}
+	locations, err := a.trips.OptimizeTrip(c, trip.ID)
+	if err != nil {
+		return err
+	}
+	if len(locations) == 0 {
+		return c.Reply(c.T("trip.empty"))
+	}
+	a.localizeLocations(c, locations)
+	if err := c.Reply(tripSummary(c, trip, locations)); err != nil {
+		return err
+	}
+	a.sendRouteMap(c, trip, locations)
+	return nil
+}
+
+// finishTrip завершает текущий маршрут пользователя: после этого о его локациях можно оставить отзыв.
+func (a *app) finishTrip(c *bot.Context) error {
+	trip, err := a.trips.GetActiveTrip(c, c.User.ID)
+	if err != nil {
+		return err
+	}
+	if trip == nil {
+		return c.Reply(c.T("trip.none"))
+	}
+	_, err = a.trips.CompleteTrip(c, c.User.ID, trip.ID)
+	switch {
+	case errors.Is(err, service.ErrTripEmpty):
+		return c.Reply(c.T("trip.empty"))
+	case errors.Is(err, service.ErrTripCompleted):
+		return c.Reply(c.T("trip.already_completed"))
+	case err != nil:
+		return err
+	}
+	return c.Reply(c.T("trip.completed", "name", trip.Name))
+}
+
+// tripSummary формирует порядок посещения точек и ссылку на маршрут в картах.
+func tripSummary(c *bot.Context, trip *model.Trip, locations []model.Location) string {
+	points := []string{}
+	for _, loc := range locations {
+		points = append(points, fmt.Sprintf("%f,%f", loc.Latitude, loc.Longitude))
+	}
+	return c.T("trip.summary", "name", trip.Name, "locations", locations,
+		"map", "https://www.google.com/maps/dir/"+strings.Join(points, "/"))
+}
@@ -0,0 +1,58 @@
+// Команда setrole назначает роль пользователю по его ID или Telegram ID. Нужна прежде всего для
+// назначения первого администратора: дальше роли меняют администраторы в боте или через API.
+// Пользователь должен хотя бы раз написать боту; изменение записывается в журнал без автора.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"tourism/internal/config"
+	"tourism/internal/repository"
+	"tourism/internal/service"
+)
+
+func main() {
+	userID := flag.Int("user-id", 0, "ID пользователя в базе")
+	telegramID := flag.Int64("telegram-id", 0, "Telegram ID пользователя")
+	role := flag.String("role", "admin", "назначаемая роль: user, provider, support или admin")
+	flag.Parse()
+
+	if (*userID == 0) == (*telegramID == 0) {
+		log.Fatal("укажите ровно один из флагов -user-id или -telegram-id")
+	}
+
+	cfg, err := config.Load(config.AppTool)
+	if err != nil {
+		log.Fatal(err)
+	}
+	db, err := cfg.DB.Connect()
+	if err != nil {
+		log.Fatal(err)
+	}
+	store := repository.NewDB(db, cfg.DB.QueryTimeout.Std())
+	userRepo := repository.NewUserRepository(store)
+	admin := service.NewAdminService(store, userRepo, repository.NewBookingRepository(store),
+		repository.NewTripRepository(store), repository.NewMessageRepository(store),
+		repository.NewAuditRepository(store), repository.NewNotificationRepository(store))
+
+	ctx := context.Background()
+	if *telegramID != 0 {
+		user, err := userRepo.GetByTelegramID(ctx, *telegramID)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if user == nil {
+			log.Fatalf("пользователь с Telegram ID %d не найден: он должен сначала написать боту", *telegramID)
+		}
+		*userID = user.ID
+	}
+
+	user, err := admin.GrantRole(ctx, *userID, *role)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("пользователю %d (%s) назначена роль %s\n", user.ID, user.FirstName, user.Role)
+}
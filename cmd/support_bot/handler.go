@@ -0,0 +1,279 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"tourism/internal/bot"
+	"tourism/internal/i18n"
+	"tourism/internal/model"
+	"tourism/internal/repository"
+	"tourism/internal/service"
+	"tourism/internal/telegram"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// supportBot обрабатывает обновления бота поддержки.
+type supportBot struct {
+	api          telegram.Sender
+	users        repository.UserStore
+	messages     repository.MessageStore
+	locRepo      repository.LocationStore
+	reviews      *service.ReviewService
+	translations *service.TranslationService
+	accounts     *service.UserService
+	texts        *i18n.Registry
+}
+
+// run обрабатывает обновления параллельно, сохраняя порядок внутри чата, пока не будет отменен ctx.
+func (b *supportBot) run(ctx context.Context, updates telegram.Updates, cfg bot.DispatcherConfig) {
+	bot.NewDispatcher(cfg, b.handle).Run(ctx, updates)
+}
+
+// send отправляет сообщение от бота поддержки; ошибка отправки логируется с полями обновления из ctx.
+func (b *supportBot) send(ctx context.Context, c tgbotapi.Chattable) {
+	send(ctx, b.api, c)
+}
+
+// reply отправляет в чат chatID сообщение key на языке locale.
+func (b *supportBot) reply(ctx context.Context, chatID int64, locale, key string, args ...any) {
+	b.send(ctx, tgbotapi.NewMessage(chatID, b.texts.Text(locale, key, args...)))
+}
+
+// handle обрабатывает одно обновление: решения модератора, команды и обращения пользователей.
+func (b *supportBot) handle(ctx context.Context, update tgbotapi.Update) {
+	// решения модератора по отзывам и переводам
+	if cq := update.CallbackQuery; cq != nil {
+		if _, err := b.api.Request(tgbotapi.NewCallback(cq.ID, "")); err != nil {
+			slog.WarnContext(ctx, "Не удалось ответить на нажатие кнопки", "err", err)
+		}
+		chatID := cq.Message.Chat.ID
+		locale := b.texts.Resolve(cq.From.LanguageCode)
+		op, err := b.users.GetByTelegramID(ctx, cq.From.ID)
+		if err == nil {
+			locale = b.texts.Resolve(op.PreferredLanguage())
+		}
+		if err == nil && op.IsBlocked {
+			return
+		}
+		if lang, ok := strings.CutPrefix(cq.Data, bot.LanguagePrefix); ok && err == nil {
+			b.setLanguage(ctx, chatID, op, lang)
+			return
+		}
+		if err != nil || op.Role != "support" {
+			b.reply(ctx, chatID, locale, "support.command_unavailable")
+			return
+		}
+		parts := strings.Split(cq.Data, "_")
+		if len(parts) != 3 {
+			return
+		}
+		id, _ := strconv.Atoi(parts[2])
+		var result string
+		switch parts[0] + "_" + parts[1] {
+		case "MOD_APPROVE":
+			if _, err := b.reviews.Approve(ctx, op.ID, id); err != nil {
+				result = err.Error()
+			} else {
+				result = b.texts.Text(locale, "support.review_approved", "id", id)
+			}
+		case "MOD_REJECT":
+			if _, err := b.reviews.Reject(ctx, op.ID, id, ""); err != nil {
+				result = err.Error()
+			} else {
+				result = b.texts.Text(locale, "support.review_rejected", "id", id)
+			}
+		case "TRN_APPROVE":
+			if _, err := b.translations.Approve(ctx, op.ID, id); err != nil {
+				result = err.Error()
+			} else {
+				result = b.texts.Text(locale, "translation.approved", "id", id)
+			}
+		case "TRN_REJECT":
+			if _, err := b.translations.Reject(ctx, op.ID, id, ""); err != nil {
+				result = err.Error()
+			} else {
+				result = b.texts.Text(locale, "translation.rejected", "id", id)
+			}
+		default:
+			return
+		}
+		b.send(ctx, tgbotapi.NewEditMessageText(chatID, cq.Message.MessageID, cq.Message.Text+"\n\n"+result))
+		return
+	}
+	if update.Message == nil {
+		return
+	}
+	msg := update.Message
+	chatID := msg.Chat.ID
+	userTelegramID := msg.From.ID
+
+	// Определяем пользователя и его роль
+	user, err := b.users.GetByTelegramID(ctx, userTelegramID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		slog.ErrorContext(ctx, "Не удалось загрузить пользователя", "telegram_id", userTelegramID, "err", err)
+		return
+	}
+	if err != nil {
+		newUser := &model.User{
+			TelegramID:   userTelegramID,
+			Username:     msg.From.UserName,
+			FirstName:    msg.From.FirstName,
+			LastName:     msg.From.LastName,
+			Role:         "user",
+			LanguageCode: msg.From.LanguageCode,
+			Language:     model.BaseLanguage(msg.From.LanguageCode),
+			IsActive:     true,
+			CreatedAt:    time.Now(),
+		}
+		id, err := b.users.Create(ctx, newUser)
+		if err != nil {
+			slog.ErrorContext(ctx, "Не удалось зарегистрировать пользователя", "telegram_id", userTelegramID, "err", err)
+			return
+		}
+		newUser.ID = id
+		user = newUser
+	}
+	// заблокированным администратором пользователям бот поддержки не отвечает
+	if user.IsBlocked {
+		slog.InfoContext(ctx, "Сообщение заблокированного пользователя пропущено", "user_id", user.ID)
+		return
+	}
+	locale := b.texts.Resolve(user.PreferredLanguage())
+
+	if msg.IsCommand() {
+		switch msg.Command() {
+		case "start":
+			if user.Role == "support" {
+				b.reply(ctx, chatID, locale, "support.operator_start")
+			} else {
+				b.reply(ctx, chatID, locale, "support.user_start")
+			}
+		case "language":
+			reply := tgbotapi.NewMessage(chatID, b.texts.Text(locale, "language.choose"))
+			reply.ReplyMarkup = bot.LanguageKeyboard(b.texts, locale)
+			b.send(ctx, reply)
+		case "answer":
+			if user.Role != "support" {
+				b.reply(ctx, chatID, locale, "support.command_unavailable")
+			} else {
+				args := msg.CommandArguments()
+				parts := strings.SplitN(args, " ", 2)
+				if len(parts) < 2 {
+					b.reply(ctx, chatID, locale, "support.answer_usage")
+				} else {
+					uid, err := strconv.Atoi(parts[0])
+					if err != nil {
+						b.reply(ctx, chatID, locale, "support.bad_user_id")
+					} else {
+						replyText := parts[1]
+						recipient, err := b.users.GetByID(ctx, uid)
+						if err != nil {
+							b.reply(ctx, chatID, locale, "support.user_not_found")
+						} else {
+							b.reply(ctx, recipient.TelegramID, recipient.PreferredLanguage(), "support.answer", "text", replyText)
+							if err := b.messages.Save(ctx, &model.Message{FromUserID: user.ID, ToUserID: recipient.ID, Content: replyText, IsSupport: true}); err != nil {
+								slog.ErrorContext(ctx, "Не удалось сохранить ответ поддержки", "err", err)
+							}
+							b.reply(ctx, chatID, locale, "support.answer_sent")
+						}
+					}
+				}
+			}
+		case "moderation":
+			if user.Role != "support" {
+				b.reply(ctx, chatID, locale, "support.command_unavailable")
+			} else {
+				sendModerationQueue(ctx, b.api, b.texts, locale, b.reviews, b.locRepo, chatID)
+			}
+		case "reject":
+			if user.Role != "support" {
+				b.reply(ctx, chatID, locale, "support.command_unavailable")
+			} else {
+				parts := strings.SplitN(msg.CommandArguments(), " ", 2)
+				reviewID, err := strconv.Atoi(parts[0])
+				if err != nil || len(parts) < 2 {
+					b.reply(ctx, chatID, locale, "support.reject_usage")
+				} else if _, err := b.reviews.Reject(ctx, user.ID, reviewID, strings.TrimSpace(parts[1])); err != nil {
+					b.send(ctx, tgbotapi.NewMessage(chatID, err.Error()))
+				} else {
+					b.reply(ctx, chatID, locale, "support.review_rejected", "id", reviewID)
+				}
+			}
+		case "translations":
+			if user.Role != "support" {
+				b.reply(ctx, chatID, locale, "support.command_unavailable")
+			} else {
+				sendTranslationQueue(ctx, b.api, b.texts, locale, b.translations, user.ID, chatID)
+			}
+		case "reject_translation":
+			if user.Role != "support" {
+				b.reply(ctx, chatID, locale, "support.command_unavailable")
+			} else {
+				parts := strings.SplitN(msg.CommandArguments(), " ", 2)
+				translationID, err := strconv.Atoi(parts[0])
+				if err != nil || len(parts) < 2 {
+					b.reply(ctx, chatID, locale, "translation.reject_usage")
+				} else if _, err := b.translations.Reject(ctx, user.ID, translationID, parts[1]); err != nil {
+					b.send(ctx, tgbotapi.NewMessage(chatID, err.Error()))
+				} else {
+					b.reply(ctx, chatID, locale, "translation.rejected", "id", translationID)
+				}
+			}
+		case "modlog":
+			if user.Role != "support" {
+				b.reply(ctx, chatID, locale, "support.command_unavailable")
+			} else if reviewID, err := strconv.Atoi(strings.TrimSpace(msg.CommandArguments())); err != nil {
+				b.reply(ctx, chatID, locale, "support.modlog_usage")
+			} else if entries, err := b.reviews.ModerationLog(ctx, reviewID); err != nil {
+				b.send(ctx, tgbotapi.NewMessage(chatID, err.Error()))
+			} else {
+				b.send(ctx, tgbotapi.NewMessage(chatID, formatModerationLog(b.texts, locale, reviewID, entries)))
+			}
+		}
+		return
+	}
+
+	// Обработка обычных сообщений
+	if user.Role == "support" {
+		b.reply(ctx, chatID, locale, "support.operator_hint")
+	} else {
+		if err := b.messages.Save(ctx, &model.Message{FromUserID: user.ID, Content: msg.Text, IsSupport: true}); err != nil {
+			slog.ErrorContext(ctx, "Не удалось сохранить обращение", "err", err)
+		}
+		supportUsers, err := b.users.ListByRole(ctx, "support")
+		if err != nil {
+			slog.ErrorContext(ctx, "Не удалось загрузить операторов", "err", err)
+		}
+		if len(supportUsers) == 0 {
+			b.reply(ctx, chatID, locale, "support.no_operators")
+		} else {
+			for _, sup := range supportUsers {
+				b.reply(ctx, sup.TelegramID, sup.PreferredLanguage(), "support.request",
+					"name", user.FirstName, "id", user.ID, "text", msg.Text)
+			}
+			b.reply(ctx, chatID, locale, "support.request_sent")
+		}
+	}
+}
+
+// setLanguage сохраняет выбранный пользователем язык интерфейса и подтверждает выбор уже на нем.
+func (b *supportBot) setLanguage(ctx context.Context, chatID int64, user *model.User, locale string) {
+	if !b.texts.HasLocale(locale) {
+		b.reply(ctx, chatID, user.PreferredLanguage(), "language.unsupported")
+		return
+	}
+	lang, err := b.accounts.SetLanguage(ctx, user.ID, locale)
+	if err != nil {
+		slog.ErrorContext(ctx, "Не удалось сохранить язык пользователя", "user_id", user.ID, "err", err)
+		b.reply(ctx, chatID, user.PreferredLanguage(), "bot.error")
+		return
+	}
+	b.reply(ctx, chatID, lang, "language.changed")
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"tourism/internal/bot"
+	"tourism/internal/i18n"
+	"tourism/internal/model"
+	"tourism/internal/repository/memory"
+	"tourism/internal/service"
+	"tourism/internal/telegram/telegramtest"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// сколько ждать ответа бота на обновление
+const replyTimeout = 5 * time.Second
+
+// testSupport — бот поддержки поверх хранилищ в памяти, подключенный к поддельному Bot API.
+type testSupport struct {
+	t      *testing.T
+	server *telegramtest.Server
+	texts  *i18n.Registry
+	users  *memory.UserRepository
+}
+
+func newTestSupport(t *testing.T) *testSupport {
+	s := memory.NewStore()
+	users := memory.NewUserRepository(s)
+	locations := memory.NewLocationRepository(s)
+	offers := memory.NewOfferRepository(s)
+	notifications := memory.NewNotificationRepository(s)
+	texts, err := i18n.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := telegramtest.NewServer("456:test")
+	api, err := server.NewBot()
+	if err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+	sb := &supportBot{
+		api:          api,
+		users:        users,
+		messages:     memory.NewMessageRepository(s),
+		locRepo:      locations,
+		reviews:      service.NewReviewService(s, memory.NewReviewRepository(s), locations, users, notifications, service.DefaultModerationRules()),
+		translations: service.NewTranslationService(s, memory.NewTranslationRepository(s), locations, offers, users, notifications),
+		accounts:     service.NewUserService(users),
+		texts:        texts,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		sb.run(ctx, api, bot.DispatcherConfig{ShutdownTimeout: time.Second})
+	}()
+	t.Cleanup(func() {
+		cancel()
+		server.Close()
+		<-done
+	})
+	return &testSupport{t: t, server: server, texts: texts, users: users}
+}
+
+// newUser создает пользователя с ролью role и русским языком интерфейса и возвращает его
+// вместе с отправителем для обновлений.
+func (b *testSupport) newUser(telegramID int64, role, name string) (*model.User, tgbotapi.User) {
+	b.t.Helper()
+	u := &model.User{TelegramID: telegramID, FirstName: name, Role: role, LanguageCode: "ru", Language: "ru",
+		IsActive: true, CreatedAt: time.Now()}
+	id, err := b.users.Create(context.Background(), u)
+	if err != nil {
+		b.t.Fatal(err)
+	}
+	u.ID = id
+	return u, tgbotapi.User{ID: telegramID, FirstName: name, LanguageCode: "ru"}
+}
+
+// expect ждет, что бот отправит в чат chatID сообщение key на русском языке.
+func (b *testSupport) expect(chatID int64, key string, args ...any) {
+	b.t.Helper()
+	text := b.texts.Text("ru", key, args...)
+	_, err := b.server.WaitCall(replyTimeout, func(c telegramtest.Call) bool {
+		return c.ChatID == chatID && c.Text == text
+	})
+	if err != nil {
+		b.t.Fatalf("нет сообщения %q в чат %d: %v; отправлено: %+v", text, chatID, err, b.server.CallsTo(chatID))
+	}
+}
+
+func TestSupportConversation(t *testing.T) {
+	b := newTestSupport(t)
+	user, userTG := b.newUser(1001, "user", "Алан")
+	_, operatorTG := b.newUser(3001, "support", "Мадина")
+
+	b.server.SendText(userTG, "Не приходит подтверждение брони")
+	b.expect(operatorTG.ID, "support.request", "name", "Алан", "id", user.ID, "text", "Не приходит подтверждение брони")
+	b.expect(userTG.ID, "support.request_sent")
+
+	b.server.SendText(operatorTG, fmt.Sprintf("/answer %d Проверили, бронь подтверждена", user.ID))
+	b.expect(userTG.ID, "support.answer", "text", "Проверили, бронь подтверждена")
+	b.expect(operatorTG.ID, "support.answer_sent")
+}
+
+func TestSupportAnswerRequiresOperator(t *testing.T) {
+	b := newTestSupport(t)
+	user, userTG := b.newUser(1001, "user", "Алан")
+	_, otherTG := b.newUser(1002, "user", "Зарина")
+
+	b.server.SendText(otherTG, fmt.Sprintf("/answer %d Здравствуйте", user.ID))
+	b.expect(otherTG.ID, "support.command_unavailable")
+	if calls := b.server.CallsTo(userTG.ID); len(calls) != 0 {
+		t.Fatalf("пользователю отправлены сообщения: %+v", calls)
+	}
+}
+
+func TestSupportWithoutOperators(t *testing.T) {
+	b := newTestSupport(t)
+	_, userTG := b.newUser(1001, "user", "Алан")
+
+	b.server.SendText(userTG, "Есть кто-нибудь?")
+	b.expect(userTG.ID, "support.no_operators")
+}
@@ -1,132 +1,148 @@
 package main
 
 import (
-	"fmt"
+	"context"
+	"io"
 	"log"
+	"log/slog"
+	"net/http"
 	"os"
-	"strconv"
-	"strings"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
-	"tourism/internal/model"
+	"tourism/internal/bot"
+	"tourism/internal/config"
+	"tourism/internal/health"
+	"tourism/internal/i18n"
+	"tourism/internal/logging"
+	"tourism/internal/metrics"
 	"tourism/internal/repository"
-
-	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
-	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
+	"tourism/internal/service"
+	"tourism/internal/telegram"
+	"tourism/migrations"
 )
 
 func main() {
-	dbHost := os.Getenv("DB_HOST")
-	dbPort := os.Getenv("DB_PORT")
-	dbUser := os.Getenv("DB_USER")
-	dbPass := os.Getenv("DB_PASS")
-	dbName := os.Getenv("DB_NAME")
-	if dbHost == "" {
-		dbHost = "db"
+	cfg, err := config.Load(config.AppSupportBot)
+	if err != nil {
+		log.Fatal(err)
 	}
-	if dbPort == "" {
-		dbPort = "5432"
+	if err := logging.Setup("support_bot", cfg.Log.Level, cfg.Log.Format); err != nil {
+		log.Fatal(err)
 	}
-	dsn := "host=" + dbHost + " port=" + dbPort + " user=" + dbUser + " password=" + dbPass + " dbname=" + dbName + " sslmode=disable"
-	db, err := sqlx.Connect("postgres", dsn)
+	slog.Info("Конфигурация загружена", "config", cfg)
+	texts, err := i18n.New()
 	if err != nil {
-		log.Fatalf("DB connection failed: %v", err)
+		fatal("Не удалось загрузить тексты сообщений", err)
 	}
+	db, err := cfg.DB.Connect()
+	if err != nil {
+		fatal("Нет подключения к базе данных", err)
+	}
+
+	// запросы репозиториев ограничены сроком контекста и DB_QUERY_TIMEOUT
+	store := repository.NewDB(db, cfg.DB.QueryTimeout.Std())
 
-	userRepo := repository.NewUserRepository(db)
-	messageRepo := repository.NewMessageRepository(db)
+	userRepo := repository.NewUserRepository(store)
+	messageRepo := repository.NewMessageRepository(store)
+	locRepo := repository.NewLocationRepository(store)
+	reviewRepo := repository.NewReviewRepository(store)
+	// уведомления об одобренных отзывах и переводах отправляет основной бот
+	notificationRepo := repository.NewNotificationRepository(store)
+	reviewService := service.NewReviewService(store, reviewRepo, locRepo, userRepo, notificationRepo, service.DefaultModerationRules())
+	translationService := service.NewTranslationService(store, repository.NewTranslationRepository(store), locRepo,
+		repository.NewOfferRepository(store), userRepo, notificationRepo)
 
-	supportToken := os.Getenv("SUPPORT_BOT_TOKEN")
-	if supportToken == "" {
-		log.Fatal("Не указан токен бота поддержки (SUPPORT_BOT_TOKEN)")
+	botAPI, err := telegram.New(cfg.SupportBot.Token.Value(), cfg.Telegram.APIEndpoint)
+	if err != nil {
+		fatal("Ошибка инициализации бота поддержки", err)
 	}
-	bot, err := tgbotapi.NewBotAPI(supportToken)
+	slog.Info("Запущен бот поддержки", "username", botAPI.Self.UserName)
+	api := telegram.Instrument(botAPI)
+
+	// служебный HTTP-сервер: метрики и проверки живости и готовности
+	checker := health.NewChecker()
+	checker.Add("database", health.Database(db))
+	checker.Add("migrations", health.Migrations(db, migrations.FS))
+	mux := http.NewServeMux()
+	checker.Register(mux)
+	srv, err := metrics.Serve(cfg.SupportBot.MetricsListen, mux)
 	if err != nil {
-		log.Fatal("Ошибка инициализации support бота:", err)
+		fatal("Не удалось запустить служебный HTTP-сервер", err)
 	}
-	log.Printf("Запущен бот поддержки %s", bot.Self.UserName)
 
-	u := tgbotapi.NewUpdate(0)
-	u.Timeout = 60
-	updates := bot.GetUpdatesChan(u)
+	// обновления приходят через вебхук (SUPPORT_BOT_WEBHOOK_URL) или long polling
+	updates, err := telegram.Listen(api, cfg.SupportBot.Webhook.Telegram())
+	if err != nil {
+		fatal("Ошибка получения обновлений", err)
+	}
+	// по сигналу бот перестает принимать обновления и дорабатывает уже принятые
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	for update := range updates {
-		if update.Message == nil {
-			continue
-		}
-		msg := update.Message
-		chatID := msg.Chat.ID
-		userTelegramID := msg.From.ID
+	go func() {
+		<-ctx.Done()
+		checker.Shutdown()
+	}()
 
-		// Определяем пользователя и его роль
-		user, err := userRepo.GetByTelegramID(userTelegramID)
-		if err != nil {
-			newUser := &model.User{
-				TelegramID: userTelegramID,
-				Username:   msg.From.UserName,
-				FirstName:  msg.From.FirstName,
-				LastName:   msg.From.LastName,
-				Role:       "user",
-			}
-			id, _ := userRepo.Create(newUser)
-			newUser.ID = id
-			user = newUser
-		}
+	// напоминание дожидается при остановке, чтобы не закрыть базу посреди отправки
+	var background sync.WaitGroup
+	if cfg.Features.ModerationReminder {
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			runModerationReminder(ctx, api, texts, reviewService, userRepo, cfg.SupportBot.ReminderInterval.Std())
+		}()
+	}
 
-		if msg.IsCommand() {
-			switch msg.Command() {
-			case "start":
-				if user.Role == "support" {
-					bot.Send(tgbotapi.NewMessage(chatID, "Оператор поддержки на связи. Ожидание обращений..."))
-				} else {
-					bot.Send(tgbotapi.NewMessage(chatID, "Здравствуйте! Опишите, пожалуйста, ваш вопрос, и оператор поддержки скоро ответит."))
-				}
-			case "answer":
-				if user.Role != "support" {
-					bot.Send(tgbotapi.NewMessage(chatID, "Команда недоступна."))
-				} else {
-					args := msg.CommandArguments()
-					parts := strings.SplitN(args, " ", 2)
-					if len(parts) < 2 {
-						bot.Send(tgbotapi.NewMessage(chatID, "Использование: /answer <UserID> <текст ответа>"))
-					} else {
-						uid, err := strconv.Atoi(parts[0])
-						if err != nil {
-							bot.Send(tgbotapi.NewMessage(chatID, "Некорректный ID пользователя."))
-						} else {
-							replyText := parts[1]
-							recipient, err := userRepo.GetByID(uid)
-							if err != nil {
-								bot.Send(tgbotapi.NewMessage(chatID, "Пользователь не найден."))
-							} else {
-								outMsg := tgbotapi.NewMessage(recipient.TelegramID, fmt.Sprintf("Ответ поддержки: %s", replyText))
-								bot.Send(outMsg)
-								messageRepo.Save(&model.Message{FromUserID: user.ID, ToUserID: recipient.ID, Content: replyText, IsSupport: true})
-								bot.Send(tgbotapi.NewMessage(chatID, "Ответ отправлен пользователю."))
-							}
-						}
-					}
-				}
-			}
-			continue
-		}
+	sb := &supportBot{
+		api:          api,
+		users:        userRepo,
+		messages:     messageRepo,
+		locRepo:      locRepo,
+		reviews:      reviewService,
+		translations: translationService,
+		accounts:     service.NewUserService(userRepo),
+		texts:        texts,
+	}
+	sb.run(ctx, updates, bot.DispatcherConfig{
+		Workers:         cfg.SupportBot.Updates.Workers,
+		QueueSize:       cfg.SupportBot.Updates.QueueSize,
+		ShutdownTimeout: cfg.SupportBot.Updates.ShutdownTimeout.Std(),
+	})
+	shutdown(&background, srv, db, cfg.SupportBot.Updates.ShutdownTimeout.Std())
+	slog.Info("Бот поддержки остановлен")
+}
 
-		// Обработка обычных сообщений
-		if user.Role == "support" {
-			bot.Send(tgbotapi.NewMessage(chatID, "Для ответа пользователю используйте команду /answer <ID> <сообщение>."))
-		} else {
-			messageRepo.Save(&model.Message{FromUserID: user.ID, Content: msg.Text, IsSupport: true})
-			supportUsers := []model.User{}
-			db.Select(&supportUsers, "SELECT * FROM users WHERE role='support'")
-			if len(supportUsers) == 0 {
-				bot.Send(tgbotapi.NewMessage(chatID, "Нет доступных операторов."))
-			} else {
-				for _, sup := range supportUsers {
-					out := fmt.Sprintf("Запрос от пользователя %s (ID %d):\n%s", user.FirstName, user.ID, msg.Text)
-					bot.Send(tgbotapi.NewMessage(sup.TelegramID, out))
-				}
-				bot.Send(tgbotapi.NewMessage(chatID, "Ваш запрос отправлен в службу поддержки. Ожидайте ответа."))
-			}
+// shutdown дожидается фоновых задач (не дольше timeout), останавливает служебный
+// HTTP-сервер и закрывает базу данных.
+func shutdown(background *sync.WaitGroup, srv *http.Server, db io.Closer, timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		background.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		slog.Warn("Фоновые задачи не завершились вовремя", "timeout", timeout)
+	}
+	if srv != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			slog.Error("Ошибка остановки служебного HTTP-сервера", "err", err)
 		}
 	}
+	if err := db.Close(); err != nil {
+		slog.Error("Ошибка закрытия базы данных", "err", err)
+	}
+}
+
+// fatal логирует ошибку и завершает процесс.
+func fatal(msg string, err error) {
+	slog.Error(msg, "err", err)
+	os.Exit(1)
 }
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"tourism/internal/i18n"
+	"tourism/internal/model"
+	"tourism/internal/repository"
+	"tourism/internal/service"
+	"tourism/internal/telegram"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// сколько отзывов показывать по команде /moderation
+const moderationPageSize = 10
+
+// moderationCard формирует карточку отзыва для модератора с кнопками решения.
+func moderationCard(texts *i18n.Registry, locale string, chatID int64, r *model.Review, locationName string) tgbotapi.MessageConfig {
+	reasons := []string{}
+	for _, f := range r.Flags {
+		reasons = append(reasons, service.FlagDescription(f))
+	}
+	text := texts.Text(locale, "moderation.card", "id", r.ID, "location", locationName, "author", r.AuthorName,
+		"user_id", r.UserID, "rating", r.Rating, "reasons", reasons, "text", r.Text)
+	msg := tgbotapi.NewMessage(chatID, strings.TrimSpace(text))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(texts.Text(locale, "moderation.approve"), fmt.Sprintf("MOD_APPROVE_%d", r.ID)),
+		tgbotapi.NewInlineKeyboardButtonData(texts.Text(locale, "moderation.reject"), fmt.Sprintf("MOD_REJECT_%d", r.ID)),
+	))
+	return msg
+}
+
+// sendModerationQueue отправляет модератору отзывы, ожидающие проверки.
+func sendModerationQueue(ctx context.Context, bot telegram.Sender, texts *i18n.Registry, locale string, reviews *service.ReviewService, locRepo repository.LocationStore, chatID int64) {
+	pending, err := reviews.PendingReviews(ctx, moderationPageSize)
+	if err != nil {
+		slog.ErrorContext(ctx, "Не удалось загрузить очередь модерации", "err", err)
+		send(ctx, bot, tgbotapi.NewMessage(chatID, texts.Text(locale, "moderation.load_failed")))
+		return
+	}
+	if len(pending) == 0 {
+		send(ctx, bot, tgbotapi.NewMessage(chatID, texts.Text(locale, "moderation.empty")))
+		return
+	}
+	for i := range pending {
+		name := ""
+		if loc, err := locRepo.GetByID(ctx, pending[i].LocationID); err == nil {
+			name = loc.Name
+		} else {
+			slog.WarnContext(ctx, "Локация отзыва не найдена", "review_id", pending[i].ID, "location_id", pending[i].LocationID, "err", err)
+		}
+		send(ctx, bot, moderationCard(texts, locale, chatID, &pending[i], name))
+	}
+}
+
+// formatModerationLog форматирует журнал решений по отзыву на языке locale.
+func formatModerationLog(texts *i18n.Registry, locale string, reviewID int, entries []model.ModerationEntry) string {
+	if len(entries) == 0 {
+		return texts.Text(locale, "moderation.log_empty", "id", reviewID)
+	}
+	return texts.Text(locale, "moderation.log", "id", reviewID, "entries", entries)
+}
+
+// runModerationReminder периодически сообщает операторам о новых отзывах в очереди модерации.
+func runModerationReminder(ctx context.Context, bot telegram.Sender, texts *i18n.Registry, reviews *service.ReviewService, userRepo repository.UserStore, interval time.Duration) {
+	lastCount := 0
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		count, err := reviews.CountPending(ctx)
+		if err != nil {
+			slog.Error("Не удалось посчитать очередь модерации", "err", err)
+			continue
+		}
+		if count > lastCount {
+			operators, err := userRepo.ListByRole(ctx, "support")
+			if err != nil {
+				slog.Error("Не удалось загрузить операторов", "err", err)
+				continue
+			}
+			for _, op := range operators {
+				text := texts.Text(op.PreferredLanguage(), "moderation.reminder", "count", count)
+				send(ctx, bot, tgbotapi.NewMessage(op.TelegramID, text))
+			}
+		}
+		lastCount = count
+	}
+}
+
+// send отправляет сообщение; ошибка отправки логируется с полями из ctx.
+func send(ctx context.Context, bot telegram.Sender, c tgbotapi.Chattable) {
+	if _, err := bot.Send(c); err != nil {
+		slog.WarnContext(ctx, "Не удалось отправить сообщение", "err", err)
+	}
+}
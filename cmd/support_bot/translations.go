@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"tourism/internal/i18n"
+	"tourism/internal/model"
+	"tourism/internal/service"
+	"tourism/internal/telegram"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// translationCard формирует карточку перевода для проверки с кнопками решения.
+func translationCard(texts *i18n.Registry, locale string, chatID int64, t *model.Translation, original string) tgbotapi.MessageConfig {
+	authorID := 0
+	if t.AuthorID != nil {
+		authorID = *t.AuthorID
+	}
+	text := texts.Text(locale, "translation.card", "id", t.ID, "entity", texts.Text(locale, "translation.entity."+t.Entity),
+		"original", original, "language", texts.Text(t.Locale, "language.name"), "author_id", authorID,
+		"name", t.Name, "description", t.Description)
+	msg := tgbotapi.NewMessage(chatID, strings.TrimSpace(text))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(texts.Text(locale, "moderation.approve"), fmt.Sprintf("TRN_APPROVE_%d", t.ID)),
+		tgbotapi.NewInlineKeyboardButtonData(texts.Text(locale, "moderation.reject"), fmt.Sprintf("TRN_REJECT_%d", t.ID)),
+	))
+	return msg
+}
+
+// sendTranslationQueue отправляет сотруднику поддержки reviewerID переводы, ожидающие проверки.
+func sendTranslationQueue(ctx context.Context, bot telegram.Sender, texts *i18n.Registry, locale string, translations *service.TranslationService, reviewerID int, chatID int64) {
+	pending, err := translations.Pending(ctx, reviewerID, moderationPageSize)
+	if err != nil {
+		slog.ErrorContext(ctx, "Не удалось загрузить очередь переводов", "err", err)
+		send(ctx, bot, tgbotapi.NewMessage(chatID, texts.Text(locale, "translation.queue_failed")))
+		return
+	}
+	if len(pending) == 0 {
+		send(ctx, bot, tgbotapi.NewMessage(chatID, texts.Text(locale, "translation.queue_empty")))
+		return
+	}
+	for i := range pending {
+		original, err := translations.Original(ctx, &pending[i])
+		if err != nil {
+			slog.WarnContext(ctx, "Запись перевода не найдена", "translation_id", pending[i].ID,
+				"entity", pending[i].Entity, "entity_id", pending[i].EntityID, "err", err)
+		}
+		send(ctx, bot, translationCard(texts, locale, chatID, &pending[i], original))
+	}
+}
@@ -0,0 +1,42 @@
+// Package blob хранит файлы (фото локаций и их уменьшенные копии) по ключам вида
+// "locations/1/photos/2/small.jpg": в каталоге локальной файловой системы или в бакете
+// S3-совместимого хранилища.
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"path"
+	"time"
+)
+
+// ErrNotFound возвращается, если файла с таким ключом нет.
+var ErrNotFound = errors.New("файл не найден в хранилище")
+
+// Store — хранилище файлов.
+type Store interface {
+	// Put сохраняет файл, заменяя существующий с тем же ключом.
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Open открывает файл для чтения; вызывающий закрывает Object.Body.
+	Open(ctx context.Context, key string) (*Object, error)
+	// Delete удаляет файл; отсутствие файла не считается ошибкой.
+	Delete(ctx context.Context, key string) error
+}
+
+// Object — открытый файл хранилища.
+type Object struct {
+	Body        io.ReadCloser
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// contentType определяет тип файла по расширению ключа.
+func contentType(key string) string {
+	if t := mime.TypeByExtension(path.Ext(key)); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,95 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Local хранит файлы в каталоге локальной файловой системы; ключ — относительный путь.
+type Local struct {
+	dir string
+}
+
+// NewLocal создает хранилище в каталоге dir, создавая его при необходимости.
+func NewLocal(dir string) (*Local, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("не удалось создать каталог хранилища %s: %w", dir, err)
+	}
+	return &Local{dir: dir}, nil
+}
+
+// Put записывает файл во временный файл и переименовывает его, чтобы читатели не увидели
+// недописанный файл.
+func (s *Local) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return fmt.Errorf("не удалось создать каталог для %s: %w", key, err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("не удалось сохранить %s: %w", key, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("не удалось сохранить %s: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("не удалось сохранить %s: %w", key, err)
+	}
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		return fmt.Errorf("не удалось сохранить %s: %w", key, err)
+	}
+	return nil
+}
+
+// Open открывает файл; тип содержимого определяется по расширению ключа.
+func (s *Local) Open(ctx context.Context, key string) (*Object, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть %s: %w", key, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("не удалось открыть %s: %w", key, err)
+	}
+	return &Object{Body: f, Size: info.Size(), ContentType: contentType(key), ModTime: info.ModTime()}, nil
+}
+
+// Delete удаляет файл.
+func (s *Local) Delete(ctx context.Context, key string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("не удалось удалить %s: %w", key, err)
+	}
+	return nil
+}
+
+// path возвращает путь файла, не позволяя ключу выйти за пределы каталога хранилища.
+func (s *Local) path(key string) (string, error) {
+	if !fs.ValidPath(key) || strings.Contains(key, `\`) {
+		return "", fmt.Errorf("некорректный ключ файла %q", key)
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
+
+var _ Store = (*Local)(nil)
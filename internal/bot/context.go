@@ -0,0 +1,234 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"tourism/internal/i18n"
+	"tourism/internal/model"
+	"tourism/internal/telegram"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Context — данные и вспомогательные методы для обработки одного обновления Telegram.
+// Context реализует context.Context: он отменяется, если бот останавливается, не дождавшись
+// завершения обработки, поэтому его можно передавать в долгие операции.
+type Context struct {
+	Bot    telegram.Sender
+	Update tgbotapi.Update
+	ChatID int64          // чат, в который отвечает бот
+	From   *tgbotapi.User // отправитель сообщения или нажавший кнопку
+	User   *model.User    // пользователь системы, заполняется middleware Auth
+	Texts  *i18n.Registry // тексты сообщений бота
+
+	// State — активный сценарий пользователя (nil, если сценарий не начат).
+	State *model.ConversationState
+	// Interrupted — сценарий, прерванный командой или кнопкой меню, которую сейчас обрабатывает бот.
+	Interrupted *model.ConversationState
+
+	ctx   context.Context
+	param string
+	store StateStore
+	ttl   time.Duration
+}
+
+// Deadline реализует context.Context.
+func (c *Context) Deadline() (time.Time, bool) {
+	return c.ctx.Deadline()
+}
+
+// Done реализует context.Context.
+func (c *Context) Done() <-chan struct{} {
+	return c.ctx.Done()
+}
+
+// Err реализует context.Context.
+func (c *Context) Err() error {
+	return c.ctx.Err()
+}
+
+// Value реализует context.Context.
+func (c *Context) Value(key interface{}) interface{} {
+	return c.ctx.Value(key)
+}
+
+// Message возвращает обрабатываемое сообщение (nil для нажатий inline-кнопок).
+func (c *Context) Message() *tgbotapi.Message {
+	return c.Update.Message
+}
+
+// Callback возвращает нажатие inline-кнопки (nil для сообщений).
+func (c *Context) Callback() *tgbotapi.CallbackQuery {
+	return c.Update.CallbackQuery
+}
+
+// Text возвращает текст сообщения, подпись к фото или данные нажатой кнопки.
+func (c *Context) Text() string {
+	if cq := c.Callback(); cq != nil {
+		return cq.Data
+	}
+	if msg := c.Message(); msg != nil {
+		if msg.Text == "" {
+			return msg.Caption
+		}
+		return msg.Text
+	}
+	return ""
+}
+
+// Args возвращает аргументы команды (текст после /команды).
+func (c *Context) Args() string {
+	if msg := c.Message(); msg != nil && msg.IsCommand() {
+		return msg.CommandArguments()
+	}
+	return ""
+}
+
+// Param возвращает часть данных кнопки после префикса, по которому был выбран обработчик.
+func (c *Context) Param() string {
+	return c.param
+}
+
+// IntParam возвращает Param как число.
+func (c *Context) IntParam() (int, error) {
+	n, err := strconv.Atoi(c.param)
+	if err != nil {
+		return 0, fmt.Errorf("некорректный параметр кнопки %q", c.param)
+	}
+	return n, nil
+}
+
+// PhotoID возвращает FileID фото наибольшего размера из сообщения или пустую строку.
+func (c *Context) PhotoID() string {
+	if msg := c.Message(); msg != nil && len(msg.Photo) > 0 {
+		return msg.Photo[len(msg.Photo)-1].FileID
+	}
+	return ""
+}
+
+// SharedLocation возвращает геопозицию, отправленную в сообщении, или nil.
+func (c *Context) SharedLocation() *tgbotapi.Location {
+	if msg := c.Message(); msg != nil {
+		return msg.Location
+	}
+	return nil
+}
+
+// Locale возвращает локаль, на которой бот отвечает пользователю: по выбранному им языку
+// (или языку его профиля), а до авторизации — по языку Telegram.
+func (c *Context) Locale() string {
+	lang := ""
+	if c.User != nil {
+		lang = c.User.PreferredLanguage()
+	} else if c.From != nil {
+		lang = c.From.LanguageCode
+	}
+	return c.Texts.Resolve(lang)
+}
+
+// T возвращает текст сообщения key на языке пользователя; args — пары ключ-значение для шаблона.
+func (c *Context) T(key string, args ...any) string {
+	return c.Texts.Text(c.Locale(), key, args...)
+}
+
+// Send отправляет сообщение через бота. Ошибки отправки логируются.
+func (c *Context) Send(m tgbotapi.Chattable) (tgbotapi.Message, error) {
+	sent, err := c.Bot.Send(m)
+	if err != nil {
+		slog.WarnContext(c, "Не удалось отправить сообщение", "err", err)
+	}
+	return sent, err
+}
+
+// SendMediaGroup отправляет альбом фото через бота. Ошибки отправки логируются.
+func (c *Context) SendMediaGroup(m tgbotapi.MediaGroupConfig) ([]tgbotapi.Message, error) {
+	sent, err := c.Bot.SendMediaGroup(m)
+	if err != nil {
+		slog.WarnContext(c, "Не удалось отправить альбом", "photos", len(m.Media), "err", err)
+	}
+	return sent, err
+}
+
+// Reply отправляет текстовый ответ в текущий чат.
+func (c *Context) Reply(text string) error {
+	_, err := c.Send(tgbotapi.NewMessage(c.ChatID, text))
+	return err
+}
+
+// Payload разбирает данные активного сценария в dst.
+func (c *Context) Payload(dst interface{}) error {
+	return DecodePayload(c.State, dst)
+}
+
+// DecodePayload разбирает данные сценария state в dst. Для nil ничего не делает.
+func DecodePayload(state *model.ConversationState, dst interface{}) error {
+	if state == nil || len(state.Payload) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(state.Payload, dst); err != nil {
+		return fmt.Errorf("некорректные данные сценария %s: %w", state.Flow, err)
+	}
+	return nil
+}
+
+// SetState переводит пользователя на шаг step сценария flow. Ответ ожидается не дольше тайм-аута роутера.
+func (c *Context) SetState(flow, step string, payload interface{}) error {
+	return c.SetStateTTL(flow, step, payload, c.ttl)
+}
+
+// SetStateTTL переводит пользователя на шаг сценария с собственным тайм-аутом (0 — без ограничения).
+func (c *Context) SetStateTTL(flow, step string, payload interface{}, ttl time.Duration) error {
+	state, err := c.SetStateFor(c.From.ID, flow, step, payload, ttl)
+	if err != nil {
+		return err
+	}
+	c.State = state
+	return nil
+}
+
+// SetStateFor переводит в сценарий другого пользователя (например, второго участника чата).
+func (c *Context) SetStateFor(telegramID int64, flow, step string, payload interface{}, ttl time.Duration) (*model.ConversationState, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось сохранить данные сценария %s: %w", flow, err)
+	}
+	state := &model.ConversationState{
+		TelegramID: telegramID,
+		Flow:       flow,
+		Step:       step,
+		Payload:    data,
+		UpdatedAt:  time.Now(),
+	}
+	if ttl > 0 {
+		expires := state.UpdatedAt.Add(ttl)
+		state.ExpiresAt = &expires
+	}
+	if err := c.store.Save(c, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// StateOf возвращает активный сценарий другого пользователя.
+func (c *Context) StateOf(telegramID int64) (*model.ConversationState, error) {
+	return c.store.Get(c, telegramID)
+}
+
+// ClearState завершает активный сценарий пользователя.
+func (c *Context) ClearState() error {
+	if err := c.store.Delete(c, c.From.ID); err != nil {
+		return err
+	}
+	c.State = nil
+	return nil
+}
+
+// ClearStateFor завершает сценарий другого пользователя.
+func (c *Context) ClearStateFor(telegramID int64) error {
+	return c.store.Delete(c, telegramID)
+}
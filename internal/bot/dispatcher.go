@@ -0,0 +1,212 @@
+package bot
+
+import (
+	"context"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"tourism/internal/logging"
+	"tourism/internal/metrics"
+	"tourism/internal/telegram"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// DispatcherConfig — настройки параллельной обработки обновлений.
+type DispatcherConfig struct {
+	Workers         int           // число воркеров; обновления одного чата всегда обрабатывает один и тот же воркер
+	QueueSize       int           // длина очереди каждого воркера; при заполнении прием обновлений приостанавливается
+	ShutdownTimeout time.Duration // сколько ждать обработки принятых обновлений при остановке
+}
+
+// DefaultDispatcherConfig — настройки по умолчанию.
+var DefaultDispatcherConfig = DispatcherConfig{Workers: 8, QueueSize: 64, ShutdownTimeout: 15 * time.Second}
+
+// UpdateHandler обрабатывает одно обновление. ctx отменяется, если обработка не уложилась в время остановки.
+type UpdateHandler func(ctx context.Context, update tgbotapi.Update)
+
+// Dispatcher обрабатывает обновления параллельно на нескольких воркерах, сохраняя порядок
+// внутри чата: чат закрепляется за воркером по своему ID, поэтому медленный запрос одного
+// пользователя задерживает только чаты того же воркера. Очереди ограничены: когда очередь
+// воркера заполнена, Dispatcher перестает читать новые обновления (long polling не
+// запрашивает следующую порцию, вебхук не отвечает Telegram, пока не освободится место).
+type Dispatcher struct {
+	cfg    DispatcherConfig
+	handle UpdateHandler
+}
+
+// NewDispatcher создает диспетчер. Незаданные поля cfg берутся из DefaultDispatcherConfig.
+func NewDispatcher(cfg DispatcherConfig, handle UpdateHandler) *Dispatcher {
+	if cfg.Workers <= 0 {
+		cfg.Workers = DefaultDispatcherConfig.Workers
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = DefaultDispatcherConfig.QueueSize
+	}
+	if cfg.ShutdownTimeout <= 0 {
+		cfg.ShutdownTimeout = DefaultDispatcherConfig.ShutdownTimeout
+	}
+	return &Dispatcher{cfg: cfg, handle: handle}
+}
+
+// Run получает обновления из updates и распределяет их по воркерам, пока источник не закроет канал.
+// После отмены ctx прием останавливается (StopReceivingUpdates), уже полученные обновления
+// обрабатываются в течение ShutdownTimeout; по истечении этого времени контекст обработчиков
+// отменяется, а оставшиеся в очередях обновления отбрасываются.
+func (d *Dispatcher) Run(ctx context.Context, updates telegram.Updates) {
+	u := tgbotapi.NewUpdate(0)
+	u.Timeout = 60
+	u.AllowedUpdates = telegram.AllowedUpdates
+	incoming := updates.GetUpdatesChan(u)
+
+	// обработчики не прерываются сразу при остановке, а успевают закончить начатое
+	handlerCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancel()
+
+	queues := make([]chan tgbotapi.Update, d.cfg.Workers)
+	var wg sync.WaitGroup
+	for i := range queues {
+		queues[i] = make(chan tgbotapi.Update, d.cfg.QueueSize)
+		wg.Add(1)
+		go func(queue <-chan tgbotapi.Update) {
+			defer wg.Done()
+			d.work(handlerCtx, queue)
+		}(queues[i])
+	}
+
+	stopping := ctx.Done()
+	var deadline <-chan struct{} // закрывается через ShutdownTimeout после начала остановки
+	stop := func() {
+		stopping = nil
+		slog.Info("Остановка: прием новых обновлений прекращен")
+		expired := make(chan struct{})
+		time.AfterFunc(d.cfg.ShutdownTimeout, func() { close(expired) })
+		deadline = expired
+		// остановка вебхука ждет ответа на принятые запросы, поэтому чтение продолжается параллельно
+		go updates.StopReceivingUpdates()
+	}
+
+receive:
+	for {
+		var update tgbotapi.Update
+		select {
+		case upd, ok := <-incoming:
+			if !ok {
+				break receive
+			}
+			update = upd
+		case <-stopping:
+			stop()
+			continue
+		case <-deadline:
+			slog.Warn("Источник обновлений не остановился вовремя", "timeout", d.cfg.ShutdownTimeout)
+			break receive
+		}
+
+		queue := queues[shard(update, len(queues))]
+		for sent := false; !sent; {
+			select {
+			case queue <- update:
+				sent = true
+			case <-stopping:
+				stop()
+			case <-deadline:
+				slog.Warn("Обновление отброшено: очередь не освободилась до остановки", "update_id", update.UpdateID)
+				break receive
+			}
+		}
+	}
+
+	for _, q := range queues {
+		close(q)
+	}
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+	if deadline == nil {
+		<-finished
+		return
+	}
+	select {
+	case <-finished:
+		return
+	case <-deadline:
+	}
+	// время вышло: обработчики получают отмену, необработанные обновления из очередей отбрасываются
+	cancel()
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		slog.Warn("Обработка обновлений не завершилась вовремя", "timeout", d.cfg.ShutdownTimeout)
+	}
+}
+
+// work обрабатывает очередь одного воркера по порядку.
+func (d *Dispatcher) work(ctx context.Context, queue <-chan tgbotapi.Update) {
+	for update := range queue {
+		if ctx.Err() != nil {
+			continue
+		}
+		d.safeHandle(ctx, update)
+	}
+}
+
+// safeHandle добавляет в контекст поля логов обновления, считает метрики и не дает панике
+// в обработчике остановить воркер.
+func (d *Dispatcher) safeHandle(ctx context.Context, update tgbotapi.Update) {
+	kind := UpdateType(update)
+	metrics.BotUpdates.WithLabelValues(kind).Inc()
+	ctx = logging.With(ctx, "update_id", update.UpdateID)
+	if chat := update.FromChat(); chat != nil {
+		ctx = logging.With(ctx, "chat_id", chat.ID)
+	}
+	start := time.Now()
+	defer func() {
+		metrics.BotUpdateDuration.WithLabelValues(kind).Observe(time.Since(start).Seconds())
+		if p := recover(); p != nil {
+			slog.ErrorContext(ctx, "Паника при обработке обновления", "panic", p, "stack", string(debug.Stack()))
+		}
+	}()
+	d.handle(ctx, update)
+}
+
+// UpdateType возвращает тип обновления для логов и метрик: "message", "callback_query" и т.д.
+func UpdateType(update tgbotapi.Update) string {
+	switch {
+	case update.Message != nil:
+		if update.Message.IsCommand() {
+			return "command"
+		}
+		return "message"
+	case update.EditedMessage != nil:
+		return "edited_message"
+	case update.CallbackQuery != nil:
+		return "callback_query"
+	case update.InlineQuery != nil:
+		return "inline_query"
+	case update.ChannelPost != nil:
+		return "channel_post"
+	case update.MyChatMember != nil:
+		return "my_chat_member"
+	case update.ChatMember != nil:
+		return "chat_member"
+	case update.PreCheckoutQuery != nil:
+		return "pre_checkout_query"
+	}
+	return "other"
+}
+
+// shard выбирает воркер для обновления по ID чата (или отправителя, если чата нет).
+func shard(update tgbotapi.Update, n int) int {
+	var key int64
+	if chat := update.FromChat(); chat != nil {
+		key = chat.ID
+	} else if user := update.SentFrom(); user != nil {
+		key = user.ID
+	}
+	return int(uint64(key) % uint64(n))
+}
@@ -0,0 +1,279 @@
+package bot
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// fakeUpdates — источник обновлений, которые тест передает по одному через небуферизованный канал:
+// отправка завершается, только когда диспетчер прочитал обновление.
+type fakeUpdates struct {
+	ch      chan tgbotapi.Update
+	once    sync.Once
+	stopped chan struct{}
+}
+
+func newFakeUpdates() *fakeUpdates {
+	return &fakeUpdates{ch: make(chan tgbotapi.Update), stopped: make(chan struct{})}
+}
+
+func (f *fakeUpdates) GetUpdatesChan(tgbotapi.UpdateConfig) tgbotapi.UpdatesChannel { return f.ch }
+
+func (f *fakeUpdates) StopReceivingUpdates() {
+	f.once.Do(func() {
+		close(f.stopped)
+		close(f.ch)
+	})
+}
+
+// send передает обновление диспетчеру; false — диспетчер не прочитал его за timeout.
+func (f *fakeUpdates) send(u tgbotapi.Update, timeout time.Duration) bool {
+	select {
+	case f.ch <- u:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// chatUpdate — сообщение с текстом text в чате chatID.
+func chatUpdate(id int, chatID int64, text string) tgbotapi.Update {
+	return tgbotapi.Update{UpdateID: id, Message: &tgbotapi.Message{
+		Chat: &tgbotapi.Chat{ID: chatID},
+		From: &tgbotapi.User{ID: chatID},
+		Text: text,
+	}}
+}
+
+// runDispatcher запускает диспетчер и возвращает функцию остановки, которая ждет завершения Run.
+func runDispatcher(t *testing.T, cfg DispatcherConfig, handle UpdateHandler) (*fakeUpdates, func()) {
+	t.Helper()
+	updates := newFakeUpdates()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		NewDispatcher(cfg, handle).Run(ctx, updates)
+	}()
+	stop := func() {
+		cancel()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Run не завершился после остановки")
+		}
+	}
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return updates, stop
+}
+
+func TestShard(t *testing.T) {
+	const n = 8
+	for _, chatID := range []int64{1, 7, 8, 123456789, -1001234567890} {
+		first := shard(chatUpdate(1, chatID, "a"), n)
+		if first < 0 || first >= n {
+			t.Fatalf("чат %d: воркер %d вне [0, %d)", chatID, first, n)
+		}
+		// нажатие кнопки в том же чате попадает к тому же воркеру
+		press := tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+			From:    &tgbotapi.User{ID: chatID},
+			Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: chatID}},
+		}}
+		if got := shard(press, n); got != first {
+			t.Errorf("чат %d: сообщение у воркера %d, нажатие у %d", chatID, first, got)
+		}
+	}
+	// без чата обновление закрепляется по отправителю
+	inline := tgbotapi.Update{InlineQuery: &tgbotapi.InlineQuery{From: &tgbotapi.User{ID: 42}}}
+	if got, want := shard(inline, n), shard(chatUpdate(1, 42, "a"), n); got != want {
+		t.Errorf("inline-запрос у воркера %d, чат отправителя у %d", got, want)
+	}
+	if got := shard(tgbotapi.Update{}, n); got != 0 {
+		t.Errorf("обновление без чата и отправителя у воркера %d, ожидался 0", got)
+	}
+}
+
+func TestDispatcherKeepsChatOrder(t *testing.T) {
+	var mu sync.Mutex
+	got := map[int64][]int{}
+	updates, stop := runDispatcher(t, DispatcherConfig{Workers: 4, QueueSize: 8}, func(ctx context.Context, u tgbotapi.Update) {
+		// обработка первых обновлений медленнее последующих: без закрепления порядок бы нарушился
+		if u.UpdateID%10 < 3 {
+			time.Sleep(time.Millisecond)
+		}
+		mu.Lock()
+		got[u.Message.Chat.ID] = append(got[u.Message.Chat.ID], u.UpdateID)
+		mu.Unlock()
+	})
+
+	chats := []int64{101, 102, 103, 104, 105}
+	want := map[int64][]int{}
+	id := 0
+	for i := 0; i < 30; i++ {
+		for _, chatID := range chats {
+			id++
+			if !updates.send(chatUpdate(id, chatID, "text"), time.Second) {
+				t.Fatalf("обновление %d не принято", id)
+			}
+			want[chatID] = append(want[chatID], id)
+		}
+	}
+	stop()
+
+	for _, chatID := range chats {
+		if len(got[chatID]) != len(want[chatID]) {
+			t.Fatalf("чат %d: обработано %d обновлений из %d", chatID, len(got[chatID]), len(want[chatID]))
+		}
+		for i := range want[chatID] {
+			if got[chatID][i] != want[chatID][i] {
+				t.Fatalf("чат %d: порядок %v, ожидался %v", chatID, got[chatID], want[chatID])
+			}
+		}
+	}
+}
+
+func TestDispatcherSlowChatDoesNotBlockOthers(t *testing.T) {
+	release := make(chan struct{})
+	handled := make(chan int64, 10)
+	updates, stop := runDispatcher(t, DispatcherConfig{Workers: 2, QueueSize: 4}, func(ctx context.Context, u tgbotapi.Update) {
+		if u.Message.Text == "slow" {
+			<-release
+		}
+		handled <- u.Message.Chat.ID
+	})
+	// чаты 2 и 3 закреплены за разными воркерами
+	updates.send(chatUpdate(1, 2, "slow"), time.Second)
+	updates.send(chatUpdate(2, 3, "fast"), time.Second)
+	select {
+	case chatID := <-handled:
+		if chatID != 3 {
+			t.Fatalf("первым обработан чат %d, ожидался 3", chatID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("медленный чат задержал обработку другого чата")
+	}
+	close(release)
+	stop()
+}
+
+func TestDispatcherBackpressure(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	handled := 0
+	updates, stop := runDispatcher(t, DispatcherConfig{Workers: 1, QueueSize: 2}, func(ctx context.Context, u tgbotapi.Update) {
+		<-release
+		mu.Lock()
+		handled++
+		mu.Unlock()
+	})
+
+	// первое обновление обрабатывается, два ждут в очереди, четвертое ждет места в очереди
+	for id := 1; id <= 4; id++ {
+		if !updates.send(chatUpdate(id, 7, "text"), time.Second) {
+			t.Fatalf("обновление %d не принято при свободной очереди", id)
+		}
+	}
+	// очередь заполнена: диспетчер не читает новые обновления
+	if updates.send(chatUpdate(5, 7, "text"), 100*time.Millisecond) {
+		t.Fatal("обновление принято при заполненной очереди")
+	}
+	close(release)
+	if !updates.send(chatUpdate(5, 7, "text"), time.Second) {
+		t.Fatal("обновление не принято после освобождения очереди")
+	}
+	stop()
+	mu.Lock()
+	defer mu.Unlock()
+	if handled != 5 {
+		t.Errorf("обработано %d обновлений, ожидалось 5", handled)
+	}
+}
+
+func TestDispatcherDrainsOnShutdown(t *testing.T) {
+	var mu sync.Mutex
+	handled := []int{}
+	updates, stop := runDispatcher(t, DispatcherConfig{Workers: 1, QueueSize: 8, ShutdownTimeout: 5 * time.Second}, func(ctx context.Context, u tgbotapi.Update) {
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		handled = append(handled, u.UpdateID)
+		mu.Unlock()
+	})
+	for id := 1; id <= 5; id++ {
+		updates.send(chatUpdate(id, 7, "text"), time.Second)
+	}
+	stop()
+	select {
+	case <-updates.stopped:
+	default:
+		t.Error("при остановке не вызван StopReceivingUpdates")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(handled) != 5 {
+		t.Errorf("до остановки обработано %v, ожидались все 5 принятых обновлений", handled)
+	}
+}
+
+func TestDispatcherShutdownTimeoutCancelsHandlers(t *testing.T) {
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+	var mu sync.Mutex
+	handled := []int{}
+	updates, stop := runDispatcher(t, DispatcherConfig{Workers: 1, QueueSize: 8, ShutdownTimeout: 100 * time.Millisecond}, func(ctx context.Context, u tgbotapi.Update) {
+		mu.Lock()
+		handled = append(handled, u.UpdateID)
+		mu.Unlock()
+		if u.UpdateID == 1 {
+			close(started)
+			<-ctx.Done()
+			close(cancelled)
+		}
+	})
+	updates.send(chatUpdate(1, 7, "stuck"), time.Second)
+	updates.send(chatUpdate(2, 7, "queued"), time.Second)
+	<-started
+
+	start := time.Now()
+	stop()
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("остановка заняла %v при ShutdownTimeout 100ms", elapsed)
+	}
+	select {
+	case <-cancelled:
+	default:
+		t.Fatal("контекст зависшего обработчика не отменен после ShutdownTimeout")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(handled) != 1 {
+		t.Errorf("обработаны %v: обновления из очереди после истечения ShutdownTimeout должны отбрасываться", handled)
+	}
+}
+
+func TestDispatcherRecoversPanic(t *testing.T) {
+	handled := make(chan int, 2)
+	updates, stop := runDispatcher(t, DispatcherConfig{Workers: 1}, func(ctx context.Context, u tgbotapi.Update) {
+		if u.UpdateID == 1 {
+			panic("сбой обработчика")
+		}
+		handled <- u.UpdateID
+	})
+	updates.send(chatUpdate(1, 7, "panic"), time.Second)
+	updates.send(chatUpdate(2, 7, "ok"), time.Second)
+	select {
+	case id := <-handled:
+		if id != 2 {
+			t.Fatalf("обработано %d, ожидалось 2", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("воркер остановился после паники")
+	}
+	stop()
+}
@@ -0,0 +1,30 @@
+package bot
+
+import (
+	"tourism/internal/i18n"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// LanguagePrefix — префикс данных кнопок выбора языка интерфейса: LANG_<локаль>.
+const LanguagePrefix = "LANG_"
+
+// LanguageKeyboard возвращает кнопки выбора языка: по кнопке на каждую локаль с ее названием
+// на ней самой (сообщение language.name), текущая локаль отмечена. Локаль по умолчанию идет первой.
+func LanguageKeyboard(texts *i18n.Registry, current string) tgbotapi.InlineKeyboardMarkup {
+	locales := []string{i18n.DefaultLocale}
+	for _, l := range texts.Locales() {
+		if l != i18n.DefaultLocale {
+			locales = append(locales, l)
+		}
+	}
+	rows := [][]tgbotapi.InlineKeyboardButton{}
+	for _, l := range locales {
+		title := texts.Text(l, "language.name")
+		if l == current {
+			title = "✅ " + title
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(title, LanguagePrefix+l)))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
@@ -0,0 +1,49 @@
+package bot
+
+import (
+	"tourism/internal/i18n"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// MenuButtons — кнопки главного меню основного бота по ролям (идентификаторы сообщений с надписями).
+// Меню нужно и вне обработчиков: уведомление об одобрении заявки провайдера сразу присылает новое.
+var MenuButtons = map[string][][]string{
+	"user": {
+		{"menu.search", "menu.new_trip"},
+		{"menu.subscription", "menu.support"},
+		{"menu.language", "menu.become_provider"},
+	},
+	"provider": {
+		{"menu.search", "menu.bookings"},
+		{"menu.catalog", "menu.support"},
+		{"menu.language"},
+	},
+	"support": {
+		{"menu.broadcast", "menu.add_photo"},
+		{"menu.check_locations", "menu.applications"},
+		{"menu.changes", "menu.language"},
+	},
+	"admin": {
+		{"menu.users", "menu.audit"},
+		{"menu.language"},
+	},
+}
+
+// MenuKeyboard возвращает клавиатуру главного меню для роли role на языке locale.
+// Для неизвестной роли используется меню туриста.
+func MenuKeyboard(texts *i18n.Registry, locale string, role string) tgbotapi.ReplyKeyboardMarkup {
+	layout, ok := MenuButtons[role]
+	if !ok {
+		layout = MenuButtons["user"]
+	}
+	rows := [][]tgbotapi.KeyboardButton{}
+	for _, keys := range layout {
+		row := []tgbotapi.KeyboardButton{}
+		for _, key := range keys {
+			row = append(row, tgbotapi.NewKeyboardButton(texts.Text(locale, key)))
+		}
+		rows = append(rows, tgbotapi.NewKeyboardButtonRow(row...))
+	}
+	return tgbotapi.NewReplyKeyboard(rows...)
+}
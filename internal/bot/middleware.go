@@ -0,0 +1,105 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"time"
+
+	"tourism/internal/model"
+)
+
+// Recover перехватывает панику в обработчике и превращает ее в ошибку, чтобы бот продолжил работу.
+func Recover() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) (err error) {
+			defer func() {
+				if p := recover(); p != nil {
+					slog.ErrorContext(c, "Паника при обработке обновления", "panic", p, "stack", string(debug.Stack()))
+					err = fmt.Errorf("паника: %v", p)
+				}
+			}()
+			return next(c)
+		}
+	}
+}
+
+// Logger логирует каждое обновление: отправителя, текст или данные кнопки, длительность и ошибку.
+func Logger() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			start := time.Now()
+			err := next(c)
+			attrs := []any{
+				"type", UpdateType(c.Update),
+				"from", c.From.ID,
+				"text", c.Text(),
+				"duration_ms", time.Since(start).Milliseconds(),
+			}
+			if err != nil {
+				slog.ErrorContext(c, "Ошибка обработки обновления", append(attrs, "err", err)...)
+			} else {
+				slog.InfoContext(c, "Обновление обработано", attrs...)
+			}
+			return err
+		}
+	}
+}
+
+// Authenticator находит или регистрирует пользователя по данным Telegram.
+type Authenticator interface {
+	AuthUser(ctx context.Context, telegramID int64, username, firstName, lastName, languageCode string) (*model.User, error)
+}
+
+// Auth заполняет Context.User. Новые пользователи регистрируются при первом обращении.
+// Обновления от пользователей, заблокированных администратором, пропускаются без ответа.
+func Auth(auth Authenticator) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			u, err := auth.AuthUser(c, c.From.ID, c.From.UserName, c.From.FirstName, c.From.LastName, c.From.LanguageCode)
+			if err != nil {
+				return fmt.Errorf("авторизация пользователя %d: %w", c.From.ID, err)
+			}
+			if u.IsBlocked {
+				slog.InfoContext(c, "Обновление заблокированного пользователя пропущено", "user_id", u.ID)
+				return nil
+			}
+			c.User = u
+			return next(c)
+		}
+	}
+}
+
+// RequireRole пропускает к обработчику только пользователей с указанной ролью. Для ролей,
+// у которых есть сообщение bot.role_required.<роль>, отказ объясняется им, иначе общим текстом.
+func RequireRole(role string, h HandlerFunc) HandlerFunc {
+	return func(c *Context) error {
+		if c.User == nil || c.User.Role != role {
+			key := "bot.role_required." + role
+			if !c.Texts.Has(key) {
+				return c.Reply(c.T("bot.role_required", "role", role))
+			}
+			return c.Reply(c.T(key))
+		}
+		return h(c)
+	}
+}
+
+// RunCleanup периодически удаляет брошенные сценарии с истекшим временем ожидания.
+func RunCleanup(ctx context.Context, store StateStore, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if n, err := store.DeleteExpired(ctx, now); err != nil {
+				slog.Error("Очистка состояний диалогов", "err", err)
+			} else if n > 0 {
+				slog.Info("Удалены брошенные диалоги", "count", n)
+			}
+		}
+	}
+}
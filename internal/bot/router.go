@@ -0,0 +1,236 @@
+// Package bot содержит каркас диалогов основного Telegram-бота: маршрутизацию команд,
+// кнопок и inline-кнопок по обработчикам, хранение состояния сценариев и middleware.
+package bot
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"tourism/internal/i18n"
+	"tourism/internal/model"
+	"tourism/internal/telegram"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// HandlerFunc обрабатывает одно обновление. Возвращенная ошибка логируется,
+// а пользователь получает общее сообщение об ошибке.
+type HandlerFunc func(c *Context) error
+
+// Middleware оборачивает обработчик дополнительной логикой.
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// StateStore хранит состояние сценариев пользователей.
+type StateStore interface {
+	Get(ctx context.Context, telegramID int64) (*model.ConversationState, error)
+	Save(ctx context.Context, state *model.ConversationState) error
+	Delete(ctx context.Context, telegramID int64) error
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// DefaultStateTTL — сколько бот ждет ответа пользователя на шаге сценария.
+const DefaultStateTTL = 30 * time.Minute
+
+// CancelCommand — встроенная команда выхода из любого сценария.
+const CancelCommand = "cancel"
+
+// сообщения пользователю от самого роутера
+const (
+	errorText     = "bot.error"
+	cancelledText = "bot.cancelled"
+	nothingText   = "bot.nothing_to_cancel"
+	expiredText   = "bot.expired"
+)
+
+type callbackRoute struct {
+	prefix  string
+	handler HandlerFunc
+}
+
+// Router выбирает обработчик для обновления:
+//   - inline-кнопки — по префиксу данных кнопки (самый длинный подходящий префикс);
+//   - /cancel завершает активный сценарий;
+//   - команды и кнопки меню прерывают активный сценарий;
+//   - остальные сообщения получает обработчик активного сценария, а без сценария — Fallback.
+type Router struct {
+	store      StateStore
+	texts      *i18n.Registry
+	ttl        time.Duration
+	middleware []Middleware
+	commands   map[string]HandlerFunc
+	buttons    map[string]HandlerFunc
+	callbacks  []callbackRoute
+	flows      map[string]HandlerFunc
+	fallback   HandlerFunc
+}
+
+// NewRouter создает роутер. ttl — время ожидания ответа на шаге сценария по умолчанию,
+// texts — тексты сообщений бота (см. Context.T).
+func NewRouter(store StateStore, texts *i18n.Registry, ttl time.Duration) *Router {
+	return &Router{
+		store:    store,
+		texts:    texts,
+		ttl:      ttl,
+		commands: make(map[string]HandlerFunc),
+		buttons:  make(map[string]HandlerFunc),
+		flows:    make(map[string]HandlerFunc),
+	}
+}
+
+// Use добавляет middleware. Middleware выполняются в порядке добавления.
+func (r *Router) Use(mw ...Middleware) {
+	r.middleware = append(r.middleware, mw...)
+}
+
+// Command регистрирует обработчик команды (без "/").
+func (r *Router) Command(name string, h HandlerFunc) {
+	r.commands[name] = h
+}
+
+// Text регистрирует обработчик кнопки клавиатуры (точного текста сообщения).
+func (r *Router) Text(text string, h HandlerFunc) {
+	r.buttons[text] = h
+}
+
+// Button регистрирует обработчик кнопки клавиатуры, надпись которой — сообщение key,
+// на всех языках бота.
+func (r *Router) Button(key string, h HandlerFunc) {
+	for _, text := range r.texts.Variants(key) {
+		r.Text(text, h)
+	}
+}
+
+// Callback регистрирует обработчик inline-кнопок, данные которых начинаются с prefix.
+func (r *Router) Callback(prefix string, h HandlerFunc) {
+	r.callbacks = append(r.callbacks, callbackRoute{prefix: prefix, handler: h})
+}
+
+// Flow регистрирует обработчик сообщений пользователей, находящихся в сценарии flow.
+func (r *Router) Flow(flow string, h HandlerFunc) {
+	r.flows[flow] = h
+}
+
+// Fallback регистрирует обработчик сообщений вне сценариев, не являющихся командами и кнопками меню.
+func (r *Router) Fallback(h HandlerFunc) {
+	r.fallback = h
+}
+
+// Handle обрабатывает обновление. Неподдерживаемые типы обновлений пропускаются.
+func (r *Router) Handle(api telegram.Sender, update tgbotapi.Update) {
+	r.HandleContext(context.Background(), api, update)
+}
+
+// HandleContext обрабатывает обновление; ctx становится контекстом обработчика (см. Context).
+func (r *Router) HandleContext(ctx context.Context, api telegram.Sender, update tgbotapi.Update) {
+	c := &Context{Bot: api, Update: update, Texts: r.texts, ctx: ctx, store: r.store, ttl: r.ttl}
+	switch {
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil:
+		c.ChatID = update.CallbackQuery.Message.Chat.ID
+		c.From = update.CallbackQuery.From
+	case update.Message != nil && update.Message.From != nil:
+		c.ChatID = update.Message.Chat.ID
+		c.From = update.Message.From
+	default:
+		return
+	}
+
+	h := r.dispatch
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		h = r.middleware[i](h)
+	}
+	if err := h(c); err != nil {
+		c.Reply(c.T(errorText))
+	}
+}
+
+// Run получает обновления из updates (long polling или вебхук) и обрабатывает их параллельно
+// (см. Dispatcher), отвечая через api, пока источник не закроет канал или не будет отменен ctx.
+func (r *Router) Run(ctx context.Context, api telegram.Sender, updates telegram.Updates, cfg DispatcherConfig) {
+	NewDispatcher(cfg, func(ctx context.Context, update tgbotapi.Update) {
+		r.HandleContext(ctx, api, update)
+	}).Run(ctx, updates)
+}
+
+func (r *Router) dispatch(c *Context) error {
+	state, err := r.store.Get(c, c.From.ID)
+	if err != nil {
+		return err
+	}
+	c.State = state
+	// брошенный сценарий завершается, а пользователь узнает об этом, если продолжит его сообщением
+	expired := state != nil && state.Expired(time.Now())
+	if expired {
+		if err := c.ClearState(); err != nil {
+			return err
+		}
+	}
+
+	if cq := c.Callback(); cq != nil {
+		c.Bot.Request(tgbotapi.NewCallback(cq.ID, ""))
+		if route := r.matchCallback(cq.Data); route != nil {
+			c.param = strings.TrimPrefix(cq.Data, route.prefix)
+			return route.handler(c)
+		}
+		return nil
+	}
+
+	msg := c.Message()
+	if msg.IsCommand() && msg.Command() == CancelCommand {
+		if c.State == nil {
+			return c.Reply(c.T(nothingText))
+		}
+		if err := c.ClearState(); err != nil {
+			return err
+		}
+		return c.Reply(c.T(cancelledText))
+	}
+
+	// команды и кнопки меню начинают новое действие, поэтому прерывают текущий сценарий
+	var h HandlerFunc
+	if msg.IsCommand() {
+		h = r.commands[msg.Command()]
+	} else {
+		h = r.buttons[msg.Text]
+	}
+	if h != nil {
+		if c.State != nil {
+			c.Interrupted = c.State
+			if err := c.ClearState(); err != nil {
+				return err
+			}
+		}
+		return h(c)
+	}
+
+	if expired {
+		return c.Reply(c.T(expiredText))
+	}
+	if c.State != nil {
+		if h, ok := r.flows[c.State.Flow]; ok {
+			return h(c)
+		}
+		slog.WarnContext(c, "Неизвестный сценарий, состояние сброшено", "flow", c.State.Flow, "user_id", c.From.ID)
+		if err := c.ClearState(); err != nil {
+			return err
+		}
+	}
+
+	if r.fallback != nil {
+		return r.fallback(c)
+	}
+	return nil
+}
+
+// matchCallback находит обработчик inline-кнопки с самым длинным подходящим префиксом.
+func (r *Router) matchCallback(data string) *callbackRoute {
+	var best *callbackRoute
+	for i := range r.callbacks {
+		route := &r.callbacks[i]
+		if strings.HasPrefix(data, route.prefix) && (best == nil || len(route.prefix) > len(best.prefix)) {
+			best = route
+		}
+	}
+	return best
+}
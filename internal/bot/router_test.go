@@ -0,0 +1,486 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"tourism/internal/i18n"
+	"tourism/internal/model"
+	"tourism/internal/repository/memory"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// fakeAPI записывает отправленные сообщения и служебные запросы вместо обращения к Telegram.
+type fakeAPI struct {
+	mu       sync.Mutex
+	sent     []tgbotapi.MessageConfig
+	requests []tgbotapi.Chattable
+	mediaErr error // ошибка отправки альбомов
+}
+
+func (f *fakeAPI) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if m, ok := c.(tgbotapi.MessageConfig); ok {
+		f.sent = append(f.sent, m)
+	}
+	return tgbotapi.Message{MessageID: len(f.sent)}, nil
+}
+
+func (f *fakeAPI) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, c)
+	return &tgbotapi.APIResponse{Ok: true}, nil
+}
+
+func (f *fakeAPI) SendMediaGroup(tgbotapi.MediaGroupConfig) ([]tgbotapi.Message, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return nil, f.mediaErr
+}
+
+// texts возвращает тексты отправленных сообщений и очищает список.
+func (f *fakeAPI) texts() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []string
+	for _, m := range f.sent {
+		out = append(out, m.Text)
+	}
+	f.sent = nil
+	return out
+}
+
+// answered возвращает ID нажатий, на которые бот ответил answerCallbackQuery.
+func (f *fakeAPI) answered() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var ids []string
+	for _, r := range f.requests {
+		if cb, ok := r.(tgbotapi.CallbackConfig); ok {
+			ids = append(ids, cb.CallbackQueryID)
+		}
+	}
+	return ids
+}
+
+const userID = 42
+
+type routerEnv struct {
+	t      *testing.T
+	api    *fakeAPI
+	store  *memory.StateRepository
+	texts  *i18n.Registry
+	router *Router
+	calls  []string // обработчики, получившие обновления
+}
+
+func newRouterEnv(t *testing.T) *routerEnv {
+	t.Helper()
+	texts, err := i18n.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := &routerEnv{t: t, api: &fakeAPI{}, store: memory.NewStateRepository(memory.NewStore()), texts: texts}
+	env.router = NewRouter(env.store, texts, time.Hour)
+	return env
+}
+
+// handler возвращает обработчик, который только записывает свое имя.
+func (e *routerEnv) handler(name string) HandlerFunc {
+	return func(c *Context) error {
+		e.calls = append(e.calls, name)
+		return nil
+	}
+}
+
+// text возвращает сообщение key на языке по умолчанию.
+func (e *routerEnv) text(key string) string {
+	return e.texts.Text(e.texts.Resolve(""), key)
+}
+
+func (e *routerEnv) send(text string) {
+	msg := &tgbotapi.Message{
+		MessageID: 1,
+		Chat:      &tgbotapi.Chat{ID: userID},
+		From:      &tgbotapi.User{ID: userID},
+		Text:      text,
+	}
+	if len(text) > 1 && text[0] == '/' {
+		end := len(text)
+		for i, r := range text {
+			if r == ' ' {
+				end = i
+				break
+			}
+		}
+		msg.Entities = []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: end}}
+	}
+	e.router.Handle(e.api, tgbotapi.Update{Message: msg})
+}
+
+func (e *routerEnv) press(id, data string) {
+	e.router.Handle(e.api, tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+		ID:      id,
+		From:    &tgbotapi.User{ID: userID},
+		Message: &tgbotapi.Message{MessageID: 1, Chat: &tgbotapi.Chat{ID: userID}},
+		Data:    data,
+	}})
+}
+
+// setState переводит пользователя в сценарий flow, ожидание ответа в котором истекает через ttl.
+func (e *routerEnv) setState(flow string, ttl time.Duration) {
+	e.t.Helper()
+	expires := time.Now().Add(ttl)
+	err := e.store.Save(context.Background(), &model.ConversationState{TelegramID: userID, Flow: flow, Step: "step", ExpiresAt: &expires})
+	if err != nil {
+		e.t.Fatal(err)
+	}
+}
+
+func (e *routerEnv) state() *model.ConversationState {
+	e.t.Helper()
+	st, err := e.store.Get(context.Background(), userID)
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	return st
+}
+
+// expect проверяет обработчики, вызванные с прошлой проверки, и отправленные сообщения.
+func (e *routerEnv) expect(calls []string, texts ...string) {
+	e.t.Helper()
+	if !slices.Equal(e.calls, calls) {
+		e.t.Errorf("вызваны обработчики %v, ожидались %v", e.calls, calls)
+	}
+	if got := e.api.texts(); !slices.Equal(got, texts) {
+		e.t.Errorf("отправлены сообщения %q, ожидались %q", got, texts)
+	}
+	e.calls = nil
+}
+
+func TestRouterDispatch(t *testing.T) {
+	env := newRouterEnv(t)
+	r := env.router
+	r.Command("start", env.handler("start"))
+	r.Text("Каталог", env.handler("catalog"))
+	r.Button("menu.search", env.handler("search"))
+	r.Flow("booking", env.handler("booking"))
+	r.Fallback(env.handler("fallback"))
+
+	env.send("/start")
+	env.expect([]string{"start"})
+	env.send("/start@tourism_bot payload")
+	env.expect([]string{"start"})
+	env.send("Каталог")
+	env.expect([]string{"catalog"})
+	// кнопка меню распознается на любом языке бота
+	for _, text := range env.texts.Variants("menu.search") {
+		env.send(text)
+		env.expect([]string{"search"})
+	}
+	env.send("/unknown")
+	env.expect([]string{"fallback"})
+	env.send("просто текст")
+	env.expect([]string{"fallback"})
+
+	// в сценарии сообщения получает его обработчик
+	env.setState("booking", time.Hour)
+	env.send("завтра в 10")
+	env.expect([]string{"booking"})
+	if env.state() == nil {
+		t.Fatal("сценарий завершен без ClearState")
+	}
+
+	// неизвестный сценарий сбрасывается, сообщение получает Fallback
+	env.setState("removed_flow", time.Hour)
+	env.send("текст")
+	env.expect([]string{"fallback"})
+	if env.state() != nil {
+		t.Error("состояние неизвестного сценария не сброшено")
+	}
+}
+
+func TestRouterInterruptsFlow(t *testing.T) {
+	env := newRouterEnv(t)
+	var interrupted *model.ConversationState
+	env.router.Command("start", func(c *Context) error {
+		env.calls = append(env.calls, "start")
+		interrupted = c.Interrupted
+		if c.State != nil {
+			t.Error("обработчик команды получил прерванный сценарий в State")
+		}
+		return nil
+	})
+	env.router.Flow("booking", env.handler("booking"))
+
+	env.setState("booking", time.Hour)
+	env.send("/start")
+	env.expect([]string{"start"})
+	if interrupted == nil || interrupted.Flow != "booking" {
+		t.Errorf("Interrupted = %+v, ожидался сценарий booking", interrupted)
+	}
+	if env.state() != nil {
+		t.Error("команда не прервала сценарий")
+	}
+	env.send("завтра в 10")
+	env.expect(nil)
+}
+
+func TestRouterCancel(t *testing.T) {
+	env := newRouterEnv(t)
+	env.router.Flow("booking", env.handler("booking"))
+	env.router.Fallback(env.handler("fallback"))
+
+	env.send("/cancel")
+	env.expect(nil, env.text(nothingText))
+
+	env.setState("booking", time.Hour)
+	env.send("/cancel")
+	env.expect(nil, env.text(cancelledText))
+	if env.state() != nil {
+		t.Error("/cancel не завершил сценарий")
+	}
+	env.send("текст")
+	env.expect([]string{"fallback"})
+
+	// /cancel встроена в роутер и не переопределяется обработчиком команды
+	env.router.Command(CancelCommand, env.handler("cancel"))
+	env.send("/cancel")
+	env.expect(nil, env.text(nothingText))
+}
+
+func TestRouterStateTimeout(t *testing.T) {
+	env := newRouterEnv(t)
+	env.router.Command("start", env.handler("start"))
+	env.router.Flow("booking", env.handler("booking"))
+	env.router.Fallback(env.handler("fallback"))
+
+	// ответ после тайм-аута не попадает в брошенный сценарий
+	env.setState("booking", -time.Minute)
+	env.send("завтра в 10")
+	env.expect(nil, env.text(expiredText))
+	if env.state() != nil {
+		t.Error("истекший сценарий не удален")
+	}
+	env.send("текст")
+	env.expect([]string{"fallback"})
+
+	// команда после тайм-аута выполняется без сообщения об истечении и без Interrupted
+	env.setState("booking", -time.Minute)
+	var interrupted *model.ConversationState
+	env.router.Command("start", func(c *Context) error {
+		env.calls = append(env.calls, "start")
+		interrupted = c.Interrupted
+		return nil
+	})
+	env.send("/start")
+	env.expect([]string{"start"})
+	if interrupted != nil {
+		t.Errorf("истекший сценарий передан как прерванный: %+v", interrupted)
+	}
+
+	// /cancel после тайм-аута: отменять уже нечего
+	env.setState("booking", -time.Minute)
+	env.send("/cancel")
+	env.expect(nil, env.text(nothingText))
+}
+
+func TestContextSetState(t *testing.T) {
+	env := newRouterEnv(t)
+	type payload struct {
+		LocationID int `json:"location_id"`
+	}
+	env.router.Command("book", func(c *Context) error {
+		return c.SetState("booking", "details", payload{LocationID: 7})
+	})
+	env.router.Command("chat", func(c *Context) error {
+		return c.SetStateTTL("chat", "open", nil, 0)
+	})
+	var got payload
+	var step string
+	env.router.Flow("booking", func(c *Context) error {
+		step = c.State.Step
+		return c.Payload(&got)
+	})
+
+	start := time.Now()
+	env.send("/book")
+	st := env.state()
+	if st == nil || st.Flow != "booking" || st.ExpiresAt == nil {
+		t.Fatalf("состояние %+v", st)
+	}
+	if d := st.ExpiresAt.Sub(start); d < time.Hour || d > time.Hour+time.Minute {
+		t.Errorf("ожидание ответа %v, ожидался тайм-аут роутера 1h", d)
+	}
+	env.send("двое взрослых")
+	if step != "details" || got.LocationID != 7 {
+		t.Errorf("шаг %q, данные %+v", step, got)
+	}
+
+	// сценарий без ограничения по времени не истекает
+	env.send("/chat")
+	if st := env.state(); st == nil || st.Flow != "chat" || st.ExpiresAt != nil {
+		t.Errorf("состояние %+v, ожидался сценарий chat без тайм-аута", st)
+	}
+}
+
+func TestRouterCallbacks(t *testing.T) {
+	env := newRouterEnv(t)
+	var params []string
+	route := func(name string) HandlerFunc {
+		return func(c *Context) error {
+			env.calls = append(env.calls, name)
+			params = append(params, c.Param())
+			return nil
+		}
+	}
+	env.router.Callback("loc:", route("loc"))
+	env.router.Callback("loc:book:", route("book"))
+	env.router.Callback("page:", func(c *Context) error {
+		_, err := c.IntParam()
+		return err
+	})
+
+	env.press("1", "loc:15")
+	env.press("2", "loc:book:15")
+	env.press("3", "unknown:1")
+	env.expect([]string{"loc", "book"})
+	if !slices.Equal(params, []string{"15", "15"}) {
+		t.Errorf("параметры кнопок %v", params)
+	}
+	// на каждое нажатие бот отвечает, чтобы у пользователя пропал индикатор загрузки,
+	// даже если кнопка устарела и обработчика у нее нет
+	if got := env.api.answered(); !slices.Equal(got, []string{"1", "2", "3"}) {
+		t.Errorf("ответы на нажатия %v", got)
+	}
+
+	// ошибка обработчика кнопки сообщается пользователю
+	env.press("4", "page:abc")
+	env.expect(nil, env.text(errorText))
+
+	// нажатие кнопки не прерывает сценарий
+	env.setState("booking", time.Hour)
+	env.press("5", "loc:3")
+	env.expect([]string{"loc"})
+	if env.state() == nil {
+		t.Error("нажатие кнопки прервало сценарий")
+	}
+	// кнопка под сообщением брошенного сценария работает, а сценарий удаляется
+	env.setState("booking", -time.Minute)
+	env.press("6", "loc:3")
+	env.expect([]string{"loc"})
+	if env.state() != nil {
+		t.Error("истекший сценарий не удален")
+	}
+}
+
+func TestRouterMiddleware(t *testing.T) {
+	env := newRouterEnv(t)
+	var order []string
+	mark := func(name string) Middleware {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(c *Context) error {
+				order = append(order, name+">")
+				err := next(c)
+				order = append(order, "<"+name)
+				return err
+			}
+		}
+	}
+	env.router.Use(Recover(), mark("a"), mark("b"))
+	env.router.Command("start", func(c *Context) error {
+		order = append(order, "start")
+		return nil
+	})
+	env.router.Command("fail", func(c *Context) error { return errors.New("сбой") })
+	env.router.Command("panic", func(c *Context) error { panic("сбой") })
+
+	env.send("/start")
+	if want := []string{"a>", "b>", "start", "<b", "<a"}; !slices.Equal(order, want) {
+		t.Errorf("порядок middleware %v, ожидался %v", order, want)
+	}
+	env.send("/fail")
+	env.send("/panic")
+	env.expect(nil, env.text(errorText), env.text(errorText))
+
+	// обновления без отправителя (например, посты каналов) пропускаются
+	order = nil
+	env.router.Handle(env.api, tgbotapi.Update{ChannelPost: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: -100}}})
+	env.router.Handle(env.api, tgbotapi.Update{Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: -100}, Text: "/start"}})
+	if len(order) != 0 {
+		t.Errorf("обработано обновление без отправителя: %v", order)
+	}
+}
+
+type fakeAuth struct{ user *model.User }
+
+func (a fakeAuth) AuthUser(ctx context.Context, telegramID int64, username, firstName, lastName, languageCode string) (*model.User, error) {
+	if a.user == nil {
+		return nil, errors.New("база данных недоступна")
+	}
+	u := *a.user
+	u.TelegramID = telegramID
+	return &u, nil
+}
+
+func TestAuthAndRequireRole(t *testing.T) {
+	tests := []struct {
+		name  string
+		user  *model.User
+		calls []string
+		texts []string
+	}{
+		{"провайдер", &model.User{ID: 1, Role: "provider"}, []string{"locations"}, nil},
+		{"турист", &model.User{ID: 2, Role: "user"}, nil, []string{"bot.role_required.provider"}},
+		{"заблокированный", &model.User{ID: 3, Role: "provider", IsBlocked: true}, nil, nil},
+		{"ошибка авторизации", nil, nil, []string{errorText}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newRouterEnv(t)
+			env.router.Use(Auth(fakeAuth{tt.user}))
+			env.router.Command("my_locations", RequireRole("provider", env.handler("locations")))
+			env.send("/my_locations")
+			var texts []string
+			for _, key := range tt.texts {
+				texts = append(texts, env.text(key))
+			}
+			env.expect(tt.calls, texts...)
+		})
+	}
+}
+
+func TestContextSendMediaGroupLogsError(t *testing.T) {
+	var logs bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	defer slog.SetDefault(prev)
+
+	env := newRouterEnv(t)
+	env.api.mediaErr = errors.New("Bad Request: wrong file identifier")
+	var sendErr error
+	env.router.Command("album", func(c *Context) error {
+		_, sendErr = c.SendMediaGroup(tgbotapi.NewMediaGroup(c.ChatID, []interface{}{
+			tgbotapi.NewInputMediaPhoto(tgbotapi.FileID("a")),
+			tgbotapi.NewInputMediaPhoto(tgbotapi.FileID("b")),
+		}))
+		return nil
+	})
+	env.send("/album")
+	if sendErr == nil {
+		t.Error("ошибка отправки альбома не возвращена")
+	}
+	if out := logs.String(); !strings.Contains(out, "wrong file identifier") || !strings.Contains(out, "photos=2") {
+		t.Errorf("ошибка отправки альбома не записана в лог: %s", out)
+	}
+}
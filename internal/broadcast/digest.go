@@ -0,0 +1,143 @@
+package broadcast
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"tourism/internal/i18n"
+	"tourism/internal/model"
+	"tourism/internal/service"
+	"tourism/internal/telegram"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// DigestConfig задает расписание отправки дайджестов.
+type DigestConfig struct {
+	CheckInterval time.Duration  // как часто искать подписчиков, которым пора отправить дайджест
+	BatchSize     int            // сколько подписчиков обрабатывать за одну проверку
+	Lease         time.Duration  // время, на которое подписчик резервируется за экземпляром
+	FromHour      int            // отправлять не раньше этого часа (по Zone)
+	ToHour        int            // и не позже этого часа
+	Zone          *time.Location // часовой пояс подписчиков
+}
+
+// DefaultDigestConfig возвращает расписание по умолчанию: проверка раз в 15 минут, с 10 до 21 часа по Москве.
+func DefaultDigestConfig() DigestConfig {
+	return DigestConfig{
+		CheckInterval: 15 * time.Minute,
+		BatchSize:     200,
+		Lease:         time.Hour,
+		FromHour:      10,
+		ToHour:        21,
+		Zone:          time.FixedZone("MSK", 3*60*60),
+	}
+}
+
+// DigestJob периодически отправляет подписчикам персональный дайджест.
+type DigestJob struct {
+	sender telegram.Sender
+	svc    *service.DigestService
+	texts  *i18n.Registry
+	global *Limiter
+	cfg    DigestConfig
+}
+
+// NewDigestJob создает задачу отправки дайджестов.
+func NewDigestJob(sender telegram.Sender, svc *service.DigestService, texts *i18n.Registry, global *Limiter, cfg DigestConfig) *DigestJob {
+	return &DigestJob{sender: sender, svc: svc, texts: texts, global: global, cfg: cfg}
+}
+
+// Run выполняет задачу до отмены ctx.
+func (j *DigestJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.cfg.CheckInterval)
+	defer ticker.Stop()
+	for {
+		j.RunOnce(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce отправляет дайджесты всем подписчикам, которым они положены на момент now.
+func (j *DigestJob) RunOnce(ctx context.Context, now time.Time) {
+	if h := now.In(j.cfg.Zone).Hour(); h < j.cfg.FromHour || h >= j.cfg.ToHour {
+		return
+	}
+	subs, err := j.svc.DueSubscribers(ctx, now, now.Add(j.cfg.Lease), j.cfg.BatchSize)
+	if err != nil {
+		slog.Error("Ошибка выборки подписчиков дайджеста", "err", err)
+		return
+	}
+	sent := 0
+	for i := range subs {
+		sub := &subs[i]
+		digest, err := j.svc.Build(ctx, sub, now)
+		if err != nil {
+			slog.Error("Ошибка сборки дайджеста", "user_id", sub.UserID, "err", err)
+			continue
+		}
+		if !digest.IsEmpty() {
+			if err := j.global.Wait(ctx); err != nil {
+				return
+			}
+			_, sendErr := j.sender.Send(DigestMessage(j.texts, sub.PreferredLanguage(), sub.TelegramID, digest))
+			switch kind, retryAfter := classify(sendErr); kind {
+			case resultSent:
+				sent++
+			case resultRateLimited:
+				// оставшиеся подписчики получат дайджест, когда истечет их резерв
+				j.global.Pause(retryAfter)
+				return
+			case resultBlocked:
+				if err := j.svc.MarkInactive(context.WithoutCancel(ctx), sub.UserID); err != nil {
+					slog.Error("Ошибка отметки неактивного пользователя", "user_id", sub.UserID, "err", err)
+				}
+				continue
+			default:
+				slog.Warn("Не удалось отправить дайджест", "user_id", sub.UserID, "err", sendErr)
+				continue
+			}
+		}
+		// отправленный дайджест записывается и после остановки, чтобы не повторить его
+		if err := j.svc.Record(context.WithoutCancel(ctx), sub.UserID, digest, now); err != nil {
+			slog.Error("Ошибка сохранения дайджеста", "user_id", sub.UserID, "err", err)
+		}
+	}
+	if sent > 0 {
+		slog.Info("Отправлены дайджесты", "count", sent)
+	}
+}
+
+// DigestMessage формирует компактное сообщение дайджеста на языке locale с кнопками на карточки локаций.
+func DigestMessage(texts *i18n.Registry, locale string, chatID int64, d *model.Digest) tgbotapi.MessageConfig {
+	rows := [][]tgbotapi.InlineKeyboardButton{}
+	seen := map[int]bool{}
+	addButton := func(text string, locationID int) {
+		if seen[locationID] {
+			return
+		}
+		seen[locationID] = true
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(text, fmt.Sprintf("LOC_%d", locationID)),
+		))
+	}
+	for _, l := range d.Locations {
+		addButton("📍 "+l.Name, l.ID)
+	}
+	for _, o := range d.Offers {
+		addButton("🔥 "+o.Name, o.LocationID)
+	}
+	text := texts.Text(locale, "digest.message", "locations", d.Locations, "offers", d.Offers)
+	msg := tgbotapi.NewMessage(chatID, strings.TrimRight(text, "\n"))
+	if len(rows) > 0 {
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	}
+	return msg
+}
@@ -0,0 +1,71 @@
+package broadcast
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// LockID — ключ advisory-блокировки, которую держит экземпляр бота, выполняющий фоновые отправки.
+const LockID = 7240186
+
+// Lock — блокировка, которую в каждый момент держит не больше одного экземпляра бота.
+type Lock interface {
+	// Acquire пытается взять блокировку; false — ее держит другой экземпляр.
+	Acquire(ctx context.Context) (bool, error)
+	// Check проверяет, что блокировка все еще удерживается.
+	Check(ctx context.Context) error
+	// Release снимает блокировку.
+	Release(ctx context.Context) error
+}
+
+// RunExclusive выполняет run до отмены ctx, но только пока этот экземпляр держит lock. Limiter
+// считает отправки в памяти процесса, поэтому общий лимит Telegram соблюдается, только если
+// фоновые отправки выполняет один экземпляр бота. Остальные экземпляры каждые interval пытаются
+// взять блокировку и заменяют владельца, если он остановился или потерял соединение с базой.
+func RunExclusive(ctx context.Context, lock Lock, interval time.Duration, run func(ctx context.Context)) {
+	for {
+		held, err := lock.Acquire(ctx)
+		if err != nil {
+			slog.Error("Ошибка блокировки фоновых отправок", "err", err)
+		}
+		if held {
+			slog.Info("Экземпляр выполняет фоновые отправки")
+			runHeld(ctx, lock, interval, run)
+			if err := lock.Release(context.WithoutCancel(ctx)); err != nil {
+				slog.Error("Ошибка снятия блокировки фоновых отправок", "err", err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// runHeld выполняет run и останавливает его, если блокировка потеряна.
+func runHeld(ctx context.Context, lock Lock, interval time.Duration, run func(ctx context.Context)) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		run(ctx)
+	}()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := lock.Check(ctx); err != nil {
+				slog.Warn("Блокировка фоновых отправок потеряна, отправки остановлены", "err", err)
+				cancel()
+				<-done
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeLock — блокировка, состоянием которой управляет тест.
+type fakeLock struct {
+	mu       sync.Mutex
+	free     bool  // можно ли взять блокировку
+	checkErr error // ошибка Check: блокировка потеряна
+	held     bool
+	acquired int
+	released int
+}
+
+func (l *fakeLock) Acquire(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.free || l.held {
+		return false, nil
+	}
+	l.held = true
+	l.acquired++
+	return true, nil
+}
+
+func (l *fakeLock) Check(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.checkErr
+}
+
+func (l *fakeLock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.held = false
+	l.released++
+	return nil
+}
+
+func (l *fakeLock) set(free bool, checkErr error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.free, l.checkErr = free, checkErr
+}
+
+func (l *fakeLock) counts() (acquired, released int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.acquired, l.released
+}
+
+// waitReleased ждет, пока блокировка будет снята n раз.
+func (l *fakeLock) waitReleased(n int, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if _, released := l.counts(); released >= n {
+			return true
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return false
+}
+
+func TestRunExclusive(t *testing.T) {
+	lock := &fakeLock{}
+	runs := make(chan struct{}, 10)
+	stopped := make(chan struct{}, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		RunExclusive(ctx, lock, 10*time.Millisecond, func(ctx context.Context) {
+			runs <- struct{}{}
+			<-ctx.Done()
+			stopped <- struct{}{}
+		})
+	}()
+
+	// блокировку держит другой экземпляр: отправки не запускаются
+	select {
+	case <-runs:
+		t.Fatal("отправки запущены без блокировки")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// другой экземпляр остановился: этот берет блокировку и запускает отправки
+	lock.set(true, nil)
+	select {
+	case <-runs:
+	case <-time.After(time.Second):
+		t.Fatal("отправки не запущены после освобождения блокировки")
+	}
+
+	// соединение с базой потеряно: отправки останавливаются, блокировка снимается
+	lock.set(false, errors.New("connection reset"))
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("отправки не остановлены после потери блокировки")
+	}
+	if !lock.waitReleased(1, time.Second) {
+		acquired, released := lock.counts()
+		t.Errorf("блокировка взята %d раз, снята %d, ожидалось по одному", acquired, released)
+	}
+
+	// блокировка снова свободна: отправки перезапускаются
+	lock.set(true, nil)
+	select {
+	case <-runs:
+	case <-time.After(time.Second):
+		t.Fatal("отправки не перезапущены")
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("RunExclusive не завершился после отмены")
+	}
+	if acquired, released := lock.counts(); acquired != 2 || released != 2 {
+		t.Errorf("после остановки блокировка взята %d раз, снята %d, ожидалось по два", acquired, released)
+	}
+}
@@ -0,0 +1,102 @@
+package broadcast
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"tourism/internal/telegram"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// DefaultRate — число сообщений в секунду на бота по умолчанию (Telegram допускает ~30).
+const DefaultRate = 25
+
+// Limiter равномерно распределяет отправки во времени: не чаще одной за interval.
+// Один Limiter разделяется всеми отправками бота: фоновыми (уведомления, рассылки, дайджесты)
+// и ответами пользователям (через LimitedSender). Он считает отправки только в своем процессе,
+// поэтому фоновые отправки запускаются через RunExclusive: их выполняет один экземпляр бота.
+// Ответы на обновления отправляет экземпляр, принявший обновление, и учитываются они в его лимите.
+type Limiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// NewLimiter создает ограничитель на perSecond отправок в секунду.
+func NewLimiter(perSecond int) *Limiter {
+	if perSecond <= 0 {
+		perSecond = 1
+	}
+	return &Limiter{interval: time.Second / time.Duration(perSecond)}
+}
+
+// Wait блокируется до момента, когда можно выполнить следующую отправку.
+func (l *Limiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+
+	d := at.Sub(now)
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Pause приостанавливает отправки на d (используется при ответе 429 от Telegram).
+func (l *Limiter) Pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := time.Now().Add(d); until.After(l.next) {
+		l.next = until
+	}
+}
+
+// LimitedSender отправляет сообщения не чаще, чем позволяет Limiter, а при ответе 429
+// приостанавливает все отправки, разделяющие этот Limiter. Request (ответы на нажатия кнопок,
+// служебные методы) не ограничивается: это не сообщения в чаты.
+type LimitedSender struct {
+	telegram.Sender
+	limiter *Limiter
+}
+
+var _ telegram.Sender = (*LimitedSender)(nil)
+
+// NewLimitedSender оборачивает sender ограничителем l.
+func NewLimitedSender(sender telegram.Sender, l *Limiter) *LimitedSender {
+	return &LimitedSender{Sender: sender, limiter: l}
+}
+
+func (s *LimitedSender) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	s.limiter.Wait(context.Background())
+	msg, err := s.Sender.Send(c)
+	s.observe(err)
+	return msg, err
+}
+
+func (s *LimitedSender) SendMediaGroup(config tgbotapi.MediaGroupConfig) ([]tgbotapi.Message, error) {
+	s.limiter.Wait(context.Background())
+	msgs, err := s.Sender.SendMediaGroup(config)
+	s.observe(err)
+	return msgs, err
+}
+
+func (s *LimitedSender) observe(err error) {
+	if kind, retryAfter := classify(err); kind == resultRateLimited {
+		s.limiter.Pause(retryAfter)
+	}
+}
@@ -0,0 +1,87 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"tourism/internal/telegram/telegramtest"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestLimiterSpacesSends(t *testing.T) {
+	l := NewLimiter(20) // не чаще одной отправки в 50ms
+	ctx := context.Background()
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := l.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("4 отправки за %v, ожидалось не меньше 150ms", elapsed)
+	}
+}
+
+func TestLimiterPause(t *testing.T) {
+	l := NewLimiter(1000)
+	l.Pause(100 * time.Millisecond)
+	start := time.Now()
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("отправка после паузы через %v, ожидалось не меньше 100ms", elapsed)
+	}
+	// пауза короче уже запланированной не сдвигает отправки назад
+	l.Pause(time.Hour)
+	l.Pause(time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait во время паузы: %v, ожидалось context.DeadlineExceeded", err)
+	}
+}
+
+func TestLimitedSenderPausesOnRateLimit(t *testing.T) {
+	server := telegramtest.NewServer("123:test")
+	defer server.Close()
+	api, err := server.NewBot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := NewLimiter(1000)
+	sender := NewLimitedSender(api, l)
+
+	if _, err := sender.Send(tgbotapi.NewMessage(101, "Привет")); err != nil {
+		t.Fatal(err)
+	}
+	server.Fail("sendMessage", 429, "Too Many Requests: retry after 5", 5)
+	start := time.Now()
+	if _, err := sender.Send(tgbotapi.NewMessage(101, "Привет")); err == nil {
+		t.Fatal("ожидалась ошибка 429")
+	}
+	// ответ пользователю с 429 приостанавливает и фоновые отправки, разделяющие ограничитель
+	l.mu.Lock()
+	next := l.next
+	l.mu.Unlock()
+	if !near(next, start.Add(5*time.Second)) {
+		t.Errorf("ограничитель приостановлен до %v, ожидалось %v", next, start.Add(5*time.Second))
+	}
+	// служебные запросы не ждут ограничителя
+	done := make(chan error, 1)
+	go func() {
+		_, err := sender.Request(tgbotapi.NewCallback("1", ""))
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Request ждет ограничителя")
+	}
+}
@@ -0,0 +1,48 @@
+package broadcast
+
+import (
+	"fmt"
+
+	"tourism/internal/i18n"
+	"tourism/internal/model"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// CampaignMessage формирует сообщение рассылки для получателя chatID.
+func CampaignMessage(chatID int64, c *model.Campaign) tgbotapi.Chattable {
+	var markup *tgbotapi.InlineKeyboardMarkup
+	if len(c.Buttons) > 0 {
+		rows := [][]tgbotapi.InlineKeyboardButton{}
+		for _, b := range c.Buttons {
+			var btn tgbotapi.InlineKeyboardButton
+			if b.LocationID > 0 {
+				btn = tgbotapi.NewInlineKeyboardButtonData(b.Text, fmt.Sprintf("LOC_%d", b.LocationID))
+			} else {
+				btn = tgbotapi.NewInlineKeyboardButtonURL(b.Text, b.URL)
+			}
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(btn))
+		}
+		kb := tgbotapi.NewInlineKeyboardMarkup(rows...)
+		markup = &kb
+	}
+	if c.PhotoFileID != "" {
+		photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileID(c.PhotoFileID))
+		photo.Caption = c.Text
+		if markup != nil {
+			photo.ReplyMarkup = *markup
+		}
+		return photo
+	}
+	msg := tgbotapi.NewMessage(chatID, c.Text)
+	if markup != nil {
+		msg.ReplyMarkup = *markup
+	}
+	return msg
+}
+
+// FormatStats возвращает краткий отчет о доставке рассылки на языке locale.
+func FormatStats(texts *i18n.Registry, locale string, stats *model.DeliveryStats) string {
+	return texts.Text(locale, "broadcast.stats",
+		"sent", stats.Sent, "pending", stats.Pending, "failed", stats.Failed, "blocked", stats.Blocked)
+}
@@ -0,0 +1,219 @@
+package broadcast
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"tourism/internal/bot"
+	"tourism/internal/i18n"
+	"tourism/internal/metrics"
+	"tourism/internal/model"
+	"tourism/internal/service"
+	"tourism/internal/telegram"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// NotificationConfig задает параметры отправки уведомлений.
+type NotificationConfig struct {
+	MaxAttempts  int           // число попыток при временных ошибках
+	BatchSize    int           // сколько уведомлений забирать из очереди за раз
+	PollInterval time.Duration // пауза при пустой очереди
+	Lease        time.Duration // время, на которое уведомление резервируется за экземпляром
+	MaxBackoff   time.Duration // наибольшая пауза между попытками
+}
+
+// DefaultNotificationConfig возвращает параметры отправки уведомлений по умолчанию. Очередь
+// опрашивается чаще, чем очередь рассылок: уведомления ждут пользователи, только что сделавшие действие.
+func DefaultNotificationConfig() NotificationConfig {
+	return NotificationConfig{
+		MaxAttempts:  8,
+		BatchSize:    50,
+		PollInterval: time.Second,
+		Lease:        5 * time.Minute,
+		MaxBackoff:   30 * time.Minute,
+	}
+}
+
+// errUnknownKind — уведомление вида, для которого нет текста; повторять его бессмысленно.
+var errUnknownKind = errors.New("неизвестный вид уведомления")
+
+// NotificationDispatcher отправляет уведомления из очереди: определяет Telegram ID получателя,
+// повторяет отправку при временных ошибках и записывает итог доставки.
+type NotificationDispatcher struct {
+	sender telegram.Sender
+	svc    *service.NotificationService
+	texts  *i18n.Registry
+	global *Limiter
+	cfg    NotificationConfig
+}
+
+// NewNotificationDispatcher создает обработчик очереди уведомлений. texts — тексты уведомлений,
+// global — общий с рассылками ограничитель частоты отправок бота.
+func NewNotificationDispatcher(sender telegram.Sender, svc *service.NotificationService, texts *i18n.Registry, global *Limiter, cfg NotificationConfig) *NotificationDispatcher {
+	return &NotificationDispatcher{sender: sender, svc: svc, texts: texts, global: global, cfg: cfg}
+}
+
+// Run обрабатывает очередь до отмены ctx.
+func (d *NotificationDispatcher) Run(ctx context.Context) {
+	for ctx.Err() == nil {
+		if d.processBatch(ctx) == 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(d.cfg.PollInterval):
+			}
+		}
+	}
+}
+
+func (d *NotificationDispatcher) processBatch(ctx context.Context) int {
+	now := time.Now()
+	notifications, err := d.svc.ClaimPending(ctx, now, now.Add(d.cfg.Lease), d.cfg.BatchSize)
+	if err != nil {
+		slog.Error("Ошибка очереди уведомлений", "err", err)
+		return 0
+	}
+	// как и у рассылок, итог отправки записывается и после остановки
+	store := context.WithoutCancel(ctx)
+	for i := range notifications {
+		n := &notifications[i]
+		if ctx.Err() != nil {
+			logNotificationError(n, d.svc.Retry(store, n.ID, time.Now(), n.LastError, false))
+			continue
+		}
+		d.deliver(ctx, store, n)
+	}
+	return len(notifications)
+}
+
+// deliver отправляет одно уведомление и записывает результат через store.
+func (d *NotificationDispatcher) deliver(ctx, store context.Context, n *model.Notification) {
+	recipient, err := d.svc.Recipient(ctx, n)
+	if errors.Is(err, sql.ErrNoRows) {
+		d.fail(store, n, "получатель не найден")
+		return
+	}
+	if err != nil {
+		d.retry(store, n, fmt.Errorf("получатель #%d: %w", n.UserID, err))
+		return
+	}
+	msg, err := NotificationMessage(d.texts, recipient.PreferredLanguage(), recipient.TelegramID, n)
+	if err != nil {
+		d.fail(store, n, err.Error())
+		return
+	}
+	if err := d.global.Wait(ctx); err != nil {
+		logNotificationError(n, d.svc.Retry(store, n.ID, time.Now(), n.LastError, false))
+		return
+	}
+	_, sendErr := d.sender.Send(msg)
+	switch kind, retryAfter := classify(sendErr); kind {
+	case resultSent:
+		metrics.Notifications.WithLabelValues(n.Kind, "sent").Inc()
+		logNotificationError(n, d.svc.MarkSent(store, n.ID, recipient.TelegramID))
+	case resultRateLimited:
+		d.global.Pause(retryAfter)
+		metrics.Notifications.WithLabelValues(n.Kind, "retry").Inc()
+		logNotificationError(n, d.svc.Retry(store, n.ID, time.Now().Add(retryAfter), sendErr.Error(), false))
+	case resultBlocked:
+		metrics.Notifications.WithLabelValues(n.Kind, "blocked").Inc()
+		logNotificationError(n, d.svc.MarkBlocked(store, n, sendErr.Error()))
+	case resultPermanent:
+		d.fail(store, n, sendErr.Error())
+	default:
+		d.retry(store, n, sendErr)
+	}
+}
+
+// retry откладывает уведомление с экспоненциальной паузой или завершает его, если попытки исчерпаны.
+func (d *NotificationDispatcher) retry(ctx context.Context, n *model.Notification, cause error) {
+	if n.Attempts+1 >= d.cfg.MaxAttempts {
+		d.fail(ctx, n, cause.Error())
+		return
+	}
+	backoff := time.Duration(1<<n.Attempts) * 5 * time.Second
+	if backoff > d.cfg.MaxBackoff {
+		backoff = d.cfg.MaxBackoff
+	}
+	metrics.Notifications.WithLabelValues(n.Kind, "retry").Inc()
+	logNotificationError(n, d.svc.Retry(ctx, n.ID, time.Now().Add(backoff), cause.Error(), true))
+}
+
+func (d *NotificationDispatcher) fail(ctx context.Context, n *model.Notification, reason string) {
+	slog.Warn("Уведомление не доставлено", "notification_id", n.ID, "kind", n.Kind, "user_id", n.UserID, "reason", reason)
+	metrics.Notifications.WithLabelValues(n.Kind, "failed").Inc()
+	logNotificationError(n, d.svc.MarkFailed(ctx, n.ID, reason))
+}
+
+// logNotificationError логирует ошибку обновления статуса уведомления.
+func logNotificationError(n *model.Notification, err error) {
+	if err != nil {
+		slog.Error("Ошибка обновления уведомления", "notification_id", n.ID, "kind", n.Kind, "err", err)
+	}
+}
+
+// NotificationMessage формирует текст уведомления для получателя chatID на языке locale.
+func NotificationMessage(texts *i18n.Registry, locale string, chatID int64, n *model.Notification) (tgbotapi.Chattable, error) {
+	p := n.Params
+	var msg tgbotapi.MessageConfig
+	switch n.Kind {
+	case model.NotifyBookingCreated:
+		msg = tgbotapi.NewMessage(chatID, texts.Text(locale, "notify.booking_created",
+			"booking_id", p["booking_id"], "location", p["location"], "from", p["from"], "details", p["details"]))
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(texts.Text(locale, "booking.confirm"), "CONFIRM_"+p["booking_id"]),
+			tgbotapi.NewInlineKeyboardButtonData(texts.Text(locale, "booking.reject"), "REJECT_"+p["booking_id"]),
+		))
+	case model.NotifyBookingConfirmed, model.NotifyBookingRejected:
+		key := "notify.booking_confirmed"
+		if n.Kind == model.NotifyBookingRejected {
+			key = "notify.booking_rejected"
+		}
+		msg = tgbotapi.NewMessage(chatID, texts.Text(locale, key, "booking_id", p["booking_id"], "location", p["location"]))
+	case model.NotifyReviewPublished:
+		rating, _ := strconv.Atoi(p["rating"])
+		text := texts.Text(locale, "notify.review_published", "location", p["location"], "rating", rating, "text", p["text"])
+		msg = tgbotapi.NewMessage(chatID, strings.TrimSpace(text))
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(texts.Text(locale, "review.reply_button"), "REVIEW_REPLY_"+p["review_id"]),
+		))
+	case model.NotifyReviewReply:
+		msg = tgbotapi.NewMessage(chatID, texts.Text(locale, "notify.review_reply", "location", p["location"], "reply", p["reply"]))
+	case model.NotifyTranslationApproved, model.NotifyTranslationRejected:
+		key := "notify.translation_approved"
+		if n.Kind == model.NotifyTranslationRejected {
+			key = "notify.translation_rejected"
+		}
+		msg = tgbotapi.NewMessage(chatID, texts.Text(locale, key, "original", p["original"],
+			"language", texts.Text(p["locale"], "language.name"), "name", p["name"], "reason", p["reason"]))
+	case model.NotifyProviderApproved:
+		// новая роль действует сразу, вместе с уведомлением приходит меню провайдера
+		msg = tgbotapi.NewMessage(chatID, texts.Text(locale, "notify.provider_approved",
+			"business_name", p["business_name"], "locations", p["locations"]))
+		msg.ReplyMarkup = bot.MenuKeyboard(texts, locale, "provider")
+	case model.NotifyProviderRejected:
+		msg = tgbotapi.NewMessage(chatID, texts.Text(locale, "notify.provider_rejected",
+			"business_name", p["business_name"], "reason", p["reason"]))
+	case model.NotifyContentApproved, model.NotifyContentRejected:
+		key := "notify.content_approved"
+		if n.Kind == model.NotifyContentRejected {
+			key = "notify.content_rejected"
+		}
+		msg = tgbotapi.NewMessage(chatID, texts.Text(locale, key, "entity", texts.Text(locale, "content.entity."+p["entity"]),
+			"name", p["name"], "reason", p["reason"]))
+	case model.NotifyRoleChanged:
+		// как и при одобрении заявки провайдера, вместе с уведомлением приходит меню новой роли
+		msg = tgbotapi.NewMessage(chatID, texts.Text(locale, "notify.role_changed",
+			"role", texts.Text(locale, "role."+p["role"])))
+		msg.ReplyMarkup = bot.MenuKeyboard(texts, locale, p["role"])
+	default:
+		return nil, fmt.Errorf("%w: %q", errUnknownKind, n.Kind)
+	}
+	return msg, nil
+}
@@ -0,0 +1,245 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"tourism/internal/i18n"
+	"tourism/internal/model"
+	"tourism/internal/service"
+	"tourism/internal/telegram"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Config задает параметры доставки рассылок.
+type Config struct {
+	PerChatInterval time.Duration // минимальный интервал между сообщениями в один чат
+	MaxAttempts     int           // число попыток при временных ошибках
+	BatchSize       int           // сколько доставок забирать из очереди за раз
+	PollInterval    time.Duration // пауза при пустой очереди
+	Lease           time.Duration // время, на которое доставка резервируется за экземпляром
+}
+
+// DefaultConfig возвращает параметры доставки по умолчанию.
+func DefaultConfig() Config {
+	return Config{
+		PerChatInterval: time.Second,
+		MaxAttempts:     5,
+		BatchSize:       50,
+		PollInterval:    2 * time.Second,
+		Lease:           5 * time.Minute,
+	}
+}
+
+// Worker отправляет запланированные рассылки из очереди доставки с соблюдением лимитов Telegram.
+type Worker struct {
+	sender    telegram.Sender
+	svc       *service.BroadcastService
+	texts     *i18n.Registry
+	cfg       Config
+	global    *Limiter
+	chats     map[int64]time.Time // чат -> время, раньше которого в него нельзя писать
+	campaigns map[int]*model.Campaign
+}
+
+// NewWorker создает обработчик очереди рассылок. global ограничивает общую частоту отправок бота,
+// texts — тексты отчетов о доставке авторам.
+func NewWorker(sender telegram.Sender, svc *service.BroadcastService, texts *i18n.Registry, global *Limiter, cfg Config) *Worker {
+	return &Worker{
+		sender:    sender,
+		svc:       svc,
+		texts:     texts,
+		cfg:       cfg,
+		global:    global,
+		chats:     make(map[int64]time.Time),
+		campaigns: make(map[int]*model.Campaign),
+	}
+}
+
+// Run обрабатывает очередь до отмены ctx.
+func (w *Worker) Run(ctx context.Context) {
+	for ctx.Err() == nil {
+		if claimed, err := w.svc.ClaimDue(ctx, time.Now()); err != nil {
+			slog.Error("Ошибка планировщика рассылок", "err", err)
+		} else {
+			for _, c := range claimed {
+				slog.Info("Рассылка поставлена в очередь доставки", "campaign_id", c.ID)
+			}
+		}
+
+		processed := w.processBatch(ctx)
+		if ctx.Err() == nil {
+			w.completeFinished(ctx)
+		}
+
+		if processed == 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(w.cfg.PollInterval):
+			}
+		}
+	}
+}
+
+func (w *Worker) processBatch(ctx context.Context) int {
+	now := time.Now()
+	deliveries, err := w.svc.ClaimDeliveries(ctx, now, now.Add(w.cfg.Lease), w.cfg.BatchSize)
+	if err != nil {
+		slog.Error("Ошибка очереди рассылок", "err", err)
+		return 0
+	}
+	// результаты доставок записываются и после остановки, иначе уже отправленное сообщение
+	// уйдет повторно после истечения аренды; время записи ограничено таймаутом запросов
+	store := context.WithoutCancel(ctx)
+	for i := range deliveries {
+		d := &deliveries[i]
+		if ctx.Err() != nil {
+			// возвращаем неотправленные доставки в очередь, не дожидаясь истечения аренды
+			logDeliveryError(d, w.svc.RetryDelivery(store, d.ID, time.Now(), d.LastError, false))
+			continue
+		}
+		c, err := w.campaign(ctx, d.CampaignID)
+		if err != nil {
+			logDeliveryError(d, w.svc.MarkFailed(store, d.ID, err.Error()))
+			continue
+		}
+		if next, ok := w.chats[d.TelegramID]; ok && next.After(time.Now()) {
+			logDeliveryError(d, w.svc.RetryDelivery(store, d.ID, next, d.LastError, false))
+			continue
+		}
+		if err := w.global.Wait(ctx); err != nil {
+			logDeliveryError(d, w.svc.RetryDelivery(store, d.ID, time.Now(), d.LastError, false))
+			continue
+		}
+		_, err = w.sender.Send(CampaignMessage(d.TelegramID, c))
+		w.chats[d.TelegramID] = time.Now().Add(w.cfg.PerChatInterval)
+		w.handleResult(store, d, err)
+	}
+	w.forgetIdleChats()
+	return len(deliveries)
+}
+
+func (w *Worker) handleResult(ctx context.Context, d *model.CampaignDelivery, sendErr error) {
+	var err error
+	switch kind, retryAfter := classify(sendErr); kind {
+	case resultSent:
+		err = w.svc.MarkDelivered(ctx, d.ID)
+	case resultRateLimited:
+		// лимит Telegram: приостанавливаем все отправки и не считаем попытку неудачной
+		w.global.Pause(retryAfter)
+		err = w.svc.RetryDelivery(ctx, d.ID, time.Now().Add(retryAfter), sendErr.Error(), false)
+	case resultBlocked:
+		err = w.svc.MarkBlocked(ctx, d, sendErr.Error())
+	case resultPermanent:
+		err = w.svc.MarkFailed(ctx, d.ID, sendErr.Error())
+	default:
+		if d.Attempts+1 >= w.cfg.MaxAttempts {
+			err = w.svc.MarkFailed(ctx, d.ID, sendErr.Error())
+		} else {
+			backoff := time.Duration(1<<d.Attempts) * 5 * time.Second
+			err = w.svc.RetryDelivery(ctx, d.ID, time.Now().Add(backoff), sendErr.Error(), true)
+		}
+	}
+	logDeliveryError(d, err)
+}
+
+// logDeliveryError логирует ошибку обновления статуса доставки.
+func logDeliveryError(d *model.CampaignDelivery, err error) {
+	if err != nil {
+		slog.Error("Ошибка обновления доставки", "delivery_id", d.ID, "campaign_id", d.CampaignID, "err", err)
+	}
+}
+
+func (w *Worker) campaign(ctx context.Context, id int) (*model.Campaign, error) {
+	if c, ok := w.campaigns[id]; ok {
+		return c, nil
+	}
+	c, err := w.svc.GetCampaign(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("рассылка #%d не найдена: %w", id, err)
+	}
+	w.campaigns[id] = c
+	return c, nil
+}
+
+// completeFinished завершает обработанные рассылки и отправляет авторам отчет о доставке.
+func (w *Worker) completeFinished(ctx context.Context) {
+	ids, err := w.svc.CompleteFinished(ctx)
+	if err != nil {
+		slog.Error("Ошибка завершения рассылок", "err", err)
+		return
+	}
+	for _, id := range ids {
+		delete(w.campaigns, id)
+		stats, err := w.svc.Stats(ctx, id)
+		if err != nil {
+			slog.Error("Ошибка статистики рассылки", "campaign_id", id, "err", err)
+			continue
+		}
+		slog.Info("Рассылка завершена", "campaign_id", id,
+			"sent", stats.Sent, "failed", stats.Failed, "blocked", stats.Blocked)
+		author, err := w.svc.Author(ctx, id)
+		if err != nil {
+			slog.Error("Не найден автор рассылки", "campaign_id", id, "err", err)
+			continue
+		}
+		locale := w.texts.Resolve(author.PreferredLanguage())
+		report := w.texts.Text(locale, "broadcast.finished", "id", id, "stats", FormatStats(w.texts, locale, stats))
+		if _, err := w.sender.Send(tgbotapi.NewMessage(author.TelegramID, report)); err != nil {
+			slog.Warn("Не удалось отправить отчет о рассылке", "campaign_id", id, "chat_id", author.TelegramID, "err", err)
+		}
+	}
+}
+
+func (w *Worker) forgetIdleChats() {
+	now := time.Now()
+	for chatID, next := range w.chats {
+		if next.Before(now) {
+			delete(w.chats, chatID)
+		}
+	}
+}
+
+type sendResult int
+
+const (
+	resultSent sendResult = iota
+	resultRateLimited
+	resultBlocked
+	resultPermanent
+	resultTransient
+)
+
+// classify определяет, как обработать результат отправки.
+func classify(err error) (sendResult, time.Duration) {
+	if err == nil {
+		return resultSent, 0
+	}
+	var apiErr *tgbotapi.Error
+	if !errors.As(err, &apiErr) {
+		return resultTransient, 0
+	}
+	if apiErr.Code == 429 || apiErr.RetryAfter > 0 {
+		retryAfter := time.Duration(apiErr.RetryAfter) * time.Second
+		if retryAfter <= 0 {
+			retryAfter = time.Second
+		}
+		return resultRateLimited, retryAfter
+	}
+	msg := strings.ToLower(apiErr.Message)
+	switch {
+	case apiErr.Code == 403,
+		strings.Contains(msg, "bot was blocked"),
+		strings.Contains(msg, "user is deactivated"),
+		strings.Contains(msg, "chat not found"):
+		return resultBlocked, 0
+	case apiErr.Code == 400:
+		return resultPermanent, 0
+	}
+	return resultTransient, 0
+}
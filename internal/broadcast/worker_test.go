@@ -0,0 +1,364 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"tourism/internal/i18n"
+	"tourism/internal/model"
+	"tourism/internal/repository/memory"
+	"tourism/internal/service"
+	"tourism/internal/telegram/telegramtest"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestClassify(t *testing.T) {
+	apiErr := func(code int, msg string, retryAfter int) error {
+		return &tgbotapi.Error{Code: code, Message: msg, ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: retryAfter}}
+	}
+	tests := []struct {
+		name       string
+		err        error
+		want       sendResult
+		retryAfter time.Duration
+	}{
+		{"успех", nil, resultSent, 0},
+		{"сетевая ошибка", errors.New("connection reset by peer"), resultTransient, 0},
+		{"429 с retry_after", apiErr(429, "Too Many Requests: retry after 7", 7), resultRateLimited, 7 * time.Second},
+		{"429 без retry_after", apiErr(429, "Too Many Requests", 0), resultRateLimited, time.Second},
+		{"retry_after без 429", apiErr(400, "Flood control exceeded", 3), resultRateLimited, 3 * time.Second},
+		{"обернутая 429", fmt.Errorf("отправка: %w", apiErr(429, "Too Many Requests", 2)), resultRateLimited, 2 * time.Second},
+		{"403 бот заблокирован", apiErr(403, "Forbidden: bot was blocked by the user", 0), resultBlocked, 0},
+		{"403 пользователь удален", apiErr(403, "Forbidden: user is deactivated", 0), resultBlocked, 0},
+		{"400 чат не найден", apiErr(400, "Bad Request: chat not found", 0), resultBlocked, 0},
+		{"400 некорректный запрос", apiErr(400, "Bad Request: message text is empty", 0), resultPermanent, 0},
+		{"500", apiErr(500, "Internal Server Error", 0), resultTransient, 0},
+		{"502", apiErr(502, "Bad Gateway", 0), resultTransient, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, retryAfter := classify(tt.err)
+			if got != tt.want || retryAfter != tt.retryAfter {
+				t.Errorf("classify(%v) = %v, %v; ожидалось %v, %v", tt.err, got, retryAfter, tt.want, tt.retryAfter)
+			}
+		})
+	}
+}
+
+// retry — вызов DeliveryStore.Retry.
+type retry struct {
+	id           int
+	at           time.Time
+	reason       string
+	countAttempt bool
+}
+
+// recordingDeliveries — очередь доставки в памяти, которая запоминает отложенные доставки.
+type recordingDeliveries struct {
+	*memory.DeliveryRepository
+	mu      sync.Mutex
+	retries []retry
+}
+
+func (r *recordingDeliveries) Retry(ctx context.Context, id int, at time.Time, reason string, countAttempt bool) error {
+	r.mu.Lock()
+	r.retries = append(r.retries, retry{id: id, at: at, reason: reason, countAttempt: countAttempt})
+	r.mu.Unlock()
+	return r.DeliveryRepository.Retry(ctx, id, at, reason, countAttempt)
+}
+
+func (r *recordingDeliveries) takeRetries() []retry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	retries := r.retries
+	r.retries = nil
+	return retries
+}
+
+// workerEnv — Worker поверх хранилищ в памяти, отправляющий в поддельный Bot API.
+type workerEnv struct {
+	t          *testing.T
+	server     *telegramtest.Server
+	users      *memory.UserRepository
+	subs       *memory.SubscriptionRepository
+	deliveries *recordingDeliveries
+	svc        *service.BroadcastService
+	limiter    *Limiter
+	worker     *Worker
+	author     *model.User
+}
+
+func newWorkerEnv(t *testing.T, cfg Config) *workerEnv {
+	s := memory.NewStore()
+	users := memory.NewUserRepository(s)
+	subs := memory.NewSubscriptionRepository(s)
+	deliveries := &recordingDeliveries{DeliveryRepository: memory.NewDeliveryRepository(s)}
+	svc := service.NewBroadcastService(s, memory.NewCampaignRepository(s), deliveries, subs, users)
+	texts, err := i18n.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := telegramtest.NewServer("123:test")
+	t.Cleanup(server.Close)
+	api, err := server.NewBot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	limiter := NewLimiter(1000)
+	e := &workerEnv{t: t, server: server, users: users, subs: subs, deliveries: deliveries, svc: svc, limiter: limiter,
+		worker: NewWorker(api, svc, texts, limiter, cfg)}
+	e.author = e.newUser(1)
+	return e
+}
+
+func (e *workerEnv) newUser(telegramID int64) *model.User {
+	e.t.Helper()
+	u := &model.User{TelegramID: telegramID, FirstName: "Тест", Role: "user", Language: "ru", IsActive: true, CreatedAt: time.Now()}
+	id, err := e.users.Create(context.Background(), u)
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	u.ID = id
+	return u
+}
+
+// subscriber создает подписанного на рассылки пользователя.
+func (e *workerEnv) subscriber(telegramID int64) *model.User {
+	e.t.Helper()
+	u := e.newUser(telegramID)
+	if err := e.subs.Subscribe(context.Background(), u.ID); err != nil {
+		e.t.Fatal(err)
+	}
+	return u
+}
+
+// campaign ставит рассылку с текстом text в очередь доставки всем подписчикам.
+func (e *workerEnv) campaign(text string) {
+	e.t.Helper()
+	ctx := context.Background()
+	c, err := e.svc.CreateDraft(ctx, e.author.ID, text, "")
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	if err := e.svc.SendNow(ctx, c.ID); err != nil {
+		e.t.Fatal(err)
+	}
+	if _, err := e.svc.ClaimDue(ctx, time.Now()); err != nil {
+		e.t.Fatal(err)
+	}
+}
+
+// pending забирает все ожидающие доставки, в том числе отложенные на будущее.
+func (e *workerEnv) pending() []model.CampaignDelivery {
+	e.t.Helper()
+	far := time.Now().Add(24 * time.Hour)
+	ds, err := e.svc.ClaimDeliveries(context.Background(), far, far, 100)
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	return ds
+}
+
+// stats возвращает счетчики доставки первой рассылки.
+func (e *workerEnv) stats() *model.DeliveryStats {
+	e.t.Helper()
+	stats, err := e.svc.Stats(context.Background(), 1)
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	return stats
+}
+
+func (e *workerEnv) sent() int {
+	n := 0
+	for _, c := range e.server.Calls() {
+		if c.Method == "sendMessage" {
+			n++
+		}
+	}
+	return n
+}
+
+// near проверяет, что got отстоит от want не больше чем на секунду.
+func near(got, want time.Time) bool {
+	d := got.Sub(want)
+	return d > -time.Second && d < time.Second
+}
+
+func TestWorkerDelivers(t *testing.T) {
+	e := newWorkerEnv(t, DefaultConfig())
+	a, b := e.subscriber(101), e.subscriber(102)
+	e.campaign("Скидки в Дигории")
+
+	if n := e.worker.processBatch(context.Background()); n != 2 {
+		t.Fatalf("обработано %d доставок, ожидалось 2", n)
+	}
+	for _, u := range []*model.User{a, b} {
+		if calls := e.server.CallsTo(u.TelegramID); len(calls) != 1 || calls[0].Text != "Скидки в Дигории" {
+			t.Errorf("получателю %d отправлено %+v", u.TelegramID, calls)
+		}
+	}
+	if stats := e.stats(); stats.Sent != 2 || stats.Pending != 0 {
+		t.Errorf("статистика %+v", stats)
+	}
+}
+
+func TestWorkerRateLimited(t *testing.T) {
+	e := newWorkerEnv(t, DefaultConfig())
+	e.subscriber(101)
+	e.campaign("Скидки")
+	e.server.Fail("sendMessage", 429, "Too Many Requests: retry after 30", 30)
+
+	start := time.Now()
+	e.worker.processBatch(context.Background())
+	retries := e.deliveries.takeRetries()
+	if len(retries) != 1 || retries[0].countAttempt || !near(retries[0].at, start.Add(30*time.Second)) {
+		t.Fatalf("доставка отложена %+v, ожидалась одна повторная попытка через 30s без учета попытки", retries)
+	}
+	// лимит Telegram общий для бота: следующая отправка ждет окончания паузы
+	e.limiter.mu.Lock()
+	next := e.limiter.next
+	e.limiter.mu.Unlock()
+	if !near(next, start.Add(30*time.Second)) {
+		t.Errorf("ограничитель приостановлен до %v, ожидалось %v", next, start.Add(30*time.Second))
+	}
+	ds := e.pending()
+	if len(ds) != 1 || ds[0].Attempts != 0 {
+		t.Errorf("доставка после 429: %+v, ожидалась ожидающая без попыток", ds)
+	}
+}
+
+func TestWorkerBlocked(t *testing.T) {
+	e := newWorkerEnv(t, DefaultConfig())
+	u := e.subscriber(101)
+	e.campaign("Скидки")
+	e.server.Fail("sendMessage", 403, "Forbidden: bot was blocked by the user", 0)
+
+	e.worker.processBatch(context.Background())
+	if stats := e.stats(); stats.Blocked != 1 || stats.Pending != 0 {
+		t.Errorf("статистика %+v, ожидалась одна заблокированная доставка", stats)
+	}
+	got, err := e.users.GetByID(context.Background(), u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.IsActive {
+		t.Error("заблокировавший бота пользователь остался активным")
+	}
+	if retries := e.deliveries.takeRetries(); len(retries) != 0 {
+		t.Errorf("заблокированная доставка отложена: %+v", retries)
+	}
+}
+
+func TestWorkerPermanentError(t *testing.T) {
+	e := newWorkerEnv(t, DefaultConfig())
+	e.subscriber(101)
+	e.campaign("Скидки")
+	e.server.Fail("sendMessage", 400, "Bad Request: message is too long", 0)
+
+	e.worker.processBatch(context.Background())
+	if stats := e.stats(); stats.Failed != 1 || stats.Pending != 0 {
+		t.Errorf("статистика %+v, ожидалась одна неудачная доставка", stats)
+	}
+	if retries := e.deliveries.takeRetries(); len(retries) != 0 {
+		t.Errorf("доставка с ошибкой 400 отложена: %+v", retries)
+	}
+}
+
+func TestWorkerTransientBackoff(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MaxAttempts = 4
+	e := newWorkerEnv(t, cfg)
+	e.subscriber(101)
+	e.campaign("Скидки")
+	ctx := context.Background()
+	sendErr := &tgbotapi.Error{Code: 502, Message: "Bad Gateway"}
+
+	for attempt := 0; attempt < cfg.MaxAttempts-1; attempt++ {
+		ds := e.pending()
+		if len(ds) != 1 || ds[0].Attempts != attempt {
+			t.Fatalf("попытка %d: доставки %+v", attempt, ds)
+		}
+		start := time.Now()
+		e.worker.handleResult(ctx, &ds[0], sendErr)
+		retries := e.deliveries.takeRetries()
+		want := start.Add(time.Duration(1<<attempt) * 5 * time.Second)
+		if len(retries) != 1 || !retries[0].countAttempt || !near(retries[0].at, want) {
+			t.Fatalf("попытка %d: отложено %+v, ожидалось на %v с учетом попытки", attempt, retries, want)
+		}
+	}
+	// последняя попытка завершает доставку ошибкой
+	ds := e.pending()
+	if len(ds) != 1 || ds[0].Attempts != cfg.MaxAttempts-1 {
+		t.Fatalf("перед последней попыткой: %+v", ds)
+	}
+	e.worker.handleResult(ctx, &ds[0], sendErr)
+	if retries := e.deliveries.takeRetries(); len(retries) != 0 {
+		t.Errorf("после MaxAttempts доставка отложена: %+v", retries)
+	}
+	if stats := e.stats(); stats.Failed != 1 || stats.Pending != 0 {
+		t.Errorf("статистика %+v, ожидалась одна неудачная доставка", stats)
+	}
+}
+
+func TestWorkerPerChatInterval(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.PerChatInterval = time.Hour
+	e := newWorkerEnv(t, cfg)
+	u := e.subscriber(101)
+	e.campaign("Первая")
+	e.campaign("Вторая")
+
+	start := time.Now()
+	e.worker.processBatch(context.Background())
+	if calls := e.server.CallsTo(u.TelegramID); len(calls) != 1 || calls[0].Text != "Первая" {
+		t.Fatalf("в чат отправлено %+v, ожидалось одно сообщение", calls)
+	}
+	retries := e.deliveries.takeRetries()
+	if len(retries) != 1 || retries[0].countAttempt || !near(retries[0].at, start.Add(time.Hour)) {
+		t.Fatalf("вторая доставка отложена %+v, ожидалось через PerChatInterval без учета попытки", retries)
+	}
+	// до истечения интервала очередь не отдает вторую доставку
+	e.worker.processBatch(context.Background())
+	if n := e.sent(); n != 1 {
+		t.Errorf("отправлено %d сообщений, ожидалось 1", n)
+	}
+}
+
+func TestWorkerReturnsLeasesOnShutdown(t *testing.T) {
+	e := newWorkerEnv(t, DefaultConfig())
+	for id := int64(101); id <= 103; id++ {
+		e.subscriber(id)
+	}
+	e.campaign("Скидки")
+	// отправка ждет ограничителя, пока бот не остановится
+	e.limiter.Pause(time.Hour)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if n := e.worker.processBatch(ctx); n != 3 {
+		t.Fatalf("обработано %d доставок, ожидалось 3", n)
+	}
+	if n := e.sent(); n != 0 {
+		t.Errorf("после остановки отправлено %d сообщений", n)
+	}
+	// доставки сразу возвращаются в очередь, а не ждут истечения аренды
+	now := time.Now()
+	ds, err := e.svc.ClaimDeliveries(context.Background(), now, now.Add(time.Minute), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ds) != 3 {
+		t.Fatalf("в очереди %d доставок, ожидалось 3", len(ds))
+	}
+	for _, d := range ds {
+		if d.Attempts != 0 {
+			t.Errorf("доставка %d: попыток %d, остановка не должна считаться попыткой", d.ID, d.Attempts)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+// Package config загружает настройки API и ботов: значения по умолчанию, затем необязательный
+// файл YAML или TOML (переменная CONFIG_FILE), затем переменные окружения, которые имеют приоритет.
+// Ошибки проверки собираются все сразу, а секреты не попадают в логи.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// App — приложение, для которого загружается конфигурация; от него зависит, какие поля обязательны.
+type App string
+
+const (
+	AppAPI        App = "api"
+	AppBot        App = "bot"
+	AppSupportBot App = "support_bot"
+	AppTool       App = "tool" // служебные команды, которым нужна только база данных
+)
+
+// Config — настройки всех приложений проекта.
+type Config struct {
+	DB         DB         `yaml:"db" toml:"db"`
+	API        API        `yaml:"api" toml:"api"`
+	Telegram   Telegram   `yaml:"telegram" toml:"telegram"`
+	Bot        Bot        `yaml:"bot" toml:"bot"`
+	SupportBot SupportBot `yaml:"support_bot" toml:"support_bot"`
+	Broadcast  Broadcast  `yaml:"broadcast" toml:"broadcast"`
+	Storage    Storage    `yaml:"storage" toml:"storage"`
+	Maps       Maps       `yaml:"maps" toml:"maps"`
+	Features   Features   `yaml:"features" toml:"features"`
+	Log        Log        `yaml:"log" toml:"log"`
+}
+
+// DB — подключение к PostgreSQL и пул соединений.
+type DB struct {
+	Host            string   `yaml:"host" toml:"host" env:"DB_HOST"`
+	Port            int      `yaml:"port" toml:"port" env:"DB_PORT"`
+	User            string   `yaml:"user" toml:"user" env:"DB_USER"`
+	Password        Secret   `yaml:"password" toml:"password" env:"DB_PASS"`
+	Name            string   `yaml:"name" toml:"name" env:"DB_NAME"`
+	SSLMode         string   `yaml:"sslmode" toml:"sslmode" env:"DB_SSLMODE"`
+	ConnectTimeout  Duration `yaml:"connect_timeout" toml:"connect_timeout" env:"DB_CONNECT_TIMEOUT"`
+	MaxOpenConns    int      `yaml:"max_open_conns" toml:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns    int      `yaml:"max_idle_conns" toml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	ConnMaxLifetime Duration `yaml:"conn_max_lifetime" toml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`
+	// QueryTimeout ограничивает каждый запрос репозиториев, даже если у контекста нет срока; 0 — без ограничения.
+	QueryTimeout Duration `yaml:"query_timeout" toml:"query_timeout" env:"DB_QUERY_TIMEOUT"`
+}
+
+// API — HTTP-сервер REST API.
+type API struct {
+	Port         int      `yaml:"port" toml:"port" env:"API_PORT"`
+	ReadTimeout  Duration `yaml:"read_timeout" toml:"read_timeout" env:"API_READ_TIMEOUT"`
+	WriteTimeout Duration `yaml:"write_timeout" toml:"write_timeout" env:"API_WRITE_TIMEOUT"`
+	// ShutdownTimeout — сколько ждать завершения начатых запросов при остановке.
+	ShutdownTimeout Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout" env:"API_SHUTDOWN_TIMEOUT"`
+	// AuthMaxAge — срок действия данных запуска Mini App, которыми клиент подтверждает пользователя.
+	AuthMaxAge Duration `yaml:"auth_max_age" toml:"auth_max_age" env:"API_AUTH_MAX_AGE"`
+}
+
+// Telegram — общие настройки подключения к Bot API.
+type Telegram struct {
+	// APIEndpoint — шаблон адреса методов вида "http://host/bot%s/%s"; пустой — api.telegram.org.
+	APIEndpoint string `yaml:"api_endpoint" toml:"api_endpoint" env:"TELEGRAM_API_ENDPOINT"`
+}
+
+// Webhook — получение обновлений через вебхук; без URL бот работает через long polling.
+type Webhook struct {
+	URL            string `yaml:"url" toml:"url" env:"WEBHOOK_URL"`
+	Path           string `yaml:"path" toml:"path" env:"WEBHOOK_PATH"`
+	Listen         string `yaml:"listen" toml:"listen" env:"WEBHOOK_LISTEN"`
+	Secret         Secret `yaml:"secret" toml:"secret" env:"WEBHOOK_SECRET"`
+	MaxConnections int    `yaml:"max_connections" toml:"max_connections" env:"WEBHOOK_MAX_CONNECTIONS"`
+}
+
+// Updates — параллельная обработка обновлений.
+type Updates struct {
+	Workers         int      `yaml:"workers" toml:"workers" env:"WORKERS"`
+	QueueSize       int      `yaml:"queue_size" toml:"queue_size" env:"QUEUE_SIZE"`
+	ShutdownTimeout Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+}
+
+// Bot — основной бот. Переменные окружения вебхука и обработки обновлений имеют префикс BOT_
+// (BOT_WEBHOOK_URL, BOT_WORKERS и т.д.).
+type Bot struct {
+	Token           Secret   `yaml:"token" toml:"token" env:"BOT_TOKEN"`
+	SupportUsername string   `yaml:"support_username" toml:"support_username" env:"SUPPORT_BOT_USERNAME"`
+	StateTTL        Duration `yaml:"state_ttl" toml:"state_ttl" env:"BOT_STATE_TTL"`
+	MetricsListen   string   `yaml:"metrics_listen" toml:"metrics_listen" env:"BOT_METRICS_LISTEN"` // адрес /metrics и /health/*; пусто — отключено
+	Webhook         Webhook  `yaml:"webhook" toml:"webhook" env:"BOT_"`
+	Updates         Updates  `yaml:"updates" toml:"updates" env:"BOT_"`
+}
+
+// SupportBot — бот поддержки. Переменные окружения вебхука и обработки обновлений имеют
+// префикс SUPPORT_BOT_.
+type SupportBot struct {
+	Token            Secret   `yaml:"token" toml:"token" env:"SUPPORT_BOT_TOKEN"`
+	ReminderInterval Duration `yaml:"reminder_interval" toml:"reminder_interval" env:"SUPPORT_BOT_REMINDER_INTERVAL"`
+	MetricsListen    string   `yaml:"metrics_listen" toml:"metrics_listen" env:"SUPPORT_BOT_METRICS_LISTEN"`
+	Webhook          Webhook  `yaml:"webhook" toml:"webhook" env:"SUPPORT_BOT_"`
+	Updates          Updates  `yaml:"updates" toml:"updates" env:"SUPPORT_BOT_"`
+}
+
+// Log — структурированные логи.
+type Log struct {
+	Level  string `yaml:"level" toml:"level" env:"LOG_LEVEL"`    // debug, info, warn или error
+	Format string `yaml:"format" toml:"format" env:"LOG_FORMAT"` // json или text
+}
+
+// Broadcast — рассылки и дайджесты.
+type Broadcast struct {
+	Rate int `yaml:"rate" toml:"rate" env:"BROADCAST_RATE"` // общий лимит сообщений в секунду
+}
+
+// Storage — хранилище фото локаций: каталог на диске или бакет S3. Переменные окружения S3
+// имеют префикс STORAGE_S3_ (STORAGE_S3_BUCKET и т.д.).
+type Storage struct {
+	Backend string    `yaml:"backend" toml:"backend" env:"STORAGE_BACKEND"` // local или s3
+	Dir     string    `yaml:"dir" toml:"dir" env:"STORAGE_DIR"`             // каталог для backend local
+	S3      StorageS3 `yaml:"s3" toml:"s3" env:"STORAGE_S3_"`
+}
+
+// StorageS3 — подключение к S3-совместимому хранилищу.
+type StorageS3 struct {
+	Endpoint  string `yaml:"endpoint" toml:"endpoint" env:"ENDPOINT"`
+	Region    string `yaml:"region" toml:"region" env:"REGION"`
+	Bucket    string `yaml:"bucket" toml:"bucket" env:"BUCKET"`
+	AccessKey string `yaml:"access_key" toml:"access_key" env:"ACCESS_KEY"`
+	SecretKey Secret `yaml:"secret_key" toml:"secret_key" env:"SECRET_KEY"`
+	PathStyle bool   `yaml:"path_style" toml:"path_style" env:"PATH_STYLE"` // бакет в пути адреса, как у MinIO
+}
+
+// Maps — статические карты локаций и маршрутов. Без каталога тайлов карты рисуются
+// по векторной подложке.
+type Maps struct {
+	TileDir     string `yaml:"tile_dir" toml:"tile_dir" env:"MAP_TILE_DIR"`          // кэш тайлов {z}/{x}/{y}.png
+	Attribution string `yaml:"attribution" toml:"attribution" env:"MAP_ATTRIBUTION"` // подпись источника тайлов
+	Basemap     string `yaml:"basemap" toml:"basemap" env:"MAP_BASEMAP"`             // файл GeoJSON; пусто — встроенная подложка
+}
+
+// Features — переключатели фоновых и необязательных функций.
+type Features struct {
+	Broadcasts         bool `yaml:"broadcasts" toml:"broadcasts" env:"FEATURE_BROADCASTS"`                            // доставка рассылок
+	Digests            bool `yaml:"digests" toml:"digests" env:"FEATURE_DIGESTS"`                                     // еженедельные дайджесты
+	ModerationReminder bool `yaml:"moderation_reminder" toml:"moderation_reminder" env:"FEATURE_MODERATION_REMINDER"` // напоминания операторам об очереди модерации
+	Notifications      bool `yaml:"notifications" toml:"notifications" env:"FEATURE_NOTIFICATIONS"`                   // отправка уведомлений о бронированиях и отзывах
+	ContentModeration  bool `yaml:"content_moderation" toml:"content_moderation" env:"FEATURE_CONTENT_MODERATION"`    // проверка поддержкой изменений каталога от провайдеров
+	PhotoStorage       bool `yaml:"photo_storage" toml:"photo_storage" env:"FEATURE_PHOTO_STORAGE"`                   // перенос фото локаций из Telegram в хранилище
+}
+
+// Default возвращает конфигурацию по умолчанию.
+func Default() Config {
+	updates := Updates{Workers: 8, QueueSize: 64, ShutdownTimeout: Duration(15 * time.Second)}
+	return Config{
+		DB: DB{
+			Host:            "localhost",
+			Port:            5432,
+			SSLMode:         "disable",
+			ConnectTimeout:  Duration(5 * time.Second),
+			MaxOpenConns:    20,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: Duration(30 * time.Minute),
+			QueryTimeout:    Duration(10 * time.Second),
+		},
+		API: API{
+			Port:            8080,
+			ReadTimeout:     Duration(10 * time.Second),
+			WriteTimeout:    Duration(30 * time.Second),
+			ShutdownTimeout: Duration(15 * time.Second),
+			AuthMaxAge:      Duration(24 * time.Hour),
+		},
+		Bot: Bot{
+			StateTTL:      Duration(30 * time.Minute),
+			MetricsListen: ":9090",
+			Webhook:       Webhook{Listen: ":8443"},
+			Updates:       updates,
+		},
+		SupportBot: SupportBot{
+			ReminderInterval: Duration(5 * time.Minute),
+			MetricsListen:    ":9090",
+			Webhook:          Webhook{Listen: ":8443"},
+			Updates:          updates,
+		},
+		Broadcast: Broadcast{Rate: 25},
+		Storage:   Storage{Backend: "local", Dir: "data/photos", S3: StorageS3{Region: "us-east-1"}},
+		Maps:      Maps{Attribution: "© OpenStreetMap contributors"},
+		Features:  Features{Broadcasts: true, Digests: true, ModerationReminder: true, Notifications: true, ContentModeration: true, PhotoStorage: true},
+		Log:       Log{Level: "info", Format: "json"},
+	}
+}
+
+// Load собирает конфигурацию приложения app из значений по умолчанию, файла CONFIG_FILE
+// (если задан) и переменных окружения и проверяет ее.
+func Load(app App) (*Config, error) {
+	cfg := Default()
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	problems := cfg.loadEnv(os.LookupEnv)
+	problems = append(problems, cfg.validate(app)...)
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	return &cfg, nil
+}
+
+// loadFile читает файл YAML (.yaml, .yml) или TOML (.toml) поверх текущих значений.
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("не удалось прочитать файл конфигурации: %w", err)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("ошибка в файле конфигурации %s: %w", path, err)
+		}
+	case ".toml":
+		dec := toml.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(c); err != nil {
+			return fmt.Errorf("ошибка в файле конфигурации %s: %w", path, err)
+		}
+	default:
+		return fmt.Errorf("неизвестный формат файла конфигурации %s: ожидается .yaml, .yml или .toml", path)
+	}
+	return nil
+}
+
+// String возвращает конфигурацию в JSON для логов; секреты заменены на "***".
+func (c Config) String() string {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Sprintf("<конфигурация: %v>", err)
+	}
+	return string(data)
+}
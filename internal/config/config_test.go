@@ -0,0 +1,374 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// writeFile создает файл конфигурации name с содержимым data во временном каталоге.
+func writeFile(t *testing.T, name, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// lookupMap возвращает функцию поиска переменных окружения по env.
+func lookupMap(env map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+}
+
+const yamlConfig = `
+db:
+  host: db.internal
+  port: 6432
+  user: tourism
+  password: file-password
+  name: tourism
+  query_timeout: 3s
+bot:
+  token: file-token
+  state_ttl: 10m
+  webhook:
+    url: https://bot.example.com
+    secret: file-secret
+  updates:
+    workers: 2
+features:
+  digests: false
+log:
+  level: debug
+`
+
+const tomlConfig = `
+[db]
+host = "db.internal"
+port = 6432
+user = "tourism"
+password = "file-password"
+name = "tourism"
+query_timeout = "3s"
+
+[bot]
+token = "file-token"
+state_ttl = "10m"
+
+[bot.webhook]
+url = "https://bot.example.com"
+secret = "file-secret"
+
+[bot.updates]
+workers = 2
+
+[features]
+digests = false
+
+[log]
+level = "debug"
+`
+
+func TestLoadPrecedence(t *testing.T) {
+	for _, tt := range []struct{ name, file, data string }{
+		{"yaml", "config.yaml", yamlConfig},
+		{"toml", "config.toml", tomlConfig},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CONFIG_FILE", writeFile(t, tt.file, tt.data))
+			// переменные окружения важнее файла, в том числе во вложенных структурах с префиксом
+			t.Setenv("DB_PORT", "5433")
+			t.Setenv("BOT_TOKEN", "env-token")
+			t.Setenv("BOT_WORKERS", "4")
+			t.Setenv("BOT_WEBHOOK_SECRET", "env-secret")
+			t.Setenv("FEATURE_DIGESTS", "true")
+
+			cfg, err := Load(AppBot)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checks := []struct {
+				field     string
+				got, want any
+			}{
+				// из окружения
+				{"DB.Port", cfg.DB.Port, 5433},
+				{"Bot.Token", cfg.Bot.Token.Value(), "env-token"},
+				{"Bot.Updates.Workers", cfg.Bot.Updates.Workers, 4},
+				{"Bot.Webhook.Secret", cfg.Bot.Webhook.Secret.Value(), "env-secret"},
+				{"Features.Digests", cfg.Features.Digests, true},
+				// из файла
+				{"DB.Host", cfg.DB.Host, "db.internal"},
+				{"DB.Password", cfg.DB.Password.Value(), "file-password"},
+				{"DB.QueryTimeout", cfg.DB.QueryTimeout.Std(), 3 * time.Second},
+				{"Bot.StateTTL", cfg.Bot.StateTTL.Std(), 10 * time.Minute},
+				{"Bot.Webhook.URL", cfg.Bot.Webhook.URL, "https://bot.example.com"},
+				{"Log.Level", cfg.Log.Level, "debug"},
+				// значения по умолчанию для полей, не заданных ни в файле, ни в окружении
+				{"DB.SSLMode", cfg.DB.SSLMode, "disable"},
+				{"Bot.Webhook.Listen", cfg.Bot.Webhook.Listen, ":8443"},
+				{"Bot.Updates.QueueSize", cfg.Bot.Updates.QueueSize, 64},
+				{"Features.Broadcasts", cfg.Features.Broadcasts, true},
+				{"Log.Format", cfg.Log.Format, "json"},
+			}
+			for _, c := range checks {
+				if c.got != c.want {
+					t.Errorf("%s = %v, ожидалось %v", c.field, c.got, c.want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadFileErrors(t *testing.T) {
+	tests := []struct {
+		name, file, data, want string
+	}{
+		{"неизвестное поле", "config.yaml", "db:\n  hots: localhost\n", "hots"},
+		{"неизвестное поле toml", "config.toml", "[db]\nhots = \"localhost\"\n", "config.toml"},
+		{"некорректная длительность", "config.yaml", "bot:\n  state_ttl: полчаса\n", "некорректная длительность"},
+		{"неизвестный формат", "config.json", "{}", "неизвестный формат"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			err := cfg.loadFile(writeFile(t, tt.file, tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ошибка %v, ожидалось упоминание %q", err, tt.want)
+			}
+		})
+	}
+	cfg := Default()
+	if err := cfg.loadFile(filepath.Join(t.TempDir(), "missing.yaml")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("отсутствующий файл: %v", err)
+	}
+}
+
+func TestLoadEnv(t *testing.T) {
+	cfg := Default()
+	problems := cfg.loadEnv(lookupMap(map[string]string{
+		"DB_HOST":                    "pg",
+		"DB_CONNECT_TIMEOUT":         "2s",
+		"SUPPORT_BOT_WEBHOOK_URL":    "https://support.example.com",
+		"SUPPORT_BOT_QUEUE_SIZE":     "16",
+		"STORAGE_S3_BUCKET":          "photos",
+		"STORAGE_S3_PATH_STYLE":      "true",
+		"FEATURE_PHOTO_STORAGE":      "false",
+		"BOT_WEBHOOK_URL":            "",                // пустое значение тоже переопределяет
+		"WEBHOOK_URL":                "https://ignored", // без префикса приложения не читается
+		"SUPPORT_BOT_STATE_TTL":      "1m",              // такого поля нет
+		"SUPPORT_BOT_METRICS_LISTEN": ":9191",
+	}))
+	if len(problems) != 0 {
+		t.Fatalf("ошибки разбора: %v", problems)
+	}
+	if cfg.DB.Host != "pg" || cfg.DB.ConnectTimeout.Std() != 2*time.Second {
+		t.Errorf("DB = %+v", cfg.DB)
+	}
+	if cfg.SupportBot.Webhook.URL != "https://support.example.com" || cfg.SupportBot.Updates.QueueSize != 16 ||
+		cfg.SupportBot.MetricsListen != ":9191" {
+		t.Errorf("SupportBot = %+v", cfg.SupportBot)
+	}
+	if cfg.Bot.Webhook.URL != "" || cfg.Bot.Updates.QueueSize != 64 {
+		t.Errorf("Bot = %+v", cfg.Bot)
+	}
+	if cfg.Storage.S3.Bucket != "photos" || !cfg.Storage.S3.PathStyle || cfg.Features.PhotoStorage {
+		t.Errorf("Storage = %+v, PhotoStorage = %v", cfg.Storage, cfg.Features.PhotoStorage)
+	}
+
+	// ошибки разбора перечисляются все, с именами переменных
+	cfg = Default()
+	problems = cfg.loadEnv(lookupMap(map[string]string{
+		"DB_PORT":             "пять",
+		"BOT_STATE_TTL":       "10",
+		"FEATURE_DIGESTS":     "да",
+		"SUPPORT_BOT_WORKERS": "4",
+	}))
+	for _, name := range []string{"DB_PORT", "BOT_STATE_TTL", "FEATURE_DIGESTS"} {
+		if !slices.ContainsFunc(problems, func(p string) bool { return strings.HasPrefix(p, name+":") }) {
+			t.Errorf("нет ошибки %s среди %v", name, problems)
+		}
+	}
+	if len(problems) != 3 {
+		t.Errorf("ошибки разбора: %v", problems)
+	}
+}
+
+// validConfig возвращает конфигурацию, которая проходит проверку для всех приложений.
+func validConfig() Config {
+	cfg := Default()
+	cfg.DB.User = "tourism"
+	cfg.DB.Name = "tourism"
+	cfg.Bot.Token = "123:bot"
+	cfg.SupportBot.Token = "456:support"
+	return cfg
+}
+
+func TestValidate(t *testing.T) {
+	for _, app := range []App{AppAPI, AppBot, AppSupportBot, AppTool} {
+		cfg := validConfig()
+		if problems := cfg.validate(app); len(problems) != 0 {
+			t.Errorf("%s: корректная конфигурация не прошла проверку: %v", app, problems)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		app    App
+		modify func(c *Config)
+		want   []string // фрагменты ожидаемых ошибок, все сразу
+	}{
+		{"база данных", AppTool, func(c *Config) {
+			c.DB.Host, c.DB.Port, c.DB.User, c.DB.Name, c.DB.SSLMode = "", 70000, "", "", "on"
+			c.DB.MaxOpenConns, c.DB.MaxIdleConns = 0, 5
+			c.DB.QueryTimeout = Duration(-time.Second)
+		}, []string{"DB_HOST", "DB_PORT", "DB_USER", "DB_NAME", "DB_SSLMODE", "DB_MAX_OPEN_CONNS", "DB_MAX_IDLE_CONNS", "отрицательными"}},
+		{"логи", AppTool, func(c *Config) {
+			c.Log.Level, c.Log.Format = "verbose", "xml"
+		}, []string{"LOG_LEVEL", "LOG_FORMAT"}},
+		{"API", AppAPI, func(c *Config) {
+			c.API.Port, c.API.ReadTimeout, c.API.AuthMaxAge = 0, 0, 0
+			c.Bot.Token, c.SupportBot.Token = "", ""
+			c.Storage.Backend = "ftp"
+		}, []string{"API_PORT", "таймауты API", "BOT_TOKEN или SUPPORT_BOT_TOKEN", "API_AUTH_MAX_AGE", "STORAGE_BACKEND"}},
+		{"бот", AppBot, func(c *Config) {
+			c.DB.User = ""
+			c.Bot.Token, c.Bot.StateTTL, c.Broadcast.Rate = "", 0, 0
+			c.Bot.Updates = Updates{}
+			c.Bot.Webhook = Webhook{URL: "http://bot.example.com", Listen: ":8443"}
+			c.Storage = Storage{Backend: "s3"}
+		}, []string{"DB_USER", "BOT_TOKEN", "BOT_STATE_TTL", "BROADCAST_RATE", "BOT_WORKERS", "BOT_QUEUE_SIZE",
+			"BOT_SHUTDOWN_TIMEOUT", "BOT_WEBHOOK_*", "STORAGE_S3_BUCKET", "STORAGE_S3_SECRET_KEY"}},
+		{"бот поддержки", AppSupportBot, func(c *Config) {
+			c.SupportBot.Token, c.SupportBot.ReminderInterval = "", 0
+			c.SupportBot.Updates.Workers = 0
+			c.SupportBot.Webhook.URL = "https://support.example.com"
+			c.SupportBot.Webhook.Secret = "с пробелом"
+		}, []string{"SUPPORT_BOT_TOKEN", "SUPPORT_BOT_REMINDER_INTERVAL", "SUPPORT_BOT_WORKERS", "SUPPORT_BOT_WEBHOOK_*"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.modify(&cfg)
+			problems := cfg.validate(tt.app)
+			for _, want := range tt.want {
+				if !slices.ContainsFunc(problems, func(p string) bool { return strings.Contains(p, want) }) {
+					t.Errorf("нет ошибки про %s среди:\n%s", want, strings.Join(problems, "\n"))
+				}
+			}
+		})
+	}
+}
+
+func TestLoadReportsAllProblems(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("DB_USER", "")
+	t.Setenv("DB_NAME", "")
+	t.Setenv("DB_PORT", "пять")
+	t.Setenv("BOT_TOKEN", "")
+	t.Setenv("BOT_WORKERS", "0")
+	t.Setenv("LOG_FORMAT", "xml")
+
+	_, err := Load(AppBot)
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("ошибка %v, ожидалась *ValidationError", err)
+	}
+	// ошибки разбора окружения и проверки собраны в одну ошибку
+	for _, want := range []string{"DB_PORT:", "DB_USER", "DB_NAME", "BOT_TOKEN", "BOT_WORKERS", "LOG_FORMAT"} {
+		if !slices.ContainsFunc(verr.Problems, func(p string) bool { return strings.Contains(p, want) }) {
+			t.Errorf("нет ошибки про %s среди %v", want, verr.Problems)
+		}
+	}
+	if lines := strings.Count(err.Error(), "\n  - "); lines != len(verr.Problems) {
+		t.Errorf("в тексте ошибки %d пунктов из %d:\n%s", lines, len(verr.Problems), err)
+	}
+}
+
+func TestSecretRedacted(t *testing.T) {
+	var empty Secret
+	s := Secret("p@ssw0rd")
+	if s.Value() != "p@ssw0rd" {
+		t.Errorf("Value() = %q", s.Value())
+	}
+	text, err := s.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, got := range []string{s.String(), string(text), fmt.Sprint(s), fmt.Sprintf("%v", s), fmt.Sprintf("%s", s)} {
+		if got != "***" {
+			t.Errorf("секрет выведен как %q", got)
+		}
+	}
+	if got := fmt.Sprintf("%#v", s); strings.Contains(got, "p@ssw0rd") {
+		t.Errorf("%%#v выводит секрет: %s", got)
+	}
+	if empty.String() != "" {
+		t.Errorf("пустой секрет выведен как %q", empty.String())
+	}
+}
+
+func TestConfigDoesNotLeakSecrets(t *testing.T) {
+	cfg := validConfig()
+	cfg.DB.Password = "db-pass-1"
+	cfg.Bot.Token = "bot-token-2"
+	cfg.SupportBot.Token = "support-token-3"
+	cfg.Bot.Webhook.Secret = "webhook-secret-4"
+	cfg.SupportBot.Webhook.Secret = "webhook-secret-5"
+	cfg.Storage.S3.SecretKey = "s3-secret-6"
+	secrets := []string{"db-pass-1", "bot-token-2", "support-token-3", "webhook-secret-4", "webhook-secret-5", "s3-secret-6"}
+
+	var jsonLog, textLog bytes.Buffer
+	slog.New(slog.NewJSONHandler(&jsonLog, nil)).Info("Конфигурация загружена", "config", &cfg)
+	slog.New(slog.NewTextHandler(&textLog, nil)).Info("Конфигурация загружена", "config", &cfg)
+	asJSON, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asYAML, err := yaml.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputs := map[string]string{
+		"String":    cfg.String(),
+		"%v":        fmt.Sprintf("%v", cfg),
+		"%+v":       fmt.Sprintf("%+v", &cfg),
+		"%#v":       fmt.Sprintf("%#v", cfg),
+		"JSON":      string(asJSON),
+		"YAML":      string(asYAML),
+		"slog JSON": jsonLog.String(),
+		"slog text": textLog.String(),
+	}
+	for name, out := range outputs {
+		for _, secret := range secrets {
+			if strings.Contains(out, secret) {
+				t.Errorf("%s: секрет %q попал в вывод:\n%s", name, secret, out)
+			}
+		}
+		if !strings.Contains(out, "***") {
+			t.Errorf("%s: секреты не заменены на ***:\n%s", name, out)
+		}
+	}
+	// несекретные поля остаются в выводе
+	if !strings.Contains(cfg.String(), `"Host":"localhost"`) {
+		t.Errorf("String() = %s", cfg.String())
+	}
+	// настоящие значения доступны приложению
+	if !strings.Contains(cfg.DB.DSN(), "password='db-pass-1'") {
+		t.Errorf("DSN() = %s", cfg.DB.DSN())
+	}
+}
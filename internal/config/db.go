@@ -0,0 +1,45 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"tourism/internal/metrics"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// DSN возвращает строку подключения в формате key=value для lib/pq.
+func (c DB) DSN() string {
+	parts := []string{
+		"host=" + quote(c.Host),
+		fmt.Sprintf("port=%d", c.Port),
+		"user=" + quote(c.User),
+		"password=" + quote(c.Password.Value()),
+		"dbname=" + quote(c.Name),
+		"sslmode=" + quote(c.SSLMode),
+	}
+	if secs := int(c.ConnectTimeout.Std().Seconds()); secs > 0 {
+		parts = append(parts, fmt.Sprintf("connect_timeout=%d", secs))
+	}
+	return strings.Join(parts, " ")
+}
+
+// quote экранирует значение параметра строки подключения.
+func quote(v string) string {
+	v = strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(v)
+	return "'" + v + "'"
+}
+
+// Connect подключается к базе данных и настраивает пул соединений. Длительность запросов
+// записывается в метрики (см. metrics.DriverName).
+func (c DB) Connect() (*sqlx.DB, error) {
+	db, err := sqlx.Connect(metrics.DriverName, c.DSN())
+	if err != nil {
+		return nil, fmt.Errorf("не удалось подключиться к базе данных: %w", err)
+	}
+	db.SetMaxOpenConns(c.MaxOpenConns)
+	db.SetMaxIdleConns(c.MaxIdleConns)
+	db.SetConnMaxLifetime(c.ConnMaxLifetime.Std())
+	return db, nil
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// loadEnv заполняет поля по тегам env. У вложенной структуры тег задает префикс имен ее полей.
+// Возвращает ошибки разбора значений.
+func (c *Config) loadEnv(lookup func(string) (string, bool)) []string {
+	var problems []string
+	loadEnvStruct(reflect.ValueOf(c).Elem(), "", lookup, &problems)
+	return problems
+}
+
+var textUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+func loadEnvStruct(v reflect.Value, prefix string, lookup func(string) (string, bool), problems *[]string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field, value := t.Field(i), v.Field(i)
+		tag, ok := field.Tag.Lookup("env")
+		if field.Type.Kind() == reflect.Struct && !reflect.PointerTo(field.Type).Implements(textUnmarshaler) {
+			loadEnvStruct(value, prefix+tag, lookup, problems)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		name := prefix + tag
+		raw, ok := lookup(name)
+		if !ok {
+			continue
+		}
+		if err := setValue(value, raw); err != nil {
+			*problems = append(*problems, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+}
+
+// setValue разбирает строку raw в поле v.
+func setValue(v reflect.Value, raw string) error {
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(raw))
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("ожидается целое число, получено %q", raw)
+		}
+		v.SetInt(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("ожидается true или false, получено %q", raw)
+		}
+		v.SetBool(b)
+	default:
+		return fmt.Errorf("неподдерживаемый тип поля %s", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package config
+
+import (
+	"tourism/internal/staticmap"
+)
+
+// Renderer создает отрисовщик карт с тайлами из MAP_TILE_DIR и подложкой из MAP_BASEMAP.
+func (m Maps) Renderer() (*staticmap.Renderer, error) {
+	cfg := staticmap.DefaultConfig()
+	cfg.TileDir = m.TileDir
+	cfg.Attribution = m.Attribution
+	var basemap *staticmap.Basemap
+	if m.Basemap != "" {
+		b, err := staticmap.LoadBasemap(m.Basemap)
+		if err != nil {
+			return nil, err
+		}
+		basemap = b
+	}
+	return staticmap.NewRenderer(cfg, basemap)
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"tourism/internal/blob"
+)
+
+// Open открывает хранилище фото, выбранное в STORAGE_BACKEND.
+func (s Storage) Open() (blob.Store, error) {
+	if s.Backend == "s3" {
+		store, err := blob.NewS3(blob.S3Config{
+			Endpoint:  s.S3.Endpoint,
+			Region:    s.S3.Region,
+			Bucket:    s.S3.Bucket,
+			AccessKey: s.S3.AccessKey,
+			SecretKey: s.S3.SecretKey.Value(),
+			PathStyle: s.S3.PathStyle,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	}
+	store, err := blob.NewLocal(s.Dir)
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Secret — значение, которое нельзя выводить в логи (пароли, токены). При выводе через fmt,
+// JSON или YAML заменяется на "***"; настоящее значение возвращает Value.
+type Secret string
+
+const redacted = "***"
+
+// Value возвращает значение секрета.
+func (s Secret) Value() string {
+	return string(s)
+}
+
+// String скрывает значение секрета; пустой секрет выводится как пустая строка.
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+// GoString скрывает значение секрета при выводе через %#v.
+func (s Secret) GoString() string {
+	return fmt.Sprintf("config.Secret(%q)", s.String())
+}
+
+// MarshalText скрывает значение секрета при сериализации.
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText читает секрет из файла конфигурации.
+func (s *Secret) UnmarshalText(text []byte) error {
+	*s = Secret(text)
+	return nil
+}
+
+// Duration — длительность, которая в файлах и переменных окружения записывается как "30s", "5m", "1h30m".
+type Duration time.Duration
+
+// Std возвращает значение как time.Duration.
+func (d Duration) Std() time.Duration {
+	return time.Duration(d)
+}
+
+// String реализует fmt.Stringer.
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+// MarshalText реализует encoding.TextMarshaler.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText реализует encoding.TextUnmarshaler.
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(strings.TrimSpace(string(text)))
+	if err != nil {
+		return fmt.Errorf("некорректная длительность %q (ожидается, например, 30s, 5m, 1h)", text)
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// ValidationError перечисляет все ошибки конфигурации.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "некорректная конфигурация:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
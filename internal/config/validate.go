@@ -0,0 +1,154 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"tourism/internal/telegram"
+)
+
+// validate возвращает все ошибки конфигурации приложения app.
+func (c *Config) validate(app App) []string {
+	var problems []string
+	add := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	db := c.DB
+	if db.Host == "" {
+		add("не задан адрес базы данных (DB_HOST)")
+	}
+	if db.Port <= 0 || db.Port > 65535 {
+		add("некорректный порт базы данных %d (DB_PORT)", db.Port)
+	}
+	if db.User == "" {
+		add("не задан пользователь базы данных (DB_USER)")
+	}
+	if db.Name == "" {
+		add("не задано имя базы данных (DB_NAME)")
+	}
+	switch db.SSLMode {
+	case "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+	default:
+		add("некорректный режим SSL базы данных %q (DB_SSLMODE)", db.SSLMode)
+	}
+	if db.MaxOpenConns <= 0 {
+		add("DB_MAX_OPEN_CONNS должно быть больше нуля")
+	}
+	if db.MaxIdleConns < 0 || db.MaxIdleConns > db.MaxOpenConns {
+		add("DB_MAX_IDLE_CONNS должно быть от 0 до DB_MAX_OPEN_CONNS")
+	}
+	if db.ConnectTimeout < 0 || db.ConnMaxLifetime < 0 || db.QueryTimeout < 0 {
+		add("таймауты базы данных не могут быть отрицательными")
+	}
+
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(c.Log.Level)); err != nil {
+		add("некорректный уровень логирования %q (LOG_LEVEL): ожидается debug, info, warn или error", c.Log.Level)
+	}
+	if f := strings.ToLower(c.Log.Format); f != "json" && f != "text" {
+		add("некорректный формат логов %q (LOG_FORMAT): ожидается json или text", c.Log.Format)
+	}
+
+	switch app {
+	case AppAPI:
+		if c.API.Port <= 0 || c.API.Port > 65535 {
+			add("некорректный порт API %d (API_PORT)", c.API.Port)
+		}
+		if c.API.ReadTimeout <= 0 || c.API.WriteTimeout <= 0 || c.API.ShutdownTimeout <= 0 {
+			add("таймауты API должны быть больше нуля")
+		}
+		if c.Bot.Token == "" && c.SupportBot.Token == "" {
+			add("не задан токен бота (BOT_TOKEN или SUPPORT_BOT_TOKEN): им проверяется подпись пользователей API")
+		}
+		if c.API.AuthMaxAge <= 0 {
+			add("API_AUTH_MAX_AGE должно быть больше нуля")
+		}
+		problems = append(problems, validateStorage(c.Storage)...)
+	case AppBot:
+		if c.Bot.Token == "" {
+			add("не задан токен бота (BOT_TOKEN)")
+		}
+		if c.Bot.StateTTL <= 0 {
+			add("BOT_STATE_TTL должно быть больше нуля")
+		}
+		if c.Broadcast.Rate <= 0 {
+			add("BROADCAST_RATE должно быть больше нуля")
+		}
+		problems = append(problems, validateStorage(c.Storage)...)
+		problems = append(problems, validateWebhook("BOT_", c.Bot.Webhook)...)
+		problems = append(problems, validateUpdates("BOT_", c.Bot.Updates)...)
+	case AppSupportBot:
+		if c.SupportBot.Token == "" {
+			add("не задан токен бота поддержки (SUPPORT_BOT_TOKEN)")
+		}
+		if c.SupportBot.ReminderInterval <= 0 {
+			add("SUPPORT_BOT_REMINDER_INTERVAL должно быть больше нуля")
+		}
+		problems = append(problems, validateWebhook("SUPPORT_BOT_", c.SupportBot.Webhook)...)
+		problems = append(problems, validateUpdates("SUPPORT_BOT_", c.SupportBot.Updates)...)
+	}
+	return problems
+}
+
+func validateWebhook(prefix string, w Webhook) []string {
+	if w.URL == "" {
+		return nil
+	}
+	err := w.Telegram().Validate()
+	if err == nil {
+		return nil
+	}
+	var problems []string
+	for _, line := range strings.Split(err.Error(), "\n") {
+		problems = append(problems, fmt.Sprintf("%sWEBHOOK_*: %s", prefix, line))
+	}
+	return problems
+}
+
+func validateStorage(s Storage) []string {
+	switch s.Backend {
+	case "local":
+		if s.Dir == "" {
+			return []string{"не задан каталог хранилища фото (STORAGE_DIR)"}
+		}
+	case "s3":
+		var problems []string
+		if s.S3.Endpoint == "" || s.S3.Bucket == "" || s.S3.Region == "" {
+			problems = append(problems, "для хранилища s3 нужны STORAGE_S3_ENDPOINT, STORAGE_S3_BUCKET и STORAGE_S3_REGION")
+		}
+		if s.S3.AccessKey == "" || s.S3.SecretKey == "" {
+			problems = append(problems, "для хранилища s3 нужны STORAGE_S3_ACCESS_KEY и STORAGE_S3_SECRET_KEY")
+		}
+		return problems
+	default:
+		return []string{fmt.Sprintf("неизвестное хранилище фото %q (STORAGE_BACKEND): ожидается local или s3", s.Backend)}
+	}
+	return nil
+}
+
+func validateUpdates(prefix string, u Updates) []string {
+	var problems []string
+	if u.Workers <= 0 {
+		problems = append(problems, prefix+"WORKERS должно быть больше нуля")
+	}
+	if u.QueueSize <= 0 {
+		problems = append(problems, prefix+"QUEUE_SIZE должно быть больше нуля")
+	}
+	if u.ShutdownTimeout <= 0 {
+		problems = append(problems, prefix+"SHUTDOWN_TIMEOUT должно быть больше нуля")
+	}
+	return problems
+}
+
+// Telegram возвращает настройки вебхука в виде, который принимает пакет telegram.
+func (w Webhook) Telegram() telegram.WebhookConfig {
+	return telegram.WebhookConfig{
+		URL:            w.URL,
+		Path:           w.Path,
+		Listen:         w.Listen,
+		SecretToken:    w.Secret.Value(),
+		MaxConnections: w.MaxConnections,
+	}
+}
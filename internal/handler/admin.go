@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"tourism/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// adminRequest описывает тело запросов администратора — параметры действия. Кто выполняет
+// действие, определяет Auth.
+type adminRequest struct {
+	Role   string `json:"role"`
+	Reason string `json:"reason"`
+}
+
+// SearchUsers обработчик для GET /api/admin/users?q= - ищет пользователей по ID, Telegram ID,
+// username или имени. Поддерживает параметр limit (по умолчанию 20, не более 100).
+func (h *Handler) SearchUsers(c *gin.Context) {
+	limit, ok := limitQuery(c)
+	if !ok {
+		return
+	}
+	users, err := h.Admin.Search(c.Request.Context(), currentUser(c).ID, c.Query("q"), limit)
+	if err != nil {
+		adminError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, users)
+}
+
+// GetUser обработчик для GET /api/admin/users/:id - возвращает профиль пользователя.
+func (h *Handler) GetUser(c *gin.Context) {
+	userID, ok := userParam(c)
+	if !ok {
+		return
+	}
+	user, err := h.Admin.User(c.Request.Context(), currentUser(c).ID, userID)
+	if err != nil {
+		adminError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// ListUserBookings обработчик для GET /api/admin/users/:id/bookings - возвращает последние брони пользователя.
+func (h *Handler) ListUserBookings(c *gin.Context) {
+	userID, ok := userParam(c)
+	if !ok {
+		return
+	}
+	limit, ok := limitQuery(c)
+	if !ok {
+		return
+	}
+	bookings, err := h.Admin.Bookings(c.Request.Context(), currentUser(c).ID, userID, limit)
+	if err != nil {
+		adminError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, bookings)
+}
+
+// ListUserTrips обработчик для GET /api/admin/users/:id/trips - возвращает последние маршруты пользователя.
+func (h *Handler) ListUserTrips(c *gin.Context) {
+	userID, ok := userParam(c)
+	if !ok {
+		return
+	}
+	limit, ok := limitQuery(c)
+	if !ok {
+		return
+	}
+	trips, err := h.Admin.Trips(c.Request.Context(), currentUser(c).ID, userID, limit)
+	if err != nil {
+		adminError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, trips)
+}
+
+// ListUserTickets обработчик для GET /api/admin/users/:id/tickets - возвращает последние сообщения
+// переписки пользователя с поддержкой.
+func (h *Handler) ListUserTickets(c *gin.Context) {
+	userID, ok := userParam(c)
+	if !ok {
+		return
+	}
+	limit, ok := limitQuery(c)
+	if !ok {
+		return
+	}
+	messages, err := h.Admin.Tickets(c.Request.Context(), currentUser(c).ID, userID, limit)
+	if err != nil {
+		adminError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, messages)
+}
+
+// SetUserRole обработчик для PUT /api/admin/users/:id/role - меняет роль пользователя.
+func (h *Handler) SetUserRole(c *gin.Context) {
+	userID, req, ok := adminBody(c)
+	if !ok {
+		return
+	}
+	user, err := h.Admin.SetRole(c.Request.Context(), currentUser(c).ID, userID, req.Role)
+	if err != nil {
+		adminError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// BlockUser обработчик для POST /api/admin/users/:id/block - блокирует пользователя.
+func (h *Handler) BlockUser(c *gin.Context) {
+	userID, req, ok := adminBody(c)
+	if !ok {
+		return
+	}
+	user, err := h.Admin.Block(c.Request.Context(), currentUser(c).ID, userID, req.Reason)
+	if err != nil {
+		adminError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// UnblockUser обработчик для POST /api/admin/users/:id/unblock - снимает блокировку пользователя.
+func (h *Handler) UnblockUser(c *gin.Context) {
+	userID, ok := userParam(c)
+	if !ok {
+		return
+	}
+	user, err := h.Admin.Unblock(c.Request.Context(), currentUser(c).ID, userID)
+	if err != nil {
+		adminError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// ListAudit обработчик для GET /api/admin/audit?user_id= - возвращает последние записи журнала
+// действий администраторов, с user_id — только о действиях с этим пользователем.
+func (h *Handler) ListAudit(c *gin.Context) {
+	limit, ok := limitQuery(c)
+	if !ok {
+		return
+	}
+	userID, err := strconv.Atoi(c.DefaultQuery("user_id", "0"))
+	if err != nil || userID < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный ID пользователя"})
+		return
+	}
+	entries, err := h.Admin.AuditLog(c.Request.Context(), currentUser(c).ID, userID, limit)
+	if err != nil {
+		adminError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+// limitQuery читает параметр limit (по умолчанию 20, не более 100) запросов на чтение.
+func limitQuery(c *gin.Context) (int, bool) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный параметр limit"})
+		return 0, false
+	}
+	return limit, true
+}
+
+// adminBody читает ID пользователя из пути и тело запроса администратора.
+func adminBody(c *gin.Context) (int, adminRequest, bool) {
+	var req adminRequest
+	userID, ok := userParam(c)
+	if !ok {
+		return 0, req, false
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректное тело запроса"})
+		return 0, req, false
+	}
+	return userID, req, true
+}
+
+func userParam(c *gin.Context) (int, bool) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный ID пользователя"})
+		return 0, false
+	}
+	return userID, true
+}
+
+// adminError отвечает 403, если действие выполняет не администратор, 404 для неизвестного
+// пользователя и 400 с текстом ошибки в остальных случаях.
+func adminError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrNotAdmin):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, sql.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Пользователь не найден"})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
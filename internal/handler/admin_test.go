@@ -0,0 +1,51 @@
+package handler_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"tourism/internal/telegram"
+	"tourism/internal/telegram/telegramtest"
+)
+
+func TestAdminRejectsForgedAdminID(t *testing.T) {
+	api := newTestAPI(t)
+	admin := api.newUser(1001, "admin")
+	user := api.newUser(1002, "user")
+	target := api.newUser(1003, "user")
+	path := fmt.Sprintf("/api/admin/users/%d/role", target.ID)
+	// admin_id из тела запроса больше ничего не значит: действует тот, кто подписал запрос
+	body := map[string]any{"admin_id": admin.ID, "role": "admin"}
+
+	if rec := api.do(http.MethodPut, path, "", body); rec.Code != http.StatusUnauthorized {
+		t.Errorf("без авторизации: код %d, ожидался 401", rec.Code)
+	}
+	forged := "tma " + telegramtest.InitData("999:other", telegram.WebAppUser{ID: admin.TelegramID}, time.Now())
+	if rec := api.do(http.MethodPut, path, forged, body); rec.Code != http.StatusUnauthorized {
+		t.Errorf("подпись чужим токеном: код %d, ожидался 401", rec.Code)
+	}
+	stale := "tma " + telegramtest.InitData(botToken, telegram.WebAppUser{ID: admin.TelegramID}, time.Now().Add(-2*time.Hour))
+	if rec := api.do(http.MethodPut, path, stale, body); rec.Code != http.StatusUnauthorized {
+		t.Errorf("устаревшие данные: код %d, ожидался 401", rec.Code)
+	}
+	if rec := api.do(http.MethodPut, path, authAs(user, botToken), body); rec.Code != http.StatusForbidden {
+		t.Errorf("не администратор с чужим admin_id: код %d, ожидался 403", rec.Code)
+	}
+	got, err := api.users.GetByID(context.Background(), target.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Role != "user" {
+		t.Fatalf("роль изменена запросом без прав: %q", got.Role)
+	}
+
+	if rec := api.do(http.MethodPut, path, authAs(admin, botToken), map[string]any{"role": "provider"}); rec.Code != http.StatusOK {
+		t.Fatalf("администратор: код %d, ответ %s", rec.Code, rec.Body)
+	}
+	if got, _ := api.users.GetByID(context.Background(), target.ID); got.Role != "provider" {
+		t.Errorf("роль после запроса администратора %q, ожидалась provider", got.Role)
+	}
+}
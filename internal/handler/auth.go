@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"tourism/internal/model"
+	"tourism/internal/service"
+	"tourism/internal/telegram"
+
+	"github.com/gin-gonic/gin"
+)
+
+// authScheme — схема заголовка Authorization: клиент (Mini App бота) передает в нем
+// Telegram.WebApp.initData как есть: "Authorization: tma <initData>".
+const authScheme = "tma "
+
+// userKey — ключ пользователя запроса в контексте gin.
+const userKey = "user"
+
+// Auth пропускает только запросы пользователей ботов: подпись данных запуска Mini App проверяется
+// токенами ботов tokens, а пользователь ищется по Telegram ID. Найденный пользователь доступен
+// обработчикам через currentUser; роли и права проверяют сервисы по данным из базы.
+func Auth(users *service.UserService, tokens []string, maxAge time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		if !strings.HasPrefix(header, authScheme) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Требуется авторизация"})
+			return
+		}
+		tgUser, err := telegram.ValidateInitData(strings.TrimPrefix(header, authScheme), tokens, maxAge, time.Now())
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		user, err := users.GetByTelegramID(c.Request.Context(), tgUser.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Пользователь не найден: начните диалог с ботом"})
+			return
+		}
+		if err != nil {
+			internalError(c, "Не удалось получить пользователя", err)
+			c.Abort()
+			return
+		}
+		if user.IsBlocked {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Пользователь заблокирован"})
+			return
+		}
+		c.Set(userKey, user)
+		c.Next()
+	}
+}
+
+// currentUser возвращает пользователя, подтвержденного Auth.
+func currentUser(c *gin.Context) *model.User {
+	return c.MustGet(userKey).(*model.User)
+}
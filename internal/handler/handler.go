@@ -1,8 +1,14 @@
 package handler
 
 import (
+	"database/sql"
+	"errors"
+	"log/slog"
 	"net/http"
+	"strconv"
 
+	"tourism/internal/i18n"
+	"tourism/internal/model"
 	"tourism/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -16,11 +22,19 @@ type Handler struct {
 	BookingService  *service.BookingService
 	ChatService     *service.ChatService
 	OfferService    *service.OfferService
+	ReviewService   *service.ReviewService
+	Translations    *service.TranslationService
+	Admin           *service.AdminService
+	Photos          *service.PhotoStorageService
+	Maps            *service.MapService
+	Texts           *i18n.Registry // локали, на которые переводится контент (Accept-Language)
 }
 
 // NewHandler создает новый Handler с внедрением зависимостей (сервисов).
 func NewHandler(us *service.UserService, ls *service.LocationService, ts *service.TripService,
-	bs *service.BookingService, cs *service.ChatService, os *service.OfferService) *Handler {
+	bs *service.BookingService, cs *service.ChatService, os *service.OfferService, rs *service.ReviewService,
+	trs *service.TranslationService, as *service.AdminService, ps *service.PhotoStorageService,
+	ms *service.MapService, texts *i18n.Registry) *Handler {
 	return &Handler{
 		UserService:     us,
 		LocationService: ls,
@@ -28,21 +42,228 @@ func NewHandler(us *service.UserService, ls *service.LocationService, ts *servic
 		BookingService:  bs,
 		ChatService:     cs,
 		OfferService:    os,
+		ReviewService:   rs,
+		Translations:    trs,
+		Admin:           as,
+		Photos:          ps,
+		Maps:            ms,
+		Texts:           texts,
 	}
 }
 
 // ListLocations обработчик для GET /api/locations - возвращает список всех локаций.
+// Названия и описания переводятся на язык из заголовка Accept-Language, если есть перевод.
 func (h *Handler) ListLocations(c *gin.Context) {
-	locations, err := h.LocationService.SearchLocations("", "", 0, "")
+	locations, err := h.LocationService.SearchLocations(c.Request.Context(), "", "", 0, "")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить локации"})
+		internalError(c, "Не удалось получить локации", err)
 		return
 	}
+	locale := h.contentLocale(c)
+	if err := h.Translations.LocalizeLocations(c.Request.Context(), locale, locations); err != nil {
+		slog.WarnContext(c.Request.Context(), "Не удалось загрузить переводы локаций", "locale", locale, "err", err)
+	}
 	c.JSON(http.StatusOK, locations)
 }
 
+// ListLocationPhotos обработчик для GET /api/locations/:id/photos - возвращает галерею локации
+// в порядке показа: позицию, подпись, признак обложки и FileID каждого фото, а для фото, уже
+// сохраненных в хранилище, — размеры оригинала и адреса для GET /api/photos/:id/:size.
+func (h *Handler) ListLocationPhotos(c *gin.Context) {
+	locationID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный ID локации"})
+		return
+	}
+	loc, photos, err := h.LocationService.GetLocationDetails(c.Request.Context(), locationID)
+	if errors.Is(err, sql.ErrNoRows) || loc != nil && loc.Hidden {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Локация не найдена"})
+		return
+	}
+	if err != nil {
+		internalError(c, "Не удалось получить фото локации", err)
+		return
+	}
+	result := make([]locationPhoto, 0, len(photos))
+	for i := range photos {
+		result = append(result, locationPhoto{LocationPhoto: photos[i], URLs: photoURLs(&photos[i])})
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// ListOffers обработчик для GET /api/offers?type=housing|tour - возвращает предложения указанного типа
+// на языке из заголовка Accept-Language.
+func (h *Handler) ListOffers(c *gin.Context) {
+	offerType := c.Query("type")
+	if offerType == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Не указан тип предложений (type)"})
+		return
+	}
+	offers, err := h.OfferService.ListOffers(c.Request.Context(), offerType)
+	if err != nil {
+		internalError(c, "Не удалось получить предложения", err)
+		return
+	}
+	locale := h.contentLocale(c)
+	if err := h.Translations.LocalizeOffers(c.Request.Context(), locale, offers); err != nil {
+		slog.WarnContext(c.Request.Context(), "Не удалось загрузить переводы предложений", "locale", locale, "err", err)
+	}
+	c.JSON(http.StatusOK, offers)
+}
+
+// contentLocale выбирает язык ответа по заголовку Accept-Language и сообщает его в Content-Language.
+func (h *Handler) contentLocale(c *gin.Context) string {
+	locale := h.Texts.Negotiate(c.GetHeader("Accept-Language"))
+	c.Header("Content-Language", locale)
+	c.Header("Vary", "Accept-Language")
+	return locale
+}
+
+// ListReviews обработчик для GET /api/locations/:id/reviews - возвращает опубликованные отзывы о локации.
+// Поддерживает параметры limit (по умолчанию 20, не более 100) и offset.
+func (h *Handler) ListReviews(c *gin.Context) {
+	locationID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный ID локации"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный параметр limit"})
+		return
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный параметр offset"})
+		return
+	}
+	reviews, total, err := h.ReviewService.ListReviews(c.Request.Context(), locationID, limit, offset)
+	if err != nil {
+		internalError(c, "Не удалось получить отзывы", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"total": total, "reviews": reviews})
+}
+
 // ListUsers обработчик для GET /api/users - возвращает список всех пользователей.
 func (h *Handler) ListUsers(c *gin.Context) {
 	// Для простоты: возвращаем заглушку, реальный список пользователей не выводится
 	c.JSON(http.StatusOK, gin.H{"message": "Список пользователей недоступен в данной версии API"})
 }
+
+// subscriptionRequest описывает тело запроса PUT /api/users/:id/subscription.
+type subscriptionRequest struct {
+	Subscribed bool     `json:"subscribed"`
+	Topics     []string `json:"topics"`
+	Regions    []string `json:"regions"`
+	Frequency  string   `json:"frequency"`
+}
+
+// GetSubscription обработчик для GET /api/users/:id/subscription - возвращает настройки подписки пользователя.
+// Доступен только самому пользователю.
+func (h *Handler) GetSubscription(c *gin.Context) {
+	userID, ok := ownUserParam(c)
+	if !ok {
+		return
+	}
+	sub, err := h.OfferService.GetPreferences(c.Request.Context(), userID)
+	if err != nil {
+		internalError(c, "Не удалось получить подписку", err)
+		return
+	}
+	if sub == nil {
+		c.JSON(http.StatusOK, gin.H{"subscribed": false})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"subscribed": true,
+		"topics":     sub.Topics,
+		"regions":    sub.Regions,
+		"frequency":  sub.Frequency,
+	})
+}
+
+// UpdateSubscription обработчик для PUT /api/users/:id/subscription - сохраняет настройки подписки пользователя.
+// Доступен только самому пользователю.
+func (h *Handler) UpdateSubscription(c *gin.Context) {
+	userID, ok := ownUserParam(c)
+	if !ok {
+		return
+	}
+	var req subscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректное тело запроса"})
+		return
+	}
+	if !req.Subscribed {
+		if err := h.OfferService.Unsubscribe(c.Request.Context(), userID); err != nil {
+			internalError(c, "Не удалось отменить подписку", err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"subscribed": false})
+		return
+	}
+	if req.Frequency == "" {
+		req.Frequency = model.FrequencyInstant
+	}
+	sub := &model.OfferSubscription{UserID: userID, Topics: req.Topics, Regions: req.Regions, Frequency: req.Frequency}
+	if err := h.OfferService.UpdatePreferences(c.Request.Context(), sub); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"subscribed": true,
+		"topics":     sub.Topics,
+		"regions":    sub.Regions,
+		"frequency":  sub.Frequency,
+	})
+}
+
+// ownUserParam читает ID пользователя из пути и проверяет, что это пользователь запроса (403 иначе).
+func ownUserParam(c *gin.Context) (int, bool) {
+	userID, ok := userParam(c)
+	if !ok {
+		return 0, false
+	}
+	if userID != currentUser(c).ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Доступны только собственные настройки"})
+		return 0, false
+	}
+	return userID, true
+}
+
+// Register регистрирует маршруты /api. Маршруты, которые действуют от имени пользователя,
+// проходят через auth (см. Auth).
+func (h *Handler) Register(router *gin.Engine, auth gin.HandlerFunc) {
+	api := router.Group("/api")
+	api.GET("/locations", h.ListLocations)
+	api.GET("/locations/:id/reviews", h.ListReviews)
+	api.GET("/locations/:id/photos", h.ListLocationPhotos)
+	api.GET("/photos/:id/:size", h.GetPhoto)
+	api.GET("/offers", h.ListOffers)
+	api.GET("/users", h.ListUsers)
+
+	authed := api.Group("", auth)
+	// карта и завершение маршрута — только владельцу
+	authed.GET("/trips/:id/map.png", h.GetTripMap)
+	authed.POST("/trips/:id/complete", h.CompleteTrip)
+	// подписка: пользователь видит и меняет только свою
+	authed.GET("/users/:id/subscription", h.GetSubscription)
+	authed.PUT("/users/:id/subscription", h.UpdateSubscription)
+	// переводы: автор и проверяющий — авторизованный пользователь, права проверяет сервис по роли в базе
+	authed.POST("/locations/:id/translations", h.SubmitLocationTranslation)
+	authed.POST("/offers/:id/translations", h.SubmitOfferTranslation)
+	authed.GET("/translations", h.ListPendingTranslations)
+	authed.POST("/translations/:id/approve", h.ApproveTranslation)
+	authed.POST("/translations/:id/reject", h.RejectTranslation)
+	// управление пользователями: действие выполняет авторизованный администратор, все действия пишутся в журнал
+	authed.GET("/admin/users", h.SearchUsers)
+	authed.GET("/admin/users/:id", h.GetUser)
+	authed.GET("/admin/users/:id/bookings", h.ListUserBookings)
+	authed.GET("/admin/users/:id/trips", h.ListUserTrips)
+	authed.GET("/admin/users/:id/tickets", h.ListUserTickets)
+	authed.PUT("/admin/users/:id/role", h.SetUserRole)
+	authed.POST("/admin/users/:id/block", h.BlockUser)
+	authed.POST("/admin/users/:id/unblock", h.UnblockUser)
+	authed.GET("/admin/audit", h.ListAudit)
+}
@@ -0,0 +1,116 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"tourism/internal/handler"
+	"tourism/internal/i18n"
+	"tourism/internal/model"
+	"tourism/internal/repository/memory"
+	"tourism/internal/service"
+	"tourism/internal/staticmap"
+	"tourism/internal/telegram"
+	"tourism/internal/telegram/telegramtest"
+
+	"github.com/gin-gonic/gin"
+)
+
+// botToken — токен бота, которым подписаны данные запуска в проверках.
+const botToken = "123:test"
+
+// testAPI — API поверх хранилищ в памяти с маршрутами, как в cmd/api.
+type testAPI struct {
+	t            *testing.T
+	store        *memory.Store
+	users        *memory.UserRepository
+	locations    *memory.LocationRepository
+	translations *memory.TranslationRepository
+	trips        *memory.TripRepository
+	reviews      *service.ReviewService
+	router       *gin.Engine
+}
+
+func newTestAPI(t *testing.T) *testAPI {
+	gin.SetMode(gin.TestMode)
+	s := memory.NewStore()
+	users := memory.NewUserRepository(s)
+	bookings := memory.NewBookingRepository(s)
+	trips := memory.NewTripRepository(s)
+	messages := memory.NewMessageRepository(s)
+	notifications := memory.NewNotificationRepository(s)
+	locations := memory.NewLocationRepository(s)
+	translations := memory.NewTranslationRepository(s)
+	texts, err := i18n.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	userService := service.NewUserService(users)
+	translationService := service.NewTranslationService(s, translations, locations, memory.NewOfferRepository(s), users, notifications)
+	adminService := service.NewAdminService(s, users, bookings, trips, messages, memory.NewAuditRepository(s), notifications)
+	offerService := service.NewOfferService(memory.NewSubscriptionRepository(s), memory.NewOfferRepository(s), locations)
+	renderer, err := staticmap.NewRenderer(staticmap.DefaultConfig(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mapService := service.NewMapService(trips, renderer)
+	tripService := service.NewTripService(trips, locations)
+	reviewService := service.NewReviewService(s, memory.NewReviewRepository(s), locations, users, notifications, service.DefaultModerationRules())
+	h := handler.NewHandler(userService, nil, tripService, nil, nil, offerService, reviewService, translationService, adminService, nil, mapService, texts)
+	router := gin.New()
+	h.Register(router, handler.Auth(userService, []string{botToken}, time.Hour))
+	return &testAPI{t: t, store: s, users: users, locations: locations, translations: translations, trips: trips, reviews: reviewService, router: router}
+}
+
+// newUser создает пользователя с ролью role.
+func (a *testAPI) newUser(telegramID int64, role string) *model.User {
+	a.t.Helper()
+	u := &model.User{TelegramID: telegramID, Username: "user", FirstName: "Тест", Role: role}
+	id, err := a.users.Create(context.Background(), u)
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	u.ID = id
+	return u
+}
+
+// newLocation создает локацию провайдера providerID (nil — без провайдера).
+func (a *testAPI) newLocation(providerID *int) *model.Location {
+	a.t.Helper()
+	loc := &model.Location{Name: "Цейское ущелье", Description: "Ущелье в горах", Category: "nature",
+		Region: "Алагир", Latitude: 42.79, Longitude: 43.9, ProviderID: providerID}
+	id, err := a.locations.Create(context.Background(), loc)
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	loc.ID = id
+	return loc
+}
+
+// authAs возвращает заголовок Authorization пользователя u, подписанный токеном token.
+func authAs(u *model.User, token string) string {
+	return "tma " + telegramtest.InitData(token, telegram.WebAppUser{ID: u.TelegramID, FirstName: u.FirstName}, time.Now())
+}
+
+// do выполняет запрос с заголовком Authorization auth (пустой — без него) и телом body в JSON.
+func (a *testAPI) do(method, path, auth string, body any) *httptest.ResponseRecorder {
+	a.t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			a.t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+	rec := httptest.NewRecorder()
+	a.router.ServeHTTP(rec, req)
+	return rec
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"tourism/internal/service"
+	"tourism/internal/staticmap"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetTripMap обработчик для GET /api/trips/:id/map.png?width=&height= - отдает карту маршрута в PNG:
+// линию через точки в текущем порядке и метки с их номерами. Размеры от 100 до 1280 пикселей,
+// по умолчанию 600×400. Подпись источника тайлов, если карта нарисована по ним, передается
+// в заголовке X-Map-Attribution. Карта доступна только владельцу маршрута.
+func (h *Handler) GetTripMap(c *gin.Context) {
+	tripID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный ID маршрута"})
+		return
+	}
+	width, err1 := strconv.Atoi(c.DefaultQuery("width", strconv.Itoa(staticmap.DefaultWidth)))
+	height, err2 := strconv.Atoi(c.DefaultQuery("height", strconv.Itoa(staticmap.DefaultHeight)))
+	if err1 != nil || err2 != nil || width < staticmap.MinSize || width > staticmap.MaxSize ||
+		height < staticmap.MinSize || height > staticmap.MaxSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный размер карты"})
+		return
+	}
+	img, err := h.Maps.TripMap(c.Request.Context(), currentUser(c).ID, tripID, width, height)
+	if errors.Is(err, service.ErrNotTripOwner) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Маршрут не найден"})
+		return
+	}
+	if errors.Is(err, staticmap.ErrNoPoints) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "В маршруте нет точек с координатами"})
+		return
+	}
+	if err != nil {
+		internalError(c, "Не удалось нарисовать карту маршрута", err)
+		return
+	}
+	// маршрут меняется, поэтому карта не кэшируется
+	c.Header("Cache-Control", "no-cache")
+	if img.Attribution != "" {
+		c.Header("X-Map-Attribution", img.Attribution)
+	}
+	c.Data(http.StatusOK, "image/png", img.PNG)
+}
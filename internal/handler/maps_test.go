@@ -0,0 +1,37 @@
+package handler_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestTripMapOnlyForOwner(t *testing.T) {
+	api := newTestAPI(t)
+	owner := api.newUser(4001, "user")
+	stranger := api.newUser(4002, "user")
+	ctx := context.Background()
+	tripID, err := api.trips.Create(ctx, owner.ID, "Выходные в горах")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := api.trips.AddLocation(ctx, tripID, api.newLocation(nil).ID); err != nil {
+		t.Fatal(err)
+	}
+	path := fmt.Sprintf("/api/trips/%d/map.png?width=200&height=200", tripID)
+
+	if rec := api.do(http.MethodGet, path, "", nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("без авторизации: код %d, ожидался 401", rec.Code)
+	}
+	if rec := api.do(http.MethodGet, path, authAs(stranger, botToken), nil); rec.Code != http.StatusForbidden {
+		t.Errorf("чужой маршрут: код %d, ожидался 403", rec.Code)
+	}
+	rec := api.do(http.MethodGet, path, authAs(owner, botToken), nil)
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "image/png" {
+		t.Fatalf("свой маршрут: код %d, тип %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if rec := api.do(http.MethodGet, "/api/trips/999/map.png", authAs(owner, botToken), nil); rec.Code != http.StatusNotFound {
+		t.Errorf("несуществующий маршрут: код %d, ожидался 404", rec.Code)
+	}
+}
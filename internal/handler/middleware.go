@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"tourism/internal/logging"
+	"tourism/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader — заголовок с ID запроса. Пришедший от клиента или балансировщика ID
+// сохраняется, иначе создается новый; ответ всегда содержит этот заголовок.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestLogger присваивает запросу ID, добавляет его в контекст запроса (все записи логов
+// с этим контекстом содержат request_id) и пишет строку лога по каждому запросу.
+func RequestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" || len(id) > 128 {
+			id = logging.NewRequestID()
+		}
+		c.Header(RequestIDHeader, id)
+		ctx := logging.With(c.Request.Context(), "request_id", id)
+		c.Request = c.Request.WithContext(ctx)
+
+		start := time.Now()
+		c.Next()
+
+		attrs := []any{
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"status", c.Writer.Status(),
+			"duration_ms", time.Since(start).Milliseconds(),
+			"client_ip", c.ClientIP(),
+		}
+		switch status := c.Writer.Status(); {
+		case status >= 500:
+			slog.ErrorContext(ctx, "HTTP-запрос", attrs...)
+		case status >= 400:
+			slog.WarnContext(ctx, "HTTP-запрос", attrs...)
+		default:
+			slog.InfoContext(ctx, "HTTP-запрос", attrs...)
+		}
+	}
+}
+
+// Metrics записывает длительность запросов по шаблону маршрута (например, /api/locations/:id/reviews).
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.HTTPRequestDuration.
+			WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).
+			Observe(time.Since(start).Seconds())
+	}
+}
+
+// internalError логирует ошибку с контекстом запроса и отвечает 500 с сообщением msg.
+func internalError(c *gin.Context, msg string, err error) {
+	slog.ErrorContext(c.Request.Context(), msg, "err", err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"tourism/internal/model"
+	"tourism/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// locationPhoto — фото галереи в ответе API: для сохраненных фото добавляются адреса всех размеров.
+type locationPhoto struct {
+	model.LocationPhoto
+	URLs map[string]string `json:"urls,omitempty"`
+}
+
+// photoURLs возвращает адреса размеров сохраненного фото (original, small, medium, large).
+func photoURLs(photo *model.LocationPhoto) map[string]string {
+	if photo.StoredAt == nil {
+		return nil
+	}
+	urls := map[string]string{}
+	for _, size := range service.PhotoSizeNames() {
+		urls[size] = fmt.Sprintf("/api/photos/%d/%s", photo.ID, size)
+	}
+	return urls
+}
+
+// GetPhoto обработчик для GET /api/photos/:id/:size - отдает сохраненное фото: оригинал (original)
+// или уменьшенную копию (small, medium, large). Файл под адресом не меняется, поэтому ответ кэшируется
+// надолго; повторный запрос с If-None-Match получает 304.
+func (h *Handler) GetPhoto(c *gin.Context) {
+	photoID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный ID фото"})
+		return
+	}
+	size := c.Param("size")
+	if !slices.Contains(service.PhotoSizeNames(), size) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неизвестный размер фото"})
+		return
+	}
+	obj, photo, err := h.Photos.Open(c.Request.Context(), photoID, size)
+	if errors.Is(err, sql.ErrNoRows) || errors.Is(err, service.ErrPhotoNotStored) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Фото не найдено"})
+		return
+	}
+	if err != nil {
+		internalError(c, "Не удалось получить фото", err)
+		return
+	}
+	defer obj.Body.Close()
+
+	etag := fmt.Sprintf(`"%d-%s-%d"`, photo.ID, size, photo.StoredAt.Unix())
+	c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	c.Header("ETag", etag)
+	if !obj.ModTime.IsZero() {
+		c.Header("Last-Modified", obj.ModTime.UTC().Format(http.TimeFormat))
+	}
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.DataFromReader(http.StatusOK, obj.Size, obj.ContentType, obj.Body, nil)
+}
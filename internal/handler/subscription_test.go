@@ -0,0 +1,38 @@
+package handler_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSubscriptionOnlyForOwner(t *testing.T) {
+	api := newTestAPI(t)
+	alice := api.newUser(3001, "user")
+	bob := api.newUser(3002, "user")
+	path := func(id int) string { return fmt.Sprintf("/api/users/%d/subscription", id) }
+	body := map[string]any{"subscribed": true, "topics": []string{"tour"}, "frequency": "weekly"}
+
+	if rec := api.do(http.MethodPut, path(bob.ID), "", body); rec.Code != http.StatusUnauthorized {
+		t.Errorf("без авторизации: код %d, ожидался 401", rec.Code)
+	}
+	if rec := api.do(http.MethodPut, path(bob.ID), authAs(alice, botToken), body); rec.Code != http.StatusForbidden {
+		t.Errorf("чужая подписка: код %d, ожидался 403", rec.Code)
+	}
+	if rec := api.do(http.MethodGet, path(bob.ID), authAs(alice, botToken), nil); rec.Code != http.StatusForbidden {
+		t.Errorf("просмотр чужой подписки: код %d, ожидался 403", rec.Code)
+	}
+	rec := api.do(http.MethodGet, path(bob.ID), authAs(bob, botToken), nil)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"subscribed":false`) {
+		t.Fatalf("подписка после чужого запроса: код %d, ответ %s", rec.Code, rec.Body)
+	}
+
+	if rec := api.do(http.MethodPut, path(bob.ID), authAs(bob, botToken), body); rec.Code != http.StatusOK {
+		t.Fatalf("своя подписка: код %d, ответ %s", rec.Code, rec.Body)
+	}
+	rec = api.do(http.MethodGet, path(bob.ID), authAs(bob, botToken), nil)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"frequency":"weekly"`) {
+		t.Errorf("подписка после своего запроса: код %d, ответ %s", rec.Code, rec.Body)
+	}
+}
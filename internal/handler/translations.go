@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"tourism/internal/model"
+	"tourism/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// translationRequest описывает тело запроса POST /api/locations/:id/translations и /api/offers/:id/translations.
+// Автор перевода — пользователь, подтвержденный Auth.
+type translationRequest struct {
+	Locale      string `json:"locale"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// decisionRequest описывает тело запроса решения по переводу. Решение принимает пользователь,
+// подтвержденный Auth, если он сотрудник поддержки.
+type decisionRequest struct {
+	Reason string `json:"reason"`
+}
+
+// SubmitLocationTranslation обработчик для POST /api/locations/:id/translations - предлагает перевод локации.
+func (h *Handler) SubmitLocationTranslation(c *gin.Context) {
+	h.submitTranslation(c, model.TranslationLocation)
+}
+
+// SubmitOfferTranslation обработчик для POST /api/offers/:id/translations - предлагает перевод предложения.
+func (h *Handler) SubmitOfferTranslation(c *gin.Context) {
+	h.submitTranslation(c, model.TranslationOffer)
+}
+
+// submitTranslation сохраняет перевод записи entity. Перевод провайдера ждет проверки поддержкой,
+// перевод поддержки публикуется сразу.
+func (h *Handler) submitTranslation(c *gin.Context, entity string) {
+	entityID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный ID записи"})
+		return
+	}
+	var req translationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректное тело запроса"})
+		return
+	}
+	if !h.Texts.HasLocale(model.BaseLanguage(req.Locale)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Язык перевода не поддерживается"})
+		return
+	}
+	t, err := h.Translations.Submit(c.Request.Context(), currentUser(c).ID, entity, entityID, req.Locale, req.Name, req.Description)
+	if err != nil {
+		translationError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, t)
+}
+
+// ListPendingTranslations обработчик для GET /api/translations - возвращает переводы, ожидающие проверки.
+// Поддерживает параметр limit (по умолчанию 20, не более 100).
+func (h *Handler) ListPendingTranslations(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный параметр limit"})
+		return
+	}
+	pending, err := h.Translations.Pending(c.Request.Context(), currentUser(c).ID, limit)
+	if errors.Is(err, service.ErrNotModerator) {
+		translationError(c, err)
+		return
+	}
+	if err != nil {
+		internalError(c, "Не удалось получить очередь переводов", err)
+		return
+	}
+	c.JSON(http.StatusOK, pending)
+}
+
+// ApproveTranslation обработчик для POST /api/translations/:id/approve - публикует перевод.
+func (h *Handler) ApproveTranslation(c *gin.Context) {
+	h.decideTranslation(c, true)
+}
+
+// RejectTranslation обработчик для POST /api/translations/:id/reject - отклоняет перевод.
+func (h *Handler) RejectTranslation(c *gin.Context) {
+	h.decideTranslation(c, false)
+}
+
+func (h *Handler) decideTranslation(c *gin.Context, approve bool) {
+	translationID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный ID перевода"})
+		return
+	}
+	var req decisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректное тело запроса"})
+		return
+	}
+	var t *model.Translation
+	if approve {
+		t, err = h.Translations.Approve(c.Request.Context(), currentUser(c).ID, translationID)
+	} else {
+		t, err = h.Translations.Reject(c.Request.Context(), currentUser(c).ID, translationID, req.Reason)
+	}
+	if err != nil {
+		translationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, t)
+}
+
+// translationError отвечает 403, если у пользователя нет прав на действие с переводом,
+// и 400 с текстом ошибки в остальных случаях.
+func translationError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrNotModerator) || errors.Is(err, service.ErrTranslationForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,59 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"tourism/internal/model"
+)
+
+func TestTranslationsUseAuthenticatedUser(t *testing.T) {
+	api := newTestAPI(t)
+	provider := api.newUser(2001, "provider")
+	other := api.newUser(2002, "provider")
+	support := api.newUser(2003, "support")
+	loc := api.newLocation(&provider.ID)
+	submit := fmt.Sprintf("/api/locations/%d/translations", loc.ID)
+	body := map[string]any{"locale": "en", "name": "Tsey Gorge", "description": "A gorge"}
+
+	if rec := api.do(http.MethodPost, submit, "", body); rec.Code != http.StatusUnauthorized {
+		t.Errorf("без авторизации: код %d, ожидался 401", rec.Code)
+	}
+	// user_id из тела запроса игнорируется: чужой провайдер не может выдать себя за владельца
+	forged := map[string]any{"user_id": provider.ID, "locale": "en", "name": "Tsey Gorge"}
+	if rec := api.do(http.MethodPost, submit, authAs(other, botToken), forged); rec.Code != http.StatusForbidden {
+		t.Errorf("перевод чужой локации: код %d, ожидался 403", rec.Code)
+	}
+	rec := api.do(http.MethodPost, submit, authAs(provider, botToken), body)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("перевод провайдера: код %d, ответ %s", rec.Code, rec.Body)
+	}
+	var tr model.Translation
+	if err := json.Unmarshal(rec.Body.Bytes(), &tr); err != nil {
+		t.Fatal(err)
+	}
+	if tr.Status != model.TranslationPending || tr.AuthorID == nil || *tr.AuthorID != provider.ID {
+		t.Fatalf("перевод %+v: ожидался pending от провайдера %d", tr, provider.ID)
+	}
+
+	approve := fmt.Sprintf("/api/translations/%d/approve", tr.ID)
+	if rec := api.do(http.MethodGet, "/api/translations", authAs(provider, botToken), nil); rec.Code != http.StatusForbidden {
+		t.Errorf("очередь переводов у провайдера: код %d, ожидался 403", rec.Code)
+	}
+	if rec := api.do(http.MethodPost, approve, authAs(provider, botToken), map[string]any{"user_id": support.ID}); rec.Code != http.StatusForbidden {
+		t.Errorf("одобрение провайдером с чужим user_id: код %d, ожидался 403", rec.Code)
+	}
+	if got, _ := api.translations.GetByID(context.Background(), tr.ID); got.Status != model.TranslationPending {
+		t.Fatalf("статус перевода после запроса без прав %q", got.Status)
+	}
+	if rec := api.do(http.MethodPost, approve, authAs(support, botToken), map[string]any{}); rec.Code != http.StatusOK {
+		t.Fatalf("одобрение поддержкой: код %d, ответ %s", rec.Code, rec.Body)
+	}
+	got, _ := api.translations.GetByID(context.Background(), tr.ID)
+	if got.Status != model.TranslationApproved || got.ReviewerID == nil || *got.ReviewerID != support.ID {
+		t.Errorf("перевод после одобрения %+v, ожидался approved поддержкой %d", got, support.ID)
+	}
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"tourism/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CompleteTrip обработчик для POST /api/trips/:id/complete - завершает маршрут авторизованного владельца.
+// После этого владелец может оставить отзывы о локациях маршрута.
+func (h *Handler) CompleteTrip(c *gin.Context) {
+	tripID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный ID маршрута"})
+		return
+	}
+	trip, err := h.TripService.CompleteTrip(c.Request.Context(), currentUser(c).ID, tripID)
+	switch {
+	case errors.Is(err, service.ErrNotTripOwner):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, sql.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Маршрут не найден"})
+	case errors.Is(err, service.ErrTripEmpty), errors.Is(err, service.ErrTripCompleted):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err != nil:
+		internalError(c, "Не удалось завершить маршрут", err)
+	default:
+		c.JSON(http.StatusOK, trip)
+	}
+}
@@ -0,0 +1,71 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"tourism/internal/model"
+)
+
+func TestCompleteTripAllowsReview(t *testing.T) {
+	api := newTestAPI(t)
+	owner := api.newUser(5001, "user")
+	stranger := api.newUser(5002, "user")
+	loc := api.newLocation(nil)
+	ctx := context.Background()
+	tripID, err := api.trips.Create(ctx, owner.ID, "Выходные в горах")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := fmt.Sprintf("/api/trips/%d/complete", tripID)
+
+	if rec := api.do(http.MethodPost, path, authAs(owner, botToken), nil); rec.Code != http.StatusConflict {
+		t.Errorf("пустой маршрут: код %d, ожидался 409", rec.Code)
+	}
+	if err := api.trips.AddLocation(ctx, tripID, loc.ID); err != nil {
+		t.Fatal(err)
+	}
+	// черновик маршрута не дает права на отзыв
+	if _, err := api.reviews.CreateReview(ctx, owner.ID, loc.ID, 5, "Красиво", nil); err == nil {
+		t.Fatal("отзыв по черновику маршрута принят")
+	}
+
+	if rec := api.do(http.MethodPost, path, "", nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("без авторизации: код %d, ожидался 401", rec.Code)
+	}
+	if rec := api.do(http.MethodPost, path, authAs(stranger, botToken), nil); rec.Code != http.StatusForbidden {
+		t.Errorf("чужой маршрут: код %d, ожидался 403", rec.Code)
+	}
+	if rec := api.do(http.MethodPost, "/api/trips/999/complete", authAs(owner, botToken), nil); rec.Code != http.StatusNotFound {
+		t.Errorf("несуществующий маршрут: код %d, ожидался 404", rec.Code)
+	}
+	rec := api.do(http.MethodPost, path, authAs(owner, botToken), nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("свой маршрут: код %d: %s", rec.Code, rec.Body)
+	}
+	var trip model.Trip
+	if err := json.Unmarshal(rec.Body.Bytes(), &trip); err != nil || trip.ID != tripID || trip.Status != "completed" {
+		t.Fatalf("маршрут: %+v, %v", trip, err)
+	}
+	if rec := api.do(http.MethodPost, path, authAs(owner, botToken), nil); rec.Code != http.StatusConflict {
+		t.Errorf("повторное завершение: код %d, ожидался 409", rec.Code)
+	}
+	if _, err := api.trips.GetActive(ctx, owner.ID); err == nil {
+		t.Error("завершенный маршрут остался текущим")
+	}
+
+	review, err := api.reviews.CreateReview(ctx, owner.ID, loc.ID, 5, "Красиво", nil)
+	if err != nil {
+		t.Fatalf("отзыв после завершения маршрута: %v", err)
+	}
+	if review.Status != "published" {
+		t.Errorf("статус отзыва %q, ожидался published", review.Status)
+	}
+	// незавершенный чужой маршрут с той же локацией права не дает
+	if _, err := api.reviews.CreateReview(ctx, stranger.ID, loc.ID, 5, "Красиво", nil); err == nil {
+		t.Error("отзыв без поездки принят")
+	}
+}
@@ -0,0 +1,153 @@
+// Package health отвечает на проверки живости и готовности API и ботов:
+// /health/live — процесс работает, /health/ready — зависимости доступны и приложение принимает работу.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"tourism/internal/migrate"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// checkTimeout ограничивает время всех проверок готовности.
+const checkTimeout = 3 * time.Second
+
+// Check проверяет одну зависимость; nil — зависимость доступна.
+type Check func(ctx context.Context) error
+
+// Checker хранит проверки готовности. После Shutdown готовность всегда отрицательна,
+// чтобы балансировщик перестал направлять запросы, пока приложение завершает начатое.
+type Checker struct {
+	mu       sync.RWMutex
+	names    []string
+	checks   map[string]Check
+	stopping atomic.Bool
+}
+
+// NewChecker создает Checker без проверок.
+func NewChecker() *Checker {
+	return &Checker{checks: make(map[string]Check)}
+}
+
+// Add добавляет проверку готовности с именем name.
+func (c *Checker) Add(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.checks[name]; !ok {
+		c.names = append(c.names, name)
+	}
+	c.checks[name] = check
+}
+
+// Shutdown помечает приложение как завершающее работу.
+func (c *Checker) Shutdown() {
+	c.stopping.Store(true)
+}
+
+// Report — результат проверки готовности.
+type Report struct {
+	Status string            `json:"status"` // "ok" или "unavailable"
+	Checks map[string]string `json:"checks"` // имя проверки -> "ok" или текст ошибки
+}
+
+// Ready выполняет все проверки параллельно.
+func (c *Checker) Ready(ctx context.Context) Report {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	c.mu.RLock()
+	names := append([]string(nil), c.names...)
+	checks := make([]Check, len(names))
+	for i, name := range names {
+		checks[i] = c.checks[name]
+	}
+	c.mu.RUnlock()
+
+	results := make([]error, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			results[i] = check(ctx)
+		}(i, check)
+	}
+	wg.Wait()
+
+	report := Report{Status: "ok", Checks: make(map[string]string, len(names)+1)}
+	if c.stopping.Load() {
+		report.Status = "unavailable"
+		report.Checks["shutdown"] = "приложение завершает работу"
+	}
+	for i, name := range names {
+		if results[i] != nil {
+			report.Status = "unavailable"
+			report.Checks[name] = results[i].Error()
+		} else {
+			report.Checks[name] = "ok"
+		}
+	}
+	return report
+}
+
+// LiveHandler отвечает 200, пока процесс работает.
+func (c *Checker) LiveHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// ReadyHandler отвечает 200, если все проверки пройдены, иначе 503 с описанием проблем.
+func (c *Checker) ReadyHandler(w http.ResponseWriter, r *http.Request) {
+	report := c.Ready(r.Context())
+	status := http.StatusOK
+	if report.Status != "ok" {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, report)
+}
+
+// Register добавляет обработчики /health/live и /health/ready в mux.
+func (c *Checker) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/health/live", c.LiveHandler)
+	mux.HandleFunc("/health/ready", c.ReadyHandler)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// Database проверяет соединение с базой данных.
+func Database(db *sqlx.DB) Check {
+	return func(ctx context.Context) error {
+		if err := db.PingContext(ctx); err != nil {
+			return fmt.Errorf("база данных недоступна: %w", err)
+		}
+		return nil
+	}
+}
+
+// Migrations проверяет, что в базе применены все миграции из fsys, то есть схема соответствует коду.
+func Migrations(db *sqlx.DB, fsys fs.FS) Check {
+	return func(ctx context.Context) error {
+		st, err := migrate.GetStatus(ctx, db, fsys)
+		if err != nil {
+			return err
+		}
+		if !st.UpToDate() {
+			return errors.New("не применены миграции: " + strings.Join(st.Pending, ", "))
+		}
+		return nil
+	}
+}
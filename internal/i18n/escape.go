@@ -0,0 +1,32 @@
+package i18n
+
+import "strings"
+
+// символы, которые в тексте MarkdownV2 нужно экранировать обратной косой чертой
+const markdownV2Special = "_*[]()~`>#+-=|{}.!\\"
+
+// EscapeMarkdownV2 экранирует s для текста сообщения с ParseMode MarkdownV2.
+func EscapeMarkdownV2(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		if strings.ContainsRune(markdownV2Special, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+var htmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+// EscapeHTML экранирует s для текста сообщения с ParseMode HTML.
+func EscapeHTML(s string) string {
+	return htmlEscaper.Replace(s)
+}
+
+// Stars возвращает оценку от 0 до 5 в виде звезд.
+func Stars(rating int) string {
+	rating = min(max(rating, 0), 5)
+	return strings.Repeat("★", rating) + strings.Repeat("☆", 5-rating)
+}
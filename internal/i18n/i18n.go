@@ -0,0 +1,273 @@
+// Package i18n содержит тексты сообщений ботов: шаблоны text/template по идентификатору
+// сообщения и локали, встроенные в бинарный файл из каталога locales (по файлу YAML на локаль).
+//
+// Идентификаторы — строки с точками ("booking.created"), данные передаются парами ключ-значение,
+// как атрибуты slog: reg.Text("ru", "booking.created", "id", 12) подставляет 12 вместо {{.id}}.
+// В шаблонах доступны функции md и html (экранирование для MarkdownV2 и HTML), stars, join и inc.
+//
+// Если сообщение не переведено на язык пользователя, оно берется из следующей локали цепочки
+// (Chain): "pt-br" -> "pt" -> локаль по умолчанию.
+package i18n
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed locales/*.yaml
+var localesFS embed.FS
+
+// DefaultLocale — локаль, на которой написаны все тексты; она используется, если для языка
+// пользователя нет перевода.
+const DefaultLocale = "ru"
+
+// Registry хранит разобранные шаблоны всех локалей.
+type Registry struct {
+	defaultLocale string
+	templates     map[string]map[string]*template.Template // локаль -> идентификатор -> шаблон
+}
+
+// funcs — функции, доступные в шаблонах.
+var funcs = template.FuncMap{
+	"md":    func(v any) string { return EscapeMarkdownV2(fmt.Sprint(v)) },
+	"html":  func(v any) string { return EscapeHTML(fmt.Sprint(v)) },
+	"stars": Stars,
+	"join":  strings.Join,
+	"inc":   func(i int) int { return i + 1 },
+}
+
+// Load разбирает шаблоны из файлов <локаль>.yaml в корне fsys. Ошибка возвращается, если нет
+// файла локали по умолчанию или шаблон не разбирается; полноту переводов проверяет Check.
+func Load(fsys fs.FS, defaultLocale string) (*Registry, error) {
+	files, err := fs.Glob(fsys, "*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	r := &Registry{defaultLocale: defaultLocale, templates: make(map[string]map[string]*template.Template)}
+	var errs []error
+	for _, file := range files {
+		locale := strings.TrimSuffix(path.Base(file), ".yaml")
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		var texts map[string]string
+		if err := yaml.Unmarshal(data, &texts); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", file, err))
+			continue
+		}
+		r.templates[locale] = make(map[string]*template.Template, len(texts))
+		for key, text := range texts {
+			t, err := template.New(key).Funcs(funcs).Option("missingkey=error").Parse(text)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", file, err))
+				continue
+			}
+			r.templates[locale][key] = t
+		}
+	}
+	if _, ok := r.templates[defaultLocale]; !ok {
+		errs = append(errs, fmt.Errorf("нет текстов локали по умолчанию %q", defaultLocale))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("ошибка в текстах сообщений: %w", err)
+	}
+	return r, nil
+}
+
+// New загружает встроенные тексты. Непереведенные сообщения не мешают запуску — они показываются
+// по цепочке локалей и перечисляются в логе; полноту переводов проверяет Check (см. тесты пакета).
+func New() (*Registry, error) {
+	sub, err := fs.Sub(localesFS, "locales")
+	if err != nil {
+		return nil, err
+	}
+	r, err := Load(sub, DefaultLocale)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.Check(); err != nil {
+		slog.Warn("Не все сообщения переведены", "err", err)
+	}
+	return r, nil
+}
+
+// Check проверяет, что каждый идентификатор сообщения есть во всех локалях, и перечисляет
+// все пропуски сразу.
+func (r *Registry) Check() error {
+	keys := map[string]bool{}
+	for _, templates := range r.templates {
+		for key := range templates {
+			keys[key] = true
+		}
+	}
+	var errs []error
+	for _, locale := range r.Locales() {
+		missing := []string{}
+		for key := range keys {
+			if _, ok := r.templates[locale][key]; !ok {
+				missing = append(missing, key)
+			}
+		}
+		if len(missing) > 0 {
+			sort.Strings(missing)
+			errs = append(errs, fmt.Errorf("в локали %s нет сообщений: %s", locale, strings.Join(missing, ", ")))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Locales возвращает загруженные локали по алфавиту.
+func (r *Registry) Locales() []string {
+	locales := make([]string, 0, len(r.templates))
+	for locale := range r.templates {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Keys возвращает идентификаторы сообщений локали по умолчанию по алфавиту.
+func (r *Registry) Keys() []string {
+	keys := make([]string, 0, len(r.templates[r.defaultLocale]))
+	for key := range r.templates[r.defaultLocale] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Has сообщает, есть ли сообщение key в локали по умолчанию.
+func (r *Registry) Has(key string) bool {
+	_, ok := r.templates[r.defaultLocale][key]
+	return ok
+}
+
+// HasLocale сообщает, загружены ли тексты локали locale.
+func (r *Registry) HasLocale(locale string) bool {
+	_, ok := r.templates[locale]
+	return ok
+}
+
+// Chain возвращает загруженные локали, в которых по порядку ищется сообщение для кода языка
+// Telegram lang ("en", "pt-br"): точное совпадение, основной язык, локаль по умолчанию.
+func (r *Registry) Chain(lang string) []string {
+	lang = strings.ToLower(strings.ReplaceAll(lang, "_", "-"))
+	base, _, _ := strings.Cut(lang, "-")
+	chain := make([]string, 0, 3)
+	for _, locale := range []string{lang, base, r.defaultLocale} {
+		if r.HasLocale(locale) && !slices.Contains(chain, locale) {
+			chain = append(chain, locale)
+		}
+	}
+	return chain
+}
+
+// Resolve подбирает загруженную локаль для кода языка Telegram — первую в цепочке Chain.
+func (r *Registry) Resolve(lang string) string {
+	return r.Chain(lang)[0]
+}
+
+// Negotiate подбирает загруженную локаль по заголовку HTTP Accept-Language ("en-US,en;q=0.9,ru;q=0.5"):
+// языки перебираются по убыванию веса q, для каждого проверяются точное совпадение и основной
+// язык. Если ни один язык не загружен, возвращается локаль по умолчанию.
+func (r *Registry) Negotiate(acceptLanguage string) string {
+	type weighted struct {
+		lang string
+		q    float64
+	}
+	langs := []weighted{}
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		lang, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		if lang = strings.TrimSpace(lang); lang != "" && lang != "*" && q > 0 {
+			langs = append(langs, weighted{lang: lang, q: q})
+		}
+	}
+	sort.SliceStable(langs, func(i, j int) bool { return langs[i].q > langs[j].q })
+	for _, w := range langs {
+		lang := strings.ToLower(strings.ReplaceAll(w.lang, "_", "-"))
+		base, _, _ := strings.Cut(lang, "-")
+		for _, locale := range []string{lang, base} {
+			if r.HasLocale(locale) {
+				return locale
+			}
+		}
+	}
+	return r.defaultLocale
+}
+
+// Render выполняет шаблон key с данными из пар ключ-значение args на языке locale, а если
+// сообщение на него не переведено — на следующем языке цепочки Chain.
+func (r *Registry) Render(locale, key string, args ...any) (string, error) {
+	var t *template.Template
+	for _, l := range r.Chain(locale) {
+		if t = r.templates[l][key]; t != nil {
+			break
+		}
+	}
+	if t == nil {
+		return "", fmt.Errorf("неизвестное сообщение %q", key)
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, vars(args)); err != nil {
+		return "", fmt.Errorf("сообщение %q (%s): %w", key, locale, err)
+	}
+	return b.String(), nil
+}
+
+// Text — Render для обработчиков: ошибка логируется, а вместо текста возвращается key,
+// чтобы пользователь получил хоть какой-то ответ.
+func (r *Registry) Text(locale, key string, args ...any) string {
+	text, err := r.Render(locale, key, args...)
+	if err != nil {
+		slog.Error("Ошибка шаблона сообщения", "key", key, "locale", locale, "err", err)
+		return key
+	}
+	return text
+}
+
+// Variants возвращает текст сообщения без данных во всех локалях — например, надписи кнопки
+// меню, по которым роутер узнает нажатие на любом языке.
+func (r *Registry) Variants(key string) []string {
+	seen := map[string]bool{}
+	variants := []string{}
+	for _, locale := range r.Locales() {
+		if text, err := r.Render(locale, key); err == nil && !seen[text] {
+			seen[text] = true
+			variants = append(variants, text)
+		}
+	}
+	return variants
+}
+
+// vars собирает данные шаблона из пар ключ-значение; ключ без значения получает пустое значение.
+// Шаблон, обращающийся к ключу, которого нет в args, завершается ошибкой.
+func vars(args []any) map[string]any {
+	m := make(map[string]any, len(args)/2)
+	for i := 0; i < len(args); i += 2 {
+		key := fmt.Sprint(args[i])
+		if i+1 < len(args) {
+			m[key] = args[i+1]
+		} else {
+			m[key] = nil
+		}
+	}
+	return m
+}
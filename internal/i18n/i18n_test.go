@@ -0,0 +1,239 @@
+package i18n
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"text/template/parse"
+	"time"
+
+	"tourism/internal/model"
+)
+
+func load(t *testing.T) *Registry {
+	t.Helper()
+	sub, err := fs.Sub(localesFS, "locales")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := Load(sub, DefaultLocale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestLocalesComplete(t *testing.T) {
+	r := load(t)
+	if got, want := r.Locales(), []string{"en", "os", "ru"}; !slices.Equal(got, want) {
+		t.Fatalf("локали %v, ожидались %v", got, want)
+	}
+	if err := r.Check(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// samples — данные, с которыми выполняются шаблоны: по имени поля, которое шаблон читает из
+// данных. Поля, которых здесь нет, получают строку.
+var samples = map[string]any{
+	"rating":     4.5,
+	"price":      1500.0,
+	"topics":     []string{"housing", "tour"},
+	"regions":    []string{"Владикавказ", "Алагир"},
+	"reasons":    []string{"links", "caps"},
+	"unresolved": []string{"Мидаграбин"},
+	"links":      []string{"https://t.me/example"},
+	"locations": []model.Location{
+		{ID: 1, Name: "Цейское ущелье", Region: "Алагир", Rating: 4.8},
+		{ID: 2, Name: "Даргавс", Region: "Пригородный", Rating: 4.6},
+	},
+	"offers": []model.Offer{{ID: 1, Name: "Гостевой дом", Price: 3500}},
+	"reviews": []model.Review{
+		{ID: 1, Rating: 5, Text: "Отлично", AuthorName: "Алан", Reply: "Спасибо!",
+			PhotoFileIDs: []string{"photo"}, CreatedAt: time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)},
+		{ID: 2, Rating: 3, AuthorName: "Мадина", CreatedAt: time.Date(2025, 7, 2, 12, 0, 0, 0, time.UTC)},
+	},
+	"entries": []model.ModerationEntry{
+		{ID: 1, Action: "held", Reason: "links", CreatedAt: time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)},
+		{ID: 2, ModeratorID: new(int), Action: "approved", CreatedAt: time.Date(2025, 7, 1, 13, 0, 0, 0, time.UTC)},
+	},
+}
+
+// sampleOverrides — данные отдельных сообщений, у которых поле с тем же именем другого типа.
+var sampleOverrides = map[string]map[string]any{
+	"notify.review_published": {"rating": 4},
+}
+
+func TestTemplatesExecute(t *testing.T) {
+	r := load(t)
+	for _, locale := range r.Locales() {
+		for key, tmpl := range r.templates[locale] {
+			args := []any{}
+			for _, name := range dataFields(tmpl.Tree.Root) {
+				v, ok := sampleOverrides[key][name]
+				if !ok {
+					v, ok = samples[name]
+				}
+				if !ok {
+					v = "пример"
+				}
+				args = append(args, name, v)
+			}
+			text, err := r.Render(locale, key, args...)
+			if err != nil {
+				t.Errorf("%s: %v", locale, err)
+				continue
+			}
+			if strings.Contains(text, "%!") || strings.Contains(text, "<no value>") {
+				t.Errorf("%s: сообщение %q с неподходящими данными: %s", locale, key, text)
+			}
+		}
+	}
+}
+
+// dataFields возвращает имена полей, которые шаблон читает из переданных данных. Внутри range и
+// with точка указывает на другое значение, поэтому их тела не просматриваются.
+func dataFields(root *parse.ListNode) []string {
+	fields := []string{}
+	var walk func(n parse.Node)
+	walk = func(n parse.Node) {
+		switch n := n.(type) {
+		case *parse.ListNode:
+			if n != nil {
+				for _, c := range n.Nodes {
+					walk(c)
+				}
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe)
+		case *parse.IfNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.RangeNode:
+			walk(n.Pipe)
+		case *parse.WithNode:
+			walk(n.Pipe)
+		case *parse.PipeNode:
+			if n != nil {
+				for _, c := range n.Cmds {
+					walk(c)
+				}
+			}
+		case *parse.CommandNode:
+			for _, a := range n.Args {
+				walk(a)
+			}
+		case *parse.FieldNode:
+			if !slices.Contains(fields, n.Ident[0]) {
+				fields = append(fields, n.Ident[0])
+			}
+		}
+	}
+	walk(root)
+	return fields
+}
+
+// textFuncs — функции, первый строковый аргумент которых — идентификатор сообщения.
+var textFuncs = map[string]bool{
+	"T":        true,
+	"Text":     true,
+	"Render":   true,
+	"Button":   true,
+	"Variants": true,
+	"Has":      true,
+	"textFor":  true,
+	"reply":    true,
+	"button":   true,
+}
+
+// keyPattern — идентификатор сообщения: слова через точку, например "booking.status.pending".
+var keyPattern = regexp.MustCompile(`^[a-z_]+(\.[a-z_]+)+\.?$`)
+
+// TestKeysUsedInCode проверяет, что каждый идентификатор, который код модуля передает строковым
+// литералом в T, Text, Render, Button и другие функции текстов, есть в локали по умолчанию.
+// Литерал, к которому приписывается окончание ("booking.status." + status), должен быть
+// префиксом хотя бы одного идентификатора.
+func TestKeysUsedInCode(t *testing.T) {
+	r := load(t)
+	keys := r.Keys()
+	fset := token.NewFileSet()
+	found := 0
+	err := filepath.WalkDir("../..", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && path != "../.." && (strings.HasPrefix(d.Name(), ".") || d.Name() == "vendor") {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || !textFuncs[funcName(call.Fun)] {
+				return true
+			}
+			for _, arg := range call.Args {
+				key, prefix, ok := keyArg(arg)
+				if !ok {
+					continue
+				}
+				found++
+				if prefix && !slices.ContainsFunc(keys, func(k string) bool { return strings.HasPrefix(k, key) }) {
+					t.Errorf("%s: нет сообщений с префиксом %q", fset.Position(arg.Pos()), key)
+				}
+				if !prefix && !r.Has(key) {
+					t.Errorf("%s: нет сообщения %q", fset.Position(arg.Pos()), key)
+				}
+				break
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found == 0 {
+		t.Fatal("в коде не найдено ни одного идентификатора сообщения")
+	}
+}
+
+func funcName(fun ast.Expr) string {
+	switch f := fun.(type) {
+	case *ast.Ident:
+		return f.Name
+	case *ast.SelectorExpr:
+		return f.Sel.Name
+	}
+	return ""
+}
+
+// keyArg распознает идентификатор в аргументе: строковый литерал или литерал, к которому
+// приписывается окончание ("booking.status." + status).
+func keyArg(arg ast.Expr) (key string, prefix, ok bool) {
+	if bin, isBin := arg.(*ast.BinaryExpr); isBin && bin.Op == token.ADD {
+		arg, prefix = bin.X, true
+	}
+	lit, isLit := arg.(*ast.BasicLit)
+	if !isLit || lit.Kind != token.STRING {
+		return "", false, false
+	}
+	key, err := strconv.Unquote(lit.Value)
+	if err != nil || !keyPattern.MatchString(key) {
+		return "", false, false
+	}
+	return key, prefix, true
+}
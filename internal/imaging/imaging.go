@@ -0,0 +1,71 @@
+// Package imaging готовит уменьшенные копии фото: декодирует JPEG и PNG, уменьшает
+// изображение с усреднением пикселей и кодирует результат в JPEG.
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	_ "image/png" // декодер PNG для image.Decode
+)
+
+// Quality — качество JPEG уменьшенных копий.
+const Quality = 85
+
+// Decode декодирует изображение и возвращает его вместе с форматом ("jpeg" или "png").
+func Decode(data []byte) (image.Image, string, error) {
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("не удалось декодировать изображение: %w", err)
+	}
+	return img, format, nil
+}
+
+// Fit уменьшает изображение так, чтобы большая сторона была не больше maxSide, сохраняя
+// пропорции. Каждый пиксель результата — среднее покрываемых им пикселей исходника, поэтому
+// мелкие детали не превращаются в шум. Изображения меньше maxSide не увеличиваются.
+func Fit(src image.Image, maxSide int) *image.RGBA {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w > maxSide || h > maxSide {
+		if w >= h {
+			w, h = maxSide, max(1, h*maxSide/b.Dx())
+		} else {
+			w, h = max(1, w*maxSide/b.Dy()), maxSide
+		}
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0 := b.Min.Y + y*b.Dy()/h
+		y1 := max(y0+1, b.Min.Y+(y+1)*b.Dy()/h)
+		for x := 0; x < w; x++ {
+			x0 := b.Min.X + x*b.Dx()/w
+			x1 := max(x0+1, b.Min.X+(x+1)*b.Dx()/w)
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, bl, a, n = r+uint64(cr), g+uint64(cg), bl+uint64(cb), a+uint64(ca), n+1
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{R: uint8(r / n >> 8), G: uint8(g / n >> 8), B: uint8(bl / n >> 8), A: uint8(a / n >> 8)})
+		}
+	}
+	return dst
+}
+
+// EncodeJPEG кодирует изображение в JPEG с качеством Quality. В JPEG нет прозрачности, поэтому
+// изображение сначала накладывается на белый фон: иначе прозрачные области PNG станут черными.
+func EncodeJPEG(img image.Image) ([]byte, error) {
+	flat := image.NewRGBA(img.Bounds())
+	draw.Draw(flat, flat.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), img, img.Bounds().Min, draw.Over)
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, flat, &jpeg.Options{Quality: Quality}); err != nil {
+		return nil, fmt.Errorf("не удалось закодировать JPEG: %w", err)
+	}
+	return buf.Bytes(), nil
+}
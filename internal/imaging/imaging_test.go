@@ -0,0 +1,44 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestEncodeJPEGTransparentOnWhite(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 32; x < 64; x++ {
+			src.SetNRGBA(x, y, color.NRGBA{R: 200, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, src); err != nil {
+		t.Fatal(err)
+	}
+	img, format, err := Decode(buf.Bytes())
+	if err != nil || format != "png" {
+		t.Fatalf("Decode: %v, %q", err, format)
+	}
+	data, err := EncodeJPEG(Fit(img, 16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	thumb, format, err := Decode(data)
+	if err != nil || format != "jpeg" {
+		t.Fatalf("Decode JPEG: %v, %q", err, format)
+	}
+	if b := thumb.Bounds(); b.Dx() != 16 || b.Dy() != 16 {
+		t.Fatalf("размер копии %v, ожидалось 16x16", b)
+	}
+	// прозрачная половина — белая, непрозрачная сохраняет цвет
+	if r, g, b, _ := thumb.At(2, 8).RGBA(); r>>8 < 240 || g>>8 < 240 || b>>8 < 240 {
+		t.Errorf("прозрачная область: %d %d %d, ожидался белый", r>>8, g>>8, b>>8)
+	}
+	if r, g, b, _ := thumb.At(13, 8).RGBA(); r>>8 < 180 || g>>8 > 40 || b>>8 > 40 {
+		t.Errorf("непрозрачная область: %d %d %d, ожидался красный", r>>8, g>>8, b>>8)
+	}
+}
@@ -0,0 +1,99 @@
+// Package logging настраивает структурированные логи (log/slog) для API и ботов и переносит
+// через context.Context поля, которые добавляются ко всем записям обработки: ID HTTP-запроса,
+// ID обновления Telegram, чат.
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Setup настраивает логгер по умолчанию: уровень (debug, info, warn, error), формат (json или text)
+// и имя приложения в каждой записи. Вызовы пакета log после этого тоже пишутся через slog.
+func Setup(app, level, format string) error {
+	logger, err := New(os.Stderr, app, level, format)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+	return nil
+}
+
+// New создает логгер, который дополняет записи полями из контекста (см. With).
+func New(w io.Writer, app, level, format string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("некорректный уровень логирования %q", level)
+	}
+	opts := &slog.HandlerOptions{Level: lvl}
+	var h slog.Handler
+	switch strings.ToLower(format) {
+	case "json":
+		h = slog.NewJSONHandler(w, opts)
+	case "text":
+		h = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("некорректный формат логов %q: ожидается json или text", format)
+	}
+	return slog.New(contextHandler{h}).With("app", app), nil
+}
+
+type attrsKey struct{}
+
+// With возвращает контекст, записи с которым (slog.InfoContext и т.д.) содержат дополнительные поля.
+// Аргументы — пары ключ-значение или slog.Attr, как у slog.Logger.With.
+func With(ctx context.Context, args ...any) context.Context {
+	prev, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	attrs := append([]slog.Attr(nil), prev...)
+	attrs = append(attrs, argsToAttrs(args)...)
+	return context.WithValue(ctx, attrsKey{}, attrs)
+}
+
+// argsToAttrs разбирает аргументы With так же, как это делает slog.
+func argsToAttrs(args []any) []slog.Attr {
+	var r slog.Record
+	r.Add(args...)
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	return attrs
+}
+
+// NewRequestID возвращает случайный ID запроса из 16 шестнадцатеричных символов.
+func NewRequestID() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// contextHandler добавляет к записи поля, сохраненные в контексте функцией With.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		if attrs, ok := ctx.Value(attrsKey{}).([]slog.Attr); ok {
+			r.AddAttrs(attrs...)
+		}
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
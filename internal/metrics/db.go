@@ -0,0 +1,151 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// DriverName — драйвер PostgreSQL (lib/pq), который измеряет длительность запросов
+// (DBQueryDuration). Подключение через него ничем не отличается от "postgres".
+const DriverName = "postgres-instrumented"
+
+func init() {
+	sql.Register(DriverName, instrumentedDriver{&pq.Driver{}})
+	sqlx.BindDriver(DriverName, sqlx.DOLLAR)
+}
+
+type instrumentedDriver struct {
+	driver.Driver
+}
+
+func (d instrumentedDriver) Open(name string) (driver.Conn, error) {
+	c, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: c}, nil
+}
+
+// conn измеряет запросы, выполняемые напрямую на соединении (так database/sql выполняет
+// запросы и транзакции, если драйвер поддерживает QueryerContext и ExecerContext).
+type conn struct {
+	driver.Conn
+}
+
+var (
+	_ driver.QueryerContext     = (*conn)(nil)
+	_ driver.ExecerContext      = (*conn)(nil)
+	_ driver.ConnPrepareContext = (*conn)(nil)
+	_ driver.ConnBeginTx        = (*conn)(nil)
+	_ driver.Pinger             = (*conn)(nil)
+	_ driver.SessionResetter    = (*conn)(nil)
+	_ driver.Validator          = (*conn)(nil)
+)
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	rows, err := q.QueryContext(ctx, query, args)
+	observeQuery(query, start, err)
+	return rows, err
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	res, err := e.ExecContext(ctx, query, args)
+	observeQuery(query, start, err)
+	return res, err
+}
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return p.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c *conn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *conn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *conn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+// observeQuery записывает длительность запроса. ErrSkip означает, что database/sql повторит
+// запрос другим способом, поэтому такая попытка не учитывается.
+func observeQuery(query string, start time.Time, err error) {
+	if err == driver.ErrSkip {
+		return
+	}
+	l := labelsFor(query)
+	status := "ok"
+	if err != nil {
+		status = "error"
+	}
+	DBQueryDuration.WithLabelValues(l.operation, l.table, status).Observe(time.Since(start).Seconds())
+}
+
+type queryLabels struct {
+	operation, table string
+}
+
+var (
+	labelCache   sync.Map // текст запроса -> queryLabels
+	tablePattern = regexp.MustCompile(`(?i)\b(?:from|into|update|join)\s+([a-z_][a-z0-9_]*)`)
+)
+
+// labelsFor определяет тип запроса (первое слово) и основную таблицу (первую после
+// FROM, INTO, UPDATE или JOIN). Запросы в репозиториях постоянны, поэтому результат кешируется.
+func labelsFor(query string) queryLabels {
+	if l, ok := labelCache.Load(query); ok {
+		return l.(queryLabels)
+	}
+	l := queryLabels{operation: "other", table: "unknown"}
+	if fields := strings.Fields(query); len(fields) > 0 {
+		switch op := strings.ToLower(fields[0]); op {
+		case "select", "insert", "update", "delete", "with", "begin", "commit", "rollback":
+			l.operation = op
+		}
+	}
+	if m := tablePattern.FindStringSubmatch(query); m != nil {
+		l.table = strings.ToLower(m[1])
+	}
+	labelCache.Store(query, l)
+	return l
+}
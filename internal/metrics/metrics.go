@@ -0,0 +1,108 @@
+// Package metrics содержит метрики Prometheus API и ботов и обработчик /metrics.
+package metrics
+
+import (
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "tourism"
+
+var (
+	// HTTPRequestDuration — длительность HTTP-запросов API по маршруту и коду ответа.
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Длительность HTTP-запросов API.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// BotUpdates — число обновлений Telegram, полученных ботом, по типу обновления.
+	BotUpdates = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bot_updates_total",
+		Help:      "Обновления Telegram, полученные ботом.",
+	}, []string{"type"})
+
+	// BotUpdateDuration — длительность обработки обновления.
+	BotUpdateDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "bot_update_duration_seconds",
+		Help:      "Длительность обработки обновлений Telegram.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"type"})
+
+	// TelegramRequests — запросы к Bot API по методу.
+	TelegramRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "telegram_requests_total",
+		Help:      "Запросы к Telegram Bot API.",
+	}, []string{"method"})
+
+	// TelegramSendErrors — ошибки запросов к Bot API по методу и коду ошибки Telegram
+	// ("network", если ответ не получен).
+	TelegramSendErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "telegram_send_errors_total",
+		Help:      "Ошибки запросов к Telegram Bot API.",
+	}, []string{"method", "code"})
+
+	// BookingTransitions — смены статуса бронирований.
+	BookingTransitions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "booking_transitions_total",
+		Help:      "Смены статуса бронирований.",
+	}, []string{"from", "to"})
+
+	// Notifications — результаты отправки уведомлений из очереди по виду уведомления и итогу
+	// ("sent", "retry", "failed", "blocked").
+	Notifications = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "notifications_total",
+		Help:      "Результаты отправки уведомлений пользователям.",
+	}, []string{"kind", "result"})
+
+	// DBQueryDuration — длительность запросов к базе данных по типу запроса и таблице.
+	DBQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Длительность запросов к PostgreSQL.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table", "status"})
+)
+
+// Handler отдает метрики в формате Prometheus.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// Serve запускает отдельный HTTP-сервер с метриками на адресе addr (для ботов, у которых нет API).
+// Пустой addr отключает сервер. Дополнительные обработчики регистрируются в mux до вызова.
+func Serve(addr string, mux *http.ServeMux) (*http.Server, error) {
+	if addr == "" {
+		return nil, nil
+	}
+	if mux == nil {
+		mux = http.NewServeMux()
+	}
+	mux.Handle("/metrics", Handler())
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Сервер метрик остановлен с ошибкой", "err", err)
+		}
+	}()
+	slog.Info("Метрики доступны", "addr", addr, "path", "/metrics")
+	return srv, nil
+}
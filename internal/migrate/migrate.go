@@ -0,0 +1,188 @@
+// Package migrate применяет SQL-миграции и хранит версии примененных в таблице schema_migrations.
+// Версия миграции — имя файла без расширения (например, "009_conversation_states").
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// legacyVersions — миграции, которые нельзя выполнить повторно (создание таблиц без IF NOT EXISTS,
+// начальные данные). До учета версий API при каждом старте выполнял все файлы заново, поэтому
+// в базе без schema_migrations, где уже есть таблица users, они применены: их версии
+// записываются без выполнения. Остальные миграции того времени идемпотентны и выполняются обычно.
+var legacyVersions = []string{"001_init", "002_seed"}
+
+// lockID — ключ advisory-блокировки, чтобы несколько реплик API не применяли миграции одновременно.
+const lockID = 7240185
+
+const createTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version    TEXT PRIMARY KEY,
+	applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+)`
+
+// Status — состояние схемы базы относительно файлов миграций.
+type Status struct {
+	Current string   // последняя примененная версия ("" — ни одной)
+	Latest  string   // последняя версия среди файлов
+	Pending []string // версии, которые еще не применены
+}
+
+// UpToDate сообщает, применены ли все миграции.
+func (s Status) UpToDate() bool {
+	return len(s.Pending) == 0
+}
+
+// Versions возвращает версии миграций из fsys по возрастанию.
+func Versions(fsys fs.FS) ([]string, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("список миграций: %w", err)
+	}
+	versions := make([]string, len(names))
+	for i, name := range names {
+		versions[i] = strings.TrimSuffix(path.Base(name), ".sql")
+	}
+	sort.Strings(versions)
+	return versions, nil
+}
+
+// Apply применяет еще не примененные миграции из fsys по порядку, каждую в своей транзакции,
+// и возвращает их версии. При ошибке останавливается: следующие миграции могут зависеть от нее.
+func Apply(ctx context.Context, db *sqlx.DB, fsys fs.FS) ([]string, error) {
+	versions, err := Versions(fsys)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := db.Connx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("подключение для миграций: %w", err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, lockID); err != nil {
+		return nil, fmt.Errorf("блокировка миграций: %w", err)
+	}
+	defer conn.ExecContext(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, lockID)
+
+	var legacy bool
+	err = conn.GetContext(ctx, &legacy,
+		`SELECT to_regclass('schema_migrations') IS NULL AND to_regclass('users') IS NOT NULL`)
+	if err != nil {
+		return nil, fmt.Errorf("проверка схемы: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, createTable); err != nil {
+		return nil, fmt.Errorf("создание schema_migrations: %w", err)
+	}
+	if legacy {
+		if err := recordLegacy(ctx, conn); err != nil {
+			return nil, err
+		}
+	}
+	done, err := appliedSet(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []string
+	for _, v := range versions {
+		if done[v] {
+			continue
+		}
+		content, err := fs.ReadFile(fsys, v+".sql")
+		if err != nil {
+			return applied, fmt.Errorf("миграция %s: %w", v, err)
+		}
+		if err := applyOne(ctx, conn, v, string(content)); err != nil {
+			return applied, fmt.Errorf("миграция %s: %w", v, err)
+		}
+		slog.Info("Миграция применена", "version", v)
+		applied = append(applied, v)
+	}
+	return applied, nil
+}
+
+// recordLegacy записывает версии legacyVersions для базы, созданной до учета версий.
+func recordLegacy(ctx context.Context, conn *sqlx.Conn) error {
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("учет миграций до schema_migrations: %w", err)
+	}
+	defer tx.Rollback()
+	for _, v := range legacyVersions {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version) VALUES ($1) ON CONFLICT DO NOTHING`, v); err != nil {
+			return fmt.Errorf("учет миграции %s: %w", v, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("учет миграций до schema_migrations: %w", err)
+	}
+	slog.Info("База создана до учета версий, миграции отмечены примененными", "versions", legacyVersions)
+	return nil
+}
+
+// applyOne выполняет миграцию и записывает ее версию в одной транзакции.
+func applyOne(ctx context.Context, conn *sqlx.Conn, version, content string) error {
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, content); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version) VALUES ($1)`, version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetStatus сравнивает примененные версии с файлами fsys. Таблица schema_migrations не создается:
+// если ее нет, все миграции считаются непримененными.
+func GetStatus(ctx context.Context, db sqlx.QueryerContext, fsys fs.FS) (Status, error) {
+	versions, err := Versions(fsys)
+	if err != nil {
+		return Status{}, err
+	}
+	var st Status
+	if len(versions) > 0 {
+		st.Latest = versions[len(versions)-1]
+	}
+	var exists bool
+	if err := sqlx.GetContext(ctx, db, &exists, `SELECT to_regclass('schema_migrations') IS NOT NULL`); err != nil {
+		return st, fmt.Errorf("проверка schema_migrations: %w", err)
+	}
+	done := map[string]bool{}
+	if exists {
+		if done, err = appliedSet(ctx, db); err != nil {
+			return st, err
+		}
+	}
+	for _, v := range versions {
+		if done[v] {
+			st.Current = v
+		} else {
+			st.Pending = append(st.Pending, v)
+		}
+	}
+	return st, nil
+}
+
+// appliedSet возвращает множество примененных версий.
+func appliedSet(ctx context.Context, db sqlx.QueryerContext) (map[string]bool, error) {
+	var versions []string
+	if err := sqlx.SelectContext(ctx, db, &versions, `SELECT version FROM schema_migrations`); err != nil {
+		return nil, fmt.Errorf("примененные миграции: %w", err)
+	}
+	set := make(map[string]bool, len(versions))
+	for _, v := range versions {
+		set[v] = true
+	}
+	return set, nil
+}
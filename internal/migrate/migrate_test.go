@@ -0,0 +1,389 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// fakeDB — база в памяти, понимающая только запросы пакета migrate. Миграцией считается любой
+// другой запрос; запрос с текстом "FAIL" завершается ошибкой. Изменения внутри транзакции
+// видны только после Commit.
+type fakeDB struct {
+	mu        sync.Mutex
+	tables    map[string]bool
+	versions  []string // записи schema_migrations
+	executed  []string // выполненные миграции
+	locks     int      // захваты advisory-блокировки
+	unlocks   int      // освобождения advisory-блокировки
+	lockedNow bool
+}
+
+var (
+	fakeDBsMu sync.Mutex
+	fakeDBs   = map[string]*fakeDB{}
+)
+
+func init() {
+	sql.Register("migratetest", fakeDriver{})
+}
+
+// openFake создает пустую базу (с таблицами tables) и подключение к ней.
+func openFake(t *testing.T, tables ...string) (*fakeDB, *sqlx.DB) {
+	t.Helper()
+	f := &fakeDB{tables: map[string]bool{}}
+	for _, name := range tables {
+		f.tables[name] = true
+	}
+	fakeDBsMu.Lock()
+	fakeDBs[t.Name()] = f
+	fakeDBsMu.Unlock()
+	db, err := sqlx.Open("migratetest", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+		fakeDBsMu.Lock()
+		delete(fakeDBs, t.Name())
+		fakeDBsMu.Unlock()
+	})
+	return f, db
+}
+
+func (f *fakeDB) state() (versions, executed []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.versions), slices.Clone(f.executed)
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	fakeDBsMu.Lock()
+	defer fakeDBsMu.Unlock()
+	f, ok := fakeDBs[name]
+	if !ok {
+		return nil, fmt.Errorf("база %s не найдена", name)
+	}
+	return &fakeConn{db: f}, nil
+}
+
+type fakeConn struct {
+	db *fakeDB
+	tx *fakeTx
+}
+
+// fakeTx копит изменения до Commit.
+type fakeTx struct {
+	conn     *fakeConn
+	versions []string
+	executed []string
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("подготовленные запросы не поддерживаются")
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *fakeConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if c.tx != nil {
+		return nil, errors.New("транзакция уже начата")
+	}
+	c.tx = &fakeTx{conn: c}
+	return c.tx, nil
+}
+
+func (tx *fakeTx) Commit() error {
+	f := tx.conn.db
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, v := range tx.versions {
+		if slices.Contains(f.versions, v) {
+			tx.conn.tx = nil
+			return fmt.Errorf("повтор версии %s", v)
+		}
+	}
+	f.versions = append(f.versions, tx.versions...)
+	f.executed = append(f.executed, tx.executed...)
+	tx.conn.tx = nil
+	return nil
+}
+
+func (tx *fakeTx) Rollback() error {
+	tx.conn.tx = nil
+	return nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	f := c.db
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case strings.Contains(query, "pg_advisory_lock"):
+		if f.lockedNow {
+			return nil, errors.New("блокировка уже захвачена")
+		}
+		f.lockedNow = true
+		f.locks++
+	case strings.Contains(query, "pg_advisory_unlock"):
+		f.lockedNow = false
+		f.unlocks++
+	case query == createTable:
+		f.tables["schema_migrations"] = true
+	case strings.HasPrefix(query, "INSERT INTO schema_migrations"):
+		if !f.tables["schema_migrations"] {
+			return nil, errors.New(`relation "schema_migrations" does not exist`)
+		}
+		v := args[0].Value.(string)
+		exists := slices.Contains(f.versions, v) || c.tx != nil && slices.Contains(c.tx.versions, v)
+		if exists {
+			if strings.Contains(query, "ON CONFLICT DO NOTHING") {
+				return driver.RowsAffected(0), nil
+			}
+			return nil, fmt.Errorf("повтор версии %s", v)
+		}
+		if c.tx == nil {
+			f.versions = append(f.versions, v)
+		} else {
+			c.tx.versions = append(c.tx.versions, v)
+		}
+	default:
+		if strings.Contains(query, "FAIL") {
+			return nil, errors.New("syntax error")
+		}
+		if c.tx == nil {
+			f.executed = append(f.executed, query)
+		} else {
+			c.tx.executed = append(c.tx.executed, query)
+		}
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	f := c.db
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch query {
+	case `SELECT to_regclass('schema_migrations') IS NULL AND to_regclass('users') IS NOT NULL`:
+		return &fakeRows{columns: []string{"?column?"}, values: [][]driver.Value{{!f.tables["schema_migrations"] && f.tables["users"]}}}, nil
+	case `SELECT to_regclass('schema_migrations') IS NOT NULL`:
+		return &fakeRows{columns: []string{"?column?"}, values: [][]driver.Value{{f.tables["schema_migrations"]}}}, nil
+	case `SELECT version FROM schema_migrations`:
+		if !f.tables["schema_migrations"] {
+			return nil, errors.New(`relation "schema_migrations" does not exist`)
+		}
+		rows := &fakeRows{columns: []string{"version"}}
+		for _, v := range f.versions {
+			rows.values = append(rows.values, []driver.Value{v})
+		}
+		return rows, nil
+	}
+	return nil, fmt.Errorf("неожиданный запрос %q", query)
+}
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func migrationsFS(names ...string) fstest.MapFS {
+	fsys := fstest.MapFS{}
+	for _, name := range names {
+		fsys[name+".sql"] = &fstest.MapFile{Data: []byte("-- " + name)}
+	}
+	return fsys
+}
+
+func TestApply(t *testing.T) {
+	f, db := openFake(t)
+	ctx := context.Background()
+	fsys := migrationsFS("002_seed", "001_init", "010_reviews", "003_trips")
+	fsys["README.md"] = &fstest.MapFile{Data: []byte("не миграция")}
+
+	applied, err := Apply(ctx, db, fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"001_init", "002_seed", "003_trips", "010_reviews"}
+	if !slices.Equal(applied, want) {
+		t.Errorf("применены %v, ожидалось %v", applied, want)
+	}
+	versions, executed := f.state()
+	if !slices.Equal(versions, want) {
+		t.Errorf("в schema_migrations записаны %v, ожидалось %v", versions, want)
+	}
+	if len(executed) != 4 || executed[0] != "-- 001_init" || executed[3] != "-- 010_reviews" {
+		t.Errorf("выполнены %q", executed)
+	}
+	if f.locks != 1 || f.unlocks != 1 {
+		t.Errorf("блокировка захвачена %d раз, освобождена %d", f.locks, f.unlocks)
+	}
+
+	// повторный запуск ничего не выполняет
+	applied, err = Apply(ctx, db, fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 0 {
+		t.Errorf("повторно применены %v", applied)
+	}
+	// новые миграции применяются, старые пропускаются
+	fsys["011_photos.sql"] = &fstest.MapFile{Data: []byte("-- 011_photos")}
+	applied, err = Apply(ctx, db, fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(applied, []string{"011_photos"}) {
+		t.Errorf("применены %v, ожидалось [011_photos]", applied)
+	}
+	if _, executed := f.state(); len(executed) != 5 {
+		t.Errorf("выполнены %q", executed)
+	}
+	if f.locks != 3 || f.unlocks != 3 {
+		t.Errorf("блокировка захвачена %d раз, освобождена %d", f.locks, f.unlocks)
+	}
+}
+
+func TestApplyLegacySchema(t *testing.T) {
+	fsys := migrationsFS("001_init", "002_seed", "003_trips")
+
+	t.Run("база до учета версий", func(t *testing.T) {
+		// таблица users есть, schema_migrations нет: 001 и 002 уже выполнены прежним API
+		f, db := openFake(t, "users")
+		applied, err := Apply(context.Background(), db, fsys)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(applied, []string{"003_trips"}) {
+			t.Errorf("применены %v, ожидалось [003_trips]", applied)
+		}
+		versions, executed := f.state()
+		if !slices.Equal(versions, []string{"001_init", "002_seed", "003_trips"}) {
+			t.Errorf("в schema_migrations записаны %v", versions)
+		}
+		if !slices.Equal(executed, []string{"-- 003_trips"}) {
+			t.Errorf("выполнены %q: миграции до учета версий не должны выполняться повторно", executed)
+		}
+	})
+	t.Run("учет версий уже ведется", func(t *testing.T) {
+		// schema_migrations есть: отсутствующие в ней версии выполняются, даже если users существует
+		f, db := openFake(t, "users", "schema_migrations")
+		f.versions = []string{"001_init"}
+		applied, err := Apply(context.Background(), db, fsys)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(applied, []string{"002_seed", "003_trips"}) {
+			t.Errorf("применены %v", applied)
+		}
+	})
+	t.Run("пустая база", func(t *testing.T) {
+		f, db := openFake(t)
+		if _, err := Apply(context.Background(), db, fsys); err != nil {
+			t.Fatal(err)
+		}
+		if _, executed := f.state(); len(executed) != 3 {
+			t.Errorf("в новой базе выполнены %q, ожидались все миграции", executed)
+		}
+	})
+}
+
+func TestApplyStopsAtFailure(t *testing.T) {
+	f, db := openFake(t)
+	ctx := context.Background()
+	fsys := migrationsFS("001_init", "002_seed", "004_reviews")
+	fsys["003_trips.sql"] = &fstest.MapFile{Data: []byte("ALTER TABLE trips FAIL")}
+
+	applied, err := Apply(ctx, db, fsys)
+	if err == nil || !strings.Contains(err.Error(), "003_trips") {
+		t.Fatalf("ошибка %v, ожидалось упоминание 003_trips", err)
+	}
+	if !slices.Equal(applied, []string{"001_init", "002_seed"}) {
+		t.Errorf("до ошибки применены %v", applied)
+	}
+	versions, executed := f.state()
+	if !slices.Equal(versions, []string{"001_init", "002_seed"}) {
+		t.Errorf("в schema_migrations записаны %v: неудачная миграция не должна записываться", versions)
+	}
+	if slices.Contains(executed, "-- 004_reviews") {
+		t.Error("после неудачной миграции выполнена следующая")
+	}
+	if f.lockedNow || f.unlocks != 1 {
+		t.Error("блокировка не освобождена после ошибки")
+	}
+
+	// после исправления миграция применяется со следующего запуска
+	fsys["003_trips.sql"] = &fstest.MapFile{Data: []byte("-- 003_trips")}
+	applied, err = Apply(ctx, db, fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(applied, []string{"003_trips", "004_reviews"}) {
+		t.Errorf("применены %v", applied)
+	}
+}
+
+func TestGetStatus(t *testing.T) {
+	f, db := openFake(t)
+	ctx := context.Background()
+	fsys := migrationsFS("001_init", "002_seed", "003_trips")
+
+	st, err := GetStatus(ctx, db, fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Current != "" || st.Latest != "003_trips" || len(st.Pending) != 3 || st.UpToDate() {
+		t.Errorf("без schema_migrations: %+v", st)
+	}
+	if f.tables["schema_migrations"] {
+		t.Error("GetStatus создал schema_migrations")
+	}
+
+	f.tables["schema_migrations"] = true
+	f.versions = []string{"001_init", "002_seed"}
+	if st, err = GetStatus(ctx, db, fsys); err != nil {
+		t.Fatal(err)
+	}
+	if st.Current != "002_seed" || !slices.Equal(st.Pending, []string{"003_trips"}) || st.UpToDate() {
+		t.Errorf("частично применены: %+v", st)
+	}
+
+	if _, err := Apply(ctx, db, fsys); err != nil {
+		t.Fatal(err)
+	}
+	if st, err = GetStatus(ctx, db, fsys); err != nil {
+		t.Fatal(err)
+	}
+	if st.Current != "003_trips" || !st.UpToDate() {
+		t.Errorf("все применены: %+v", st)
+	}
+}
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// Действия администраторов, которые записываются в журнал.
+const (
+	AuditUserSearch = "user_search" // поиск пользователей
+	AuditUserView   = "user_view"   // просмотр пользователя, его броней, маршрутов или обращений
+	AuditRoleChange = "role_change" // смена роли
+	AuditBlock      = "block"       // блокировка
+	AuditUnblock    = "unblock"     // разблокировка
+)
+
+// AuditEntry — запись журнала действий администраторов.
+type AuditEntry struct {
+	ID           int       `db:"id"`
+	ActorID      *int      `db:"actor_id"` // NULL для служебных команд
+	Action       string    `db:"action"`
+	TargetUserID *int      `db:"target_user_id"` // NULL для поиска
+	Details      string    `db:"details"`        // подробности: запрос поиска, старая и новая роль, причина блокировки
+	CreatedAt    time.Time `db:"created_at"`
+}
@@ -6,5 +6,5 @@ type Booking struct {
 	UserID     int    `db:"user_id"`     // пользователь (турист), создавший заявку
 	LocationID int    `db:"location_id"` // локация (например, жилье или тур), которую бронируют
 	Details    string `db:"details"`     // текстовые детали бронирования (даты, количество участников)
-	Status     string `db:"status"`      // статус заявки: "pending", "confirmed", "rejected"
+	Status     string `db:"status"`      // статус заявки: "pending", "confirmed", "rejected", "cancelled"
 }
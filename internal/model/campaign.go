@@ -0,0 +1,105 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Campaign представляет рассылку предложений подписчикам.
+type Campaign struct {
+	ID          int             `db:"id"`
+	AuthorID    *int            `db:"author_id"` // оператор, подготовивший рассылку
+	Text        string          `db:"text"`
+	PhotoFileID string          `db:"photo_file_id"` // (опционально) FileID фото, отправляемого вместе с текстом
+	Buttons     CampaignButtons `db:"buttons"`
+	Segment     Segment         `db:"segment"`
+	Status      string          `db:"status"`       // статус рассылки: "draft", "scheduled", "sending", "sent", "cancelled"
+	ScheduledAt *time.Time      `db:"scheduled_at"` // время запланированной отправки
+	CreatedAt   time.Time       `db:"created_at"`
+	SentAt      *time.Time      `db:"sent_at"`
+}
+
+// CampaignButton описывает inline-кнопку рассылки: ссылку или переход к карточке локации.
+type CampaignButton struct {
+	Text       string `json:"text"`
+	URL        string `json:"url,omitempty"`
+	LocationID int    `json:"location_id,omitempty"`
+}
+
+// CampaignButtons хранится в базе как JSONB-массив.
+type CampaignButtons []CampaignButton
+
+// Value реализует driver.Valuer.
+func (b CampaignButtons) Value() (driver.Value, error) {
+	if b == nil {
+		b = CampaignButtons{}
+	}
+	data, err := json.Marshal(b)
+	return string(data), err
+}
+
+// Scan реализует sql.Scanner.
+func (b *CampaignButtons) Scan(src interface{}) error {
+	return scanJSON(src, b)
+}
+
+// Segment задаёт аудиторию рассылки. Пустые поля означают отсутствие фильтра.
+type Segment struct {
+	Topics       []string `json:"topics,omitempty"`        // темы рассылки (housing, tour, festival)
+	Regions      []string `json:"regions,omitempty"`       // регионы из подписки или из маршрутов и бронирований пользователя
+	Categories   []string `json:"categories,omitempty"`    // категории локаций, которыми интересовался пользователь
+	WithBookings bool     `json:"with_bookings,omitempty"` // только пользователи, у которых были бронирования
+	Languages    []string `json:"languages,omitempty"`     // коды языков Telegram (ru, en, ...)
+}
+
+// Value реализует driver.Valuer.
+func (s Segment) Value() (driver.Value, error) {
+	data, err := json.Marshal(s)
+	return string(data), err
+}
+
+// Scan реализует sql.Scanner.
+func (s *Segment) Scan(src interface{}) error {
+	return scanJSON(src, s)
+}
+
+// String возвращает человекочитаемое описание сегмента.
+func (s Segment) String() string {
+	parts := []string{}
+	if len(s.Topics) > 0 {
+		parts = append(parts, "темы: "+strings.Join(s.Topics, ", "))
+	}
+	if len(s.Regions) > 0 {
+		parts = append(parts, "регионы: "+strings.Join(s.Regions, ", "))
+	}
+	if len(s.Categories) > 0 {
+		parts = append(parts, "категории: "+strings.Join(s.Categories, ", "))
+	}
+	if s.WithBookings {
+		parts = append(parts, "только с бронированиями")
+	}
+	if len(s.Languages) > 0 {
+		parts = append(parts, "языки: "+strings.Join(s.Languages, ", "))
+	}
+	if len(parts) == 0 {
+		return "все подписчики"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// scanJSON декодирует JSON-значение колонки в dst.
+func scanJSON(src interface{}, dst interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, dst)
+	case string:
+		return json.Unmarshal([]byte(v), dst)
+	default:
+		return fmt.Errorf("неподдерживаемый тип JSON-колонки: %T", src)
+	}
+}
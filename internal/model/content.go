@@ -0,0 +1,46 @@
+package model
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Записи каталога, которые провайдер ведет сам.
+const (
+	ContentLocation = "location"
+	ContentOffer    = "offer"
+)
+
+// Статусы изменения каталога.
+const (
+	ChangePending  = "pending"  // ожидает проверки поддержкой
+	ChangeApproved = "approved" // применено к каталогу
+	ChangeRejected = "rejected" // отклонено поддержкой
+	ChangeReplaced = "replaced" // не проверено и заменено более новым изменением той же записи
+)
+
+// ContentChange — новая локация или предложение либо новая версия существующей записи,
+// присланная провайдером. Хранит все поля записи; фото добавляются к уже имеющимся.
+type ContentChange struct {
+	ID           int            `db:"id"`
+	Entity       string         `db:"entity"`      // "location" или "offer"
+	EntityID     *int           `db:"entity_id"`   // NULL для новой записи до ее создания
+	LocationID   *int           `db:"location_id"` // локация предложения
+	AuthorID     int            `db:"author_id"`
+	Name         string         `db:"name"`
+	Description  string         `db:"description"`
+	Category     string         `db:"category"` // только для локаций
+	Region       string         `db:"region"`   // только для локаций
+	Latitude     float64        `db:"latitude"`
+	Longitude    float64        `db:"longitude"`
+	OfferType    string         `db:"offer_type"` // только для предложений: "housing", "tour"
+	Price        float64        `db:"price"`
+	Contact      string         `db:"contact"`
+	PhotoFileIDs pq.StringArray `db:"photo_file_ids"` // FileID новых фото в Telegram
+	Status       string         `db:"status"`
+	ReviewerID   *int           `db:"reviewer_id"`
+	Reason       string         `db:"reason"` // причина отклонения
+	CreatedAt    time.Time      `db:"created_at"`
+	ReviewedAt   *time.Time     `db:"reviewed_at"`
+}
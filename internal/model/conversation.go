@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx/types"
+)
+
+// ConversationState — текущий сценарий диалога пользователя с ботом (например, оформление брони)
+// и данные, накопленные на предыдущих шагах.
+type ConversationState struct {
+	TelegramID int64          `db:"telegram_id"`
+	Flow       string         `db:"flow"`       // сценарий: "booking", "review", "broadcast" и т.д.
+	Step       string         `db:"step"`       // шаг внутри сценария
+	Payload    types.JSONText `db:"payload"`    // данные сценария в JSON
+	UpdatedAt  time.Time      `db:"updated_at"` // время последнего перехода
+	ExpiresAt  *time.Time     `db:"expires_at"` // после этого момента сценарий считается брошенным; nil — без ограничения
+}
+
+// Expired сообщает, истекло ли время ожидания ответа пользователя.
+func (s *ConversationState) Expired(now time.Time) bool {
+	return s.ExpiresAt != nil && !now.Before(*s.ExpiresAt)
+}
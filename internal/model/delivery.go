@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// CampaignDelivery представляет отправку рассылки одному получателю.
+type CampaignDelivery struct {
+	ID            int        `db:"id"`
+	CampaignID    int        `db:"campaign_id"`
+	UserID        int        `db:"user_id"`
+	TelegramID    int64      `db:"telegram_id"`
+	Status        string     `db:"status"` // статус доставки: "pending", "sent", "failed", "blocked"
+	Attempts      int        `db:"attempts"`
+	NextAttemptAt time.Time  `db:"next_attempt_at"`
+	LastError     string     `db:"last_error"`
+	SentAt        *time.Time `db:"sent_at"`
+}
+
+// DeliveryStats содержит счетчики доставки рассылки.
+type DeliveryStats struct {
+	Pending int `db:"pending"`
+	Sent    int `db:"sent"`
+	Failed  int `db:"failed"`
+	Blocked int `db:"blocked"`
+}
@@ -0,0 +1,31 @@
+package model
+
+// Типы элементов дайджеста.
+const (
+	DigestItemLocation = "location"
+	DigestItemOffer    = "offer"
+)
+
+// DigestSubscriber — подписчик еженедельного дайджеста вместе с его Telegram ID и языком.
+type DigestSubscriber struct {
+	OfferSubscription
+	TelegramID   int64  `db:"telegram_id"`
+	LanguageCode string `db:"language_code"`
+	Language     string `db:"language"`
+}
+
+// PreferredLanguage возвращает язык дайджеста — так же, как User.PreferredLanguage.
+func (s *DigestSubscriber) PreferredLanguage() string {
+	return preferredLanguage(s.Language, s.LanguageCode)
+}
+
+// Digest содержит новые локации и предложения, подобранные для одного подписчика.
+type Digest struct {
+	Locations []Location
+	Offers    []Offer
+}
+
+// IsEmpty сообщает, что для подписчика нет ничего нового.
+func (d *Digest) IsEmpty() bool {
+	return len(d.Locations) == 0 && len(d.Offers) == 0
+}
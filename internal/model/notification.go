@@ -0,0 +1,61 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+// Виды уведомлений.
+const (
+	NotifyBookingCreated   = "booking_created"   // провайдеру: новая заявка на бронирование
+	NotifyBookingConfirmed = "booking_confirmed" // туристу: бронь подтверждена
+	NotifyBookingRejected  = "booking_rejected"  // туристу: бронь отклонена
+	NotifyReviewPublished  = "review_published"  // провайдеру: опубликован отзыв о его локации
+	NotifyReviewReply      = "review_reply"      // автору отзыва: провайдер ответил
+
+	NotifyTranslationApproved = "translation_approved" // автору перевода: перевод опубликован
+	NotifyTranslationRejected = "translation_rejected" // автору перевода: перевод отклонен
+
+	NotifyProviderApproved = "provider_approved" // заявителю: заявка на роль провайдера одобрена
+	NotifyProviderRejected = "provider_rejected" // заявителю: заявка на роль провайдера отклонена
+
+	NotifyContentApproved = "content_approved" // провайдеру: изменение каталога применено
+	NotifyContentRejected = "content_rejected" // провайдеру: изменение каталога отклонено
+
+	NotifyRoleChanged = "role_changed" // пользователю: администратор изменил его роль
+)
+
+// Notification — уведомление пользователю в очереди отправки (outbox). Получатель хранится
+// внутренним ID, Telegram ID определяется при отправке и сохраняется в TelegramID.
+type Notification struct {
+	ID            int                `db:"id"`
+	UserID        int                `db:"user_id"`
+	Kind          string             `db:"kind"`
+	Params        NotificationParams `db:"params"`    // данные для текста уведомления
+	DedupKey      string             `db:"dedup_key"` // уведомление с тем же ключом ставится в очередь один раз
+	Status        string             `db:"status"`    // статус: "pending", "sent", "failed", "blocked"
+	Attempts      int                `db:"attempts"`
+	NextAttemptAt time.Time          `db:"next_attempt_at"`
+	LastError     string             `db:"last_error"`
+	TelegramID    *int64             `db:"telegram_id"`
+	CreatedAt     time.Time          `db:"created_at"`
+	SentAt        *time.Time         `db:"sent_at"`
+}
+
+// NotificationParams хранится в базе как JSONB-объект.
+type NotificationParams map[string]string
+
+// Value реализует driver.Valuer.
+func (p NotificationParams) Value() (driver.Value, error) {
+	if p == nil {
+		p = NotificationParams{}
+	}
+	data, err := json.Marshal(p)
+	return string(data), err
+}
+
+// Scan реализует sql.Scanner.
+func (p *NotificationParams) Scan(src interface{}) error {
+	return scanJSON(src, p)
+}
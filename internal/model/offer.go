@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Offer представляет коммерческое предложение провайдера (жильё, тур), привязанное к локации.
+type Offer struct {
+	ID          int            `db:"id"`
+	LocationID  int            `db:"location_id"`
+	Type        string         `db:"type"` // тип предложения: "housing", "tour"
+	Name        string         `db:"name"`
+	Description string         `db:"description"`
+	Price       float64        `db:"price"`
+	Contact     string         `db:"contact"`
+	PhotoFileID string         `db:"photo_file_id"` // FileID фотографии в Telegram
+	SocialLinks pq.StringArray `db:"social_links"`
+	Hidden      bool           `db:"hidden"` // скрыто провайдером: не показывается в списках и дайджестах
+	CreatedAt   time.Time      `db:"created_at"`
+}
@@ -1,8 +1,21 @@
 package model
 
+import "time"
+
 // LocationPhoto представляет фото, связанное с определенной локацией.
 type LocationPhoto struct {
 	ID         int    `db:"id"`
 	LocationID int    `db:"location_id"`
-	FileID     string `db:"file_id"` // FileID фотографии в Telegram (для повторной отправки без загрузки)
+	FileID     string `db:"file_id"`  // FileID фотографии в Telegram (для повторной отправки без загрузки)
+	Position   int    `db:"position"` // порядок в галерее, начиная с 1
+	Caption    string `db:"caption"`
+	IsCover    bool   `db:"is_cover"` // обложка: показывается первой в карточке локации
+	// копия в хранилище файлов: оригинал и уменьшенные копии лежат под префиксом StorageKey
+	StorageKey    string     `db:"storage_key" json:"-"`
+	ContentType   string     `db:"content_type"` // тип оригинала: image/jpeg или image/png
+	Width         int        `db:"width"`
+	Height        int        `db:"height"`
+	StoredAt      *time.Time `db:"stored_at"` // nil — фото еще не скачано из Telegram
+	StoreAttempts int        `db:"store_attempts" json:"-"`
+	StoreError    string     `db:"store_error" json:"-"`
 }
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Статусы заявки на роль провайдера.
+const (
+	ApplicationPending  = "pending"
+	ApplicationApproved = "approved"
+	ApplicationRejected = "rejected"
+)
+
+// ProviderApplication — заявка пользователя на роль провайдера.
+type ProviderApplication struct {
+	ID              int            `db:"id"`
+	UserID          int            `db:"user_id"`
+	BusinessName    string         `db:"business_name"`
+	Contacts        string         `db:"contacts"`
+	DocumentFileIDs pq.StringArray `db:"document_file_ids"` // FileID фотографий документов в Telegram
+	LocationIDs     pq.Int64Array  `db:"location_ids"`      // локации каталога, которыми владеет заявитель
+	LocationsNote   string         `db:"locations_note"`    // локации, которые не удалось найти в каталоге
+	Status          string         `db:"status"`
+	ReviewerID      *int           `db:"reviewer_id"`
+	Reason          string         `db:"reason"` // причина отклонения
+	CreatedAt       time.Time      `db:"created_at"`
+	ReviewedAt      *time.Time     `db:"reviewed_at"`
+}
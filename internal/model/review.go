@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Review представляет отзыв туриста о локации.
+type Review struct {
+	ID           int            `db:"id"`
+	LocationID   int            `db:"location_id"`
+	UserID       int            `db:"user_id"`
+	Rating       int            `db:"rating"` // оценка от 1 до 5
+	Text         string         `db:"text"`
+	PhotoFileIDs pq.StringArray `db:"photo_file_ids"` // FileID фотографий в Telegram
+	Status       string         `db:"status"`         // статус отзыва: "pending", "published", "rejected"
+	Reply        string         `db:"reply"`          // публичный ответ провайдера
+	RepliedAt    *time.Time     `db:"replied_at"`
+	CreatedAt    time.Time      `db:"created_at"`
+	Flags        pq.StringArray `db:"moderation_flags"` // причины, по которым отзыв удержан на модерации
+	AuthorName   string         `db:"author_name"`      // имя автора (заполняется при выводе списка)
+}
+
+// Признаки нарушений, по которым отзыв удерживается на модерации.
+const (
+	FlagProfanity    = "profanity"     // нецензурная лексика
+	FlagLink         = "link"          // ссылки
+	FlagContacts     = "contacts"      // телефоны, e-mail, Telegram-аккаунты
+	FlagDuplicate    = "duplicate"     // текст повторяет другой отзыв
+	FlagFreshAccount = "fresh_account" // низкая оценка от недавно созданного аккаунта
+	FlagRatingBurst  = "rating_burst"  // всплеск низких оценок локации
+	FlagPhotos       = "photos"        // фото в отзыве проверяет модератор
+)
+
+// Действия журнала модерации.
+const (
+	ModerationAutoPublished = "auto_published"
+	ModerationHeld          = "held"
+	ModerationApproved      = "approved"
+	ModerationRejected      = "rejected"
+)
+
+// ModerationEntry — запись журнала модерации отзыва.
+type ModerationEntry struct {
+	ID          int       `db:"id"`
+	ReviewID    int       `db:"review_id"`
+	ModeratorID *int      `db:"moderator_id"` // NULL для автоматических решений
+	Action      string    `db:"action"`
+	Reason      string    `db:"reason"`
+	CreatedAt   time.Time `db:"created_at"`
+}
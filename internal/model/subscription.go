@@ -1,7 +1,54 @@
 package model
 
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Темы подписки (совпадают с типами предложений).
+const (
+	TopicHousing  = "housing"
+	TopicTour     = "tour"
+	TopicFestival = "festival"
+)
+
+// Topics перечисляет все темы подписки в порядке отображения.
+var Topics = []string{TopicHousing, TopicTour, TopicFestival}
+
+// Частота получения предложений.
+const (
+	FrequencyInstant = "instant" // сразу по мере появления (рассылки)
+	FrequencyWeekly  = "weekly"  // еженедельный дайджест
+)
+
 // OfferSubscription представляет подписку пользователя на рассылку предложений.
 type OfferSubscription struct {
-	ID     int `db:"id"`
-	UserID int `db:"user_id"`
+	ID           int            `db:"id"`
+	UserID       int            `db:"user_id"`
+	Topics       pq.StringArray `db:"topics"`         // темы, на которые подписан пользователь
+	Regions      pq.StringArray `db:"regions"`        // интересующие регионы; пустой список означает любые
+	Frequency    string         `db:"frequency"`      // "instant" или "weekly"
+	LastDigestAt *time.Time     `db:"last_digest_at"` // время последнего отправленного дайджеста
+	// DigestLeaseUntil — до какого момента подписчик зарезервирован за экземпляром бота, отправляющим дайджест.
+	DigestLeaseUntil *time.Time `db:"digest_lease_until"`
+}
+
+// HasTopic сообщает, подписан ли пользователь на тему.
+func (s *OfferSubscription) HasTopic(topic string) bool {
+	return contains(s.Topics, topic)
+}
+
+// HasRegion сообщает, выбран ли регион в подписке.
+func (s *OfferSubscription) HasRegion(region string) bool {
+	return contains(s.Regions, region)
+}
+
+func contains(values []string, v string) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
 }
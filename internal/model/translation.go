@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// Сущности, у которых переводится название и описание.
+const (
+	TranslationLocation = "location"
+	TranslationOffer    = "offer"
+)
+
+// Статусы перевода.
+const (
+	TranslationPending  = "pending"  // ожидает проверки поддержкой
+	TranslationApproved = "approved" // показывается пользователям
+	TranslationRejected = "rejected" // отклонен поддержкой
+	TranslationReplaced = "replaced" // был одобрен, заменен более новым переводом
+)
+
+// ContentLanguage — язык, на котором хранятся названия и описания в самих локациях и предложениях.
+const ContentLanguage = "ru"
+
+// Translation — перевод названия и описания локации или предложения на язык Locale.
+type Translation struct {
+	ID          int        `db:"id"`
+	Entity      string     `db:"entity"` // "location" или "offer"
+	EntityID    int        `db:"entity_id"`
+	Locale      string     `db:"locale"` // основной язык без региона: "en", "os"
+	Name        string     `db:"name"`
+	Description string     `db:"description"`
+	Status      string     `db:"status"`
+	AuthorID    *int       `db:"author_id"`
+	ReviewerID  *int       `db:"reviewer_id"`
+	Reason      string     `db:"reason"` // причина отклонения
+	CreatedAt   time.Time  `db:"created_at"`
+	ReviewedAt  *time.Time `db:"reviewed_at"`
+}
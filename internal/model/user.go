@@ -1,10 +1,52 @@
 package model
 
+import (
+	"strings"
+	"time"
+)
+
 type User struct {
-	ID         int    `db:"id"`
-	TelegramID int64  `db:"telegram_id"`
-	Username   string `db:"username"`
-	FirstName  string `db:"first_name"`
-	LastName   string `db:"last_name"`
-	Role       string `db:"role"`
+	ID           int       `db:"id"`
+	TelegramID   int64     `db:"telegram_id"`
+	Username     string    `db:"username"`
+	FirstName    string    `db:"first_name"`
+	LastName     string    `db:"last_name"`
+	Role         string    `db:"role"`
+	LanguageCode string    `db:"language_code"` // language_code из профиля Telegram
+	Language     string    `db:"language"`      // язык интерфейса, выбранный пользователем (/language)
+	IsActive     bool      `db:"is_active"`     // false, если пользователь заблокировал бота
+	IsBlocked    bool      `db:"is_blocked"`    // true, если пользователя заблокировал администратор
+	CreatedAt    time.Time `db:"created_at"`
+}
+
+// Roles перечисляет роли пользователей: турист, провайдер, оператор поддержки и администратор.
+var Roles = []string{"user", "provider", "support", "admin"}
+
+// IsRole сообщает, является ли role известной ролью.
+func IsRole(role string) bool {
+	for _, r := range Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// PreferredLanguage возвращает язык, на котором боты пишут пользователю: выбранный им,
+// а если он не выбран — язык профиля Telegram.
+func (u *User) PreferredLanguage() string {
+	return preferredLanguage(u.Language, u.LanguageCode)
+}
+
+// BaseLanguage возвращает основной язык кода Telegram в нижнем регистре: "en-US" -> "en".
+func BaseLanguage(code string) string {
+	base, _, _ := strings.Cut(strings.ToLower(strings.ReplaceAll(code, "_", "-")), "-")
+	return base
+}
+
+func preferredLanguage(language, languageCode string) string {
+	if language != "" {
+		return language
+	}
+	return languageCode
 }
@@ -0,0 +1,86 @@
+// Package photosync переносит фото локаций из Telegram в хранилище файлов, чтобы API
+// могло отдавать их сайту и приложениям без обращения к Bot API.
+package photosync
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"tourism/internal/service"
+)
+
+// Downloader скачивает файл из Telegram по FileID. Реализуется *telegram.Downloader.
+type Downloader interface {
+	Download(ctx context.Context, fileID string) ([]byte, error)
+}
+
+// Config задает периодичность переноса фото.
+type Config struct {
+	Interval  time.Duration // как часто искать несохраненные фото
+	BatchSize int           // сколько фото переносить за одну проверку
+}
+
+// DefaultConfig возвращает настройки по умолчанию: проверка раз в минуту, до 20 фото за раз.
+func DefaultConfig() Config {
+	return Config{Interval: time.Minute, BatchSize: 20}
+}
+
+// Job периодически скачивает новые фото галерей и сохраняет их вместе с уменьшенными копиями.
+type Job struct {
+	files Downloader
+	svc   *service.PhotoStorageService
+	cfg   Config
+}
+
+// NewJob создает задачу переноса фото.
+func NewJob(files Downloader, svc *service.PhotoStorageService, cfg Config) *Job {
+	return &Job{files: files, svc: svc, cfg: cfg}
+}
+
+// Run выполняет задачу до отмены ctx.
+func (j *Job) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		j.RunOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce переносит очередную порцию фото и возвращает, сколько сохранено. Неудачная попытка
+// записывается, и фото скачивается заново при следующей проверке, пока попытки не кончатся.
+func (j *Job) RunOnce(ctx context.Context) int {
+	photos, err := j.svc.Pending(ctx, j.cfg.BatchSize)
+	if err != nil {
+		slog.Error("Ошибка выборки фото для сохранения", "err", err)
+		return 0
+	}
+	stored := 0
+	for i := range photos {
+		photo := &photos[i]
+		data, err := j.files.Download(ctx, photo.FileID)
+		if err == nil {
+			err = j.svc.Save(ctx, photo, data)
+		}
+		if ctx.Err() != nil {
+			return stored
+		}
+		if err != nil {
+			slog.Warn("Не удалось сохранить фото", "photo_id", photo.ID, "err", err)
+			if err := j.svc.Fail(ctx, photo.ID, err); err != nil {
+				slog.Error("Ошибка записи неудачной попытки сохранения фото", "photo_id", photo.ID, "err", err)
+			}
+			continue
+		}
+		stored++
+	}
+	if stored > 0 {
+		slog.Info("Сохранены фото локаций", "count", stored)
+	}
+	return stored
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+)
+
+// AdvisoryLock — сеансовая advisory-блокировка PostgreSQL. Ее держит отдельное соединение,
+// поэтому блокировка снимается и при потере этого соединения: Check позволяет это заметить.
+type AdvisoryLock struct {
+	db   *DB
+	key  int64
+	mu   sync.Mutex
+	conn *sql.Conn
+}
+
+// NewAdvisoryLock создает блокировку с ключом key.
+func NewAdvisoryLock(db *DB, key int64) *AdvisoryLock {
+	return &AdvisoryLock{db: db, key: key}
+}
+
+// Acquire пытается взять блокировку, не дожидаясь ее. Возвращает false, если ее держит другой сеанс.
+func (l *AdvisoryLock) Acquire(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.conn != nil {
+		return true, nil
+	}
+	conn, err := l.db.db.Conn(ctx)
+	if err != nil {
+		return false, fmt.Errorf("подключение для блокировки: %w", err)
+	}
+	var held bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", l.key).Scan(&held); err != nil {
+		conn.Close()
+		return false, fmt.Errorf("не удалось взять блокировку: %w", err)
+	}
+	if !held {
+		conn.Close()
+		return false, nil
+	}
+	l.conn = conn
+	return true, nil
+}
+
+// Check проверяет, что соединение, которое держит блокировку, живо.
+func (l *AdvisoryLock) Check(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.conn == nil {
+		return fmt.Errorf("блокировка %d не взята", l.key)
+	}
+	ctx, cancel := l.db.withTimeout(ctx)
+	defer cancel()
+	if _, err := l.conn.ExecContext(ctx, "SELECT 1"); err != nil {
+		return fmt.Errorf("соединение с блокировкой %d потеряно: %w", l.key, err)
+	}
+	return nil
+}
+
+// Release снимает блокировку и закрывает ее соединение.
+func (l *AdvisoryLock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.conn == nil {
+		return nil
+	}
+	_, err := l.conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", l.key)
+	l.conn.Close()
+	l.conn = nil
+	if err != nil {
+		return fmt.Errorf("не удалось снять блокировку: %w", err)
+	}
+	return nil
+}
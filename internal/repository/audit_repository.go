@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"tourism/internal/model"
+)
+
+// AuditRepository обеспечивает доступ к журналу действий администраторов в базе данных.
+type AuditRepository struct {
+	db *DB
+}
+
+// NewAuditRepository создает новый репозиторий журнала действий администраторов.
+func NewAuditRepository(db *DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// Add записывает действие в журнал. Возвращает ID записи.
+func (r *AuditRepository) Add(ctx context.Context, e *model.AuditEntry) (int, error) {
+	var id int
+	err := r.db.Get(ctx, &id,
+		"INSERT INTO audit_log (actor_id, action, target_user_id, details) VALUES ($1, $2, $3, $4) RETURNING id",
+		e.ActorID, e.Action, e.TargetUserID, e.Details)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось записать действие в журнал: %w", err)
+	}
+	return id, nil
+}
+
+// ListRecent возвращает последние записи журнала.
+func (r *AuditRepository) ListRecent(ctx context.Context, limit int) ([]model.AuditEntry, error) {
+	entries := []model.AuditEntry{}
+	err := r.db.Select(ctx, &entries, "SELECT * FROM audit_log ORDER BY id DESC LIMIT $1", limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении журнала: %w", err)
+	}
+	return entries, nil
+}
+
+// ListByTarget возвращает последние записи журнала о действиях с пользователем userID.
+func (r *AuditRepository) ListByTarget(ctx context.Context, userID int, limit int) ([]model.AuditEntry, error) {
+	entries := []model.AuditEntry{}
+	err := r.db.Select(ctx, &entries,
+		"SELECT * FROM audit_log WHERE target_user_id=$1 ORDER BY id DESC LIMIT $2", userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении журнала пользователя: %w", err)
+	}
+	return entries, nil
+}
@@ -1,28 +1,27 @@
 package repository
 
 import (
+	"context"
 	"fmt"
 
 	"tourism/internal/model"
-
-	"github.com/jmoiron/sqlx"
 )
 
 // BookingRepository обеспечивает доступ к данным бронирований в базе данных.
 type BookingRepository struct {
-	db *sqlx.DB
+	db *DB
 }
 
 // NewBookingRepository создает новый репозиторий для бронирований.
-func NewBookingRepository(db *sqlx.DB) *BookingRepository {
+func NewBookingRepository(db *DB) *BookingRepository {
 	return &BookingRepository{db: db}
 }
 
 // Create создает новую заявку на бронирование.
-func (r *BookingRepository) Create(booking *model.Booking) (int, error) {
+func (r *BookingRepository) Create(ctx context.Context, booking *model.Booking) (int, error) {
 	query := `INSERT INTO bookings (user_id, location_id, details, status) VALUES ($1, $2, $3, $4) RETURNING id`
 	var id int
-	err := r.db.QueryRow(query, booking.UserID, booking.LocationID, booking.Details, booking.Status).Scan(&id)
+	err := r.db.Get(ctx, &id, query, booking.UserID, booking.LocationID, booking.Details, booking.Status)
 	if err != nil {
 		return 0, fmt.Errorf("не удалось создать бронирование: %w", err)
 	}
@@ -30,9 +29,19 @@ func (r *BookingRepository) Create(booking *model.Booking) (int, error) {
 }
 
 // GetByID возвращает бронирование по ID.
-func (r *BookingRepository) GetByID(id int) (*model.Booking, error) {
+func (r *BookingRepository) GetByID(ctx context.Context, id int) (*model.Booking, error) {
+	var booking model.Booking
+	err := r.db.Get(ctx, &booking, "SELECT * FROM bookings WHERE id=$1", id)
+	if err != nil {
+		return nil, err
+	}
+	return &booking, nil
+}
+
+// GetByIDForUpdate возвращает бронирование по ID и блокирует запись до конца транзакции.
+func (r *BookingRepository) GetByIDForUpdate(ctx context.Context, id int) (*model.Booking, error) {
 	var booking model.Booking
-	err := r.db.Get(&booking, "SELECT * FROM bookings WHERE id=$1", id)
+	err := r.db.Get(ctx, &booking, "SELECT * FROM bookings WHERE id=$1 FOR UPDATE", id)
 	if err != nil {
 		return nil, err
 	}
@@ -40,10 +49,35 @@ func (r *BookingRepository) GetByID(id int) (*model.Booking, error) {
 }
 
 // UpdateStatus обновляет статус бронирования.
-func (r *BookingRepository) UpdateStatus(id int, status string) error {
-	_, err := r.db.Exec("UPDATE bookings SET status=$1 WHERE id=$2", status, id)
+func (r *BookingRepository) UpdateStatus(ctx context.Context, id int, status string) error {
+	_, err := r.db.Exec(ctx, "UPDATE bookings SET status=$1 WHERE id=$2", status, id)
 	if err != nil {
 		return fmt.Errorf("не удалось обновить статус бронирования: %w", err)
 	}
 	return nil
 }
+
+// ListByProvider возвращает бронирования локаций провайдера, начиная с новых.
+func (r *BookingRepository) ListByProvider(ctx context.Context, providerID int, limit int) ([]model.Booking, error) {
+	bookings := []model.Booking{}
+	err := r.db.Select(ctx, &bookings,
+		`SELECT b.* FROM bookings b
+		 JOIN locations l ON b.location_id = l.id
+		 WHERE l.provider_id=$1
+		 ORDER BY b.id DESC
+		 LIMIT $2`, providerID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении бронирований провайдера: %w", err)
+	}
+	return bookings, nil
+}
+
+// ListByUser возвращает бронирования пользователя, начиная с новых (не более limit).
+func (r *BookingRepository) ListByUser(ctx context.Context, userID int, limit int) ([]model.Booking, error) {
+	bookings := []model.Booking{}
+	err := r.db.Select(ctx, &bookings, "SELECT * FROM bookings WHERE user_id=$1 ORDER BY id DESC LIMIT $2", userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении бронирований пользователя: %w", err)
+	}
+	return bookings, nil
+}
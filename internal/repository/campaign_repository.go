@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"tourism/internal/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// CampaignRepository обеспечивает доступ к рассылкам в базе данных.
+type CampaignRepository struct {
+	db *DB
+}
+
+// NewCampaignRepository создает новый репозиторий рассылок.
+func NewCampaignRepository(db *DB) *CampaignRepository {
+	return &CampaignRepository{db: db}
+}
+
+// Create сохраняет новую рассылку. Возвращает ID созданной записи.
+func (r *CampaignRepository) Create(ctx context.Context, c *model.Campaign) (int, error) {
+	query := `INSERT INTO campaigns (author_id, text, photo_file_id, buttons, segment, status)
+	          VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	var id int
+	err := r.db.Get(ctx, &id, query, c.AuthorID, c.Text, c.PhotoFileID, c.Buttons, c.Segment, c.Status)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось создать рассылку: %w", err)
+	}
+	return id, nil
+}
+
+// GetByID возвращает рассылку по ID.
+func (r *CampaignRepository) GetByID(ctx context.Context, id int) (*model.Campaign, error) {
+	var c model.Campaign
+	err := r.db.Get(ctx, &c, "SELECT * FROM campaigns WHERE id=$1", id)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// GetByIDForUpdate возвращает рассылку по ID и блокирует запись до конца транзакции.
+func (r *CampaignRepository) GetByIDForUpdate(ctx context.Context, id int) (*model.Campaign, error) {
+	var c model.Campaign
+	err := r.db.Get(ctx, &c, "SELECT * FROM campaigns WHERE id=$1 FOR UPDATE", id)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// UpdateSegment сохраняет аудиторию рассылки.
+func (r *CampaignRepository) UpdateSegment(ctx context.Context, id int, segment model.Segment) error {
+	_, err := r.db.Exec(ctx, "UPDATE campaigns SET segment=$1 WHERE id=$2", segment, id)
+	if err != nil {
+		return fmt.Errorf("не удалось обновить аудиторию рассылки: %w", err)
+	}
+	return nil
+}
+
+// Schedule переводит рассылку в статус "scheduled" с указанным временем отправки.
+func (r *CampaignRepository) Schedule(ctx context.Context, id int, at time.Time) error {
+	_, err := r.db.Exec(ctx, "UPDATE campaigns SET status='scheduled', scheduled_at=$1 WHERE id=$2", at, id)
+	if err != nil {
+		return fmt.Errorf("не удалось запланировать рассылку: %w", err)
+	}
+	return nil
+}
+
+// UpdateStatus обновляет статус рассылки.
+func (r *CampaignRepository) UpdateStatus(ctx context.Context, id int, status string) error {
+	_, err := r.db.Exec(ctx, "UPDATE campaigns SET status=$1 WHERE id=$2", status, id)
+	if err != nil {
+		return fmt.Errorf("не удалось обновить статус рассылки: %w", err)
+	}
+	return nil
+}
+
+// ClaimDue атомарно переводит все рассылки, время которых наступило, в статус "sending",
+// ставит в очередь доставки каждого получателя из их сегментов и возвращает эти рассылки.
+// Благодаря условию на статус одну рассылку забирает только один экземпляр бота.
+func (r *CampaignRepository) ClaimDue(ctx context.Context, now time.Time) ([]model.Campaign, error) {
+	campaigns := []model.Campaign{}
+	err := r.db.WithinTx(ctx, func(ctx context.Context) error {
+		err := r.db.Select(ctx, &campaigns,
+			`UPDATE campaigns SET status='sending'
+			 WHERE status='scheduled' AND scheduled_at <= $1
+			 RETURNING *`, now)
+		if err != nil {
+			return fmt.Errorf("ошибка при выборке запланированных рассылок: %w", err)
+		}
+		for _, c := range campaigns {
+			audience, args := segmentAudienceQuery("?, u.id, u.telegram_id", c.Segment)
+			query := sqlx.Rebind(sqlx.DOLLAR,
+				"INSERT INTO campaign_deliveries (campaign_id, user_id, telegram_id) "+audience+" ON CONFLICT DO NOTHING")
+			if _, err := r.db.Exec(ctx, query, append([]interface{}{c.ID}, args...)...); err != nil {
+				return fmt.Errorf("не удалось поставить рассылку #%d в очередь: %w", c.ID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return campaigns, nil
+}
+
+// CompleteFinished переводит в статус "sent" рассылки, у которых не осталось ожидающих доставок.
+// Возвращает ID завершенных рассылок.
+func (r *CampaignRepository) CompleteFinished(ctx context.Context) ([]int, error) {
+	ids := []int{}
+	err := r.db.Select(ctx, &ids,
+		`UPDATE campaigns c SET status='sent', sent_at=NOW()
+		 WHERE c.status='sending' AND NOT EXISTS (
+		     SELECT 1 FROM campaign_deliveries d WHERE d.campaign_id = c.id AND d.status = 'pending')
+		 RETURNING c.id`)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при завершении рассылок: %w", err)
+	}
+	return ids, nil
+}
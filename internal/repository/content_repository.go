@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"tourism/internal/model"
+
+	"github.com/lib/pq"
+)
+
+// ContentChangeRepository обеспечивает доступ к изменениям каталога от провайдеров в базе данных.
+type ContentChangeRepository struct {
+	db *DB
+}
+
+// NewContentChangeRepository создает новый репозиторий изменений каталога.
+func NewContentChangeRepository(db *DB) *ContentChangeRepository {
+	return &ContentChangeRepository{db: db}
+}
+
+// Create сохраняет новое изменение. Возвращает ID созданной записи.
+func (r *ContentChangeRepository) Create(ctx context.Context, c *model.ContentChange) (int, error) {
+	query := `INSERT INTO content_changes (entity, entity_id, location_id, author_id, name, description, category, region,
+	              latitude, longitude, offer_type, price, contact, photo_file_ids, status)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15) RETURNING id`
+	photos := c.PhotoFileIDs
+	if photos == nil {
+		photos = pq.StringArray{}
+	}
+	var id int
+	err := r.db.Get(ctx, &id, query, c.Entity, c.EntityID, c.LocationID, c.AuthorID, c.Name, c.Description, c.Category,
+		c.Region, c.Latitude, c.Longitude, c.OfferType, c.Price, c.Contact, photos, c.Status)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось сохранить изменение: %w", err)
+	}
+	return id, nil
+}
+
+// GetByID возвращает изменение по ID.
+func (r *ContentChangeRepository) GetByID(ctx context.Context, id int) (*model.ContentChange, error) {
+	var c model.ContentChange
+	err := r.db.Get(ctx, &c, "SELECT * FROM content_changes WHERE id=$1", id)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// GetByIDForUpdate возвращает изменение по ID и блокирует запись до конца транзакции.
+func (r *ContentChangeRepository) GetByIDForUpdate(ctx context.Context, id int) (*model.ContentChange, error) {
+	var c model.ContentChange
+	err := r.db.Get(ctx, &c, "SELECT * FROM content_changes WHERE id=$1 FOR UPDATE", id)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// ListByStatus возвращает изменения с указанным статусом, начиная со старых.
+func (r *ContentChangeRepository) ListByStatus(ctx context.Context, status string, limit int) ([]model.ContentChange, error) {
+	changes := []model.ContentChange{}
+	err := r.db.Select(ctx, &changes,
+		"SELECT * FROM content_changes WHERE status=$1 ORDER BY created_at, id LIMIT $2", status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении очереди изменений: %w", err)
+	}
+	return changes, nil
+}
+
+// UpdateStatus записывает решение по изменению: статус, проверяющего и причину отклонения.
+func (r *ContentChangeRepository) UpdateStatus(ctx context.Context, id int, status string, reviewerID *int, reason string) error {
+	_, err := r.db.Exec(ctx,
+		"UPDATE content_changes SET status=$1, reviewer_id=$2, reason=$3, reviewed_at=NOW() WHERE id=$4",
+		status, reviewerID, reason, id)
+	if err != nil {
+		return fmt.Errorf("не удалось обновить статус изменения: %w", err)
+	}
+	return nil
+}
+
+// SetEntity запоминает запись каталога, созданную по изменению.
+func (r *ContentChangeRepository) SetEntity(ctx context.Context, id int, entityID int) error {
+	_, err := r.db.Exec(ctx, "UPDATE content_changes SET entity_id=$1 WHERE id=$2", entityID, id)
+	if err != nil {
+		return fmt.Errorf("не удалось обновить изменение: %w", err)
+	}
+	return nil
+}
+
+// ReplacePending переводит непроверенные изменения записи каталога в статус replaced.
+func (r *ContentChangeRepository) ReplacePending(ctx context.Context, entity string, entityID int) error {
+	_, err := r.db.Exec(ctx,
+		"UPDATE content_changes SET status='replaced' WHERE entity=$1 AND entity_id=$2 AND status='pending'",
+		entity, entityID)
+	if err != nil {
+		return fmt.Errorf("не удалось заменить изменение: %w", err)
+	}
+	return nil
+}
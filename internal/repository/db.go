@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Transactor выполняет fn как единицу работы: изменения всех хранилищ, вызванных с контекстом,
+// который получает fn, фиксируются вместе или не фиксируются вовсе. Если ctx уже относится
+// к транзакции, fn выполняется в ней же.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// DB — подключение к базе данных, через которое работают репозитории. Запросы выполняются
+// в транзакции, начатой WithinTx, если она есть в контексте, и ограничены по времени:
+// сроком контекста или queryTimeout, если он наступает раньше.
+type DB struct {
+	db           *sqlx.DB
+	queryTimeout time.Duration
+}
+
+var _ Transactor = (*DB)(nil)
+
+// NewDB создает подключение для репозиториев. queryTimeout <= 0 — без собственного ограничения,
+// запросы ограничены только сроком контекста.
+func NewDB(db *sqlx.DB, queryTimeout time.Duration) *DB {
+	return &DB{db: db, queryTimeout: queryTimeout}
+}
+
+type txKey struct{}
+
+// WithinTx начинает транзакцию, выполняет в ней fn и фиксирует ее, если fn не вернула ошибку.
+func (d *DB) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return fn(ctx)
+	}
+	tx, err := d.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("не удалось зафиксировать транзакцию: %w", err)
+	}
+	return nil
+}
+
+// Get выполняет запрос и сканирует единственную строку результата в dest.
+func (d *DB) Get(ctx context.Context, dest any, query string, args ...any) error {
+	ctx, cancel := d.withTimeout(ctx)
+	defer cancel()
+	return sqlx.GetContext(ctx, d.conn(ctx), dest, query, args...)
+}
+
+// Select выполняет запрос и сканирует все строки результата в срез dest.
+func (d *DB) Select(ctx context.Context, dest any, query string, args ...any) error {
+	ctx, cancel := d.withTimeout(ctx)
+	defer cancel()
+	return sqlx.SelectContext(ctx, d.conn(ctx), dest, query, args...)
+}
+
+// Exec выполняет запрос без результата.
+func (d *DB) Exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx, cancel := d.withTimeout(ctx)
+	defer cancel()
+	return d.conn(ctx).ExecContext(ctx, query, args...)
+}
+
+// conn возвращает транзакцию из ctx или пул соединений.
+func (d *DB) conn(ctx context.Context) sqlx.ExtContext {
+	if tx, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return tx
+	}
+	return d.db
+}
+
+func (d *DB) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if d.queryTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, d.queryTimeout)
+}
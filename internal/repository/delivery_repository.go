@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"tourism/internal/model"
+)
+
+// DeliveryRepository обеспечивает доступ к очереди доставки рассылок.
+type DeliveryRepository struct {
+	db *DB
+}
+
+// NewDeliveryRepository создает новый репозиторий доставок.
+func NewDeliveryRepository(db *DB) *DeliveryRepository {
+	return &DeliveryRepository{db: db}
+}
+
+// ClaimPending забирает до limit ожидающих доставок, время которых наступило.
+// Забранные записи откладываются до leaseUntil, чтобы их не взял другой экземпляр бота;
+// если отправка не завершится (например, процесс упадет), доставка будет повторена после истечения аренды.
+func (r *DeliveryRepository) ClaimPending(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]model.CampaignDelivery, error) {
+	deliveries := []model.CampaignDelivery{}
+	err := r.db.Select(ctx, &deliveries,
+		`UPDATE campaign_deliveries SET next_attempt_at=$2
+		 WHERE id IN (
+		     SELECT id FROM campaign_deliveries
+		     WHERE status='pending' AND next_attempt_at <= $1
+		     ORDER BY next_attempt_at, id
+		     LIMIT $3
+		     FOR UPDATE SKIP LOCKED)
+		 RETURNING *`, now, leaseUntil, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при выборке очереди рассылок: %w", err)
+	}
+	return deliveries, nil
+}
+
+// MarkSent отмечает доставку как успешную.
+func (r *DeliveryRepository) MarkSent(ctx context.Context, id int) error {
+	_, err := r.db.Exec(ctx,
+		"UPDATE campaign_deliveries SET status='sent', attempts=attempts+1, sent_at=NOW(), last_error='' WHERE id=$1", id)
+	if err != nil {
+		return fmt.Errorf("не удалось обновить статус доставки: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed завершает доставку с окончательным статусом ("failed" или "blocked").
+func (r *DeliveryRepository) MarkFailed(ctx context.Context, id int, status string, reason string) error {
+	_, err := r.db.Exec(ctx,
+		"UPDATE campaign_deliveries SET status=$1, attempts=attempts+1, last_error=$2 WHERE id=$3", status, reason, id)
+	if err != nil {
+		return fmt.Errorf("не удалось обновить статус доставки: %w", err)
+	}
+	return nil
+}
+
+// Retry откладывает доставку до указанного времени. countAttempt=false используется,
+// когда отправка не выполнялась (например, из-за лимита на чат).
+func (r *DeliveryRepository) Retry(ctx context.Context, id int, at time.Time, reason string, countAttempt bool) error {
+	inc := 0
+	if countAttempt {
+		inc = 1
+	}
+	_, err := r.db.Exec(ctx,
+		"UPDATE campaign_deliveries SET next_attempt_at=$1, last_error=$2, attempts=attempts+$3 WHERE id=$4",
+		at, reason, inc, id)
+	if err != nil {
+		return fmt.Errorf("не удалось отложить доставку: %w", err)
+	}
+	return nil
+}
+
+// Stats возвращает счетчики доставки рассылки по статусам.
+func (r *DeliveryRepository) Stats(ctx context.Context, campaignID int) (*model.DeliveryStats, error) {
+	var stats model.DeliveryStats
+	err := r.db.Get(ctx, &stats,
+		`SELECT COUNT(*) FILTER (WHERE status='pending') AS pending,
+		        COUNT(*) FILTER (WHERE status='sent') AS sent,
+		        COUNT(*) FILTER (WHERE status='failed') AS failed,
+		        COUNT(*) FILTER (WHERE status='blocked') AS blocked
+		 FROM campaign_deliveries WHERE campaign_id=$1`, campaignID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении статистики рассылки: %w", err)
+	}
+	return &stats, nil
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"tourism/internal/model"
+
+	"github.com/lib/pq"
+)
+
+// DigestRepository обеспечивает подбор материалов для дайджеста и учет отправленного.
+type DigestRepository struct {
+	db *DB
+}
+
+// NewDigestRepository создает новый репозиторий дайджестов.
+func NewDigestRepository(db *DB) *DigestRepository {
+	return &DigestRepository{db: db}
+}
+
+// DueSubscribers забирает до limit активных подписчиков дайджеста, не получавших его после before,
+// и резервирует их до leaseUntil, чтобы их не взял другой экземпляр бота. Резерв снимает Record;
+// подписчик, дайджест которого не удалось отправить, возвращается в очередь после leaseUntil.
+func (r *DigestRepository) DueSubscribers(ctx context.Context, before time.Time, now time.Time, leaseUntil time.Time, limit int) ([]model.DigestSubscriber, error) {
+	subs := []model.DigestSubscriber{}
+	err := r.db.Select(ctx, &subs,
+		`WITH claimed AS (
+		     UPDATE offer_subscriptions SET digest_lease_until=$3
+		     WHERE id IN (
+		         SELECT s.id FROM offer_subscriptions s
+		         JOIN users u ON s.user_id = u.id
+		         WHERE u.is_active AND s.frequency = 'weekly'
+		           AND (s.last_digest_at IS NULL OR s.last_digest_at < $1)
+		           AND (s.digest_lease_until IS NULL OR s.digest_lease_until <= $2)
+		         ORDER BY s.last_digest_at NULLS FIRST, s.id
+		         LIMIT $4
+		         FOR UPDATE OF s SKIP LOCKED)
+		     RETURNING *)
+		 SELECT c.*, u.telegram_id, u.language_code, u.language FROM claimed c
+		 JOIN users u ON c.user_id = u.id
+		 ORDER BY c.last_digest_at NULLS FIRST, c.id`, before, now, leaseUntil, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при выборке подписчиков дайджеста: %w", err)
+	}
+	return subs, nil
+}
+
+// NewLocations возвращает видимые локации, появившиеся после since в выбранных регионах (пустой список — любые)
+// и еще не попадавшие в дайджест пользователя.
+func (r *DigestRepository) NewLocations(ctx context.Context, userID int, regions []string, since time.Time, limit int) ([]model.Location, error) {
+	locations := []model.Location{}
+	err := r.db.Select(ctx, &locations,
+		`SELECT l.* FROM locations l
+		 WHERE l.created_at > $2 AND NOT l.hidden
+		   AND (CARDINALITY($3::TEXT[]) = 0 OR l.region = ANY($3))
+		   AND NOT EXISTS (SELECT 1 FROM digest_items d
+		                   WHERE d.user_id = $1 AND d.item_type = 'location' AND d.item_id = l.id)
+		 ORDER BY l.created_at DESC
+		 LIMIT $4`, userID, since, pq.Array(regions), limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при подборе локаций для дайджеста: %w", err)
+	}
+	return locations, nil
+}
+
+// NewOffers возвращает видимые предложения выбранных тем, появившиеся после since в выбранных регионах
+// и еще не попадавшие в дайджест пользователя.
+func (r *DigestRepository) NewOffers(ctx context.Context, userID int, topics []string, regions []string, since time.Time, limit int) ([]model.Offer, error) {
+	offers := []model.Offer{}
+	err := r.db.Select(ctx, &offers,
+		`SELECT o.* FROM offers o
+		 JOIN locations l ON o.location_id = l.id
+		 WHERE o.created_at > $2 AND NOT o.hidden AND NOT l.hidden
+		   AND o.type = ANY($3)
+		   AND (CARDINALITY($4::TEXT[]) = 0 OR l.region = ANY($4))
+		   AND NOT EXISTS (SELECT 1 FROM digest_items d
+		                   WHERE d.user_id = $1 AND d.item_type = 'offer' AND d.item_id = o.id)
+		 ORDER BY o.created_at DESC
+		 LIMIT $5`, userID, since, pq.Array(topics), pq.Array(regions), limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при подборе предложений для дайджеста: %w", err)
+	}
+	return offers, nil
+}
+
+// Record сохраняет отправленные элементы дайджеста и время отправки и снимает резерв подписчика.
+func (r *DigestRepository) Record(ctx context.Context, userID int, digest *model.Digest, at time.Time) error {
+	return r.db.WithinTx(ctx, func(ctx context.Context) error {
+		insert := `INSERT INTO digest_items (user_id, item_type, item_id, sent_at) VALUES ($1, $2, $3, $4)
+		           ON CONFLICT DO NOTHING`
+		for _, l := range digest.Locations {
+			if _, err := r.db.Exec(ctx, insert, userID, model.DigestItemLocation, l.ID, at); err != nil {
+				return fmt.Errorf("не удалось сохранить элемент дайджеста: %w", err)
+			}
+		}
+		for _, o := range digest.Offers {
+			if _, err := r.db.Exec(ctx, insert, userID, model.DigestItemOffer, o.ID, at); err != nil {
+				return fmt.Errorf("не удалось сохранить элемент дайджеста: %w", err)
+			}
+		}
+		if _, err := r.db.Exec(ctx, "UPDATE offer_subscriptions SET last_digest_at=$1, digest_lease_until=NULL WHERE user_id=$2", at, userID); err != nil {
+			return fmt.Errorf("не удалось обновить время дайджеста: %w", err)
+		}
+		return nil
+	})
+}
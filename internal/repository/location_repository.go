@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -11,27 +12,40 @@ import (
 
 // LocationRepository обеспечивает доступ к данным локаций в базе данных.
 type LocationRepository struct {
-	db *sqlx.DB
+	db *DB
 }
 
 // NewLocationRepository создает новый репозиторий для локаций.
-func NewLocationRepository(db *sqlx.DB) *LocationRepository {
+func NewLocationRepository(db *DB) *LocationRepository {
 	return &LocationRepository{db: db}
 }
 
-// FindAll возвращает все локации (без фильтрации).
-func (r *LocationRepository) FindAll() ([]model.Location, error) {
+// Create сохраняет новую локацию. Возвращает ID созданной записи.
+func (r *LocationRepository) Create(ctx context.Context, loc *model.Location) (int, error) {
+	query := `INSERT INTO locations (name, description, category, region, rating, latitude, longitude, provider_id)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`
+	var id int
+	err := r.db.Get(ctx, &id, query, loc.Name, loc.Description, loc.Category, loc.Region, loc.Rating,
+		loc.Latitude, loc.Longitude, loc.ProviderID)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось создать локацию: %w", err)
+	}
+	return id, nil
+}
+
+// FindAll возвращает все видимые локации (без фильтрации).
+func (r *LocationRepository) FindAll(ctx context.Context) ([]model.Location, error) {
 	locations := []model.Location{}
-	err := r.db.Select(&locations, "SELECT * FROM locations")
+	err := r.db.Select(ctx, &locations, "SELECT * FROM locations WHERE NOT hidden")
 	if err != nil {
 		return nil, fmt.Errorf("ошибка при получении списка локаций: %w", err)
 	}
 	return locations, nil
 }
 
-// FindByFilters выполняет поиск локаций по заданным фильтрам (категория, регион, минимальный рейтинг) и ключевому слову.
-func (r *LocationRepository) FindByFilters(category string, region string, minRating float64, keyword string) ([]model.Location, error) {
-	query := "SELECT * FROM locations WHERE 1=1"
+// FindByFilters выполняет поиск видимых локаций по заданным фильтрам (категория, регион, минимальный рейтинг) и ключевому слову.
+func (r *LocationRepository) FindByFilters(ctx context.Context, category string, region string, minRating float64, keyword string) ([]model.Location, error) {
+	query := "SELECT * FROM locations WHERE NOT hidden"
 	args := []interface{}{}
 	if category != "" && strings.ToLower(category) != "any" {
 		query += " AND LOWER(category)=LOWER(?)"
@@ -52,37 +66,204 @@ func (r *LocationRepository) FindByFilters(category string, region string, minRa
 	}
 	query = sqlx.Rebind(sqlx.DOLLAR, query)
 	locations := []model.Location{}
-	if err := r.db.Select(&locations, query, args...); err != nil {
+	if err := r.db.Select(ctx, &locations, query, args...); err != nil {
 		return nil, fmt.Errorf("ошибка при поиске локаций: %w", err)
 	}
 	return locations, nil
 }
 
+// ListRegions возвращает список регионов, в которых есть видимые локации.
+func (r *LocationRepository) ListRegions(ctx context.Context) ([]string, error) {
+	regions := []string{}
+	err := r.db.Select(ctx, &regions,
+		"SELECT DISTINCT region FROM locations WHERE NOT hidden AND COALESCE(region, '') <> '' ORDER BY region")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении списка регионов: %w", err)
+	}
+	return regions, nil
+}
+
 // GetByID получает локацию по ее идентификатору.
-func (r *LocationRepository) GetByID(id int) (*model.Location, error) {
+func (r *LocationRepository) GetByID(ctx context.Context, id int) (*model.Location, error) {
 	var location model.Location
-	err := r.db.Get(&location, "SELECT * FROM locations WHERE id=$1", id)
+	err := r.db.Get(ctx, &location, "SELECT * FROM locations WHERE id=$1", id)
 	if err != nil {
 		return nil, err
 	}
 	return &location, nil
 }
 
-// AddPhoto сохраняет новый идентификатор фото, связанного с локацией.
-func (r *LocationRepository) AddPhoto(locationID int, fileID string) error {
-	_, err := r.db.Exec("INSERT INTO location_photos (location_id, file_id) VALUES ($1, $2)", locationID, fileID)
+// GetByIDForUpdate получает локацию по ID и блокирует запись до конца транзакции.
+func (r *LocationRepository) GetByIDForUpdate(ctx context.Context, id int) (*model.Location, error) {
+	var location model.Location
+	err := r.db.Get(ctx, &location, "SELECT * FROM locations WHERE id=$1 FOR UPDATE", id)
+	if err != nil {
+		return nil, err
+	}
+	return &location, nil
+}
+
+// SetProvider закрепляет локацию за провайдером; nil снимает закрепление.
+func (r *LocationRepository) SetProvider(ctx context.Context, id int, providerID *int) error {
+	_, err := r.db.Exec(ctx, "UPDATE locations SET provider_id=$1 WHERE id=$2", providerID, id)
+	if err != nil {
+		return fmt.Errorf("не удалось закрепить локацию за провайдером: %w", err)
+	}
+	return nil
+}
+
+// Update сохраняет название, описание, категорию, регион и координаты локации.
+func (r *LocationRepository) Update(ctx context.Context, loc *model.Location) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE locations SET name=$1, description=$2, category=$3, region=$4, latitude=$5, longitude=$6
+		 WHERE id=$7`, loc.Name, loc.Description, loc.Category, loc.Region, loc.Latitude, loc.Longitude, loc.ID)
+	if err != nil {
+		return fmt.Errorf("не удалось обновить локацию: %w", err)
+	}
+	return nil
+}
+
+// SetHidden скрывает локацию из поиска, каталога и дайджестов или снова показывает ее.
+func (r *LocationRepository) SetHidden(ctx context.Context, id int, hidden bool) error {
+	_, err := r.db.Exec(ctx, "UPDATE locations SET hidden=$1 WHERE id=$2", hidden, id)
 	if err != nil {
-		return fmt.Errorf("ошибка при сохранении фото локации: %w", err)
+		return fmt.Errorf("не удалось изменить видимость локации: %w", err)
 	}
 	return nil
 }
 
-// GetPhotos возвращает все сохраненные фотографии для указанной локации.
-func (r *LocationRepository) GetPhotos(locationID int) ([]model.LocationPhoto, error) {
+// ListByProvider возвращает локации провайдера, включая скрытые, в порядке ID.
+func (r *LocationRepository) ListByProvider(ctx context.Context, providerID int) ([]model.Location, error) {
+	locations := []model.Location{}
+	err := r.db.Select(ctx, &locations, "SELECT * FROM locations WHERE provider_id=$1 ORDER BY id", providerID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении локаций провайдера: %w", err)
+	}
+	return locations, nil
+}
+
+// AddPhoto сохраняет новое фото локации в конец галереи и возвращает его ID.
+// Первое фото локации становится обложкой.
+func (r *LocationRepository) AddPhoto(ctx context.Context, locationID int, fileID string, caption string) (int, error) {
+	var id int
+	err := r.db.Get(ctx, &id,
+		`INSERT INTO location_photos (location_id, file_id, caption, position, is_cover)
+		 SELECT $1::int, $2::text, $3::text, COALESCE(MAX(position), 0) + 1, NOT COALESCE(BOOL_OR(is_cover), FALSE)
+		 FROM location_photos WHERE location_id=$1
+		 RETURNING id`, locationID, fileID, caption)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при сохранении фото локации: %w", err)
+	}
+	return id, nil
+}
+
+// GetPhoto возвращает фото по ID.
+func (r *LocationRepository) GetPhoto(ctx context.Context, id int) (*model.LocationPhoto, error) {
+	var photo model.LocationPhoto
+	err := r.db.Get(ctx, &photo, "SELECT * FROM location_photos WHERE id=$1", id)
+	if err != nil {
+		return nil, err
+	}
+	return &photo, nil
+}
+
+// GetPhotos возвращает все фотографии локации в порядке галереи.
+func (r *LocationRepository) GetPhotos(ctx context.Context, locationID int) ([]model.LocationPhoto, error) {
 	photos := []model.LocationPhoto{}
-	err := r.db.Select(&photos, "SELECT * FROM location_photos WHERE location_id=$1", locationID)
+	err := r.db.Select(ctx, &photos, "SELECT * FROM location_photos WHERE location_id=$1 ORDER BY position, id", locationID)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка при получении фотографий локации: %w", err)
 	}
 	return photos, nil
 }
+
+// SetPhotoOrder нумерует фото локации в порядке photoIDs, начиная с 1.
+func (r *LocationRepository) SetPhotoOrder(ctx context.Context, locationID int, photoIDs []int) error {
+	return r.db.WithinTx(ctx, func(ctx context.Context) error {
+		for idx, id := range photoIDs {
+			_, err := r.db.Exec(ctx, "UPDATE location_photos SET position=$1 WHERE id=$2 AND location_id=$3", idx+1, id, locationID)
+			if err != nil {
+				return fmt.Errorf("не удалось изменить порядок фото: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// SetPhotoCaption меняет подпись к фото.
+func (r *LocationRepository) SetPhotoCaption(ctx context.Context, id int, caption string) error {
+	_, err := r.db.Exec(ctx, "UPDATE location_photos SET caption=$1 WHERE id=$2", caption, id)
+	if err != nil {
+		return fmt.Errorf("не удалось изменить подпись к фото: %w", err)
+	}
+	return nil
+}
+
+// SetCoverPhoto делает фото photoID обложкой локации вместо прежней.
+func (r *LocationRepository) SetCoverPhoto(ctx context.Context, locationID int, photoID int) error {
+	return r.db.WithinTx(ctx, func(ctx context.Context) error {
+		// обложка уникальна (location_photos_cover_idx), поэтому прежняя снимается отдельным запросом
+		if _, err := r.db.Exec(ctx, "UPDATE location_photos SET is_cover=FALSE WHERE location_id=$1 AND is_cover", locationID); err != nil {
+			return fmt.Errorf("не удалось изменить обложку локации: %w", err)
+		}
+		if _, err := r.db.Exec(ctx, "UPDATE location_photos SET is_cover=TRUE WHERE id=$1 AND location_id=$2", photoID, locationID); err != nil {
+			return fmt.Errorf("не удалось изменить обложку локации: %w", err)
+		}
+		return nil
+	})
+}
+
+// DeletePhoto удаляет фото.
+func (r *LocationRepository) DeletePhoto(ctx context.Context, id int) error {
+	_, err := r.db.Exec(ctx, "DELETE FROM location_photos WHERE id=$1", id)
+	if err != nil {
+		return fmt.Errorf("не удалось удалить фото: %w", err)
+	}
+	return nil
+}
+
+// ListPhotosToStore возвращает фото, еще не сохраненные в хранилище файлов, у которых было
+// меньше maxAttempts неудачных попыток, в порядке добавления.
+func (r *LocationRepository) ListPhotosToStore(ctx context.Context, maxAttempts int, limit int) ([]model.LocationPhoto, error) {
+	photos := []model.LocationPhoto{}
+	err := r.db.Select(ctx, &photos,
+		`SELECT * FROM location_photos WHERE stored_at IS NULL AND store_attempts < $1 ORDER BY id LIMIT $2`,
+		maxAttempts, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении фото для сохранения: %w", err)
+	}
+	return photos, nil
+}
+
+// MarkPhotoStored отмечает, что оригинал и уменьшенные копии фото сохранены под префиксом storageKey.
+func (r *LocationRepository) MarkPhotoStored(ctx context.Context, id int, storageKey string, contentType string, width int, height int) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE location_photos SET storage_key=$1, content_type=$2, width=$3, height=$4, stored_at=NOW(), store_error=''
+		 WHERE id=$5`, storageKey, contentType, width, height, id)
+	if err != nil {
+		return fmt.Errorf("не удалось отметить фото сохраненным: %w", err)
+	}
+	return nil
+}
+
+// MarkPhotoStoreFailed записывает неудачную попытку сохранить фото.
+func (r *LocationRepository) MarkPhotoStoreFailed(ctx context.Context, id int, reason string) error {
+	_, err := r.db.Exec(ctx, "UPDATE location_photos SET store_attempts=store_attempts+1, store_error=$1 WHERE id=$2", reason, id)
+	if err != nil {
+		return fmt.Errorf("не удалось записать ошибку сохранения фото: %w", err)
+	}
+	return nil
+}
+
+// ListWithoutPhotos возвращает видимые локации, к которым еще не добавлено ни одного фото.
+func (r *LocationRepository) ListWithoutPhotos(ctx context.Context) ([]model.Location, error) {
+	locations := []model.Location{}
+	err := r.db.Select(ctx, &locations,
+		`SELECT l.* FROM locations l
+		 WHERE NOT l.hidden AND NOT EXISTS (SELECT 1 FROM location_photos p WHERE p.location_id = l.id)
+		 ORDER BY l.id`)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении локаций без фото: %w", err)
+	}
+	return locations, nil
+}
@@ -0,0 +1,63 @@
+package memory
+
+import (
+	"context"
+
+	"tourism/internal/model"
+)
+
+// AuditRepository — журнал действий администраторов в памяти.
+type AuditRepository struct {
+	s *Store
+}
+
+// NewAuditRepository создает журнал действий администраторов поверх s.
+func NewAuditRepository(s *Store) *AuditRepository {
+	return &AuditRepository{s: s}
+}
+
+// Add записывает действие в журнал.
+func (r *AuditRepository) Add(ctx context.Context, e *model.AuditEntry) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if e.ActorID != nil && r.s.userLocked(*e.ActorID) == nil {
+		return 0, constraint("не удалось записать действие в журнал: пользователь %d не найден", *e.ActorID)
+	}
+	if e.TargetUserID != nil && r.s.userLocked(*e.TargetUserID) == nil {
+		return 0, constraint("не удалось записать действие в журнал: пользователь %d не найден", *e.TargetUserID)
+	}
+	entry := model.AuditEntry{
+		ID:           r.s.nextID("audit_log"),
+		ActorID:      cloneInt(e.ActorID),
+		Action:       e.Action,
+		TargetUserID: cloneInt(e.TargetUserID),
+		Details:      e.Details,
+		CreatedAt:    r.s.now(),
+	}
+	r.s.audit = append(r.s.audit, entry)
+	return entry.ID, nil
+}
+
+// ListRecent возвращает последние записи журнала.
+func (r *AuditRepository) ListRecent(ctx context.Context, limit int) ([]model.AuditEntry, error) {
+	return r.list(func(e *model.AuditEntry) bool { return true }, limit), nil
+}
+
+// ListByTarget возвращает последние записи журнала о действиях с пользователем userID.
+func (r *AuditRepository) ListByTarget(ctx context.Context, userID int, limit int) ([]model.AuditEntry, error) {
+	return r.list(func(e *model.AuditEntry) bool { return e.TargetUserID != nil && *e.TargetUserID == userID }, limit), nil
+}
+
+func (r *AuditRepository) list(match func(e *model.AuditEntry) bool, limit int) []model.AuditEntry {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	entries := []model.AuditEntry{}
+	for i := len(r.s.audit) - 1; i >= 0 && len(entries) < limit; i-- {
+		if e := r.s.audit[i]; match(&e) {
+			e.ActorID = cloneInt(e.ActorID)
+			e.TargetUserID = cloneInt(e.TargetUserID)
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"context"
+
+	"tourism/internal/model"
+)
+
+// BookingRepository — хранилище бронирований в памяти.
+type BookingRepository struct {
+	s *Store
+}
+
+// NewBookingRepository создает хранилище бронирований поверх s.
+func NewBookingRepository(s *Store) *BookingRepository {
+	return &BookingRepository{s: s}
+}
+
+// Create создает заявку на бронирование.
+func (r *BookingRepository) Create(ctx context.Context, booking *model.Booking) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if r.s.userLocked(booking.UserID) == nil || r.s.locationLocked(booking.LocationID) == nil {
+		return 0, constraint("не удалось создать бронирование")
+	}
+	b := *booking
+	b.ID = r.s.nextID("bookings")
+	r.s.bookings = append(r.s.bookings, b)
+	return b.ID, nil
+}
+
+// GetByID возвращает бронирование по ID.
+func (r *BookingRepository) GetByID(ctx context.Context, id int) (*model.Booking, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, b := range r.s.bookings {
+		if b.ID == id {
+			return &b, nil
+		}
+	}
+	return nil, notFound()
+}
+
+// GetByIDForUpdate возвращает бронирование по ID.
+func (r *BookingRepository) GetByIDForUpdate(ctx context.Context, id int) (*model.Booking, error) {
+	return r.GetByID(ctx, id)
+}
+
+// UpdateStatus обновляет статус бронирования.
+func (r *BookingRepository) UpdateStatus(ctx context.Context, id int, status string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for i := range r.s.bookings {
+		if r.s.bookings[i].ID == id {
+			r.s.bookings[i].Status = status
+		}
+	}
+	return nil
+}
+
+// ListByProvider возвращает бронирования локаций провайдера, начиная с новых.
+func (r *BookingRepository) ListByProvider(ctx context.Context, providerID int, limit int) ([]model.Booking, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	bookings := []model.Booking{}
+	for i := len(r.s.bookings) - 1; i >= 0 && len(bookings) < limit; i-- {
+		b := r.s.bookings[i]
+		if l := r.s.locationLocked(b.LocationID); l != nil && l.ProviderID != nil && *l.ProviderID == providerID {
+			bookings = append(bookings, b)
+		}
+	}
+	return bookings, nil
+}
+
+// ListByUser возвращает бронирования пользователя, начиная с новых.
+func (r *BookingRepository) ListByUser(ctx context.Context, userID int, limit int) ([]model.Booking, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	bookings := []model.Booking{}
+	for i := len(r.s.bookings) - 1; i >= 0 && len(bookings) < limit; i-- {
+		if r.s.bookings[i].UserID == userID {
+			bookings = append(bookings, r.s.bookings[i])
+		}
+	}
+	return bookings, nil
+}
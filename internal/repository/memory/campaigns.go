@@ -0,0 +1,175 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"tourism/internal/model"
+)
+
+// CampaignRepository — хранилище рассылок в памяти.
+type CampaignRepository struct {
+	s *Store
+}
+
+// NewCampaignRepository создает хранилище рассылок поверх s.
+func NewCampaignRepository(s *Store) *CampaignRepository {
+	return &CampaignRepository{s: s}
+}
+
+// Create добавляет рассылку.
+func (r *CampaignRepository) Create(ctx context.Context, c *model.Campaign) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	campaign := copyCampaign(c)
+	campaign.ID = r.s.nextID("campaigns")
+	if campaign.Buttons == nil {
+		campaign.Buttons = model.CampaignButtons{}
+	}
+	campaign.ScheduledAt = nil
+	campaign.SentAt = nil
+	campaign.CreatedAt = r.s.now()
+	r.s.campaigns = append(r.s.campaigns, *campaign)
+	return campaign.ID, nil
+}
+
+// GetByID возвращает рассылку по ID.
+func (r *CampaignRepository) GetByID(ctx context.Context, id int) (*model.Campaign, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if c := r.campaignLocked(id); c != nil {
+		return copyCampaign(c), nil
+	}
+	return nil, notFound()
+}
+
+// GetByIDForUpdate возвращает рассылку по ID.
+func (r *CampaignRepository) GetByIDForUpdate(ctx context.Context, id int) (*model.Campaign, error) {
+	return r.GetByID(ctx, id)
+}
+
+// UpdateSegment сохраняет аудиторию рассылки.
+func (r *CampaignRepository) UpdateSegment(ctx context.Context, id int, segment model.Segment) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if c := r.campaignLocked(id); c != nil {
+		c.Segment = copySegment(segment)
+	}
+	return nil
+}
+
+// Schedule переводит рассылку в статус "scheduled" с указанным временем отправки.
+func (r *CampaignRepository) Schedule(ctx context.Context, id int, at time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if c := r.campaignLocked(id); c != nil {
+		c.Status = "scheduled"
+		c.ScheduledAt = &at
+	}
+	return nil
+}
+
+// UpdateStatus обновляет статус рассылки.
+func (r *CampaignRepository) UpdateStatus(ctx context.Context, id int, status string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if c := r.campaignLocked(id); c != nil {
+		c.Status = status
+	}
+	return nil
+}
+
+// ClaimDue переводит наступившие рассылки в статус "sending" и ставит в очередь доставки их получателей.
+func (r *CampaignRepository) ClaimDue(ctx context.Context, now time.Time) ([]model.Campaign, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	campaigns := []model.Campaign{}
+	for i := range r.s.campaigns {
+		c := &r.s.campaigns[i]
+		if c.Status != "scheduled" || c.ScheduledAt == nil || c.ScheduledAt.After(now) {
+			continue
+		}
+		c.Status = "sending"
+		for _, u := range r.s.audienceLocked(c.Segment) {
+			if r.s.deliveryExistsLocked(c.ID, u.ID) {
+				continue
+			}
+			r.s.deliveries = append(r.s.deliveries, model.CampaignDelivery{
+				ID:            r.s.nextID("campaign_deliveries"),
+				CampaignID:    c.ID,
+				UserID:        u.ID,
+				TelegramID:    u.TelegramID,
+				Status:        "pending",
+				NextAttemptAt: r.s.now(),
+			})
+		}
+		campaigns = append(campaigns, *copyCampaign(c))
+	}
+	return campaigns, nil
+}
+
+// CompleteFinished переводит в статус "sent" рассылки без ожидающих доставок и возвращает их ID.
+func (r *CampaignRepository) CompleteFinished(ctx context.Context) ([]int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	ids := []int{}
+	for i := range r.s.campaigns {
+		c := &r.s.campaigns[i]
+		if c.Status != "sending" {
+			continue
+		}
+		pending := false
+		for _, d := range r.s.deliveries {
+			pending = pending || (d.CampaignID == c.ID && d.Status == "pending")
+		}
+		if pending {
+			continue
+		}
+		now := r.s.now()
+		c.Status = "sent"
+		c.SentAt = &now
+		ids = append(ids, c.ID)
+	}
+	return ids, nil
+}
+
+func (r *CampaignRepository) campaignLocked(id int) *model.Campaign {
+	for i := range r.s.campaigns {
+		if r.s.campaigns[i].ID == id {
+			return &r.s.campaigns[i]
+		}
+	}
+	return nil
+}
+
+func copyCampaign(c *model.Campaign) *model.Campaign {
+	out := *c
+	if c.AuthorID != nil {
+		id := *c.AuthorID
+		out.AuthorID = &id
+	}
+	if c.Buttons != nil {
+		out.Buttons = append(model.CampaignButtons{}, c.Buttons...)
+	}
+	out.Segment = copySegment(c.Segment)
+	out.ScheduledAt = cloneTime(c.ScheduledAt)
+	out.SentAt = cloneTime(c.SentAt)
+	return &out
+}
+
+// copySegment копирует сегмент так же, как его "копирует" JSONB: пустые списки пропадают (omitempty).
+func copySegment(s model.Segment) model.Segment {
+	nilIfEmpty := func(v []string) []string {
+		if len(v) == 0 {
+			return nil
+		}
+		return cloneStrings(v)
+	}
+	return model.Segment{
+		Topics:       nilIfEmpty(s.Topics),
+		Regions:      nilIfEmpty(s.Regions),
+		Categories:   nilIfEmpty(s.Categories),
+		WithBookings: s.WithBookings,
+		Languages:    nilIfEmpty(s.Languages),
+	}
+}
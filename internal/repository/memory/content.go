@@ -0,0 +1,147 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"tourism/internal/model"
+)
+
+// ContentChangeRepository — хранилище изменений каталога от провайдеров в памяти.
+type ContentChangeRepository struct {
+	s *Store
+}
+
+// NewContentChangeRepository создает хранилище изменений каталога поверх s.
+func NewContentChangeRepository(s *Store) *ContentChangeRepository {
+	return &ContentChangeRepository{s: s}
+}
+
+// Create добавляет изменение.
+func (r *ContentChangeRepository) Create(ctx context.Context, c *model.ContentChange) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if r.s.userLocked(c.AuthorID) == nil {
+		return 0, constraint("не удалось сохранить изменение: автор %d не найден", c.AuthorID)
+	}
+	if c.LocationID != nil && r.s.locationLocked(*c.LocationID) == nil {
+		return 0, constraint("не удалось сохранить изменение: локация %d не найдена", *c.LocationID)
+	}
+	change := *copyChange(c)
+	change.ID = r.s.nextID("content_changes")
+	if change.Status == "" {
+		change.Status = model.ChangePending
+	}
+	change.ReviewerID = nil
+	change.Reason = ""
+	change.CreatedAt = r.s.now()
+	change.ReviewedAt = nil
+	r.s.changes = append(r.s.changes, change)
+	return change.ID, nil
+}
+
+// GetByID возвращает изменение по ID.
+func (r *ContentChangeRepository) GetByID(ctx context.Context, id int) (*model.ContentChange, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if c := r.changeLocked(id); c != nil {
+		return copyChange(c), nil
+	}
+	return nil, notFound()
+}
+
+// GetByIDForUpdate возвращает изменение по ID.
+func (r *ContentChangeRepository) GetByIDForUpdate(ctx context.Context, id int) (*model.ContentChange, error) {
+	return r.GetByID(ctx, id)
+}
+
+// ListByStatus возвращает изменения с указанным статусом, начиная со старых.
+func (r *ContentChangeRepository) ListByStatus(ctx context.Context, status string, limit int) ([]model.ContentChange, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	changes := []model.ContentChange{}
+	for i := range r.s.changes {
+		if r.s.changes[i].Status == status {
+			changes = append(changes, *copyChange(&r.s.changes[i]))
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].CreatedAt.Before(changes[j].CreatedAt) })
+	if len(changes) > limit {
+		changes = changes[:limit]
+	}
+	return changes, nil
+}
+
+// UpdateStatus записывает решение по изменению: статус, проверяющего и причину отклонения.
+func (r *ContentChangeRepository) UpdateStatus(ctx context.Context, id int, status string, reviewerID *int, reason string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	c := r.changeLocked(id)
+	if c == nil {
+		return nil
+	}
+	if reviewerID != nil && r.s.userLocked(*reviewerID) == nil {
+		return constraint("не удалось обновить статус изменения")
+	}
+	now := r.s.now()
+	c.Status = status
+	c.ReviewerID = nil
+	if reviewerID != nil {
+		v := *reviewerID
+		c.ReviewerID = &v
+	}
+	c.Reason = reason
+	c.ReviewedAt = &now
+	return nil
+}
+
+// SetEntity запоминает запись каталога, созданную по изменению.
+func (r *ContentChangeRepository) SetEntity(ctx context.Context, id int, entityID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if c := r.changeLocked(id); c != nil {
+		c.EntityID = &entityID
+	}
+	return nil
+}
+
+// ReplacePending переводит непроверенные изменения записи каталога в статус replaced.
+func (r *ContentChangeRepository) ReplacePending(ctx context.Context, entity string, entityID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for i := range r.s.changes {
+		c := &r.s.changes[i]
+		if c.Entity == entity && c.EntityID != nil && *c.EntityID == entityID && c.Status == model.ChangePending {
+			c.Status = model.ChangeReplaced
+		}
+	}
+	return nil
+}
+
+func (r *ContentChangeRepository) changeLocked(id int) *model.ContentChange {
+	for i := range r.s.changes {
+		if r.s.changes[i].ID == id {
+			return &r.s.changes[i]
+		}
+	}
+	return nil
+}
+
+func copyChange(c *model.ContentChange) *model.ContentChange {
+	out := *c
+	if c.EntityID != nil {
+		v := *c.EntityID
+		out.EntityID = &v
+	}
+	if c.LocationID != nil {
+		v := *c.LocationID
+		out.LocationID = &v
+	}
+	if c.ReviewerID != nil {
+		v := *c.ReviewerID
+		out.ReviewerID = &v
+	}
+	out.PhotoFileIDs = cloneStrings(c.PhotoFileIDs)
+	out.ReviewedAt = cloneTime(c.ReviewedAt)
+	return &out
+}
@@ -0,0 +1,134 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"tourism/internal/model"
+)
+
+// DeliveryRepository — очередь доставки рассылок в памяти.
+type DeliveryRepository struct {
+	s *Store
+}
+
+// NewDeliveryRepository создает очередь доставки поверх s.
+func NewDeliveryRepository(s *Store) *DeliveryRepository {
+	return &DeliveryRepository{s: s}
+}
+
+// ClaimPending забирает до limit ожидающих доставок, время которых наступило, и откладывает их до leaseUntil.
+func (r *DeliveryRepository) ClaimPending(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]model.CampaignDelivery, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	due := []*model.CampaignDelivery{}
+	for i := range r.s.deliveries {
+		d := &r.s.deliveries[i]
+		if d.Status == "pending" && !d.NextAttemptAt.After(now) {
+			due = append(due, d)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		if !due[i].NextAttemptAt.Equal(due[j].NextAttemptAt) {
+			return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+		}
+		return due[i].ID < due[j].ID
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	deliveries := []model.CampaignDelivery{}
+	for _, d := range due {
+		d.NextAttemptAt = leaseUntil
+		deliveries = append(deliveries, copyDelivery(d))
+	}
+	return deliveries, nil
+}
+
+// MarkSent отмечает доставку как успешную.
+func (r *DeliveryRepository) MarkSent(ctx context.Context, id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if d := r.deliveryLocked(id); d != nil {
+		now := r.s.now()
+		d.Status = "sent"
+		d.Attempts++
+		d.SentAt = &now
+		d.LastError = ""
+	}
+	return nil
+}
+
+// MarkFailed завершает доставку с окончательным статусом ("failed" или "blocked").
+func (r *DeliveryRepository) MarkFailed(ctx context.Context, id int, status string, reason string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if d := r.deliveryLocked(id); d != nil {
+		d.Status = status
+		d.Attempts++
+		d.LastError = reason
+	}
+	return nil
+}
+
+// Retry откладывает доставку до указанного времени.
+func (r *DeliveryRepository) Retry(ctx context.Context, id int, at time.Time, reason string, countAttempt bool) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if d := r.deliveryLocked(id); d != nil {
+		d.NextAttemptAt = at
+		d.LastError = reason
+		if countAttempt {
+			d.Attempts++
+		}
+	}
+	return nil
+}
+
+// Stats возвращает счетчики доставки рассылки по статусам.
+func (r *DeliveryRepository) Stats(ctx context.Context, campaignID int) (*model.DeliveryStats, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var stats model.DeliveryStats
+	for _, d := range r.s.deliveries {
+		if d.CampaignID != campaignID {
+			continue
+		}
+		switch d.Status {
+		case "pending":
+			stats.Pending++
+		case "sent":
+			stats.Sent++
+		case "failed":
+			stats.Failed++
+		case "blocked":
+			stats.Blocked++
+		}
+	}
+	return &stats, nil
+}
+
+func (r *DeliveryRepository) deliveryLocked(id int) *model.CampaignDelivery {
+	for i := range r.s.deliveries {
+		if r.s.deliveries[i].ID == id {
+			return &r.s.deliveries[i]
+		}
+	}
+	return nil
+}
+
+func (s *Store) deliveryExistsLocked(campaignID, userID int) bool {
+	for _, d := range s.deliveries {
+		if d.CampaignID == campaignID && d.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+func copyDelivery(d *model.CampaignDelivery) model.CampaignDelivery {
+	c := *d
+	c.SentAt = cloneTime(d.SentAt)
+	return c
+}
@@ -0,0 +1,144 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"tourism/internal/model"
+)
+
+// DigestRepository — подбор и учет элементов дайджеста в памяти.
+type DigestRepository struct {
+	s *Store
+}
+
+// NewDigestRepository создает хранилище дайджестов поверх s.
+func NewDigestRepository(s *Store) *DigestRepository {
+	return &DigestRepository{s: s}
+}
+
+// DueSubscribers забирает до limit активных подписчиков дайджеста, не получавших его после before
+// (сначала тех, кто не получал дайджест ни разу), и резервирует их до leaseUntil.
+func (r *DigestRepository) DueSubscribers(ctx context.Context, before time.Time, now time.Time, leaseUntil time.Time, limit int) ([]model.DigestSubscriber, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	due := []*model.OfferSubscription{}
+	for i := range r.s.subscriptions {
+		sub := &r.s.subscriptions[i]
+		u := r.s.userLocked(sub.UserID)
+		if u == nil || !u.IsActive || sub.Frequency != model.FrequencyWeekly {
+			continue
+		}
+		if sub.LastDigestAt != nil && !sub.LastDigestAt.Before(before) {
+			continue
+		}
+		if sub.DigestLeaseUntil != nil && sub.DigestLeaseUntil.After(now) {
+			continue
+		}
+		due = append(due, sub)
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		a, b := due[i].LastDigestAt, due[j].LastDigestAt
+		switch {
+		case a == nil && b == nil:
+			return due[i].ID < due[j].ID
+		case a == nil || b == nil:
+			return a == nil
+		case !a.Equal(*b):
+			return a.Before(*b)
+		}
+		return due[i].ID < due[j].ID
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	subs := []model.DigestSubscriber{}
+	for _, sub := range due {
+		lease := leaseUntil
+		sub.DigestLeaseUntil = &lease
+		u := r.s.userLocked(sub.UserID)
+		subs = append(subs, model.DigestSubscriber{OfferSubscription: *copySubscription(sub), TelegramID: u.TelegramID,
+			LanguageCode: u.LanguageCode, Language: u.Language})
+	}
+	return subs, nil
+}
+
+// NewLocations возвращает видимые локации, появившиеся после since в выбранных регионах и еще не попадавшие в дайджест.
+func (r *DigestRepository) NewLocations(ctx context.Context, userID int, regions []string, since time.Time, limit int) ([]model.Location, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	locations := []model.Location{}
+	for _, l := range r.s.locations {
+		if l.Hidden || !l.CreatedAt.After(since) || (len(regions) > 0 && !contains(regions, l.Region)) {
+			continue
+		}
+		if _, sent := r.s.digestItems[digestItem{userID, model.DigestItemLocation, l.ID}]; sent {
+			continue
+		}
+		locations = append(locations, l)
+	}
+	sort.SliceStable(locations, func(i, j int) bool { return locations[i].CreatedAt.After(locations[j].CreatedAt) })
+	if len(locations) > limit {
+		locations = locations[:limit]
+	}
+	return locations, nil
+}
+
+// NewOffers возвращает видимые предложения выбранных тем, появившиеся после since в выбранных регионах
+// и еще не попадавшие в дайджест.
+func (r *DigestRepository) NewOffers(ctx context.Context, userID int, topics []string, regions []string, since time.Time, limit int) ([]model.Offer, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	offers := []model.Offer{}
+	for _, o := range r.s.offers {
+		l := r.s.locationLocked(o.LocationID)
+		if l == nil || l.Hidden || o.Hidden || !o.CreatedAt.After(since) || !contains(topics, o.Type) {
+			continue
+		}
+		if len(regions) > 0 && !contains(regions, l.Region) {
+			continue
+		}
+		if _, sent := r.s.digestItems[digestItem{userID, model.DigestItemOffer, o.ID}]; sent {
+			continue
+		}
+		offers = append(offers, o)
+	}
+	sort.SliceStable(offers, func(i, j int) bool { return offers[i].CreatedAt.After(offers[j].CreatedAt) })
+	if len(offers) > limit {
+		offers = offers[:limit]
+	}
+	return offers, nil
+}
+
+// Record сохраняет отправленные элементы дайджеста и время отправки и снимает резерв подписчика.
+func (r *DigestRepository) Record(ctx context.Context, userID int, digest *model.Digest, at time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, l := range digest.Locations {
+		key := digestItem{userID, model.DigestItemLocation, l.ID}
+		if _, ok := r.s.digestItems[key]; !ok {
+			r.s.digestItems[key] = at
+		}
+	}
+	for _, o := range digest.Offers {
+		key := digestItem{userID, model.DigestItemOffer, o.ID}
+		if _, ok := r.s.digestItems[key]; !ok {
+			r.s.digestItems[key] = at
+		}
+	}
+	if sub := r.s.subscriptionLocked(userID); sub != nil {
+		sub.LastDigestAt = &at
+		sub.DigestLeaseUntil = nil
+	}
+	return nil
+}
+
+func contains(values []string, v string) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,315 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+
+	"tourism/internal/model"
+)
+
+// LocationRepository — хранилище локаций в памяти.
+type LocationRepository struct {
+	s *Store
+}
+
+// NewLocationRepository создает хранилище локаций поверх s.
+func NewLocationRepository(s *Store) *LocationRepository {
+	return &LocationRepository{s: s}
+}
+
+// Create добавляет локацию.
+func (r *LocationRepository) Create(ctx context.Context, loc *model.Location) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if loc.ProviderID != nil && r.s.userLocked(*loc.ProviderID) == nil {
+		return 0, constraint("не удалось создать локацию: провайдер %d не найден", *loc.ProviderID)
+	}
+	l := *loc
+	l.ID = r.s.nextID("locations")
+	if l.ProviderID != nil {
+		id := *l.ProviderID
+		l.ProviderID = &id
+	}
+	l.CreatedAt = r.s.now()
+	r.s.locations = append(r.s.locations, l)
+	return l.ID, nil
+}
+
+// FindAll возвращает все видимые локации.
+func (r *LocationRepository) FindAll(ctx context.Context) ([]model.Location, error) {
+	return r.FindByFilters(ctx, "", "", 0, "")
+}
+
+// FindByFilters ищет видимые локации по категории, региону (без учета регистра; "any" — любые),
+// минимальному рейтингу и подстроке в названии или описании.
+func (r *LocationRepository) FindByFilters(ctx context.Context, category string, region string, minRating float64, keyword string) ([]model.Location, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	keyword = strings.ToLower(keyword)
+	locations := []model.Location{}
+	for _, l := range r.s.locations {
+		if l.Hidden {
+			continue
+		}
+		if category != "" && strings.ToLower(category) != "any" && !equalFold(l.Category, category) {
+			continue
+		}
+		if region != "" && strings.ToLower(region) != "any" && !equalFold(l.Region, region) {
+			continue
+		}
+		if minRating > 0 && l.Rating < minRating {
+			continue
+		}
+		if keyword != "" && !strings.Contains(strings.ToLower(l.Name), keyword) &&
+			!strings.Contains(strings.ToLower(l.Description), keyword) {
+			continue
+		}
+		locations = append(locations, l)
+	}
+	return locations, nil
+}
+
+// ListRegions возвращает отсортированный список непустых регионов видимых локаций.
+func (r *LocationRepository) ListRegions(ctx context.Context) ([]string, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	seen := map[string]bool{}
+	regions := []string{}
+	for _, l := range r.s.locations {
+		if !l.Hidden && l.Region != "" && !seen[l.Region] {
+			seen[l.Region] = true
+			regions = append(regions, l.Region)
+		}
+	}
+	sort.Strings(regions)
+	return regions, nil
+}
+
+// GetByID возвращает локацию по ID.
+func (r *LocationRepository) GetByID(ctx context.Context, id int) (*model.Location, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if l := r.s.locationLocked(id); l != nil {
+		c := *l
+		return &c, nil
+	}
+	return nil, notFound()
+}
+
+// GetByIDForUpdate возвращает локацию по ID.
+func (r *LocationRepository) GetByIDForUpdate(ctx context.Context, id int) (*model.Location, error) {
+	return r.GetByID(ctx, id)
+}
+
+// SetProvider закрепляет локацию за провайдером; nil снимает закрепление.
+func (r *LocationRepository) SetProvider(ctx context.Context, id int, providerID *int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if providerID != nil && r.s.userLocked(*providerID) == nil {
+		return constraint("не удалось закрепить локацию: провайдер %d не найден", *providerID)
+	}
+	l := r.s.locationLocked(id)
+	if l == nil {
+		return nil
+	}
+	l.ProviderID = nil
+	if providerID != nil {
+		v := *providerID
+		l.ProviderID = &v
+	}
+	return nil
+}
+
+// Update сохраняет название, описание, категорию, регион и координаты локации.
+func (r *LocationRepository) Update(ctx context.Context, loc *model.Location) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if l := r.s.locationLocked(loc.ID); l != nil {
+		l.Name = loc.Name
+		l.Description = loc.Description
+		l.Category = loc.Category
+		l.Region = loc.Region
+		l.Latitude = loc.Latitude
+		l.Longitude = loc.Longitude
+	}
+	return nil
+}
+
+// SetHidden скрывает локацию или снова показывает ее.
+func (r *LocationRepository) SetHidden(ctx context.Context, id int, hidden bool) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if l := r.s.locationLocked(id); l != nil {
+		l.Hidden = hidden
+	}
+	return nil
+}
+
+// ListByProvider возвращает локации провайдера, включая скрытые, в порядке ID.
+func (r *LocationRepository) ListByProvider(ctx context.Context, providerID int) ([]model.Location, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	locations := []model.Location{}
+	for _, l := range r.s.locations {
+		if l.ProviderID != nil && *l.ProviderID == providerID {
+			c := l
+			id := *l.ProviderID
+			c.ProviderID = &id
+			locations = append(locations, c)
+		}
+	}
+	return locations, nil
+}
+
+// AddPhoto добавляет фото в конец галереи локации; первое фото становится обложкой.
+func (r *LocationRepository) AddPhoto(ctx context.Context, locationID int, fileID string, caption string) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if r.s.locationLocked(locationID) == nil {
+		return 0, constraint("ошибка при сохранении фото локации: локация %d не найдена", locationID)
+	}
+	position, hasCover := 0, false
+	for _, p := range r.s.photos {
+		if p.LocationID == locationID {
+			position = max(position, p.Position)
+			hasCover = hasCover || p.IsCover
+		}
+	}
+	id := r.s.nextID("location_photos")
+	r.s.photos = append(r.s.photos, model.LocationPhoto{
+		ID:         id,
+		LocationID: locationID,
+		FileID:     fileID,
+		Position:   position + 1,
+		Caption:    caption,
+		IsCover:    !hasCover,
+	})
+	return id, nil
+}
+
+// GetPhoto возвращает фото по ID.
+func (r *LocationRepository) GetPhoto(ctx context.Context, id int) (*model.LocationPhoto, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if p := r.s.photoLocked(id); p != nil {
+		return clonePhoto(p), nil
+	}
+	return nil, notFound()
+}
+
+// GetPhotos возвращает фото локации в порядке галереи.
+func (r *LocationRepository) GetPhotos(ctx context.Context, locationID int) ([]model.LocationPhoto, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	photos := []model.LocationPhoto{}
+	for i := range r.s.photos {
+		if p := &r.s.photos[i]; p.LocationID == locationID {
+			photos = append(photos, *clonePhoto(p))
+		}
+	}
+	sort.SliceStable(photos, func(i, j int) bool { return photos[i].Position < photos[j].Position })
+	return photos, nil
+}
+
+// SetPhotoOrder нумерует фото локации в порядке photoIDs, начиная с 1.
+func (r *LocationRepository) SetPhotoOrder(ctx context.Context, locationID int, photoIDs []int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for idx, id := range photoIDs {
+		if p := r.s.photoLocked(id); p != nil && p.LocationID == locationID {
+			p.Position = idx + 1
+		}
+	}
+	return nil
+}
+
+// SetPhotoCaption меняет подпись к фото.
+func (r *LocationRepository) SetPhotoCaption(ctx context.Context, id int, caption string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if p := r.s.photoLocked(id); p != nil {
+		p.Caption = caption
+	}
+	return nil
+}
+
+// SetCoverPhoto делает фото photoID обложкой локации вместо прежней.
+func (r *LocationRepository) SetCoverPhoto(ctx context.Context, locationID int, photoID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for i := range r.s.photos {
+		if p := &r.s.photos[i]; p.LocationID == locationID {
+			p.IsCover = p.ID == photoID
+		}
+	}
+	return nil
+}
+
+// DeletePhoto удаляет фото.
+func (r *LocationRepository) DeletePhoto(ctx context.Context, id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.photos = slices.DeleteFunc(r.s.photos, func(p model.LocationPhoto) bool { return p.ID == id })
+	return nil
+}
+
+// ListPhotosToStore возвращает несохраненные фото с числом неудачных попыток меньше maxAttempts в порядке ID.
+func (r *LocationRepository) ListPhotosToStore(ctx context.Context, maxAttempts int, limit int) ([]model.LocationPhoto, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	photos := []model.LocationPhoto{}
+	for i := range r.s.photos {
+		if p := &r.s.photos[i]; p.StoredAt == nil && p.StoreAttempts < maxAttempts && len(photos) < limit {
+			photos = append(photos, *clonePhoto(p))
+		}
+	}
+	return photos, nil
+}
+
+// MarkPhotoStored отмечает фото сохраненным в хранилище файлов.
+func (r *LocationRepository) MarkPhotoStored(ctx context.Context, id int, storageKey string, contentType string, width int, height int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if p := r.s.photoLocked(id); p != nil {
+		now := r.s.now()
+		p.StorageKey, p.ContentType, p.Width, p.Height = storageKey, contentType, width, height
+		p.StoredAt, p.StoreError = &now, ""
+	}
+	return nil
+}
+
+// MarkPhotoStoreFailed записывает неудачную попытку сохранить фото.
+func (r *LocationRepository) MarkPhotoStoreFailed(ctx context.Context, id int, reason string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if p := r.s.photoLocked(id); p != nil {
+		p.StoreAttempts++
+		p.StoreError = reason
+	}
+	return nil
+}
+
+// ListWithoutPhotos возвращает видимые локации без фото в порядке ID.
+func (r *LocationRepository) ListWithoutPhotos(ctx context.Context) ([]model.Location, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	withPhoto := map[int]bool{}
+	for _, p := range r.s.photos {
+		withPhoto[p.LocationID] = true
+	}
+	locations := []model.Location{}
+	for _, l := range r.s.locations {
+		if !l.Hidden && !withPhoto[l.ID] {
+			locations = append(locations, l)
+		}
+	}
+	return locations, nil
+}
+
+func clonePhoto(p *model.LocationPhoto) *model.LocationPhoto {
+	c := *p
+	c.StoredAt = cloneTime(p.StoredAt)
+	return &c
+}
@@ -0,0 +1,32 @@
+package memory_test
+
+import (
+	"testing"
+
+	"tourism/internal/repository/memory"
+	"tourism/internal/repository/repotest"
+)
+
+func TestStores(t *testing.T) {
+	s := memory.NewStore()
+	repotest.Run(t, repotest.Stores{
+		Tx:            s,
+		Users:         memory.NewUserRepository(s),
+		Locations:     memory.NewLocationRepository(s),
+		Trips:         memory.NewTripRepository(s),
+		Bookings:      memory.NewBookingRepository(s),
+		Messages:      memory.NewMessageRepository(s),
+		Offers:        memory.NewOfferRepository(s),
+		Subscriptions: memory.NewSubscriptionRepository(s),
+		Campaigns:     memory.NewCampaignRepository(s),
+		Deliveries:    memory.NewDeliveryRepository(s),
+		Notifications: memory.NewNotificationRepository(s),
+		Digests:       memory.NewDigestRepository(s),
+		Reviews:       memory.NewReviewRepository(s),
+		Translations:  memory.NewTranslationRepository(s),
+		Applications:  memory.NewProviderApplicationRepository(s),
+		Changes:       memory.NewContentChangeRepository(s),
+		Audit:         memory.NewAuditRepository(s),
+		States:        memory.NewStateRepository(s),
+	})
+}
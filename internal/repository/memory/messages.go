@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"context"
+
+	"tourism/internal/model"
+)
+
+// MessageRepository — хранилище сообщений чатов в памяти.
+type MessageRepository struct {
+	s *Store
+}
+
+// NewMessageRepository создает хранилище сообщений поверх s.
+func NewMessageRepository(s *Store) *MessageRepository {
+	return &MessageRepository{s: s}
+}
+
+// Save сохраняет сообщение.
+func (r *MessageRepository) Save(ctx context.Context, msg *model.Message) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	m := *msg
+	m.ID = r.s.nextID("messages")
+	if m.BookingID != nil {
+		id := *m.BookingID
+		m.BookingID = &id
+	}
+	r.s.messages = append(r.s.messages, m)
+	return nil
+}
+
+// ListByBooking возвращает сообщения чата по бронированию.
+func (r *MessageRepository) ListByBooking(ctx context.Context, bookingID int) ([]model.Message, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	messages := []model.Message{}
+	for _, m := range r.s.messages {
+		if m.BookingID != nil && *m.BookingID == bookingID {
+			messages = append(messages, m)
+		}
+	}
+	return messages, nil
+}
+
+// ListSupportMessages возвращает переписку пользователя с поддержкой.
+func (r *MessageRepository) ListSupportMessages(ctx context.Context, userID int) ([]model.Message, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	messages := []model.Message{}
+	for _, m := range r.s.messages {
+		if m.IsSupport && (m.FromUserID == userID || m.ToUserID == userID) {
+			messages = append(messages, m)
+		}
+	}
+	return messages, nil
+}
@@ -0,0 +1,152 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"sort"
+	"time"
+
+	"tourism/internal/model"
+)
+
+// NotificationRepository — очередь уведомлений пользователям в памяти.
+type NotificationRepository struct {
+	s *Store
+}
+
+// NewNotificationRepository создает очередь уведомлений поверх s.
+func NewNotificationRepository(s *Store) *NotificationRepository {
+	return &NotificationRepository{s: s}
+}
+
+// Enqueue ставит уведомление в очередь. Если уведомление с тем же ключом дедупликации
+// уже есть, новое не добавляется и возвращается 0.
+func (r *NotificationRepository) Enqueue(ctx context.Context, n *model.Notification) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if r.s.userLocked(n.UserID) == nil || n.DedupKey == "" {
+		return 0, constraint("не удалось поставить уведомление в очередь")
+	}
+	for _, x := range r.s.notifications {
+		if x.DedupKey == n.DedupKey {
+			return 0, nil
+		}
+	}
+	now := r.s.now()
+	params := maps.Clone(n.Params)
+	if params == nil {
+		params = model.NotificationParams{}
+	}
+	c := model.Notification{
+		ID:            r.s.nextID("notifications"),
+		UserID:        n.UserID,
+		Kind:          n.Kind,
+		Params:        params,
+		DedupKey:      n.DedupKey,
+		Status:        "pending",
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+	r.s.notifications = append(r.s.notifications, c)
+	return c.ID, nil
+}
+
+// GetByID возвращает уведомление по ID.
+func (r *NotificationRepository) GetByID(ctx context.Context, id int) (*model.Notification, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if n := r.notificationLocked(id); n != nil {
+		c := copyNotification(n)
+		return &c, nil
+	}
+	return nil, notFound()
+}
+
+// ClaimPending забирает до limit ожидающих уведомлений, время которых наступило, и откладывает их до leaseUntil.
+func (r *NotificationRepository) ClaimPending(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]model.Notification, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	due := []*model.Notification{}
+	for i := range r.s.notifications {
+		n := &r.s.notifications[i]
+		if n.Status == "pending" && !n.NextAttemptAt.After(now) {
+			due = append(due, n)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		if !due[i].NextAttemptAt.Equal(due[j].NextAttemptAt) {
+			return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+		}
+		return due[i].ID < due[j].ID
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	notifications := []model.Notification{}
+	for _, n := range due {
+		n.NextAttemptAt = leaseUntil
+		notifications = append(notifications, copyNotification(n))
+	}
+	return notifications, nil
+}
+
+// MarkSent отмечает уведомление как доставленное на указанный Telegram ID.
+func (r *NotificationRepository) MarkSent(ctx context.Context, id int, telegramID int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if n := r.notificationLocked(id); n != nil {
+		now := r.s.now()
+		n.Status = "sent"
+		n.Attempts++
+		n.SentAt = &now
+		n.LastError = ""
+		n.TelegramID = &telegramID
+	}
+	return nil
+}
+
+// MarkFailed завершает уведомление с окончательным статусом ("failed" или "blocked").
+func (r *NotificationRepository) MarkFailed(ctx context.Context, id int, status string, reason string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if n := r.notificationLocked(id); n != nil {
+		n.Status = status
+		n.Attempts++
+		n.LastError = reason
+	}
+	return nil
+}
+
+// Retry откладывает уведомление до указанного времени.
+func (r *NotificationRepository) Retry(ctx context.Context, id int, at time.Time, reason string, countAttempt bool) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if n := r.notificationLocked(id); n != nil {
+		n.NextAttemptAt = at
+		n.LastError = reason
+		if countAttempt {
+			n.Attempts++
+		}
+	}
+	return nil
+}
+
+func (r *NotificationRepository) notificationLocked(id int) *model.Notification {
+	for i := range r.s.notifications {
+		if r.s.notifications[i].ID == id {
+			return &r.s.notifications[i]
+		}
+	}
+	return nil
+}
+
+func copyNotification(n *model.Notification) model.Notification {
+	c := *n
+	c.Params = maps.Clone(n.Params)
+	c.SentAt = cloneTime(n.SentAt)
+	if n.TelegramID != nil {
+		id := *n.TelegramID
+		c.TelegramID = &id
+	}
+	return c
+}
@@ -0,0 +1,103 @@
+package memory
+
+import (
+	"context"
+
+	"tourism/internal/model"
+
+	"github.com/lib/pq"
+)
+
+// OfferRepository — хранилище предложений провайдеров в памяти.
+type OfferRepository struct {
+	s *Store
+}
+
+// NewOfferRepository создает хранилище предложений поверх s.
+func NewOfferRepository(s *Store) *OfferRepository {
+	return &OfferRepository{s: s}
+}
+
+// Create добавляет предложение.
+func (r *OfferRepository) Create(ctx context.Context, o *model.Offer) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if r.s.locationLocked(o.LocationID) == nil {
+		return 0, constraint("не удалось создать предложение: локация %d не найдена", o.LocationID)
+	}
+	offer := *o
+	offer.ID = r.s.nextID("offers")
+	offer.SocialLinks = pq.StringArray(cloneStrings(o.SocialLinks))
+	offer.CreatedAt = r.s.now()
+	r.s.offers = append(r.s.offers, offer)
+	return offer.ID, nil
+}
+
+// ListByType возвращает видимые предложения указанного типа у видимых локаций в порядке ID.
+func (r *OfferRepository) ListByType(ctx context.Context, offerType string) ([]model.Offer, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	offers := []model.Offer{}
+	for _, o := range r.s.offers {
+		if l := r.s.locationLocked(o.LocationID); o.Type == offerType && !o.Hidden && l != nil && !l.Hidden {
+			offers = append(offers, o)
+		}
+	}
+	return offers, nil
+}
+
+// GetByID возвращает предложение по ID.
+func (r *OfferRepository) GetByID(ctx context.Context, id int) (*model.Offer, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if o := r.s.offerLocked(id); o != nil {
+		c := *o
+		c.SocialLinks = pq.StringArray(cloneStrings(o.SocialLinks))
+		return &c, nil
+	}
+	return nil, notFound()
+}
+
+// GetByIDForUpdate возвращает предложение по ID.
+func (r *OfferRepository) GetByIDForUpdate(ctx context.Context, id int) (*model.Offer, error) {
+	return r.GetByID(ctx, id)
+}
+
+// Update сохраняет тип, название, описание, цену, контакт и фото предложения.
+func (r *OfferRepository) Update(ctx context.Context, o *model.Offer) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if offer := r.s.offerLocked(o.ID); offer != nil {
+		offer.Type = o.Type
+		offer.Name = o.Name
+		offer.Description = o.Description
+		offer.Price = o.Price
+		offer.Contact = o.Contact
+		offer.PhotoFileID = o.PhotoFileID
+	}
+	return nil
+}
+
+// SetHidden скрывает предложение или снова показывает его.
+func (r *OfferRepository) SetHidden(ctx context.Context, id int, hidden bool) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if o := r.s.offerLocked(id); o != nil {
+		o.Hidden = hidden
+	}
+	return nil
+}
+
+// ListByLocation возвращает предложения локации, включая скрытые, в порядке ID.
+func (r *OfferRepository) ListByLocation(ctx context.Context, locationID int) ([]model.Offer, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	offers := []model.Offer{}
+	for _, o := range r.s.offers {
+		if o.LocationID == locationID {
+			o.SocialLinks = pq.StringArray(cloneStrings(o.SocialLinks))
+			offers = append(offers, o)
+		}
+	}
+	return offers, nil
+}
@@ -0,0 +1,145 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"tourism/internal/model"
+
+	"github.com/lib/pq"
+)
+
+// ProviderApplicationRepository — хранилище заявок на роль провайдера в памяти.
+type ProviderApplicationRepository struct {
+	s *Store
+}
+
+// NewProviderApplicationRepository создает хранилище заявок поверх s.
+func NewProviderApplicationRepository(s *Store) *ProviderApplicationRepository {
+	return &ProviderApplicationRepository{s: s}
+}
+
+// Create добавляет заявку в статусе pending. У пользователя может быть только одна заявка на рассмотрении.
+func (r *ProviderApplicationRepository) Create(ctx context.Context, a *model.ProviderApplication) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if r.s.userLocked(a.UserID) == nil {
+		return 0, constraint("не удалось сохранить заявку")
+	}
+	for _, other := range r.s.applications {
+		if other.UserID == a.UserID && other.Status == model.ApplicationPending {
+			return 0, constraint("не удалось сохранить заявку: у пользователя %d уже есть заявка на рассмотрении", a.UserID)
+		}
+	}
+	app := *copyApplication(a)
+	app.ID = r.s.nextID("provider_applications")
+	app.Status = model.ApplicationPending
+	app.ReviewerID = nil
+	app.Reason = ""
+	app.CreatedAt = r.s.now()
+	app.ReviewedAt = nil
+	r.s.applications = append(r.s.applications, app)
+	return app.ID, nil
+}
+
+// GetByID возвращает заявку по ID.
+func (r *ProviderApplicationRepository) GetByID(ctx context.Context, id int) (*model.ProviderApplication, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if a := r.applicationLocked(id); a != nil {
+		return copyApplication(a), nil
+	}
+	return nil, notFound()
+}
+
+// GetByIDForUpdate возвращает заявку по ID.
+func (r *ProviderApplicationRepository) GetByIDForUpdate(ctx context.Context, id int) (*model.ProviderApplication, error) {
+	return r.GetByID(ctx, id)
+}
+
+// GetLatestByUser возвращает последнюю заявку пользователя.
+func (r *ProviderApplicationRepository) GetLatestByUser(ctx context.Context, userID int) (*model.ProviderApplication, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var latest *model.ProviderApplication
+	for i := range r.s.applications {
+		a := &r.s.applications[i]
+		if a.UserID == userID && (latest == nil || !a.CreatedAt.Before(latest.CreatedAt)) {
+			latest = a
+		}
+	}
+	if latest == nil {
+		return nil, notFound()
+	}
+	return copyApplication(latest), nil
+}
+
+// ListByStatus возвращает заявки с указанным статусом, начиная со старых.
+func (r *ProviderApplicationRepository) ListByStatus(ctx context.Context, status string, limit int) ([]model.ProviderApplication, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	applications := []model.ProviderApplication{}
+	for i := range r.s.applications {
+		if r.s.applications[i].Status == status {
+			applications = append(applications, *copyApplication(&r.s.applications[i]))
+		}
+	}
+	sort.SliceStable(applications, func(i, j int) bool {
+		return applications[i].CreatedAt.Before(applications[j].CreatedAt)
+	})
+	if len(applications) > limit {
+		applications = applications[:limit]
+	}
+	return applications, nil
+}
+
+// UpdateStatus записывает решение по заявке: статус, проверяющего и причину отклонения.
+func (r *ProviderApplicationRepository) UpdateStatus(ctx context.Context, id int, status string, reviewerID *int, reason string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	a := r.applicationLocked(id)
+	if a == nil {
+		return nil
+	}
+	if reviewerID != nil && r.s.userLocked(*reviewerID) == nil {
+		return constraint("не удалось обновить статус заявки")
+	}
+	if status == model.ApplicationPending {
+		for _, other := range r.s.applications {
+			if other.ID != id && other.UserID == a.UserID && other.Status == model.ApplicationPending {
+				return constraint("не удалось обновить статус заявки: у пользователя %d уже есть заявка на рассмотрении", a.UserID)
+			}
+		}
+	}
+	now := r.s.now()
+	a.Status = status
+	a.ReviewerID = nil
+	if reviewerID != nil {
+		v := *reviewerID
+		a.ReviewerID = &v
+	}
+	a.Reason = reason
+	a.ReviewedAt = &now
+	return nil
+}
+
+func (r *ProviderApplicationRepository) applicationLocked(id int) *model.ProviderApplication {
+	for i := range r.s.applications {
+		if r.s.applications[i].ID == id {
+			return &r.s.applications[i]
+		}
+	}
+	return nil
+}
+
+func copyApplication(a *model.ProviderApplication) *model.ProviderApplication {
+	c := *a
+	c.DocumentFileIDs = cloneStrings(a.DocumentFileIDs)
+	c.LocationIDs = append(pq.Int64Array{}, a.LocationIDs...)
+	if a.ReviewerID != nil {
+		v := *a.ReviewerID
+		c.ReviewerID = &v
+	}
+	c.ReviewedAt = cloneTime(a.ReviewedAt)
+	return &c
+}
@@ -0,0 +1,308 @@
+package memory
+
+import (
+	"context"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"tourism/internal/model"
+
+	"github.com/lib/pq"
+)
+
+// ReviewRepository — хранилище отзывов и журнала модерации в памяти.
+type ReviewRepository struct {
+	s *Store
+}
+
+// NewReviewRepository создает хранилище отзывов поверх s.
+func NewReviewRepository(s *Store) *ReviewRepository {
+	return &ReviewRepository{s: s}
+}
+
+// Create добавляет отзыв. Пользователь может оставить только один отзыв о локации.
+func (r *ReviewRepository) Create(ctx context.Context, review *model.Review) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if r.s.userLocked(review.UserID) == nil || r.s.locationLocked(review.LocationID) == nil {
+		return 0, constraint("не удалось сохранить отзыв")
+	}
+	if review.Rating < 1 || review.Rating > 5 {
+		return 0, constraint("не удалось сохранить отзыв: оценка %d вне диапазона 1..5", review.Rating)
+	}
+	for _, rv := range r.s.reviews {
+		if rv.UserID == review.UserID && rv.LocationID == review.LocationID {
+			return 0, constraint("не удалось сохранить отзыв: пользователь %d уже оценил локацию %d",
+				review.UserID, review.LocationID)
+		}
+	}
+	rv := *copyReview(review)
+	rv.ID = r.s.nextID("reviews")
+	rv.Reply = ""
+	rv.RepliedAt = nil
+	rv.AuthorName = ""
+	rv.CreatedAt = r.s.now()
+	r.s.reviews = append(r.s.reviews, rv)
+	return rv.ID, nil
+}
+
+// GetByID возвращает отзыв по ID.
+func (r *ReviewRepository) GetByID(ctx context.Context, id int) (*model.Review, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if rv := r.reviewLocked(id); rv != nil {
+		return copyReview(rv), nil
+	}
+	return nil, notFound()
+}
+
+// GetByIDForUpdate возвращает отзыв по ID.
+func (r *ReviewRepository) GetByIDForUpdate(ctx context.Context, id int) (*model.Review, error) {
+	return r.GetByID(ctx, id)
+}
+
+// Exists проверяет, оставлял ли пользователь отзыв о локации.
+func (r *ReviewRepository) Exists(ctx context.Context, userID int, locationID int) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, rv := range r.s.reviews {
+		if rv.UserID == userID && rv.LocationID == locationID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ListPublished возвращает опубликованные отзывы о локации, начиная с новых.
+func (r *ReviewRepository) ListPublished(ctx context.Context, locationID int, limit int, offset int) ([]model.Review, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	reviews := r.listLocked(func(rv *model.Review) bool {
+		return rv.LocationID == locationID && rv.Status == "published"
+	})
+	sort.SliceStable(reviews, func(i, j int) bool { return reviews[i].CreatedAt.After(reviews[j].CreatedAt) })
+	return page(reviews, limit, offset), nil
+}
+
+// CountPublished возвращает число опубликованных отзывов о локации.
+func (r *ReviewRepository) CountPublished(ctx context.Context, locationID int) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	n := 0
+	for _, rv := range r.s.reviews {
+		if rv.LocationID == locationID && rv.Status == "published" {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// AddPhoto добавляет фото к отзыву.
+func (r *ReviewRepository) AddPhoto(ctx context.Context, id int, fileID string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if rv := r.reviewLocked(id); rv != nil {
+		rv.PhotoFileIDs = append(pq.StringArray(cloneStrings(rv.PhotoFileIDs)), fileID)
+	}
+	return nil
+}
+
+// SetReply сохраняет публичный ответ провайдера на отзыв.
+func (r *ReviewRepository) SetReply(ctx context.Context, id int, reply string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if rv := r.reviewLocked(id); rv != nil {
+		now := r.s.now()
+		rv.Reply = reply
+		rv.RepliedAt = &now
+	}
+	return nil
+}
+
+// CanReview проверяет, что у пользователя есть подтвержденное бронирование локации или завершенный маршрут с ней.
+func (r *ReviewRepository) CanReview(ctx context.Context, userID int, locationID int) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, b := range r.s.bookings {
+		if b.UserID == userID && b.LocationID == locationID && b.Status == "confirmed" {
+			return true, nil
+		}
+	}
+	for _, t := range r.s.trips {
+		if t.UserID != userID || t.Status != "completed" {
+			continue
+		}
+		for _, tl := range r.s.tripLocations {
+			if tl.TripID == t.ID && tl.LocationID == locationID {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// RecalculateRating пересчитывает рейтинг локации как среднюю оценку опубликованных отзывов
+// (с округлением до сотых, 0 — если отзывов нет).
+func (r *ReviewRepository) RecalculateRating(ctx context.Context, locationID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	l := r.s.locationLocked(locationID)
+	if l == nil {
+		return nil
+	}
+	sum, n := 0, 0
+	for _, rv := range r.s.reviews {
+		if rv.LocationID == locationID && rv.Status == "published" {
+			sum += rv.Rating
+			n++
+		}
+	}
+	l.Rating = 0
+	if n > 0 {
+		l.Rating = math.Round(float64(sum)/float64(n)*100) / 100
+	}
+	return nil
+}
+
+// UpdateStatus обновляет статус отзыва.
+func (r *ReviewRepository) UpdateStatus(ctx context.Context, id int, status string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if rv := r.reviewLocked(id); rv != nil {
+		rv.Status = status
+	}
+	return nil
+}
+
+// ListByStatus возвращает отзывы с указанным статусом, начиная со старых.
+func (r *ReviewRepository) ListByStatus(ctx context.Context, status string, limit int) ([]model.Review, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	reviews := r.listLocked(func(rv *model.Review) bool { return rv.Status == status })
+	sort.SliceStable(reviews, func(i, j int) bool { return reviews[i].CreatedAt.Before(reviews[j].CreatedAt) })
+	return page(reviews, limit, 0), nil
+}
+
+// CountByStatus возвращает число отзывов с указанным статусом.
+func (r *ReviewRepository) CountByStatus(ctx context.Context, status string) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	n := 0
+	for _, rv := range r.s.reviews {
+		if rv.Status == status {
+			n++
+		}
+	}
+	return n, nil
+}
+
+var spaces = regexp.MustCompile(`\s+`)
+
+func normalizeText(text string) string {
+	return strings.ToLower(spaces.ReplaceAllString(text, " "))
+}
+
+// DuplicateTextExists проверяет, есть ли у других пользователей отзыв с тем же текстом
+// (без учета регистра и пробелов).
+func (r *ReviewRepository) DuplicateTextExists(ctx context.Context, userID int, text string) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	text = normalizeText(text)
+	for _, rv := range r.s.reviews {
+		if rv.UserID != userID && normalizeText(rv.Text) == text {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CountLowRatings возвращает число отзывов с оценкой не выше maxRating о локации, оставленных после since.
+func (r *ReviewRepository) CountLowRatings(ctx context.Context, locationID int, maxRating int, since time.Time) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	n := 0
+	for _, rv := range r.s.reviews {
+		if rv.LocationID == locationID && rv.Rating <= maxRating && !rv.CreatedAt.Before(since) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// AddModerationEntry добавляет запись в журнал модерации.
+func (r *ReviewRepository) AddModerationEntry(ctx context.Context, entry *model.ModerationEntry) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if r.reviewLocked(entry.ReviewID) == nil {
+		return constraint("не удалось записать решение модерации: отзыв %d не найден", entry.ReviewID)
+	}
+	e := *entry
+	e.ID = r.s.nextID("review_moderation_log")
+	if entry.ModeratorID != nil {
+		id := *entry.ModeratorID
+		e.ModeratorID = &id
+	}
+	e.CreatedAt = r.s.now()
+	r.s.moderation = append(r.s.moderation, e)
+	return nil
+}
+
+// ListModerationEntries возвращает журнал модерации отзыва.
+func (r *ReviewRepository) ListModerationEntries(ctx context.Context, reviewID int) ([]model.ModerationEntry, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	entries := []model.ModerationEntry{}
+	for _, e := range r.s.moderation {
+		if e.ReviewID == reviewID {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+func (r *ReviewRepository) reviewLocked(id int) *model.Review {
+	for i := range r.s.reviews {
+		if r.s.reviews[i].ID == id {
+			return &r.s.reviews[i]
+		}
+	}
+	return nil
+}
+
+// listLocked возвращает копии подходящих отзывов с именем автора, как в JOIN с users.
+func (r *ReviewRepository) listLocked(match func(*model.Review) bool) []model.Review {
+	reviews := []model.Review{}
+	for i := range r.s.reviews {
+		if !match(&r.s.reviews[i]) {
+			continue
+		}
+		rv := copyReview(&r.s.reviews[i])
+		if u := r.s.userLocked(rv.UserID); u != nil {
+			rv.AuthorName = u.FirstName
+		}
+		reviews = append(reviews, *rv)
+	}
+	return reviews
+}
+
+func copyReview(rv *model.Review) *model.Review {
+	c := *rv
+	c.PhotoFileIDs = pq.StringArray(cloneStrings(rv.PhotoFileIDs))
+	c.Flags = pq.StringArray(cloneStrings(rv.Flags))
+	c.RepliedAt = cloneTime(rv.RepliedAt)
+	return &c
+}
+
+func page(reviews []model.Review, limit, offset int) []model.Review {
+	if offset >= len(reviews) {
+		return []model.Review{}
+	}
+	reviews = reviews[offset:]
+	if len(reviews) > limit {
+		reviews = reviews[:limit]
+	}
+	return reviews
+}
@@ -0,0 +1,73 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"tourism/internal/model"
+
+	"github.com/jmoiron/sqlx/types"
+)
+
+// StateRepository — хранилище состояния диалогов бота в памяти.
+type StateRepository struct {
+	s *Store
+}
+
+// NewStateRepository создает хранилище состояний диалогов поверх s.
+func NewStateRepository(s *Store) *StateRepository {
+	return &StateRepository{s: s}
+}
+
+// Get возвращает состояние диалога пользователя или nil, если диалог не начат.
+func (r *StateRepository) Get(ctx context.Context, telegramID int64) (*model.ConversationState, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	state, ok := r.s.states[telegramID]
+	if !ok {
+		return nil, nil
+	}
+	return copyState(&state), nil
+}
+
+// Save сохраняет состояние диалога, заменяя предыдущее.
+func (r *StateRepository) Save(ctx context.Context, state *model.ConversationState) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	st := copyState(state)
+	if len(st.Payload) == 0 {
+		st.Payload = types.JSONText("{}")
+	}
+	st.UpdatedAt = r.s.now()
+	r.s.states[state.TelegramID] = *st
+	return nil
+}
+
+// Delete удаляет состояние диалога пользователя.
+func (r *StateRepository) Delete(ctx context.Context, telegramID int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	delete(r.s.states, telegramID)
+	return nil
+}
+
+// DeleteExpired удаляет состояния, время ожидания которых истекло к моменту now.
+func (r *StateRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var n int64
+	for id, st := range r.s.states {
+		if st.ExpiresAt != nil && !st.ExpiresAt.After(now) {
+			delete(r.s.states, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func copyState(st *model.ConversationState) *model.ConversationState {
+	c := *st
+	c.Payload = append(types.JSONText(nil), st.Payload...)
+	c.ExpiresAt = cloneTime(st.ExpiresAt)
+	return &c
+}
@@ -0,0 +1,258 @@
+// Package memory содержит хранилища в памяти с тем же поведением, что и репозитории PostgreSQL
+// из пакета repository. Они нужны для проверки сервисов и сценариев ботов без базы данных.
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"tourism/internal/model"
+	"tourism/internal/repository"
+)
+
+// ErrConstraint возвращается при нарушении ограничений, которые в PostgreSQL обеспечивает схема:
+// уникальность и ссылки на несуществующие записи.
+var ErrConstraint = errors.New("нарушено ограничение целостности")
+
+// Store — "база данных" в памяти: все таблицы под одной блокировкой, чтобы запросы, затрагивающие
+// несколько таблиц (аудитория рассылки, право на отзыв), видели согласованные данные.
+// Хранилища отдельных сущностей создаются поверх одного Store, как репозитории поверх одного *sqlx.DB.
+// Транзакции выполняются по одной, поэтому методы GetByIDForUpdate не блокируют запись отдельно,
+// а просто читают ее, как GetByID.
+type Store struct {
+	mu   sync.Mutex
+	txMu sync.Mutex // транзакции выполняются по одной
+	now  func() time.Time
+
+	users         []model.User
+	locations     []model.Location
+	photos        []model.LocationPhoto
+	trips         []model.Trip
+	tripLocations []model.TripLocation
+	bookings      []model.Booking
+	messages      []model.Message
+	offers        []model.Offer
+	subscriptions []model.OfferSubscription
+	campaigns     []model.Campaign
+	deliveries    []model.CampaignDelivery
+	notifications []model.Notification
+	digestItems   map[digestItem]time.Time
+	reviews       []model.Review
+	moderation    []model.ModerationEntry
+	translations  []model.Translation
+	applications  []model.ProviderApplication
+	changes       []model.ContentChange
+	audit         []model.AuditEntry
+	states        map[int64]model.ConversationState
+
+	seq map[string]int
+}
+
+var (
+	_ repository.Transactor               = (*Store)(nil)
+	_ repository.UserStore                = (*UserRepository)(nil)
+	_ repository.LocationStore            = (*LocationRepository)(nil)
+	_ repository.TripStore                = (*TripRepository)(nil)
+	_ repository.BookingStore             = (*BookingRepository)(nil)
+	_ repository.MessageStore             = (*MessageRepository)(nil)
+	_ repository.OfferStore               = (*OfferRepository)(nil)
+	_ repository.SubscriptionStore        = (*SubscriptionRepository)(nil)
+	_ repository.CampaignStore            = (*CampaignRepository)(nil)
+	_ repository.DeliveryStore            = (*DeliveryRepository)(nil)
+	_ repository.NotificationStore        = (*NotificationRepository)(nil)
+	_ repository.DigestStore              = (*DigestRepository)(nil)
+	_ repository.ReviewStore              = (*ReviewRepository)(nil)
+	_ repository.TranslationStore         = (*TranslationRepository)(nil)
+	_ repository.ProviderApplicationStore = (*ProviderApplicationRepository)(nil)
+	_ repository.ContentChangeStore       = (*ContentChangeRepository)(nil)
+	_ repository.AuditStore               = (*AuditRepository)(nil)
+	_ repository.StateStore               = (*StateRepository)(nil)
+)
+
+type digestItem struct {
+	userID   int
+	itemType string
+	itemID   int
+}
+
+// NewStore создает пустое хранилище.
+func NewStore() *Store {
+	return &Store{
+		now:         time.Now,
+		digestItems: make(map[digestItem]time.Time),
+		states:      make(map[int64]model.ConversationState),
+		seq:         make(map[string]int),
+	}
+}
+
+// SetClock подменяет источник текущего времени (для значений по умолчанию вроде created_at).
+func (s *Store) SetClock(now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = now
+}
+
+type txKey struct{}
+
+// WithinTx выполняет fn как транзакцию: если fn вернула ошибку, все таблицы возвращаются
+// к состоянию до ее начала. Транзакции выполняются по одной; изменения, сделанные во время
+// транзакции без нее, при откате тоже отменяются — для проверок без базы данных этого достаточно.
+func (s *Store) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if tx, ok := ctx.Value(txKey{}).(*Store); ok && tx == s {
+		return fn(ctx)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.txMu.Lock()
+	defer s.txMu.Unlock()
+	s.mu.Lock()
+	saved := s.snapshotLocked()
+	s.mu.Unlock()
+	if err := fn(context.WithValue(ctx, txKey{}, s)); err != nil {
+		s.mu.Lock()
+		s.restoreLocked(saved)
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// snapshotLocked копирует все таблицы. Записи копируются по значению: хранилища заменяют
+// вложенные срезы целиком, а не меняют их элементы.
+func (s *Store) snapshotLocked() *Store {
+	return &Store{
+		users:         slices.Clone(s.users),
+		locations:     slices.Clone(s.locations),
+		photos:        slices.Clone(s.photos),
+		trips:         slices.Clone(s.trips),
+		tripLocations: slices.Clone(s.tripLocations),
+		bookings:      slices.Clone(s.bookings),
+		messages:      slices.Clone(s.messages),
+		offers:        slices.Clone(s.offers),
+		subscriptions: slices.Clone(s.subscriptions),
+		campaigns:     slices.Clone(s.campaigns),
+		deliveries:    slices.Clone(s.deliveries),
+		notifications: slices.Clone(s.notifications),
+		digestItems:   maps.Clone(s.digestItems),
+		reviews:       slices.Clone(s.reviews),
+		moderation:    slices.Clone(s.moderation),
+		translations:  slices.Clone(s.translations),
+		applications:  slices.Clone(s.applications),
+		changes:       slices.Clone(s.changes),
+		audit:         slices.Clone(s.audit),
+		states:        maps.Clone(s.states),
+		seq:           maps.Clone(s.seq),
+	}
+}
+
+func (s *Store) restoreLocked(saved *Store) {
+	s.users = saved.users
+	s.locations = saved.locations
+	s.photos = saved.photos
+	s.trips = saved.trips
+	s.tripLocations = saved.tripLocations
+	s.bookings = saved.bookings
+	s.messages = saved.messages
+	s.offers = saved.offers
+	s.subscriptions = saved.subscriptions
+	s.campaigns = saved.campaigns
+	s.deliveries = saved.deliveries
+	s.notifications = saved.notifications
+	s.digestItems = saved.digestItems
+	s.reviews = saved.reviews
+	s.moderation = saved.moderation
+	s.translations = saved.translations
+	s.applications = saved.applications
+	s.changes = saved.changes
+	s.audit = saved.audit
+	s.states = saved.states
+	s.seq = saved.seq
+}
+
+// nextID выдает следующий идентификатор таблицы, как SERIAL.
+func (s *Store) nextID(table string) int {
+	s.seq[table]++
+	return s.seq[table]
+}
+
+func notFound() error {
+	return sql.ErrNoRows
+}
+
+func constraint(format string, args ...interface{}) error {
+	return fmt.Errorf("%s: %w", fmt.Sprintf(format, args...), ErrConstraint)
+}
+
+func (s *Store) userLocked(id int) *model.User {
+	for i := range s.users {
+		if s.users[i].ID == id {
+			return &s.users[i]
+		}
+	}
+	return nil
+}
+
+func (s *Store) locationLocked(id int) *model.Location {
+	for i := range s.locations {
+		if s.locations[i].ID == id {
+			return &s.locations[i]
+		}
+	}
+	return nil
+}
+
+func (s *Store) photoLocked(id int) *model.LocationPhoto {
+	for i := range s.photos {
+		if s.photos[i].ID == id {
+			return &s.photos[i]
+		}
+	}
+	return nil
+}
+
+func (s *Store) offerLocked(id int) *model.Offer {
+	for i := range s.offers {
+		if s.offers[i].ID == id {
+			return &s.offers[i]
+		}
+	}
+	return nil
+}
+
+// cloneStrings копирует срез, чтобы вызывающий код не менял данные хранилища. nil становится пустым срезом,
+// как значение по умолчанию '{}' у колонок-массивов.
+func cloneStrings(in []string) []string {
+	return append([]string{}, in...)
+}
+
+func cloneInt(v *int) *int {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	return &c
+}
+
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
+
+func containsFold(values []string, v string) bool {
+	for _, x := range values {
+		if equalFold(x, v) {
+			return true
+		}
+	}
+	return false
+}
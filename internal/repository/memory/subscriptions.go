@@ -0,0 +1,226 @@
+package memory
+
+import (
+	"context"
+
+	"tourism/internal/model"
+
+	"github.com/lib/pq"
+)
+
+// SubscriptionRepository — хранилище подписок на предложения в памяти.
+type SubscriptionRepository struct {
+	s *Store
+}
+
+// NewSubscriptionRepository создает хранилище подписок поверх s.
+func NewSubscriptionRepository(s *Store) *SubscriptionRepository {
+	return &SubscriptionRepository{s: s}
+}
+
+// Subscribe подписывает пользователя с настройками по умолчанию (если еще не подписан).
+func (r *SubscriptionRepository) Subscribe(ctx context.Context, userID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if r.s.subscriptionLocked(userID) != nil {
+		return nil
+	}
+	if r.s.userLocked(userID) == nil {
+		return constraint("не удалось оформить подписку: пользователь %d не найден", userID)
+	}
+	r.s.subscriptions = append(r.s.subscriptions, model.OfferSubscription{
+		ID:        r.s.nextID("offer_subscriptions"),
+		UserID:    userID,
+		Topics:    pq.StringArray(cloneStrings(model.Topics)),
+		Regions:   pq.StringArray{},
+		Frequency: model.FrequencyInstant,
+	})
+	return nil
+}
+
+// Unsubscribe удаляет подписку пользователя.
+func (r *SubscriptionRepository) Unsubscribe(ctx context.Context, userID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	subs := r.s.subscriptions[:0]
+	for _, sub := range r.s.subscriptions {
+		if sub.UserID != userID {
+			subs = append(subs, sub)
+		}
+	}
+	r.s.subscriptions = subs
+	return nil
+}
+
+// GetByUserID возвращает подписку пользователя.
+func (r *SubscriptionRepository) GetByUserID(ctx context.Context, userID int) (*model.OfferSubscription, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if sub := r.s.subscriptionLocked(userID); sub != nil {
+		return copySubscription(sub), nil
+	}
+	return nil, notFound()
+}
+
+// SavePreferences сохраняет темы, регионы и частоту подписки (создает подписку, если ее нет).
+func (r *SubscriptionRepository) SavePreferences(ctx context.Context, sub *model.OfferSubscription) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	existing := r.s.subscriptionLocked(sub.UserID)
+	if existing == nil {
+		if r.s.userLocked(sub.UserID) == nil {
+			return constraint("не удалось сохранить настройки подписки: пользователь %d не найден", sub.UserID)
+		}
+		r.s.subscriptions = append(r.s.subscriptions, model.OfferSubscription{
+			ID:     r.s.nextID("offer_subscriptions"),
+			UserID: sub.UserID,
+		})
+		existing = &r.s.subscriptions[len(r.s.subscriptions)-1]
+	}
+	existing.Topics = pq.StringArray(cloneStrings(sub.Topics))
+	existing.Regions = pq.StringArray(cloneStrings(sub.Regions))
+	existing.Frequency = sub.Frequency
+	return nil
+}
+
+// GetAllSubscriberTelegramIDs возвращает Telegram ID всех активных подписчиков.
+func (r *SubscriptionRepository) GetAllSubscriberTelegramIDs(ctx context.Context) ([]int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	ids := []int64{}
+	for _, sub := range r.s.subscriptions {
+		if u := r.s.userLocked(sub.UserID); u != nil && u.IsActive {
+			ids = append(ids, u.TelegramID)
+		}
+	}
+	return ids, nil
+}
+
+// GetSubscriberTelegramIDsBySegment возвращает Telegram ID активных подписчиков, попадающих в сегмент.
+func (r *SubscriptionRepository) GetSubscriberTelegramIDsBySegment(ctx context.Context, segment model.Segment) ([]int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	ids := []int64{}
+	for _, u := range r.s.audienceLocked(segment) {
+		ids = append(ids, u.TelegramID)
+	}
+	return ids, nil
+}
+
+func (s *Store) subscriptionLocked(userID int) *model.OfferSubscription {
+	for i := range s.subscriptions {
+		if s.subscriptions[i].UserID == userID {
+			return &s.subscriptions[i]
+		}
+	}
+	return nil
+}
+
+func copySubscription(sub *model.OfferSubscription) *model.OfferSubscription {
+	c := *sub
+	c.Topics = pq.StringArray(cloneStrings(sub.Topics))
+	c.Regions = pq.StringArray(cloneStrings(sub.Regions))
+	c.LastDigestAt = cloneTime(sub.LastDigestAt)
+	c.DigestLeaseUntil = cloneTime(sub.DigestLeaseUntil)
+	return &c
+}
+
+// audienceLocked повторяет выборку segmentAudienceQuery: активные подписчики с частотой "instant",
+// отфильтрованные по темам, регионам, категориям, наличию бронирований и языку.
+func (s *Store) audienceLocked(segment model.Segment) []model.User {
+	users := []model.User{}
+	for _, sub := range s.subscriptions {
+		u := s.userLocked(sub.UserID)
+		if u == nil || !u.IsActive || sub.Frequency != model.FrequencyInstant {
+			continue
+		}
+		if len(segment.Topics) > 0 && !overlaps(sub.Topics, segment.Topics) {
+			continue
+		}
+		interests := s.interestLocationsLocked(u.ID)
+		if len(segment.Regions) > 0 {
+			match := overlapsFold(sub.Regions, segment.Regions)
+			for _, l := range interests {
+				match = match || containsFold(segment.Regions, l.Region)
+			}
+			if !match {
+				continue
+			}
+		}
+		if len(segment.Categories) > 0 {
+			match := false
+			for _, l := range interests {
+				match = match || containsFold(segment.Categories, l.Category)
+			}
+			if !match {
+				continue
+			}
+		}
+		if segment.WithBookings && !s.hasBookingsLocked(u.ID) {
+			continue
+		}
+		if len(segment.Languages) > 0 {
+			if !containsFold(segment.Languages, model.BaseLanguage(u.PreferredLanguage())) {
+				continue
+			}
+		}
+		users = append(users, *u)
+	}
+	return users
+}
+
+// interestLocationsLocked возвращает локации из бронирований и маршрутов пользователя.
+func (s *Store) interestLocationsLocked(userID int) []model.Location {
+	ids := map[int]bool{}
+	for _, b := range s.bookings {
+		if b.UserID == userID {
+			ids[b.LocationID] = true
+		}
+	}
+	for _, t := range s.trips {
+		if t.UserID != userID {
+			continue
+		}
+		for _, tl := range s.tripLocations {
+			if tl.TripID == t.ID {
+				ids[tl.LocationID] = true
+			}
+		}
+	}
+	locations := []model.Location{}
+	for _, l := range s.locations {
+		if ids[l.ID] {
+			locations = append(locations, l)
+		}
+	}
+	return locations
+}
+
+func (s *Store) hasBookingsLocked(userID int) bool {
+	for _, b := range s.bookings {
+		if b.UserID == userID && b.Status != "rejected" {
+			return true
+		}
+	}
+	return false
+}
+
+func overlaps(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func overlapsFold(a, b []string) bool {
+	for _, x := range a {
+		if containsFold(b, x) {
+			return true
+		}
+	}
+	return false
+}
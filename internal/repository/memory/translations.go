@@ -0,0 +1,171 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"tourism/internal/model"
+)
+
+// TranslationRepository — хранилище переводов в памяти.
+type TranslationRepository struct {
+	s *Store
+}
+
+// NewTranslationRepository создает хранилище переводов поверх s.
+func NewTranslationRepository(s *Store) *TranslationRepository {
+	return &TranslationRepository{s: s}
+}
+
+// Create добавляет перевод. Одобренный перевод записи на язык может быть только один.
+func (r *TranslationRepository) Create(ctx context.Context, t *model.Translation) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if t.Entity != model.TranslationLocation && t.Entity != model.TranslationOffer {
+		return 0, constraint("не удалось сохранить перевод: неизвестная сущность %q", t.Entity)
+	}
+	if t.AuthorID != nil && r.s.userLocked(*t.AuthorID) == nil {
+		return 0, constraint("не удалось сохранить перевод")
+	}
+	status := t.Status
+	if status == "" {
+		status = model.TranslationPending
+	}
+	if status == model.TranslationApproved && r.approvedLocked(t.Entity, t.EntityID, t.Locale) != nil {
+		return 0, constraint("не удалось сохранить перевод: уже есть одобренный перевод")
+	}
+	tr := *copyTranslation(t)
+	tr.ID = r.s.nextID("translations")
+	tr.Status = status
+	tr.ReviewerID = nil
+	tr.Reason = ""
+	tr.CreatedAt = r.s.now()
+	tr.ReviewedAt = nil
+	r.s.translations = append(r.s.translations, tr)
+	return tr.ID, nil
+}
+
+// GetByID возвращает перевод по ID.
+func (r *TranslationRepository) GetByID(ctx context.Context, id int) (*model.Translation, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if t := r.translationLocked(id); t != nil {
+		return copyTranslation(t), nil
+	}
+	return nil, notFound()
+}
+
+// GetByIDForUpdate возвращает перевод по ID.
+func (r *TranslationRepository) GetByIDForUpdate(ctx context.Context, id int) (*model.Translation, error) {
+	return r.GetByID(ctx, id)
+}
+
+// ListApproved возвращает одобренные переводы на язык locale для записей entityIDs.
+func (r *TranslationRepository) ListApproved(ctx context.Context, entity string, entityIDs []int, locale string) ([]model.Translation, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	ids := make(map[int]bool, len(entityIDs))
+	for _, id := range entityIDs {
+		ids[id] = true
+	}
+	translations := []model.Translation{}
+	for i := range r.s.translations {
+		t := &r.s.translations[i]
+		if t.Entity == entity && ids[t.EntityID] && t.Locale == locale && t.Status == model.TranslationApproved {
+			translations = append(translations, *copyTranslation(t))
+		}
+	}
+	sort.SliceStable(translations, func(i, j int) bool { return translations[i].EntityID < translations[j].EntityID })
+	return translations, nil
+}
+
+// ListByStatus возвращает переводы с указанным статусом, начиная со старых.
+func (r *TranslationRepository) ListByStatus(ctx context.Context, status string, limit int) ([]model.Translation, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	translations := []model.Translation{}
+	for i := range r.s.translations {
+		if r.s.translations[i].Status == status {
+			translations = append(translations, *copyTranslation(&r.s.translations[i]))
+		}
+	}
+	sort.SliceStable(translations, func(i, j int) bool {
+		return translations[i].CreatedAt.Before(translations[j].CreatedAt)
+	})
+	if len(translations) > limit {
+		translations = translations[:limit]
+	}
+	return translations, nil
+}
+
+// UpdateStatus записывает решение по переводу: статус, проверяющего и причину отклонения.
+func (r *TranslationRepository) UpdateStatus(ctx context.Context, id int, status string, reviewerID *int, reason string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	t := r.translationLocked(id)
+	if t == nil {
+		return nil
+	}
+	if reviewerID != nil && r.s.userLocked(*reviewerID) == nil {
+		return constraint("не удалось обновить статус перевода")
+	}
+	if status == model.TranslationApproved {
+		if other := r.approvedLocked(t.Entity, t.EntityID, t.Locale); other != nil && other.ID != id {
+			return constraint("не удалось обновить статус перевода: уже есть одобренный перевод")
+		}
+	}
+	now := r.s.now()
+	t.Status = status
+	if reviewerID != nil {
+		v := *reviewerID
+		t.ReviewerID = &v
+	} else {
+		t.ReviewerID = nil
+	}
+	t.Reason = reason
+	t.ReviewedAt = &now
+	return nil
+}
+
+// ReplaceApproved переводит действующий одобренный перевод записи на язык locale в статус replaced.
+func (r *TranslationRepository) ReplaceApproved(ctx context.Context, entity string, entityID int, locale string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if t := r.approvedLocked(entity, entityID, locale); t != nil {
+		t.Status = model.TranslationReplaced
+	}
+	return nil
+}
+
+func (r *TranslationRepository) translationLocked(id int) *model.Translation {
+	for i := range r.s.translations {
+		if r.s.translations[i].ID == id {
+			return &r.s.translations[i]
+		}
+	}
+	return nil
+}
+
+func (r *TranslationRepository) approvedLocked(entity string, entityID int, locale string) *model.Translation {
+	for i := range r.s.translations {
+		t := &r.s.translations[i]
+		if t.Entity == entity && t.EntityID == entityID && t.Locale == locale && t.Status == model.TranslationApproved {
+			return t
+		}
+	}
+	return nil
+}
+
+func copyTranslation(t *model.Translation) *model.Translation {
+	c := *t
+	if t.AuthorID != nil {
+		v := *t.AuthorID
+		c.AuthorID = &v
+	}
+	if t.ReviewerID != nil {
+		v := *t.ReviewerID
+		c.ReviewerID = &v
+	}
+	c.ReviewedAt = cloneTime(t.ReviewedAt)
+	return &c
+}
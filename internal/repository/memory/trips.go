@@ -0,0 +1,147 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"tourism/internal/model"
+)
+
+// TripRepository — хранилище маршрутов в памяти.
+type TripRepository struct {
+	s *Store
+}
+
+// NewTripRepository создает хранилище маршрутов поверх s.
+func NewTripRepository(s *Store) *TripRepository {
+	return &TripRepository{s: s}
+}
+
+// Create создает черновик маршрута пользователя.
+func (r *TripRepository) Create(ctx context.Context, userID int, name string) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if r.s.userLocked(userID) == nil {
+		return 0, constraint("не удалось создать поездку: пользователь %d не найден", userID)
+	}
+	t := model.Trip{ID: r.s.nextID("trips"), UserID: userID, Name: name, Status: "draft"}
+	r.s.trips = append(r.s.trips, t)
+	return t.ID, nil
+}
+
+// AddLocation добавляет локацию в конец маршрута.
+func (r *TripRepository) AddLocation(ctx context.Context, tripID int, locationID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if !r.tripExistsLocked(tripID) || r.s.locationLocked(locationID) == nil {
+		return constraint("ошибка при добавлении локации в маршрут %d", tripID)
+	}
+	order := 0
+	for _, tl := range r.s.tripLocations {
+		if tl.TripID == tripID && tl.Order > order {
+			order = tl.Order
+		}
+	}
+	r.s.tripLocations = append(r.s.tripLocations, model.TripLocation{
+		ID:         r.s.nextID("trip_locations"),
+		TripID:     tripID,
+		LocationID: locationID,
+		Order:      order + 1,
+	})
+	return nil
+}
+
+// UpdateOrder задает порядок локаций маршрута.
+func (r *TripRepository) UpdateOrder(ctx context.Context, tripID int, locationOrder []int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for idx, locID := range locationOrder {
+		for i := range r.s.tripLocations {
+			tl := &r.s.tripLocations[i]
+			if tl.TripID == tripID && tl.LocationID == locID {
+				tl.Order = idx + 1
+			}
+		}
+	}
+	return nil
+}
+
+// GetLocations возвращает локации маршрута в текущем порядке.
+func (r *TripRepository) GetLocations(ctx context.Context, tripID int) ([]model.Location, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	entries := []model.TripLocation{}
+	for _, tl := range r.s.tripLocations {
+		if tl.TripID == tripID {
+			entries = append(entries, tl)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Order < entries[j].Order })
+	locations := []model.Location{}
+	for _, tl := range entries {
+		if l := r.s.locationLocked(tl.LocationID); l != nil {
+			locations = append(locations, *l)
+		}
+	}
+	return locations, nil
+}
+
+// GetActive возвращает последний черновик маршрута пользователя.
+func (r *TripRepository) GetActive(ctx context.Context, userID int) (*model.Trip, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for i := len(r.s.trips) - 1; i >= 0; i-- {
+		if t := r.s.trips[i]; t.UserID == userID && t.Status == "draft" {
+			return &t, nil
+		}
+	}
+	return nil, notFound()
+}
+
+// GetByID возвращает маршрут по ID.
+func (r *TripRepository) GetByID(ctx context.Context, id int) (*model.Trip, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, t := range r.s.trips {
+		if t.ID == id {
+			return &t, nil
+		}
+	}
+	return nil, notFound()
+}
+
+func (r *TripRepository) tripExistsLocked(id int) bool {
+	for _, t := range r.s.trips {
+		if t.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// ListByUser возвращает маршруты пользователя, начиная с новых.
+func (r *TripRepository) ListByUser(ctx context.Context, userID int, limit int) ([]model.Trip, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	trips := []model.Trip{}
+	for i := len(r.s.trips) - 1; i >= 0 && len(trips) < limit; i-- {
+		if r.s.trips[i].UserID == userID {
+			trips = append(trips, r.s.trips[i])
+		}
+	}
+	return trips, nil
+}
+
+// Complete переводит черновик маршрута в статус completed. Возвращает false, если маршрут
+// уже завершен или не найден.
+func (r *TripRepository) Complete(ctx context.Context, id int) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for i := range r.s.trips {
+		if t := &r.s.trips[i]; t.ID == id && t.Status == "draft" {
+			t.Status = "completed"
+			return true, nil
+		}
+	}
+	return false, nil
+}
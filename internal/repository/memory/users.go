@@ -0,0 +1,154 @@
+package memory
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"tourism/internal/model"
+)
+
+// UserRepository — хранилище пользователей в памяти.
+type UserRepository struct {
+	s *Store
+}
+
+// NewUserRepository создает хранилище пользователей поверх s.
+func NewUserRepository(s *Store) *UserRepository {
+	return &UserRepository{s: s}
+}
+
+// Create добавляет пользователя. Как и в БД, пользователь создается активным.
+func (r *UserRepository) Create(ctx context.Context, user *model.User) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, u := range r.s.users {
+		if u.TelegramID == user.TelegramID {
+			return 0, constraint("не удалось создать пользователя: telegram_id %d уже занят", user.TelegramID)
+		}
+	}
+	u := *user
+	u.ID = r.s.nextID("users")
+	u.IsActive = true
+	u.CreatedAt = r.s.now()
+	r.s.users = append(r.s.users, u)
+	return u.ID, nil
+}
+
+// GetByTelegramID ищет пользователя по Telegram ID.
+func (r *UserRepository) GetByTelegramID(ctx context.Context, telegramID int64) (*model.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, u := range r.s.users {
+		if u.TelegramID == telegramID {
+			return &u, nil
+		}
+	}
+	return nil, notFound()
+}
+
+// GetByID возвращает пользователя по ID.
+func (r *UserRepository) GetByID(ctx context.Context, id int) (*model.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if u := r.s.userLocked(id); u != nil {
+		c := *u
+		return &c, nil
+	}
+	return nil, notFound()
+}
+
+// GetByIDForUpdate возвращает пользователя по ID.
+func (r *UserRepository) GetByIDForUpdate(ctx context.Context, id int) (*model.User, error) {
+	return r.GetByID(ctx, id)
+}
+
+// UpdateLanguageCode сохраняет язык пользователя.
+func (r *UserRepository) UpdateLanguageCode(ctx context.Context, id int, languageCode string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if u := r.s.userLocked(id); u != nil {
+		u.LanguageCode = languageCode
+	}
+	return nil
+}
+
+// UpdateLanguage сохраняет язык интерфейса пользователя.
+func (r *UserRepository) UpdateLanguage(ctx context.Context, id int, language string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if u := r.s.userLocked(id); u != nil {
+		u.Language = language
+	}
+	return nil
+}
+
+// UpdateRole меняет роль пользователя.
+func (r *UserRepository) UpdateRole(ctx context.Context, id int, role string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if u := r.s.userLocked(id); u != nil {
+		u.Role = role
+	}
+	return nil
+}
+
+// SetActive помечает пользователя активным или неактивным.
+func (r *UserRepository) SetActive(ctx context.Context, id int, active bool) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if u := r.s.userLocked(id); u != nil {
+		u.IsActive = active
+	}
+	return nil
+}
+
+// SetBlocked блокирует пользователя или снимает блокировку.
+func (r *UserRepository) SetBlocked(ctx context.Context, id int, blocked bool) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if u := r.s.userLocked(id); u != nil {
+		u.IsBlocked = blocked
+	}
+	return nil
+}
+
+// ListByRole возвращает пользователей с ролью role в порядке ID.
+func (r *UserRepository) ListByRole(ctx context.Context, role string) ([]model.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	users := []model.User{}
+	for _, u := range r.s.users {
+		if u.Role == role {
+			users = append(users, u)
+		}
+	}
+	return users, nil
+}
+
+// Search ищет пользователей по ID, Telegram ID, username, имени или фамилии в порядке ID.
+func (r *UserRepository) Search(ctx context.Context, query string, limit int) ([]model.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	query = strings.TrimPrefix(strings.TrimSpace(query), "@")
+	users := []model.User{}
+	if query == "" {
+		return users, nil
+	}
+	q := strings.ToLower(query)
+	for _, u := range r.s.users {
+		if len(users) >= limit {
+			break
+		}
+		if strconv.Itoa(u.ID) == query || strconv.FormatInt(u.TelegramID, 10) == query ||
+			strings.Contains(strings.ToLower(u.Username), q) || strings.Contains(strings.ToLower(u.FirstName), q) ||
+			strings.Contains(strings.ToLower(u.LastName), q) {
+			users = append(users, u)
+		}
+	}
+	return users, nil
+}
+
+func equalFold(a, b string) bool {
+	return strings.ToLower(a) == strings.ToLower(b)
+}
@@ -1,26 +1,25 @@
 package repository
 
 import (
+	"context"
 	"fmt"
 
 	"tourism/internal/model"
-
-	"github.com/jmoiron/sqlx"
 )
 
 // MessageRepository обеспечивает сохранение и получение сообщений чата из базы данных.
 type MessageRepository struct {
-	db *sqlx.DB
+	db *DB
 }
 
 // NewMessageRepository создает новый репозиторий сообщений.
-func NewMessageRepository(db *sqlx.DB) *MessageRepository {
+func NewMessageRepository(db *DB) *MessageRepository {
 	return &MessageRepository{db: db}
 }
 
 // Save сохраняет новое сообщение чата.
-func (r *MessageRepository) Save(msg *model.Message) error {
-	_, err := r.db.Exec(`INSERT INTO messages (from_user_id, to_user_id, booking_id, content, is_support)
+func (r *MessageRepository) Save(ctx context.Context, msg *model.Message) error {
+	_, err := r.db.Exec(ctx, `INSERT INTO messages (from_user_id, to_user_id, booking_id, content, is_support)
 	                      VALUES ($1, $2, $3, $4, $5)`,
 		msg.FromUserID, msg.ToUserID, msg.BookingID, msg.Content, msg.IsSupport)
 	if err != nil {
@@ -30,9 +29,9 @@ func (r *MessageRepository) Save(msg *model.Message) error {
 }
 
 // ListByBooking получает все сообщения для заданного бронирования (чат турист-провайдер).
-func (r *MessageRepository) ListByBooking(bookingID int) ([]model.Message, error) {
+func (r *MessageRepository) ListByBooking(ctx context.Context, bookingID int) ([]model.Message, error) {
 	messages := []model.Message{}
-	err := r.db.Select(&messages, "SELECT * FROM messages WHERE booking_id=$1 ORDER BY id", bookingID)
+	err := r.db.Select(ctx, &messages, "SELECT * FROM messages WHERE booking_id=$1 ORDER BY id", bookingID)
 	if err != nil {
 		return nil, err
 	}
@@ -40,9 +39,9 @@ func (r *MessageRepository) ListByBooking(bookingID int) ([]model.Message, error
 }
 
 // ListSupportMessages получает все сообщения чата поддержки (по пользователю).
-func (r *MessageRepository) ListSupportMessages(userID int) ([]model.Message, error) {
+func (r *MessageRepository) ListSupportMessages(ctx context.Context, userID int) ([]model.Message, error) {
 	messages := []model.Message{}
-	err := r.db.Select(&messages, "SELECT * FROM messages WHERE is_support=true AND (from_user_id=$1 OR to_user_id=$1) ORDER BY id", userID)
+	err := r.db.Select(ctx, &messages, "SELECT * FROM messages WHERE is_support=true AND (from_user_id=$1 OR to_user_id=$1) ORDER BY id", userID)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"tourism/internal/model"
+)
+
+// NotificationRepository обеспечивает доступ к очереди уведомлений пользователям.
+type NotificationRepository struct {
+	db *DB
+}
+
+// NewNotificationRepository создает новый репозиторий уведомлений.
+func NewNotificationRepository(db *DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// Enqueue ставит уведомление в очередь и возвращает его ID. Если уведомление с тем же
+// ключом дедупликации уже есть, новое не добавляется и возвращается 0.
+func (r *NotificationRepository) Enqueue(ctx context.Context, n *model.Notification) (int, error) {
+	query := `INSERT INTO notifications (user_id, kind, params, dedup_key)
+	          VALUES ($1, $2, $3, $4)
+	          ON CONFLICT (dedup_key) DO NOTHING
+	          RETURNING id`
+	var id int
+	err := r.db.Get(ctx, &id, query, n.UserID, n.Kind, n.Params, n.DedupKey)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("не удалось поставить уведомление в очередь: %w", err)
+	}
+	return id, nil
+}
+
+// GetByID возвращает уведомление по ID.
+func (r *NotificationRepository) GetByID(ctx context.Context, id int) (*model.Notification, error) {
+	var n model.Notification
+	err := r.db.Get(ctx, &n, "SELECT * FROM notifications WHERE id=$1", id)
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+// ClaimPending забирает до limit ожидающих уведомлений, время которых наступило, и откладывает
+// их до leaseUntil, чтобы их не взял другой экземпляр бота.
+func (r *NotificationRepository) ClaimPending(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]model.Notification, error) {
+	notifications := []model.Notification{}
+	err := r.db.Select(ctx, &notifications,
+		`UPDATE notifications SET next_attempt_at=$2
+		 WHERE id IN (
+		     SELECT id FROM notifications
+		     WHERE status='pending' AND next_attempt_at <= $1
+		     ORDER BY next_attempt_at, id
+		     LIMIT $3
+		     FOR UPDATE SKIP LOCKED)
+		 RETURNING *`, now, leaseUntil, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при выборке очереди уведомлений: %w", err)
+	}
+	return notifications, nil
+}
+
+// MarkSent отмечает уведомление как доставленное на указанный Telegram ID.
+func (r *NotificationRepository) MarkSent(ctx context.Context, id int, telegramID int64) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE notifications SET status='sent', attempts=attempts+1, sent_at=NOW(), last_error='', telegram_id=$1
+		 WHERE id=$2`, telegramID, id)
+	if err != nil {
+		return fmt.Errorf("не удалось обновить статус уведомления: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed завершает уведомление с окончательным статусом ("failed" или "blocked").
+func (r *NotificationRepository) MarkFailed(ctx context.Context, id int, status string, reason string) error {
+	_, err := r.db.Exec(ctx,
+		"UPDATE notifications SET status=$1, attempts=attempts+1, last_error=$2 WHERE id=$3", status, reason, id)
+	if err != nil {
+		return fmt.Errorf("не удалось обновить статус уведомления: %w", err)
+	}
+	return nil
+}
+
+// Retry откладывает уведомление до указанного времени. countAttempt=false используется,
+// когда отправка не выполнялась (например, из-за лимита на чат).
+func (r *NotificationRepository) Retry(ctx context.Context, id int, at time.Time, reason string, countAttempt bool) error {
+	inc := 0
+	if countAttempt {
+		inc = 1
+	}
+	_, err := r.db.Exec(ctx,
+		"UPDATE notifications SET next_attempt_at=$1, last_error=$2, attempts=attempts+$3 WHERE id=$4",
+		at, reason, inc, id)
+	if err != nil {
+		return fmt.Errorf("не удалось отложить уведомление: %w", err)
+	}
+	return nil
+}
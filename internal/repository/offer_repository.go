@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"tourism/internal/model"
+
+	"github.com/lib/pq"
+)
+
+// OfferRepository обеспечивает доступ к предложениям провайдеров в базе данных.
+type OfferRepository struct {
+	db *DB
+}
+
+// NewOfferRepository создает новый репозиторий предложений.
+func NewOfferRepository(db *DB) *OfferRepository {
+	return &OfferRepository{db: db}
+}
+
+// Create сохраняет новое предложение. Возвращает ID созданной записи.
+func (r *OfferRepository) Create(ctx context.Context, o *model.Offer) (int, error) {
+	query := `INSERT INTO offers (location_id, type, name, description, price, contact, photo_file_id, social_links)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`
+	links := o.SocialLinks
+	if links == nil {
+		links = pq.StringArray{}
+	}
+	var id int
+	err := r.db.Get(ctx, &id, query, o.LocationID, o.Type, o.Name, o.Description, o.Price, o.Contact,
+		o.PhotoFileID, links)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось создать предложение: %w", err)
+	}
+	return id, nil
+}
+
+// ListByType возвращает видимые предложения указанного типа ("housing", "tour") у видимых локаций.
+func (r *OfferRepository) ListByType(ctx context.Context, offerType string) ([]model.Offer, error) {
+	offers := []model.Offer{}
+	err := r.db.Select(ctx, &offers,
+		`SELECT o.* FROM offers o JOIN locations l ON o.location_id = l.id
+		 WHERE o.type=$1 AND NOT o.hidden AND NOT l.hidden ORDER BY o.id`, offerType)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении списка предложений: %w", err)
+	}
+	return offers, nil
+}
+
+// GetByID возвращает предложение по ID.
+func (r *OfferRepository) GetByID(ctx context.Context, id int) (*model.Offer, error) {
+	var offer model.Offer
+	err := r.db.Get(ctx, &offer, "SELECT * FROM offers WHERE id=$1", id)
+	if err != nil {
+		return nil, err
+	}
+	return &offer, nil
+}
+
+// GetByIDForUpdate возвращает предложение по ID и блокирует запись до конца транзакции.
+func (r *OfferRepository) GetByIDForUpdate(ctx context.Context, id int) (*model.Offer, error) {
+	var offer model.Offer
+	err := r.db.Get(ctx, &offer, "SELECT * FROM offers WHERE id=$1 FOR UPDATE", id)
+	if err != nil {
+		return nil, err
+	}
+	return &offer, nil
+}
+
+// Update сохраняет тип, название, описание, цену, контакт и фото предложения.
+func (r *OfferRepository) Update(ctx context.Context, o *model.Offer) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE offers SET type=$1, name=$2, description=$3, price=$4, contact=$5, photo_file_id=$6
+		 WHERE id=$7`, o.Type, o.Name, o.Description, o.Price, o.Contact, o.PhotoFileID, o.ID)
+	if err != nil {
+		return fmt.Errorf("не удалось обновить предложение: %w", err)
+	}
+	return nil
+}
+
+// SetHidden скрывает предложение из списков и дайджестов или снова показывает его.
+func (r *OfferRepository) SetHidden(ctx context.Context, id int, hidden bool) error {
+	_, err := r.db.Exec(ctx, "UPDATE offers SET hidden=$1 WHERE id=$2", hidden, id)
+	if err != nil {
+		return fmt.Errorf("не удалось изменить видимость предложения: %w", err)
+	}
+	return nil
+}
+
+// ListByLocation возвращает предложения локации, включая скрытые, в порядке ID.
+func (r *OfferRepository) ListByLocation(ctx context.Context, locationID int) ([]model.Offer, error) {
+	offers := []model.Offer{}
+	err := r.db.Select(ctx, &offers, "SELECT * FROM offers WHERE location_id=$1 ORDER BY id", locationID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении предложений локации: %w", err)
+	}
+	return offers, nil
+}
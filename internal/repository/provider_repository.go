@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"tourism/internal/model"
+
+	"github.com/lib/pq"
+)
+
+// ProviderApplicationRepository обеспечивает доступ к заявкам на роль провайдера в базе данных.
+type ProviderApplicationRepository struct {
+	db *DB
+}
+
+// NewProviderApplicationRepository создает новый репозиторий заявок провайдеров.
+func NewProviderApplicationRepository(db *DB) *ProviderApplicationRepository {
+	return &ProviderApplicationRepository{db: db}
+}
+
+// Create сохраняет новую заявку. Возвращает ID созданной записи.
+func (r *ProviderApplicationRepository) Create(ctx context.Context, a *model.ProviderApplication) (int, error) {
+	query := `INSERT INTO provider_applications (user_id, business_name, contacts, document_file_ids, location_ids, locations_note)
+	          VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	docs := a.DocumentFileIDs
+	if docs == nil {
+		docs = pq.StringArray{}
+	}
+	locations := a.LocationIDs
+	if locations == nil {
+		locations = pq.Int64Array{}
+	}
+	var id int
+	err := r.db.Get(ctx, &id, query, a.UserID, a.BusinessName, a.Contacts, docs, locations, a.LocationsNote)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось сохранить заявку: %w", err)
+	}
+	return id, nil
+}
+
+// GetByID возвращает заявку по ID.
+func (r *ProviderApplicationRepository) GetByID(ctx context.Context, id int) (*model.ProviderApplication, error) {
+	var a model.ProviderApplication
+	err := r.db.Get(ctx, &a, "SELECT * FROM provider_applications WHERE id=$1", id)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// GetByIDForUpdate возвращает заявку по ID и блокирует запись до конца транзакции.
+func (r *ProviderApplicationRepository) GetByIDForUpdate(ctx context.Context, id int) (*model.ProviderApplication, error) {
+	var a model.ProviderApplication
+	err := r.db.Get(ctx, &a, "SELECT * FROM provider_applications WHERE id=$1 FOR UPDATE", id)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// GetLatestByUser возвращает последнюю заявку пользователя.
+func (r *ProviderApplicationRepository) GetLatestByUser(ctx context.Context, userID int) (*model.ProviderApplication, error) {
+	var a model.ProviderApplication
+	err := r.db.Get(ctx, &a, "SELECT * FROM provider_applications WHERE user_id=$1 ORDER BY created_at DESC, id DESC LIMIT 1", userID)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// ListByStatus возвращает заявки с указанным статусом, начиная со старых.
+func (r *ProviderApplicationRepository) ListByStatus(ctx context.Context, status string, limit int) ([]model.ProviderApplication, error) {
+	applications := []model.ProviderApplication{}
+	err := r.db.Select(ctx, &applications,
+		"SELECT * FROM provider_applications WHERE status=$1 ORDER BY created_at, id LIMIT $2", status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении очереди заявок: %w", err)
+	}
+	return applications, nil
+}
+
+// UpdateStatus записывает решение по заявке: статус, проверяющего и причину отклонения.
+func (r *ProviderApplicationRepository) UpdateStatus(ctx context.Context, id int, status string, reviewerID *int, reason string) error {
+	_, err := r.db.Exec(ctx,
+		"UPDATE provider_applications SET status=$1, reviewer_id=$2, reason=$3, reviewed_at=NOW() WHERE id=$4",
+		status, reviewerID, reason, id)
+	if err != nil {
+		return fmt.Errorf("не удалось обновить статус заявки: %w", err)
+	}
+	return nil
+}
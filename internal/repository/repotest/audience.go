@@ -0,0 +1,380 @@
+package repotest
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"tourism/internal/model"
+)
+
+// subscriber создает подписчика с указанными настройками.
+func (t *T) subscriber(lang string, sub model.OfferSubscription) *model.User {
+	ctx := t.Context()
+	u := t.newUser("user", lang)
+	sub.UserID = u.ID
+	t.must(t.stores.Subscriptions.SavePreferences(ctx, &sub), "сохранение подписки")
+	return u
+}
+
+func telegramIDs(users ...*model.User) []int64 {
+	ids := make([]int64, len(users))
+	for i, u := range users {
+		ids[i] = u.TelegramID
+	}
+	return ids
+}
+
+func sameInt64s(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, v := range a {
+		if !containsInt64(b, v) {
+			return false
+		}
+	}
+	return true
+}
+
+func checkSubscriptions(t *T) {
+	ctx := t.Context()
+	s := t.Stores()
+
+	// подписка по умолчанию: все темы, любые регионы, сразу
+	u := t.newUser("user", "ru")
+	t.must(s.Subscriptions.Subscribe(ctx, u.ID), "Subscribe")
+	sub, err := s.Subscriptions.GetByUserID(ctx, u.ID)
+	t.must(err, "GetByUserID")
+	if !sameStrings(sub.Topics, model.Topics) || len(sub.Regions) != 0 || sub.Frequency != model.FrequencyInstant ||
+		sub.LastDigestAt != nil {
+		t.Errorf("подписка по умолчанию: %+v", sub)
+	}
+	t.must(s.Subscriptions.SavePreferences(ctx, &model.OfferSubscription{
+		UserID: u.ID, Topics: []string{model.TopicTour}, Regions: []string{"Ирафский"}, Frequency: model.FrequencyWeekly,
+	}), "SavePreferences")
+	t.must(s.Subscriptions.Subscribe(ctx, u.ID), "повторный Subscribe")
+	sub, err = s.Subscriptions.GetByUserID(ctx, u.ID)
+	t.must(err, "GetByUserID")
+	if !sameStrings(sub.Topics, []string{model.TopicTour}) || !sameStrings(sub.Regions, []string{"Ирафский"}) ||
+		sub.Frequency != model.FrequencyWeekly {
+		t.Errorf("повторная подписка изменила настройки: %+v", sub)
+	}
+	t.must(s.Subscriptions.Unsubscribe(ctx, u.ID), "Unsubscribe")
+	if _, err := s.Subscriptions.GetByUserID(ctx, u.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetByUserID после отписки: %v, ожидалось sql.ErrNoRows", err)
+	}
+
+	// сегменты аудитории; регион уникален для запуска, поэтому чужие подписчики в выборку не попадают
+	a, region := t.audience()
+	upper := strings.ToUpper(region)
+	cases := []struct {
+		name    string
+		segment model.Segment
+		want    []*model.User
+	}{
+		{"регион", model.Segment{Regions: []string{upper}}, []*model.User{a.byRegion, a.byBooking, a.festival, a.rejected}},
+		{"тема", model.Segment{Regions: []string{upper}, Topics: []string{model.TopicTour}}, []*model.User{a.byRegion, a.byBooking, a.rejected}},
+		{"язык", model.Segment{Regions: []string{upper}, Languages: []string{"EN"}}, []*model.User{a.byRegion}},
+		{"категория", model.Segment{Regions: []string{upper}, Categories: []string{"MUSEUM"}}, []*model.User{a.byBooking}},
+		{"бронирования", model.Segment{Regions: []string{upper}, WithBookings: true}, []*model.User{a.byBooking}},
+	}
+	for _, c := range cases {
+		ids, err := s.Subscriptions.GetSubscriberTelegramIDsBySegment(ctx, c.segment)
+		t.must(err, "GetSubscriberTelegramIDsBySegment")
+		if want := telegramIDs(c.want...); !sameInt64s(ids, want) {
+			t.Errorf("сегмент %q: получены %v, ожидались %v", c.name, ids, want)
+		}
+	}
+
+	// язык, выбранный пользователем, важнее языка профиля Telegram
+	t.must(s.Users.UpdateLanguage(ctx, a.festival.ID, "en"), "UpdateLanguage")
+	t.must(s.Users.UpdateLanguage(ctx, a.byRegion.ID, "os"), "UpdateLanguage")
+	ids, err := s.Subscriptions.GetSubscriberTelegramIDsBySegment(ctx, model.Segment{Regions: []string{upper}, Languages: []string{"en"}})
+	t.must(err, "GetSubscriberTelegramIDsBySegment")
+	if want := telegramIDs(a.festival); !sameInt64s(ids, want) {
+		t.Errorf("сегмент по выбранному языку: получены %v, ожидались %v", ids, want)
+	}
+
+	all, err := s.Subscriptions.GetAllSubscriberTelegramIDs(ctx)
+	t.must(err, "GetAllSubscriberTelegramIDs")
+	if !containsInt64(all, a.byRegion.TelegramID) || !containsInt64(all, a.weekly.TelegramID) {
+		t.Errorf("GetAllSubscriberTelegramIDs не вернул активных подписчиков")
+	}
+	if containsInt64(all, a.inactive.TelegramID) {
+		t.Errorf("GetAllSubscriberTelegramIDs вернул неактивного пользователя")
+	}
+}
+
+// audience — подписчики, по-разному попадающие в сегменты одного региона.
+type audience struct {
+	byRegion  *model.User // регион в подписке, язык en-US
+	byBooking *model.User // регион из бронирования локации категории museum
+	festival  *model.User // подписан только на фестивали
+	rejected  *model.User // регион из отклоненного бронирования
+	weekly    *model.User // получает дайджест, а не рассылки
+	inactive  *model.User // заблокировал бота
+}
+
+// audience создает подписчиков в новом регионе. Имена регионов и категорий латиницей, чтобы
+// сравнение без учета регистра не зависело от локали базы.
+func (t *T) audience() (*audience, string) {
+	ctx := t.Context()
+	region := t.unique("repotest-region")
+	instant := func(regions ...string) model.OfferSubscription {
+		return model.OfferSubscription{Topics: model.Topics, Regions: regions, Frequency: model.FrequencyInstant}
+	}
+	a := &audience{
+		byRegion:  t.subscriber("en-US", instant(region)),
+		byBooking: t.subscriber("ru", instant()),
+		festival: t.subscriber("ru", model.OfferSubscription{
+			Topics: []string{model.TopicFestival}, Regions: []string{region}, Frequency: model.FrequencyInstant,
+		}),
+		rejected: t.subscriber("ru", instant()),
+		weekly: t.subscriber("en", model.OfferSubscription{
+			Topics: model.Topics, Regions: []string{region}, Frequency: model.FrequencyWeekly,
+		}),
+		inactive: t.subscriber("en", instant(region)),
+	}
+	t.must(t.stores.Users.SetActive(ctx, a.inactive.ID, false), "SetActive")
+
+	museum := t.newLocation(region, "museum", nil)
+	park := t.newLocation(region, "park", nil)
+	_, err := t.stores.Bookings.Create(ctx, &model.Booking{UserID: a.byBooking.ID, LocationID: museum.ID, Status: "pending"})
+	t.must(err, "создание бронирования")
+	_, err = t.stores.Bookings.Create(ctx, &model.Booking{UserID: a.rejected.ID, LocationID: park.ID, Status: "rejected"})
+	t.must(err, "создание бронирования")
+	return a, region
+}
+
+func checkCampaigns(t *T) {
+	ctx := t.Context()
+	s := t.Stores()
+	a, region := t.audience()
+	author := t.newUser("support", "ru")
+
+	campaign := &model.Campaign{
+		AuthorID: &author.ID,
+		Text:     "Скидки на туры",
+		Buttons:  model.CampaignButtons{{Text: "Подробнее", URL: "https://example.com"}},
+		Segment:  model.Segment{Regions: []string{region}},
+		Status:   "draft",
+	}
+	id, err := s.Campaigns.Create(ctx, campaign)
+	t.must(err, "Create")
+	got, err := s.Campaigns.GetByID(ctx, id)
+	t.must(err, "GetByID")
+	if got.Text != campaign.Text || got.Status != "draft" || got.AuthorID == nil || *got.AuthorID != author.ID ||
+		len(got.Buttons) != 1 || got.Buttons[0] != campaign.Buttons[0] || got.ScheduledAt != nil || got.CreatedAt.IsZero() {
+		t.Errorf("GetByID вернул %+v", got)
+	}
+	if _, err := s.Campaigns.GetByID(ctx, -1); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetByID для неизвестной рассылки: %v, ожидалось sql.ErrNoRows", err)
+	}
+
+	segment := model.Segment{Regions: []string{region}, Topics: []string{model.TopicTour}}
+	t.must(s.Campaigns.UpdateSegment(ctx, id, segment), "UpdateSegment")
+	// время в прошлом, чтобы ClaimDue не забрал чужие запланированные рассылки
+	at := time.Date(2001, 1, 1, 12, 0, 0, 0, time.UTC)
+	t.must(s.Campaigns.Schedule(ctx, id, at), "Schedule")
+	got, err = s.Campaigns.GetByID(ctx, id)
+	t.must(err, "GetByID")
+	if got.Status != "scheduled" || got.ScheduledAt == nil || !got.ScheduledAt.Equal(at) ||
+		!sameStrings(got.Segment.Topics, segment.Topics) {
+		t.Errorf("после Schedule: %+v", got)
+	}
+
+	if claimed, err := s.Campaigns.ClaimDue(ctx, at.Add(-time.Second)); err != nil || len(claimed) != 0 {
+		t.Errorf("ClaimDue до наступления времени: %v, %v", claimed, err)
+	}
+	claimed, err := s.Campaigns.ClaimDue(ctx, at)
+	t.must(err, "ClaimDue")
+	if len(claimed) != 1 || claimed[0].ID != id || claimed[0].Status != "sending" {
+		t.Fatalf("ClaimDue вернул %+v, ожидалась рассылка %d в статусе sending", claimed, id)
+	}
+	if again, err := s.Campaigns.ClaimDue(ctx, at); err != nil || len(again) != 0 {
+		t.Errorf("повторный ClaimDue вернул %v, %v", again, err)
+	}
+	stats, err := s.Deliveries.Stats(ctx, id)
+	t.must(err, "Stats")
+	if *stats != (model.DeliveryStats{Pending: 3}) {
+		t.Errorf("очередь после ClaimDue: %+v, ожидалось 3 ожидающих", *stats)
+	}
+
+	lease := time.Now().Add(time.Hour)
+	own := t.claim(id, lease)
+	ids := []int64{}
+	byTelegram := map[int64]model.CampaignDelivery{}
+	for _, d := range own {
+		ids = append(ids, d.TelegramID)
+		byTelegram[d.TelegramID] = d
+		if d.Status != "pending" || d.Attempts != 0 || !sameTime(d.NextAttemptAt, lease) {
+			t.Errorf("забранная доставка: %+v", d)
+		}
+	}
+	if want := telegramIDs(a.byRegion, a.byBooking, a.rejected); !sameInt64s(ids, want) {
+		t.Fatalf("получатели рассылки: %v, ожидались %v", ids, want)
+	}
+	if leased := t.claim(id, lease); len(leased) != 0 {
+		t.Errorf("доставки под арендой забраны повторно: %v", leased)
+	}
+
+	sent, blocked, failed := byTelegram[a.byRegion.TelegramID], byTelegram[a.byBooking.TelegramID], byTelegram[a.rejected.TelegramID]
+	t.must(s.Deliveries.MarkSent(ctx, sent.ID), "MarkSent")
+	t.must(s.Deliveries.Retry(ctx, blocked.ID, time.Now().Add(-time.Hour), "Too Many Requests", true), "Retry")
+	t.must(s.Deliveries.Retry(ctx, failed.ID, time.Now().Add(-time.Hour), "лимит чата", false), "Retry")
+	retried := t.claim(id, lease)
+	if len(retried) != 2 {
+		t.Fatalf("после Retry забрано %d доставок, ожидалось 2", len(retried))
+	}
+	for _, d := range retried {
+		switch d.ID {
+		case blocked.ID:
+			if d.Attempts != 1 || d.LastError != "Too Many Requests" {
+				t.Errorf("доставка после Retry с попыткой: %+v", d)
+			}
+		case failed.ID:
+			if d.Attempts != 0 || d.LastError != "лимит чата" {
+				t.Errorf("доставка после Retry без попытки: %+v", d)
+			}
+		default:
+			t.Errorf("после Retry забрана лишняя доставка %+v", d)
+		}
+	}
+	t.must(s.Deliveries.MarkFailed(ctx, blocked.ID, "blocked", "Forbidden: bot was blocked by the user"), "MarkFailed")
+
+	// пока есть ожидающая доставка, рассылка не завершается
+	finished, err := s.Campaigns.CompleteFinished(ctx)
+	t.must(err, "CompleteFinished")
+	if containsInt(finished, id) {
+		t.Errorf("рассылка с ожидающими доставками завершена")
+	}
+	t.must(s.Deliveries.MarkFailed(ctx, failed.ID, "failed", "Bad Request"), "MarkFailed")
+	stats, err = s.Deliveries.Stats(ctx, id)
+	t.must(err, "Stats")
+	if *stats != (model.DeliveryStats{Sent: 1, Failed: 1, Blocked: 1}) {
+		t.Errorf("итог доставки: %+v", *stats)
+	}
+	finished, err = s.Campaigns.CompleteFinished(ctx)
+	t.must(err, "CompleteFinished")
+	if !containsInt(finished, id) {
+		t.Errorf("CompleteFinished не завершил рассылку %d (%v)", id, finished)
+	}
+	got, err = s.Campaigns.GetByID(ctx, id)
+	t.must(err, "GetByID")
+	if got.Status != "sent" || got.SentAt == nil {
+		t.Errorf("после завершения: статус %q, sent_at %v", got.Status, got.SentAt)
+	}
+}
+
+// claim забирает ожидающие доставки и возвращает только доставки рассылки campaignID.
+// Чужие доставки, забранные заодно, сразу возвращаются в очередь.
+func (t *T) claim(campaignID int, lease time.Time) []model.CampaignDelivery {
+	ctx := t.Context()
+	now := time.Now()
+	claimed, err := t.stores.Deliveries.ClaimPending(ctx, now.Add(time.Minute), lease, 1000)
+	t.must(err, "ClaimPending")
+	own := []model.CampaignDelivery{}
+	for _, d := range claimed {
+		if d.CampaignID == campaignID {
+			own = append(own, d)
+			continue
+		}
+		t.must(t.stores.Deliveries.Retry(ctx, d.ID, now, d.LastError, false), "возврат чужой доставки")
+	}
+	return own
+}
+
+func checkDigests(t *T) {
+	ctx := t.Context()
+	s := t.Stores()
+	regionName := t.unique("repotest-digest")
+	since := time.Now().Add(-time.Hour)
+	w := t.subscriber("ru", model.OfferSubscription{
+		Topics: []string{model.TopicHousing, model.TopicTour}, Regions: []string{regionName}, Frequency: model.FrequencyWeekly,
+	})
+
+	now := time.Now()
+	due, err := s.Digests.DueSubscribers(ctx, now, now, now.Add(time.Minute), 1000)
+	t.must(err, "DueSubscribers")
+	if d := findSubscriber(due, w.ID); d == nil || d.TelegramID != w.TelegramID || d.LastDigestAt != nil {
+		t.Errorf("DueSubscribers: подписчик %d не найден или заполнен неверно (%+v)", w.ID, d)
+	}
+	// зарезервированного подписчика не получает другой экземпляр, пока не истек резерв
+	due, err = s.Digests.DueSubscribers(ctx, now, now, now.Add(time.Minute), 1000)
+	t.must(err, "DueSubscribers")
+	if findSubscriber(due, w.ID) != nil {
+		t.Errorf("DueSubscribers вернул подписчика %d, зарезервированного другим вызовом", w.ID)
+	}
+	due, err = s.Digests.DueSubscribers(ctx, now, now.Add(time.Minute), now.Add(2*time.Minute), 1000)
+	t.must(err, "DueSubscribers")
+	if findSubscriber(due, w.ID) == nil {
+		t.Errorf("DueSubscribers: подписчик %d не вернулся в очередь после истечения резерва", w.ID)
+	}
+
+	loc := t.newLocation(regionName, "guesthouse", nil)
+	elsewhere := t.newLocation(t.unique("repotest-digest"), "guesthouse", nil)
+	housing, err := s.Offers.Create(ctx, &model.Offer{LocationID: loc.ID, Type: model.TopicHousing, Name: t.unique("Дом")})
+	t.must(err, "создание предложения")
+	_, err = s.Offers.Create(ctx, &model.Offer{LocationID: loc.ID, Type: model.TopicFestival, Name: t.unique("Фестиваль")})
+	t.must(err, "создание предложения")
+	_, err = s.Offers.Create(ctx, &model.Offer{LocationID: elsewhere.ID, Type: model.TopicTour, Name: t.unique("Тур")})
+	t.must(err, "создание предложения")
+
+	locations, err := s.Digests.NewLocations(ctx, w.ID, []string{regionName}, since, 10)
+	t.must(err, "NewLocations")
+	if ids := locationIDs(locations); !equalInts(ids, []int{loc.ID}) {
+		t.Errorf("NewLocations по региону: %v, ожидалось [%d]", ids, loc.ID)
+	}
+	anyRegion, err := s.Digests.NewLocations(ctx, w.ID, nil, since, 1000)
+	t.must(err, "NewLocations")
+	if ids := locationIDs(anyRegion); !containsInt(ids, loc.ID) || !containsInt(ids, elsewhere.ID) {
+		t.Errorf("NewLocations без регионов не вернул новые локации: %v", ids)
+	}
+	if old, err := s.Digests.NewLocations(ctx, w.ID, []string{regionName}, time.Now().Add(time.Hour), 10); err != nil || len(old) != 0 {
+		t.Errorf("NewLocations после since в будущем: %v, %v", locationIDs(old), err)
+	}
+	offers, err := s.Digests.NewOffers(ctx, w.ID, []string{model.TopicHousing, model.TopicTour}, []string{regionName}, since, 10)
+	t.must(err, "NewOffers")
+	if len(offers) != 1 || offers[0].ID != housing {
+		t.Errorf("NewOffers: %+v, ожидалось предложение %d", offers, housing)
+	}
+
+	at := time.Now().Truncate(time.Second)
+	digest := &model.Digest{Locations: locations, Offers: offers}
+	t.must(s.Digests.Record(ctx, w.ID, digest, at), "Record")
+	t.must(s.Digests.Record(ctx, w.ID, digest, at), "повторный Record")
+	if again, err := s.Digests.NewLocations(ctx, w.ID, []string{regionName}, since, 10); err != nil || len(again) != 0 {
+		t.Errorf("отправленная локация повторно попала в дайджест: %v, %v", locationIDs(again), err)
+	}
+	if again, err := s.Digests.NewOffers(ctx, w.ID, []string{model.TopicHousing, model.TopicTour}, []string{regionName}, since, 10); err != nil || len(again) != 0 {
+		t.Errorf("отправленное предложение повторно попало в дайджест: %v, %v", again, err)
+	}
+	// элементы учитываются для каждого пользователя отдельно
+	other := t.newUser("user", "ru")
+	if fresh, err := s.Digests.NewLocations(ctx, other.ID, []string{regionName}, since, 10); err != nil || len(fresh) != 1 {
+		t.Errorf("дайджест другого пользователя: %v, %v", locationIDs(fresh), err)
+	}
+
+	due, err = s.Digests.DueSubscribers(ctx, at, now, now, 1000)
+	t.must(err, "DueSubscribers")
+	if findSubscriber(due, w.ID) != nil {
+		t.Errorf("подписчик получил дайджест в %v, но снова в очереди", at)
+	}
+	// Record снимает резерв: следующий дайджест доступен без ожидания его истечения
+	due, err = s.Digests.DueSubscribers(ctx, at.Add(time.Second), now, now, 1000)
+	t.must(err, "DueSubscribers")
+	if d := findSubscriber(due, w.ID); d == nil || d.LastDigestAt == nil || !d.LastDigestAt.Equal(at) {
+		t.Errorf("DueSubscribers после Record: %+v", d)
+	}
+}
+
+func findSubscriber(subs []model.DigestSubscriber, userID int) *model.DigestSubscriber {
+	for i := range subs {
+		if subs[i].UserID == userID {
+			return &subs[i]
+		}
+	}
+	return nil
+}
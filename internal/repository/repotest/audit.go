@@ -0,0 +1,58 @@
+package repotest
+
+import "tourism/internal/model"
+
+func checkAudit(t *T) {
+	ctx := t.Context()
+	s := t.Stores()
+	admin := t.newUser("admin", "ru")
+	target := t.newUser("user", "ru")
+	other := t.newUser("user", "ru")
+
+	search, err := s.Audit.Add(ctx, &model.AuditEntry{ActorID: &admin.ID, Action: model.AuditUserSearch, Details: "Тест"})
+	t.must(err, "Add")
+	block, err := s.Audit.Add(ctx, &model.AuditEntry{ActorID: &admin.ID, Action: model.AuditBlock,
+		TargetUserID: &target.ID, Details: "спам"})
+	t.must(err, "Add")
+	// служебная команда записывается без автора
+	role, err := s.Audit.Add(ctx, &model.AuditEntry{Action: model.AuditRoleChange, TargetUserID: &target.ID,
+		Details: "user -> admin"})
+	t.must(err, "Add")
+	_, err = s.Audit.Add(ctx, &model.AuditEntry{ActorID: &admin.ID, Action: model.AuditUserView, TargetUserID: &other.ID})
+	t.must(err, "Add")
+	missing := -1
+	if _, err := s.Audit.Add(ctx, &model.AuditEntry{Action: model.AuditBlock, TargetUserID: &missing}); err == nil {
+		t.Errorf("запись о несуществующем пользователе должна отклоняться")
+	}
+
+	entries, err := s.Audit.ListByTarget(ctx, target.ID, 10)
+	t.must(err, "ListByTarget")
+	if len(entries) != 2 || entries[0].ID != role || entries[1].ID != block {
+		t.Fatalf("ListByTarget вернул %+v, ожидались [%d %d]", entries, role, block)
+	}
+	if entries[0].ActorID != nil || entries[0].Details != "user -> admin" {
+		t.Errorf("запись без автора: %+v", entries[0])
+	}
+	if e := entries[1]; e.ActorID == nil || *e.ActorID != admin.ID || e.Action != model.AuditBlock ||
+		e.Details != "спам" || e.CreatedAt.IsZero() {
+		t.Errorf("запись о блокировке: %+v", e)
+	}
+	entries, err = s.Audit.ListByTarget(ctx, target.ID, 1)
+	t.must(err, "ListByTarget")
+	if len(entries) != 1 || entries[0].ID != role {
+		t.Errorf("ListByTarget с лимитом 1 вернул %+v", entries)
+	}
+
+	recent, err := s.Audit.ListRecent(ctx, 10)
+	t.must(err, "ListRecent")
+	found := false
+	for i, e := range recent {
+		found = found || e.ID == search
+		if i > 0 && e.ID > recent[i-1].ID {
+			t.Errorf("ListRecent вернул записи не по убыванию ID")
+		}
+	}
+	if !found {
+		t.Errorf("ListRecent не вернул запись о поиске %d", search)
+	}
+}
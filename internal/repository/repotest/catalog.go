@@ -0,0 +1,463 @@
+package repotest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+
+	"tourism/internal/model"
+)
+
+func checkUsers(t *T) {
+	ctx := t.Context()
+	s := t.Stores()
+	u := t.newUser("provider", "ru")
+
+	got, err := s.Users.GetByTelegramID(ctx, u.TelegramID)
+	t.must(err, "GetByTelegramID")
+	if got.ID != u.ID || got.Username != u.Username || got.FirstName != u.FirstName || got.Role != u.Role ||
+		got.LanguageCode != u.LanguageCode || got.Language != u.Language {
+		t.Errorf("GetByTelegramID вернул %+v, ожидался %+v", got, u)
+	}
+	if !got.IsActive {
+		t.Errorf("новый пользователь должен быть активным")
+	}
+	if got.CreatedAt.IsZero() {
+		t.Errorf("у нового пользователя не заполнено created_at")
+	}
+	byID, err := s.Users.GetByID(ctx, u.ID)
+	t.must(err, "GetByID")
+	if byID.TelegramID != u.TelegramID {
+		t.Errorf("GetByID вернул Telegram ID %d, ожидался %d", byID.TelegramID, u.TelegramID)
+	}
+	t.must(s.Tx.WithinTx(ctx, func(ctx context.Context) error {
+		locked, err := s.Users.GetByIDForUpdate(ctx, u.ID)
+		if err != nil {
+			return err
+		}
+		if locked.TelegramID != u.TelegramID || locked.Role != u.Role {
+			t.Errorf("GetByIDForUpdate вернул %+v, ожидался пользователь %d", locked, u.ID)
+		}
+		return nil
+	}), "GetByIDForUpdate")
+	if _, err := s.Users.GetByIDForUpdate(ctx, -1); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetByIDForUpdate для неизвестного пользователя: %v, ожидалось sql.ErrNoRows", err)
+	}
+
+	if _, err := s.Users.Create(ctx, &model.User{TelegramID: u.TelegramID, Role: "user"}); err == nil {
+		t.Errorf("повторный Telegram ID должен отклоняться")
+	}
+	if _, err := s.Users.GetByTelegramID(ctx, t.telegramID()); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetByTelegramID для неизвестного пользователя: %v, ожидалось sql.ErrNoRows", err)
+	}
+	if _, err := s.Users.GetByID(ctx, -1); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetByID для неизвестного пользователя: %v, ожидалось sql.ErrNoRows", err)
+	}
+
+	t.must(s.Users.UpdateLanguageCode(ctx, u.ID, "en-US"), "UpdateLanguageCode")
+	t.must(s.Users.UpdateLanguage(ctx, u.ID, "os"), "UpdateLanguage")
+	t.must(s.Users.SetActive(ctx, u.ID, false), "SetActive")
+	got, err = s.Users.GetByID(ctx, u.ID)
+	t.must(err, "GetByID")
+	if got.LanguageCode != "en-US" || got.Language != "os" || got.IsActive {
+		t.Errorf("после обновления: язык %q, язык интерфейса %q, активен %v", got.LanguageCode, got.Language, got.IsActive)
+	}
+
+	providers, err := s.Users.ListByRole(ctx, "provider")
+	t.must(err, "ListByRole")
+	found := false
+	for _, p := range providers {
+		found = found || p.ID == u.ID
+		if p.Role != "provider" {
+			t.Errorf("ListByRole(provider) вернул пользователя с ролью %q", p.Role)
+		}
+	}
+	if !found {
+		t.Errorf("ListByRole не вернул созданного провайдера")
+	}
+
+	if got.IsBlocked {
+		t.Errorf("новый пользователь не должен быть заблокирован")
+	}
+	t.must(s.Users.SetBlocked(ctx, u.ID, true), "SetBlocked")
+	got, err = s.Users.GetByID(ctx, u.ID)
+	t.must(err, "GetByID")
+	if !got.IsBlocked {
+		t.Errorf("после SetBlocked пользователь не заблокирован")
+	}
+
+	for _, query := range []string{"@" + strings.ToUpper(u.Username), strconv.Itoa(u.ID),
+		strconv.FormatInt(u.TelegramID, 10)} {
+		found, err := s.Users.Search(ctx, query, 10)
+		t.must(err, "Search")
+		if len(found) != 1 || found[0].ID != u.ID {
+			t.Errorf("Search(%q) вернул %d пользователей, ожидался %d", query, len(found), u.ID)
+		}
+	}
+	if found, err := s.Users.Search(ctx, "  ", 10); err != nil || len(found) != 0 {
+		t.Errorf("Search по пустому запросу: %v, %v", found, err)
+	}
+}
+
+func checkLocations(t *T) {
+	ctx := t.Context()
+	s := t.Stores()
+	region := t.unique("Регион")
+	provider := t.newUser("provider", "ru")
+	museum := t.newLocation(region, "музей", &provider.ID)
+	park := t.newLocation(region, "парк", nil)
+
+	got, err := s.Locations.GetByID(ctx, museum.ID)
+	t.must(err, "GetByID")
+	if got.Name != museum.Name || got.Region != region || got.Category != "музей" || got.Rating != museum.Rating ||
+		got.ProviderID == nil || *got.ProviderID != provider.ID {
+		t.Errorf("GetByID вернул %+v, ожидалась %+v", got, museum)
+	}
+	if got.CreatedAt.IsZero() {
+		t.Errorf("у новой локации не заполнено created_at")
+	}
+	if _, err := s.Locations.GetByID(ctx, -1); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetByID для неизвестной локации: %v, ожидалось sql.ErrNoRows", err)
+	}
+
+	// регион и категория сравниваются без учета регистра, "any" отключает фильтр
+	found, err := s.Locations.FindByFilters(ctx, "any", region, 0, "")
+	t.must(err, "FindByFilters")
+	if ids := locationIDs(found); len(ids) != 2 || !containsInt(ids, museum.ID) || !containsInt(ids, park.ID) {
+		t.Errorf("поиск по региону вернул %v, ожидались %d и %d", ids, museum.ID, park.ID)
+	}
+	found, err = s.Locations.FindByFilters(ctx, "МУЗЕЙ", region, 0, "")
+	t.must(err, "FindByFilters")
+	if ids := locationIDs(found); !equalInts(ids, []int{museum.ID}) {
+		t.Errorf("поиск по категории вернул %v, ожидался [%d]", ids, museum.ID)
+	}
+	found, err = s.Locations.FindByFilters(ctx, "", region, 4, "")
+	t.must(err, "FindByFilters")
+	if len(found) != 0 {
+		t.Errorf("поиск с минимальным рейтингом 4 вернул %v", locationIDs(found))
+	}
+	found, err = s.Locations.FindByFilters(ctx, "", "", 0, park.Name)
+	t.must(err, "FindByFilters")
+	if ids := locationIDs(found); !equalInts(ids, []int{park.ID}) {
+		t.Errorf("поиск по названию вернул %v, ожидался [%d]", ids, park.ID)
+	}
+
+	all, err := s.Locations.FindAll(ctx)
+	t.must(err, "FindAll")
+	if !containsInt(locationIDs(all), museum.ID) {
+		t.Errorf("FindAll не вернул созданную локацию")
+	}
+	regions, err := s.Locations.ListRegions(ctx)
+	t.must(err, "ListRegions")
+	n := 0
+	for _, r := range regions {
+		if r == region {
+			n++
+		}
+	}
+	if n != 1 {
+		t.Errorf("ListRegions вернул регион %q %d раз(а), ожидался один", region, n)
+	}
+
+	first, err := s.Locations.AddPhoto(ctx, museum.ID, "photo-1", "")
+	t.must(err, "AddPhoto")
+	second, err := s.Locations.AddPhoto(ctx, museum.ID, "photo-2", "Вид сверху")
+	t.must(err, "AddPhoto")
+	photos, err := s.Locations.GetPhotos(ctx, museum.ID)
+	t.must(err, "GetPhotos")
+	fileIDs := []string{}
+	for _, p := range photos {
+		fileIDs = append(fileIDs, p.FileID)
+		if p.LocationID != museum.ID {
+			t.Errorf("фото %d привязано к локации %d", p.ID, p.LocationID)
+		}
+	}
+	if !sameStrings(fileIDs, []string{"photo-1", "photo-2"}) {
+		t.Errorf("GetPhotos вернул %v", fileIDs)
+	}
+	if len(photos) == 2 && (photos[0].Position != 1 || photos[1].Position != 2 || !photos[0].IsCover || photos[1].IsCover) {
+		t.Errorf("новые фото: позиции %d, %d, обложки %v, %v; ожидались 1, 2 и обложка у первого",
+			photos[0].Position, photos[1].Position, photos[0].IsCover, photos[1].IsCover)
+	}
+	if _, err := s.Locations.AddPhoto(ctx, -1, "photo", ""); err == nil {
+		t.Errorf("фото несуществующей локации должно отклоняться")
+	}
+	photo, err := s.Locations.GetPhoto(ctx, second)
+	t.must(err, "GetPhoto")
+	if photo.Caption != "Вид сверху" || photo.LocationID != museum.ID {
+		t.Errorf("GetPhoto вернул %+v", photo)
+	}
+	if _, err := s.Locations.GetPhoto(ctx, -1); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetPhoto несуществующего фото: %v, ожидался sql.ErrNoRows", err)
+	}
+
+	t.must(s.Locations.SetPhotoOrder(ctx, museum.ID, []int{second, first}), "SetPhotoOrder")
+	t.must(s.Locations.SetCoverPhoto(ctx, museum.ID, second), "SetCoverPhoto")
+	t.must(s.Locations.SetPhotoCaption(ctx, first, "Вход"), "SetPhotoCaption")
+	photos, err = s.Locations.GetPhotos(ctx, museum.ID)
+	t.must(err, "GetPhotos")
+	if len(photos) != 2 || photos[0].ID != second || photos[1].ID != first || !photos[0].IsCover || photos[1].IsCover ||
+		photos[1].Caption != "Вход" {
+		t.Errorf("после смены порядка, обложки и подписи GetPhotos вернул %+v", photos)
+	}
+	third, err := s.Locations.AddPhoto(ctx, museum.ID, "photo-3", "")
+	t.must(err, "AddPhoto")
+	t.must(s.Locations.DeletePhoto(ctx, first), "DeletePhoto")
+	photos, err = s.Locations.GetPhotos(ctx, museum.ID)
+	t.must(err, "GetPhotos")
+	if len(photos) != 2 || photos[0].ID != second || photos[1].ID != third || photos[1].Position != 3 || photos[1].IsCover {
+		t.Errorf("после добавления и удаления GetPhotos вернул %+v", photos)
+	}
+
+	pendingIDs := func() []int {
+		pending, err := s.Locations.ListPhotosToStore(ctx, 2, 1000)
+		t.must(err, "ListPhotosToStore")
+		ids := []int{}
+		for _, p := range pending {
+			ids = append(ids, p.ID)
+		}
+		return ids
+	}
+	if ids := pendingIDs(); !containsInt(ids, second) || !containsInt(ids, third) {
+		t.Errorf("ListPhotosToStore: несохраненные фото %d и %d не найдены в %v", second, third, ids)
+	}
+	t.must(s.Locations.MarkPhotoStored(ctx, second, "locations/photos/2", "image/jpeg", 800, 600), "MarkPhotoStored")
+	t.must(s.Locations.MarkPhotoStoreFailed(ctx, third, "timeout"), "MarkPhotoStoreFailed")
+	if ids := pendingIDs(); containsInt(ids, second) || !containsInt(ids, third) {
+		t.Errorf("ListPhotosToStore после сохранения и одной ошибки вернул %v", ids)
+	}
+	t.must(s.Locations.MarkPhotoStoreFailed(ctx, third, "bad image"), "MarkPhotoStoreFailed")
+	if ids := pendingIDs(); containsInt(ids, third) {
+		t.Errorf("ListPhotosToStore вернул фото %d после исчерпания попыток", third)
+	}
+	stored, err := s.Locations.GetPhoto(ctx, second)
+	t.must(err, "GetPhoto")
+	if stored.StoredAt == nil || stored.StorageKey != "locations/photos/2" || stored.ContentType != "image/jpeg" ||
+		stored.Width != 800 || stored.Height != 600 {
+		t.Errorf("MarkPhotoStored: получено %+v", stored)
+	}
+	failed, err := s.Locations.GetPhoto(ctx, third)
+	t.must(err, "GetPhoto")
+	if failed.StoredAt != nil || failed.StoreAttempts != 2 || failed.StoreError != "bad image" {
+		t.Errorf("MarkPhotoStoreFailed: попыток %d, ошибка %q", failed.StoreAttempts, failed.StoreError)
+	}
+	without, err := s.Locations.ListWithoutPhotos(ctx)
+	t.must(err, "ListWithoutPhotos")
+	ids := locationIDs(without)
+	if containsInt(ids, museum.ID) || !containsInt(ids, park.ID) {
+		t.Errorf("ListWithoutPhotos: локация с фото %v, без фото %v", containsInt(ids, museum.ID), containsInt(ids, park.ID))
+	}
+}
+
+func checkTrips(t *T) {
+	ctx := t.Context()
+	s := t.Stores()
+	region := t.unique("Регион")
+	u := t.newUser("user", "ru")
+	a := t.newLocation(region, "музей", nil)
+	b := t.newLocation(region, "парк", nil)
+	c := t.newLocation(region, "крепость", nil)
+
+	if _, err := s.Trips.GetActive(ctx, u.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetActive без маршрутов: %v, ожидалось sql.ErrNoRows", err)
+	}
+	first, err := s.Trips.Create(ctx, u.ID, "Первый")
+	t.must(err, "Create")
+	second, err := s.Trips.Create(ctx, u.ID, "Второй")
+	t.must(err, "Create")
+	active, err := s.Trips.GetActive(ctx, u.ID)
+	t.must(err, "GetActive")
+	if active.ID != second || active.Name != "Второй" || active.Status != "draft" || active.UserID != u.ID {
+		t.Errorf("GetActive вернул %+v, ожидался последний черновик %d", active, second)
+	}
+	byID, err := s.Trips.GetByID(ctx, first)
+	t.must(err, "GetByID")
+	if byID.ID != first || byID.Name != "Первый" || byID.UserID != u.ID {
+		t.Errorf("GetByID вернул %+v, ожидался маршрут %d", byID, first)
+	}
+	if _, err := s.Trips.GetByID(ctx, -1); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetByID несуществующего маршрута: %v, ожидалось sql.ErrNoRows", err)
+	}
+
+	for _, l := range []*model.Location{a, b, c} {
+		t.must(s.Trips.AddLocation(ctx, first, l.ID), "AddLocation")
+	}
+	got, err := s.Trips.GetLocations(ctx, first)
+	t.must(err, "GetLocations")
+	if ids := locationIDs(got); !equalInts(ids, []int{a.ID, b.ID, c.ID}) {
+		t.Errorf("порядок добавления: %v", ids)
+	}
+	t.must(s.Trips.UpdateOrder(ctx, first, []int{c.ID, a.ID, b.ID}), "UpdateOrder")
+	got, err = s.Trips.GetLocations(ctx, first)
+	t.must(err, "GetLocations")
+	if ids := locationIDs(got); !equalInts(ids, []int{c.ID, a.ID, b.ID}) {
+		t.Errorf("порядок после UpdateOrder: %v", ids)
+	}
+	empty, err := s.Trips.GetLocations(ctx, second)
+	t.must(err, "GetLocations")
+	if empty == nil || len(empty) != 0 {
+		t.Errorf("пустой маршрут: %v, ожидался пустой список", empty)
+	}
+	if _, err := s.Trips.Create(ctx, -1, "Чужой"); err == nil {
+		t.Errorf("маршрут несуществующего пользователя должен отклоняться")
+	}
+	trips, err := s.Trips.ListByUser(ctx, u.ID, 10)
+	t.must(err, "ListByUser")
+	if len(trips) != 2 || trips[0].ID != second || trips[1].ID != first {
+		t.Errorf("ListByUser вернул %+v, ожидались [%d %d]", trips, second, first)
+	}
+
+	// завершенный маршрут перестает быть текущим, повторно не завершается
+	ok, err := s.Trips.Complete(ctx, second)
+	t.must(err, "Complete")
+	completed, err := s.Trips.GetByID(ctx, second)
+	t.must(err, "GetByID")
+	if !ok || completed.Status != "completed" {
+		t.Errorf("Complete черновика: %v, статус %q", ok, completed.Status)
+	}
+	if ok, err := s.Trips.Complete(ctx, second); err != nil || ok {
+		t.Errorf("повторный Complete: %v, %v", ok, err)
+	}
+	if ok, err := s.Trips.Complete(ctx, -1); err != nil || ok {
+		t.Errorf("Complete несуществующего маршрута: %v, %v", ok, err)
+	}
+	active, err = s.Trips.GetActive(ctx, u.ID)
+	t.must(err, "GetActive")
+	if active.ID != first {
+		t.Errorf("GetActive после завершения вернул %d, ожидался черновик %d", active.ID, first)
+	}
+}
+
+func checkBookings(t *T) {
+	ctx := t.Context()
+	s := t.Stores()
+	region := t.unique("Регион")
+	tourist := t.newUser("user", "ru")
+	provider := t.newUser("provider", "ru")
+	own := t.newLocation(region, "жилье", &provider.ID)
+	other := t.newLocation(region, "жилье", nil)
+
+	first, err := s.Bookings.Create(ctx, &model.Booking{UserID: tourist.ID, LocationID: own.ID, Details: "2 человека", Status: "pending"})
+	t.must(err, "Create")
+	_, err = s.Bookings.Create(ctx, &model.Booking{UserID: tourist.ID, LocationID: other.ID, Details: "чужая", Status: "pending"})
+	t.must(err, "Create")
+	second, err := s.Bookings.Create(ctx, &model.Booking{UserID: tourist.ID, LocationID: own.ID, Details: "3 человека", Status: "pending"})
+	t.must(err, "Create")
+
+	got, err := s.Bookings.GetByID(ctx, first)
+	t.must(err, "GetByID")
+	if got.UserID != tourist.ID || got.LocationID != own.ID || got.Details != "2 человека" || got.Status != "pending" {
+		t.Errorf("GetByID вернул %+v", got)
+	}
+	if _, err := s.Bookings.GetByID(ctx, -1); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetByID для неизвестного бронирования: %v, ожидалось sql.ErrNoRows", err)
+	}
+	t.must(s.Bookings.UpdateStatus(ctx, first, "confirmed"), "UpdateStatus")
+	got, err = s.Bookings.GetByID(ctx, first)
+	t.must(err, "GetByID")
+	if got.Status != "confirmed" {
+		t.Errorf("статус после UpdateStatus: %q", got.Status)
+	}
+
+	list, err := s.Bookings.ListByProvider(ctx, provider.ID, 10)
+	t.must(err, "ListByProvider")
+	ids := []int{}
+	for _, b := range list {
+		ids = append(ids, b.ID)
+	}
+	if !equalInts(ids, []int{second, first}) {
+		t.Errorf("ListByProvider вернул %v, ожидалось [%d %d]", ids, second, first)
+	}
+	list, err = s.Bookings.ListByProvider(ctx, provider.ID, 1)
+	t.must(err, "ListByProvider")
+	if len(list) != 1 || list[0].ID != second {
+		t.Errorf("ListByProvider с лимитом 1 вернул %v", list)
+	}
+	if _, err := s.Bookings.Create(ctx, &model.Booking{UserID: tourist.ID, LocationID: -1, Status: "pending"}); err == nil {
+		t.Errorf("бронирование несуществующей локации должно отклоняться")
+	}
+	list, err = s.Bookings.ListByUser(ctx, tourist.ID, 2)
+	t.must(err, "ListByUser")
+	if len(list) != 2 || list[0].ID != second || list[0].UserID != tourist.ID {
+		t.Errorf("ListByUser с лимитом 2 вернул %+v", list)
+	}
+}
+
+func checkMessages(t *T) {
+	ctx := t.Context()
+	s := t.Stores()
+	tourist := t.newUser("user", "ru")
+	provider := t.newUser("provider", "ru")
+	operator := t.newUser("support", "ru")
+	loc := t.newLocation(t.unique("Регион"), "жилье", &provider.ID)
+	booking, err := s.Bookings.Create(ctx, &model.Booking{UserID: tourist.ID, LocationID: loc.ID, Status: "confirmed"})
+	t.must(err, "создание бронирования")
+
+	t.must(s.Messages.Save(ctx, &model.Message{FromUserID: tourist.ID, ToUserID: provider.ID, BookingID: &booking, Content: "Здравствуйте"}), "Save")
+	t.must(s.Messages.Save(ctx, &model.Message{FromUserID: provider.ID, ToUserID: tourist.ID, BookingID: &booking, Content: "Добрый день"}), "Save")
+	t.must(s.Messages.Save(ctx, &model.Message{FromUserID: tourist.ID, ToUserID: operator.ID, Content: "Помогите", IsSupport: true}), "Save")
+	t.must(s.Messages.Save(ctx, &model.Message{FromUserID: operator.ID, ToUserID: tourist.ID, Content: "Слушаю", IsSupport: true}), "Save")
+
+	chat, err := s.Messages.ListByBooking(ctx, booking)
+	t.must(err, "ListByBooking")
+	contents := []string{}
+	for _, m := range chat {
+		contents = append(contents, m.Content)
+		if m.BookingID == nil || *m.BookingID != booking {
+			t.Errorf("сообщение %d без ссылки на бронирование", m.ID)
+		}
+	}
+	if !sameStrings(contents, []string{"Здравствуйте", "Добрый день"}) {
+		t.Errorf("ListByBooking вернул %v", contents)
+	}
+	support, err := s.Messages.ListSupportMessages(ctx, tourist.ID)
+	t.must(err, "ListSupportMessages")
+	contents = contents[:0]
+	for _, m := range support {
+		contents = append(contents, m.Content)
+	}
+	if !sameStrings(contents, []string{"Помогите", "Слушаю"}) {
+		t.Errorf("ListSupportMessages вернул %v", contents)
+	}
+}
+
+func checkOffers(t *T) {
+	ctx := t.Context()
+	s := t.Stores()
+	loc := t.newLocation(t.unique("Регион"), "жилье", nil)
+	offer := &model.Offer{LocationID: loc.ID, Type: model.TopicHousing, Name: t.unique("Гостевой дом"), Price: 3500, Contact: "@host"}
+	id, err := s.Offers.Create(ctx, offer)
+	t.must(err, "Create")
+
+	got, err := s.Offers.GetByID(ctx, id)
+	t.must(err, "GetByID")
+	if got.Name != offer.Name || got.LocationID != loc.ID || got.Price != 3500 || got.Contact != "@host" {
+		t.Errorf("GetByID вернул %+v", got)
+	}
+	if got.SocialLinks == nil || len(got.SocialLinks) != 0 {
+		t.Errorf("ссылки по умолчанию: %#v, ожидался пустой список", got.SocialLinks)
+	}
+	if got.CreatedAt.IsZero() {
+		t.Errorf("у нового предложения не заполнено created_at")
+	}
+	if _, err := s.Offers.GetByID(ctx, -1); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetByID для неизвестного предложения: %v, ожидалось sql.ErrNoRows", err)
+	}
+	housing, err := s.Offers.ListByType(ctx, model.TopicHousing)
+	t.must(err, "ListByType")
+	found := false
+	for _, o := range housing {
+		found = found || o.ID == id
+		if o.Type != model.TopicHousing {
+			t.Errorf("ListByType(housing) вернул предложение типа %q", o.Type)
+		}
+	}
+	if !found {
+		t.Errorf("ListByType не вернул созданное предложение")
+	}
+	if _, err := s.Offers.Create(ctx, &model.Offer{LocationID: -1, Type: model.TopicTour, Name: "Тур"}); err == nil {
+		t.Errorf("предложение для несуществующей локации должно отклоняться")
+	}
+}
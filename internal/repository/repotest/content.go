@@ -0,0 +1,158 @@
+package repotest
+
+import (
+	"database/sql"
+	"errors"
+
+	"tourism/internal/model"
+)
+
+func checkContent(t *T) {
+	ctx := t.Context()
+	s := t.Stores()
+	provider := t.newUser("provider", "ru")
+	support := t.newUser("support", "ru")
+	region := t.unique("repotest-content")
+	loc := t.newLocation(region, "hotel", &provider.ID)
+	other := t.newLocation(region, "hotel", nil)
+
+	// изменения каталога
+	newLocation := &model.ContentChange{
+		Entity: model.ContentLocation, AuthorID: provider.ID, Name: "Новая локация", Description: "описание",
+		Category: "hotel", Region: region, Latitude: 42.8, Longitude: 44.6, PhotoFileIDs: []string{"photo-1"},
+		Status: model.ChangePending,
+	}
+	first, err := s.Changes.Create(ctx, newLocation)
+	t.must(err, "Create")
+	got, err := s.Changes.GetByID(ctx, first)
+	t.must(err, "GetByID")
+	if got.Entity != model.ContentLocation || got.EntityID != nil || got.LocationID != nil || got.AuthorID != provider.ID ||
+		got.Name != "Новая локация" || got.Category != "hotel" || got.Region != region || got.Latitude != 42.8 ||
+		len(got.PhotoFileIDs) != 1 || got.PhotoFileIDs[0] != "photo-1" || got.Status != model.ChangePending ||
+		got.ReviewerID != nil || got.ReviewedAt != nil || got.CreatedAt.IsZero() {
+		t.Errorf("GetByID вернул %+v", got)
+	}
+	if _, err := s.Changes.GetByID(ctx, -1); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetByID для неизвестного изменения: %v, ожидалось sql.ErrNoRows", err)
+	}
+	missing := -1
+	if _, err := s.Changes.Create(ctx, &model.ContentChange{Entity: model.ContentOffer, AuthorID: provider.ID, Name: "Тур",
+		LocationID: &missing, Status: model.ChangePending}); err == nil {
+		t.Errorf("изменение предложения несуществующей локации должно отклоняться")
+	}
+
+	edit := func(name string) int {
+		id, err := s.Changes.Create(ctx, &model.ContentChange{Entity: model.ContentOffer, EntityID: &loc.ID,
+			LocationID: &loc.ID, AuthorID: provider.ID, Name: name, OfferType: model.TopicTour, Price: 1500,
+			Status: model.ChangePending})
+		t.must(err, "Create")
+		return id
+	}
+	older, newer := edit("Старая версия"), edit("Новая версия")
+	t.must(s.Changes.ReplacePending(ctx, model.ContentOffer, loc.ID), "ReplacePending")
+	if c, err := s.Changes.GetByID(ctx, older); err != nil || c.Status != model.ChangeReplaced {
+		t.Errorf("изменение после ReplacePending: %+v, %v", c, err)
+	}
+	if c, err := s.Changes.GetByID(ctx, newer); err != nil || c.Status != model.ChangeReplaced {
+		t.Errorf("изменение после ReplacePending: %+v, %v", c, err)
+	}
+	if c, err := s.Changes.GetByID(ctx, first); err != nil || c.Status != model.ChangePending {
+		t.Errorf("ReplacePending затронул изменение другой записи: %+v, %v", c, err)
+	}
+
+	queue, err := s.Changes.ListByStatus(ctx, model.ChangePending, 1000)
+	t.must(err, "ListByStatus")
+	found := false
+	for _, c := range queue {
+		found = found || c.ID == first
+		if c.Status != model.ChangePending {
+			t.Errorf("ListByStatus(pending) вернул изменение в статусе %q", c.Status)
+		}
+	}
+	if !found {
+		t.Errorf("ListByStatus не вернул изменение %d", first)
+	}
+
+	t.must(s.Changes.UpdateStatus(ctx, first, model.ChangeApproved, &support.ID, ""), "UpdateStatus")
+	t.must(s.Changes.SetEntity(ctx, first, other.ID), "SetEntity")
+	got, err = s.Changes.GetByID(ctx, first)
+	t.must(err, "GetByID")
+	if got.Status != model.ChangeApproved || got.ReviewerID == nil || *got.ReviewerID != support.ID ||
+		got.ReviewedAt == nil || got.EntityID == nil || *got.EntityID != other.ID {
+		t.Errorf("одобренное изменение: %+v", got)
+	}
+
+	// редактирование и скрытие локаций
+	loc.Name, loc.Description, loc.Category, loc.Latitude, loc.Longitude = t.unique("Переименованная"), "новое описание", "camping", 43.1, 44.1
+	t.must(s.Locations.Update(ctx, loc), "Locations.Update")
+	if l, err := s.Locations.GetByID(ctx, loc.ID); err != nil || l.Name != loc.Name || l.Description != "новое описание" ||
+		l.Category != "camping" || l.Region != region || l.Latitude != 43.1 || l.Longitude != 44.1 || l.Rating != 3.5 ||
+		l.ProviderID == nil || *l.ProviderID != provider.ID {
+		t.Errorf("локация после Update: %+v, %v", l, err)
+	}
+	own, err := s.Locations.ListByProvider(ctx, provider.ID)
+	t.must(err, "ListByProvider")
+	if ids := locationIDs(own); len(ids) != 1 || ids[0] != loc.ID {
+		t.Errorf("ListByProvider вернул %v, ожидалась локация %d", ids, loc.ID)
+	}
+
+	offer := &model.Offer{LocationID: loc.ID, Type: model.TopicTour, Name: t.unique("Тур"), Price: 1000, PhotoFileID: "old"}
+	offer.ID, err = s.Offers.Create(ctx, offer)
+	t.must(err, "Offers.Create")
+	offer.Type, offer.Name, offer.Description, offer.Price, offer.Contact, offer.PhotoFileID =
+		model.TopicHousing, t.unique("Дом"), "у реки", 2500, "@host", "new"
+	t.must(s.Offers.Update(ctx, offer), "Offers.Update")
+	if o, err := s.Offers.GetByIDForUpdate(ctx, offer.ID); err != nil || o.Type != model.TopicHousing || o.Name != offer.Name ||
+		o.Description != "у реки" || o.Price != 2500 || o.Contact != "@host" || o.PhotoFileID != "new" || o.Hidden {
+		t.Errorf("предложение после Update: %+v, %v", o, err)
+	}
+
+	listed := func(offerType string) bool {
+		offers, err := s.Offers.ListByType(ctx, offerType)
+		t.must(err, "ListByType")
+		for _, o := range offers {
+			if o.ID == offer.ID {
+				return true
+			}
+		}
+		return false
+	}
+	searched := func() bool {
+		found, err := s.Locations.FindByFilters(ctx, "", region, 0, "")
+		t.must(err, "FindByFilters")
+		return containsInt(locationIDs(found), loc.ID)
+	}
+	if !listed(model.TopicHousing) || !searched() {
+		t.Errorf("видимые локация и предложение должны попадать в списки")
+	}
+	t.must(s.Offers.SetHidden(ctx, offer.ID, true), "Offers.SetHidden")
+	if listed(model.TopicHousing) {
+		t.Errorf("ListByType вернул скрытое предложение")
+	}
+	offers, err := s.Offers.ListByLocation(ctx, loc.ID)
+	t.must(err, "ListByLocation")
+	if len(offers) != 1 || offers[0].ID != offer.ID || !offers[0].Hidden {
+		t.Errorf("ListByLocation вернул %+v, ожидалось скрытое предложение %d", offers, offer.ID)
+	}
+	t.must(s.Offers.SetHidden(ctx, offer.ID, false), "Offers.SetHidden")
+
+	t.must(s.Locations.SetHidden(ctx, loc.ID, true), "Locations.SetHidden")
+	if searched() || listed(model.TopicHousing) {
+		t.Errorf("скрытая локация и ее предложения не должны попадать в поиск")
+	}
+	all, err := s.Locations.FindAll(ctx)
+	t.must(err, "FindAll")
+	if containsInt(locationIDs(all), loc.ID) {
+		t.Errorf("FindAll вернул скрытую локацию")
+	}
+	if l, err := s.Locations.GetByID(ctx, loc.ID); err != nil || !l.Hidden {
+		t.Errorf("GetByID для скрытой локации: %+v, %v", l, err)
+	}
+	if own, err := s.Locations.ListByProvider(ctx, provider.ID); err != nil || len(own) != 1 || !own[0].Hidden {
+		t.Errorf("ListByProvider должен возвращать скрытые локации: %+v, %v", own, err)
+	}
+	t.must(s.Locations.SetHidden(ctx, loc.ID, false), "Locations.SetHidden")
+	if !searched() {
+		t.Errorf("локация должна вернуться в поиск после показа")
+	}
+}
@@ -0,0 +1,107 @@
+package repotest
+
+import (
+	"context"
+	"time"
+
+	"tourism/internal/model"
+)
+
+func checkNotifications(t *T) {
+	s := t.Stores()
+	ctx := t.Context()
+	u := t.newUser("user", "ru")
+
+	n := &model.Notification{
+		UserID:   u.ID,
+		Kind:     model.NotifyBookingConfirmed,
+		Params:   model.NotificationParams{"booking_id": "1"},
+		DedupKey: t.unique("notification"),
+	}
+	id, err := s.Notifications.Enqueue(ctx, n)
+	t.must(err, "Enqueue")
+	if id == 0 {
+		t.Fatalf("Enqueue не вернул ID уведомления")
+	}
+	got, err := s.Notifications.GetByID(ctx, id)
+	t.must(err, "GetByID")
+	if got.UserID != u.ID || got.Kind != n.Kind || got.Params["booking_id"] != "1" || got.Status != "pending" ||
+		got.Attempts != 0 || got.TelegramID != nil || got.SentAt != nil {
+		t.Errorf("сохраненное уведомление: %+v", got)
+	}
+
+	// уведомление с тем же ключом не дублируется
+	if dup, err := s.Notifications.Enqueue(ctx, n); err != nil || dup != 0 {
+		t.Errorf("повторный Enqueue вернул %d, %v, ожидалось 0 без ошибки", dup, err)
+	}
+
+	// уведомление, поставленное в откатившейся транзакции, не остается в очереди
+	rolledBack := &model.Notification{UserID: u.ID, Kind: model.NotifyBookingRejected, DedupKey: t.unique("notification")}
+	var rolledBackID int
+	err = s.Tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if rolledBackID, err = s.Notifications.Enqueue(ctx, rolledBack); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	if err != errRollback || rolledBackID == 0 {
+		t.Errorf("Enqueue в транзакции: ID %d, ошибка %v", rolledBackID, err)
+	}
+	if _, err := s.Notifications.GetByID(ctx, rolledBackID); err == nil {
+		t.Errorf("после отката уведомление %d осталось в очереди", rolledBackID)
+	}
+
+	lease := time.Now().Add(time.Hour)
+	claimed := t.claimNotifications(id, lease)
+	if len(claimed) != 1 || !sameTime(claimed[0].NextAttemptAt, lease) {
+		t.Fatalf("ClaimPending вернул %+v, ожидалось уведомление %d под арендой", claimed, id)
+	}
+	if leased := t.claimNotifications(id, lease); len(leased) != 0 {
+		t.Errorf("уведомление под арендой забрано повторно: %v", leased)
+	}
+
+	t.must(s.Notifications.Retry(ctx, id, time.Now().Add(-time.Hour), "Too Many Requests", true), "Retry")
+	retried := t.claimNotifications(id, lease)
+	if len(retried) != 1 || retried[0].Attempts != 1 || retried[0].LastError != "Too Many Requests" {
+		t.Fatalf("после Retry забрано %+v", retried)
+	}
+	t.must(s.Notifications.MarkSent(ctx, id, u.TelegramID), "MarkSent")
+	got, err = s.Notifications.GetByID(ctx, id)
+	t.must(err, "GetByID")
+	if got.Status != "sent" || got.Attempts != 2 || got.LastError != "" || got.SentAt == nil ||
+		got.TelegramID == nil || *got.TelegramID != u.TelegramID {
+		t.Errorf("после MarkSent: %+v", got)
+	}
+	if sent := t.claimNotifications(id, lease); len(sent) != 0 {
+		t.Errorf("отправленное уведомление забрано повторно: %v", sent)
+	}
+
+	failed := &model.Notification{UserID: u.ID, Kind: model.NotifyReviewReply, DedupKey: t.unique("notification")}
+	failedID, err := s.Notifications.Enqueue(ctx, failed)
+	t.must(err, "Enqueue")
+	t.must(s.Notifications.MarkFailed(ctx, failedID, "blocked", "Forbidden: bot was blocked by the user"), "MarkFailed")
+	got, err = s.Notifications.GetByID(ctx, failedID)
+	t.must(err, "GetByID")
+	if got.Status != "blocked" || got.Attempts != 1 || got.LastError == "" || got.Params == nil {
+		t.Errorf("после MarkFailed: %+v", got)
+	}
+}
+
+// claimNotifications забирает ожидающие уведомления и возвращает только уведомление id.
+// Чужие уведомления, забранные заодно, сразу возвращаются в очередь.
+func (t *T) claimNotifications(id int, lease time.Time) []model.Notification {
+	ctx := t.Context()
+	now := time.Now()
+	claimed, err := t.stores.Notifications.ClaimPending(ctx, now.Add(time.Minute), lease, 1000)
+	t.must(err, "ClaimPending")
+	own := []model.Notification{}
+	for _, n := range claimed {
+		if n.ID == id {
+			own = append(own, n)
+			continue
+		}
+		t.must(t.stores.Notifications.Retry(ctx, n.ID, now, n.LastError, false), "возврат чужого уведомления")
+	}
+	return own
+}
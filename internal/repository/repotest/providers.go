@@ -0,0 +1,94 @@
+package repotest
+
+import (
+	"database/sql"
+	"errors"
+
+	"tourism/internal/model"
+)
+
+func checkProviders(t *T) {
+	ctx := t.Context()
+	s := t.Stores()
+	applicant := t.newUser("user", "ru")
+	other := t.newUser("user", "ru")
+	support := t.newUser("support", "ru")
+	owned := t.newLocation(t.unique("repotest-providers"), "hotel", nil)
+
+	if _, err := s.Applications.GetLatestByUser(ctx, applicant.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetLatestByUser без заявок: %v, ожидалось sql.ErrNoRows", err)
+	}
+	create := func(u *model.User, name string) int {
+		id, err := s.Applications.Create(ctx, &model.ProviderApplication{
+			UserID: u.ID, BusinessName: name, Contacts: "+7 900 000-00-00",
+			DocumentFileIDs: []string{"doc-1", "doc-2"}, LocationIDs: []int64{int64(owned.ID)}, LocationsNote: "Гостевой дом",
+		})
+		t.must(err, "Create")
+		return id
+	}
+	first := create(applicant, "Первая заявка")
+	if _, err := s.Applications.Create(ctx, &model.ProviderApplication{UserID: applicant.ID, BusinessName: "Вторая", Contacts: "-"}); err == nil {
+		t.Errorf("вторая заявка на рассмотрении должна отклоняться")
+	}
+	if _, err := s.Applications.Create(ctx, &model.ProviderApplication{UserID: -1, BusinessName: "Нет пользователя", Contacts: "-"}); err == nil {
+		t.Errorf("заявка несуществующего пользователя должна отклоняться")
+	}
+	create(other, "Другая заявка")
+
+	got, err := s.Applications.GetByID(ctx, first)
+	t.must(err, "GetByID")
+	if got.UserID != applicant.ID || got.BusinessName != "Первая заявка" || got.Contacts != "+7 900 000-00-00" ||
+		len(got.DocumentFileIDs) != 2 || got.DocumentFileIDs[1] != "doc-2" || len(got.LocationIDs) != 1 ||
+		got.LocationIDs[0] != int64(owned.ID) || got.LocationsNote != "Гостевой дом" || got.Status != model.ApplicationPending ||
+		got.ReviewerID != nil || got.ReviewedAt != nil || got.CreatedAt.IsZero() {
+		t.Errorf("GetByID вернул %+v", got)
+	}
+	if _, err := s.Applications.GetByID(ctx, -1); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetByID для неизвестной заявки: %v, ожидалось sql.ErrNoRows", err)
+	}
+
+	queue, err := s.Applications.ListByStatus(ctx, model.ApplicationPending, 1000)
+	t.must(err, "ListByStatus")
+	found := false
+	for _, a := range queue {
+		found = found || a.ID == first
+		if a.Status != model.ApplicationPending {
+			t.Errorf("ListByStatus(pending) вернул заявку в статусе %q", a.Status)
+		}
+	}
+	if !found {
+		t.Errorf("ListByStatus не вернул заявку %d", first)
+	}
+
+	t.must(s.Applications.UpdateStatus(ctx, first, model.ApplicationRejected, &support.ID, "нет документов"), "UpdateStatus")
+	got, err = s.Applications.GetByID(ctx, first)
+	t.must(err, "GetByID")
+	if got.Status != model.ApplicationRejected || got.ReviewerID == nil || *got.ReviewerID != support.ID ||
+		got.Reason != "нет документов" || got.ReviewedAt == nil {
+		t.Errorf("отклоненная заявка: %+v", got)
+	}
+	// после решения по заявке можно подать новую, и она становится последней
+	second := create(applicant, "Повторная заявка")
+	if latest, err := s.Applications.GetLatestByUser(ctx, applicant.ID); err != nil || latest.ID != second {
+		t.Errorf("GetLatestByUser вернул %+v, %v; ожидалась заявка %d", latest, err, second)
+	}
+
+	t.must(s.Users.UpdateRole(ctx, applicant.ID, "provider"), "UpdateRole")
+	if u, err := s.Users.GetByID(ctx, applicant.ID); err != nil || u.Role != "provider" {
+		t.Errorf("роль после UpdateRole: %+v, %v", u, err)
+	}
+	t.must(s.Locations.SetProvider(ctx, owned.ID, &applicant.ID), "SetProvider")
+	loc, err := s.Locations.GetByIDForUpdate(ctx, owned.ID)
+	t.must(err, "GetByIDForUpdate")
+	if loc.ProviderID == nil || *loc.ProviderID != applicant.ID {
+		t.Errorf("провайдер локации после SetProvider: %v", loc.ProviderID)
+	}
+	missing := -1
+	if err := s.Locations.SetProvider(ctx, owned.ID, &missing); err == nil {
+		t.Errorf("закрепление локации за несуществующим пользователем должно отклоняться")
+	}
+	t.must(s.Locations.SetProvider(ctx, owned.ID, nil), "SetProvider(nil)")
+	if loc, err := s.Locations.GetByID(ctx, owned.ID); err != nil || loc.ProviderID != nil {
+		t.Errorf("провайдер локации после снятия: %+v, %v", loc, err)
+	}
+}
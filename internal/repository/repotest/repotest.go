@@ -0,0 +1,205 @@
+// Package repotest содержит общий набор проверок хранилищ: один и тот же сценарий выполняется
+// против репозиториев PostgreSQL и хранилищ в памяти, чтобы их поведение не расходилось.
+//
+// Проверки создают собственные данные (пользователей с отрицательными Telegram ID, локации в
+// уникальном регионе) и не удаляют их, а очереди рассылок и уведомлений при проверке
+// забираются целиком, поэтому запускать набор против PostgreSQL нужно на отдельной тестовой базе.
+// Набор запускается из тестов пакетов memory и repository (см. Run).
+package repotest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"tourism/internal/model"
+	"tourism/internal/repository"
+)
+
+// Stores — набор проверяемых хранилищ одной реализации.
+type Stores struct {
+	Tx            repository.Transactor
+	Users         repository.UserStore
+	Locations     repository.LocationStore
+	Trips         repository.TripStore
+	Bookings      repository.BookingStore
+	Messages      repository.MessageStore
+	Offers        repository.OfferStore
+	Subscriptions repository.SubscriptionStore
+	Campaigns     repository.CampaignStore
+	Deliveries    repository.DeliveryStore
+	Notifications repository.NotificationStore
+	Digests       repository.DigestStore
+	Reviews       repository.ReviewStore
+	Translations  repository.TranslationStore
+	Applications  repository.ProviderApplicationStore
+	Changes       repository.ContentChangeStore
+	Audit         repository.AuditStore
+	States        repository.StateStore
+}
+
+// Check — одна группа проверок.
+type Check struct {
+	Name string
+	Run  func(t *T)
+}
+
+// Checks перечисляет все группы проверок в порядке выполнения.
+var Checks = []Check{
+	{"users", checkUsers},
+	{"locations", checkLocations},
+	{"trips", checkTrips},
+	{"bookings", checkBookings},
+	{"messages", checkMessages},
+	{"offers", checkOffers},
+	{"subscriptions", checkSubscriptions},
+	{"campaigns", checkCampaigns},
+	{"digests", checkDigests},
+	{"notifications", checkNotifications},
+	{"reviews", checkReviews},
+	{"translations", checkTranslations},
+	{"providers", checkProviders},
+	{"content", checkContent},
+	{"audit", checkAudit},
+	{"states", checkStates},
+	{"transactions", checkTransactions},
+}
+
+// Run выполняет все группы проверок против хранилищ s, каждую — отдельным подтестом t.
+func Run(t *testing.T, s Stores) {
+	run := &run{ctx: context.Background(), stores: s, suffix: time.Now().UnixNano()}
+	for _, c := range Checks {
+		t.Run(c.Name, func(t *testing.T) {
+			c.Run(&T{T: t, run: run})
+		})
+	}
+}
+
+// run хранит общее для всех групп состояние одного запуска.
+type run struct {
+	ctx    context.Context
+	stores Stores
+	suffix int64
+	seq    int64
+}
+
+// T передается в группу проверок: testing.T подтеста и общее состояние запуска.
+type T struct {
+	*testing.T
+	*run
+}
+
+// Context возвращает контекст, с которым вызываются хранилища.
+func (t *T) Context() context.Context {
+	return t.ctx
+}
+
+// Stores возвращает проверяемые хранилища.
+func (t *T) Stores() Stores {
+	return t.stores
+}
+
+// must прекращает группу, если шаг подготовки данных завершился ошибкой.
+func (t *T) must(err error, step string) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("%s: %v", step, err)
+	}
+}
+
+// unique возвращает строку, не повторяющуюся между запусками.
+func (t *T) unique(prefix string) string {
+	t.seq++
+	return fmt.Sprintf("%s-%d-%d", prefix, t.suffix, t.seq)
+}
+
+// telegramID возвращает отрицательный Telegram ID, который не совпадет с реальным пользователем.
+func (t *T) telegramID() int64 {
+	t.seq++
+	return -(t.suffix/1000%1_000_000_000_000 + t.seq)
+}
+
+// newUser создает пользователя с указанными ролью и языком.
+func (t *T) newUser(role, lang string) *model.User {
+	ctx := t.Context()
+	u := &model.User{
+		TelegramID:   t.telegramID(),
+		Username:     t.unique("user"),
+		FirstName:    "Тест",
+		LastName:     "Репозиториев",
+		Role:         role,
+		LanguageCode: lang,
+		Language:     model.BaseLanguage(lang),
+	}
+	id, err := t.stores.Users.Create(ctx, u)
+	t.must(err, "создание пользователя")
+	u.ID = id
+	return u
+}
+
+// newLocation создает локацию в регионе region.
+func (t *T) newLocation(region, category string, providerID *int) *model.Location {
+	ctx := t.Context()
+	loc := &model.Location{
+		Name:        t.unique("Локация"),
+		Description: "проверка репозиториев",
+		Category:    category,
+		Region:      region,
+		Rating:      3.5,
+		Latitude:    43.02,
+		Longitude:   44.68,
+		ProviderID:  providerID,
+	}
+	id, err := t.stores.Locations.Create(ctx, loc)
+	t.must(err, "создание локации")
+	loc.ID = id
+	return loc
+}
+
+func locationIDs(locations []model.Location) []int {
+	ids := make([]int, len(locations))
+	for i, l := range locations {
+		ids[i] = l.ID
+	}
+	return ids
+}
+
+func containsInt(values []int, v int) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+func containsInt64(values []int64, v int64) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+func equalInts(a, b []int) bool {
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+// sameStrings сравнивает списки как множества.
+func sameStrings(a, b []string) bool {
+	a = append([]string{}, a...)
+	b = append([]string{}, b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	return strings.Join(a, "\x00") == strings.Join(b, "\x00")
+}
+
+// sameTime сравнивает моменты времени с точностью, которую сохраняет PostgreSQL (микросекунды).
+func sameTime(a, b time.Time) bool {
+	d := a.Sub(b)
+	return d > -time.Microsecond && d < time.Microsecond
+}
@@ -0,0 +1,251 @@
+package repotest
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+
+	"tourism/internal/model"
+
+	"github.com/jmoiron/sqlx/types"
+)
+
+func checkReviews(t *T) {
+	ctx := t.Context()
+	s := t.Stores()
+	loc := t.newLocation(t.unique("repotest-reviews"), "museum", nil)
+	first, second, third := t.newUser("user", "ru"), t.newUser("user", "ru"), t.newUser("user", "ru")
+	moderator := t.newUser("support", "ru")
+
+	if ok, err := s.Reviews.CanReview(ctx, first.ID, loc.ID); err != nil || ok {
+		t.Errorf("CanReview без посещения: %v, %v", ok, err)
+	}
+	_, err := s.Bookings.Create(ctx, &model.Booking{UserID: first.ID, LocationID: loc.ID, Status: "pending"})
+	t.must(err, "создание бронирования")
+	if ok, err := s.Reviews.CanReview(ctx, first.ID, loc.ID); err != nil || ok {
+		t.Errorf("CanReview с неподтвержденным бронированием: %v, %v", ok, err)
+	}
+	booking, err := s.Bookings.Create(ctx, &model.Booking{UserID: first.ID, LocationID: loc.ID, Status: "pending"})
+	t.must(err, "создание бронирования")
+	t.must(s.Bookings.UpdateStatus(ctx, booking, "confirmed"), "подтверждение бронирования")
+	if ok, err := s.Reviews.CanReview(ctx, first.ID, loc.ID); err != nil || !ok {
+		t.Errorf("CanReview с подтвержденным бронированием: %v, %v", ok, err)
+	}
+	// черновик маршрута с локацией не дает права на отзыв
+	trip, err := s.Trips.Create(ctx, second.ID, "С отзывом")
+	t.must(err, "создание маршрута")
+	t.must(s.Trips.AddLocation(ctx, trip, loc.ID), "добавление в маршрут")
+	if ok, err := s.Reviews.CanReview(ctx, second.ID, loc.ID); err != nil || ok {
+		t.Errorf("CanReview с черновиком маршрута: %v, %v", ok, err)
+	}
+	done, err := s.Trips.Complete(ctx, trip)
+	t.must(err, "завершение маршрута")
+	if ok, err := s.Reviews.CanReview(ctx, second.ID, loc.ID); !done || err != nil || !ok {
+		t.Errorf("CanReview с завершенным маршрутом: %v, %v", ok, err)
+	}
+
+	text := "Great   place " + t.unique("repotest")
+	create := func(u *model.User, rating int, text string) int {
+		id, err := s.Reviews.Create(ctx, &model.Review{
+			LocationID: loc.ID, UserID: u.ID, Rating: rating, Text: text,
+			PhotoFileIDs: []string{}, Flags: []string{}, Status: "published",
+		})
+		t.must(err, "Create")
+		return id
+	}
+	r1 := create(first, 5, text)
+	r2 := create(second, 4, "")
+	r3 := create(third, 4, "")
+
+	if _, err := s.Reviews.Create(ctx, &model.Review{LocationID: loc.ID, UserID: first.ID, Rating: 3, Status: "published"}); err == nil {
+		t.Errorf("второй отзыв пользователя о локации должен отклоняться")
+	}
+	other := t.newUser("user", "ru")
+	if _, err := s.Reviews.Create(ctx, &model.Review{LocationID: loc.ID, UserID: other.ID, Rating: 6, Status: "published"}); err == nil {
+		t.Errorf("оценка вне диапазона 1..5 должна отклоняться")
+	}
+	if ok, err := s.Reviews.Exists(ctx, first.ID, loc.ID); err != nil || !ok {
+		t.Errorf("Exists для автора отзыва: %v, %v", ok, err)
+	}
+	if ok, err := s.Reviews.Exists(ctx, other.ID, loc.ID); err != nil || ok {
+		t.Errorf("Exists для пользователя без отзыва: %v, %v", ok, err)
+	}
+
+	got, err := s.Reviews.GetByID(ctx, r1)
+	t.must(err, "GetByID")
+	if got.UserID != first.ID || got.LocationID != loc.ID || got.Rating != 5 || got.Text != text ||
+		got.Status != "published" || got.Reply != "" || got.RepliedAt != nil || got.CreatedAt.IsZero() {
+		t.Errorf("GetByID вернул %+v", got)
+	}
+	if _, err := s.Reviews.GetByID(ctx, -1); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetByID для неизвестного отзыва: %v, ожидалось sql.ErrNoRows", err)
+	}
+
+	t.must(s.Reviews.RecalculateRating(ctx, loc.ID), "RecalculateRating")
+	t.expectRating(loc.ID, 4.33)
+	published, err := s.Reviews.ListPublished(ctx, loc.ID, 10, 0)
+	t.must(err, "ListPublished")
+	ids := []int{}
+	for _, r := range published {
+		ids = append(ids, r.ID)
+		if r.AuthorName != "Тест" {
+			t.Errorf("отзыв %d: имя автора %q", r.ID, r.AuthorName)
+		}
+	}
+	if len(ids) != 3 || !containsInt(ids, r1) || !containsInt(ids, r2) || !containsInt(ids, r3) {
+		t.Errorf("ListPublished вернул %v", ids)
+	}
+	if tail, err := s.Reviews.ListPublished(ctx, loc.ID, 10, 2); err != nil || len(tail) != 1 {
+		t.Errorf("ListPublished со смещением 2: %d отзывов, %v", len(tail), err)
+	}
+
+	pendingBefore, err := s.Reviews.CountByStatus(ctx, "pending")
+	t.must(err, "CountByStatus")
+	t.must(s.Reviews.UpdateStatus(ctx, r3, "pending"), "UpdateStatus")
+	if n, err := s.Reviews.CountPublished(ctx, loc.ID); err != nil || n != 2 {
+		t.Errorf("CountPublished после снятия с публикации: %d, %v", n, err)
+	}
+	if n, err := s.Reviews.CountByStatus(ctx, "pending"); err != nil || n != pendingBefore+1 {
+		t.Errorf("CountByStatus(pending): %d, ожидалось %d (%v)", n, pendingBefore+1, err)
+	}
+	t.must(s.Reviews.RecalculateRating(ctx, loc.ID), "RecalculateRating")
+	t.expectRating(loc.ID, 4.5)
+	queue, err := s.Reviews.ListByStatus(ctx, "pending", 1000)
+	t.must(err, "ListByStatus")
+	found := false
+	for _, r := range queue {
+		if r.ID == r3 {
+			found = r.AuthorName == "Тест"
+		}
+		if r.Status != "pending" {
+			t.Errorf("ListByStatus(pending) вернул отзыв в статусе %q", r.Status)
+		}
+	}
+	if !found {
+		t.Errorf("ListByStatus не вернул отзыв %d с именем автора", r3)
+	}
+
+	t.must(s.Reviews.AddPhoto(ctx, r1, "review-photo-1"), "AddPhoto")
+	t.must(s.Reviews.AddPhoto(ctx, r1, "review-photo-2"), "AddPhoto")
+	t.must(s.Reviews.SetReply(ctx, r1, "Спасибо!"), "SetReply")
+	got, err = s.Reviews.GetByID(ctx, r1)
+	t.must(err, "GetByID")
+	if strings.Join(got.PhotoFileIDs, ",") != "review-photo-1,review-photo-2" {
+		t.Errorf("фото отзыва: %v", got.PhotoFileIDs)
+	}
+	if got.Reply != "Спасибо!" || got.RepliedAt == nil {
+		t.Errorf("ответ на отзыв: %q, %v", got.Reply, got.RepliedAt)
+	}
+
+	// повтор текста ищется среди отзывов других пользователей без учета регистра и пробелов
+	variant := strings.ToUpper(strings.Replace(text, "   ", "\t", 1))
+	if ok, err := s.Reviews.DuplicateTextExists(ctx, other.ID, variant); err != nil || !ok {
+		t.Errorf("DuplicateTextExists(%q): %v, %v", variant, ok, err)
+	}
+	if ok, err := s.Reviews.DuplicateTextExists(ctx, first.ID, variant); err != nil || ok {
+		t.Errorf("DuplicateTextExists для собственного отзыва: %v, %v", ok, err)
+	}
+
+	low := t.newUser("user", "ru")
+	create(low, 2, "")
+	if n, err := s.Reviews.CountLowRatings(ctx, loc.ID, 2, time.Now().Add(-time.Hour)); err != nil || n != 1 {
+		t.Errorf("CountLowRatings за час: %d, %v", n, err)
+	}
+	if n, err := s.Reviews.CountLowRatings(ctx, loc.ID, 4, time.Now().Add(-time.Hour)); err != nil || n != 3 {
+		t.Errorf("CountLowRatings с порогом 4: %d, %v", n, err)
+	}
+	if n, err := s.Reviews.CountLowRatings(ctx, loc.ID, 2, time.Now().Add(time.Hour)); err != nil || n != 0 {
+		t.Errorf("CountLowRatings после since в будущем: %d, %v", n, err)
+	}
+
+	t.must(s.Reviews.AddModerationEntry(ctx, &model.ModerationEntry{ReviewID: r3, Action: model.ModerationHeld, Reason: model.FlagLink}), "AddModerationEntry")
+	t.must(s.Reviews.AddModerationEntry(ctx, &model.ModerationEntry{ReviewID: r3, ModeratorID: &moderator.ID, Action: model.ModerationApproved}), "AddModerationEntry")
+	entries, err := s.Reviews.ListModerationEntries(ctx, r3)
+	t.must(err, "ListModerationEntries")
+	if len(entries) != 2 || entries[0].Action != model.ModerationHeld || entries[0].ModeratorID != nil ||
+		entries[0].Reason != model.FlagLink || entries[1].ModeratorID == nil || *entries[1].ModeratorID != moderator.ID ||
+		entries[1].CreatedAt.IsZero() {
+		t.Errorf("журнал модерации: %+v", entries)
+	}
+	if err := s.Reviews.AddModerationEntry(ctx, &model.ModerationEntry{ReviewID: -1, Action: model.ModerationHeld}); err == nil {
+		t.Errorf("запись журнала для несуществующего отзыва должна отклоняться")
+	}
+}
+
+func (t *T) expectRating(locationID int, want float64) {
+	ctx := t.Context()
+	loc, err := t.stores.Locations.GetByID(ctx, locationID)
+	t.must(err, "GetByID")
+	if math.Abs(loc.Rating-want) > 1e-9 {
+		t.Errorf("рейтинг локации %v, ожидался %v", loc.Rating, want)
+	}
+}
+
+func checkStates(t *T) {
+	ctx := t.Context()
+	s := t.Stores()
+	id, stale, forever := t.telegramID(), t.telegramID(), t.telegramID()
+
+	if st, err := s.States.Get(ctx, id); err != nil || st != nil {
+		t.Errorf("Get без диалога: %+v, %v; ожидалось nil, nil", st, err)
+	}
+	expires := time.Now().Add(time.Hour)
+	t.must(s.States.Save(ctx, &model.ConversationState{
+		TelegramID: id, Flow: "booking", Step: "details", Payload: types.JSONText(`{"offer_id": 5}`), ExpiresAt: &expires,
+	}), "Save")
+	st, err := s.States.Get(ctx, id)
+	t.must(err, "Get")
+	if st == nil || st.Flow != "booking" || st.Step != "details" || st.UpdatedAt.IsZero() ||
+		st.ExpiresAt == nil || !sameTime(*st.ExpiresAt, expires) {
+		t.Fatalf("Get вернул %+v", st)
+	}
+	t.expectPayload(st.Payload, map[string]interface{}{"offer_id": 5.0})
+
+	// повторное сохранение заменяет сценарий, пустые данные хранятся как {}
+	t.must(s.States.Save(ctx, &model.ConversationState{TelegramID: id, Flow: "review", Step: "rating"}), "Save")
+	st, err = s.States.Get(ctx, id)
+	t.must(err, "Get")
+	if st == nil || st.Flow != "review" || st.Step != "rating" || st.ExpiresAt != nil {
+		t.Fatalf("после замены Get вернул %+v", st)
+	}
+	t.expectPayload(st.Payload, map[string]interface{}{})
+
+	past := time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+	t.must(s.States.Save(ctx, &model.ConversationState{TelegramID: stale, Flow: "search", Step: "query", ExpiresAt: &past}), "Save")
+	t.must(s.States.Save(ctx, &model.ConversationState{TelegramID: forever, Flow: "chat", Step: "relay"}), "Save")
+	t.must(s.States.Save(ctx, &model.ConversationState{TelegramID: id, Flow: "review", Step: "rating", ExpiresAt: &expires}), "Save")
+	n, err := s.States.DeleteExpired(ctx, time.Now())
+	t.must(err, "DeleteExpired")
+	if n < 1 {
+		t.Errorf("DeleteExpired удалил %d состояний", n)
+	}
+	if st, err := s.States.Get(ctx, stale); err != nil || st != nil {
+		t.Errorf("истекшее состояние не удалено: %+v, %v", st, err)
+	}
+	for _, keep := range []int64{id, forever} {
+		if st, err := s.States.Get(ctx, keep); err != nil || st == nil {
+			t.Errorf("DeleteExpired удалил действующее состояние %d (%v)", keep, err)
+		}
+	}
+
+	t.must(s.States.Delete(ctx, id), "Delete")
+	t.must(s.States.Delete(ctx, forever), "Delete")
+	if st, err := s.States.Get(ctx, id); err != nil || st != nil {
+		t.Errorf("Get после Delete: %+v, %v", st, err)
+	}
+}
+
+func (t *T) expectPayload(payload types.JSONText, want map[string]interface{}) {
+	got := map[string]interface{}{}
+	if err := json.Unmarshal(payload, &got); err != nil {
+		t.Errorf("данные сценария %q: %v", payload, err)
+		return
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("данные сценария %v, ожидались %v", got, want)
+	}
+}
@@ -0,0 +1,78 @@
+package repotest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"tourism/internal/model"
+)
+
+var errRollback = errors.New("откат проверки")
+
+func checkTransactions(t *T) {
+	s := t.Stores()
+	ctx := t.Context()
+	if s.Tx == nil {
+		t.Fatalf("не задан Stores.Tx")
+	}
+
+	// зафиксированная транзакция сохраняет изменения всех хранилищ
+	var committed *model.User
+	err := s.Tx.WithinTx(ctx, func(ctx context.Context) error {
+		committed = &model.User{TelegramID: t.telegramID(), Username: t.unique("tx"), Role: "user"}
+		id, err := s.Users.Create(ctx, committed)
+		if err != nil {
+			return err
+		}
+		committed.ID = id
+		_, err = s.Bookings.Create(ctx, &model.Booking{UserID: id, LocationID: t.newLocation(t.unique("Регион"), "Природа", nil).ID, Status: "pending"})
+		return err
+	})
+	t.must(err, "WithinTx")
+	if _, err := s.Users.GetByTelegramID(ctx, committed.TelegramID); err != nil {
+		t.Errorf("после фиксации пользователь не найден: %v", err)
+	}
+
+	// ошибка fn откатывает все изменения, в том числе сделанные во вложенном WithinTx
+	rolledBack := &model.User{TelegramID: t.telegramID(), Username: t.unique("tx"), Role: "user"}
+	var bookingID int
+	err = s.Tx.WithinTx(ctx, func(ctx context.Context) error {
+		id, err := s.Users.Create(ctx, rolledBack)
+		if err != nil {
+			return err
+		}
+		return s.Tx.WithinTx(ctx, func(ctx context.Context) error {
+			loc := t.newLocation(t.unique("Регион"), "Природа", nil)
+			bookingID, err = s.Bookings.Create(ctx, &model.Booking{UserID: id, LocationID: loc.ID, Status: "pending"})
+			if err != nil {
+				return err
+			}
+			booking, err := s.Bookings.GetByIDForUpdate(ctx, bookingID)
+			if err != nil {
+				return err
+			}
+			if booking.UserID != id {
+				t.Errorf("GetByIDForUpdate вернул бронирование пользователя %d, ожидался %d", booking.UserID, id)
+			}
+			return errRollback
+		})
+	})
+	if !errors.Is(err, errRollback) {
+		t.Errorf("WithinTx вернул %v, ожидалась ошибка fn", err)
+	}
+	if _, err := s.Users.GetByTelegramID(ctx, rolledBack.TelegramID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("после отката пользователь остался: %v", err)
+	}
+	if _, err := s.Bookings.GetByID(ctx, bookingID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("после отката бронирование из вложенной транзакции осталось: %v", err)
+	}
+
+	// отмененный контекст не начинает транзакцию
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	called := false
+	if err := s.Tx.WithinTx(canceled, func(context.Context) error { called = true; return nil }); err == nil || called {
+		t.Errorf("WithinTx с отмененным контекстом: ошибка %v, fn вызвана %v", err, called)
+	}
+}
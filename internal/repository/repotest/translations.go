@@ -0,0 +1,102 @@
+package repotest
+
+import (
+	"database/sql"
+	"errors"
+
+	"tourism/internal/model"
+)
+
+func checkTranslations(t *T) {
+	ctx := t.Context()
+	s := t.Stores()
+	provider := t.newUser("provider", "ru")
+	support := t.newUser("support", "ru")
+	first := t.newLocation(t.unique("repotest-translations"), "museum", &provider.ID)
+	second := t.newLocation(t.unique("repotest-translations"), "museum", &provider.ID)
+
+	create := func(entity string, entityID int, locale, name, status string) int {
+		id, err := s.Translations.Create(ctx, &model.Translation{
+			Entity: entity, EntityID: entityID, Locale: locale, Name: name, Description: name + " description",
+			Status: status, AuthorID: &provider.ID,
+		})
+		t.must(err, "Create")
+		return id
+	}
+	firstEn := create(model.TranslationLocation, first.ID, "en", "First", model.TranslationApproved)
+	secondEn := create(model.TranslationLocation, second.ID, "en", "Second", model.TranslationPending)
+	firstOs := create(model.TranslationLocation, first.ID, "os", "Фыццаг", model.TranslationApproved)
+	create(model.TranslationOffer, first.ID, "en", "Offer with the same ID", model.TranslationApproved)
+
+	got, err := s.Translations.GetByID(ctx, secondEn)
+	t.must(err, "GetByID")
+	if got.Entity != model.TranslationLocation || got.EntityID != second.ID || got.Locale != "en" || got.Name != "Second" ||
+		got.Description != "Second description" || got.Status != model.TranslationPending || got.AuthorID == nil ||
+		*got.AuthorID != provider.ID || got.ReviewerID != nil || got.ReviewedAt != nil || got.CreatedAt.IsZero() {
+		t.Errorf("GetByID вернул %+v", got)
+	}
+	if _, err := s.Translations.GetByID(ctx, -1); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetByID для неизвестного перевода: %v, ожидалось sql.ErrNoRows", err)
+	}
+	if _, err := s.Translations.Create(ctx, &model.Translation{Entity: "trip", EntityID: first.ID, Locale: "en", Name: "Trip"}); err == nil {
+		t.Errorf("перевод неизвестной сущности должен отклоняться")
+	}
+	if _, err := s.Translations.Create(ctx, &model.Translation{
+		Entity: model.TranslationLocation, EntityID: first.ID, Locale: "en", Name: "Duplicate", Status: model.TranslationApproved,
+	}); err == nil {
+		t.Errorf("второй одобренный перевод записи на язык должен отклоняться")
+	}
+
+	approved, err := s.Translations.ListApproved(ctx, model.TranslationLocation, []int{first.ID, second.ID}, "en")
+	t.must(err, "ListApproved")
+	if len(approved) != 1 || approved[0].ID != firstEn {
+		t.Errorf("ListApproved(en) вернул %+v", approved)
+	}
+	if approved, err := s.Translations.ListApproved(ctx, model.TranslationLocation, []int{first.ID}, "os"); err != nil ||
+		len(approved) != 1 || approved[0].ID != firstOs {
+		t.Errorf("ListApproved(os) вернул %+v, %v", approved, err)
+	}
+	if approved, err := s.Translations.ListApproved(ctx, model.TranslationLocation, nil, "en"); err != nil || len(approved) != 0 {
+		t.Errorf("ListApproved без записей вернул %+v, %v", approved, err)
+	}
+
+	queue, err := s.Translations.ListByStatus(ctx, model.TranslationPending, 1000)
+	t.must(err, "ListByStatus")
+	found := false
+	for _, tr := range queue {
+		found = found || tr.ID == secondEn
+		if tr.Status != model.TranslationPending {
+			t.Errorf("ListByStatus(pending) вернул перевод в статусе %q", tr.Status)
+		}
+	}
+	if !found {
+		t.Errorf("ListByStatus не вернул перевод %d", secondEn)
+	}
+
+	// замена одобренного перевода: старый получает статус replaced, новый становится действующим
+	newer := create(model.TranslationLocation, first.ID, "en", "First, revised", model.TranslationPending)
+	if err := s.Translations.UpdateStatus(ctx, newer, model.TranslationApproved, &support.ID, ""); err == nil {
+		t.Errorf("одобрение второго перевода без замены первого должно отклоняться")
+	}
+	t.must(s.Translations.ReplaceApproved(ctx, model.TranslationLocation, first.ID, "en"), "ReplaceApproved")
+	t.must(s.Translations.UpdateStatus(ctx, newer, model.TranslationApproved, &support.ID, ""), "UpdateStatus")
+	if old, err := s.Translations.GetByID(ctx, firstEn); err != nil || old.Status != model.TranslationReplaced {
+		t.Errorf("замененный перевод: %+v, %v", old, err)
+	}
+	got, err = s.Translations.GetByID(ctx, newer)
+	t.must(err, "GetByID")
+	if got.Status != model.TranslationApproved || got.ReviewerID == nil || *got.ReviewerID != support.ID || got.ReviewedAt == nil {
+		t.Errorf("одобренный перевод: %+v", got)
+	}
+	if approved, err := s.Translations.ListApproved(ctx, model.TranslationLocation, []int{first.ID}, "en"); err != nil ||
+		len(approved) != 1 || approved[0].ID != newer {
+		t.Errorf("ListApproved после замены вернул %+v, %v", approved, err)
+	}
+
+	t.must(s.Translations.UpdateStatus(ctx, secondEn, model.TranslationRejected, &support.ID, "машинный перевод"), "UpdateStatus")
+	got, err = s.Translations.GetByID(ctx, secondEn)
+	t.must(err, "GetByID")
+	if got.Status != model.TranslationRejected || got.Reason != "машинный перевод" || got.ReviewedAt == nil {
+		t.Errorf("отклоненный перевод: %+v", got)
+	}
+}
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"tourism/internal/model"
+)
+
+// ReviewRepository обеспечивает доступ к отзывам о локациях.
+type ReviewRepository struct {
+	db *DB
+}
+
+// NewReviewRepository создает новый репозиторий отзывов.
+func NewReviewRepository(db *DB) *ReviewRepository {
+	return &ReviewRepository{db: db}
+}
+
+// Create сохраняет новый отзыв. Возвращает ID созданной записи.
+func (r *ReviewRepository) Create(ctx context.Context, review *model.Review) (int, error) {
+	query := `INSERT INTO reviews (location_id, user_id, rating, text, photo_file_ids, status, moderation_flags)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+	var id int
+	err := r.db.Get(ctx, &id, query, review.LocationID, review.UserID, review.Rating, review.Text,
+		review.PhotoFileIDs, review.Status, review.Flags)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось сохранить отзыв: %w", err)
+	}
+	return id, nil
+}
+
+// GetByID возвращает отзыв по ID.
+func (r *ReviewRepository) GetByID(ctx context.Context, id int) (*model.Review, error) {
+	var review model.Review
+	err := r.db.Get(ctx, &review, "SELECT * FROM reviews WHERE id=$1", id)
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// GetByIDForUpdate возвращает отзыв по ID и блокирует запись до конца транзакции.
+func (r *ReviewRepository) GetByIDForUpdate(ctx context.Context, id int) (*model.Review, error) {
+	var review model.Review
+	err := r.db.Get(ctx, &review, "SELECT * FROM reviews WHERE id=$1 FOR UPDATE", id)
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// Exists проверяет, оставлял ли пользователь отзыв о локации.
+func (r *ReviewRepository) Exists(ctx context.Context, userID int, locationID int) (bool, error) {
+	var exists bool
+	err := r.db.Get(ctx, &exists, "SELECT EXISTS (SELECT 1 FROM reviews WHERE user_id=$1 AND location_id=$2)", userID, locationID)
+	return exists, err
+}
+
+// ListPublished возвращает опубликованные отзывы о локации, начиная с новых.
+func (r *ReviewRepository) ListPublished(ctx context.Context, locationID int, limit int, offset int) ([]model.Review, error) {
+	reviews := []model.Review{}
+	err := r.db.Select(ctx, &reviews,
+		`SELECT r.*, COALESCE(u.first_name, '') AS author_name FROM reviews r
+		 JOIN users u ON r.user_id = u.id
+		 WHERE r.location_id=$1 AND r.status='published'
+		 ORDER BY r.created_at DESC
+		 LIMIT $2 OFFSET $3`, locationID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении отзывов: %w", err)
+	}
+	return reviews, nil
+}
+
+// CountPublished возвращает число опубликованных отзывов о локации.
+func (r *ReviewRepository) CountPublished(ctx context.Context, locationID int) (int, error) {
+	var n int
+	err := r.db.Get(ctx, &n, "SELECT COUNT(*) FROM reviews WHERE location_id=$1 AND status='published'", locationID)
+	return n, err
+}
+
+// AddPhoto добавляет фото к отзыву.
+func (r *ReviewRepository) AddPhoto(ctx context.Context, id int, fileID string) error {
+	_, err := r.db.Exec(ctx, "UPDATE reviews SET photo_file_ids = ARRAY_APPEND(photo_file_ids, $1) WHERE id=$2", fileID, id)
+	if err != nil {
+		return fmt.Errorf("ошибка при сохранении фото отзыва: %w", err)
+	}
+	return nil
+}
+
+// SetReply сохраняет публичный ответ провайдера на отзыв.
+func (r *ReviewRepository) SetReply(ctx context.Context, id int, reply string) error {
+	_, err := r.db.Exec(ctx, "UPDATE reviews SET reply=$1, replied_at=NOW() WHERE id=$2", reply, id)
+	if err != nil {
+		return fmt.Errorf("не удалось сохранить ответ на отзыв: %w", err)
+	}
+	return nil
+}
+
+// CanReview проверяет, что пользователь бывал в локации: у него есть подтвержденное бронирование
+// или завершенный маршрут, содержащий эту локацию. Черновик маршрута не в счет.
+func (r *ReviewRepository) CanReview(ctx context.Context, userID int, locationID int) (bool, error) {
+	var ok bool
+	err := r.db.Get(ctx, &ok,
+		`SELECT EXISTS (SELECT 1 FROM bookings
+		                WHERE user_id=$1 AND location_id=$2 AND status='confirmed')
+		     OR EXISTS (SELECT 1 FROM trips t JOIN trip_locations tl ON tl.trip_id = t.id
+		                WHERE t.user_id=$1 AND tl.location_id=$2 AND t.status='completed')`, userID, locationID)
+	return ok, err
+}
+
+// RecalculateRating пересчитывает рейтинг локации как среднюю оценку опубликованных отзывов.
+func (r *ReviewRepository) RecalculateRating(ctx context.Context, locationID int) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE locations SET rating = COALESCE(
+		     (SELECT ROUND(AVG(rating)::NUMERIC, 2) FROM reviews WHERE location_id=$1 AND status='published'), 0)
+		 WHERE id=$1`, locationID)
+	if err != nil {
+		return fmt.Errorf("не удалось пересчитать рейтинг локации: %w", err)
+	}
+	return nil
+}
+
+// UpdateStatus обновляет статус отзыва.
+func (r *ReviewRepository) UpdateStatus(ctx context.Context, id int, status string) error {
+	_, err := r.db.Exec(ctx, "UPDATE reviews SET status=$1 WHERE id=$2", status, id)
+	if err != nil {
+		return fmt.Errorf("не удалось обновить статус отзыва: %w", err)
+	}
+	return nil
+}
+
+// ListByStatus возвращает отзывы с указанным статусом, начиная со старых.
+func (r *ReviewRepository) ListByStatus(ctx context.Context, status string, limit int) ([]model.Review, error) {
+	reviews := []model.Review{}
+	err := r.db.Select(ctx, &reviews,
+		`SELECT r.*, COALESCE(u.first_name, '') AS author_name FROM reviews r
+		 JOIN users u ON r.user_id = u.id
+		 WHERE r.status=$1
+		 ORDER BY r.created_at
+		 LIMIT $2`, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении очереди отзывов: %w", err)
+	}
+	return reviews, nil
+}
+
+// CountByStatus возвращает число отзывов с указанным статусом.
+func (r *ReviewRepository) CountByStatus(ctx context.Context, status string) (int, error) {
+	var n int
+	err := r.db.Get(ctx, &n, "SELECT COUNT(*) FROM reviews WHERE status=$1", status)
+	return n, err
+}
+
+// DuplicateTextExists проверяет, есть ли у других пользователей отзыв с тем же текстом
+// (без учета регистра и пробелов).
+func (r *ReviewRepository) DuplicateTextExists(ctx context.Context, userID int, text string) (bool, error) {
+	var exists bool
+	err := r.db.Get(ctx, &exists,
+		`SELECT EXISTS (SELECT 1 FROM reviews
+		                WHERE user_id <> $1
+		                  AND LOWER(REGEXP_REPLACE(text, '\s+', ' ', 'g')) = LOWER(REGEXP_REPLACE($2, '\s+', ' ', 'g')))`,
+		userID, text)
+	return exists, err
+}
+
+// CountLowRatings возвращает число отзывов с оценкой не выше maxRating о локации, оставленных после since.
+func (r *ReviewRepository) CountLowRatings(ctx context.Context, locationID int, maxRating int, since time.Time) (int, error) {
+	var n int
+	err := r.db.Get(ctx, &n,
+		"SELECT COUNT(*) FROM reviews WHERE location_id=$1 AND rating <= $2 AND created_at >= $3",
+		locationID, maxRating, since)
+	return n, err
+}
+
+// AddModerationEntry добавляет запись в журнал модерации.
+func (r *ReviewRepository) AddModerationEntry(ctx context.Context, entry *model.ModerationEntry) error {
+	_, err := r.db.Exec(ctx,
+		"INSERT INTO review_moderation_log (review_id, moderator_id, action, reason) VALUES ($1, $2, $3, $4)",
+		entry.ReviewID, entry.ModeratorID, entry.Action, entry.Reason)
+	if err != nil {
+		return fmt.Errorf("не удалось записать решение модерации: %w", err)
+	}
+	return nil
+}
+
+// ListModerationEntries возвращает журнал модерации отзыва.
+func (r *ReviewRepository) ListModerationEntries(ctx context.Context, reviewID int) ([]model.ModerationEntry, error) {
+	entries := []model.ModerationEntry{}
+	err := r.db.Select(ctx, &entries, "SELECT * FROM review_moderation_log WHERE review_id=$1 ORDER BY id", reviewID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении журнала модерации: %w", err)
+	}
+	return entries, nil
+}
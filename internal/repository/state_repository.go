@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"tourism/internal/model"
+)
+
+// StateRepository хранит состояние диалогов пользователей с ботом.
+type StateRepository struct {
+	db *DB
+}
+
+// NewStateRepository создает новый репозиторий состояний диалогов.
+func NewStateRepository(db *DB) *StateRepository {
+	return &StateRepository{db: db}
+}
+
+// Get возвращает состояние диалога пользователя или nil, если диалог не начат.
+func (r *StateRepository) Get(ctx context.Context, telegramID int64) (*model.ConversationState, error) {
+	var state model.ConversationState
+	err := r.db.Get(ctx, &state, "SELECT * FROM conversation_states WHERE telegram_id=$1", telegramID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении состояния диалога: %w", err)
+	}
+	return &state, nil
+}
+
+// Save сохраняет состояние диалога, заменяя предыдущее.
+func (r *StateRepository) Save(ctx context.Context, state *model.ConversationState) error {
+	payload := state.Payload
+	if len(payload) == 0 {
+		payload = []byte("{}")
+	}
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO conversation_states (telegram_id, flow, step, payload, updated_at, expires_at)
+		 VALUES ($1, $2, $3, $4, NOW(), $5)
+		 ON CONFLICT (telegram_id) DO UPDATE SET flow=EXCLUDED.flow, step=EXCLUDED.step, payload=EXCLUDED.payload,
+		     updated_at=EXCLUDED.updated_at, expires_at=EXCLUDED.expires_at`,
+		state.TelegramID, state.Flow, state.Step, payload, state.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("не удалось сохранить состояние диалога: %w", err)
+	}
+	return nil
+}
+
+// Delete удаляет состояние диалога пользователя.
+func (r *StateRepository) Delete(ctx context.Context, telegramID int64) error {
+	_, err := r.db.Exec(ctx, "DELETE FROM conversation_states WHERE telegram_id=$1", telegramID)
+	if err != nil {
+		return fmt.Errorf("не удалось сбросить состояние диалога: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired удаляет состояния, время ожидания которых истекло к моменту now. Возвращает число удаленных записей.
+func (r *StateRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	res, err := r.db.Exec(ctx, "DELETE FROM conversation_states WHERE expires_at <= $1", now)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось удалить устаревшие состояния диалогов: %w", err)
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,236 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"tourism/internal/model"
+)
+
+// Интерфейсы хранилищ, от которых зависят сервисы. Реализуются репозиториями поверх PostgreSQL
+// из этого пакета и хранилищами в памяти из пакета memory; общее поведение обеих реализаций
+// проверяет пакет repotest. Методы Get*/GetBy* возвращают sql.ErrNoRows, если запись не найдена.
+// Все методы принимают контекст: его отмена и срок прерывают запрос, а транзакция, начатая
+// через Transactor.WithinTx, передается в хранилища тоже через контекст. Методы *ForUpdate
+// внутри транзакции блокируют запись до ее завершения, чтобы проверка состояния и его изменение
+// не пересекались с параллельными изменениями той же записи.
+
+// UserStore — хранилище пользователей. Search находит пользователей по ID или Telegram ID
+// (если запрос — число), а также по подстроке username, имени или фамилии без учета регистра.
+type UserStore interface {
+	Create(ctx context.Context, user *model.User) (int, error)
+	GetByTelegramID(ctx context.Context, telegramID int64) (*model.User, error)
+	GetByID(ctx context.Context, id int) (*model.User, error)
+	GetByIDForUpdate(ctx context.Context, id int) (*model.User, error)
+	UpdateLanguageCode(ctx context.Context, id int, languageCode string) error
+	UpdateLanguage(ctx context.Context, id int, language string) error
+	UpdateRole(ctx context.Context, id int, role string) error
+	SetActive(ctx context.Context, id int, active bool) error
+	SetBlocked(ctx context.Context, id int, blocked bool) error
+	ListByRole(ctx context.Context, role string) ([]model.User, error)
+	Search(ctx context.Context, query string, limit int) ([]model.User, error)
+}
+
+// LocationStore — хранилище локаций и их фотографий. Списки FindAll, FindByFilters, ListRegions
+// и ListWithoutPhotos не включают скрытые локации, GetByID и ListByProvider — включают.
+type LocationStore interface {
+	Create(ctx context.Context, loc *model.Location) (int, error)
+	FindAll(ctx context.Context) ([]model.Location, error)
+	FindByFilters(ctx context.Context, category string, region string, minRating float64, keyword string) ([]model.Location, error)
+	ListRegions(ctx context.Context) ([]string, error)
+	GetByID(ctx context.Context, id int) (*model.Location, error)
+	GetByIDForUpdate(ctx context.Context, id int) (*model.Location, error)
+	SetProvider(ctx context.Context, id int, providerID *int) error
+	Update(ctx context.Context, loc *model.Location) error
+	SetHidden(ctx context.Context, id int, hidden bool) error
+	ListByProvider(ctx context.Context, providerID int) ([]model.Location, error)
+	AddPhoto(ctx context.Context, locationID int, fileID string, caption string) (int, error)
+	GetPhoto(ctx context.Context, id int) (*model.LocationPhoto, error)
+	GetPhotos(ctx context.Context, locationID int) ([]model.LocationPhoto, error)
+	SetPhotoOrder(ctx context.Context, locationID int, photoIDs []int) error
+	SetPhotoCaption(ctx context.Context, id int, caption string) error
+	SetCoverPhoto(ctx context.Context, locationID int, photoID int) error
+	DeletePhoto(ctx context.Context, id int) error
+	ListPhotosToStore(ctx context.Context, maxAttempts int, limit int) ([]model.LocationPhoto, error)
+	MarkPhotoStored(ctx context.Context, id int, storageKey string, contentType string, width int, height int) error
+	MarkPhotoStoreFailed(ctx context.Context, id int, reason string) error
+	ListWithoutPhotos(ctx context.Context) ([]model.Location, error)
+}
+
+// TripStore — хранилище маршрутов.
+type TripStore interface {
+	Create(ctx context.Context, userID int, name string) (int, error)
+	AddLocation(ctx context.Context, tripID int, locationID int) error
+	UpdateOrder(ctx context.Context, tripID int, locationOrder []int) error
+	GetLocations(ctx context.Context, tripID int) ([]model.Location, error)
+	GetActive(ctx context.Context, userID int) (*model.Trip, error)
+	GetByID(ctx context.Context, id int) (*model.Trip, error)
+	ListByUser(ctx context.Context, userID int, limit int) ([]model.Trip, error)
+	Complete(ctx context.Context, id int) (bool, error)
+}
+
+// BookingStore — хранилище бронирований.
+type BookingStore interface {
+	Create(ctx context.Context, booking *model.Booking) (int, error)
+	GetByID(ctx context.Context, id int) (*model.Booking, error)
+	GetByIDForUpdate(ctx context.Context, id int) (*model.Booking, error)
+	UpdateStatus(ctx context.Context, id int, status string) error
+	ListByProvider(ctx context.Context, providerID int, limit int) ([]model.Booking, error)
+	ListByUser(ctx context.Context, userID int, limit int) ([]model.Booking, error)
+}
+
+// MessageStore — хранилище сообщений чатов.
+type MessageStore interface {
+	Save(ctx context.Context, msg *model.Message) error
+	ListByBooking(ctx context.Context, bookingID int) ([]model.Message, error)
+	ListSupportMessages(ctx context.Context, userID int) ([]model.Message, error)
+}
+
+// OfferStore — хранилище предложений провайдеров. ListByType не включает скрытые предложения
+// и предложения скрытых локаций, GetByID и ListByLocation — включают.
+type OfferStore interface {
+	Create(ctx context.Context, o *model.Offer) (int, error)
+	ListByType(ctx context.Context, offerType string) ([]model.Offer, error)
+	GetByID(ctx context.Context, id int) (*model.Offer, error)
+	GetByIDForUpdate(ctx context.Context, id int) (*model.Offer, error)
+	Update(ctx context.Context, o *model.Offer) error
+	SetHidden(ctx context.Context, id int, hidden bool) error
+	ListByLocation(ctx context.Context, locationID int) ([]model.Offer, error)
+}
+
+// SubscriptionStore — хранилище подписок на предложения.
+type SubscriptionStore interface {
+	Subscribe(ctx context.Context, userID int) error
+	Unsubscribe(ctx context.Context, userID int) error
+	GetByUserID(ctx context.Context, userID int) (*model.OfferSubscription, error)
+	SavePreferences(ctx context.Context, sub *model.OfferSubscription) error
+	GetAllSubscriberTelegramIDs(ctx context.Context) ([]int64, error)
+	GetSubscriberTelegramIDsBySegment(ctx context.Context, segment model.Segment) ([]int64, error)
+}
+
+// CampaignStore — хранилище рассылок.
+type CampaignStore interface {
+	Create(ctx context.Context, c *model.Campaign) (int, error)
+	GetByID(ctx context.Context, id int) (*model.Campaign, error)
+	GetByIDForUpdate(ctx context.Context, id int) (*model.Campaign, error)
+	UpdateSegment(ctx context.Context, id int, segment model.Segment) error
+	Schedule(ctx context.Context, id int, at time.Time) error
+	UpdateStatus(ctx context.Context, id int, status string) error
+	ClaimDue(ctx context.Context, now time.Time) ([]model.Campaign, error)
+	CompleteFinished(ctx context.Context) ([]int, error)
+}
+
+// DeliveryStore — очередь доставки рассылок.
+type DeliveryStore interface {
+	ClaimPending(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]model.CampaignDelivery, error)
+	MarkSent(ctx context.Context, id int) error
+	MarkFailed(ctx context.Context, id int, status string, reason string) error
+	Retry(ctx context.Context, id int, at time.Time, reason string, countAttempt bool) error
+	Stats(ctx context.Context, campaignID int) (*model.DeliveryStats, error)
+}
+
+// NotificationStore — очередь уведомлений пользователям (outbox).
+type NotificationStore interface {
+	Enqueue(ctx context.Context, n *model.Notification) (int, error)
+	GetByID(ctx context.Context, id int) (*model.Notification, error)
+	ClaimPending(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]model.Notification, error)
+	MarkSent(ctx context.Context, id int, telegramID int64) error
+	MarkFailed(ctx context.Context, id int, status string, reason string) error
+	Retry(ctx context.Context, id int, at time.Time, reason string, countAttempt bool) error
+}
+
+// DigestStore — подбор и учет элементов еженедельного дайджеста.
+type DigestStore interface {
+	DueSubscribers(ctx context.Context, before time.Time, now time.Time, leaseUntil time.Time, limit int) ([]model.DigestSubscriber, error)
+	NewLocations(ctx context.Context, userID int, regions []string, since time.Time, limit int) ([]model.Location, error)
+	NewOffers(ctx context.Context, userID int, topics []string, regions []string, since time.Time, limit int) ([]model.Offer, error)
+	Record(ctx context.Context, userID int, digest *model.Digest, at time.Time) error
+}
+
+// ReviewStore — хранилище отзывов и журнала их модерации.
+type ReviewStore interface {
+	Create(ctx context.Context, review *model.Review) (int, error)
+	GetByID(ctx context.Context, id int) (*model.Review, error)
+	GetByIDForUpdate(ctx context.Context, id int) (*model.Review, error)
+	Exists(ctx context.Context, userID int, locationID int) (bool, error)
+	ListPublished(ctx context.Context, locationID int, limit int, offset int) ([]model.Review, error)
+	CountPublished(ctx context.Context, locationID int) (int, error)
+	AddPhoto(ctx context.Context, id int, fileID string) error
+	SetReply(ctx context.Context, id int, reply string) error
+	CanReview(ctx context.Context, userID int, locationID int) (bool, error)
+	RecalculateRating(ctx context.Context, locationID int) error
+	UpdateStatus(ctx context.Context, id int, status string) error
+	ListByStatus(ctx context.Context, status string, limit int) ([]model.Review, error)
+	CountByStatus(ctx context.Context, status string) (int, error)
+	DuplicateTextExists(ctx context.Context, userID int, text string) (bool, error)
+	CountLowRatings(ctx context.Context, locationID int, maxRating int, since time.Time) (int, error)
+	AddModerationEntry(ctx context.Context, entry *model.ModerationEntry) error
+	ListModerationEntries(ctx context.Context, reviewID int) ([]model.ModerationEntry, error)
+}
+
+// TranslationStore — хранилище переводов локаций и предложений.
+type TranslationStore interface {
+	Create(ctx context.Context, t *model.Translation) (int, error)
+	GetByID(ctx context.Context, id int) (*model.Translation, error)
+	GetByIDForUpdate(ctx context.Context, id int) (*model.Translation, error)
+	ListApproved(ctx context.Context, entity string, entityIDs []int, locale string) ([]model.Translation, error)
+	ListByStatus(ctx context.Context, status string, limit int) ([]model.Translation, error)
+	UpdateStatus(ctx context.Context, id int, status string, reviewerID *int, reason string) error
+	ReplaceApproved(ctx context.Context, entity string, entityID int, locale string) error
+}
+
+// ProviderApplicationStore — хранилище заявок на роль провайдера.
+type ProviderApplicationStore interface {
+	Create(ctx context.Context, a *model.ProviderApplication) (int, error)
+	GetByID(ctx context.Context, id int) (*model.ProviderApplication, error)
+	GetByIDForUpdate(ctx context.Context, id int) (*model.ProviderApplication, error)
+	GetLatestByUser(ctx context.Context, userID int) (*model.ProviderApplication, error)
+	ListByStatus(ctx context.Context, status string, limit int) ([]model.ProviderApplication, error)
+	UpdateStatus(ctx context.Context, id int, status string, reviewerID *int, reason string) error
+}
+
+// ContentChangeStore — хранилище изменений каталога, присланных провайдерами.
+type ContentChangeStore interface {
+	Create(ctx context.Context, c *model.ContentChange) (int, error)
+	GetByID(ctx context.Context, id int) (*model.ContentChange, error)
+	GetByIDForUpdate(ctx context.Context, id int) (*model.ContentChange, error)
+	ListByStatus(ctx context.Context, status string, limit int) ([]model.ContentChange, error)
+	UpdateStatus(ctx context.Context, id int, status string, reviewerID *int, reason string) error
+	SetEntity(ctx context.Context, id int, entityID int) error
+	ReplacePending(ctx context.Context, entity string, entityID int) error
+}
+
+// AuditStore — журнал действий администраторов. Списки возвращаются начиная с новых записей.
+type AuditStore interface {
+	Add(ctx context.Context, e *model.AuditEntry) (int, error)
+	ListRecent(ctx context.Context, limit int) ([]model.AuditEntry, error)
+	ListByTarget(ctx context.Context, userID int, limit int) ([]model.AuditEntry, error)
+}
+
+// StateStore — хранилище состояния диалогов бота.
+type StateStore interface {
+	Get(ctx context.Context, telegramID int64) (*model.ConversationState, error)
+	Save(ctx context.Context, state *model.ConversationState) error
+	Delete(ctx context.Context, telegramID int64) error
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+var (
+	_ UserStore                = (*UserRepository)(nil)
+	_ LocationStore            = (*LocationRepository)(nil)
+	_ TripStore                = (*TripRepository)(nil)
+	_ BookingStore             = (*BookingRepository)(nil)
+	_ MessageStore             = (*MessageRepository)(nil)
+	_ OfferStore               = (*OfferRepository)(nil)
+	_ SubscriptionStore        = (*SubscriptionRepository)(nil)
+	_ CampaignStore            = (*CampaignRepository)(nil)
+	_ DeliveryStore            = (*DeliveryRepository)(nil)
+	_ NotificationStore        = (*NotificationRepository)(nil)
+	_ DigestStore              = (*DigestRepository)(nil)
+	_ ReviewStore              = (*ReviewRepository)(nil)
+	_ TranslationStore         = (*TranslationRepository)(nil)
+	_ ProviderApplicationStore = (*ProviderApplicationRepository)(nil)
+	_ ContentChangeStore       = (*ContentChangeRepository)(nil)
+	_ AuditStore               = (*AuditRepository)(nil)
+	_ StateStore               = (*StateRepository)(nil)
+)
@@ -1,24 +1,29 @@
 package repository
 
 import (
+	"context"
 	"fmt"
+	"strings"
+
+	"tourism/internal/model"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 // SubscriptionRepository обеспечивает доступ к данным подписчиков на рассылки.
 type SubscriptionRepository struct {
-	db *sqlx.DB
+	db *DB
 }
 
 // NewSubscriptionRepository создает новый репозиторий подписок.
-func NewSubscriptionRepository(db *sqlx.DB) *SubscriptionRepository {
+func NewSubscriptionRepository(db *DB) *SubscriptionRepository {
 	return &SubscriptionRepository{db: db}
 }
 
 // Subscribe добавляет пользователя в список подписчиков (если еще не подписан).
-func (r *SubscriptionRepository) Subscribe(userID int) error {
-	_, err := r.db.Exec("INSERT INTO offer_subscriptions (user_id) VALUES ($1) ON CONFLICT DO NOTHING", userID)
+func (r *SubscriptionRepository) Subscribe(ctx context.Context, userID int) error {
+	_, err := r.db.Exec(ctx, "INSERT INTO offer_subscriptions (user_id) VALUES ($1) ON CONFLICT DO NOTHING", userID)
 	if err != nil {
 		return fmt.Errorf("не удалось оформить подписку: %w", err)
 	}
@@ -26,22 +31,100 @@ func (r *SubscriptionRepository) Subscribe(userID int) error {
 }
 
 // Unsubscribe удаляет пользователя из подписчиков.
-func (r *SubscriptionRepository) Unsubscribe(userID int) error {
-	_, err := r.db.Exec("DELETE FROM offer_subscriptions WHERE user_id=$1", userID)
+func (r *SubscriptionRepository) Unsubscribe(ctx context.Context, userID int) error {
+	_, err := r.db.Exec(ctx, "DELETE FROM offer_subscriptions WHERE user_id=$1", userID)
 	if err != nil {
 		return fmt.Errorf("не удалось отменить подписку: %w", err)
 	}
 	return nil
 }
 
+// GetByUserID возвращает подписку пользователя. Если пользователь не подписан, возвращает sql.ErrNoRows.
+func (r *SubscriptionRepository) GetByUserID(ctx context.Context, userID int) (*model.OfferSubscription, error) {
+	var sub model.OfferSubscription
+	err := r.db.Get(ctx, &sub, "SELECT * FROM offer_subscriptions WHERE user_id=$1", userID)
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// SavePreferences сохраняет темы, регионы и частоту подписки (создает подписку, если ее нет).
+func (r *SubscriptionRepository) SavePreferences(ctx context.Context, sub *model.OfferSubscription) error {
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO offer_subscriptions (user_id, topics, regions, frequency) VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (user_id) DO UPDATE SET topics=EXCLUDED.topics, regions=EXCLUDED.regions, frequency=EXCLUDED.frequency`,
+		sub.UserID, sub.Topics, sub.Regions, sub.Frequency)
+	if err != nil {
+		return fmt.Errorf("не удалось сохранить настройки подписки: %w", err)
+	}
+	return nil
+}
+
 // GetAllSubscriberTelegramIDs возвращает Telegram ID всех пользователей, подписанных на предложения.
-func (r *SubscriptionRepository) GetAllSubscriberTelegramIDs() ([]int64, error) {
+func (r *SubscriptionRepository) GetAllSubscriberTelegramIDs(ctx context.Context) ([]int64, error) {
 	ids := []int64{}
-	err := r.db.Select(&ids,
+	err := r.db.Select(ctx, &ids,
 		`SELECT u.telegram_id FROM offer_subscriptions s 
-		 JOIN users u ON s.user_id = u.id`)
+		 JOIN users u ON s.user_id = u.id
+		 WHERE u.is_active`)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка при получении списка подписчиков: %w", err)
 	}
 	return ids, nil
 }
+
+// interestLocations выбирает локации, которыми интересовался пользователь u: из его бронирований и маршрутов.
+const interestLocations = `(SELECT b.location_id FROM bookings b WHERE b.user_id = u.id
+	UNION SELECT tl.location_id FROM trips t JOIN trip_locations tl ON tl.trip_id = t.id WHERE t.user_id = u.id)`
+
+// GetSubscriberTelegramIDsBySegment возвращает Telegram ID активных подписчиков, попадающих в сегмент аудитории.
+func (r *SubscriptionRepository) GetSubscriberTelegramIDsBySegment(ctx context.Context, segment model.Segment) ([]int64, error) {
+	query, args := segmentAudienceQuery("u.telegram_id", segment)
+	query = sqlx.Rebind(sqlx.DOLLAR, query)
+	ids := []int64{}
+	if err := r.db.Select(ctx, &ids, query, args...); err != nil {
+		return nil, fmt.Errorf("ошибка при выборке аудитории рассылки: %w", err)
+	}
+	return ids, nil
+}
+
+// segmentAudienceQuery строит запрос (с плейсхолдерами "?") по активным подписчикам, попадающим в сегмент.
+// columns — выбираемые колонки, пользователь доступен под псевдонимом u.
+func segmentAudienceQuery(columns string, segment model.Segment) (string, []interface{}) {
+	// подписчики еженедельного дайджеста не получают разовые рассылки
+	query := "SELECT " + columns + " FROM offer_subscriptions s JOIN users u ON s.user_id = u.id" +
+		" WHERE u.is_active AND s.frequency = 'instant'"
+	args := []interface{}{}
+	if len(segment.Topics) > 0 {
+		query += " AND s.topics && ?"
+		args = append(args, pq.Array(segment.Topics))
+	}
+	if len(segment.Regions) > 0 {
+		regions := pq.Array(lowerAll(segment.Regions))
+		query += " AND (EXISTS (SELECT 1 FROM UNNEST(s.regions) r WHERE LOWER(r) = ANY(?))" +
+			" OR EXISTS (SELECT 1 FROM locations l WHERE l.id IN " + interestLocations + " AND LOWER(l.region) = ANY(?)))"
+		args = append(args, regions, regions)
+	}
+	if len(segment.Categories) > 0 {
+		query += " AND EXISTS (SELECT 1 FROM locations l WHERE l.id IN " + interestLocations + " AND LOWER(l.category) = ANY(?))"
+		args = append(args, pq.Array(lowerAll(segment.Categories)))
+	}
+	if segment.WithBookings {
+		query += " AND EXISTS (SELECT 1 FROM bookings b WHERE b.user_id = u.id AND b.status <> 'rejected')"
+	}
+	if len(segment.Languages) > 0 {
+		// язык — выбранный пользователем, иначе из профиля Telegram; "en-US" и "en" считаются одним языком
+		query += " AND SPLIT_PART(LOWER(COALESCE(NULLIF(u.language, ''), u.language_code)), '-', 1) = ANY(?)"
+		args = append(args, pq.Array(lowerAll(segment.Languages)))
+	}
+	return query, args
+}
+
+func lowerAll(values []string) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = strings.ToLower(v)
+	}
+	return out
+}
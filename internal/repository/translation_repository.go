@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"tourism/internal/model"
+
+	"github.com/lib/pq"
+)
+
+// TranslationRepository обеспечивает доступ к переводам локаций и предложений в базе данных.
+type TranslationRepository struct {
+	db *DB
+}
+
+// NewTranslationRepository создает новый репозиторий переводов.
+func NewTranslationRepository(db *DB) *TranslationRepository {
+	return &TranslationRepository{db: db}
+}
+
+// Create сохраняет новый перевод. Возвращает ID созданной записи.
+func (r *TranslationRepository) Create(ctx context.Context, t *model.Translation) (int, error) {
+	query := `INSERT INTO translations (entity, entity_id, locale, name, description, status, author_id)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+	var id int
+	err := r.db.Get(ctx, &id, query, t.Entity, t.EntityID, t.Locale, t.Name, t.Description, t.Status, t.AuthorID)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось сохранить перевод: %w", err)
+	}
+	return id, nil
+}
+
+// GetByID возвращает перевод по ID.
+func (r *TranslationRepository) GetByID(ctx context.Context, id int) (*model.Translation, error) {
+	var t model.Translation
+	err := r.db.Get(ctx, &t, "SELECT * FROM translations WHERE id=$1", id)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// GetByIDForUpdate возвращает перевод по ID и блокирует запись до конца транзакции.
+func (r *TranslationRepository) GetByIDForUpdate(ctx context.Context, id int) (*model.Translation, error) {
+	var t model.Translation
+	err := r.db.Get(ctx, &t, "SELECT * FROM translations WHERE id=$1 FOR UPDATE", id)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// ListApproved возвращает одобренные переводы на язык locale для записей entityIDs.
+func (r *TranslationRepository) ListApproved(ctx context.Context, entity string, entityIDs []int, locale string) ([]model.Translation, error) {
+	translations := []model.Translation{}
+	if len(entityIDs) == 0 {
+		return translations, nil
+	}
+	ids := make(pq.Int64Array, len(entityIDs))
+	for i, id := range entityIDs {
+		ids[i] = int64(id)
+	}
+	err := r.db.Select(ctx, &translations,
+		`SELECT * FROM translations
+		 WHERE entity=$1 AND entity_id = ANY($2) AND locale=$3 AND status='approved'
+		 ORDER BY entity_id`, entity, ids, locale)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении переводов: %w", err)
+	}
+	return translations, nil
+}
+
+// ListByStatus возвращает переводы с указанным статусом, начиная со старых.
+func (r *TranslationRepository) ListByStatus(ctx context.Context, status string, limit int) ([]model.Translation, error) {
+	translations := []model.Translation{}
+	err := r.db.Select(ctx, &translations,
+		"SELECT * FROM translations WHERE status=$1 ORDER BY created_at, id LIMIT $2", status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении очереди переводов: %w", err)
+	}
+	return translations, nil
+}
+
+// UpdateStatus записывает решение по переводу: статус, проверяющего и причину отклонения.
+func (r *TranslationRepository) UpdateStatus(ctx context.Context, id int, status string, reviewerID *int, reason string) error {
+	_, err := r.db.Exec(ctx,
+		"UPDATE translations SET status=$1, reviewer_id=$2, reason=$3, reviewed_at=NOW() WHERE id=$4",
+		status, reviewerID, reason, id)
+	if err != nil {
+		return fmt.Errorf("не удалось обновить статус перевода: %w", err)
+	}
+	return nil
+}
+
+// ReplaceApproved переводит действующий одобренный перевод записи на язык locale в статус replaced.
+func (r *TranslationRepository) ReplaceApproved(ctx context.Context, entity string, entityID int, locale string) error {
+	_, err := r.db.Exec(ctx,
+		"UPDATE translations SET status='replaced' WHERE entity=$1 AND entity_id=$2 AND locale=$3 AND status='approved'",
+		entity, entityID, locale)
+	if err != nil {
+		return fmt.Errorf("не удалось заменить перевод: %w", err)
+	}
+	return nil
+}
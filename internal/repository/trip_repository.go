@@ -1,65 +1,69 @@
 package repository
 
 import (
+	"context"
 	"fmt"
 
 	"tourism/internal/model"
-
-	"github.com/jmoiron/sqlx"
 )
 
 // TripRepository обеспечивает доступ к данным маршрутов (поездок) в базе данных.
 type TripRepository struct {
-	db *sqlx.DB
+	db *DB
 }
 
 // NewTripRepository создает новый репозиторий для поездок.
-func NewTripRepository(db *sqlx.DB) *TripRepository {
+func NewTripRepository(db *DB) *TripRepository {
 	return &TripRepository{db: db}
 }
 
 // Create создает новую поездку (маршрут) для указанного пользователя.
-func (r *TripRepository) Create(userID int, name string) (int, error) {
+func (r *TripRepository) Create(ctx context.Context, userID int, name string) (int, error) {
 	query := `INSERT INTO trips (user_id, name, status) VALUES ($1, $2, $3) RETURNING id`
 	var id int
-	err := r.db.QueryRow(query, userID, name, "draft").Scan(&id)
+	err := r.db.Get(ctx, &id, query, userID, name, "draft")
 	if err != nil {
 		return 0, fmt.Errorf("не удалось создать поездку: %w", err)
 	}
 	return id, nil
 }
 
-// AddLocation добавляет локацию в маршрут (в конец списка).
-func (r *TripRepository) AddLocation(tripID int, locationID int) error {
-	var order int
-	r.db.Get(&order, "SELECT COALESCE(MAX(order_index), 0) + 1 FROM trip_locations WHERE trip_id=$1", tripID)
-	_, err := r.db.Exec("INSERT INTO trip_locations (trip_id, location_id, order_index) VALUES ($1, $2, $3)", tripID, locationID, order)
-	if err != nil {
-		return fmt.Errorf("ошибка при добавлении локации в маршрут: %w", err)
-	}
-	return nil
+// AddLocation добавляет локацию в маршрут (в конец списка). Строка маршрута блокируется
+// до конца транзакции, поэтому параллельные добавления не получают одинаковый порядковый номер.
+func (r *TripRepository) AddLocation(ctx context.Context, tripID int, locationID int) error {
+	return r.db.WithinTx(ctx, func(ctx context.Context) error {
+		var locked int
+		if err := r.db.Get(ctx, &locked, "SELECT id FROM trips WHERE id=$1 FOR UPDATE", tripID); err != nil {
+			return fmt.Errorf("ошибка при добавлении локации в маршрут: %w", err)
+		}
+		_, err := r.db.Exec(ctx,
+			`INSERT INTO trip_locations (trip_id, location_id, order_index)
+			 SELECT $1, $2, COALESCE(MAX(order_index), 0) + 1 FROM trip_locations WHERE trip_id=$1`,
+			tripID, locationID)
+		if err != nil {
+			return fmt.Errorf("ошибка при добавлении локации в маршрут: %w", err)
+		}
+		return nil
+	})
 }
 
 // UpdateOrder обновляет порядок следования локаций в маршруте.
-func (r *TripRepository) UpdateOrder(tripID int, locationOrder []int) error {
-	tx, err := r.db.Begin()
-	if err != nil {
-		return err
-	}
-	for idx, locID := range locationOrder {
-		_, err := tx.Exec("UPDATE trip_locations SET order_index=$1 WHERE trip_id=$2 AND location_id=$3", idx+1, tripID, locID)
-		if err != nil {
-			tx.Rollback()
-			return fmt.Errorf("не удалось обновить порядок маршрута: %w", err)
+func (r *TripRepository) UpdateOrder(ctx context.Context, tripID int, locationOrder []int) error {
+	return r.db.WithinTx(ctx, func(ctx context.Context) error {
+		for idx, locID := range locationOrder {
+			_, err := r.db.Exec(ctx, "UPDATE trip_locations SET order_index=$1 WHERE trip_id=$2 AND location_id=$3", idx+1, tripID, locID)
+			if err != nil {
+				return fmt.Errorf("не удалось обновить порядок маршрута: %w", err)
+			}
 		}
-	}
-	return tx.Commit()
+		return nil
+	})
 }
 
 // GetLocations возвращает список локаций (с полями Location) для заданного маршрута в текущем порядке.
-func (r *TripRepository) GetLocations(tripID int) ([]model.Location, error) {
+func (r *TripRepository) GetLocations(ctx context.Context, tripID int) ([]model.Location, error) {
 	locations := []model.Location{}
-	err := r.db.Select(&locations,
+	err := r.db.Select(ctx, &locations,
 		`SELECT l.* FROM trip_locations tl 
 		 JOIN locations l ON tl.location_id = l.id 
 		 WHERE tl.trip_id=$1 
@@ -69,3 +73,47 @@ func (r *TripRepository) GetLocations(tripID int) ([]model.Location, error) {
 	}
 	return locations, nil
 }
+
+// GetActive возвращает последний черновик маршрута пользователя. Если черновиков нет, возвращает sql.ErrNoRows.
+func (r *TripRepository) GetActive(ctx context.Context, userID int) (*model.Trip, error) {
+	var trip model.Trip
+	err := r.db.Get(ctx, &trip, "SELECT * FROM trips WHERE user_id=$1 AND status='draft' ORDER BY id DESC LIMIT 1", userID)
+	if err != nil {
+		return nil, err
+	}
+	return &trip, nil
+}
+
+// GetByID возвращает маршрут по ID. Если маршрута нет, возвращает sql.ErrNoRows.
+func (r *TripRepository) GetByID(ctx context.Context, id int) (*model.Trip, error) {
+	var trip model.Trip
+	err := r.db.Get(ctx, &trip, "SELECT * FROM trips WHERE id=$1", id)
+	if err != nil {
+		return nil, err
+	}
+	return &trip, nil
+}
+
+// ListByUser возвращает маршруты пользователя, начиная с новых (не более limit).
+func (r *TripRepository) ListByUser(ctx context.Context, userID int, limit int) ([]model.Trip, error) {
+	trips := []model.Trip{}
+	err := r.db.Select(ctx, &trips, "SELECT * FROM trips WHERE user_id=$1 ORDER BY id DESC LIMIT $2", userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении маршрутов пользователя: %w", err)
+	}
+	return trips, nil
+}
+
+// Complete переводит черновик маршрута в статус completed. Возвращает false, если маршрут
+// уже завершен или не найден: из параллельных завершений проходит одно.
+func (r *TripRepository) Complete(ctx context.Context, id int) (bool, error) {
+	res, err := r.db.Exec(ctx, "UPDATE trips SET status='completed' WHERE id=$1 AND status='draft'", id)
+	if err != nil {
+		return false, fmt.Errorf("не удалось завершить маршрут: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("не удалось завершить маршрут: %w", err)
+	}
+	return n > 0, nil
+}
@@ -1,29 +1,30 @@
 package repository
 
 import (
+	"context"
 	"fmt"
+	"strings"
 
 	"tourism/internal/model"
-
-	"github.com/jmoiron/sqlx"
 )
 
 // UserRepository обеспечивает доступ к данным пользователей в базе данных.
 type UserRepository struct {
-	db *sqlx.DB
+	db *DB
 }
 
 // NewUserRepository создаёт новый репозиторий пользователей.
-func NewUserRepository(db *sqlx.DB) *UserRepository {
+func NewUserRepository(db *DB) *UserRepository {
 	return &UserRepository{db: db}
 }
 
 // Create добавляет нового пользователя в базу. Возвращает ID созданного пользователя.
-func (r *UserRepository) Create(user *model.User) (int, error) {
-	query := `INSERT INTO users (telegram_id, username, first_name, last_name, role)
-	          VALUES ($1, $2, $3, $4, $5) RETURNING id`
+func (r *UserRepository) Create(ctx context.Context, user *model.User) (int, error) {
+	query := `INSERT INTO users (telegram_id, username, first_name, last_name, role, language_code, language)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
 	var id int
-	err := r.db.QueryRow(query, user.TelegramID, user.Username, user.FirstName, user.LastName, user.Role).Scan(&id)
+	err := r.db.Get(ctx, &id, query, user.TelegramID, user.Username, user.FirstName, user.LastName, user.Role,
+		user.LanguageCode, user.Language)
 	if err != nil {
 		return 0, fmt.Errorf("не удалось создать пользователя: %w", err)
 	}
@@ -31,9 +32,9 @@ func (r *UserRepository) Create(user *model.User) (int, error) {
 }
 
 // GetByTelegramID ищет пользователя по его Telegram ID. Возвращает nil, если не найдено.
-func (r *UserRepository) GetByTelegramID(telegramID int64) (*model.User, error) {
+func (r *UserRepository) GetByTelegramID(ctx context.Context, telegramID int64) (*model.User, error) {
 	var user model.User
-	err := r.db.Get(&user, "SELECT * FROM users WHERE telegram_id=$1", telegramID)
+	err := r.db.Get(ctx, &user, "SELECT * FROM users WHERE telegram_id=$1", telegramID)
 	if err != nil {
 		// sqlx.Get возвращает ошибку, если не найдено (sql.ErrNoRows и др.)
 		return nil, err
@@ -42,11 +43,98 @@ func (r *UserRepository) GetByTelegramID(telegramID int64) (*model.User, error)
 }
 
 // GetByID возвращает пользователя по внутреннему идентификатору.
-func (r *UserRepository) GetByID(id int) (*model.User, error) {
+func (r *UserRepository) GetByID(ctx context.Context, id int) (*model.User, error) {
+	var user model.User
+	err := r.db.Get(ctx, &user, "SELECT * FROM users WHERE id=$1", id)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetByIDForUpdate возвращает пользователя по ID, блокируя запись до конца транзакции.
+func (r *UserRepository) GetByIDForUpdate(ctx context.Context, id int) (*model.User, error) {
 	var user model.User
-	err := r.db.Get(&user, "SELECT * FROM users WHERE id=$1", id)
+	err := r.db.Get(ctx, &user, "SELECT * FROM users WHERE id=$1 FOR UPDATE", id)
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
+
+// UpdateLanguageCode сохраняет language_code пользователя из профиля Telegram.
+func (r *UserRepository) UpdateLanguageCode(ctx context.Context, id int, languageCode string) error {
+	_, err := r.db.Exec(ctx, "UPDATE users SET language_code=$1 WHERE id=$2", languageCode, id)
+	if err != nil {
+		return fmt.Errorf("не удалось обновить язык пользователя: %w", err)
+	}
+	return nil
+}
+
+// UpdateLanguage сохраняет язык интерфейса, выбранный пользователем.
+func (r *UserRepository) UpdateLanguage(ctx context.Context, id int, language string) error {
+	_, err := r.db.Exec(ctx, "UPDATE users SET language=$1 WHERE id=$2", language, id)
+	if err != nil {
+		return fmt.Errorf("не удалось сохранить язык интерфейса: %w", err)
+	}
+	return nil
+}
+
+// UpdateRole меняет роль пользователя.
+func (r *UserRepository) UpdateRole(ctx context.Context, id int, role string) error {
+	_, err := r.db.Exec(ctx, "UPDATE users SET role=$1 WHERE id=$2", role, id)
+	if err != nil {
+		return fmt.Errorf("не удалось изменить роль пользователя: %w", err)
+	}
+	return nil
+}
+
+// SetActive помечает пользователя активным или неактивным (например, если он заблокировал бота).
+func (r *UserRepository) SetActive(ctx context.Context, id int, active bool) error {
+	_, err := r.db.Exec(ctx, "UPDATE users SET is_active=$1 WHERE id=$2", active, id)
+	if err != nil {
+		return fmt.Errorf("не удалось обновить активность пользователя: %w", err)
+	}
+	return nil
+}
+
+// SetBlocked блокирует пользователя или снимает блокировку.
+func (r *UserRepository) SetBlocked(ctx context.Context, id int, blocked bool) error {
+	_, err := r.db.Exec(ctx, "UPDATE users SET is_blocked=$1 WHERE id=$2", blocked, id)
+	if err != nil {
+		return fmt.Errorf("не удалось изменить блокировку пользователя: %w", err)
+	}
+	return nil
+}
+
+// ListByRole возвращает всех пользователей с указанной ролью.
+func (r *UserRepository) ListByRole(ctx context.Context, role string) ([]model.User, error) {
+	users := []model.User{}
+	err := r.db.Select(ctx, &users, "SELECT * FROM users WHERE role=$1 ORDER BY id", role)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении пользователей: %w", err)
+	}
+	return users, nil
+}
+
+// Search ищет пользователей по ID, Telegram ID, username, имени или фамилии (не более limit, в порядке ID).
+func (r *UserRepository) Search(ctx context.Context, query string, limit int) ([]model.User, error) {
+	query = strings.TrimPrefix(strings.TrimSpace(query), "@")
+	users := []model.User{}
+	if query == "" {
+		return users, nil
+	}
+	pattern := "%" + strings.ToLower(query) + "%"
+	err := r.db.Select(ctx, &users,
+		`SELECT * FROM users
+		 WHERE id::text = $1 OR telegram_id::text = $1
+		    OR LOWER(COALESCE(username, '')) LIKE $2
+		    OR LOWER(COALESCE(first_name, '')) LIKE $2
+		    OR LOWER(COALESCE(last_name, '')) LIKE $2
+		 ORDER BY id
+		 LIMIT $3`, query, pattern, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при поиске пользователей: %w", err)
+	}
+	return users, nil
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"tourism/internal/model"
+	"tourism/internal/repository"
+)
+
+// ErrNotAdmin возвращается, если действие с пользователями пытается выполнить не администратор.
+var ErrNotAdmin = errors.New("управлять пользователями может только администратор")
+
+// AdminService — управление пользователями: поиск, просмотр броней, маршрутов и обращений
+// в поддержку, смена ролей и блокировка. Все действия доступны только администраторам
+// и записываются в журнал (audit_log).
+type AdminService struct {
+	tx               repository.Transactor
+	userRepo         repository.UserStore
+	bookingRepo      repository.BookingStore
+	tripRepo         repository.TripStore
+	messageRepo      repository.MessageStore
+	auditRepo        repository.AuditStore
+	notificationRepo repository.NotificationStore
+}
+
+// NewAdminService создает сервис администрирования пользователей.
+func NewAdminService(tx repository.Transactor, userRepo repository.UserStore, bookingRepo repository.BookingStore,
+	tripRepo repository.TripStore, messageRepo repository.MessageStore, auditRepo repository.AuditStore,
+	notificationRepo repository.NotificationStore) *AdminService {
+	return &AdminService{tx: tx, userRepo: userRepo, bookingRepo: bookingRepo, tripRepo: tripRepo,
+		messageRepo: messageRepo, auditRepo: auditRepo, notificationRepo: notificationRepo}
+}
+
+// Search ищет пользователей по ID, Telegram ID, username или имени.
+func (s *AdminService) Search(ctx context.Context, adminID int, query string, limit int) ([]model.User, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("не указан запрос для поиска пользователей")
+	}
+	if err := s.requireAdmin(ctx, adminID); err != nil {
+		return nil, err
+	}
+	if err := s.audit(ctx, adminID, model.AuditUserSearch, nil, query); err != nil {
+		return nil, err
+	}
+	return s.userRepo.Search(ctx, query, limit)
+}
+
+// User возвращает профиль пользователя.
+func (s *AdminService) User(ctx context.Context, adminID int, userID int) (*model.User, error) {
+	if err := s.view(ctx, adminID, userID, "profile"); err != nil {
+		return nil, err
+	}
+	return s.userRepo.GetByID(ctx, userID)
+}
+
+// Bookings возвращает последние бронирования пользователя.
+func (s *AdminService) Bookings(ctx context.Context, adminID int, userID int, limit int) ([]model.Booking, error) {
+	if err := s.view(ctx, adminID, userID, "bookings"); err != nil {
+		return nil, err
+	}
+	return s.bookingRepo.ListByUser(ctx, userID, limit)
+}
+
+// Trips возвращает последние маршруты пользователя.
+func (s *AdminService) Trips(ctx context.Context, adminID int, userID int, limit int) ([]model.Trip, error) {
+	if err := s.view(ctx, adminID, userID, "trips"); err != nil {
+		return nil, err
+	}
+	return s.tripRepo.ListByUser(ctx, userID, limit)
+}
+
+// Tickets возвращает последние limit сообщений переписки пользователя с поддержкой в порядке отправки.
+func (s *AdminService) Tickets(ctx context.Context, adminID int, userID int, limit int) ([]model.Message, error) {
+	if err := s.view(ctx, adminID, userID, "tickets"); err != nil {
+		return nil, err
+	}
+	messages, err := s.messageRepo.ListSupportMessages(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) > limit {
+		messages = messages[len(messages)-limit:]
+	}
+	return messages, nil
+}
+
+// AuditLog возвращает последние записи журнала: о действиях с пользователем userID или все, если userID = 0.
+func (s *AdminService) AuditLog(ctx context.Context, adminID int, userID int, limit int) ([]model.AuditEntry, error) {
+	if err := s.requireAdmin(ctx, adminID); err != nil {
+		return nil, err
+	}
+	if userID == 0 {
+		return s.auditRepo.ListRecent(ctx, limit)
+	}
+	return s.auditRepo.ListByTarget(ctx, userID, limit)
+}
+
+// SetRole меняет роль пользователя; пользователь получает уведомление с новым меню.
+// Свою роль администратор менять не может, чтобы не остаться без администраторов.
+func (s *AdminService) SetRole(ctx context.Context, adminID int, userID int, role string) (*model.User, error) {
+	role = strings.ToLower(strings.TrimSpace(role))
+	if !model.IsRole(role) {
+		return nil, fmt.Errorf("неизвестная роль %q, допустимые: %s", role, strings.Join(model.Roles, ", "))
+	}
+	if adminID == userID {
+		return nil, fmt.Errorf("нельзя изменить собственную роль")
+	}
+	if err := s.requireAdmin(ctx, adminID); err != nil {
+		return nil, err
+	}
+	return s.changeRole(ctx, &adminID, userID, role)
+}
+
+// GrantRole меняет роль пользователя без проверки прав: для служебной команды, которой
+// назначают первого администратора. В журнал действие записывается без автора.
+func (s *AdminService) GrantRole(ctx context.Context, userID int, role string) (*model.User, error) {
+	if !model.IsRole(role) {
+		return nil, fmt.Errorf("неизвестная роль %q, допустимые: %s", role, strings.Join(model.Roles, ", "))
+	}
+	return s.changeRole(ctx, nil, userID, role)
+}
+
+func (s *AdminService) changeRole(ctx context.Context, adminID *int, userID int, role string) (*model.User, error) {
+	var user *model.User
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = s.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if user.Role == role {
+			return fmt.Errorf("у пользователя %d уже роль %s", userID, role)
+		}
+		if err := s.userRepo.UpdateRole(ctx, userID, role); err != nil {
+			return err
+		}
+		entryID, err := s.auditRepo.Add(ctx, &model.AuditEntry{ActorID: adminID, Action: model.AuditRoleChange,
+			TargetUserID: &userID, Details: user.Role + " -> " + role})
+		if err != nil {
+			return err
+		}
+		user.Role = role
+		return enqueueNotification(ctx, s.notificationRepo, userID, model.NotifyRoleChanged,
+			fmt.Sprintf("audit:%d:role", entryID), model.NotificationParams{"role": role})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Block блокирует пользователя: оба бота перестают отвечать ему, бронировать он не может.
+// Администратора сначала нужно лишить роли.
+func (s *AdminService) Block(ctx context.Context, adminID int, userID int, reason string) (*model.User, error) {
+	reason = strings.TrimSpace(reason)
+	if adminID == userID {
+		return nil, fmt.Errorf("нельзя заблокировать самого себя")
+	}
+	return s.setBlocked(ctx, adminID, userID, true, reason)
+}
+
+// Unblock снимает блокировку пользователя.
+func (s *AdminService) Unblock(ctx context.Context, adminID int, userID int) (*model.User, error) {
+	return s.setBlocked(ctx, adminID, userID, false, "")
+}
+
+func (s *AdminService) setBlocked(ctx context.Context, adminID int, userID int, blocked bool, reason string) (*model.User, error) {
+	if err := s.requireAdmin(ctx, adminID); err != nil {
+		return nil, err
+	}
+	var user *model.User
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = s.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if user.IsBlocked == blocked {
+			if blocked {
+				return fmt.Errorf("пользователь %d уже заблокирован", userID)
+			}
+			return fmt.Errorf("пользователь %d не заблокирован", userID)
+		}
+		if blocked && user.Role == "admin" {
+			return fmt.Errorf("нельзя заблокировать администратора, сначала измените его роль")
+		}
+		if err := s.userRepo.SetBlocked(ctx, userID, blocked); err != nil {
+			return err
+		}
+		action := model.AuditUnblock
+		if blocked {
+			action = model.AuditBlock
+		}
+		user.IsBlocked = blocked
+		return s.audit(ctx, adminID, action, &userID, reason)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// view проверяет права администратора и записывает в журнал просмотр данных пользователя.
+func (s *AdminService) view(ctx context.Context, adminID int, userID int, what string) error {
+	if err := s.requireAdmin(ctx, adminID); err != nil {
+		return err
+	}
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return err
+	}
+	return s.audit(ctx, adminID, model.AuditUserView, &userID, what)
+}
+
+func (s *AdminService) audit(ctx context.Context, adminID int, action string, userID *int, details string) error {
+	_, err := s.auditRepo.Add(ctx, &model.AuditEntry{ActorID: &adminID, Action: action, TargetUserID: userID, Details: details})
+	return err
+}
+
+// requireAdmin проверяет, что adminID — незаблокированный администратор.
+func (s *AdminService) requireAdmin(ctx context.Context, adminID int) error {
+	admin, err := s.userRepo.GetByID(ctx, adminID)
+	if err != nil || admin.Role != "admin" || admin.IsBlocked {
+		return ErrNotAdmin
+	}
+	return nil
+}
@@ -1,34 +1,41 @@
 package service
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"tourism/internal/model"
 	"tourism/internal/repository"
 )
 
 type AuthService struct {
-	userRepo *repository.UserRepository
+	userRepo repository.UserStore
 }
 
-func NewAuthService(userRepo *repository.UserRepository) *AuthService {
+func NewAuthService(userRepo repository.UserStore) *AuthService {
 	return &AuthService{userRepo: userRepo}
 }
 
-func (s *AuthService) AuthUser(telegramID int64, username, firstName, lastName string) (*model.User, error) {
-	user, err := s.userRepo.GetByTelegramID(telegramID)
+func (s *AuthService) AuthUser(ctx context.Context, telegramID int64, username, firstName, lastName, languageCode string) (*model.User, error) {
+	user, err := s.userRepo.GetByTelegramID(ctx, telegramID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 
 			newUser := &model.User{
-				TelegramID: telegramID,
-				Username:   username,
-				FirstName:  firstName,
-				LastName:   lastName,
-				Role:       "user",
+				TelegramID:   telegramID,
+				Username:     username,
+				FirstName:    firstName,
+				LastName:     lastName,
+				Role:         "user",
+				LanguageCode: languageCode,
+				// язык интерфейса по умолчанию — из профиля Telegram, пользователь меняет его командой /language
+				Language:  model.BaseLanguage(languageCode),
+				IsActive:  true,
+				CreatedAt: time.Now(),
 			}
-			id, err := s.userRepo.Create(newUser)
+			id, err := s.userRepo.Create(ctx, newUser)
 			if err != nil {
 				return nil, err
 			}
@@ -38,6 +45,17 @@ func (s *AuthService) AuthUser(telegramID int64, username, firstName, lastName s
 		// Другая ошибка выполнения запроса
 		return nil, fmt.Errorf("ошибка при поиске пользователя: %w", err)
 	}
-	// Пользователь найден, возвращаем его
+	// Пользователь снова пишет боту — значит, он его больше не блокирует
+	if !user.IsActive {
+		if err := s.userRepo.SetActive(ctx, user.ID, true); err == nil {
+			user.IsActive = true
+		}
+	}
+	// Пользователь найден: обновляем язык, если он изменился в Telegram
+	if languageCode != "" && languageCode != user.LanguageCode {
+		if err := s.userRepo.UpdateLanguageCode(ctx, user.ID, languageCode); err == nil {
+			user.LanguageCode = languageCode
+		}
+	}
 	return user, nil
 }
@@ -1,42 +1,145 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+
+	"tourism/internal/metrics"
 	"tourism/internal/model"
 	"tourism/internal/repository"
 )
 
+// ErrNotBookingProvider возвращается, если решение по брони принимает не провайдер ее локации.
+var ErrNotBookingProvider = errors.New("решение по брони может принять только провайдер локации")
+
+// ErrBookingStatus возвращается при недопустимой смене статуса бронирования (см. bookingTransitions).
+var ErrBookingStatus = errors.New("недопустимая смена статуса бронирования")
+
+// bookingTransitions перечисляет допустимые смены статуса: по новой заявке провайдер принимает
+// решение один раз, а подтвержденную бронь можно только отменить.
+var bookingTransitions = map[string][]string{
+	"pending":   {"confirmed", "rejected"},
+	"confirmed": {"cancelled"},
+}
+
 // BookingService содержит бизнес-логику, связанную с бронированиями.
 type BookingService struct {
-	bookingRepo *repository.BookingRepository
+	tx               repository.Transactor
+	bookingRepo      repository.BookingStore
+	locationRepo     repository.LocationStore
+	userRepo         repository.UserStore
+	notificationRepo repository.NotificationStore
 }
 
 // NewBookingService создает новый сервис бронирований.
-func NewBookingService(bookingRepo *repository.BookingRepository) *BookingService {
-	return &BookingService{bookingRepo: bookingRepo}
+func NewBookingService(tx repository.Transactor, bookingRepo repository.BookingStore, locationRepo repository.LocationStore,
+	userRepo repository.UserStore, notificationRepo repository.NotificationStore) *BookingService {
+	return &BookingService{tx: tx, bookingRepo: bookingRepo, locationRepo: locationRepo, userRepo: userRepo,
+		notificationRepo: notificationRepo}
 }
 
-// CreateBooking создает новую заявку на бронирование для пользователя.
-func (s *BookingService) CreateBooking(userID int, locationID int, details string) (int, error) {
+// CreateBooking создает новую заявку на бронирование для пользователя и ставит в очередь
+// уведомление провайдеру локации.
+// Заблокированные администратором пользователи бронировать не могут.
+func (s *BookingService) CreateBooking(ctx context.Context, userID int, locationID int, details string) (int, error) {
+	tourist, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if tourist.IsBlocked {
+		return 0, fmt.Errorf("пользователь %d заблокирован, бронирование недоступно", userID)
+	}
 	booking := &model.Booking{
 		UserID:     userID,
 		LocationID: locationID,
 		Details:    details,
 		Status:     "pending",
 	}
-	return s.bookingRepo.Create(booking)
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		id, err := s.bookingRepo.Create(ctx, booking)
+		if err != nil {
+			return err
+		}
+		booking.ID = id
+		loc, err := s.locationRepo.GetByID(ctx, locationID)
+		if err != nil {
+			return err
+		}
+		if loc.ProviderID == nil {
+			slog.WarnContext(ctx, "У локации нет провайдера, заявку некому подтвердить", "booking_id", id, "location_id", locationID)
+			return nil
+		}
+		return enqueueNotification(ctx, s.notificationRepo, *loc.ProviderID, model.NotifyBookingCreated,
+			fmt.Sprintf("booking:%d:created", id), model.NotificationParams{
+				"booking_id": strconv.Itoa(id),
+				"location":   loc.Name,
+				"from":       tourist.FirstName,
+				"details":    details,
+			})
+	})
+	if err != nil {
+		return 0, err
+	}
+	metrics.BookingTransitions.WithLabelValues("new", booking.Status).Inc()
+	return booking.ID, nil
+}
+
+// ConfirmBooking подтверждает заявку bookingID от имени провайдера providerID.
+func (s *BookingService) ConfirmBooking(ctx context.Context, providerID int, bookingID int) error {
+	return s.setStatus(ctx, providerID, bookingID, "confirmed", model.NotifyBookingConfirmed)
 }
 
-// ConfirmBooking устанавливает статус бронирования "confirmed".
-func (s *BookingService) ConfirmBooking(bookingID int) error {
-	return s.bookingRepo.UpdateStatus(bookingID, "confirmed")
+// RejectBooking отклоняет заявку bookingID от имени провайдера providerID.
+func (s *BookingService) RejectBooking(ctx context.Context, providerID int, bookingID int) error {
+	return s.setStatus(ctx, providerID, bookingID, "rejected", model.NotifyBookingRejected)
 }
 
-// RejectBooking устанавливает статус бронирования "rejected".
-func (s *BookingService) RejectBooking(bookingID int) error {
-	return s.bookingRepo.UpdateStatus(bookingID, "rejected")
+// setStatus меняет статус бронирования, ставит в очередь уведомление туристу kind и учитывает
+// переход в метриках. Менять статус может только провайдер локации брони (иначе ErrNotBookingProvider)
+// и только по bookingTransitions (иначе ErrBookingStatus). Бронирование блокируется на время смены,
+// чтобы из параллельных решений по нему прошло одно.
+func (s *BookingService) setStatus(ctx context.Context, providerID int, bookingID int, status string, kind string) error {
+	var from string
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		booking, err := s.bookingRepo.GetByIDForUpdate(ctx, bookingID)
+		if err != nil {
+			return err
+		}
+		loc, err := s.locationRepo.GetByID(ctx, booking.LocationID)
+		if err != nil {
+			return err
+		}
+		if loc.ProviderID == nil || *loc.ProviderID != providerID {
+			return ErrNotBookingProvider
+		}
+		from = booking.Status
+		if !slices.Contains(bookingTransitions[from], status) {
+			return fmt.Errorf("%w: бронь #%d %q -> %q", ErrBookingStatus, bookingID, from, status)
+		}
+		if err := s.bookingRepo.UpdateStatus(ctx, bookingID, status); err != nil {
+			return err
+		}
+		return enqueueNotification(ctx, s.notificationRepo, booking.UserID, kind,
+			fmt.Sprintf("booking:%d:%s", bookingID, status),
+			model.NotificationParams{"booking_id": strconv.Itoa(bookingID), "location": loc.Name})
+	})
+	if err != nil {
+		return err
+	}
+	metrics.BookingTransitions.WithLabelValues(from, status).Inc()
+	return nil
 }
 
 // GetBooking возвращает бронирование по ID.
-func (s *BookingService) GetBooking(bookingID int) (*model.Booking, error) {
-	return s.bookingRepo.GetByID(bookingID)
+func (s *BookingService) GetBooking(ctx context.Context, bookingID int) (*model.Booking, error) {
+	return s.bookingRepo.GetByID(ctx, bookingID)
+}
+
+// ListProviderBookings возвращает последние бронирования локаций провайдера.
+func (s *BookingService) ListProviderBookings(ctx context.Context, providerID int) ([]model.Booking, error) {
+	return s.bookingRepo.ListByProvider(ctx, providerID, 20)
 }
@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"tourism/internal/model"
+	"tourism/internal/repository"
+)
+
+// BroadcastService содержит логику подготовки и планирования рассылок по сегментам аудитории.
+type BroadcastService struct {
+	tx           repository.Transactor
+	campaignRepo repository.CampaignStore
+	deliveryRepo repository.DeliveryStore
+	subRepo      repository.SubscriptionStore
+	userRepo     repository.UserStore
+}
+
+// NewBroadcastService создает новый сервис рассылок.
+func NewBroadcastService(tx repository.Transactor, campaignRepo repository.CampaignStore, deliveryRepo repository.DeliveryStore,
+	subRepo repository.SubscriptionStore, userRepo repository.UserStore) *BroadcastService {
+	return &BroadcastService{tx: tx, campaignRepo: campaignRepo, deliveryRepo: deliveryRepo, subRepo: subRepo, userRepo: userRepo}
+}
+
+// CreateDraft создает черновик рассылки. Текст может содержать строки кнопок (см. ParseCampaignText).
+func (s *BroadcastService) CreateDraft(ctx context.Context, authorID int, text string, photoFileID string) (*model.Campaign, error) {
+	body, buttons, err := ParseCampaignText(text)
+	if err != nil {
+		return nil, err
+	}
+	if body == "" && photoFileID == "" {
+		return nil, fmt.Errorf("рассылка не может быть пустой")
+	}
+	c := &model.Campaign{
+		AuthorID:    &authorID,
+		Text:        body,
+		PhotoFileID: photoFileID,
+		Buttons:     buttons,
+		Status:      "draft",
+		CreatedAt:   time.Now(),
+	}
+	id, err := s.campaignRepo.Create(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	c.ID = id
+	return c, nil
+}
+
+// GetCampaign возвращает рассылку по ID.
+func (s *BroadcastService) GetCampaign(ctx context.Context, campaignID int) (*model.Campaign, error) {
+	return s.campaignRepo.GetByID(ctx, campaignID)
+}
+
+// SetSegment задает аудиторию черновика рассылки.
+func (s *BroadcastService) SetSegment(ctx context.Context, campaignID int, segment model.Segment) error {
+	return s.withStatus(ctx, campaignID, []string{"draft"}, func(ctx context.Context) error {
+		return s.campaignRepo.UpdateSegment(ctx, campaignID, segment)
+	})
+}
+
+// Schedule планирует отправку черновика рассылки на указанное время.
+func (s *BroadcastService) Schedule(ctx context.Context, campaignID int, at time.Time) error {
+	return s.withStatus(ctx, campaignID, []string{"draft"}, func(ctx context.Context) error {
+		return s.campaignRepo.Schedule(ctx, campaignID, at)
+	})
+}
+
+// SendNow ставит черновик рассылки в очередь на немедленную отправку.
+func (s *BroadcastService) SendNow(ctx context.Context, campaignID int) error {
+	return s.Schedule(ctx, campaignID, time.Now())
+}
+
+// Cancel отменяет черновик или запланированную рассылку.
+func (s *BroadcastService) Cancel(ctx context.Context, campaignID int) error {
+	return s.withStatus(ctx, campaignID, []string{"draft", "scheduled"}, func(ctx context.Context) error {
+		return s.campaignRepo.UpdateStatus(ctx, campaignID, "cancelled")
+	})
+}
+
+// Audience возвращает Telegram ID подписчиков, попадающих в сегмент.
+func (s *BroadcastService) Audience(ctx context.Context, segment model.Segment) ([]int64, error) {
+	return s.subRepo.GetSubscriberTelegramIDsBySegment(ctx, segment)
+}
+
+// ClaimDue забирает рассылки, время отправки которых наступило, и ставит их получателей в очередь доставки.
+func (s *BroadcastService) ClaimDue(ctx context.Context, now time.Time) ([]model.Campaign, error) {
+	return s.campaignRepo.ClaimDue(ctx, now)
+}
+
+// CompleteFinished завершает рассылки, все доставки которых обработаны. Возвращает их ID.
+func (s *BroadcastService) CompleteFinished(ctx context.Context) ([]int, error) {
+	return s.campaignRepo.CompleteFinished(ctx)
+}
+
+// ClaimDeliveries забирает из очереди до limit доставок, готовых к отправке.
+func (s *BroadcastService) ClaimDeliveries(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]model.CampaignDelivery, error) {
+	return s.deliveryRepo.ClaimPending(ctx, now, leaseUntil, limit)
+}
+
+// MarkDelivered отмечает доставку как успешную.
+func (s *BroadcastService) MarkDelivered(ctx context.Context, deliveryID int) error {
+	return s.deliveryRepo.MarkSent(ctx, deliveryID)
+}
+
+// MarkFailed окончательно завершает доставку с ошибкой.
+func (s *BroadcastService) MarkFailed(ctx context.Context, deliveryID int, reason string) error {
+	return s.deliveryRepo.MarkFailed(ctx, deliveryID, "failed", reason)
+}
+
+// MarkBlocked завершает доставку получателю, заблокировавшему бота, и помечает его неактивным,
+// чтобы он не попадал в следующие рассылки.
+func (s *BroadcastService) MarkBlocked(ctx context.Context, d *model.CampaignDelivery, reason string) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.deliveryRepo.MarkFailed(ctx, d.ID, "blocked", reason); err != nil {
+			return err
+		}
+		return s.userRepo.SetActive(ctx, d.UserID, false)
+	})
+}
+
+// RetryDelivery откладывает доставку до указанного времени.
+func (s *BroadcastService) RetryDelivery(ctx context.Context, deliveryID int, at time.Time, reason string, countAttempt bool) error {
+	return s.deliveryRepo.Retry(ctx, deliveryID, at, reason, countAttempt)
+}
+
+// Author возвращает автора рассылки.
+func (s *BroadcastService) Author(ctx context.Context, campaignID int) (*model.User, error) {
+	c, err := s.campaignRepo.GetByID(ctx, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	if c.AuthorID == nil {
+		return nil, fmt.Errorf("у рассылки #%d нет автора", campaignID)
+	}
+	return s.userRepo.GetByID(ctx, *c.AuthorID)
+}
+
+// Stats возвращает счетчики доставки рассылки.
+func (s *BroadcastService) Stats(ctx context.Context, campaignID int) (*model.DeliveryStats, error) {
+	return s.deliveryRepo.Stats(ctx, campaignID)
+}
+
+// withStatus выполняет change в транзакции, если рассылка находится в одном из статусов statuses.
+// Рассылка блокируется до конца транзакции, поэтому воркер не заберет ее между проверкой и изменением.
+func (s *BroadcastService) withStatus(ctx context.Context, campaignID int, statuses []string, change func(ctx context.Context) error) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		c, err := s.campaignRepo.GetByIDForUpdate(ctx, campaignID)
+		if err != nil {
+			return fmt.Errorf("рассылка #%d не найдена", campaignID)
+		}
+		for _, st := range statuses {
+			if c.Status == st {
+				return change(ctx)
+			}
+		}
+		return fmt.Errorf("рассылка #%d уже в статусе %q и не может быть изменена", campaignID, c.Status)
+	})
+}
+
+// ParseCampaignText отделяет текст рассылки от описания кнопок.
+// Кнопка задается отдельной строкой вида "btn: Текст | https://example.com" или "btn: Текст | LOC_5".
+func ParseCampaignText(text string) (string, model.CampaignButtons, error) {
+	body := []string{}
+	buttons := model.CampaignButtons{}
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(strings.ToLower(trimmed), "btn:") {
+			body = append(body, line)
+			continue
+		}
+		parts := strings.SplitN(strings.TrimSpace(trimmed[len("btn:"):]), "|", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return "", nil, fmt.Errorf("некорректная кнопка %q: ожидается \"btn: Текст | ссылка\"", trimmed)
+		}
+		btn := model.CampaignButton{Text: strings.TrimSpace(parts[0])}
+		target := strings.TrimSpace(parts[1])
+		if strings.HasPrefix(target, "LOC_") {
+			id, err := strconv.Atoi(strings.TrimPrefix(target, "LOC_"))
+			if err != nil || id <= 0 {
+				return "", nil, fmt.Errorf("некорректная ссылка на локацию %q", target)
+			}
+			btn.LocationID = id
+		} else {
+			u, err := url.Parse(target)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "tg") {
+				return "", nil, fmt.Errorf("некорректная ссылка %q", target)
+			}
+			btn.URL = target
+		}
+		buttons = append(buttons, btn)
+	}
+	return strings.TrimSpace(strings.Join(body, "\n")), buttons, nil
+}
+
+// ParseSegment разбирает описание аудитории вида
+// "topic=tour; region=Северная Осетия; category=Природа,История; bookings=yes; lang=ru,en".
+// Пустая строка или "all" означает всех подписчиков.
+func ParseSegment(input string) (model.Segment, error) {
+	segment := model.Segment{}
+	input = strings.TrimSpace(input)
+	if input == "" || strings.EqualFold(input, "all") || strings.EqualFold(input, "все") {
+		return segment, nil
+	}
+	for _, clause := range strings.Split(input, ";") {
+		clause = strings.TrimSpace(clause)
+		if clause == "" {
+			continue
+		}
+		kv := strings.SplitN(clause, "=", 2)
+		if len(kv) != 2 {
+			return segment, fmt.Errorf("некорректное условие %q: ожидается ключ=значение", clause)
+		}
+		key := strings.ToLower(strings.TrimSpace(kv[0]))
+		values := splitList(kv[1])
+		switch key {
+		case "topic":
+			for _, t := range values {
+				if !isTopic(t) {
+					return segment, fmt.Errorf("неизвестная тема %q (допустимы %s)", t, strings.Join(model.Topics, ", "))
+				}
+			}
+			segment.Topics = values
+		case "region":
+			segment.Regions = values
+		case "category":
+			segment.Categories = values
+		case "lang":
+			segment.Languages = values
+		case "bookings":
+			v := strings.ToLower(strings.TrimSpace(kv[1]))
+			switch v {
+			case "yes", "да", "true", "1":
+				segment.WithBookings = true
+			case "no", "нет", "false", "0":
+				segment.WithBookings = false
+			default:
+				return segment, fmt.Errorf("некорректное значение bookings: %q", v)
+			}
+		default:
+			return segment, fmt.Errorf("неизвестный ключ %q (допустимы topic, region, category, bookings, lang)", key)
+		}
+	}
+	return segment, nil
+}
+
+func splitList(s string) []string {
+	out := []string{}
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -1,47 +1,62 @@
 package service
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
-	"sync"
+	"time"
+
+	"tourism/internal/model"
 	"tourism/internal/repository"
 )
 
-// ChatService управляет состоянием чат-сессий между туристами и провайдерами.
+// ChatFlow — сценарий бота, в котором находятся участники чата: их сообщения пересылаются собеседнику.
+const ChatFlow = "chat"
+
+// ChatTTL — сколько чат остается открытым без сообщений.
+const ChatTTL = 24 * time.Hour
+
+// ChatSession — данные активного чата участника, хранятся в состоянии его диалога с ботом.
+type ChatSession struct {
+	Partner   int64 `json:"partner"` // Telegram ID собеседника
+	BookingID int   `json:"booking_id"`
+}
+
+// ChatService управляет чат-сессиями между туристами и провайдерами. Активные чаты хранятся
+// в состоянии диалогов, поэтому переживают перезапуск бота и видны всем его экземплярам.
 type ChatService struct {
-	bookingRepo  *repository.BookingRepository
-	userRepo     *repository.UserRepository
-	locationRepo *repository.LocationRepository
-	chatBooking  map[int64]int   // соответствие TelegramID пользователя -> BookingID чата
-	activeChats  map[int64]int64 // соответствие TelegramID пользователя -> TelegramID собеседника
-	mu           sync.Mutex
+	bookingRepo  repository.BookingStore
+	userRepo     repository.UserStore
+	locationRepo repository.LocationStore
+	stateRepo    repository.StateStore
 }
 
 // NewChatService создает новый сервис чата.
-func NewChatService(bookingRepo *repository.BookingRepository, userRepo *repository.UserRepository, locationRepo *repository.LocationRepository) *ChatService {
+func NewChatService(bookingRepo repository.BookingStore, userRepo repository.UserStore, locationRepo repository.LocationStore,
+	stateRepo repository.StateStore) *ChatService {
 	return &ChatService{
 		bookingRepo:  bookingRepo,
 		userRepo:     userRepo,
 		locationRepo: locationRepo,
-		chatBooking:  make(map[int64]int),
-		activeChats:  make(map[int64]int64),
+		stateRepo:    stateRepo,
 	}
 }
 
 // StartChat инициирует чат между пользователем с telegramID и вторым участником по указанному ID бронирования.
-func (s *ChatService) StartChat(telegramID int64, bookingID int) (int64, error) {
-	booking, err := s.bookingRepo.GetByID(bookingID)
+// Возвращает Telegram ID собеседника.
+func (s *ChatService) StartChat(ctx context.Context, telegramID int64, bookingID int) (int64, error) {
+	booking, err := s.bookingRepo.GetByID(ctx, bookingID)
 	if err != nil {
 		return 0, fmt.Errorf("бронирование не найдено")
 	}
-	var partnerTelegramID int64
-	bookingUser, err := s.userRepo.GetByID(booking.UserID)
+	bookingUser, err := s.userRepo.GetByID(ctx, booking.UserID)
 	if err != nil {
 		return 0, fmt.Errorf("не найден пользователь заявки")
 	}
 	partnerUserID := 0
 	if bookingUser.TelegramID == telegramID {
 		// текущий пользователь - турист, второй участник - провайдер
-		location, err := s.locationRepo.GetByID(booking.LocationID)
+		location, err := s.locationRepo.GetByID(ctx, booking.LocationID)
 		if err != nil || location.ProviderID == nil {
 			return 0, fmt.Errorf("не удалось определить провайдера для чата")
 		}
@@ -50,52 +65,100 @@ func (s *ChatService) StartChat(telegramID int64, bookingID int) (int64, error)
 		// текущий пользователь - провайдер, второй участник - турист
 		partnerUserID = booking.UserID
 	}
-	partnerUser, err := s.userRepo.GetByID(partnerUserID)
+	partnerUser, err := s.userRepo.GetByID(ctx, partnerUserID)
 	if err != nil {
 		return 0, fmt.Errorf("не найден второй участник чата")
 	}
-	partnerTelegramID = partnerUser.TelegramID
+	partner := partnerUser.TelegramID
+
+	if err := s.save(ctx, telegramID, ChatSession{Partner: partner, BookingID: bookingID}); err != nil {
+		return 0, err
+	}
+	if err := s.save(ctx, partner, ChatSession{Partner: telegramID, BookingID: bookingID}); err != nil {
+		return 0, err
+	}
+	return partner, nil
+}
+
+// KeepAlive продлевает чат пользователя на ChatTTL; вызывается при каждом сообщении.
+func (s *ChatService) KeepAlive(ctx context.Context, telegramID int64) error {
+	session, err := s.session(ctx, telegramID)
+	if err != nil || session == nil {
+		return err
+	}
+	return s.save(ctx, telegramID, *session)
+}
+
+// EndChat завершает чат пользователя с собеседником partner: сбрасывает состояние пользователя и,
+// если собеседник все еще в этом чате, его состояние. Собеседник передается явно, потому что
+// команда бота сбрасывает сценарий пользователя до вызова обработчика. Возвращает true, если
+// чат собеседника тоже был завершен.
+func (s *ChatService) EndChat(ctx context.Context, telegramID, partner int64) (bool, error) {
+	if err := s.stateRepo.Delete(ctx, telegramID); err != nil {
+		return false, err
+	}
+	session, err := s.session(ctx, partner)
+	if err != nil || session == nil || session.Partner != telegramID {
+		return false, err
+	}
+	if err := s.stateRepo.Delete(ctx, partner); err != nil {
+		return false, err
+	}
+	return true, nil
+}
 
-	// Сохраняем состояние активного чата в памяти
-	s.mu.Lock()
-	s.activeChats[telegramID] = partnerTelegramID
-	s.activeChats[partnerTelegramID] = telegramID
-	s.chatBooking[telegramID] = bookingID
-	s.chatBooking[partnerTelegramID] = bookingID
-	s.mu.Unlock()
-	return partnerTelegramID, nil
+// GetChatPartner возвращает Telegram ID собеседника, если пользователь и собеседник находятся
+// в чате друг с другом, иначе 0.
+func (s *ChatService) GetChatPartner(ctx context.Context, telegramID int64) (int64, error) {
+	session, err := s.session(ctx, telegramID)
+	if err != nil || session == nil {
+		return 0, err
+	}
+	partner, err := s.session(ctx, session.Partner)
+	if err != nil || partner == nil || partner.Partner != telegramID {
+		return 0, err
+	}
+	return session.Partner, nil
 }
 
-// EndChat завершает активный чат для указанного пользователя (и второго участника).
-func (s *ChatService) EndChat(telegramID int64) {
-	s.mu.Lock()
-	partnerID, ok := s.activeChats[telegramID]
-	if ok {
-		// удаляем оба соответствия
-		delete(s.activeChats, telegramID)
-		delete(s.activeChats, partnerID)
-		delete(s.chatBooking, telegramID)
-		delete(s.chatBooking, partnerID)
-	}
-	s.mu.Unlock()
+// GetChatBookingID возвращает идентификатор бронирования чата, в котором участвует пользователь, иначе 0.
+func (s *ChatService) GetChatBookingID(ctx context.Context, telegramID int64) (int, error) {
+	session, err := s.session(ctx, telegramID)
+	if err != nil || session == nil {
+		return 0, err
+	}
+	return session.BookingID, nil
 }
 
-// GetChatPartner возвращает TelegramID собеседника, если пользователь находится в чате, иначе 0.
-func (s *ChatService) GetChatPartner(telegramID int64) int64 {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	if partner, ok := s.activeChats[telegramID]; ok {
-		return partner
-	}
-	return 0
+// session возвращает активный чат пользователя или nil, если пользователь в другом сценарии
+// или чат истек.
+func (s *ChatService) session(ctx context.Context, telegramID int64) (*ChatSession, error) {
+	state, err := s.stateRepo.Get(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil || state.Flow != ChatFlow || state.Expired(time.Now()) {
+		return nil, nil
+	}
+	var session ChatSession
+	if err := json.Unmarshal(state.Payload, &session); err != nil {
+		return nil, fmt.Errorf("некорректные данные чата: %w", err)
+	}
+	return &session, nil
 }
 
-// GetChatBookingID возвращает идентификатор бронирования чата, в котором участвует пользователь.
-func (s *ChatService) GetChatBookingID(telegramID int64) int {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	if bookingID, ok := s.chatBooking[telegramID]; ok {
-		return bookingID
-	}
-	return 0
+func (s *ChatService) save(ctx context.Context, telegramID int64, session ChatSession) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("не удалось сохранить данные чата: %w", err)
+	}
+	now := time.Now()
+	expires := now.Add(ChatTTL)
+	return s.stateRepo.Save(ctx, &model.ConversationState{
+		TelegramID: telegramID,
+		Flow:       ChatFlow,
+		Payload:    data,
+		UpdatedAt:  now,
+		ExpiresAt:  &expires,
+	})
 }
@@ -0,0 +1,487 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"tourism/internal/model"
+	"tourism/internal/repository"
+)
+
+// ограничения на поля локаций и предложений, которые провайдер заполняет в боте
+const (
+	maxContentName        = 255
+	maxContentDescription = 4000
+	maxContentLabel       = 100 // категория и регион
+	maxContentContact     = 255
+	maxContentPhotos      = 10
+	maxPhotoCaption       = 1024 // ограничение Telegram на подпись к фото
+)
+
+// ContentService содержит логику ведения каталога провайдерами: создание и редактирование
+// своих локаций и предложений, их скрытие и проверку изменений поддержкой. Если модерация
+// включена, изменение провайдера ждет одобрения и только после него попадает в каталог;
+// скрытие записей применяется сразу.
+type ContentService struct {
+	tx               repository.Transactor
+	changeRepo       repository.ContentChangeStore
+	locationRepo     repository.LocationStore
+	offerRepo        repository.OfferStore
+	userRepo         repository.UserStore
+	notificationRepo repository.NotificationStore
+	moderate         bool
+}
+
+// NewContentService создает новый сервис каталога провайдеров; moderate включает проверку
+// изменений поддержкой.
+func NewContentService(tx repository.Transactor, changeRepo repository.ContentChangeStore,
+	locationRepo repository.LocationStore, offerRepo repository.OfferStore, userRepo repository.UserStore,
+	notificationRepo repository.NotificationStore, moderate bool) *ContentService {
+	return &ContentService{tx: tx, changeRepo: changeRepo, locationRepo: locationRepo, offerRepo: offerRepo,
+		userRepo: userRepo, notificationRepo: notificationRepo, moderate: moderate}
+}
+
+// ProviderLocations возвращает локации провайдера, включая скрытые.
+func (s *ContentService) ProviderLocations(ctx context.Context, providerID int) ([]model.Location, error) {
+	return s.locationRepo.ListByProvider(ctx, providerID)
+}
+
+// OwnLocation возвращает локацию, если ею может управлять пользователь userID: ее провайдер или поддержка.
+func (s *ContentService) OwnLocation(ctx context.Context, userID int, locationID int) (*model.Location, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("пользователь не найден")
+	}
+	loc, err := s.locationRepo.GetByID(ctx, locationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("локация не найдена")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении локации: %w", err)
+	}
+	if user.Role != "support" && (loc.ProviderID == nil || *loc.ProviderID != userID) {
+		return nil, fmt.Errorf("локация «%s» закреплена не за вами", loc.Name)
+	}
+	return loc, nil
+}
+
+// OwnOffer возвращает предложение, если им может управлять пользователь userID.
+func (s *ContentService) OwnOffer(ctx context.Context, userID int, offerID int) (*model.Offer, error) {
+	offer, err := s.offerRepo.GetByID(ctx, offerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("предложение не найдено")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении предложения: %w", err)
+	}
+	if _, err := s.OwnLocation(ctx, userID, offer.LocationID); err != nil {
+		return nil, err
+	}
+	return offer, nil
+}
+
+// LocationOffers возвращает предложения локации пользователя userID, включая скрытые.
+func (s *ContentService) LocationOffers(ctx context.Context, userID int, locationID int) ([]model.Offer, error) {
+	if _, err := s.OwnLocation(ctx, userID, locationID); err != nil {
+		return nil, err
+	}
+	return s.offerRepo.ListByLocation(ctx, locationID)
+}
+
+// Submit проверяет и сохраняет новую локацию или предложение либо новую версию существующей
+// записи. Изменение провайдера при включенной модерации получает статус pending (предыдущее
+// непроверенное изменение той же записи заменяется), иначе сразу применяется к каталогу.
+func (s *ContentService) Submit(ctx context.Context, c *model.ContentChange) (*model.ContentChange, error) {
+	if err := validateChange(c); err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.GetByID(ctx, c.AuthorID)
+	if err != nil {
+		return nil, fmt.Errorf("пользователь не найден")
+	}
+	if user.Role != "provider" {
+		return nil, fmt.Errorf("добавлять и редактировать записи каталога могут только провайдеры")
+	}
+	switch {
+	case c.Entity == model.ContentLocation && c.EntityID != nil:
+		if _, err := s.OwnLocation(ctx, c.AuthorID, *c.EntityID); err != nil {
+			return nil, err
+		}
+	case c.Entity == model.ContentOffer && c.EntityID != nil:
+		offer, err := s.OwnOffer(ctx, c.AuthorID, *c.EntityID)
+		if err != nil {
+			return nil, err
+		}
+		// предложение остается у своей локации
+		c.LocationID = &offer.LocationID
+	case c.Entity == model.ContentOffer:
+		if c.LocationID == nil {
+			return nil, fmt.Errorf("не указана локация предложения")
+		}
+		if _, err := s.OwnLocation(ctx, c.AuthorID, *c.LocationID); err != nil {
+			return nil, err
+		}
+	}
+	c.Status = model.ChangePending
+	if !s.moderate {
+		c.Status = model.ChangeApproved
+	}
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if c.EntityID != nil {
+			if err := s.changeRepo.ReplacePending(ctx, c.Entity, *c.EntityID); err != nil {
+				return err
+			}
+		}
+		id, err := s.changeRepo.Create(ctx, c)
+		if err != nil {
+			return err
+		}
+		c.ID = id
+		if c.Status == model.ChangeApproved {
+			return s.apply(ctx, c)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// SetLocationHidden скрывает локацию пользователя userID из поиска, каталога и дайджестов
+// или снова показывает ее. Скрытие не требует проверки поддержкой.
+func (s *ContentService) SetLocationHidden(ctx context.Context, userID int, locationID int, hidden bool) (*model.Location, error) {
+	loc, err := s.OwnLocation(ctx, userID, locationID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.locationRepo.SetHidden(ctx, locationID, hidden); err != nil {
+		return nil, err
+	}
+	loc.Hidden = hidden
+	return loc, nil
+}
+
+// SetOfferHidden скрывает предложение пользователя userID или снова показывает его.
+func (s *ContentService) SetOfferHidden(ctx context.Context, userID int, offerID int, hidden bool) (*model.Offer, error) {
+	offer, err := s.OwnOffer(ctx, userID, offerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.offerRepo.SetHidden(ctx, offerID, hidden); err != nil {
+		return nil, err
+	}
+	offer.Hidden = hidden
+	return offer, nil
+}
+
+// LocationPhotos возвращает галерею локации пользователя userID в порядке показа.
+func (s *ContentService) LocationPhotos(ctx context.Context, userID int, locationID int) (*model.Location, []model.LocationPhoto, error) {
+	loc, err := s.OwnLocation(ctx, userID, locationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	photos, err := s.locationRepo.GetPhotos(ctx, locationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return loc, photos, nil
+}
+
+// MovePhoto ставит фото на позицию position (с 1) в галерее локации, остальные сдвигаются.
+// Позиция за пределами галереи означает ее начало или конец.
+func (s *ContentService) MovePhoto(ctx context.Context, userID int, photoID int, position int) (*model.LocationPhoto, error) {
+	photo, err := s.ownPhoto(ctx, userID, photoID)
+	if err != nil {
+		return nil, err
+	}
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		photos, err := s.locationRepo.GetPhotos(ctx, photo.LocationID)
+		if err != nil {
+			return err
+		}
+		ids := []int{}
+		for _, p := range photos {
+			if p.ID != photoID {
+				ids = append(ids, p.ID)
+			}
+		}
+		position = min(max(position, 1), len(ids)+1)
+		ids = slices.Insert(ids, position-1, photoID)
+		photo.Position = position
+		return s.locationRepo.SetPhotoOrder(ctx, photo.LocationID, ids)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return photo, nil
+}
+
+// SetPhotoCover делает фото обложкой локации: оно открывает альбом в карточке.
+func (s *ContentService) SetPhotoCover(ctx context.Context, userID int, photoID int) (*model.LocationPhoto, error) {
+	photo, err := s.ownPhoto(ctx, userID, photoID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.locationRepo.SetCoverPhoto(ctx, photo.LocationID, photoID); err != nil {
+		return nil, err
+	}
+	photo.IsCover = true
+	return photo, nil
+}
+
+// SetPhotoCaption меняет подпись к фото; пустая строка убирает подпись.
+func (s *ContentService) SetPhotoCaption(ctx context.Context, userID int, photoID int, caption string) (*model.LocationPhoto, error) {
+	caption = strings.TrimSpace(caption)
+	if utf8.RuneCountInString(caption) > maxPhotoCaption {
+		return nil, fmt.Errorf("подпись длиннее %d символов", maxPhotoCaption)
+	}
+	photo, err := s.ownPhoto(ctx, userID, photoID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.locationRepo.SetPhotoCaption(ctx, photoID, caption); err != nil {
+		return nil, err
+	}
+	photo.Caption = caption
+	return photo, nil
+}
+
+// DeletePhoto удаляет фото из галереи и перенумеровывает оставшиеся. Если удалена обложка,
+// ею становится первое из оставшихся фото.
+func (s *ContentService) DeletePhoto(ctx context.Context, userID int, photoID int) (*model.LocationPhoto, error) {
+	photo, err := s.ownPhoto(ctx, userID, photoID)
+	if err != nil {
+		return nil, err
+	}
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.locationRepo.DeletePhoto(ctx, photoID); err != nil {
+			return err
+		}
+		photos, err := s.locationRepo.GetPhotos(ctx, photo.LocationID)
+		if err != nil || len(photos) == 0 {
+			return err
+		}
+		ids := []int{}
+		for _, p := range photos {
+			ids = append(ids, p.ID)
+		}
+		if err := s.locationRepo.SetPhotoOrder(ctx, photo.LocationID, ids); err != nil {
+			return err
+		}
+		if photo.IsCover {
+			return s.locationRepo.SetCoverPhoto(ctx, photo.LocationID, ids[0])
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return photo, nil
+}
+
+// ownPhoto возвращает фото, если галереей его локации может управлять пользователь userID.
+func (s *ContentService) ownPhoto(ctx context.Context, userID int, photoID int) (*model.LocationPhoto, error) {
+	photo, err := s.locationRepo.GetPhoto(ctx, photoID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("фото #%d не найдено", photoID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении фото: %w", err)
+	}
+	if _, err := s.OwnLocation(ctx, userID, photo.LocationID); err != nil {
+		return nil, err
+	}
+	return photo, nil
+}
+
+// Pending возвращает очередь изменений, ожидающих проверки.
+func (s *ContentService) Pending(ctx context.Context, limit int) ([]model.ContentChange, error) {
+	return s.changeRepo.ListByStatus(ctx, model.ChangePending, limit)
+}
+
+// Approve применяет изменение к каталогу; автор получает уведомление.
+func (s *ContentService) Approve(ctx context.Context, reviewerID int, changeID int) (*model.ContentChange, error) {
+	return s.decide(ctx, reviewerID, changeID, model.ChangeApproved, "", func(ctx context.Context, c *model.ContentChange) error {
+		if err := s.apply(ctx, c); err != nil {
+			return err
+		}
+		return enqueueNotification(ctx, s.notificationRepo, c.AuthorID, model.NotifyContentApproved,
+			fmt.Sprintf("content_change:%d:approved", c.ID), model.NotificationParams{
+				"change_id": strconv.Itoa(c.ID),
+				"entity":    c.Entity,
+				"name":      c.Name,
+			})
+	})
+}
+
+// Reject отклоняет изменение; автор получает уведомление с причиной.
+func (s *ContentService) Reject(ctx context.Context, reviewerID int, changeID int, reason string) (*model.ContentChange, error) {
+	reason = strings.TrimSpace(reason)
+	return s.decide(ctx, reviewerID, changeID, model.ChangeRejected, reason, func(ctx context.Context, c *model.ContentChange) error {
+		return enqueueNotification(ctx, s.notificationRepo, c.AuthorID, model.NotifyContentRejected,
+			fmt.Sprintf("content_change:%d:rejected", c.ID), model.NotificationParams{
+				"change_id": strconv.Itoa(c.ID),
+				"entity":    c.Entity,
+				"name":      c.Name,
+				"reason":    reason,
+			})
+	})
+}
+
+// decide записывает решение поддержки по изменению и выполняет then в той же транзакции.
+func (s *ContentService) decide(ctx context.Context, reviewerID int, changeID int, status string, reason string,
+	then func(ctx context.Context, c *model.ContentChange) error) (*model.ContentChange, error) {
+	reviewer, err := s.userRepo.GetByID(ctx, reviewerID)
+	if err != nil || reviewer.Role != "support" {
+		return nil, fmt.Errorf("проверять изменения каталога может только поддержка")
+	}
+	// изменение блокируется, чтобы два сотрудника поддержки не приняли по нему разные решения
+	var c *model.ContentChange
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		c, err = s.changeRepo.GetByIDForUpdate(ctx, changeID)
+		if err != nil {
+			return fmt.Errorf("изменение не найдено")
+		}
+		if c.Status != model.ChangePending {
+			return fmt.Errorf("изменение #%d уже обработано (статус %q)", changeID, c.Status)
+		}
+		if err := s.changeRepo.UpdateStatus(ctx, changeID, status, &reviewerID, reason); err != nil {
+			return err
+		}
+		c.Status = status
+		c.ReviewerID = &reviewerID
+		c.Reason = reason
+		return then(ctx, c)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// apply переносит изменение в каталог: создает запись или обновляет существующую и добавляет фото.
+// Локация могла быть передана другому провайдеру, пока изменение ждало проверки, поэтому
+// закрепление за автором проверяется еще раз.
+func (s *ContentService) apply(ctx context.Context, c *model.ContentChange) error {
+	switch c.Entity {
+	case model.ContentLocation:
+		loc := &model.Location{Name: c.Name, Description: c.Description, Category: c.Category, Region: c.Region,
+			Latitude: c.Latitude, Longitude: c.Longitude, ProviderID: &c.AuthorID}
+		if c.EntityID == nil {
+			id, err := s.locationRepo.Create(ctx, loc)
+			if err != nil {
+				return err
+			}
+			if err := s.changeRepo.SetEntity(ctx, c.ID, id); err != nil {
+				return err
+			}
+			c.EntityID = &id
+		} else {
+			current, err := s.locationRepo.GetByIDForUpdate(ctx, *c.EntityID)
+			if err != nil {
+				return fmt.Errorf("локация #%d не найдена", *c.EntityID)
+			}
+			if current.ProviderID == nil || *current.ProviderID != c.AuthorID {
+				return fmt.Errorf("локация #%d больше не закреплена за автором изменения", current.ID)
+			}
+			loc.ID = current.ID
+			if err := s.locationRepo.Update(ctx, loc); err != nil {
+				return err
+			}
+		}
+		for _, fileID := range c.PhotoFileIDs {
+			if _, err := s.locationRepo.AddPhoto(ctx, *c.EntityID, fileID, ""); err != nil {
+				return err
+			}
+		}
+		return nil
+	case model.ContentOffer:
+		owner, err := s.locationRepo.GetByIDForUpdate(ctx, *c.LocationID)
+		if err != nil {
+			return fmt.Errorf("локация #%d не найдена", *c.LocationID)
+		}
+		if owner.ProviderID == nil || *owner.ProviderID != c.AuthorID {
+			return fmt.Errorf("локация #%d больше не закреплена за автором изменения", owner.ID)
+		}
+		offer := &model.Offer{LocationID: *c.LocationID, Type: c.OfferType, Name: c.Name, Description: c.Description,
+			Price: c.Price, Contact: c.Contact}
+		if len(c.PhotoFileIDs) > 0 {
+			offer.PhotoFileID = c.PhotoFileIDs[len(c.PhotoFileIDs)-1]
+		}
+		if c.EntityID == nil {
+			id, err := s.offerRepo.Create(ctx, offer)
+			if err != nil {
+				return err
+			}
+			if err := s.changeRepo.SetEntity(ctx, c.ID, id); err != nil {
+				return err
+			}
+			c.EntityID = &id
+			return nil
+		}
+		current, err := s.offerRepo.GetByIDForUpdate(ctx, *c.EntityID)
+		if err != nil {
+			return fmt.Errorf("предложение #%d не найдено", *c.EntityID)
+		}
+		offer.ID = current.ID
+		if offer.PhotoFileID == "" {
+			offer.PhotoFileID = current.PhotoFileID
+		}
+		return s.offerRepo.Update(ctx, offer)
+	}
+	return fmt.Errorf("неизвестный тип записи %q", c.Entity)
+}
+
+// validateChange приводит поля изменения к каноничному виду и проверяет их.
+func validateChange(c *model.ContentChange) error {
+	c.Name = strings.TrimSpace(c.Name)
+	c.Description = strings.TrimSpace(c.Description)
+	c.Category = strings.TrimSpace(c.Category)
+	c.Region = strings.TrimSpace(c.Region)
+	c.Contact = strings.TrimSpace(c.Contact)
+	switch {
+	case c.Entity != model.ContentLocation && c.Entity != model.ContentOffer:
+		return fmt.Errorf("неизвестный тип записи %q", c.Entity)
+	case c.Name == "":
+		return fmt.Errorf("название не может быть пустым")
+	case utf8.RuneCountInString(c.Name) > maxContentName:
+		return fmt.Errorf("название длиннее %d символов", maxContentName)
+	case utf8.RuneCountInString(c.Description) > maxContentDescription:
+		return fmt.Errorf("описание длиннее %d символов", maxContentDescription)
+	case len(c.PhotoFileIDs) > maxContentPhotos:
+		return fmt.Errorf("за один раз можно добавить не более %d фото", maxContentPhotos)
+	}
+	if c.Entity == model.ContentLocation {
+		c.LocationID = nil
+		c.OfferType, c.Price, c.Contact = "", 0, ""
+		switch {
+		case c.Category == "" || c.Region == "":
+			return fmt.Errorf("укажите категорию и регион локации")
+		case utf8.RuneCountInString(c.Category) > maxContentLabel || utf8.RuneCountInString(c.Region) > maxContentLabel:
+			return fmt.Errorf("категория и регион должны быть не длиннее %d символов", maxContentLabel)
+		case c.Latitude == 0 && c.Longitude == 0:
+			return fmt.Errorf("не указаны координаты локации")
+		case c.Latitude < -90 || c.Latitude > 90 || c.Longitude < -180 || c.Longitude > 180:
+			return fmt.Errorf("некорректные координаты %.6f, %.6f", c.Latitude, c.Longitude)
+		}
+		return nil
+	}
+	c.Category, c.Region, c.Latitude, c.Longitude = "", "", 0, 0
+	switch {
+	case !isTopic(c.OfferType):
+		return fmt.Errorf("неизвестный тип предложения %q (допустимы %s)", c.OfferType, strings.Join(model.Topics, ", "))
+	case c.Price < 0:
+		return fmt.Errorf("цена не может быть отрицательной")
+	case utf8.RuneCountInString(c.Contact) > maxContentContact:
+		return fmt.Errorf("контакт длиннее %d символов", maxContentContact)
+	}
+	return nil
+}
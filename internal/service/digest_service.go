@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"tourism/internal/model"
+	"tourism/internal/repository"
+)
+
+// DigestService содержит логику еженедельного персонального дайджеста.
+type DigestService struct {
+	digestRepo   repository.DigestStore
+	userRepo     repository.UserStore
+	translations *TranslationService
+	period       time.Duration
+	maxItems     int
+}
+
+// NewDigestService создает сервис дайджестов. period — интервал между дайджестами,
+// maxItems — максимум локаций и предложений (каждого вида) в одном дайджесте. Названия
+// и описания в дайджесте переводятся на язык подписчика через translations.
+func NewDigestService(digestRepo repository.DigestStore, userRepo repository.UserStore,
+	translations *TranslationService, period time.Duration, maxItems int) *DigestService {
+	return &DigestService{digestRepo: digestRepo, userRepo: userRepo, translations: translations,
+		period: period, maxItems: maxItems}
+}
+
+// DueSubscribers забирает подписчиков, которым пора отправить дайджест, и резервирует их
+// до leaseUntil, чтобы другой экземпляр бота не отправил им тот же дайджест.
+func (s *DigestService) DueSubscribers(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]model.DigestSubscriber, error) {
+	return s.digestRepo.DueSubscribers(ctx, now.Add(-s.period), now, leaseUntil, limit)
+}
+
+// Build подбирает для подписчика новые локации и предложения с момента прошлого дайджеста
+// (для первого дайджеста — за последний период) на языке подписчика.
+func (s *DigestService) Build(ctx context.Context, sub *model.DigestSubscriber, now time.Time) (*model.Digest, error) {
+	since := now.Add(-s.period)
+	if sub.LastDigestAt != nil {
+		since = *sub.LastDigestAt
+	}
+	locations, err := s.digestRepo.NewLocations(ctx, sub.UserID, sub.Regions, since, s.maxItems)
+	if err != nil {
+		return nil, err
+	}
+	offers := []model.Offer{}
+	if len(sub.Topics) > 0 {
+		offers, err = s.digestRepo.NewOffers(ctx, sub.UserID, sub.Topics, sub.Regions, since, s.maxItems)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := s.translations.LocalizeLocations(ctx, sub.PreferredLanguage(), locations); err != nil {
+		return nil, err
+	}
+	if err := s.translations.LocalizeOffers(ctx, sub.PreferredLanguage(), offers); err != nil {
+		return nil, err
+	}
+	return &model.Digest{Locations: locations, Offers: offers}, nil
+}
+
+// Record отмечает дайджест отправленным, чтобы его элементы не повторялись.
+// Пустой дайджест тоже записывается: следующая проверка будет через полный период.
+func (s *DigestService) Record(ctx context.Context, userID int, digest *model.Digest, at time.Time) error {
+	return s.digestRepo.Record(ctx, userID, digest, at)
+}
+
+// MarkInactive помечает подписчика, заблокировавшего бота, неактивным.
+func (s *DigestService) MarkInactive(ctx context.Context, userID int) error {
+	return s.userRepo.SetActive(ctx, userID, false)
+}
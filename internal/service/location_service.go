@@ -1,39 +1,59 @@
 package service
 
 import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
 	"tourism/internal/model"
 	"tourism/internal/repository"
 )
 
 // LocationService содержит бизнес-логику, связанную с локациями.
 type LocationService struct {
-	locationRepo *repository.LocationRepository
+	locationRepo repository.LocationStore
 }
 
 // NewLocationService создает новый сервис локаций.
-func NewLocationService(locationRepo *repository.LocationRepository) *LocationService {
+func NewLocationService(locationRepo repository.LocationStore) *LocationService {
 	return &LocationService{locationRepo: locationRepo}
 }
 
 // SearchLocations выполняет поиск локаций по заданным параметрам фильтрации и/или ключевому слову.
-func (s *LocationService) SearchLocations(category string, region string, minRating float64, keyword string) ([]model.Location, error) {
-	return s.locationRepo.FindByFilters(category, region, minRating, keyword)
+func (s *LocationService) SearchLocations(ctx context.Context, category string, region string, minRating float64, keyword string) ([]model.Location, error) {
+	return s.locationRepo.FindByFilters(ctx, category, region, minRating, keyword)
 }
 
 // GetLocationDetails получает подробные данные о локации (сам объект и список фото).
-func (s *LocationService) GetLocationDetails(locationID int) (*model.Location, []model.LocationPhoto, error) {
-	location, err := s.locationRepo.GetByID(locationID)
+func (s *LocationService) GetLocationDetails(ctx context.Context, locationID int) (*model.Location, []model.LocationPhoto, error) {
+	location, err := s.locationRepo.GetByID(ctx, locationID)
 	if err != nil {
 		return nil, nil, err
 	}
-	photos, err := s.locationRepo.GetPhotos(locationID)
+	photos, err := s.locationRepo.GetPhotos(ctx, locationID)
 	if err != nil {
 		return location, nil, err // возвращаем локацию даже если фото не загрузились
 	}
 	return location, photos, nil
 }
 
-// AddPhoto добавляет фото (FileID) к указанной локации.
-func (s *LocationService) AddPhoto(locationID int, fileID string) error {
-	return s.locationRepo.AddPhoto(locationID, fileID)
+// AddPhoto добавляет фото (FileID) с подписью в конец галереи указанной локации.
+func (s *LocationService) AddPhoto(ctx context.Context, locationID int, fileID string, caption string) error {
+	caption = strings.TrimSpace(caption)
+	if utf8.RuneCountInString(caption) > maxPhotoCaption {
+		return fmt.Errorf("подпись длиннее %d символов", maxPhotoCaption)
+	}
+	_, err := s.locationRepo.AddPhoto(ctx, locationID, fileID, caption)
+	return err
+}
+
+// Photos возвращает галерею локации в порядке показа.
+func (s *LocationService) Photos(ctx context.Context, locationID int) ([]model.LocationPhoto, error) {
+	return s.locationRepo.GetPhotos(ctx, locationID)
+}
+
+// ListWithoutPhotos возвращает локации, которым нужны фотографии.
+func (s *LocationService) ListWithoutPhotos(ctx context.Context) ([]model.Location, error) {
+	return s.locationRepo.ListWithoutPhotos(ctx)
 }
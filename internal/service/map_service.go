@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"tourism/internal/model"
+	"tourism/internal/repository"
+	"tourism/internal/staticmap"
+)
+
+// ErrNotTripOwner возвращается, если карту маршрута запрашивает или маршрут завершает не его владелец.
+var ErrNotTripOwner = errors.New("маршрут принадлежит другому пользователю")
+
+// MapService рисует статические карты локаций и маршрутов.
+type MapService struct {
+	tripRepo repository.TripStore
+	renderer *staticmap.Renderer
+}
+
+// NewMapService создает сервис карт.
+func NewMapService(tripRepo repository.TripStore, renderer *staticmap.Renderer) *MapService {
+	return &MapService{tripRepo: tripRepo, renderer: renderer}
+}
+
+// LocationMap рисует карту с меткой локации. Для локации без координат возвращает staticmap.ErrNoPoints.
+func (s *MapService) LocationMap(ctx context.Context, loc *model.Location) (*staticmap.Image, error) {
+	m := staticmap.Map{}
+	if hasCoordinates(loc) {
+		m.Markers = []staticmap.Marker{{Point: staticmap.Point{Lat: loc.Latitude, Lon: loc.Longitude}}}
+	}
+	return s.renderer.Render(ctx, m)
+}
+
+// RouteMap рисует маршрут: линию через точки в порядке посещения и метки с их номерами.
+// Локации без координат пропускаются, но номера остальных совпадают с порядком в маршруте.
+// Нулевые размеры заменяются размерами по умолчанию.
+func (s *MapService) RouteMap(ctx context.Context, locations []model.Location, width, height int) (*staticmap.Image, error) {
+	m := staticmap.Map{Width: width, Height: height}
+	for i := range locations {
+		if !hasCoordinates(&locations[i]) {
+			continue
+		}
+		p := staticmap.Point{Lat: locations[i].Latitude, Lon: locations[i].Longitude}
+		m.Markers = append(m.Markers, staticmap.Marker{Point: p, Label: i + 1})
+		m.Path = append(m.Path, p)
+	}
+	return s.renderer.Render(ctx, m)
+}
+
+// TripMap рисует карту маршрута tripID пользователя userID. Для несуществующего маршрута возвращает
+// sql.ErrNoRows, для чужого — ErrNotTripOwner, для маршрута без точек с координатами — staticmap.ErrNoPoints.
+func (s *MapService) TripMap(ctx context.Context, userID int, tripID int, width, height int) (*staticmap.Image, error) {
+	trip, err := s.tripRepo.GetByID(ctx, tripID)
+	if err != nil {
+		return nil, err
+	}
+	if trip.UserID != userID {
+		return nil, ErrNotTripOwner
+	}
+	locations, err := s.tripRepo.GetLocations(ctx, tripID)
+	if err != nil {
+		return nil, err
+	}
+	return s.RouteMap(ctx, locations, width, height)
+}
+
+// hasCoordinates сообщает, заданы ли координаты локации: у локаций провайдеров без координат
+// широта и долгота нулевые.
+func hasCoordinates(loc *model.Location) bool {
+	return loc.Latitude != 0 || loc.Longitude != 0
+}
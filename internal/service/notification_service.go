@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"tourism/internal/model"
+	"tourism/internal/repository"
+)
+
+// NotificationService отдает уведомления из очереди на отправку и учитывает результат доставки.
+// Уведомления ставятся в очередь сервисами бронирований и отзывов в тех же транзакциях,
+// что и изменения, о которых они сообщают.
+type NotificationService struct {
+	tx               repository.Transactor
+	notificationRepo repository.NotificationStore
+	userRepo         repository.UserStore
+}
+
+// NewNotificationService создает новый сервис уведомлений.
+func NewNotificationService(tx repository.Transactor, notificationRepo repository.NotificationStore,
+	userRepo repository.UserStore) *NotificationService {
+	return &NotificationService{tx: tx, notificationRepo: notificationRepo, userRepo: userRepo}
+}
+
+// ClaimPending забирает из очереди до limit уведомлений, готовых к отправке.
+func (s *NotificationService) ClaimPending(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]model.Notification, error) {
+	return s.notificationRepo.ClaimPending(ctx, now, leaseUntil, limit)
+}
+
+// Recipient возвращает получателя уведомления.
+func (s *NotificationService) Recipient(ctx context.Context, n *model.Notification) (*model.User, error) {
+	return s.userRepo.GetByID(ctx, n.UserID)
+}
+
+// MarkSent отмечает уведомление как доставленное на указанный Telegram ID.
+func (s *NotificationService) MarkSent(ctx context.Context, notificationID int, telegramID int64) error {
+	return s.notificationRepo.MarkSent(ctx, notificationID, telegramID)
+}
+
+// MarkFailed окончательно завершает уведомление с ошибкой.
+func (s *NotificationService) MarkFailed(ctx context.Context, notificationID int, reason string) error {
+	return s.notificationRepo.MarkFailed(ctx, notificationID, "failed", reason)
+}
+
+// MarkBlocked завершает уведомление получателю, заблокировавшему бота, и помечает его неактивным.
+func (s *NotificationService) MarkBlocked(ctx context.Context, n *model.Notification, reason string) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.notificationRepo.MarkFailed(ctx, n.ID, "blocked", reason); err != nil {
+			return err
+		}
+		return s.userRepo.SetActive(ctx, n.UserID, false)
+	})
+}
+
+// Retry откладывает уведомление до указанного времени.
+func (s *NotificationService) Retry(ctx context.Context, notificationID int, at time.Time, reason string, countAttempt bool) error {
+	return s.notificationRepo.Retry(ctx, notificationID, at, reason, countAttempt)
+}
+
+// enqueueNotification ставит уведомление в очередь. Вызывается внутри транзакции изменения,
+// о котором сообщает уведомление; повторное уведомление с тем же ключом пропускается.
+func enqueueNotification(ctx context.Context, store repository.NotificationStore, userID int, kind string,
+	dedupKey string, params model.NotificationParams) error {
+	n := &model.Notification{UserID: userID, Kind: kind, Params: params, DedupKey: dedupKey}
+	if _, err := store.Enqueue(ctx, n); err != nil {
+		return fmt.Errorf("уведомление %s: %w", dedupKey, err)
+	}
+	return nil
+}
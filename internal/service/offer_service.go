@@ -1,28 +1,167 @@
 package service
 
-import "tourism/internal/repository"
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"tourism/internal/model"
+	"tourism/internal/repository"
+)
 
 // OfferService содержит логику подписки на рассылки интересных предложений.
 type OfferService struct {
-	subRepo *repository.SubscriptionRepository
+	subRepo      repository.SubscriptionStore
+	offerRepo    repository.OfferStore
+	locationRepo repository.LocationStore
 }
 
 // NewOfferService создает новый сервис предложений.
-func NewOfferService(subRepo *repository.SubscriptionRepository) *OfferService {
-	return &OfferService{subRepo: subRepo}
+func NewOfferService(subRepo repository.SubscriptionStore, offerRepo repository.OfferStore,
+	locationRepo repository.LocationStore) *OfferService {
+	return &OfferService{subRepo: subRepo, offerRepo: offerRepo, locationRepo: locationRepo}
 }
 
 // Subscribe оформляет подписку пользователя на рассылку.
-func (s *OfferService) Subscribe(userID int) error {
-	return s.subRepo.Subscribe(userID)
+func (s *OfferService) Subscribe(ctx context.Context, userID int) error {
+	return s.subRepo.Subscribe(ctx, userID)
 }
 
 // Unsubscribe отменяет подписку пользователя.
-func (s *OfferService) Unsubscribe(userID int) error {
-	return s.subRepo.Unsubscribe(userID)
+func (s *OfferService) Unsubscribe(ctx context.Context, userID int) error {
+	return s.subRepo.Unsubscribe(ctx, userID)
 }
 
 // GetSubscriberIDs возвращает Telegram ID всех подписчиков.
-func (s *OfferService) GetSubscriberIDs() ([]int64, error) {
-	return s.subRepo.GetAllSubscriberTelegramIDs()
+func (s *OfferService) GetSubscriberIDs(ctx context.Context) ([]int64, error) {
+	return s.subRepo.GetAllSubscriberTelegramIDs(ctx)
+}
+
+// GetPreferences возвращает настройки подписки пользователя; nil, если пользователь не подписан.
+func (s *OfferService) GetPreferences(ctx context.Context, userID int) (*model.OfferSubscription, error) {
+	sub, err := s.subRepo.GetByUserID(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении подписки: %w", err)
+	}
+	return sub, nil
+}
+
+// UpdatePreferences проверяет и сохраняет настройки подписки (подписывая пользователя, если он не был подписан).
+func (s *OfferService) UpdatePreferences(ctx context.Context, sub *model.OfferSubscription) error {
+	for _, t := range sub.Topics {
+		if !isTopic(t) {
+			return fmt.Errorf("неизвестная тема %q (допустимы %s)", t, strings.Join(model.Topics, ", "))
+		}
+	}
+	if sub.Frequency != model.FrequencyInstant && sub.Frequency != model.FrequencyWeekly {
+		return fmt.Errorf("неизвестная частота %q (допустимы %s, %s)", sub.Frequency, model.FrequencyInstant, model.FrequencyWeekly)
+	}
+	if len(sub.Regions) > 0 {
+		known, err := s.locationRepo.ListRegions(ctx)
+		if err != nil {
+			return err
+		}
+		for i, r := range sub.Regions {
+			canonical := ""
+			for _, k := range known {
+				if strings.EqualFold(k, strings.TrimSpace(r)) {
+					canonical = k
+				}
+			}
+			if canonical == "" {
+				return fmt.Errorf("неизвестный регион %q", r)
+			}
+			sub.Regions[i] = canonical
+		}
+	}
+	if sub.Topics == nil {
+		sub.Topics = []string{}
+	}
+	if sub.Regions == nil {
+		sub.Regions = []string{}
+	}
+	return s.subRepo.SavePreferences(ctx, sub)
+}
+
+// ToggleTopic включает или выключает тему в подписке пользователя.
+func (s *OfferService) ToggleTopic(ctx context.Context, userID int, topic string) (*model.OfferSubscription, error) {
+	return s.modify(ctx, userID, func(sub *model.OfferSubscription) {
+		sub.Topics = toggle(sub.Topics, topic)
+	})
+}
+
+// ToggleRegion включает или выключает регион в подписке пользователя.
+func (s *OfferService) ToggleRegion(ctx context.Context, userID int, region string) (*model.OfferSubscription, error) {
+	return s.modify(ctx, userID, func(sub *model.OfferSubscription) {
+		sub.Regions = toggle(sub.Regions, region)
+	})
+}
+
+// SetFrequency задает частоту получения предложений.
+func (s *OfferService) SetFrequency(ctx context.Context, userID int, frequency string) (*model.OfferSubscription, error) {
+	return s.modify(ctx, userID, func(sub *model.OfferSubscription) {
+		sub.Frequency = frequency
+	})
+}
+
+// ListRegions возвращает регионы, доступные для выбора в подписке.
+func (s *OfferService) ListRegions(ctx context.Context) ([]string, error) {
+	return s.locationRepo.ListRegions(ctx)
+}
+
+// ListOffers возвращает предложения указанного типа ("housing", "tour").
+func (s *OfferService) ListOffers(ctx context.Context, offerType string) ([]model.Offer, error) {
+	return s.offerRepo.ListByType(ctx, offerType)
+}
+
+// GetOffer возвращает предложение по ID.
+func (s *OfferService) GetOffer(ctx context.Context, offerID int) (*model.Offer, error) {
+	return s.offerRepo.GetByID(ctx, offerID)
+}
+
+// modify применяет изменение к подписке пользователя (создавая ее с настройками по умолчанию) и сохраняет результат.
+func (s *OfferService) modify(ctx context.Context, userID int, change func(sub *model.OfferSubscription)) (*model.OfferSubscription, error) {
+	sub, err := s.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil {
+		sub = &model.OfferSubscription{UserID: userID, Topics: model.Topics, Frequency: model.FrequencyInstant}
+	}
+	change(sub)
+	if err := s.UpdatePreferences(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func isTopic(topic string) bool {
+	for _, t := range model.Topics {
+		if t == topic {
+			return true
+		}
+	}
+	return false
+}
+
+// toggle возвращает копию values с добавленным или удаленным v.
+func toggle(values []string, v string) []string {
+	out := []string{}
+	found := false
+	for _, x := range values {
+		if x == v {
+			found = true
+			continue
+		}
+		out = append(out, x)
+	}
+	if !found {
+		out = append(out, v)
+	}
+	return out
 }
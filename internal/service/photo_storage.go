@@ -0,0 +1,164 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"image"
+
+	"tourism/internal/blob"
+	"tourism/internal/imaging"
+	"tourism/internal/model"
+	"tourism/internal/repository"
+)
+
+// PhotoOriginal — имя размера, под которым хранится исходное фото.
+const PhotoOriginal = "original"
+
+// PhotoSize — уменьшенная копия фото: имя в адресе API и наибольшая сторона в пикселях.
+type PhotoSize struct {
+	Name    string
+	MaxSide int
+}
+
+// PhotoSizes — уменьшенные копии, которые готовятся для каждого фото.
+var PhotoSizes = []PhotoSize{{Name: "small", MaxSide: 160}, {Name: "medium", MaxSide: 640}, {Name: "large", MaxSide: 1280}}
+
+const (
+	maxPhotoStoreAttempts = 5        // после стольких неудач фото больше не скачивается
+	maxPhotoPixels        = 50 << 20 // защита от изображений, которые после декодирования не поместятся в память
+)
+
+// ErrPhotoNotStored возвращается, если фото еще не сохранено в хранилище файлов.
+var ErrPhotoNotStored = errors.New("фото еще не сохранено в хранилище")
+
+// PhotoStorageService сохраняет фото локаций в хранилище файлов — оригинал и уменьшенные
+// копии — и открывает их для API. FileID остается в location_photos для отправки в Telegram.
+type PhotoStorageService struct {
+	locationRepo repository.LocationStore
+	blobs        blob.Store
+}
+
+// NewPhotoStorageService создает сервис хранения фото.
+func NewPhotoStorageService(locationRepo repository.LocationStore, blobs blob.Store) *PhotoStorageService {
+	return &PhotoStorageService{locationRepo: locationRepo, blobs: blobs}
+}
+
+// Pending возвращает фото, которые еще нужно скачать из Telegram и сохранить.
+func (s *PhotoStorageService) Pending(ctx context.Context, limit int) ([]model.LocationPhoto, error) {
+	return s.locationRepo.ListPhotosToStore(ctx, maxPhotoStoreAttempts, limit)
+}
+
+// Save сохраняет скачанное фото: оригинал и уменьшенные копии в JPEG.
+func (s *PhotoStorageService) Save(ctx context.Context, photo *model.LocationPhoto, data []byte) error {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("не удалось прочитать изображение: %w", err)
+	}
+	if cfg.Width*cfg.Height > maxPhotoPixels {
+		return fmt.Errorf("изображение %dx%d слишком большое", cfg.Width, cfg.Height)
+	}
+	img, _, err := imaging.Decode(data)
+	if err != nil {
+		return err
+	}
+	contentType := "image/" + format
+	prefix := fmt.Sprintf("locations/%d/photos/%d", photo.LocationID, photo.ID)
+	original, _ := photoKey(prefix, contentType, PhotoOriginal)
+	if err := s.blobs.Put(ctx, original, data, contentType); err != nil {
+		return err
+	}
+	for _, size := range PhotoSizes {
+		thumb, err := imaging.EncodeJPEG(imaging.Fit(img, size.MaxSide))
+		if err != nil {
+			return err
+		}
+		key, _ := photoKey(prefix, contentType, size.Name)
+		if err := s.blobs.Put(ctx, key, thumb, "image/jpeg"); err != nil {
+			return err
+		}
+	}
+	if err := s.locationRepo.MarkPhotoStored(ctx, photo.ID, prefix, contentType, cfg.Width, cfg.Height); err != nil {
+		return err
+	}
+	photo.StorageKey, photo.ContentType, photo.Width, photo.Height = prefix, contentType, cfg.Width, cfg.Height
+	return nil
+}
+
+// Fail записывает неудачную попытку сохранить фото; после maxPhotoStoreAttempts попыток фото пропускается.
+func (s *PhotoStorageService) Fail(ctx context.Context, photoID int, cause error) error {
+	return s.locationRepo.MarkPhotoStoreFailed(ctx, photoID, cause.Error())
+}
+
+// Open открывает сохраненное фото размера size (PhotoOriginal или имя из PhotoSizes).
+// Фото скрытой локации не отдаются: для них возвращается sql.ErrNoRows.
+func (s *PhotoStorageService) Open(ctx context.Context, photoID int, size string) (*blob.Object, *model.LocationPhoto, error) {
+	photo, err := s.locationRepo.GetPhoto(ctx, photoID)
+	if err != nil {
+		return nil, nil, err
+	}
+	loc, err := s.locationRepo.GetByID(ctx, photo.LocationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if loc.Hidden {
+		return nil, nil, sql.ErrNoRows
+	}
+	if photo.StoredAt == nil {
+		return nil, nil, ErrPhotoNotStored
+	}
+	key, ok := photoKey(photo.StorageKey, photo.ContentType, size)
+	if !ok {
+		return nil, nil, fmt.Errorf("неизвестный размер фото %q", size)
+	}
+	obj, err := s.blobs.Open(ctx, key)
+	if errors.Is(err, blob.ErrNotFound) {
+		return nil, nil, ErrPhotoNotStored
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return obj, photo, nil
+}
+
+// Remove удаляет из хранилища файлы удаленного из галереи фото.
+func (s *PhotoStorageService) Remove(ctx context.Context, photo *model.LocationPhoto) error {
+	if photo.StorageKey == "" {
+		return nil
+	}
+	var errs []error
+	for _, size := range PhotoSizeNames() {
+		key, _ := photoKey(photo.StorageKey, photo.ContentType, size)
+		errs = append(errs, s.blobs.Delete(ctx, key))
+	}
+	return errors.Join(errs...)
+}
+
+// PhotoSizeNames возвращает имена всех размеров фото: оригинал и уменьшенные копии.
+func PhotoSizeNames() []string {
+	names := []string{PhotoOriginal}
+	for _, size := range PhotoSizes {
+		names = append(names, size.Name)
+	}
+	return names
+}
+
+// photoKey возвращает ключ файла размера size; ok = false для неизвестного размера.
+// Оригинал сохраняет свой формат, уменьшенные копии всегда в JPEG.
+func photoKey(prefix string, contentType string, size string) (key string, ok bool) {
+	if size == PhotoOriginal {
+		ext := ".jpg"
+		if contentType == "image/png" {
+			ext = ".png"
+		}
+		return prefix + "/" + PhotoOriginal + ext, true
+	}
+	for _, s := range PhotoSizes {
+		if s.Name == size {
+			return prefix + "/" + size + ".jpg", true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"tourism/internal/model"
+	"tourism/internal/repository"
+)
+
+// ограничения заявки на роль провайдера
+const (
+	maxApplicationDocuments = 10
+	maxBusinessName         = 200
+	maxApplicationContacts  = 500
+)
+
+// ErrApplicantRole возвращается при одобрении заявки, если заявитель уже не турист.
+var ErrApplicantRole = errors.New("одобрить можно только заявку туриста")
+
+// ProviderService содержит логику подключения провайдеров: заявки пользователей на роль
+// провайдера, их проверку поддержкой и закрепление локаций за провайдерами.
+type ProviderService struct {
+	tx               repository.Transactor
+	applicationRepo  repository.ProviderApplicationStore
+	userRepo         repository.UserStore
+	locationRepo     repository.LocationStore
+	notificationRepo repository.NotificationStore
+}
+
+// NewProviderService создает новый сервис провайдеров.
+func NewProviderService(tx repository.Transactor, applicationRepo repository.ProviderApplicationStore,
+	userRepo repository.UserStore, locationRepo repository.LocationStore,
+	notificationRepo repository.NotificationStore) *ProviderService {
+	return &ProviderService{tx: tx, applicationRepo: applicationRepo, userRepo: userRepo,
+		locationRepo: locationRepo, notificationRepo: notificationRepo}
+}
+
+// Apply сохраняет заявку пользователя на роль провайдера. Подать заявку может только турист
+// без другой заявки на рассмотрении; нужен хотя бы один документ.
+func (s *ProviderService) Apply(ctx context.Context, a *model.ProviderApplication) (*model.ProviderApplication, error) {
+	a.BusinessName = strings.TrimSpace(a.BusinessName)
+	a.Contacts = strings.TrimSpace(a.Contacts)
+	switch {
+	case a.BusinessName == "":
+		return nil, fmt.Errorf("не указано название бизнеса")
+	case utf8.RuneCountInString(a.BusinessName) > maxBusinessName:
+		return nil, fmt.Errorf("название бизнеса длиннее %d символов", maxBusinessName)
+	case a.Contacts == "":
+		return nil, fmt.Errorf("не указаны контакты")
+	case utf8.RuneCountInString(a.Contacts) > maxApplicationContacts:
+		return nil, fmt.Errorf("контакты длиннее %d символов", maxApplicationContacts)
+	case len(a.DocumentFileIDs) == 0:
+		return nil, fmt.Errorf("приложите хотя бы одно фото документов")
+	case len(a.DocumentFileIDs) > maxApplicationDocuments:
+		return nil, fmt.Errorf("к заявке можно приложить не более %d фото", maxApplicationDocuments)
+	}
+	user, err := s.userRepo.GetByID(ctx, a.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("пользователь не найден")
+	}
+	if user.Role != "user" {
+		return nil, fmt.Errorf("заявку на роль провайдера может подать только турист")
+	}
+	latest, err := s.LatestApplication(ctx, a.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil && latest.Status == model.ApplicationPending {
+		return nil, fmt.Errorf("заявка #%d уже на рассмотрении", latest.ID)
+	}
+	id, err := s.applicationRepo.Create(ctx, a)
+	if err != nil {
+		return nil, err
+	}
+	a.ID = id
+	a.Status = model.ApplicationPending
+	return a, nil
+}
+
+// LatestApplication возвращает последнюю заявку пользователя; nil, если он их не подавал.
+func (s *ProviderService) LatestApplication(ctx context.Context, userID int) (*model.ProviderApplication, error) {
+	a, err := s.applicationRepo.GetLatestByUser(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении заявки: %w", err)
+	}
+	return a, nil
+}
+
+// FindLocations сопоставляет названия локаций из заявки с каталогом: название, совпадающее
+// целиком, или единственная найденная по нему локация. Ненайденные названия возвращаются
+// отдельно — их закрепляет поддержка вручную.
+func (s *ProviderService) FindLocations(ctx context.Context, names []string) ([]int, []string, error) {
+	ids := []int{}
+	unresolved := []string{}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		found, err := s.locationRepo.FindByFilters(ctx, "", "", 0, name)
+		if err != nil {
+			return nil, nil, err
+		}
+		id := 0
+		for _, l := range found {
+			if strings.EqualFold(l.Name, name) {
+				id = l.ID
+				break
+			}
+		}
+		if id == 0 && len(found) == 1 {
+			id = found[0].ID
+		}
+		if id == 0 {
+			unresolved = append(unresolved, name)
+		} else if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, unresolved, nil
+}
+
+// Pending возвращает очередь заявок, ожидающих проверки.
+func (s *ProviderService) Pending(ctx context.Context, limit int) ([]model.ProviderApplication, error) {
+	return s.applicationRepo.ListByStatus(ctx, model.ApplicationPending, limit)
+}
+
+// Approve одобряет заявку: пользователь получает роль провайдера, а локации из заявки, еще не
+// закрепленные за другим провайдером, закрепляются за ним. Возвращает заявку и ID закрепленных локаций.
+// Если роль заявителя с момента подачи изменилась (например, администратор назначил его оператором),
+// заявка не одобряется: повышение до провайдера возможно только из туриста.
+func (s *ProviderService) Approve(ctx context.Context, reviewerID int, applicationID int) (*model.ProviderApplication, []int, error) {
+	linked := []int{}
+	a, err := s.decide(ctx, reviewerID, applicationID, model.ApplicationApproved, "", func(ctx context.Context, a *model.ProviderApplication) error {
+		// пользователь блокируется, чтобы роль не изменили между проверкой и повышением
+		user, err := s.userRepo.GetByIDForUpdate(ctx, a.UserID)
+		if err != nil {
+			return fmt.Errorf("ошибка при получении заявителя: %w", err)
+		}
+		if user.Role != "user" {
+			return fmt.Errorf("%w: сейчас у заявителя роль %s", ErrApplicantRole, user.Role)
+		}
+		if err := s.userRepo.UpdateRole(ctx, a.UserID, "provider"); err != nil {
+			return err
+		}
+		names := []string{}
+		for _, id := range a.LocationIDs {
+			loc, err := s.locationRepo.GetByIDForUpdate(ctx, int(id))
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("ошибка при получении локации: %w", err)
+			}
+			if loc.ProviderID != nil && *loc.ProviderID != a.UserID {
+				continue
+			}
+			if err := s.locationRepo.SetProvider(ctx, loc.ID, &a.UserID); err != nil {
+				return err
+			}
+			linked = append(linked, loc.ID)
+			names = append(names, loc.Name)
+		}
+		return enqueueNotification(ctx, s.notificationRepo, a.UserID, model.NotifyProviderApproved,
+			fmt.Sprintf("provider_application:%d:approved", a.ID), model.NotificationParams{
+				"application_id": strconv.Itoa(a.ID),
+				"business_name":  a.BusinessName,
+				"locations":      strings.Join(names, ", "),
+			})
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return a, linked, nil
+}
+
+// Reject отклоняет заявку; заявитель получает уведомление с причиной.
+func (s *ProviderService) Reject(ctx context.Context, reviewerID int, applicationID int, reason string) (*model.ProviderApplication, error) {
+	reason = strings.TrimSpace(reason)
+	return s.decide(ctx, reviewerID, applicationID, model.ApplicationRejected, reason, func(ctx context.Context, a *model.ProviderApplication) error {
+		return enqueueNotification(ctx, s.notificationRepo, a.UserID, model.NotifyProviderRejected,
+			fmt.Sprintf("provider_application:%d:rejected", a.ID), model.NotificationParams{
+				"application_id": strconv.Itoa(a.ID),
+				"business_name":  a.BusinessName,
+				"reason":         reason,
+			})
+	})
+}
+
+// decide записывает решение поддержки по заявке и выполняет then в той же транзакции.
+func (s *ProviderService) decide(ctx context.Context, reviewerID int, applicationID int, status string, reason string,
+	then func(ctx context.Context, a *model.ProviderApplication) error) (*model.ProviderApplication, error) {
+	if err := s.requireSupport(ctx, reviewerID); err != nil {
+		return nil, err
+	}
+	// заявка блокируется, чтобы два сотрудника поддержки не приняли по ней разные решения
+	var a *model.ProviderApplication
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		a, err = s.applicationRepo.GetByIDForUpdate(ctx, applicationID)
+		if err != nil {
+			return fmt.Errorf("заявка не найдена")
+		}
+		if a.Status != model.ApplicationPending {
+			return fmt.Errorf("заявка #%d уже обработана (статус %q)", applicationID, a.Status)
+		}
+		if err := s.applicationRepo.UpdateStatus(ctx, applicationID, status, &reviewerID, reason); err != nil {
+			return err
+		}
+		a.Status = status
+		a.ReviewerID = &reviewerID
+		a.Reason = reason
+		return then(ctx, a)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// LinkLocation закрепляет локацию за провайдером по решению поддержки.
+func (s *ProviderService) LinkLocation(ctx context.Context, supportID int, locationID int, providerID int) (*model.Location, error) {
+	if err := s.requireSupport(ctx, supportID); err != nil {
+		return nil, err
+	}
+	provider, err := s.userRepo.GetByID(ctx, providerID)
+	if err != nil {
+		return nil, fmt.Errorf("пользователь не найден")
+	}
+	if provider.Role != "provider" {
+		return nil, fmt.Errorf("пользователь %d не провайдер", providerID)
+	}
+	var loc *model.Location
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		loc, err = s.locationRepo.GetByIDForUpdate(ctx, locationID)
+		if err != nil {
+			return fmt.Errorf("локация не найдена")
+		}
+		if err := s.locationRepo.SetProvider(ctx, locationID, &providerID); err != nil {
+			return err
+		}
+		loc.ProviderID = &providerID
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return loc, nil
+}
+
+// ApplicationLocations возвращает локации, указанные в заявке.
+func (s *ProviderService) ApplicationLocations(ctx context.Context, a *model.ProviderApplication) ([]model.Location, error) {
+	locations := []model.Location{}
+	for _, id := range a.LocationIDs {
+		loc, err := s.locationRepo.GetByID(ctx, int(id))
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при получении локации: %w", err)
+		}
+		locations = append(locations, *loc)
+	}
+	return locations, nil
+}
+
+func (s *ProviderService) requireSupport(ctx context.Context, userID int) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || user.Role != "support" {
+		return fmt.Errorf("проверять заявки провайдеров может только поддержка")
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"tourism/internal/model"
+	"tourism/internal/repository/memory"
+)
+
+func TestApproveOnlyPromotesTourists(t *testing.T) {
+	tests := []struct {
+		name    string
+		role    string // роль заявителя к моменту проверки заявки
+		approve bool
+	}{
+		{"турист", "user", true},
+		{"назначен оператором", "support", false},
+		{"назначен администратором", "admin", false},
+		{"уже провайдер", "provider", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newReviewEnv(t)
+			ctx := context.Background()
+			applications := memory.NewProviderApplicationRepository(env.store)
+			notifications := memory.NewNotificationRepository(env.store)
+			providers := NewProviderService(env.store, applications, env.users, env.locations, notifications)
+			support := env.newUser("support", month)
+			applicant := env.newUser("user", month)
+			free := &model.Location{Name: "Гостевой дом", Description: "Дом", Category: "hotel", Region: "Алагир", Latitude: 42.8, Longitude: 44.1}
+			id, err := env.locations.Create(ctx, free)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			application, err := providers.Apply(ctx, &model.ProviderApplication{
+				UserID: applicant.ID, BusinessName: "Гостевой дом", Contacts: "+7 900 000-00-00",
+				DocumentFileIDs: []string{"doc"}, LocationIDs: []int64{int64(id)},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			// пока заявка ждала проверки, администратор изменил роль заявителя
+			if err := env.users.UpdateRole(ctx, applicant.ID, tt.role); err != nil {
+				t.Fatal(err)
+			}
+
+			_, linked, err := providers.Approve(ctx, support.ID, application.ID)
+			user, getErr := env.users.GetByID(ctx, applicant.ID)
+			if getErr != nil {
+				t.Fatal(getErr)
+			}
+			loc, getErr := env.locations.GetByID(ctx, id)
+			if getErr != nil {
+				t.Fatal(getErr)
+			}
+			pending, getErr := notifications.ClaimPending(ctx, time.Now().Add(time.Minute), time.Now().Add(time.Hour), 10)
+			if getErr != nil {
+				t.Fatal(getErr)
+			}
+			latest, getErr := providers.LatestApplication(ctx, applicant.ID)
+			if getErr != nil {
+				t.Fatal(getErr)
+			}
+
+			if tt.approve {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if user.Role != "provider" || len(linked) != 1 || loc.ProviderID == nil || *loc.ProviderID != applicant.ID {
+					t.Errorf("роль %q, закреплены %v, провайдер локации %v", user.Role, linked, loc.ProviderID)
+				}
+				if latest.Status != model.ApplicationApproved || len(pending) != 1 {
+					t.Errorf("статус заявки %q, уведомлений %d", latest.Status, len(pending))
+				}
+				return
+			}
+			if !errors.Is(err, ErrApplicantRole) {
+				t.Fatalf("ошибка %v, ожидалась ErrApplicantRole", err)
+			}
+			// одобрение откатывается целиком: роль, заявка, локации и уведомление не меняются
+			if user.Role != tt.role {
+				t.Errorf("роль заявителя изменена на %q", user.Role)
+			}
+			if latest.Status != model.ApplicationPending {
+				t.Errorf("статус заявки %q, ожидалось %q", latest.Status, model.ApplicationPending)
+			}
+			if loc.ProviderID != nil {
+				t.Errorf("локация закреплена за %d", *loc.ProviderID)
+			}
+			if len(pending) != 0 {
+				t.Errorf("поставлено уведомлений: %d", len(pending))
+			}
+			// заявку можно отклонить
+			if _, err := providers.Reject(ctx, support.ID, application.ID, "заявитель уже работает в системе"); err != nil {
+				t.Errorf("отклонение заявки: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"tourism/internal/model"
+)
+
+// ModerationRules задает пороги автоматической модерации отзывов.
+type ModerationRules struct {
+	FreshAccountAge time.Duration // аккаунты моложе этого возраста считаются новыми
+	LowRating       int           // оценки не выше этой считаются низкими
+	BurstWindow     time.Duration // окно, в котором считается всплеск низких оценок
+	BurstThreshold  int           // число низких оценок в окне, после которого новые удерживаются
+	MinDuplicateLen int           // минимальная длина текста для проверки на дубликаты
+}
+
+// DefaultModerationRules возвращает пороги модерации по умолчанию.
+func DefaultModerationRules() ModerationRules {
+	return ModerationRules{
+		FreshAccountAge: 7 * 24 * time.Hour,
+		LowRating:       2,
+		BurstWindow:     24 * time.Hour,
+		BurstThreshold:  3,
+		MinDuplicateLen: 20,
+	}
+}
+
+var (
+	// \b в Go учитывает только латиницу, поэтому кириллические домены .рф проверяются отдельно
+	linkPattern = regexp.MustCompile(`(?i)(https?://|www\.|t\.me/|\b[a-z0-9-]+\.(ru|com|net|org|info|io|me|su)\b|[\p{L}\d-]+\.рф([^\p{L}]|$))`)
+	// кандидаты в телефоны; номером считается последовательность хотя бы из minPhoneDigits цифр,
+	// чтобы не задевать диапазоны лет и цены
+	phonePattern = regexp.MustCompile(`\+?\d[\d\s\-()]{8,}\d`)
+	// e-mail и Telegram-аккаунты
+	contactPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`[\w.+-]+@[\w-]+\.[\w.]+`),
+		regexp.MustCompile(`(^|\s)@[A-Za-z0-9_]{5,}`),
+	}
+	wordPattern = regexp.MustCompile(`[\p{L}]+`)
+)
+
+const minPhoneDigits = 10
+
+// корни нецензурных слов: в profanityRoots ищутся внутри слова, в profanityPrefixes — только в начале слова
+// (чтобы не задевать обычные слова вроде «хлебать» или «употреблять»). Слова, содержащие
+// profanityExceptions («страхуем», «психуешь», «сучковатый»), не проверяются.
+var (
+	profanityRoots      = []string{"хуй", "хуе", "хуя", "хуи", "пизд", "бляд", "мудак", "мудил"}
+	profanityPrefixes   = []string{"еба", "ебу", "ебл", "заеб", "выеб", "уеб", "наеб", "отъеб", "поеб", "доеб", "блят", "сука", "суки", "сучк", "говн", "гавн", "дерьм"}
+	profanityExceptions = []string{"страху", "психу", "сучков"}
+)
+
+// textFlags проверяет текст отзыва на нецензурную лексику, ссылки и контакты.
+func textFlags(text string) []string {
+	flags := []string{}
+	if hasProfanity(text) {
+		flags = append(flags, model.FlagProfanity)
+	}
+	if linkPattern.MatchString(text) {
+		flags = append(flags, model.FlagLink)
+	}
+	if hasContacts(text) {
+		flags = append(flags, model.FlagContacts)
+	}
+	return flags
+}
+
+func hasProfanity(text string) bool {
+	normalized := strings.ReplaceAll(strings.ToLower(text), "ё", "е")
+	for _, word := range wordPattern.FindAllString(normalized, -1) {
+		if containsAny(word, profanityExceptions) {
+			continue
+		}
+		if containsAny(word, profanityRoots) {
+			return true
+		}
+		for _, prefix := range profanityPrefixes {
+			if strings.HasPrefix(word, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func containsAny(word string, parts []string) bool {
+	for _, part := range parts {
+		if strings.Contains(word, part) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasContacts(text string) bool {
+	for _, p := range contactPatterns {
+		if p.MatchString(text) {
+			return true
+		}
+	}
+	for _, candidate := range phonePattern.FindAllString(text, -1) {
+		digits := 0
+		for _, r := range candidate {
+			if r >= '0' && r <= '9' {
+				digits++
+			}
+		}
+		if digits >= minPhoneDigits {
+			return true
+		}
+	}
+	return false
+}
+
+// FlagDescription возвращает описание признака нарушения для модератора.
+func FlagDescription(flag string) string {
+	switch flag {
+	case model.FlagProfanity:
+		return "нецензурная лексика"
+	case model.FlagLink:
+		return "ссылки"
+	case model.FlagContacts:
+		return "контактные данные"
+	case model.FlagDuplicate:
+		return "повтор чужого отзыва"
+	case model.FlagFreshAccount:
+		return "низкая оценка от нового аккаунта"
+	case model.FlagRatingBurst:
+		return "всплеск низких оценок"
+	}
+	return flag
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"slices"
+	"testing"
+
+	"tourism/internal/model"
+)
+
+func TestTextFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"обычный отзыв", "Отличное место, всем советую! Были в 10.30, т.е. до толпы.", nil},
+
+		// нецензурная лексика
+		{"корень внутри слова", "Вид просто охуенный", []string{model.FlagProfanity}},
+		{"корень в начале слова", "Пиздец, а не дорога", []string{model.FlagProfanity}},
+		{"заглавные буквы", "БЛЯДСТВО какое-то", []string{model.FlagProfanity}},
+		{"ругательство в начале слова", "Блять, опять дождь", []string{model.FlagProfanity}},
+		{"ё вместо е", "Ёбаный сервис", []string{model.FlagProfanity}},
+		{"приставка", "Гид заебал своими историями", []string{model.FlagProfanity}},
+		{"оскорбление", "Хозяин — мудак", []string{model.FlagProfanity}},
+		{"грубое слово", "Еда — полное говно", []string{model.FlagProfanity}},
+		{"сука", "Сука, холодно", []string{model.FlagProfanity}},
+
+		// обычные слова, содержащие нецензурные корни
+		{"страхуем", "Мы всегда страхуем поездку, застрахуйте и вы багаж", nil},
+		{"психуешь", "Не психуйте, на подъемник успеете", nil},
+		{"оскорблять", "Нас никто не оскорблял, персонал вежливый", nil},
+		{"употреблять", "Употреблять воду из родника можно", nil},
+		{"истреблять", "Комаров истребляют каждую весну", nil},
+		{"рубля", "Без рубля не уедешь, корабля тут нет", nil},
+		{"хлебать", "Хлебали горячий суп, учебник истории пригодился", nil},
+		{"сучковатый", "Сучковатые деревья у тропы", nil},
+		{"сукно", "Сукно на столах старое", nil},
+		{"гавань", "Красивая гавань и художник у причала", nil},
+		{"потребление", "Потребление электричества не ограничено", nil},
+
+		// ссылки
+		{"https", "Подробнее на https://example.com/tour", []string{model.FlagLink}},
+		{"www", "Смотрите www.tour-ossetia", []string{model.FlagLink}},
+		{"t.me", "Пишите в t.me/guide", []string{model.FlagLink}},
+		{"домен", "Бронируйте на guide-ossetia.ru дешевле", []string{model.FlagLink}},
+		{"домен заглавными", "Сайт EXAMPLE.COM", []string{model.FlagLink}},
+		{"кириллический домен", "Все туры на гиды-осетии.рф", []string{model.FlagLink}},
+		{"кириллический домен с точкой", "Сайт гиды-осетии.рф.", []string{model.FlagLink}},
+		{"сокращения", "Цена 3.5 тыс., т.е. недорого. В РФ таких мест мало.", nil},
+
+		// контакты
+		{"телефон", "Звоните +7 (928) 123-45-67", []string{model.FlagContacts}},
+		{"телефон без разделителей", "Мой номер 89281234567", []string{model.FlagContacts}},
+		{"телефон с пробелами", "Тел. 8 928 123 45 67", []string{model.FlagContacts}},
+		{"e-mail", "Пишите guide@mail.ru", []string{model.FlagLink, model.FlagContacts}},
+		{"аккаунт Telegram", "Пишите @ossetia_guide", []string{model.FlagContacts}},
+		{"диапазон лет", "Ездим сюда с 2019 - 2023 годы", nil},
+		{"цена", "Цена 1500 рублей за 2 часа", nil},
+		{"дата", "Были 12.07.2024, оценка 5/5", nil},
+		{"короткое упоминание", "Спасибо @anna за экскурсию", nil},
+
+		{"несколько нарушений", "Пиздатый гид, звоните 8-928-123-45-67 или t.me/guide",
+			[]string{model.FlagProfanity, model.FlagLink, model.FlagContacts}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := textFlags(tt.text)
+			if !slices.Equal(got, tt.want) && !(len(got) == 0 && len(tt.want) == 0) {
+				t.Errorf("textFlags(%q) = %v, ожидалось %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,326 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"tourism/internal/model"
+	"tourism/internal/repository"
+)
+
+// максимальная длина текста отзыва и ответа провайдера
+const maxReviewLength = 2000
+
+// максимальное число фото в отзыве
+const maxReviewPhotos = 5
+
+// ReviewService содержит бизнес-логику отзывов и рейтингов локаций.
+type ReviewService struct {
+	tx               repository.Transactor
+	reviewRepo       repository.ReviewStore
+	locationRepo     repository.LocationStore
+	userRepo         repository.UserStore
+	notificationRepo repository.NotificationStore
+	rules            ModerationRules
+}
+
+// NewReviewService создает новый сервис отзывов.
+func NewReviewService(tx repository.Transactor, reviewRepo repository.ReviewStore, locationRepo repository.LocationStore,
+	userRepo repository.UserStore, notificationRepo repository.NotificationStore, rules ModerationRules) *ReviewService {
+	return &ReviewService{tx: tx, reviewRepo: reviewRepo, locationRepo: locationRepo, userRepo: userRepo,
+		notificationRepo: notificationRepo, rules: rules}
+}
+
+// CheckCanReview проверяет, может ли пользователь оставить отзыв о локации.
+func (s *ReviewService) CheckCanReview(ctx context.Context, userID int, locationID int) error {
+	ok, err := s.reviewRepo.CanReview(ctx, userID, locationID)
+	if err != nil {
+		return fmt.Errorf("ошибка при проверке права на отзыв: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("оставить отзыв можно после подтвержденного бронирования или завершенной поездки с этой локацией")
+	}
+	exists, err := s.reviewRepo.Exists(ctx, userID, locationID)
+	if err != nil {
+		return fmt.Errorf("ошибка при проверке отзывов: %w", err)
+	}
+	if exists {
+		return fmt.Errorf("вы уже оставили отзыв об этой локации")
+	}
+	return nil
+}
+
+// CreateReview сохраняет отзыв пользователя вместе с приложенными фото. Отзыв без фото и без
+// признаков нарушений публикуется сразу (с пересчетом рейтинга локации и уведомлением провайдера),
+// иначе удерживается на модерации в статусе "pending".
+func (s *ReviewService) CreateReview(ctx context.Context, userID int, locationID int, rating int, text string, photoFileIDs []string) (*model.Review, error) {
+	if rating < 1 || rating > 5 {
+		return nil, fmt.Errorf("оценка должна быть от 1 до 5")
+	}
+	text = strings.TrimSpace(text)
+	if utf8.RuneCountInString(text) > maxReviewLength {
+		return nil, fmt.Errorf("текст отзыва не должен превышать %d символов", maxReviewLength)
+	}
+	if len(photoFileIDs) > maxReviewPhotos {
+		return nil, fmt.Errorf("к отзыву можно приложить не более %d фото", maxReviewPhotos)
+	}
+	if err := s.CheckCanReview(ctx, userID, locationID); err != nil {
+		return nil, err
+	}
+	review := &model.Review{
+		LocationID:   locationID,
+		UserID:       userID,
+		Rating:       rating,
+		Text:         text,
+		PhotoFileIDs: append([]string{}, photoFileIDs...),
+		Status:       "published",
+		CreatedAt:    time.Now(),
+	}
+	flags, err := s.moderate(ctx, review)
+	if err != nil {
+		return nil, err
+	}
+	if len(review.PhotoFileIDs) > 0 {
+		flags = append(flags, model.FlagPhotos)
+	}
+	review.Flags = flags
+	entry := &model.ModerationEntry{Action: model.ModerationAutoPublished}
+	if len(flags) > 0 {
+		review.Status = "pending"
+		entry.Action = model.ModerationHeld
+		entry.Reason = strings.Join(flags, ",")
+	}
+	// отзыв, запись журнала, рейтинг локации и уведомление провайдера сохраняются вместе
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		id, err := s.reviewRepo.Create(ctx, review)
+		if err != nil {
+			return err
+		}
+		review.ID = id
+		entry.ReviewID = id
+		if err := s.reviewRepo.AddModerationEntry(ctx, entry); err != nil {
+			return err
+		}
+		if review.Status == "published" {
+			return s.published(ctx, review)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// moderate применяет правила автоматической модерации и возвращает найденные признаки нарушений.
+func (s *ReviewService) moderate(ctx context.Context, review *model.Review) ([]string, error) {
+	flags := textFlags(review.Text)
+	if utf8.RuneCountInString(review.Text) >= s.rules.MinDuplicateLen {
+		dup, err := s.reviewRepo.DuplicateTextExists(ctx, review.UserID, review.Text)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при проверке отзыва: %w", err)
+		}
+		if dup {
+			flags = append(flags, model.FlagDuplicate)
+		}
+	}
+	if review.Rating <= s.rules.LowRating {
+		author, err := s.userRepo.GetByID(ctx, review.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при проверке отзыва: %w", err)
+		}
+		if review.CreatedAt.Sub(author.CreatedAt) < s.rules.FreshAccountAge {
+			flags = append(flags, model.FlagFreshAccount)
+		}
+		recent, err := s.reviewRepo.CountLowRatings(ctx, review.LocationID, s.rules.LowRating, review.CreatedAt.Add(-s.rules.BurstWindow))
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при проверке отзыва: %w", err)
+		}
+		if recent >= s.rules.BurstThreshold {
+			flags = append(flags, model.FlagRatingBurst)
+		}
+	}
+	return flags, nil
+}
+
+// PendingReviews возвращает очередь отзывов, ожидающих модерации.
+func (s *ReviewService) PendingReviews(ctx context.Context, limit int) ([]model.Review, error) {
+	return s.reviewRepo.ListByStatus(ctx, "pending", limit)
+}
+
+// CountPending возвращает число отзывов в очереди модерации.
+func (s *ReviewService) CountPending(ctx context.Context) (int, error) {
+	return s.reviewRepo.CountByStatus(ctx, "pending")
+}
+
+// Approve публикует удержанный отзыв и пересчитывает рейтинг локации.
+func (s *ReviewService) Approve(ctx context.Context, moderatorID int, reviewID int) (*model.Review, error) {
+	return s.decide(ctx, moderatorID, reviewID, "published", model.ModerationApproved, "")
+}
+
+// Reject отклоняет удержанный отзыв.
+func (s *ReviewService) Reject(ctx context.Context, moderatorID int, reviewID int, reason string) (*model.Review, error) {
+	return s.decide(ctx, moderatorID, reviewID, "rejected", model.ModerationRejected, reason)
+}
+
+// ModerationLog возвращает журнал решений по отзыву.
+func (s *ReviewService) ModerationLog(ctx context.Context, reviewID int) ([]model.ModerationEntry, error) {
+	return s.reviewRepo.ListModerationEntries(ctx, reviewID)
+}
+
+func (s *ReviewService) decide(ctx context.Context, moderatorID int, reviewID int, status string, action string, reason string) (*model.Review, error) {
+	// отзыв блокируется, чтобы два модератора не приняли по нему разные решения
+	var review *model.Review
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		review, err = s.reviewRepo.GetByIDForUpdate(ctx, reviewID)
+		if err != nil {
+			return fmt.Errorf("отзыв не найден")
+		}
+		if review.Status != "pending" {
+			return fmt.Errorf("отзыв #%d уже обработан (статус %q)", reviewID, review.Status)
+		}
+		if err := s.reviewRepo.UpdateStatus(ctx, reviewID, status); err != nil {
+			return err
+		}
+		review.Status = status
+		entry := &model.ModerationEntry{ReviewID: reviewID, ModeratorID: &moderatorID, Action: action, Reason: reason}
+		if err := s.reviewRepo.AddModerationEntry(ctx, entry); err != nil {
+			return err
+		}
+		if status == "published" {
+			return s.published(ctx, review)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// published пересчитывает рейтинг локации опубликованного отзыва и ставит в очередь уведомление
+// ее провайдеру. Вызывается внутри транзакции публикации.
+func (s *ReviewService) published(ctx context.Context, review *model.Review) error {
+	if err := s.reviewRepo.RecalculateRating(ctx, review.LocationID); err != nil {
+		return err
+	}
+	location, err := s.locationRepo.GetByID(ctx, review.LocationID)
+	if err != nil {
+		return err
+	}
+	if location.ProviderID == nil {
+		return nil
+	}
+	return enqueueNotification(ctx, s.notificationRepo, *location.ProviderID, model.NotifyReviewPublished,
+		fmt.Sprintf("review:%d:published", review.ID), model.NotificationParams{
+			"review_id": strconv.Itoa(review.ID),
+			"location":  location.Name,
+			"rating":    strconv.Itoa(review.Rating),
+			"text":      review.Text,
+		})
+}
+
+// AddPhoto добавляет фото к отзыву его автора. Фото проверяет модератор, поэтому опубликованный
+// отзыв снимается с публикации и возвращается на модерацию, а рейтинг локации пересчитывается.
+func (s *ReviewService) AddPhoto(ctx context.Context, userID int, reviewID int, fileID string) (*model.Review, error) {
+	var review *model.Review
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		review, err = s.reviewRepo.GetByIDForUpdate(ctx, reviewID)
+		if err != nil || review.UserID != userID {
+			return fmt.Errorf("отзыв не найден")
+		}
+		if review.Status == "rejected" {
+			return fmt.Errorf("отзыв #%d отклонен модератором", reviewID)
+		}
+		if len(review.PhotoFileIDs) >= maxReviewPhotos {
+			return fmt.Errorf("к отзыву можно приложить не более %d фото", maxReviewPhotos)
+		}
+		if err := s.reviewRepo.AddPhoto(ctx, reviewID, fileID); err != nil {
+			return err
+		}
+		review.PhotoFileIDs = append(review.PhotoFileIDs, fileID)
+		if review.Status != "published" {
+			return nil
+		}
+		if err := s.reviewRepo.UpdateStatus(ctx, reviewID, "pending"); err != nil {
+			return err
+		}
+		review.Status = "pending"
+		entry := &model.ModerationEntry{ReviewID: reviewID, Action: model.ModerationHeld, Reason: model.FlagPhotos}
+		if err := s.reviewRepo.AddModerationEntry(ctx, entry); err != nil {
+			return err
+		}
+		return s.reviewRepo.RecalculateRating(ctx, review.LocationID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// ListReviews возвращает опубликованные отзывы о локации и их общее число.
+func (s *ReviewService) ListReviews(ctx context.Context, locationID int, limit int, offset int) ([]model.Review, int, error) {
+	reviews, err := s.reviewRepo.ListPublished(ctx, locationID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.reviewRepo.CountPublished(ctx, locationID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ошибка при подсчете отзывов: %w", err)
+	}
+	return reviews, total, nil
+}
+
+// GetReview возвращает отзыв по ID.
+func (s *ReviewService) GetReview(ctx context.Context, reviewID int) (*model.Review, error) {
+	return s.reviewRepo.GetByID(ctx, reviewID)
+}
+
+// Reply сохраняет публичный ответ провайдера и ставит в очередь уведомление автору отзыва.
+// Отвечать может только провайдер, владеющий локацией, и только на опубликованный отзыв;
+// об изменении ответа автор повторно не уведомляется.
+func (s *ReviewService) Reply(ctx context.Context, providerID int, reviewID int, text string) (*model.Review, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, fmt.Errorf("ответ не может быть пустым")
+	}
+	if utf8.RuneCountInString(text) > maxReviewLength {
+		return nil, fmt.Errorf("ответ не должен превышать %d символов", maxReviewLength)
+	}
+	review, err := s.reviewRepo.GetByID(ctx, reviewID)
+	if err != nil {
+		return nil, fmt.Errorf("отзыв не найден")
+	}
+	location, err := s.locationRepo.GetByID(ctx, review.LocationID)
+	if err != nil {
+		return nil, fmt.Errorf("локация не найдена")
+	}
+	if location.ProviderID == nil || *location.ProviderID != providerID {
+		return nil, fmt.Errorf("отвечать на отзыв может только провайдер локации")
+	}
+	if review.Status != "published" {
+		return nil, fmt.Errorf("ответить можно только на опубликованный отзыв")
+	}
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.reviewRepo.SetReply(ctx, reviewID, text); err != nil {
+			return err
+		}
+		return enqueueNotification(ctx, s.notificationRepo, review.UserID, model.NotifyReviewReply,
+			fmt.Sprintf("review:%d:reply", reviewID), model.NotificationParams{
+				"review_id": strconv.Itoa(reviewID),
+				"location":  location.Name,
+				"reply":     text,
+			})
+	})
+	if err != nil {
+		return nil, err
+	}
+	review.Reply = text
+	return review, nil
+}
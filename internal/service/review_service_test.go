@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"tourism/internal/model"
+	"tourism/internal/repository/memory"
+)
+
+type reviewEnv struct {
+	t         *testing.T
+	store     *memory.Store
+	users     *memory.UserRepository
+	bookings  *memory.BookingRepository
+	locations *memory.LocationRepository
+	reviews   *ReviewService
+	provider  *model.User
+	location  *model.Location
+	nextTgID  int64
+}
+
+func newReviewEnv(t *testing.T) *reviewEnv {
+	t.Helper()
+	s := memory.NewStore()
+	env := &reviewEnv{
+		t:         t,
+		store:     s,
+		users:     memory.NewUserRepository(s),
+		bookings:  memory.NewBookingRepository(s),
+		locations: memory.NewLocationRepository(s),
+		nextTgID:  100,
+	}
+	env.reviews = NewReviewService(s, memory.NewReviewRepository(s), env.locations, env.users,
+		memory.NewNotificationRepository(s), DefaultModerationRules())
+	env.provider = env.newUser("provider", 0)
+	env.location = &model.Location{Name: "Цейское ущелье", Description: "Ущелье в горах", Category: "nature",
+		Region: "Алагир", Latitude: 42.79, Longitude: 43.9, ProviderID: &env.provider.ID}
+	id, err := env.locations.Create(context.Background(), env.location)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.location.ID = id
+	return env
+}
+
+// newUser создает пользователя с ролью role, зарегистрированного age назад.
+func (e *reviewEnv) newUser(role string, age time.Duration) *model.User {
+	e.t.Helper()
+	created := time.Now().Add(-age)
+	e.store.SetClock(func() time.Time { return created })
+	defer e.store.SetClock(time.Now)
+	e.nextTgID++
+	u := &model.User{TelegramID: e.nextTgID, FirstName: "Тест", Role: role}
+	id, err := e.users.Create(context.Background(), u)
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	u.ID = id
+	u.CreatedAt = created
+	return u
+}
+
+// newTourist создает туриста с подтвержденным бронированием локации.
+func (e *reviewEnv) newTourist(age time.Duration) *model.User {
+	e.t.Helper()
+	u := e.newUser("user", age)
+	_, err := e.bookings.Create(context.Background(), &model.Booking{UserID: u.ID, LocationID: e.location.ID, Status: "confirmed"})
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	return u
+}
+
+func (e *reviewEnv) create(u *model.User, rating int, text string) *model.Review {
+	e.t.Helper()
+	review, err := e.reviews.CreateReview(context.Background(), u.ID, e.location.ID, rating, text, nil)
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	return review
+}
+
+const month = 30 * 24 * time.Hour
+
+func TestCreateReviewModeration(t *testing.T) {
+	t.Run("чистый отзыв публикуется", func(t *testing.T) {
+		env := newReviewEnv(t)
+		review := env.create(env.newTourist(month), 5, "Отличное место, всем советую")
+		if review.Status != "published" || len(review.Flags) != 0 {
+			t.Errorf("статус %q, признаки %v", review.Status, review.Flags)
+		}
+	})
+	t.Run("повтор чужого отзыва", func(t *testing.T) {
+		env := newReviewEnv(t)
+		const text = "Прекрасные виды и чистый воздух, обязательно вернемся"
+		env.create(env.newTourist(month), 5, text)
+		review := env.create(env.newTourist(month), 5, "  "+text+"  ")
+		if review.Status != "pending" || !slices.Contains(review.Flags, model.FlagDuplicate) {
+			t.Errorf("статус %q, признаки %v: повтор не удержан", review.Status, review.Flags)
+		}
+		// короткие тексты вроде «Все понравилось» совпадают случайно
+		env.create(env.newTourist(month), 5, "Все понравилось")
+		if short := env.create(env.newTourist(month), 5, "Все понравилось"); short.Status != "published" {
+			t.Errorf("короткий повтор удержан с признаками %v", short.Flags)
+		}
+	})
+	t.Run("низкая оценка от нового аккаунта", func(t *testing.T) {
+		env := newReviewEnv(t)
+		fresh := env.create(env.newTourist(time.Hour), 1, "Не понравилось")
+		if fresh.Status != "pending" || !slices.Equal(fresh.Flags, []string{model.FlagFreshAccount}) {
+			t.Errorf("статус %q, признаки %v", fresh.Status, fresh.Flags)
+		}
+		if good := env.create(env.newTourist(time.Hour), 5, "Понравилось"); good.Status != "published" {
+			t.Errorf("высокая оценка от нового аккаунта удержана с признаками %v", good.Flags)
+		}
+		if old := env.create(env.newTourist(month), 2, "Так себе"); old.Status != "published" {
+			t.Errorf("низкая оценка от старого аккаунта удержана с признаками %v", old.Flags)
+		}
+	})
+	t.Run("всплеск низких оценок", func(t *testing.T) {
+		env := newReviewEnv(t)
+		rules := DefaultModerationRules()
+		for i := 0; i < rules.BurstThreshold; i++ {
+			if r := env.create(env.newTourist(month), 1, "Плохо"); slices.Contains(r.Flags, model.FlagRatingBurst) {
+				t.Fatalf("оценка %d из %d удержана как всплеск", i+1, rules.BurstThreshold)
+			}
+		}
+		review := env.create(env.newTourist(month), 2, "Плохо")
+		if review.Status != "pending" || !slices.Equal(review.Flags, []string{model.FlagRatingBurst}) {
+			t.Errorf("статус %q, признаки %v: всплеск не удержан", review.Status, review.Flags)
+		}
+		if good := env.create(env.newTourist(month), 5, "Хорошо"); good.Status != "published" {
+			t.Errorf("высокая оценка во время всплеска удержана с признаками %v", good.Flags)
+		}
+	})
+	t.Run("нарушения в тексте", func(t *testing.T) {
+		env := newReviewEnv(t)
+		review := env.create(env.newTourist(month), 5, "Звоните +7 928 123-45-67")
+		if review.Status != "pending" || !slices.Equal(review.Flags, []string{model.FlagContacts}) {
+			t.Errorf("статус %q, признаки %v", review.Status, review.Flags)
+		}
+	})
+}
+
+func TestReplyOnlyToPublished(t *testing.T) {
+	env := newReviewEnv(t)
+	ctx := context.Background()
+	moderator := env.newUser("admin", month)
+	published := env.create(env.newTourist(month), 5, "Отличное место")
+	pending := env.create(env.newTourist(month), 5, "Пишите t.me/guide")
+	rejected := env.create(env.newTourist(month), 5, "Смотрите www.example.com")
+	if _, err := env.reviews.Reject(ctx, moderator.ID, rejected.ID, "реклама"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, review := range []*model.Review{pending, rejected} {
+		if _, err := env.reviews.Reply(ctx, env.provider.ID, review.ID, "Спасибо"); err == nil {
+			t.Errorf("ответ на отзыв в статусе %q сохранен", review.Status)
+		}
+		got, err := env.reviews.GetReview(ctx, review.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Reply != "" {
+			t.Errorf("у неопубликованного отзыва %d сохранен ответ %q", review.ID, got.Reply)
+		}
+	}
+
+	stranger := env.newUser("provider", month)
+	if _, err := env.reviews.Reply(ctx, stranger.ID, published.ID, "Спасибо"); err == nil {
+		t.Error("чужой провайдер ответил на отзыв")
+	}
+	reply, err := env.reviews.Reply(ctx, env.provider.ID, published.ID, "Спасибо, ждем снова")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.Reply != "Спасибо, ждем снова" {
+		t.Errorf("ответ %q", reply.Reply)
+	}
+
+	// отзыв, одобренный модератором, становится доступен для ответа
+	if _, err := env.reviews.Approve(ctx, moderator.ID, pending.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.reviews.Reply(ctx, env.provider.ID, pending.ID, "Спасибо"); err != nil {
+		t.Errorf("ответ на одобренный отзыв: %v", err)
+	}
+}
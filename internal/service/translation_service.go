@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"tourism/internal/model"
+	"tourism/internal/repository"
+)
+
+// ограничения на длину переводов
+const (
+	maxTranslationName        = 200
+	maxTranslationDescription = 4000
+)
+
+// ErrNotModerator возвращается, если очередь переводов просматривает или решение по переводу
+// принимает не сотрудник поддержки.
+var ErrNotModerator = errors.New("проверять переводы может только поддержка")
+
+// ErrTranslationForbidden возвращается, если перевод предлагает не провайдер записи и не поддержка.
+var ErrTranslationForbidden = errors.New("переводить запись может только ее провайдер или поддержка")
+
+// TranslationService содержит логику переводов названий и описаний локаций и предложений:
+// провайдеры предлагают переводы своих записей, поддержка их проверяет, а пользователи
+// видят записи на своем языке, если для него есть одобренный перевод.
+type TranslationService struct {
+	tx               repository.Transactor
+	translationRepo  repository.TranslationStore
+	locationRepo     repository.LocationStore
+	offerRepo        repository.OfferStore
+	userRepo         repository.UserStore
+	notificationRepo repository.NotificationStore
+}
+
+// NewTranslationService создает новый сервис переводов.
+func NewTranslationService(tx repository.Transactor, translationRepo repository.TranslationStore,
+	locationRepo repository.LocationStore, offerRepo repository.OfferStore, userRepo repository.UserStore,
+	notificationRepo repository.NotificationStore) *TranslationService {
+	return &TranslationService{tx: tx, translationRepo: translationRepo, locationRepo: locationRepo,
+		offerRepo: offerRepo, userRepo: userRepo, notificationRepo: notificationRepo}
+}
+
+// Submit сохраняет перевод записи entity/entityID на язык locale. Провайдер может переводить
+// только свои локации и их предложения, его перевод ждет проверки; перевод от поддержки
+// публикуется сразу. Поддерживает ли язык интерфейс, проверяет вызывающий.
+func (s *TranslationService) Submit(ctx context.Context, userID int, entity string, entityID int,
+	locale string, name string, description string) (*model.Translation, error) {
+	locale = model.BaseLanguage(locale)
+	name = strings.TrimSpace(name)
+	description = strings.TrimSpace(description)
+	switch {
+	case locale == "":
+		return nil, fmt.Errorf("не указан язык перевода")
+	case locale == model.ContentLanguage:
+		return nil, fmt.Errorf("основной язык контента (%s) не переводится, он хранится в самой записи", model.ContentLanguage)
+	case name == "":
+		return nil, fmt.Errorf("название перевода не может быть пустым")
+	case utf8.RuneCountInString(name) > maxTranslationName:
+		return nil, fmt.Errorf("название перевода длиннее %d символов", maxTranslationName)
+	case utf8.RuneCountInString(description) > maxTranslationDescription:
+		return nil, fmt.Errorf("описание перевода длиннее %d символов", maxTranslationDescription)
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("пользователь не найден")
+	}
+	original, ownerID, err := s.entity(ctx, entity, entityID)
+	if err != nil {
+		return nil, err
+	}
+	t := &model.Translation{Entity: entity, EntityID: entityID, Locale: locale, Name: name,
+		Description: description, Status: model.TranslationPending, AuthorID: &userID}
+	switch {
+	case user.Role == "support":
+		t.Status = model.TranslationApproved
+	case user.Role == "provider" && ownerID != nil && *ownerID == userID:
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrTranslationForbidden, original)
+	}
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if t.Status == model.TranslationApproved {
+			if err := s.translationRepo.ReplaceApproved(ctx, entity, entityID, locale); err != nil {
+				return err
+			}
+		}
+		id, err := s.translationRepo.Create(ctx, t)
+		if err != nil {
+			return err
+		}
+		t.ID = id
+		if t.Status == model.TranslationApproved {
+			return s.translationRepo.UpdateStatus(ctx, id, model.TranslationApproved, &userID, "")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Pending возвращает сотруднику поддержки reviewerID очередь переводов, ожидающих проверки.
+func (s *TranslationService) Pending(ctx context.Context, reviewerID int, limit int) ([]model.Translation, error) {
+	if err := s.requireModerator(ctx, reviewerID); err != nil {
+		return nil, err
+	}
+	return s.translationRepo.ListByStatus(ctx, model.TranslationPending, limit)
+}
+
+// Original возвращает название записи, к которой относится перевод, на основном языке.
+func (s *TranslationService) Original(ctx context.Context, t *model.Translation) (string, error) {
+	name, _, err := s.entity(ctx, t.Entity, t.EntityID)
+	return name, err
+}
+
+// Approve публикует перевод; действовавший перевод записи на тот же язык заменяется.
+func (s *TranslationService) Approve(ctx context.Context, reviewerID int, translationID int) (*model.Translation, error) {
+	return s.decide(ctx, reviewerID, translationID, model.TranslationApproved, "")
+}
+
+// Reject отклоняет перевод.
+func (s *TranslationService) Reject(ctx context.Context, reviewerID int, translationID int, reason string) (*model.Translation, error) {
+	return s.decide(ctx, reviewerID, translationID, model.TranslationRejected, strings.TrimSpace(reason))
+}
+
+func (s *TranslationService) decide(ctx context.Context, reviewerID int, translationID int, status string, reason string) (*model.Translation, error) {
+	if err := s.requireModerator(ctx, reviewerID); err != nil {
+		return nil, err
+	}
+	// перевод блокируется, чтобы два сотрудника поддержки не приняли по нему разные решения
+	var t *model.Translation
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		t, err = s.translationRepo.GetByIDForUpdate(ctx, translationID)
+		if err != nil {
+			return fmt.Errorf("перевод не найден")
+		}
+		if t.Status != model.TranslationPending {
+			return fmt.Errorf("перевод #%d уже обработан (статус %q)", translationID, t.Status)
+		}
+		if status == model.TranslationApproved {
+			if err := s.translationRepo.ReplaceApproved(ctx, t.Entity, t.EntityID, t.Locale); err != nil {
+				return err
+			}
+		}
+		if err := s.translationRepo.UpdateStatus(ctx, translationID, status, &reviewerID, reason); err != nil {
+			return err
+		}
+		t.Status = status
+		t.ReviewerID = &reviewerID
+		t.Reason = reason
+		return s.notifyAuthor(ctx, t)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// requireModerator проверяет по базе, что userID — сотрудник поддержки и не заблокирован.
+func (s *TranslationService) requireModerator(ctx context.Context, userID int) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || user.Role != "support" || user.IsBlocked {
+		return ErrNotModerator
+	}
+	return nil
+}
+
+// notifyAuthor ставит в очередь уведомление автору перевода о решении поддержки.
+// Вызывается внутри транзакции решения.
+func (s *TranslationService) notifyAuthor(ctx context.Context, t *model.Translation) error {
+	if t.AuthorID == nil || *t.AuthorID == *t.ReviewerID {
+		return nil
+	}
+	original, _, err := s.entity(ctx, t.Entity, t.EntityID)
+	if err != nil {
+		return err
+	}
+	kind := model.NotifyTranslationApproved
+	if t.Status == model.TranslationRejected {
+		kind = model.NotifyTranslationRejected
+	}
+	return enqueueNotification(ctx, s.notificationRepo, *t.AuthorID, kind,
+		fmt.Sprintf("translation:%d:%s", t.ID, t.Status), model.NotificationParams{
+			"translation_id": strconv.Itoa(t.ID),
+			"original":       original,
+			"locale":         t.Locale,
+			"name":           t.Name,
+			"reason":         t.Reason,
+		})
+}
+
+// entity возвращает название записи на основном языке и ID ее провайдера.
+func (s *TranslationService) entity(ctx context.Context, entity string, entityID int) (string, *int, error) {
+	switch entity {
+	case model.TranslationLocation:
+		loc, err := s.locationRepo.GetByID(ctx, entityID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil, fmt.Errorf("локация не найдена")
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("ошибка при получении локации: %w", err)
+		}
+		return loc.Name, loc.ProviderID, nil
+	case model.TranslationOffer:
+		offer, err := s.offerRepo.GetByID(ctx, entityID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil, fmt.Errorf("предложение не найдено")
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("ошибка при получении предложения: %w", err)
+		}
+		loc, err := s.locationRepo.GetByID(ctx, offer.LocationID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return offer.Name, nil, nil
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("ошибка при получении локации предложения: %w", err)
+		}
+		return offer.Name, loc.ProviderID, nil
+	}
+	return "", nil, fmt.Errorf("неизвестный тип записи %q", entity)
+}
+
+// LocalizeLocations подставляет в локации названия и описания на языке lang из одобренных
+// переводов. Локации без перевода, а также пустое описание перевода остаются на основном языке.
+func (s *TranslationService) LocalizeLocations(ctx context.Context, lang string, locations []model.Location) error {
+	ids := make([]int, len(locations))
+	for i, l := range locations {
+		ids[i] = l.ID
+	}
+	translated, err := s.approved(ctx, model.TranslationLocation, ids, lang)
+	if err != nil {
+		return err
+	}
+	for i := range locations {
+		if t, ok := translated[locations[i].ID]; ok {
+			locations[i].Name, locations[i].Description = t.Name, localized(t.Description, locations[i].Description)
+		}
+	}
+	return nil
+}
+
+// LocalizeLocation подставляет в локацию название и описание на языке lang.
+func (s *TranslationService) LocalizeLocation(ctx context.Context, lang string, loc *model.Location) error {
+	locations := []model.Location{*loc}
+	if err := s.LocalizeLocations(ctx, lang, locations); err != nil {
+		return err
+	}
+	*loc = locations[0]
+	return nil
+}
+
+// LocalizeOffers подставляет в предложения названия и описания на языке lang.
+func (s *TranslationService) LocalizeOffers(ctx context.Context, lang string, offers []model.Offer) error {
+	ids := make([]int, len(offers))
+	for i, o := range offers {
+		ids[i] = o.ID
+	}
+	translated, err := s.approved(ctx, model.TranslationOffer, ids, lang)
+	if err != nil {
+		return err
+	}
+	for i := range offers {
+		if t, ok := translated[offers[i].ID]; ok {
+			offers[i].Name, offers[i].Description = t.Name, localized(t.Description, offers[i].Description)
+		}
+	}
+	return nil
+}
+
+// approved возвращает одобренные переводы записей на основной язык lang ("en-US" -> "en").
+// Для основного языка контента переводы не запрашиваются.
+func (s *TranslationService) approved(ctx context.Context, entity string, ids []int, lang string) (map[int]model.Translation, error) {
+	lang = model.BaseLanguage(lang)
+	if lang == "" || lang == model.ContentLanguage || len(ids) == 0 {
+		return nil, nil
+	}
+	list, err := s.translationRepo.ListApproved(ctx, entity, ids, lang)
+	if err != nil {
+		return nil, err
+	}
+	translated := make(map[int]model.Translation, len(list))
+	for _, t := range list {
+		translated[t.EntityID] = t
+	}
+	return translated, nil
+}
+
+func localized(translation, original string) string {
+	if translation == "" {
+		return original
+	}
+	return translation
+}
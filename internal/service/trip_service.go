@@ -1,36 +1,48 @@
 package service
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"math"
+
 	"tourism/internal/model"
 	"tourism/internal/repository"
 )
 
+var (
+	// ErrTripEmpty возвращается при завершении маршрута без локаций.
+	ErrTripEmpty = errors.New("в маршруте нет локаций")
+	// ErrTripCompleted возвращается при повторном завершении маршрута.
+	ErrTripCompleted = errors.New("маршрут уже завершен")
+)
+
 // TripService содержит бизнес-логику, связанную с планированием поездок (маршрутов).
 type TripService struct {
-	tripRepo     *repository.TripRepository
-	locationRepo *repository.LocationRepository
+	tripRepo     repository.TripStore
+	locationRepo repository.LocationStore
 }
 
 // NewTripService создает новый сервис для работы с маршрутами.
-func NewTripService(tripRepo *repository.TripRepository, locationRepo *repository.LocationRepository) *TripService {
+func NewTripService(tripRepo repository.TripStore, locationRepo repository.LocationStore) *TripService {
 	return &TripService{tripRepo: tripRepo, locationRepo: locationRepo}
 }
 
 // CreateTrip создает новый маршрут (поездку) для пользователя.
-func (s *TripService) CreateTrip(userID int, name string) (int, error) {
-	return s.tripRepo.Create(userID, name)
+func (s *TripService) CreateTrip(ctx context.Context, userID int, name string) (int, error) {
+	return s.tripRepo.Create(ctx, userID, name)
 }
 
 // AddLocationToTrip добавляет локацию в маршрут.
-func (s *TripService) AddLocationToTrip(tripID int, locationID int) error {
-	return s.tripRepo.AddLocation(tripID, locationID)
+func (s *TripService) AddLocationToTrip(ctx context.Context, tripID int, locationID int) error {
+	return s.tripRepo.AddLocation(ctx, tripID, locationID)
 }
 
 // OptimizeTrip оптимизирует порядок точек в маршруте по географической близости (наивный алгоритм).
 // Возвращает упорядоченный список локаций.
-func (s *TripService) OptimizeTrip(tripID int) ([]model.Location, error) {
-	locations, err := s.tripRepo.GetLocations(tripID)
+func (s *TripService) OptimizeTrip(ctx context.Context, tripID int) ([]model.Location, error) {
+	locations, err := s.tripRepo.GetLocations(ctx, tripID)
 	if err != nil {
 		return nil, err
 	}
@@ -65,11 +77,53 @@ func (s *TripService) OptimizeTrip(tripID int) ([]model.Location, error) {
 	for _, loc := range optimized {
 		locIDs = append(locIDs, loc.ID)
 	}
-	s.tripRepo.UpdateOrder(tripID, locIDs)
+	if err := s.tripRepo.UpdateOrder(ctx, tripID, locIDs); err != nil {
+		return nil, err
+	}
 	return optimized, nil
 }
 
 // GetTripLocations возвращает локации маршрута в текущем порядке.
-func (s *TripService) GetTripLocations(tripID int) ([]model.Location, error) {
-	return s.tripRepo.GetLocations(tripID)
+func (s *TripService) GetTripLocations(ctx context.Context, tripID int) ([]model.Location, error) {
+	return s.tripRepo.GetLocations(ctx, tripID)
+}
+
+// GetActiveTrip возвращает текущий (последний незавершенный) маршрут пользователя или nil, если его нет.
+func (s *TripService) GetActiveTrip(ctx context.Context, userID int) (*model.Trip, error) {
+	trip, err := s.tripRepo.GetActive(ctx, userID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении текущего маршрута: %w", err)
+	}
+	return trip, nil
+}
+
+// CompleteTrip завершает маршрут владельца: после поездки пользователь может оставить отзывы
+// о его локациях. Завершить можно только свой черновик хотя бы с одной локацией.
+func (s *TripService) CompleteTrip(ctx context.Context, userID int, tripID int) (*model.Trip, error) {
+	trip, err := s.tripRepo.GetByID(ctx, tripID)
+	if err != nil {
+		return nil, err
+	}
+	if trip.UserID != userID {
+		return nil, ErrNotTripOwner
+	}
+	locations, err := s.tripRepo.GetLocations(ctx, tripID)
+	if err != nil {
+		return nil, err
+	}
+	if len(locations) == 0 {
+		return nil, ErrTripEmpty
+	}
+	ok, err := s.tripRepo.Complete(ctx, tripID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrTripCompleted
+	}
+	trip.Status = "completed"
+	return trip, nil
 }
@@ -1,21 +1,42 @@
 package service
 
 import (
+	"context"
+	"fmt"
+
 	"tourism/internal/model"
 	"tourism/internal/repository"
 )
 
 // UserService содержит бизнес-логику, связанную с пользователями.
 type UserService struct {
-	userRepo *repository.UserRepository
+	userRepo repository.UserStore
 }
 
 // NewUserService создает новый сервис пользователей.
-func NewUserService(userRepo *repository.UserRepository) *UserService {
+func NewUserService(userRepo repository.UserStore) *UserService {
 	return &UserService{userRepo: userRepo}
 }
 
 // GetByID возвращает пользователя по ID (обертка над репозиторием).
-func (s *UserService) GetByID(id int) (*model.User, error) {
-	return s.userRepo.GetByID(id)
+func (s *UserService) GetByID(ctx context.Context, id int) (*model.User, error) {
+	return s.userRepo.GetByID(ctx, id)
+}
+
+// GetByTelegramID возвращает пользователя по Telegram ID (обертка над репозиторием).
+func (s *UserService) GetByTelegramID(ctx context.Context, telegramID int64) (*model.User, error) {
+	return s.userRepo.GetByTelegramID(ctx, telegramID)
+}
+
+// SetLanguage сохраняет язык интерфейса, выбранный пользователем; поддерживает ли его бот,
+// проверяет вызывающий.
+func (s *UserService) SetLanguage(ctx context.Context, userID int, language string) (string, error) {
+	language = model.BaseLanguage(language)
+	if language == "" {
+		return "", fmt.Errorf("не указан язык")
+	}
+	if err := s.userRepo.UpdateLanguage(ctx, userID, language); err != nil {
+		return "", err
+	}
+	return language, nil
 }
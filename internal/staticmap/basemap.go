@@ -0,0 +1,276 @@
+package staticmap
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/draw"
+	"math"
+	"os"
+	"sync"
+)
+
+// defaultBasemapJSON — встроенная подложка: упрощенный контур Северной Осетии, главные реки,
+// дороги и города. Координаты приблизительные — подложка нужна, чтобы метки не висели в пустоте.
+//
+//go:embed basemap/north_ossetia.geojson
+var defaultBasemapJSON []byte
+
+// Basemap — векторная подложка из GeoJSON. Вид объекта задает свойство kind: у многоугольников
+// region (по умолчанию) или water, у линий river, road или любое другое (тонкая серая линия),
+// у точек — населенные пункты.
+type Basemap struct {
+	features []feature
+}
+
+type feature struct {
+	kind     string
+	polygons [][][]Point
+	lines    [][]Point
+	points   []Point
+	// описывающий прямоугольник в градусах, чтобы пропускать объекты вне карты
+	minLat, minLon, maxLat, maxLon float64
+}
+
+var (
+	defaultBasemap     *Basemap
+	defaultBasemapOnce sync.Once
+)
+
+// DefaultBasemap возвращает встроенную подложку.
+func DefaultBasemap() *Basemap {
+	defaultBasemapOnce.Do(func() {
+		b, err := ParseBasemap(defaultBasemapJSON)
+		if err != nil {
+			panic(fmt.Sprintf("встроенная подложка карты: %v", err))
+		}
+		defaultBasemap = b
+	})
+	return defaultBasemap
+}
+
+// LoadBasemap читает подложку из файла GeoJSON.
+func LoadBasemap(path string) (*Basemap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать подложку карты: %w", err)
+	}
+	b, err := ParseBasemap(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return b, nil
+}
+
+// ParseBasemap разбирает FeatureCollection GeoJSON. Поддерживаются Point, MultiPoint, LineString,
+// MultiLineString, Polygon и MultiPolygon; объекты других типов пропускаются.
+func ParseBasemap(data []byte) (*Basemap, error) {
+	var doc struct {
+		Type     string `json:"type"`
+		Features []struct {
+			Properties struct {
+				Kind string `json:"kind"`
+			} `json:"properties"`
+			Geometry *struct {
+				Type        string          `json:"type"`
+				Coordinates json.RawMessage `json:"coordinates"`
+			} `json:"geometry"`
+		} `json:"features"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("некорректный GeoJSON: %w", err)
+	}
+	if doc.Type != "FeatureCollection" {
+		return nil, fmt.Errorf("ожидается FeatureCollection, получено %q", doc.Type)
+	}
+	b := &Basemap{}
+	for i, f := range doc.Features {
+		if f.Geometry == nil {
+			continue
+		}
+		ft := feature{kind: f.Properties.Kind}
+		var err error
+		switch f.Geometry.Type {
+		case "Point":
+			var c [2]float64
+			if err = json.Unmarshal(f.Geometry.Coordinates, &c); err == nil {
+				ft.points = []Point{toPoint(c)}
+			}
+		case "MultiPoint":
+			var cs [][2]float64
+			if err = json.Unmarshal(f.Geometry.Coordinates, &cs); err == nil {
+				ft.points = toPoints(cs)
+			}
+		case "LineString":
+			var cs [][2]float64
+			if err = json.Unmarshal(f.Geometry.Coordinates, &cs); err == nil {
+				ft.lines = [][]Point{toPoints(cs)}
+			}
+		case "MultiLineString", "Polygon":
+			var cs [][][2]float64
+			if err = json.Unmarshal(f.Geometry.Coordinates, &cs); err == nil {
+				rings := [][]Point{}
+				for _, c := range cs {
+					rings = append(rings, toPoints(c))
+				}
+				if f.Geometry.Type == "Polygon" {
+					ft.polygons = [][][]Point{rings}
+				} else {
+					ft.lines = rings
+				}
+			}
+		case "MultiPolygon":
+			var cs [][][][2]float64
+			if err = json.Unmarshal(f.Geometry.Coordinates, &cs); err == nil {
+				for _, polygon := range cs {
+					rings := [][]Point{}
+					for _, c := range polygon {
+						rings = append(rings, toPoints(c))
+					}
+					ft.polygons = append(ft.polygons, rings)
+				}
+			}
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("объект %d: некорректные координаты %s: %w", i, f.Geometry.Type, err)
+		}
+		ft.bound()
+		b.features = append(b.features, ft)
+	}
+	return b, nil
+}
+
+// GeoJSON хранит координаты в порядке [долгота, широта].
+func toPoint(c [2]float64) Point {
+	return Point{Lat: c[1], Lon: c[0]}
+}
+
+func toPoints(cs [][2]float64) []Point {
+	points := make([]Point, 0, len(cs))
+	for _, c := range cs {
+		points = append(points, toPoint(c))
+	}
+	return points
+}
+
+func (f *feature) bound() {
+	f.minLat, f.minLon = math.Inf(1), math.Inf(1)
+	f.maxLat, f.maxLon = math.Inf(-1), math.Inf(-1)
+	add := func(p Point) {
+		f.minLat, f.maxLat = math.Min(f.minLat, p.Lat), math.Max(f.maxLat, p.Lat)
+		f.minLon, f.maxLon = math.Min(f.minLon, p.Lon), math.Max(f.maxLon, p.Lon)
+	}
+	for _, polygon := range f.polygons {
+		for _, ring := range polygon {
+			for _, p := range ring {
+				add(p)
+			}
+		}
+	}
+	for _, line := range f.lines {
+		for _, p := range line {
+			add(p)
+		}
+	}
+	for _, p := range f.points {
+		add(p)
+	}
+}
+
+// draw рисует подложку: фон, области, сетку широт и долгот, реки, дороги и населенные пункты.
+func (b *Basemap) draw(v view) *image.RGBA {
+	canvas := image.NewRGBA(image.Rect(0, 0, v.width, v.height))
+	draw.Draw(canvas, canvas.Bounds(), image.NewUniform(colorBackground), image.Point{}, draw.Src)
+
+	// объекты, которые заведомо не видны, не рисуются; запас — на толщину линий
+	topLeft := v.unproject(-padding*scale, -padding*scale)
+	bottomRight := v.unproject(float64(v.width+padding*scale), float64(v.height+padding*scale))
+	visible := []feature{}
+	for _, f := range b.features {
+		if f.maxLat >= bottomRight.Lat && f.minLat <= topLeft.Lat && f.maxLon >= topLeft.Lon && f.minLon <= bottomRight.Lon {
+			visible = append(visible, f)
+		}
+	}
+
+	for _, water := range []bool{false, true} {
+		for _, f := range visible {
+			if (f.kind == "water") != water {
+				continue
+			}
+			for _, polygon := range f.polygons {
+				rings := projectAll(v, polygon)
+				if water {
+					fillPolygon(canvas, rings, colorWater)
+					continue
+				}
+				fillPolygon(canvas, rings, colorLand)
+				for _, ring := range rings {
+					strokePolyline(canvas, append(ring, ring[0]), 1.5*scale, colorBorder)
+				}
+			}
+		}
+	}
+	drawGraticule(canvas, v, topLeft, bottomRight)
+	for _, f := range visible {
+		for _, line := range f.lines {
+			pts := projectAll(v, [][]Point{line})[0]
+			switch f.kind {
+			case "river":
+				strokePolyline(canvas, pts, 2.5*scale, colorWater)
+			case "road":
+				strokePolyline(canvas, pts, 4.5*scale, colorRoadCasing)
+				strokePolyline(canvas, pts, 3*scale, colorRoad)
+			default:
+				strokePolyline(canvas, pts, 1.5*scale, colorLine)
+			}
+		}
+	}
+	for _, f := range visible {
+		for _, p := range f.points {
+			x, y := v.project(p)
+			fillCircle(canvas, point{x, y}, 4*scale, colorWhite)
+			fillCircle(canvas, point{x, y}, 3*scale, colorCity)
+		}
+	}
+	return canvas
+}
+
+func projectAll(v view, rings [][]Point) [][]point {
+	out := make([][]point, 0, len(rings))
+	for _, ring := range rings {
+		pts := make([]point, 0, len(ring))
+		for _, p := range ring {
+			x, y := v.project(p)
+			pts = append(pts, point{x, y})
+		}
+		out = append(out, pts)
+	}
+	return out
+}
+
+// graticuleSteps — шаги сетки в градусах; выбирается наибольший, при котором на карте не меньше трех линий.
+var graticuleSteps = []float64{10, 5, 2, 1, 0.5, 0.2, 0.1, 0.05, 0.02, 0.01, 0.005, 0.002, 0.001}
+
+// drawGraticule рисует сетку широт и долгот, чтобы карта без объектов подложки не была пустой.
+func drawGraticule(img *image.RGBA, v view, topLeft, bottomRight Point) {
+	span := bottomRight.Lon - topLeft.Lon
+	step := graticuleSteps[len(graticuleSteps)-1]
+	for _, s := range graticuleSteps {
+		if span/s >= 3 {
+			step = s
+			break
+		}
+	}
+	w, h := float64(v.width), float64(v.height)
+	for lon := math.Ceil(topLeft.Lon/step) * step; lon <= bottomRight.Lon; lon += step {
+		x, _ := v.project(Point{Lon: lon})
+		strokePolyline(img, []point{{x, 0}, {x, h}}, scale, colorGraticule)
+	}
+	for lat := math.Ceil(bottomRight.Lat/step) * step; lat <= topLeft.Lat; lat += step {
+		_, y := v.project(Point{Lat: lat})
+		strokePolyline(img, []point{{0, y}, {w, y}}, scale, colorGraticule)
+	}
+}
@@ -0,0 +1,175 @@
+package staticmap
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// Цвета подложки, маршрута и меток.
+var (
+	colorBackground = color.RGBA{0xe4, 0xe0, 0xd8, 0xff}
+	colorLand       = color.RGBA{0xf5, 0xf3, 0xee, 0xff}
+	colorBorder     = color.RGBA{0xb0, 0xa8, 0xa0, 0xff}
+	colorWater      = color.RGBA{0xaa, 0xd3, 0xdf, 0xff}
+	colorRoad       = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	colorRoadCasing = color.RGBA{0xd2, 0xcb, 0xbe, 0xff}
+	colorLine       = color.RGBA{0xa8, 0xa0, 0x98, 0xff}
+	colorCity       = color.RGBA{0x8a, 0x81, 0x78, 0xff}
+	colorGraticule  = color.RGBA{0xd8, 0xd3, 0xca, 0xff}
+	colorRoute      = color.RGBA{0x2a, 0x6f, 0xdb, 0xff}
+	colorMarker     = color.RGBA{0xd6, 0x3a, 0x2f, 0xff}
+	colorWhite      = color.RGBA{0xff, 0xff, 0xff, 0xff}
+)
+
+// point — точка холста.
+type point struct{ x, y float64 }
+
+// fillPolygon закрашивает многоугольник из нескольких контуров по правилу чет-нечет
+// (внутренние контуры становятся дырами). Закрашиваются пиксели, центры которых внутри.
+func fillPolygon(img *image.RGBA, rings [][]point, c color.RGBA) {
+	b := img.Bounds()
+	minY, maxY := math.Inf(1), math.Inf(-1)
+	for _, ring := range rings {
+		for _, p := range ring {
+			minY, maxY = math.Min(minY, p.y), math.Max(maxY, p.y)
+		}
+	}
+	y0 := max(b.Min.Y, int(math.Floor(minY)))
+	y1 := min(b.Max.Y-1, int(math.Ceil(maxY)))
+	xs := []float64{}
+	for y := y0; y <= y1; y++ {
+		sy := float64(y) + 0.5
+		xs = xs[:0]
+		for _, ring := range rings {
+			for i := range ring {
+				p, q := ring[i], ring[(i+1)%len(ring)]
+				if (p.y <= sy && sy < q.y) || (q.y <= sy && sy < p.y) {
+					xs = append(xs, p.x+(sy-p.y)*(q.x-p.x)/(q.y-p.y))
+				}
+			}
+		}
+		sort.Float64s(xs)
+		for i := 0; i+1 < len(xs); i += 2 {
+			// центр пикселя x+0.5 должен лежать в [xs[i], xs[i+1])
+			from := max(b.Min.X, int(math.Ceil(xs[i]-0.5)))
+			to := min(b.Max.X, int(math.Ceil(xs[i+1]-0.5)))
+			for x := from; x < to; x++ {
+				img.SetRGBA(x, y, c)
+			}
+		}
+	}
+}
+
+// fillCircle закрашивает круг.
+func fillCircle(img *image.RGBA, center point, radius float64, c color.RGBA) {
+	b := img.Bounds()
+	y0 := max(b.Min.Y, int(math.Floor(center.y-radius)))
+	y1 := min(b.Max.Y-1, int(math.Ceil(center.y+radius)))
+	x0 := max(b.Min.X, int(math.Floor(center.x-radius)))
+	x1 := min(b.Max.X-1, int(math.Ceil(center.x+radius)))
+	for y := y0; y <= y1; y++ {
+		for x := x0; x <= x1; x++ {
+			dx, dy := float64(x)+0.5-center.x, float64(y)+0.5-center.y
+			if dx*dx+dy*dy <= radius*radius {
+				img.SetRGBA(x, y, c)
+			}
+		}
+	}
+}
+
+// strokePolyline рисует ломаную толщиной width со скругленными изгибами и концами.
+func strokePolyline(img *image.RGBA, pts []point, width float64, c color.RGBA) {
+	half := width / 2
+	for i, p := range pts {
+		fillCircle(img, p, half, c)
+		if i == 0 {
+			continue
+		}
+		q := pts[i-1]
+		dx, dy := p.x-q.x, p.y-q.y
+		length := math.Hypot(dx, dy)
+		if length == 0 {
+			continue
+		}
+		nx, ny := -dy/length*half, dx/length*half
+		fillPolygon(img, [][]point{{
+			{q.x + nx, q.y + ny}, {p.x + nx, p.y + ny}, {p.x - nx, p.y - ny}, {q.x - nx, q.y - ny},
+		}}, c)
+	}
+}
+
+// drawRoute рисует линию маршрута с белой обводкой.
+func drawRoute(img *image.RGBA, v view, path []Point) {
+	if len(path) < 2 {
+		return
+	}
+	pts := make([]point, 0, len(path))
+	for _, p := range path {
+		x, y := v.project(p)
+		pts = append(pts, point{x, y})
+	}
+	strokePolyline(img, pts, 7*scale, colorWhite)
+	strokePolyline(img, pts, 4*scale, colorRoute)
+}
+
+// drawMarkers рисует метки-булавки, острием в точке. Номер метки пишется на ее головке.
+func drawMarkers(img *image.RGBA, v view, markers []Marker) {
+	for _, mk := range markers {
+		x, y := v.project(mk.Point)
+		head := point{x, y - 20*scale}
+		// белая обводка, затем сама булавка
+		fillCircle(img, head, 13*scale, colorWhite)
+		fillPolygon(img, [][]point{{{x - 10*scale, y - 15*scale}, {x + 10*scale, y - 15*scale}, {x, y + 2*scale}}}, colorWhite)
+		fillCircle(img, head, 11*scale, colorMarker)
+		fillPolygon(img, [][]point{{{x - 8*scale, y - 15*scale}, {x + 8*scale, y - 15*scale}, {x, y}}}, colorMarker)
+		if mk.Label > 0 && mk.Label < 100 {
+			drawNumber(img, head, strconv.Itoa(mk.Label), colorWhite)
+		} else {
+			fillCircle(img, head, 4*scale, colorWhite)
+		}
+	}
+}
+
+// digits — цифры шрифта 3×5: каждая строка — три бита слева направо.
+var digits = [10][5]uint8{
+	{7, 5, 5, 5, 7}, // 0
+	{2, 6, 2, 2, 7}, // 1
+	{7, 1, 7, 4, 7}, // 2
+	{7, 1, 7, 1, 7}, // 3
+	{5, 5, 7, 1, 1}, // 4
+	{7, 4, 7, 1, 7}, // 5
+	{7, 4, 7, 5, 7}, // 6
+	{7, 1, 2, 2, 2}, // 7
+	{7, 5, 7, 5, 7}, // 8
+	{7, 5, 7, 1, 7}, // 9
+}
+
+// drawNumber пишет число цифрами digits с центром в точке center.
+func drawNumber(img *image.RGBA, center point, text string, c color.RGBA) {
+	const cell = 2 * scale // сторона «пикселя» шрифта на холсте
+	width := len(text)*3*cell + (len(text)-1)*cell
+	left := int(math.Round(center.x)) - width/2
+	top := int(math.Round(center.y)) - 5*cell/2
+	for i, ch := range text {
+		glyph := digits[ch-'0']
+		gx := left + i*4*cell
+		for row, bits := range glyph {
+			for col := 0; col < 3; col++ {
+				if bits&(4>>col) == 0 {
+					continue
+				}
+				for y := 0; y < cell; y++ {
+					for x := 0; x < cell; x++ {
+						px, py := gx+col*cell+x, top+row*cell+y
+						if image.Pt(px, py).In(img.Bounds()) {
+							img.SetRGBA(px, py, c)
+						}
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,244 @@
+// Package staticmap рисует статические карты в PNG без обращения к внешним сервисам: метки локаций
+// и линию маршрута поверх тайлов из локального кэша (каталог z/x/y.png), а если нужных тайлов нет —
+// поверх простой векторной подложки из GeoJSON.
+package staticmap
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"math"
+
+	"tourism/internal/imaging"
+)
+
+const (
+	tileSize = 256
+	// scale — во сколько раз крупнее рисуется карта перед уменьшением до нужного размера:
+	// уменьшение с усреднением сглаживает края линий и меток
+	scale = 2
+	// padding — отступ от меток до края карты в пикселях; сверху на него приходится булавка метки
+	padding = 40
+	// maxLatitude — предел широты проекции Меркатора
+	maxLatitude = 85.05112878
+	// basemapZoom — масштаб карты с одной точкой на векторной подложке: подложка упрощенная,
+	// и при более крупном масштабе вокруг метки ничего не видно
+	basemapZoom = 10
+)
+
+// Размеры карты по умолчанию и допустимые пределы.
+const (
+	DefaultWidth  = 600
+	DefaultHeight = 400
+	MinSize       = 100
+	MaxSize       = 1280
+)
+
+// ErrNoPoints возвращается, если на карте нечего показать.
+var ErrNoPoints = errors.New("на карте нет точек")
+
+// Point — географические координаты в градусах.
+type Point struct {
+	Lat float64
+	Lon float64
+}
+
+// Marker — метка на карте. Label — номер на метке (например, порядок точки маршрута); 0 — без номера.
+type Marker struct {
+	Point
+	Label int
+}
+
+// Map описывает карту: размер в пикселях, метки и линию маршрута. Масштаб и центр подбираются так,
+// чтобы поместились все точки.
+type Map struct {
+	Width   int
+	Height  int
+	Markers []Marker
+	Path    []Point
+}
+
+// Image — готовая карта. Attribution — подпись источника тайлов, которую нужно показать рядом с картой;
+// пустая для векторной подложки.
+type Image struct {
+	PNG         []byte
+	Attribution string
+}
+
+// Config — источники подложки карты.
+type Config struct {
+	TileDir     string // каталог тайлов {z}/{x}/{y}.png; пустой — только векторная подложка
+	Attribution string // подпись источника тайлов, например «© участники OpenStreetMap»
+	MinZoom     int
+	MaxZoom     int
+	// DefaultZoom — масштаб карты с одной точкой
+	DefaultZoom int
+}
+
+// DefaultConfig возвращает настройки по умолчанию: без тайлов, масштабы 2–16, одна точка — на масштабе 13.
+func DefaultConfig() Config {
+	return Config{MinZoom: 2, MaxZoom: 16, DefaultZoom: 13}
+}
+
+// Renderer рисует карты. Безопасен для одновременного использования.
+type Renderer struct {
+	cfg     Config
+	tiles   *tileSource
+	basemap *Basemap
+}
+
+// NewRenderer создает отрисовщик карт. basemap — векторная подложка на случай, когда тайлов нет;
+// nil — встроенная подложка (см. DefaultBasemap).
+func NewRenderer(cfg Config, basemap *Basemap) (*Renderer, error) {
+	if cfg.MinZoom < 0 || cfg.MaxZoom > 19 || cfg.MinZoom > cfg.MaxZoom ||
+		cfg.DefaultZoom < cfg.MinZoom || cfg.DefaultZoom > cfg.MaxZoom {
+		return nil, fmt.Errorf("некорректные масштабы карты: %d–%d, по умолчанию %d", cfg.MinZoom, cfg.MaxZoom, cfg.DefaultZoom)
+	}
+	if basemap == nil {
+		basemap = DefaultBasemap()
+	}
+	r := &Renderer{cfg: cfg, basemap: basemap}
+	if cfg.TileDir != "" {
+		tiles, err := newTileSource(cfg.TileDir)
+		if err != nil {
+			return nil, err
+		}
+		r.tiles = tiles
+	}
+	return r, nil
+}
+
+// Render рисует карту. Размеры вне MinSize–MaxSize заменяются ближайшими допустимыми, нулевые —
+// размерами по умолчанию. Отмена ctx прерывает чтение тайлов, и Render возвращает ctx.Err().
+func (r *Renderer) Render(ctx context.Context, m Map) (*Image, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	points := []Point{}
+	for _, mk := range m.Markers {
+		points = append(points, mk.Point)
+	}
+	points = append(points, m.Path...)
+	if len(points) == 0 {
+		return nil, ErrNoPoints
+	}
+	width, height := clampSize(m.Width, DefaultWidth), clampSize(m.Height, DefaultHeight)
+	zoom, single := r.fitZoom(points, width, height)
+
+	attribution := ""
+	var canvas *image.RGBA
+	// тайлов нужного масштаба может не оказаться в кэше: тогда карта рисуется мельче
+	// по тайлам соседних масштабов, а если нет и их — по векторной подложке
+	if r.tiles != nil {
+		for z := zoom; z >= max(zoom-2, r.cfg.MinZoom); z-- {
+			v := newView(points, z, width, height)
+			var err error
+			if canvas, err = r.tiles.draw(ctx, v); err != nil {
+				return nil, err
+			}
+			if canvas != nil {
+				zoom, attribution = z, r.cfg.Attribution
+				break
+			}
+		}
+	}
+	if canvas == nil && single {
+		zoom = min(zoom, max(basemapZoom, r.cfg.MinZoom))
+	}
+	v := newView(points, zoom, width, height)
+	if canvas == nil {
+		canvas = r.basemap.draw(v)
+	}
+	drawRoute(canvas, v, m.Path)
+	drawMarkers(canvas, v, m.Markers)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, imaging.Fit(canvas, max(width, height))); err != nil {
+		return nil, fmt.Errorf("не удалось закодировать карту: %w", err)
+	}
+	return &Image{PNG: buf.Bytes(), Attribution: attribution}, nil
+}
+
+// fitZoom выбирает наибольший масштаб, при котором все точки помещаются на карте с отступами.
+// single сообщает, что все точки совпадают и масштаб взят по умолчанию.
+func (r *Renderer) fitZoom(points []Point, width, height int) (zoom int, single bool) {
+	minX, minY, maxX, maxY := bounds(points, 0)
+	if maxX-minX < 1e-9 && maxY-minY < 1e-9 {
+		return r.cfg.DefaultZoom, true
+	}
+	for z := r.cfg.MaxZoom; z > r.cfg.MinZoom; z-- {
+		k := math.Exp2(float64(z))
+		if (maxX-minX)*k <= float64(width-2*padding) && (maxY-minY)*k <= float64(height-2*padding) {
+			return z, false
+		}
+	}
+	return r.cfg.MinZoom, false
+}
+
+func clampSize(v, def int) int {
+	if v == 0 {
+		return def
+	}
+	return min(max(v, MinSize), MaxSize)
+}
+
+// view — видимая часть карты: масштаб и левый верхний угол в пикселях мировой проекции.
+type view struct {
+	zoom          int
+	originX       float64
+	originY       float64
+	width, height int // размер холста (уже умноженный на scale)
+}
+
+// newView центрирует карту масштаба zoom на описывающем прямоугольнике точек.
+func newView(points []Point, zoom, width, height int) view {
+	minX, minY, maxX, maxY := bounds(points, zoom)
+	return view{
+		zoom:    zoom,
+		originX: (minX+maxX)/2 - float64(width)/2,
+		originY: (minY+maxY)/2 - float64(height)/2,
+		width:   width * scale,
+		height:  height * scale,
+	}
+}
+
+// project возвращает координаты точки на холсте.
+func (v view) project(p Point) (x, y float64) {
+	wx, wy := worldPixel(p, v.zoom)
+	return (wx - v.originX) * scale, (wy - v.originY) * scale
+}
+
+// unproject возвращает географические координаты точки холста.
+func (v view) unproject(x, y float64) Point {
+	n := tileSize * math.Exp2(float64(v.zoom))
+	wx, wy := x/scale+v.originX, y/scale+v.originY
+	lat := math.Atan(math.Sinh(math.Pi*(1-2*wy/n))) * 180 / math.Pi
+	return Point{Lat: lat, Lon: wx/n*360 - 180}
+}
+
+// worldPixel переводит координаты в пиксели проекции Web Mercator на масштабе zoom.
+func worldPixel(p Point, zoom int) (x, y float64) {
+	n := tileSize * math.Exp2(float64(zoom))
+	lat := math.Max(-maxLatitude, math.Min(maxLatitude, p.Lat)) * math.Pi / 180
+	x = (p.Lon + 180) / 360 * n
+	y = (1 - math.Log(math.Tan(lat)+1/math.Cos(lat))/math.Pi) / 2 * n
+	return x, y
+}
+
+// bounds возвращает описывающий прямоугольник точек в пикселях проекции на масштабе zoom.
+func bounds(points []Point, zoom int) (minX, minY, maxX, maxY float64) {
+	minX, minY = math.Inf(1), math.Inf(1)
+	maxX, maxY = math.Inf(-1), math.Inf(-1)
+	for _, p := range points {
+		x, y := worldPixel(p, zoom)
+		minX, maxX = math.Min(minX, x), math.Max(maxX, x)
+		minY, maxY = math.Min(minY, y), math.Max(maxY, y)
+	}
+	return minX, minY, maxX, maxY
+}
@@ -0,0 +1,346 @@
+package staticmap
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// Владикавказ и Цейское ущелье — точки на встроенной подложке.
+var (
+	vladikavkaz = Point{Lat: 43.02, Lon: 44.68}
+	tsei        = Point{Lat: 42.79, Lon: 43.9}
+)
+
+var colorTile = color.RGBA{0x20, 0x90, 0x40, 0xff}
+
+func newTestRenderer(t *testing.T, cfg Config, basemap *Basemap) *Renderer {
+	t.Helper()
+	r, err := NewRenderer(cfg, basemap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// fits сообщает, помещаются ли точки на карте размера width×height на масштабе zoom с отступами.
+func fits(points []Point, zoom, width, height int) bool {
+	minX, minY, maxX, maxY := bounds(points, zoom)
+	return maxX-minX <= float64(width-2*padding) && maxY-minY <= float64(height-2*padding)
+}
+
+func TestFitZoom(t *testing.T) {
+	r := newTestRenderer(t, DefaultConfig(), nil)
+	tests := []struct {
+		name   string
+		points []Point
+		width  int
+		height int
+	}{
+		{"две точки в республике", []Point{vladikavkaz, tsei}, 600, 400},
+		{"узкая карта", []Point{vladikavkaz, tsei}, 100, 400},
+		{"соседние дома", []Point{vladikavkaz, {Lat: 43.0201, Lon: 44.6801}}, 600, 400},
+		{"Москва и Владикавказ", []Point{vladikavkaz, {Lat: 55.75, Lon: 37.62}}, 600, 400},
+		{"через полмира", []Point{{Lat: -60, Lon: -170}, {Lat: 70, Lon: 170}}, 600, 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			zoom, single := r.fitZoom(tt.points, tt.width, tt.height)
+			if single {
+				t.Fatal("разные точки приняты за одну")
+			}
+			if zoom < cfg.MinZoom || zoom > cfg.MaxZoom {
+				t.Fatalf("масштаб %d вне %d–%d", zoom, cfg.MinZoom, cfg.MaxZoom)
+			}
+			// выбран наибольший масштаб, при котором точки помещаются (или крайний допустимый)
+			if zoom > cfg.MinZoom && !fits(tt.points, zoom, tt.width, tt.height) {
+				t.Errorf("на масштабе %d точки не помещаются", zoom)
+			}
+			if zoom < cfg.MaxZoom && fits(tt.points, zoom+1, tt.width, tt.height) {
+				t.Errorf("масштаб %d, но точки помещаются и на %d", zoom, zoom+1)
+			}
+		})
+	}
+
+	zoom, single := r.fitZoom([]Point{vladikavkaz, vladikavkaz}, 600, 400)
+	if !single || zoom != DefaultConfig().DefaultZoom {
+		t.Errorf("совпадающие точки: масштаб %d, single %v", zoom, single)
+	}
+	// масштаб ограничен настройками
+	narrow := newTestRenderer(t, Config{MinZoom: 5, MaxZoom: 8, DefaultZoom: 6}, nil)
+	if zoom, _ := narrow.fitZoom([]Point{vladikavkaz, {Lat: 43.0201, Lon: 44.6801}}, 600, 400); zoom != 8 {
+		t.Errorf("близкие точки: масштаб %d, ожидался MaxZoom 8", zoom)
+	}
+	if zoom, _ := narrow.fitZoom([]Point{{Lat: -60, Lon: -170}, {Lat: 70, Lon: 170}}, 600, 400); zoom != 5 {
+		t.Errorf("далекие точки: масштаб %d, ожидался MinZoom 5", zoom)
+	}
+}
+
+func TestNewRendererValidatesZoom(t *testing.T) {
+	for _, cfg := range []Config{
+		{MinZoom: -1, MaxZoom: 16, DefaultZoom: 13},
+		{MinZoom: 2, MaxZoom: 20, DefaultZoom: 13},
+		{MinZoom: 10, MaxZoom: 5, DefaultZoom: 7},
+		{MinZoom: 2, MaxZoom: 16, DefaultZoom: 17},
+	} {
+		if _, err := NewRenderer(cfg, nil); err == nil {
+			t.Errorf("настройки %+v приняты", cfg)
+		}
+	}
+	cfg := DefaultConfig()
+	cfg.TileDir = filepath.Join(t.TempDir(), "missing")
+	if _, err := NewRenderer(cfg, nil); err == nil {
+		t.Error("принят несуществующий каталог тайлов")
+	}
+}
+
+// decode разбирает PNG карты и проверяет его размер.
+func decode(t *testing.T, img *Image, width, height int) *image.RGBA {
+	t.Helper()
+	decoded, err := png.Decode(bytes.NewReader(img.PNG))
+	if err != nil {
+		t.Fatalf("карта не в PNG: %v", err)
+	}
+	if b := decoded.Bounds(); b.Dx() != width || b.Dy() != height {
+		t.Fatalf("размер карты %dx%d, ожидался %dx%d", b.Dx(), b.Dy(), width, height)
+	}
+	rgba := image.NewRGBA(decoded.Bounds())
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			rgba.Set(x, y, decoded.At(x, y))
+		}
+	}
+	return rgba
+}
+
+// near сообщает, что цвета отличаются не больше чем на 8 по каждому каналу (уменьшение усредняет пиксели).
+func near(a, b color.RGBA) bool {
+	d := func(x, y uint8) bool { return math.Abs(float64(x)-float64(y)) <= 8 }
+	return d(a.R, b.R) && d(a.G, b.G) && d(a.B, b.B)
+}
+
+// countColor возвращает число пикселей карты, близких к цвету c.
+func countColor(img *image.RGBA, c color.RGBA) int {
+	n := 0
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if near(img.RGBAAt(x, y), c) {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func TestRenderBasemap(t *testing.T) {
+	r := newTestRenderer(t, DefaultConfig(), nil)
+	ctx := context.Background()
+
+	img, err := r.Render(ctx, Map{
+		Markers: []Marker{{Point: vladikavkaz, Label: 1}, {Point: tsei, Label: 2}},
+		Path:    []Point{vladikavkaz, tsei},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Attribution != "" {
+		t.Errorf("у векторной подложки подпись %q", img.Attribution)
+	}
+	canvas := decode(t, img, DefaultWidth, DefaultHeight)
+	// на карте видны суша из встроенного контура, линия маршрута и метки
+	for name, c := range map[string]color.RGBA{"суша": colorLand, "маршрут": colorRoute, "метки": colorMarker} {
+		if countColor(canvas, c) == 0 {
+			t.Errorf("на карте нет цвета %s", name)
+		}
+	}
+
+	// размеры приводятся к допустимым
+	for _, tt := range []struct{ w, h, wantW, wantH int }{
+		{200, 150, 200, 150},
+		{10, 10, MinSize, MinSize},
+		{5000, 100, MaxSize, MinSize},
+	} {
+		img, err := r.Render(ctx, Map{Width: tt.w, Height: tt.h, Markers: []Marker{{Point: tsei}}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		decode(t, img, tt.wantW, tt.wantH)
+	}
+
+	if _, err := r.Render(ctx, Map{}); !errors.Is(err, ErrNoPoints) {
+		t.Errorf("пустая карта: %v, ожидалось ErrNoPoints", err)
+	}
+}
+
+func TestRenderCustomBasemap(t *testing.T) {
+	// подложка из одного озера вокруг метки: вся видимая часть карты — вода
+	basemap, err := ParseBasemap([]byte(`{"type": "FeatureCollection", "features": [
+		{"type": "Feature", "properties": {"kind": "water"},
+		 "geometry": {"type": "Polygon", "coordinates": [[[40, 40], [50, 40], [50, 46], [40, 46], [40, 40]]]}},
+		{"type": "Feature", "properties": {}, "geometry": {"type": "GeometryCollection", "geometries": []}},
+		{"type": "Feature", "properties": {}, "geometry": null}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newTestRenderer(t, DefaultConfig(), basemap)
+	img, err := r.Render(context.Background(), Map{Width: 200, Height: 200, Markers: []Marker{{Point: vladikavkaz}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	canvas := decode(t, img, 200, 200)
+	if water := countColor(canvas, colorWater); water < 200*200*3/4 {
+		t.Errorf("воды на карте %d пикселей из %d", water, 200*200)
+	}
+	if countColor(canvas, colorLand) != 0 {
+		t.Error("на карте суша встроенной подложки вместо переданной")
+	}
+
+	for name, data := range map[string]string{
+		"не JSON":              `{`,
+		"не FeatureCollection": `{"type": "Feature"}`,
+		"некорректные координаты": `{"type": "FeatureCollection", "features": [
+			{"type": "Feature", "geometry": {"type": "Point", "coordinates": "43,44"}}]}`,
+	} {
+		if _, err := ParseBasemap([]byte(data)); err == nil {
+			t.Errorf("%s: подложка принята", name)
+		}
+	}
+	if _, err := LoadBasemap(filepath.Join(t.TempDir(), "missing.geojson")); err == nil {
+		t.Error("принят несуществующий файл подложки")
+	}
+}
+
+// writeTiles кладет в dir одноцветные тайлы, покрывающие карту m на масштабе zoom.
+func writeTiles(t *testing.T, dir string, m Map, zoom int) {
+	t.Helper()
+	points := []Point{}
+	for _, mk := range m.Markers {
+		points = append(points, mk.Point)
+	}
+	v := newView(points, zoom, clampSize(m.Width, DefaultWidth), clampSize(m.Height, DefaultHeight))
+	tile := image.NewRGBA(image.Rect(0, 0, tileSize, tileSize))
+	for i := 0; i < len(tile.Pix); i += 4 {
+		tile.Pix[i], tile.Pix[i+1], tile.Pix[i+2], tile.Pix[i+3] = colorTile.R, colorTile.G, colorTile.B, colorTile.A
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, tile); err != nil {
+		t.Fatal(err)
+	}
+	x0, y0 := int(math.Floor(v.originX/tileSize)), int(math.Floor(v.originY/tileSize))
+	x1 := int(math.Floor((v.originX + float64(v.width/scale) - 1) / tileSize))
+	y1 := int(math.Floor((v.originY + float64(v.height/scale) - 1) / tileSize))
+	for x := x0; x <= x1; x++ {
+		for y := y0; y <= y1; y++ {
+			path := filepath.Join(dir, strconv.Itoa(zoom), strconv.Itoa(x), strconv.Itoa(y)+".png")
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestRenderTiles(t *testing.T) {
+	m := Map{Width: 300, Height: 200, Markers: []Marker{{Point: vladikavkaz}}}
+	const attribution = "© OpenStreetMap contributors"
+	renderer := func(t *testing.T, dir string) *Renderer {
+		cfg := DefaultConfig()
+		cfg.TileDir, cfg.Attribution = dir, attribution
+		return newTestRenderer(t, cfg, nil)
+	}
+	// isTiles сообщает, нарисована ли карта по тестовым тайлам
+	isTiles := func(t *testing.T, img *Image) bool {
+		canvas := decode(t, img, 300, 200)
+		return near(canvas.RGBAAt(2, 2), colorTile)
+	}
+
+	t.Run("тайлы нужного масштаба", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTiles(t, dir, m, DefaultConfig().DefaultZoom)
+		img, err := renderer(t, dir).Render(context.Background(), m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !isTiles(t, img) || img.Attribution != attribution {
+			t.Errorf("карта не по тайлам, подпись %q", img.Attribution)
+		}
+	})
+	t.Run("тайлы соседнего масштаба", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTiles(t, dir, m, DefaultConfig().DefaultZoom-2)
+		img, err := renderer(t, dir).Render(context.Background(), m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !isTiles(t, img) || img.Attribution != attribution {
+			t.Errorf("карта не по тайлам мельче на два масштаба, подпись %q", img.Attribution)
+		}
+	})
+	t.Run("слишком мелкие тайлы", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTiles(t, dir, m, DefaultConfig().DefaultZoom-3)
+		img, err := renderer(t, dir).Render(context.Background(), m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if isTiles(t, img) || img.Attribution != "" {
+			t.Errorf("карта по тайлам мельче на три масштаба, подпись %q", img.Attribution)
+		}
+	})
+	t.Run("тайлы не все", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTiles(t, dir, m, DefaultConfig().DefaultZoom)
+		// один тайл поврежден: на недорисованной карте остались бы дыры, поэтому используется подложка
+		var damaged string
+		filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+			if damaged == "" && err == nil && !d.IsDir() {
+				damaged = path
+			}
+			return nil
+		})
+		if err := os.WriteFile(damaged, []byte("not a png"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		img, err := renderer(t, dir).Render(context.Background(), m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if isTiles(t, img) || img.Attribution != "" {
+			t.Errorf("карта по неполному набору тайлов, подпись %q", img.Attribution)
+		}
+	})
+	t.Run("пустой каталог", func(t *testing.T) {
+		img, err := renderer(t, t.TempDir()).Render(context.Background(), m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if isTiles(t, img) || img.Attribution != "" {
+			t.Errorf("подпись %q без тайлов", img.Attribution)
+		}
+	})
+	t.Run("отмена", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTiles(t, dir, m, DefaultConfig().DefaultZoom)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := renderer(t, dir).Render(ctx, m); !errors.Is(err, context.Canceled) {
+			t.Errorf("ошибка %v, ожидалось context.Canceled", err)
+		}
+		if _, err := renderer(t, "").Render(ctx, m); !errors.Is(err, context.Canceled) {
+			t.Errorf("без тайлов: ошибка %v, ожидалось context.Canceled", err)
+		}
+	})
+}
@@ -0,0 +1,100 @@
+package staticmap
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/jpeg" // тайлы в JPEG
+	_ "image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// tileSource читает тайлы из каталога в раскладке {z}/{x}/{y}.png (или .jpg), как у кэшей тайлов
+// OpenStreetMap. Тайлы в каталог кладутся заранее: сама карта в сеть не ходит.
+type tileSource struct {
+	dir string
+}
+
+func newTileSource(dir string) (*tileSource, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("каталог тайлов недоступен: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s не каталог", dir)
+	}
+	return &tileSource{dir: dir}, nil
+}
+
+// draw собирает холст из тайлов. Если хотя бы одного тайла нет или он не читается, возвращает nil
+// без ошибки; ошибка возвращается только при отмене ctx.
+func (s *tileSource) draw(ctx context.Context, v view) (*image.RGBA, error) {
+	n := 1 << v.zoom
+	x0 := int(math.Floor(v.originX / tileSize))
+	y0 := int(math.Floor(v.originY / tileSize))
+	x1 := int(math.Floor((v.originX + float64(v.width/scale) - 1) / tileSize))
+	y1 := int(math.Floor((v.originY + float64(v.height/scale) - 1) / tileSize))
+
+	canvas := image.NewRGBA(image.Rect(0, 0, v.width, v.height))
+	draw.Draw(canvas, canvas.Bounds(), image.NewUniform(colorBackground), image.Point{}, draw.Src)
+	for ty := y0; ty <= y1; ty++ {
+		if ty < 0 || ty >= n {
+			continue // за полюсами тайлов нет
+		}
+		for tx := x0; tx <= x1; tx++ {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			tile := s.load(v.zoom, ((tx%n)+n)%n, ty)
+			if tile == nil {
+				return nil, nil
+			}
+			left := int(math.Round((float64(tx*tileSize) - v.originX) * scale))
+			top := int(math.Round((float64(ty*tileSize) - v.originY) * scale))
+			blitScaled(canvas, tile, left, top)
+		}
+	}
+	return canvas, nil
+}
+
+// load читает тайл; nil, если его нет в каталоге.
+func (s *tileSource) load(z, x, y int) *image.RGBA {
+	base := filepath.Join(s.dir, strconv.Itoa(z), strconv.Itoa(x), strconv.Itoa(y))
+	for _, ext := range []string{".png", ".jpg", ".jpeg"} {
+		f, err := os.Open(base + ext)
+		if err != nil {
+			continue
+		}
+		img, _, err := image.Decode(f)
+		f.Close()
+		if err != nil {
+			return nil
+		}
+		rgba := image.NewRGBA(image.Rect(0, 0, tileSize, tileSize))
+		draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+		return rgba
+	}
+	return nil
+}
+
+// blitScaled рисует тайл на холсте, увеличивая каждый пиксель до квадрата scale×scale.
+func blitScaled(dst *image.RGBA, tile *image.RGBA, left, top int) {
+	b := dst.Bounds()
+	for y := 0; y < tileSize*scale; y++ {
+		dy := top + y
+		if dy < b.Min.Y || dy >= b.Max.Y {
+			continue
+		}
+		for x := 0; x < tileSize*scale; x++ {
+			dx := left + x
+			if dx < b.Min.X || dx >= b.Max.X {
+				continue
+			}
+			dst.SetRGBA(dx, dy, tile.RGBAAt(x/scale, y/scale))
+		}
+	}
+}
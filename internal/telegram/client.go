@@ -0,0 +1,43 @@
+// Package telegram описывает узкий интерфейс Telegram Bot API, от которого зависят боты,
+// чтобы их сценарии можно было запускать против локального сервера вместо настоящего Telegram.
+package telegram
+
+import (
+	"fmt"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Sender отправляет запросы к Bot API: сообщения, альбомы, ответы на нажатия кнопок.
+type Sender interface {
+	Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+	Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
+	SendMediaGroup(config tgbotapi.MediaGroupConfig) ([]tgbotapi.Message, error)
+}
+
+// Updates поставляет обновления от Telegram (long polling).
+type Updates interface {
+	GetUpdatesChan(config tgbotapi.UpdateConfig) tgbotapi.UpdatesChannel
+	StopReceivingUpdates()
+}
+
+// Client — все, что боту нужно от Telegram. Реализуется *tgbotapi.BotAPI.
+type Client interface {
+	Sender
+	Updates
+}
+
+var _ Client = (*tgbotapi.BotAPI)(nil)
+
+// New подключается к Bot API. endpoint — шаблон адреса методов вида "http://host/bot%s/%s";
+// пустой endpoint означает api.telegram.org.
+func New(token, endpoint string) (*tgbotapi.BotAPI, error) {
+	if endpoint == "" {
+		endpoint = tgbotapi.APIEndpoint
+	}
+	api, err := tgbotapi.NewBotAPIWithAPIEndpoint(token, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось подключиться к Bot API: %w", err)
+	}
+	return api, nil
+}
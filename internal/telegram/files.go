@@ -0,0 +1,73 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// MaxDownloadSize — наибольший файл, который Bot API отдает ботам (20 МБ).
+const MaxDownloadSize = 20 << 20
+
+// Downloader скачивает файлы, присланные боту, по их FileID: getFile возвращает путь,
+// по которому файл доступен на сервере файлов Bot API.
+type Downloader struct {
+	api          Requester
+	token        string
+	fileEndpoint string
+	client       *http.Client
+}
+
+// NewDownloader создает загрузчик файлов. endpoint — тот же шаблон адреса методов, что и у New;
+// адрес файлов получается из него заменой "/bot%s/%s" на "/file/bot%s/%s".
+func NewDownloader(api Requester, token, endpoint string) *Downloader {
+	fileEndpoint := tgbotapi.FileEndpoint
+	if endpoint != "" {
+		fileEndpoint = strings.TrimSuffix(endpoint, "/bot%s/%s") + "/file/bot%s/%s"
+	}
+	return &Downloader{api: api, token: token, fileEndpoint: fileEndpoint, client: &http.Client{Timeout: time.Minute}}
+}
+
+// Download скачивает файл fileID целиком.
+func (d *Downloader) Download(ctx context.Context, fileID string) ([]byte, error) {
+	resp, err := d.api.MakeRequest("getFile", tgbotapi.Params{"file_id": fileID})
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить файл %s: %w", fileID, err)
+	}
+	var file tgbotapi.File
+	if err := json.Unmarshal(resp.Result, &file); err != nil {
+		return nil, fmt.Errorf("некорректный ответ getFile: %w", err)
+	}
+	if file.FilePath == "" {
+		return nil, fmt.Errorf("Bot API не вернул путь к файлу %s", fileID)
+	}
+	if file.FileSize > MaxDownloadSize {
+		return nil, fmt.Errorf("файл %s слишком большой: %d байт", fileID, file.FileSize)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(d.fileEndpoint, d.token, file.FilePath), nil)
+	if err != nil {
+		return nil, fmt.Errorf("некорректный адрес файла: %w", err)
+	}
+	httpResp, err := d.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось скачать файл %s: %w", fileID, err)
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("не удалось скачать файл %s: %s", fileID, httpResp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(httpResp.Body, MaxDownloadSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("не удалось скачать файл %s: %w", fileID, err)
+	}
+	if len(data) > MaxDownloadSize {
+		return nil, fmt.Errorf("файл %s слишком большой", fileID)
+	}
+	return data, nil
+}
@@ -0,0 +1,78 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"tourism/internal/metrics"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// API — клиент Bot API целиком: отправка, получение обновлений и произвольные методы.
+// Реализуется *tgbotapi.BotAPI и Instrumented.
+type API interface {
+	Client
+	Requester
+}
+
+var _ API = (*tgbotapi.BotAPI)(nil)
+
+// Instrumented считает запросы к Bot API и их ошибки (метрики TelegramRequests и TelegramSendErrors).
+// Ошибки не логируются: это делают вызывающие, у которых есть контекст обновления.
+type Instrumented struct {
+	API
+}
+
+var _ API = (*Instrumented)(nil)
+
+// Instrument оборачивает клиент api сбором метрик.
+func Instrument(api API) *Instrumented {
+	return &Instrumented{API: api}
+}
+
+func (i *Instrumented) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	msg, err := i.API.Send(c)
+	observe(methodName(c), err)
+	return msg, err
+}
+
+func (i *Instrumented) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	resp, err := i.API.Request(c)
+	observe(methodName(c), err)
+	return resp, err
+}
+
+func (i *Instrumented) SendMediaGroup(config tgbotapi.MediaGroupConfig) ([]tgbotapi.Message, error) {
+	msgs, err := i.API.SendMediaGroup(config)
+	observe(methodName(config), err)
+	return msgs, err
+}
+
+func (i *Instrumented) MakeRequest(endpoint string, params tgbotapi.Params) (*tgbotapi.APIResponse, error) {
+	resp, err := i.API.MakeRequest(endpoint, params)
+	observe(endpoint, err)
+	return resp, err
+}
+
+// methodName возвращает имя типа запроса без пакета и суффикса Config: "Message", "Photo", "Callback".
+func methodName(c tgbotapi.Chattable) string {
+	name := fmt.Sprintf("%T", c)
+	name = name[strings.LastIndex(name, ".")+1:]
+	return strings.TrimSuffix(name, "Config")
+}
+
+func observe(method string, err error) {
+	metrics.TelegramRequests.WithLabelValues(method).Inc()
+	if err == nil {
+		return
+	}
+	code := "network"
+	var apiErr *tgbotapi.Error
+	if errors.As(err, &apiErr) {
+		code = strconv.Itoa(apiErr.Code)
+	}
+	metrics.TelegramSendErrors.WithLabelValues(method, code).Inc()
+}
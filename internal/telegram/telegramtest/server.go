@@ -0,0 +1,495 @@
+// Package telegramtest содержит локальный сервер Telegram Bot API для проверки сценариев ботов офлайн.
+// Бот подключается к нему через tgbotapi.NewBotAPIWithAPIEndpoint(token, server.Endpoint()),
+// сервер записывает все запросы бота и отдает через getUpdates обновления, добавленные проверкой.
+package telegramtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf16"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Call — запрос бота к Bot API, записанный сервером.
+type Call struct {
+	Method    string
+	ChatID    int64
+	Text      string // text или caption
+	MessageID int    // ID сообщения, которое сервер вернул боту (0 для запросов без сообщения)
+	Params    url.Values
+}
+
+// Buttons возвращает данные inline-кнопок из reply_markup запроса.
+func (c Call) Buttons() []string {
+	var markup tgbotapi.InlineKeyboardMarkup
+	if err := json.Unmarshal([]byte(c.Params.Get("reply_markup")), &markup); err != nil {
+		return nil
+	}
+	data := []string{}
+	for _, row := range markup.InlineKeyboard {
+		for _, b := range row {
+			if b.CallbackData != nil {
+				data = append(data, *b.CallbackData)
+			}
+		}
+	}
+	return data
+}
+
+// failure — ошибка, которую сервер вернет на следующий вызов метода.
+type failure struct {
+	code        int
+	description string
+	retryAfter  int
+}
+
+// Server — поддельный Bot API. Нулевое значение не используется, создавайте через NewServer.
+type Server struct {
+	Token string
+	Bot   tgbotapi.User // что бот получит в ответ на getMe
+
+	srv  *httptest.Server
+	done chan struct{}
+
+	mu            sync.Mutex
+	changed       chan struct{} // закрывается и пересоздается при каждом новом обновлении или запросе
+	calls         []Call
+	updates       []tgbotapi.Update
+	nextUpdateID  int
+	nextMessageID int
+	failures      map[string][]failure
+	webhook       string
+	webhookSecret string
+	files         map[string][]byte // содержимое файлов для getFile по FileID
+}
+
+// NewServer запускает сервер на свободном локальном порту.
+func NewServer(token string) *Server {
+	s := &Server{
+		Token:        token,
+		Bot:          tgbotapi.User{ID: 1, IsBot: true, FirstName: "Test Bot", UserName: "test_bot"},
+		done:         make(chan struct{}),
+		changed:      make(chan struct{}),
+		nextUpdateID: 1,
+		failures:     make(map[string][]failure),
+		files:        make(map[string][]byte),
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// Endpoint возвращает шаблон адреса методов для tgbotapi.NewBotAPIWithAPIEndpoint.
+func (s *Server) Endpoint() string {
+	return s.srv.URL + "/bot%s/%s"
+}
+
+// URL возвращает базовый адрес сервера.
+func (s *Server) URL() string {
+	return s.srv.URL
+}
+
+// NewBot создает клиента tgbotapi, подключенного к серверу.
+func (s *Server) NewBot() (*tgbotapi.BotAPI, error) {
+	return tgbotapi.NewBotAPIWithAPIEndpoint(s.Token, s.Endpoint())
+}
+
+// Close останавливает сервер и прерывает ожидающие getUpdates.
+func (s *Server) Close() {
+	close(s.done)
+	s.srv.Close()
+}
+
+// AddFile задает содержимое файла fileID: getFile вернет для него путь, по которому бот скачает data.
+func (s *Server) AddFile(fileID string, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[fileID] = data
+}
+
+// Inject добавляет обновление в очередь getUpdates и присваивает ему UpdateID.
+func (s *Server) Inject(u tgbotapi.Update) tgbotapi.Update {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u.UpdateID = s.nextUpdateID
+	s.nextUpdateID++
+	s.updates = append(s.updates, u)
+	s.notifyLocked()
+	return u
+}
+
+// SendText имитирует текстовое сообщение пользователя боту в личном чате.
+// Текст, начинающийся с "/", размечается как команда.
+func (s *Server) SendText(from tgbotapi.User, text string) tgbotapi.Update {
+	msg := s.userMessage(from)
+	msg.Text = text
+	if strings.HasPrefix(text, "/") {
+		command := strings.SplitN(text, " ", 2)[0]
+		msg.Entities = []tgbotapi.MessageEntity{{
+			Type:   "bot_command",
+			Offset: 0,
+			Length: len(utf16.Encode([]rune(command))),
+		}}
+	}
+	return s.Inject(tgbotapi.Update{Message: msg})
+}
+
+// SendPhoto имитирует фото с подписью от пользователя.
+func (s *Server) SendPhoto(from tgbotapi.User, fileID, caption string) tgbotapi.Update {
+	msg := s.userMessage(from)
+	msg.Caption = caption
+	msg.Photo = []tgbotapi.PhotoSize{{FileID: fileID, FileUniqueID: fileID, Width: 1280, Height: 960}}
+	return s.Inject(tgbotapi.Update{Message: msg})
+}
+
+// Press имитирует нажатие inline-кнопки с данными data под сообщением бота messageID.
+func (s *Server) Press(from tgbotapi.User, messageID int, data string) tgbotapi.Update {
+	s.mu.Lock()
+	id := strconv.Itoa(s.nextUpdateID)
+	s.mu.Unlock()
+	cq := &tgbotapi.CallbackQuery{
+		ID:   "cq" + id,
+		From: &from,
+		Message: &tgbotapi.Message{
+			MessageID: messageID,
+			From:      &s.Bot,
+			Chat:      &tgbotapi.Chat{ID: from.ID, Type: "private", FirstName: from.FirstName},
+			Date:      int(time.Now().Unix()),
+		},
+		Data: data,
+	}
+	return s.Inject(tgbotapi.Update{CallbackQuery: cq})
+}
+
+func (s *Server) userMessage(from tgbotapi.User) *tgbotapi.Message {
+	s.mu.Lock()
+	s.nextMessageID++
+	id := s.nextMessageID
+	s.mu.Unlock()
+	return &tgbotapi.Message{
+		MessageID: id,
+		From:      &from,
+		Chat:      &tgbotapi.Chat{ID: from.ID, Type: "private", FirstName: from.FirstName, UserName: from.UserName},
+		Date:      int(time.Now().Unix()),
+	}
+}
+
+// Fail заставляет сервер ответить ошибкой на следующий вызов метода (например, 429 с retryAfter или 403).
+func (s *Server) Fail(method string, code int, description string, retryAfter int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[method] = append(s.failures[method], failure{code: code, description: description, retryAfter: retryAfter})
+}
+
+// Calls возвращает копию всех записанных запросов бота.
+func (s *Server) Calls() []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Call(nil), s.calls...)
+}
+
+// CallsTo возвращает запросы бота в указанный чат.
+func (s *Server) CallsTo(chatID int64) []Call {
+	calls := []Call{}
+	for _, c := range s.Calls() {
+		if c.ChatID == chatID {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// Reset очищает записанные запросы и запланированные ошибки.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = nil
+	s.failures = make(map[string][]failure)
+}
+
+// Pending возвращает число обновлений, которые бот еще не подтвердил.
+func (s *Server) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.updates)
+}
+
+// Webhook возвращает адрес, установленный ботом через setWebhook (пустая строка — вебхук не задан).
+func (s *Server) Webhook() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.webhook
+}
+
+// WebhookSecret возвращает секрет, переданный ботом в setWebhook (secret_token).
+func (s *Server) WebhookSecret() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.webhookSecret
+}
+
+// WaitCall ждет запрос, удовлетворяющий match, и возвращает первый такой запрос.
+func (s *Server) WaitCall(timeout time.Duration, match func(Call) bool) (Call, error) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	for {
+		s.mu.Lock()
+		for _, c := range s.calls {
+			if match(c) {
+				s.mu.Unlock()
+				return c, nil
+			}
+		}
+		changed := s.changed
+		s.mu.Unlock()
+		select {
+		case <-changed:
+		case <-deadline.C:
+			return Call{}, fmt.Errorf("запрос не получен за %v", timeout)
+		case <-s.done:
+			return Call{}, fmt.Errorf("сервер остановлен")
+		}
+	}
+}
+
+// WaitText ждет сообщение бота в чат chatID, текст которого содержит substr.
+func (s *Server) WaitText(chatID int64, substr string, timeout time.Duration) (Call, error) {
+	c, err := s.WaitCall(timeout, func(c Call) bool {
+		return c.ChatID == chatID && strings.Contains(c.Text, substr)
+	})
+	if err != nil {
+		return c, fmt.Errorf("сообщение с %q в чат %d: %w", substr, chatID, err)
+	}
+	return c, nil
+}
+
+func (s *Server) notifyLocked() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// ответ Bot API
+type apiResponse struct {
+	Ok          bool                         `json:"ok"`
+	Result      interface{}                  `json:"result,omitempty"`
+	ErrorCode   int                          `json:"error_code,omitempty"`
+	Description string                       `json:"description,omitempty"`
+	Parameters  *tgbotapi.ResponseParameters `json:"parameters,omitempty"`
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	if filePath, ok := strings.CutPrefix(path, "file/bot"+s.Token+"/"); ok {
+		s.serveFile(w, filePath)
+		return
+	}
+	prefix := "bot" + s.Token + "/"
+	if !strings.HasPrefix(path, prefix) {
+		writeJSON(w, http.StatusUnauthorized, apiResponse{ErrorCode: http.StatusUnauthorized, Description: "Unauthorized"})
+		return
+	}
+	method := strings.TrimPrefix(path, prefix)
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			writeJSON(w, http.StatusBadRequest, apiResponse{ErrorCode: http.StatusBadRequest, Description: err.Error()})
+			return
+		}
+	} else if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, apiResponse{ErrorCode: http.StatusBadRequest, Description: err.Error()})
+		return
+	}
+	params := url.Values{}
+	for k, v := range r.Form {
+		params[k] = v
+	}
+	if r.MultipartForm != nil {
+		for field, files := range r.MultipartForm.File {
+			for _, f := range files {
+				params.Add(field, f.Filename)
+			}
+		}
+	}
+
+	switch method {
+	case "getMe":
+		writeJSON(w, http.StatusOK, apiResponse{Ok: true, Result: s.Bot})
+		return
+	case "getUpdates":
+		s.getUpdates(w, r, params)
+		return
+	}
+
+	result, f := s.record(method, params)
+	if f != nil {
+		resp := apiResponse{ErrorCode: f.code, Description: f.description}
+		if f.retryAfter > 0 {
+			resp.Parameters = &tgbotapi.ResponseParameters{RetryAfter: f.retryAfter}
+		}
+		writeJSON(w, f.code, resp)
+		return
+	}
+	writeJSON(w, http.StatusOK, apiResponse{Ok: true, Result: result})
+}
+
+// record записывает запрос и формирует результат метода.
+func (s *Server) record(method string, params url.Values) (interface{}, *failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.notifyLocked()
+
+	chatID, _ := strconv.ParseInt(params.Get("chat_id"), 10, 64)
+	call := Call{Method: method, ChatID: chatID, Text: params.Get("text"), Params: params}
+	if call.Text == "" {
+		call.Text = params.Get("caption")
+	}
+	if queued := s.failures[method]; len(queued) > 0 {
+		s.failures[method] = queued[1:]
+		s.calls = append(s.calls, call)
+		return nil, &queued[0]
+	}
+
+	var result interface{} = true
+	switch method {
+	case "sendMessage", "sendPhoto", "sendDocument", "sendVideo", "sendAnimation", "sendLocation", "copyMessage", "forwardMessage":
+		msg := s.botMessageLocked(chatID, params)
+		call.MessageID = msg.MessageID
+		result = msg
+	case "editMessageText", "editMessageCaption", "editMessageReplyMarkup":
+		msg := s.botMessageLocked(chatID, params)
+		msg.MessageID, _ = strconv.Atoi(params.Get("message_id"))
+		call.MessageID = msg.MessageID
+		result = msg
+	case "sendMediaGroup":
+		var media []map[string]interface{}
+		json.Unmarshal([]byte(params.Get("media")), &media)
+		messages := []tgbotapi.Message{}
+		for _, m := range media {
+			p := url.Values{}
+			if caption, ok := m["caption"].(string); ok {
+				p.Set("caption", caption)
+			}
+			if file, ok := m["media"].(string); ok {
+				p.Set("photo", file)
+			}
+			msg := s.botMessageLocked(chatID, p)
+			messages = append(messages, msg)
+		}
+		if len(messages) > 0 {
+			call.MessageID = messages[0].MessageID
+		}
+		result = messages
+	case "setWebhook":
+		s.webhook = params.Get("url")
+		s.webhookSecret = params.Get("secret_token")
+	case "deleteWebhook":
+		s.webhook = ""
+		s.webhookSecret = ""
+	case "getWebhookInfo":
+		result = tgbotapi.WebhookInfo{URL: s.webhook}
+	case "getFile":
+		fileID := params.Get("file_id")
+		data, ok := s.files[fileID]
+		if !ok {
+			s.calls = append(s.calls, call)
+			return nil, &failure{code: http.StatusBadRequest, description: "Bad Request: invalid file_id"}
+		}
+		result = tgbotapi.File{FileID: fileID, FileUniqueID: fileID, FileSize: len(data), FilePath: "photos/" + fileID}
+	}
+	s.calls = append(s.calls, call)
+	return result, nil
+}
+
+// serveFile отдает содержимое файла, добавленного AddFile, по пути из ответа getFile.
+func (s *Server) serveFile(w http.ResponseWriter, filePath string) {
+	s.mu.Lock()
+	data, ok := s.files[strings.TrimPrefix(filePath, "photos/")]
+	s.mu.Unlock()
+	if !ok {
+		http.NotFound(w, nil)
+		return
+	}
+	w.Write(data)
+}
+
+// botMessageLocked формирует сообщение, которое Telegram вернул бы в ответ на отправку.
+func (s *Server) botMessageLocked(chatID int64, params url.Values) tgbotapi.Message {
+	s.nextMessageID++
+	msg := tgbotapi.Message{
+		MessageID: s.nextMessageID,
+		From:      &s.Bot,
+		Chat:      &tgbotapi.Chat{ID: chatID, Type: "private"},
+		Date:      int(time.Now().Unix()),
+		Text:      params.Get("text"),
+		Caption:   params.Get("caption"),
+	}
+	if photo := params.Get("photo"); photo != "" {
+		msg.Photo = []tgbotapi.PhotoSize{{FileID: photo, FileUniqueID: photo}}
+	}
+	return msg
+}
+
+// getUpdates отдает обновления начиная с offset, при их отсутствии ждет до timeout секунд.
+func (s *Server) getUpdates(w http.ResponseWriter, r *http.Request, params url.Values) {
+	offset, _ := strconv.Atoi(params.Get("offset"))
+	limit, _ := strconv.Atoi(params.Get("limit"))
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+	// при нулевом тайм-ауте клиент опрашивал бы сервер без пауз
+	wait := time.Second
+	if timeout, _ := strconv.Atoi(params.Get("timeout")); timeout > 0 {
+		wait = time.Duration(timeout) * time.Second
+	}
+	deadline := time.NewTimer(wait)
+	defer deadline.Stop()
+
+	for {
+		s.mu.Lock()
+		// обновления с ID меньше offset бот уже получил
+		kept := s.updates[:0]
+		for _, u := range s.updates {
+			if u.UpdateID >= offset {
+				kept = append(kept, u)
+			}
+		}
+		s.updates = kept
+		if len(kept) > 0 {
+			n := len(kept)
+			if n > limit {
+				n = limit
+			}
+			batch := append([]tgbotapi.Update(nil), kept[:n]...)
+			s.mu.Unlock()
+			writeJSON(w, http.StatusOK, apiResponse{Ok: true, Result: batch})
+			return
+		}
+		changed := s.changed
+		s.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-deadline.C:
+			writeJSON(w, http.StatusOK, apiResponse{Ok: true, Result: []tgbotapi.Update{}})
+			return
+		case <-r.Context().Done():
+			return
+		case <-s.done:
+			writeJSON(w, http.StatusOK, apiResponse{Ok: true, Result: []tgbotapi.Update{}})
+			return
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
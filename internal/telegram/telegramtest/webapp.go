@@ -0,0 +1,22 @@
+package telegramtest
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"time"
+
+	"tourism/internal/telegram"
+)
+
+// InitData возвращает данные запуска Mini App пользователя user, подписанные токеном бота token,
+// — то, что клиент передает API в заголовке Authorization: tma <данные>.
+func InitData(token string, user telegram.WebAppUser, authDate time.Time) string {
+	data, _ := json.Marshal(user)
+	values := url.Values{}
+	values.Set("query_id", "test")
+	values.Set("user", string(data))
+	values.Set("auth_date", strconv.FormatInt(authDate.Unix(), 10))
+	values.Set("hash", telegram.SignInitData(values, token))
+	return values.Encode()
+}
@@ -0,0 +1,95 @@
+package telegram
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInitData возвращается, если данные запуска Mini App не подписаны ни одним из ботов,
+// устарели или не содержат пользователя.
+var ErrInitData = errors.New("некорректные данные авторизации Telegram")
+
+// WebAppUser — пользователь из данных запуска Mini App (поле user).
+type WebAppUser struct {
+	ID           int64  `json:"id"`
+	FirstName    string `json:"first_name"`
+	LastName     string `json:"last_name"`
+	Username     string `json:"username"`
+	LanguageCode string `json:"language_code"`
+}
+
+// ValidateInitData проверяет данные запуска Mini App (Telegram.WebApp.initData) по алгоритму
+// из документации Bot API: подпись hash должна совпасть с HMAC-SHA256 остальных полей на ключе,
+// полученном из токена одного из ботов tokens, а auth_date — быть не старше maxAge.
+func ValidateInitData(initData string, tokens []string, maxAge time.Duration, now time.Time) (*WebAppUser, error) {
+	values, err := url.ParseQuery(initData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInitData, err)
+	}
+	hash, err := hex.DecodeString(values.Get("hash"))
+	if err != nil || len(hash) != sha256.Size {
+		return nil, fmt.Errorf("%w: нет подписи", ErrInitData)
+	}
+	check := dataCheckString(values)
+	signed := false
+	for _, token := range tokens {
+		if token != "" && hmac.Equal(hash, initDataHash(check, token)) {
+			signed = true
+			break
+		}
+	}
+	if !signed {
+		return nil, fmt.Errorf("%w: неверная подпись", ErrInitData)
+	}
+	authDate, err := strconv.ParseInt(values.Get("auth_date"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: нет auth_date", ErrInitData)
+	}
+	if age := now.Sub(time.Unix(authDate, 0)); maxAge > 0 && age > maxAge {
+		return nil, fmt.Errorf("%w: данные устарели", ErrInitData)
+	}
+	var user WebAppUser
+	if err := json.Unmarshal([]byte(values.Get("user")), &user); err != nil || user.ID == 0 {
+		return nil, fmt.Errorf("%w: нет пользователя", ErrInitData)
+	}
+	return &user, nil
+}
+
+// dataCheckString собирает строку для подписи: все поля, кроме hash, в виде key=value
+// по алфавиту ключей через перевод строки.
+func dataCheckString(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		if k != "hash" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	lines := make([]string, 0, len(keys))
+	for _, k := range keys {
+		lines = append(lines, k+"="+values.Get(k))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SignInitData возвращает hash для данных запуска, подписанных токеном бота token.
+func SignInitData(values url.Values, token string) string {
+	return hex.EncodeToString(initDataHash(dataCheckString(values), token))
+}
+
+func initDataHash(check, token string) []byte {
+	key := hmac.New(sha256.New, []byte("WebAppData"))
+	key.Write([]byte(token))
+	mac := hmac.New(sha256.New, key.Sum(nil))
+	mac.Write([]byte(check))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,246 @@
+package telegram
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// SecretTokenHeader — заголовок, в котором Telegram передает секрет, заданный при setWebhook.
+const SecretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// DefaultWebhookPath — путь обработчика вебхука по умолчанию.
+const DefaultWebhookPath = "/telegram/webhook"
+
+// AllowedUpdates — типы обновлений, которые обрабатывают боты. Остальные Telegram не присылает
+// ни через вебхук, ни через long polling.
+var AllowedUpdates = []string{"message", "callback_query"}
+
+// shutdownTimeout — сколько ждать завершения уже принятых запросов при остановке вебхука.
+const shutdownTimeout = 10 * time.Second
+
+// Requester выполняет методы Bot API с произвольными параметрами. Нужен для параметров,
+// которых нет в конфигурациях tgbotapi (secret_token у setWebhook). Реализуется *tgbotapi.BotAPI.
+type Requester interface {
+	MakeRequest(endpoint string, params tgbotapi.Params) (*tgbotapi.APIResponse, error)
+}
+
+var _ Requester = (*tgbotapi.BotAPI)(nil)
+
+// WebhookConfig — настройки получения обновлений через вебхук.
+type WebhookConfig struct {
+	URL            string // публичный адрес бота за балансировщиком, например https://bot.example.com
+	Path           string // путь обработчика; по умолчанию DefaultWebhookPath
+	Listen         string // адрес HTTP-сервера, например ":8443"
+	SecretToken    string // секрет для заголовка X-Telegram-Bot-Api-Secret-Token (1-256 символов A-Z, a-z, 0-9, _ и -)
+	MaxConnections int    // максимум одновременных соединений от Telegram (0 — по умолчанию Telegram)
+}
+
+// Enabled сообщает, включен ли режим вебхука (задан публичный адрес).
+func (c WebhookConfig) Enabled() bool {
+	return c.URL != ""
+}
+
+var secretTokenPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+
+// Validate проверяет настройки и возвращает все найденные ошибки.
+func (c WebhookConfig) Validate() error {
+	var errs []error
+	if u, err := url.Parse(c.URL); err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+		errs = append(errs, fmt.Errorf("некорректный адрес вебхука %q", c.URL))
+	}
+	if c.Path != "" && !strings.HasPrefix(c.Path, "/") {
+		errs = append(errs, fmt.Errorf("путь вебхука должен начинаться с \"/\": %q", c.Path))
+	}
+	if c.Listen == "" {
+		errs = append(errs, errors.New("не задан адрес HTTP-сервера вебхука"))
+	}
+	if !secretTokenPattern.MatchString(c.SecretToken) {
+		errs = append(errs, errors.New("секрет вебхука должен содержать от 1 до 256 символов A-Z, a-z, 0-9, _ и -"))
+	}
+	return errors.Join(errs...)
+}
+
+// path возвращает путь обработчика с учетом значения по умолчанию.
+func (c WebhookConfig) path() string {
+	if c.Path == "" {
+		return DefaultWebhookPath
+	}
+	return c.Path
+}
+
+// Endpoint возвращает полный адрес, который регистрируется в Telegram.
+func (c WebhookConfig) Endpoint() string {
+	return strings.TrimSuffix(c.URL, "/") + c.path()
+}
+
+// Webhook принимает обновления по HTTP и реализует Updates, поэтому боты обрабатывают их
+// так же, как полученные long polling. Все реплики за балансировщиком регистрируют один и тот же
+// адрес и секрет, а Telegram доставляет каждое обновление одной из них.
+type Webhook struct {
+	api    Requester
+	cfg    WebhookConfig
+	server *http.Server
+
+	updates chan tgbotapi.Update
+	done    chan struct{}
+
+	mu      sync.RWMutex
+	stopped bool
+	once    sync.Once
+}
+
+var _ Updates = (*Webhook)(nil)
+
+// NewWebhook создает приемник обновлений. Сервер не запускается до вызова Start.
+func NewWebhook(api Requester, cfg WebhookConfig) (*Webhook, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("некорректные настройки вебхука: %w", err)
+	}
+	w := &Webhook{
+		api:     api,
+		cfg:     cfg,
+		updates: make(chan tgbotapi.Update, 100),
+		done:    make(chan struct{}),
+	}
+	mux := http.NewServeMux()
+	mux.Handle(cfg.path(), w)
+	w.server = &http.Server{Addr: cfg.Listen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	return w, nil
+}
+
+// Start открывает порт, запускает HTTP-сервер и регистрирует вебхук в Telegram (setWebhook).
+// Порт открывается до регистрации, чтобы Telegram не получил отказ в соединении.
+func (w *Webhook) Start() error {
+	ln, err := net.Listen("tcp", w.cfg.Listen)
+	if err != nil {
+		return fmt.Errorf("не удалось открыть порт вебхука: %w", err)
+	}
+	go func() {
+		if err := w.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Сервер вебхука остановлен с ошибкой", "err", err)
+		}
+	}()
+	if err := SetWebhook(w.api, w.cfg); err != nil {
+		w.server.Close()
+		return err
+	}
+	slog.Info("Обновления принимаются через вебхук", "endpoint", w.cfg.Endpoint(), "listen", w.cfg.Listen)
+	return nil
+}
+
+// ServeHTTP принимает одно обновление от Telegram.
+func (w *Webhook) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		rw.Header().Set("Allow", http.MethodPost)
+		http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	secret := r.Header.Get(SecretTokenHeader)
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(w.cfg.SecretToken)) != 1 {
+		http.Error(rw, "forbidden", http.StatusForbidden)
+		return
+	}
+	var update tgbotapi.Update
+	if err := json.NewDecoder(http.MaxBytesReader(rw, r.Body, 1<<20)).Decode(&update); err != nil {
+		http.Error(rw, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.stopped {
+		// Telegram повторит доставку, возможно, на другую реплику
+		http.Error(rw, "shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	select {
+	case w.updates <- update:
+		rw.WriteHeader(http.StatusOK)
+	case <-w.done:
+		http.Error(rw, "shutting down", http.StatusServiceUnavailable)
+	case <-r.Context().Done():
+	}
+}
+
+// GetUpdatesChan возвращает канал принятых обновлений. Параметры long polling не используются.
+func (w *Webhook) GetUpdatesChan(tgbotapi.UpdateConfig) tgbotapi.UpdatesChannel {
+	return w.updates
+}
+
+// StopReceivingUpdates останавливает прием: сервер перестает принимать соединения, уже принятые
+// обновления передаются обработчику, после чего канал обновлений закрывается. Вебхук в Telegram
+// не удаляется, чтобы остальные реплики продолжали получать обновления.
+func (w *Webhook) StopReceivingUpdates() {
+	w.once.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := w.server.Shutdown(ctx); err != nil {
+			slog.Warn("Сервер вебхука не остановился вовремя", "timeout", shutdownTimeout, "err", err)
+		}
+		close(w.done)
+		w.mu.Lock()
+		w.stopped = true
+		close(w.updates)
+		w.mu.Unlock()
+	})
+}
+
+// SetWebhook регистрирует адрес вебхука, секрет и типы обновлений (AllowedUpdates) в Telegram.
+func SetWebhook(api Requester, cfg WebhookConfig) error {
+	params := tgbotapi.Params{
+		"url":          cfg.Endpoint(),
+		"secret_token": cfg.SecretToken,
+	}
+	if err := params.AddInterface("allowed_updates", AllowedUpdates); err != nil {
+		return fmt.Errorf("не удалось зарегистрировать вебхук: %w", err)
+	}
+	if cfg.MaxConnections > 0 {
+		params["max_connections"] = strconv.Itoa(cfg.MaxConnections)
+	}
+	if _, err := api.MakeRequest("setWebhook", params); err != nil {
+		return fmt.Errorf("не удалось зарегистрировать вебхук: %w", err)
+	}
+	return nil
+}
+
+// DeleteWebhook удаляет вебхук, чтобы бот мог получать обновления через long polling
+// (пока вебхук задан, getUpdates возвращает ошибку). Накопившиеся обновления сохраняются.
+func DeleteWebhook(api Sender) error {
+	if _, err := api.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+		return fmt.Errorf("не удалось удалить вебхук: %w", err)
+	}
+	return nil
+}
+
+// Listen выбирает источник обновлений: вебхук, если он настроен, иначе long polling.
+// Перед long polling ранее зарегистрированный вебхук удаляется.
+func Listen(api API, cfg WebhookConfig) (Updates, error) {
+	if !cfg.Enabled() {
+		if err := DeleteWebhook(api); err != nil {
+			return nil, err
+		}
+		return api, nil
+	}
+	w, err := NewWebhook(api, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.Start(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
@@ -0,0 +1,227 @@
+package telegram_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"tourism/internal/telegram"
+	"tourism/internal/telegram/telegramtest"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const secret = "s3cret_token-1"
+
+func webhookConfig() telegram.WebhookConfig {
+	return telegram.WebhookConfig{
+		URL:            "https://bot.example.com/",
+		Listen:         "127.0.0.1:0",
+		SecretToken:    secret,
+		MaxConnections: 40,
+	}
+}
+
+func newBot(t *testing.T) (*telegramtest.Server, *tgbotapi.BotAPI) {
+	t.Helper()
+	server := telegramtest.NewServer("123:test")
+	t.Cleanup(server.Close)
+	api, err := server.NewBot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return server, api
+}
+
+// post отправляет вебхуку тело body с секретом token (пустой — без заголовка).
+func post(w http.Handler, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, telegram.DefaultWebhookPath, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set(telegram.SecretTokenHeader, token)
+	}
+	rec := httptest.NewRecorder()
+	w.ServeHTTP(rec, req)
+	return rec
+}
+
+func updateJSON(t *testing.T, id int, text string) string {
+	t.Helper()
+	b, err := json.Marshal(tgbotapi.Update{UpdateID: id, Message: &tgbotapi.Message{
+		MessageID: id, Chat: &tgbotapi.Chat{ID: 7}, From: &tgbotapi.User{ID: 7}, Text: text,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestWebhookServeHTTP(t *testing.T) {
+	_, api := newBot(t)
+	w, err := telegram.NewWebhook(api, webhookConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	updates := w.GetUpdatesChan(tgbotapi.UpdateConfig{})
+	body := updateJSON(t, 1, "привет")
+
+	tests := []struct {
+		name   string
+		token  string
+		body   string
+		status int
+	}{
+		{"без секрета", "", body, http.StatusForbidden},
+		{"чужой секрет", "wrong", body, http.StatusForbidden},
+		{"префикс секрета", secret[:4], body, http.StatusForbidden},
+		{"некорректный JSON", secret, "{not json", http.StatusBadRequest},
+		{"пустое тело", secret, "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := post(w, tt.token, tt.body); rec.Code != tt.status {
+				t.Errorf("код %d, ожидался %d", rec.Code, tt.status)
+			}
+		})
+	}
+	req := httptest.NewRequest(http.MethodGet, telegram.DefaultWebhookPath, nil)
+	req.Header.Set(telegram.SecretTokenHeader, secret)
+	rec := httptest.NewRecorder()
+	w.ServeHTTP(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != http.MethodPost {
+		t.Errorf("GET: код %d, Allow %q", rec.Code, rec.Header().Get("Allow"))
+	}
+	select {
+	case u := <-updates:
+		t.Fatalf("отклоненный запрос попал в очередь: %+v", u)
+	default:
+	}
+
+	if rec := post(w, secret, body); rec.Code != http.StatusOK {
+		t.Fatalf("корректное обновление: код %d", rec.Code)
+	}
+	select {
+	case u := <-updates:
+		if u.UpdateID != 1 || u.Message == nil || u.Message.Text != "привет" {
+			t.Errorf("принято обновление %+v", u)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("принятое обновление не передано в канал")
+	}
+}
+
+func TestWebhookRejectsAfterStop(t *testing.T) {
+	_, api := newBot(t)
+	w, err := telegram.NewWebhook(api, webhookConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	updates := w.GetUpdatesChan(tgbotapi.UpdateConfig{})
+	if rec := post(w, secret, updateJSON(t, 1, "до остановки")); rec.Code != http.StatusOK {
+		t.Fatalf("до остановки: код %d", rec.Code)
+	}
+	w.StopReceivingUpdates()
+	w.StopReceivingUpdates() // повторная остановка безопасна
+
+	// Telegram повторит доставку на другую реплику
+	if rec := post(w, secret, updateJSON(t, 2, "после остановки")); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("после остановки: код %d, ожидался 503", rec.Code)
+	}
+	// принятое до остановки обновление передается обработчику, затем канал закрывается
+	got := []int{}
+	for u := range updates {
+		got = append(got, u.UpdateID)
+	}
+	if !slices.Equal(got, []int{1}) {
+		t.Errorf("из канала получены обновления %v, ожидалось [1]", got)
+	}
+}
+
+func TestSetWebhook(t *testing.T) {
+	server, api := newBot(t)
+	cfg := webhookConfig()
+	cfg.Path = "/hook"
+	if err := telegram.SetWebhook(api, cfg); err != nil {
+		t.Fatal(err)
+	}
+	calls := server.Calls()
+	i := slices.IndexFunc(calls, func(c telegramtest.Call) bool { return c.Method == "setWebhook" })
+	if i < 0 {
+		t.Fatal("setWebhook не вызван")
+	}
+	params := calls[i].Params
+	if got := params.Get("url"); got != "https://bot.example.com/hook" {
+		t.Errorf("url %q", got)
+	}
+	if got := params.Get("secret_token"); got != secret {
+		t.Errorf("secret_token %q, ожидался %q", got, secret)
+	}
+	if got := params.Get("max_connections"); got != "40" {
+		t.Errorf("max_connections %q, ожидалось 40", got)
+	}
+	var allowed []string
+	if err := json.Unmarshal([]byte(params.Get("allowed_updates")), &allowed); err != nil {
+		t.Fatalf("allowed_updates %q: %v", params.Get("allowed_updates"), err)
+	}
+	if !slices.Equal(allowed, telegram.AllowedUpdates) {
+		t.Errorf("allowed_updates %v, ожидалось %v", allowed, telegram.AllowedUpdates)
+	}
+
+	server.Fail("setWebhook", 400, "Bad Request: bad webhook: HTTPS url must be provided for webhook", 0)
+	if err := telegram.SetWebhook(api, cfg); err == nil {
+		t.Error("ошибка setWebhook не возвращена")
+	}
+}
+
+func TestListen(t *testing.T) {
+	t.Run("long polling", func(t *testing.T) {
+		server, api := newBot(t)
+		updates, err := telegram.Listen(api, telegram.WebhookConfig{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if updates != telegram.Updates(api) {
+			t.Errorf("без адреса вебхука ожидался long polling, получен %T", updates)
+		}
+		if !slices.ContainsFunc(server.Calls(), func(c telegramtest.Call) bool { return c.Method == "deleteWebhook" }) {
+			t.Error("перед long polling вебхук не удален")
+		}
+	})
+	t.Run("вебхук", func(t *testing.T) {
+		server, api := newBot(t)
+		cfg := webhookConfig()
+		updates, err := telegram.Listen(api, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer updates.StopReceivingUpdates()
+		if _, ok := updates.(*telegram.Webhook); !ok {
+			t.Fatalf("ожидался вебхук, получен %T", updates)
+		}
+		if server.Webhook() != cfg.Endpoint() || server.WebhookSecret() != secret {
+			t.Errorf("зарегистрирован вебхук %q с секретом %q", server.Webhook(), server.WebhookSecret())
+		}
+	})
+	t.Run("некорректные настройки", func(t *testing.T) {
+		server, api := newBot(t)
+		cfg := webhookConfig()
+		cfg.SecretToken = "с пробелом"
+		if _, err := telegram.Listen(api, cfg); err == nil {
+			t.Fatal("ожидалась ошибка настроек")
+		}
+		if server.Webhook() != "" {
+			t.Errorf("при некорректных настройках зарегистрирован вебхук %q", server.Webhook())
+		}
+	})
+	t.Run("ошибка регистрации", func(t *testing.T) {
+		server, api := newBot(t)
+		server.Fail("setWebhook", 401, "Unauthorized", 0)
+		if _, err := telegram.Listen(api, webhookConfig()); err == nil {
+			t.Fatal("ожидалась ошибка setWebhook")
+		}
+	})
+}
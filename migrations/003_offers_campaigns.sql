@@ -0,0 +1,29 @@
+-- Предложения провайдеров и рассылки по сегментам аудитории
+ALTER TABLE users ADD COLUMN IF NOT EXISTS language_code VARCHAR(10) NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS offers (
+    id SERIAL PRIMARY KEY,
+    location_id INTEGER NOT NULL REFERENCES locations(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    price DOUBLE PRECISION NOT NULL DEFAULT 0,
+    contact VARCHAR(255) NOT NULL DEFAULT '',
+    photo_file_id VARCHAR(255) NOT NULL DEFAULT '',
+    social_links TEXT[] NOT NULL DEFAULT '{}'
+);
+
+CREATE TABLE IF NOT EXISTS campaigns (
+    id SERIAL PRIMARY KEY,
+    author_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    text TEXT NOT NULL DEFAULT '',
+    photo_file_id VARCHAR(255) NOT NULL DEFAULT '',
+    buttons JSONB NOT NULL DEFAULT '[]',
+    segment JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(50) NOT NULL DEFAULT 'draft',
+    scheduled_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS campaigns_due_idx ON campaigns (scheduled_at) WHERE status = 'scheduled';
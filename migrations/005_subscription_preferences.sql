@@ -0,0 +1,4 @@
+-- Темы, регионы и частота рассылки в подписке на предложения
+ALTER TABLE offer_subscriptions ADD COLUMN IF NOT EXISTS topics TEXT[] NOT NULL DEFAULT '{housing,tour,festival}';
+ALTER TABLE offer_subscriptions ADD COLUMN IF NOT EXISTS regions TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE offer_subscriptions ADD COLUMN IF NOT EXISTS frequency VARCHAR(20) NOT NULL DEFAULT 'instant';
@@ -0,0 +1,13 @@
+-- Еженедельный дайджест: даты появления локаций и предложений, учет отправленного
+ALTER TABLE locations ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+ALTER TABLE offers ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+ALTER TABLE offer_subscriptions ADD COLUMN IF NOT EXISTS last_digest_at TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS digest_items (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    item_type VARCHAR(20) NOT NULL,
+    item_id INTEGER NOT NULL,
+    sent_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, item_type, item_id)
+);
@@ -0,0 +1,11 @@
+-- Состояние диалогов основного бота: текущий сценарий, шаг и накопленные данные пользователя
+CREATE TABLE IF NOT EXISTS conversation_states (
+    telegram_id BIGINT PRIMARY KEY,
+    flow VARCHAR(50) NOT NULL,
+    step VARCHAR(50) NOT NULL DEFAULT '',
+    payload JSONB NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS conversation_states_expires_idx ON conversation_states (expires_at) WHERE expires_at IS NOT NULL;
@@ -0,0 +1,4 @@
+-- Язык интерфейса, выбранный пользователем командой /language. Для существующих пользователей
+-- заполняется основным языком из language_code профиля Telegram ("en-US" -> "en").
+ALTER TABLE users ADD COLUMN IF NOT EXISTS language VARCHAR(10) NOT NULL DEFAULT '';
+UPDATE users SET language = SPLIT_PART(LOWER(language_code), '-', 1) WHERE language = '';
@@ -0,0 +1,21 @@
+-- Переводы названий и описаний локаций и предложений. Основной язык контента — русский
+-- (колонки name и description самих записей); переводы предлагают провайдеры, а поддержка
+-- проверяет их. Для каждой записи и языка действует не больше одного одобренного перевода,
+-- предыдущий при одобрении нового получает статус replaced.
+CREATE TABLE IF NOT EXISTS translations (
+    id SERIAL PRIMARY KEY,
+    entity VARCHAR(20) NOT NULL CHECK (entity IN ('location', 'offer')),
+    entity_id INTEGER NOT NULL,
+    locale VARCHAR(10) NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    author_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    reviewer_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    reviewed_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS translations_approved_idx ON translations (entity, entity_id, locale) WHERE status = 'approved';
+CREATE INDEX IF NOT EXISTS translations_pending_idx ON translations (created_at) WHERE status = 'pending';
@@ -0,0 +1,20 @@
+-- Заявки пользователей на роль провайдера (/become_provider): название бизнеса, контакты,
+-- фото документов и локации каталога, которыми заявитель владеет. Поддержка одобряет заявку —
+-- пользователь получает роль provider, а свободные локации из заявки закрепляются за ним.
+CREATE TABLE IF NOT EXISTS provider_applications (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    business_name TEXT NOT NULL,
+    contacts TEXT NOT NULL,
+    document_file_ids TEXT[] NOT NULL DEFAULT '{}',
+    location_ids INTEGER[] NOT NULL DEFAULT '{}',
+    locations_note TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    reviewer_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    reviewed_at TIMESTAMPTZ
+);
+
+-- у пользователя может быть только одна заявка на рассмотрении
+CREATE UNIQUE INDEX IF NOT EXISTS provider_applications_pending_idx ON provider_applications (user_id) WHERE status = 'pending';
@@ -0,0 +1,14 @@
+-- Администрирование пользователей: роль admin, блокировка и журнал действий администраторов.
+-- Заблокированных пользователей оба бота игнорируют, бронировать они не могут.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_blocked BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id SERIAL PRIMARY KEY,
+    actor_id INTEGER REFERENCES users(id) ON DELETE SET NULL, -- NULL для служебных команд
+    action VARCHAR(50) NOT NULL,
+    target_user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    details TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_target_idx ON audit_log (target_user_id, id);
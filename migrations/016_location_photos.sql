@@ -0,0 +1,17 @@
+-- Галерея фото локации: порядок показа, подпись и обложка. Обложка у локации одна, она
+-- открывает альбом в карточке. Существующие фото нумеруются в порядке добавления,
+-- обложкой становится первое.
+ALTER TABLE location_photos ADD COLUMN IF NOT EXISTS position INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE location_photos ADD COLUMN IF NOT EXISTS caption TEXT NOT NULL DEFAULT '';
+ALTER TABLE location_photos ADD COLUMN IF NOT EXISTS is_cover BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE location_photos p SET position = n.position
+FROM (SELECT id, ROW_NUMBER() OVER (PARTITION BY location_id ORDER BY id) AS position FROM location_photos) n
+WHERE p.id = n.id AND p.position = 0;
+
+UPDATE location_photos SET is_cover = TRUE
+WHERE position = 1 AND NOT EXISTS (
+    SELECT 1 FROM location_photos c WHERE c.location_id = location_photos.location_id AND c.is_cover);
+
+CREATE INDEX IF NOT EXISTS location_photos_location_idx ON location_photos (location_id, position);
+CREATE UNIQUE INDEX IF NOT EXISTS location_photos_cover_idx ON location_photos (location_id) WHERE is_cover;
@@ -0,0 +1,13 @@
+-- Копии фото локаций в хранилище файлов (локальный каталог или S3): основной бот скачивает
+-- фото из Telegram один раз, сохраняет оригинал и уменьшенные копии, а API отдает их по HTTP.
+-- file_id остается для повторной отправки в Telegram. Фото, которые не удалось сохранить
+-- несколько раз подряд, больше не скачиваются.
+ALTER TABLE location_photos ADD COLUMN IF NOT EXISTS storage_key VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE location_photos ADD COLUMN IF NOT EXISTS content_type VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE location_photos ADD COLUMN IF NOT EXISTS width INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE location_photos ADD COLUMN IF NOT EXISTS height INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE location_photos ADD COLUMN IF NOT EXISTS stored_at TIMESTAMP;
+ALTER TABLE location_photos ADD COLUMN IF NOT EXISTS store_attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE location_photos ADD COLUMN IF NOT EXISTS store_error TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS location_photos_unstored_idx ON location_photos (id) WHERE stored_at IS NULL;
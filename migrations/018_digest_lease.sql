@@ -0,0 +1,2 @@
+-- Резервирование подписчика дайджеста за экземпляром бота на время отправки
+ALTER TABLE offer_subscriptions ADD COLUMN IF NOT EXISTS digest_lease_until TIMESTAMPTZ;
@@ -0,0 +1,10 @@
+// Package migrations встраивает SQL-миграции в бинарные файлы: API применяет их при старте,
+// а проверки готовности всех приложений сверяют по ним версию схемы.
+package migrations
+
+import "embed"
+
+// FS содержит файлы миграций NNN_название.sql.
+//
+//go:embed *.sql
+var FS embed.FS